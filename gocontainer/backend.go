@@ -0,0 +1,50 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the LGPLv3, see COPYING and COPYING.LESSER file for details.
+
+package gocontainer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	backendsMu sync.Mutex
+	backends   = make(map[string]ContainerFactory)
+)
+
+// RegisterBackend registers the container factory of a backend under
+// the given name, so it can be chosen by configuration. It panics if
+// a backend is already registered with that name.
+func RegisterBackend(name string, factory ContainerFactory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if backends[name] != nil {
+		panic(fmt.Errorf("gocontainer: duplicate backend name %q", name))
+	}
+	backends[name] = factory
+}
+
+// Backend returns the container factory registered with the given name.
+func Backend(name string) (ContainerFactory, error) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	factory, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("no registered container backend %q", name)
+	}
+	return factory, nil
+}
+
+// Backends returns the sorted names of the registered backends.
+func Backends() []string {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	var names []string
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,157 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the LGPLv3, see COPYING and COPYING.LESSER file for details.
+
+// gocontainer - Go package defining the interface shared by the linux
+// container backends, like golxc and godocker.
+//
+package gocontainer
+
+import (
+	"errors"
+)
+
+// State represents a container state.
+type State string
+
+const (
+	StateUnknown  State = "UNKNOWN"
+	StateStopped  State = "STOPPED"
+	StateStarting State = "STARTING"
+	StateRunning  State = "RUNNING"
+	StateAborting State = "ABORTING"
+	StateStopping State = "STOPPING"
+	StateFrozen   State = "FROZEN"
+)
+
+// LogLevel represents a container's log level.
+type LogLevel string
+
+const (
+	LogDebug    LogLevel = "DEBUG"
+	LogInfo     LogLevel = "INFO"
+	LogNotice   LogLevel = "NOTICE"
+	LogWarning  LogLevel = "WARN"
+	LogError    LogLevel = "ERROR"
+	LogCritical LogLevel = "CRIT"
+	LogFatal    LogLevel = "FATAL"
+)
+
+// Capability names an optional feature of a container backend.
+type Capability string
+
+const (
+	// Freezable backends can freeze and unfreeze the processes of
+	// a running container.
+	Freezable Capability = "freezable"
+
+	// Cloneable backends can create a copy of an existing container.
+	Cloneable Capability = "cloneable"
+
+	// Snapshot backends' containers implement Snapshotter.
+	Snapshot Capability = "snapshot"
+
+	// LiveSnapshot backends can snapshot running containers. The
+	// containers of the other Snapshot backends must be stopped first.
+	LiveSnapshot Capability = "live-snapshot"
+
+	// ImageBased backends create containers from images rather than
+	// from templates; the template given to Create names the image.
+	ImageBased Capability = "image-based"
+)
+
+// ErrNotSupported is returned by operations the backend does not
+// provide.
+var ErrNotSupported = errors.New("operation not supported by the container backend")
+
+// Container represents a linux container instance and provides
+// operations to create, maintain and destroy the container.
+type Container interface {
+
+	// Name returns the name of the container.
+	Name() string
+
+	// Create creates a new container based on the given template, or
+	// image for image based backends.
+	Create(configFile, template string, templateArgs ...string) error
+
+	// Start runs the container as a daemon.
+	Start(configFile, consoleFile string) error
+
+	// Stop terminates the running container.
+	Stop() error
+
+	// Clone creates a copy of the container, giving the copy the specified name.
+	Clone(name string) (Container, error)
+
+	// Freeze freezes all the container's processes.
+	Freeze() error
+
+	// Unfreeze thaws all frozen container's processes.
+	Unfreeze() error
+
+	// Destroy stops and removes the container.
+	Destroy() error
+
+	// Wait waits for one of the specified container states.
+	Wait(states ...State) error
+
+	// Info returns the status and the process id of the container.
+	Info() (State, int, error)
+
+	// IsConstructed checks if the container exists.
+	IsConstructed() bool
+
+	// IsRunning checks if the state of the container is 'RUNNING'.
+	IsRunning() bool
+
+	// String returns information about the container, like the name, state,
+	// and process id.
+	String() string
+
+	// LogFile returns the current filename used for the LogFile.
+	LogFile() string
+
+	// LogLevel returns the current logging level (only used if the
+	// LogFile is not "").
+	LogLevel() LogLevel
+
+	// SetLogFile sets both the LogFile and LogLevel.
+	SetLogFile(filename string, level LogLevel)
+}
+
+// Snapshotter is implemented by the containers of backends with the
+// Snapshot capability.
+type Snapshotter interface {
+	// Snapshot records the current state of the container under the
+	// given name. The container must not be running unless the backend
+	// has the LiveSnapshot capability.
+	Snapshot(name string) error
+
+	// Restore replaces the container with the named snapshot. The
+	// container must not be running.
+	Restore(name string) error
+}
+
+// ContainerFactory represents the methods used to create Containers.
+type ContainerFactory interface {
+	// New returns a container instance which can then be used for operations
+	// like Create(), Start(), Stop() or Destroy().
+	New(string) Container
+
+	// List returns all the existing containers on the system.
+	List() ([]Container, error)
+
+	// Capabilities returns the optional features the backend provides.
+	Capabilities() []Capability
+}
+
+// HasCapability reports whether the factory's backend provides the
+// given capability.
+func HasCapability(factory ContainerFactory, capability Capability) bool {
+	for _, c := range factory.Capabilities() {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
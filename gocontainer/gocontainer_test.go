@@ -0,0 +1,64 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the LGPLv3, see COPYING and COPYING.LESSER file for details.
+
+package gocontainer_test
+
+import (
+	"testing"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/gocontainer"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type GoContainerSuite struct{}
+
+var _ = Suite(&GoContainerSuite{})
+
+type stubFactory struct {
+	capabilities []gocontainer.Capability
+}
+
+func (*stubFactory) New(name string) gocontainer.Container {
+	return nil
+}
+
+func (*stubFactory) List() ([]gocontainer.Container, error) {
+	return nil, nil
+}
+
+func (f *stubFactory) Capabilities() []gocontainer.Capability {
+	return f.capabilities
+}
+
+func (s *GoContainerSuite) TestHasCapability(c *C) {
+	factory := &stubFactory{[]gocontainer.Capability{
+		gocontainer.Freezable,
+		gocontainer.ImageBased,
+	}}
+	c.Assert(gocontainer.HasCapability(factory, gocontainer.Freezable), Equals, true)
+	c.Assert(gocontainer.HasCapability(factory, gocontainer.ImageBased), Equals, true)
+	c.Assert(gocontainer.HasCapability(factory, gocontainer.Cloneable), Equals, false)
+	c.Assert(gocontainer.HasCapability(factory, gocontainer.Snapshot), Equals, false)
+	c.Assert(gocontainer.HasCapability(&stubFactory{}, gocontainer.Freezable), Equals, false)
+}
+
+func (s *GoContainerSuite) TestRegisterBackend(c *C) {
+	factory := &stubFactory{}
+	gocontainer.RegisterBackend("stub", factory)
+	found, err := gocontainer.Backend("stub")
+	c.Assert(err, IsNil)
+	c.Assert(found, Equals, gocontainer.ContainerFactory(factory))
+	c.Assert(gocontainer.Backends(), DeepEquals, []string{"stub"})
+
+	c.Assert(func() {
+		gocontainer.RegisterBackend("stub", &stubFactory{})
+	}, PanicMatches, `gocontainer: duplicate backend name "stub"`)
+}
+
+func (s *GoContainerSuite) TestUnknownBackend(c *C) {
+	_, err := gocontainer.Backend("unknown")
+	c.Assert(err, ErrorMatches, `no registered container backend "unknown"`)
+}
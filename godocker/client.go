@@ -0,0 +1,389 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the LGPLv3, see COPYING and COPYING.LESSER file for details.
+
+package godocker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSocket is the unix socket the docker daemon listens on by default.
+const DefaultSocket = "/var/run/docker.sock"
+
+// Error reports the failure of a Docker Remote API request.
+type Error struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("docker %s %s: %s", e.Method, e.Path, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("docker %s %s: %s", e.Method, e.Path, e.Message)
+}
+
+// IsNotFound returns whether err reports a missing container or image.
+func IsNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == http.StatusNotFound
+}
+
+// isNotModified returns whether err reports a request that had no effect,
+// like starting a running container.
+func isNotModified(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == http.StatusNotModified
+}
+
+// Config holds the configuration a container is created with.
+type Config struct {
+	Hostname     string
+	User         string   `json:",omitempty"`
+	Env          []string `json:",omitempty"`
+	Cmd          []string `json:",omitempty"`
+	Image        string
+	Volumes      map[string]struct{} `json:",omitempty"`
+	ExposedPorts map[string]struct{} `json:",omitempty"`
+	AttachStdout bool
+	AttachStderr bool
+	HostConfig   *HostConfig `json:",omitempty"`
+}
+
+// HostConfig holds the host specific configuration of a container.
+type HostConfig struct {
+	// Binds lists host directories mounted into the container,
+	// as "hostpath:containerpath[:ro]".
+	Binds []string `json:",omitempty"`
+}
+
+// ContainerState describes the state of a container's process.
+type ContainerState struct {
+	Running   bool
+	Paused    bool
+	Pid       int
+	ExitCode  int
+	StartedAt time.Time
+	Ghost     bool
+}
+
+// NetworkSettings describes the network a container is attached to.
+type NetworkSettings struct {
+	IPAddress   string
+	IPPrefixLen int
+	Gateway     string
+	Bridge      string
+}
+
+// ContainerInfo holds the details reported by inspecting a container.
+type ContainerInfo struct {
+	ID              string
+	Name            string
+	Created         time.Time
+	Path            string
+	Args            []string
+	Config          *Config
+	HostConfig      *HostConfig
+	State           ContainerState
+	Image           string
+	NetworkSettings *NetworkSettings
+}
+
+// APIContainer is the summary of a container returned by a listing.
+type APIContainer struct {
+	ID      string `json:"Id"`
+	Names   []string
+	Image   string
+	Command string
+	Created int64
+	Status  string
+}
+
+// Version describes the build of the docker daemon.
+type Version struct {
+	Version   string
+	GitCommit string
+	GoVersion string
+}
+
+// Info holds the system wide information reported by the docker daemon.
+type Info struct {
+	Containers int
+	Images     int
+	// Driver names the storage driver holding the images and containers.
+	Driver string
+	// DriverStatus holds details about the storage driver, as pairs
+	// of names and values.
+	DriverStatus  [][2]string
+	KernelVersion string
+	MemoryLimit   bool
+	SwapLimit     bool
+}
+
+// Client talks to a docker daemon through its Remote API.
+type Client struct {
+	socket string
+	http   *http.Client
+}
+
+// NewClient returns a client for the docker daemon listening on the
+// given unix socket.
+func NewClient(socket string) *Client {
+	transport := &http.Transport{
+		Dial: func(_, _ string) (net.Conn, error) {
+			return net.Dial("unix", socket)
+		},
+	}
+	return &Client{
+		socket: socket,
+		http:   &http.Client{Transport: transport},
+	}
+}
+
+// Version returns the version of the docker daemon.
+func (c *Client) Version() (*Version, error) {
+	var version Version
+	if err := c.do("GET", "/version", nil, nil, &version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// Info returns the system wide information held by the docker daemon.
+func (c *Client) Info() (*Info, error) {
+	var info Info
+	if err := c.do("GET", "/info", nil, nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// CreateContainer creates a container with the given name and
+// configuration and returns its id.
+func (c *Client) CreateContainer(name string, config *Config) (string, error) {
+	query := url.Values{}
+	if name != "" {
+		query.Set("name", name)
+	}
+	var result struct {
+		ID string `json:"Id"`
+	}
+	if err := c.do("POST", "/containers/create", query, config, &result); err != nil {
+		return "", err
+	}
+	return result.ID, nil
+}
+
+// StartContainer starts the container with the given id or name.
+// Starting a running container is not an error.
+func (c *Client) StartContainer(id string, hostConfig *HostConfig) error {
+	var in interface{}
+	if hostConfig != nil {
+		in = hostConfig
+	}
+	err := c.do("POST", "/containers/"+id+"/start", nil, in, nil)
+	if isNotModified(err) {
+		return nil
+	}
+	return err
+}
+
+// StopContainer stops the container with the given id or name, killing
+// it if it has not stopped after the timeout. Stopping a container
+// that is not running is not an error.
+func (c *Client) StopContainer(id string, timeout time.Duration) error {
+	query := url.Values{"t": {strconv.Itoa(int(timeout / time.Second))}}
+	err := c.do("POST", "/containers/"+id+"/stop", query, nil, nil)
+	if isNotModified(err) {
+		return nil
+	}
+	return err
+}
+
+// WaitContainer blocks until the container with the given id or name
+// stops, and returns its exit code.
+func (c *Client) WaitContainer(id string) (int, error) {
+	var result struct {
+		StatusCode int
+	}
+	if err := c.do("POST", "/containers/"+id+"/wait", nil, nil, &result); err != nil {
+		return -1, err
+	}
+	return result.StatusCode, nil
+}
+
+// PauseContainer suspends all processes of the container with the given
+// id or name.
+func (c *Client) PauseContainer(id string) error {
+	return c.do("POST", "/containers/"+id+"/pause", nil, nil, nil)
+}
+
+// UnpauseContainer resumes all processes of the container with the
+// given id or name.
+func (c *Client) UnpauseContainer(id string) error {
+	return c.do("POST", "/containers/"+id+"/unpause", nil, nil, nil)
+}
+
+// InspectContainer returns the details of the container with the given
+// id or name.
+func (c *Client) InspectContainer(id string) (*ContainerInfo, error) {
+	var info ContainerInfo
+	if err := c.do("GET", "/containers/"+id+"/json", nil, nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// ListContainers returns the running containers, or all the containers
+// if all is true.
+func (c *Client) ListContainers(all bool) ([]APIContainer, error) {
+	query := url.Values{}
+	if all {
+		query.Set("all", "1")
+	}
+	var result []APIContainer
+	if err := c.do("GET", "/containers/json", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CommitContainer creates a new image from the container with the given
+// id or name, and returns the id of the image.
+func (c *Client) CommitContainer(id, repository, tag string) (string, error) {
+	query := url.Values{"container": {id}}
+	if repository != "" {
+		query.Set("repo", repository)
+	}
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+	var result struct {
+		ID string `json:"Id"`
+	}
+	if err := c.do("POST", "/commit", query, nil, &result); err != nil {
+		return "", err
+	}
+	return result.ID, nil
+}
+
+// RemoveContainer removes the container with the given id or name,
+// along with its volumes.
+func (c *Client) RemoveContainer(id string) error {
+	return c.do("DELETE", "/containers/"+id, url.Values{"v": {"1"}}, nil, nil)
+}
+
+// RemoveImage removes the image with the given name or id.
+func (c *Client) RemoveImage(name string) error {
+	return c.do("DELETE", "/images/"+name, nil, nil, nil)
+}
+
+// ImageExists returns whether the image with the given name is
+// available to the daemon.
+func (c *Client) ImageExists(name string) (bool, error) {
+	err := c.do("GET", "/images/"+name+"/json", nil, nil, nil)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// BuildImage builds an image from the given context, a tar archive
+// holding a Dockerfile, and tags it with the given name.
+func (c *Client) BuildImage(name string, context io.Reader) error {
+	query := url.Values{"t": {name}, "rm": {"1"}}
+	data, err := c.send("POST", "/build", query, "application/tar", context)
+	if err != nil {
+		return err
+	}
+	// The daemon streams the progress of the build, reporting
+	// failures in the stream rather than in the status code.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var message struct {
+			Stream string `json:"stream"`
+			Error  string `json:"error"`
+		}
+		if err := decoder.Decode(&message); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("cannot parse docker build response: %v", err)
+		}
+		if message.Error != "" {
+			return fmt.Errorf("cannot build image %q: %s", name, strings.TrimSpace(message.Error))
+		}
+	}
+}
+
+// do sends a request to the daemon, encoding in as the JSON request body
+// and decoding the JSON response into out, when they are not nil.
+func (c *Client) do(method, path string, query url.Values, in, out interface{}) error {
+	var body io.Reader
+	contentType := ""
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+	data, err := c.send(method, path, query, contentType, body)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("cannot parse docker %s %s response: %v", method, path, err)
+	}
+	return nil
+}
+
+// send sends a request with the given body to the daemon and returns
+// the body of the response.
+func (c *Client) send(method, path string, query url.Values, contentType string, body io.Reader) ([]byte, error) {
+	// The host is ignored, the connection always goes to the socket.
+	u := "http://docker" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot talk to the docker daemon at %q: %v", c.socket, err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &Error{
+			Method:     method,
+			Path:       path,
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(data)),
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the LGPLv3, see COPYING and COPYING.LESSER file for details.
+
+package godocker
+
+import (
+	"time"
+)
+
+// SetPollInterval allows the manipulation of the interval
+// at which Wait checks the container state.
+func SetPollInterval(d time.Duration) time.Duration {
+	orig := pollInterval
+	pollInterval = d
+	return orig
+}
+
+// SetWaitTimeout allows the manipulation of how long Wait waits for
+// the container state.
+func SetWaitTimeout(d time.Duration) time.Duration {
+	orig := waitTimeout
+	waitTimeout = d
+	return orig
+}
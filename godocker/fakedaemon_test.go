@@ -0,0 +1,313 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the LGPLv3, see COPYING and COPYING.LESSER file for details.
+
+package godocker_test
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/godocker"
+)
+
+// fakeDaemon implements the subset of the Docker Remote API used by
+// godocker, keeping containers and images in memory.
+type fakeDaemon struct {
+	mu         sync.Mutex
+	listener   net.Listener
+	socket     string
+	nextId     int
+	nextIP     int
+	images     map[string]bool
+	containers map[string]*fakeContainer
+	// dockerfiles holds the Dockerfiles of the images built.
+	dockerfiles map[string]string
+	// brokenImages holds the images containers cannot be created from.
+	brokenImages map[string]bool
+}
+
+type fakeContainer struct {
+	info godocker.ContainerInfo
+}
+
+// startFakeDaemon starts a fake daemon listening on a unix socket
+// in the given directory, knowing about the given images.
+func startFakeDaemon(c *C, dir string, images ...string) *fakeDaemon {
+	d := &fakeDaemon{
+		socket:       filepath.Join(dir, "docker.sock"),
+		images:       make(map[string]bool),
+		containers:   make(map[string]*fakeContainer),
+		dockerfiles:  make(map[string]string),
+		brokenImages: make(map[string]bool),
+	}
+	for _, image := range images {
+		d.images[image] = true
+	}
+	l, err := net.Listen("unix", d.socket)
+	c.Assert(err, IsNil)
+	d.listener = l
+	go http.Serve(l, d)
+	return d
+}
+
+func (d *fakeDaemon) Close() {
+	d.listener.Close()
+}
+
+func (d *fakeDaemon) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	path := req.URL.Path
+	switch {
+	case req.Method == "GET" && path == "/version":
+		writeJSON(w, http.StatusOK, godocker.Version{
+			Version:   "0.7.0",
+			GitCommit: "0d078b6",
+			GoVersion: "go1.2",
+		})
+	case req.Method == "GET" && path == "/info":
+		writeJSON(w, http.StatusOK, godocker.Info{
+			Containers:    len(d.containers),
+			Images:        len(d.images),
+			Driver:        "aufs",
+			DriverStatus:  [][2]string{{"Root Dir", "/var/lib/docker/aufs"}},
+			KernelVersion: "3.8.0-33-generic",
+			MemoryLimit:   true,
+		})
+	case req.Method == "POST" && path == "/containers/create":
+		d.create(w, req)
+	case req.Method == "GET" && path == "/containers/json":
+		d.list(w, req)
+	case req.Method == "POST" && path == "/commit":
+		d.commit(w, req)
+	case req.Method == "POST" && path == "/build":
+		d.build(w, req)
+	case req.Method == "GET" && strings.HasPrefix(path, "/images/") && strings.HasSuffix(path, "/json"):
+		name := strings.TrimSuffix(strings.TrimPrefix(path, "/images/"), "/json")
+		if !d.images[name] {
+			http.Error(w, "No such image: "+name, http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"id": name})
+	case req.Method == "DELETE" && strings.HasPrefix(path, "/images/"):
+		name := strings.TrimPrefix(path, "/images/")
+		if !d.images[name] {
+			http.Error(w, "No such image: "+name, http.StatusNotFound)
+			return
+		}
+		delete(d.images, name)
+		w.WriteHeader(http.StatusOK)
+	case strings.HasPrefix(path, "/containers/"):
+		parts := strings.Split(strings.TrimPrefix(path, "/containers/"), "/")
+		container := d.lookup(parts[0])
+		if container == nil {
+			http.Error(w, "No such container: "+parts[0], http.StatusNotFound)
+			return
+		}
+		action := ""
+		if len(parts) > 1 {
+			action = parts[1]
+		}
+		d.containerAction(w, req, container, action)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// lookup returns the container with the given name or id.
+func (d *fakeDaemon) lookup(nameOrId string) *fakeContainer {
+	if container := d.containers[nameOrId]; container != nil {
+		return container
+	}
+	for _, container := range d.containers {
+		if container.info.ID == nameOrId {
+			return container
+		}
+	}
+	return nil
+}
+
+func (d *fakeDaemon) create(w http.ResponseWriter, req *http.Request) {
+	var config godocker.Config
+	if err := json.NewDecoder(req.Body).Decode(&config); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !d.images[config.Image] {
+		http.Error(w, "No such image: "+config.Image, http.StatusNotFound)
+		return
+	}
+	if d.brokenImages[config.Image] {
+		http.Error(w, "Cannot mount image: "+config.Image, http.StatusInternalServerError)
+		return
+	}
+	name := req.URL.Query().Get("name")
+	if d.containers[name] != nil {
+		http.Error(w, "Conflict, The name "+name+" is already assigned", http.StatusConflict)
+		return
+	}
+	d.nextId++
+	id := fmt.Sprintf("%064x", d.nextId)
+	if name == "" {
+		name = id[:12]
+	}
+	d.containers[name] = &fakeContainer{
+		info: godocker.ContainerInfo{
+			ID:              id,
+			Name:            "/" + name,
+			Created:         time.Now(),
+			Config:          &config,
+			HostConfig:      config.HostConfig,
+			Image:           config.Image,
+			NetworkSettings: &godocker.NetworkSettings{},
+		},
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"Id": id})
+}
+
+func (d *fakeDaemon) list(w http.ResponseWriter, req *http.Request) {
+	all := req.URL.Query().Get("all") == "1"
+	result := []godocker.APIContainer{}
+	for _, container := range d.containers {
+		if !all && !container.info.State.Running {
+			continue
+		}
+		result = append(result, godocker.APIContainer{
+			ID:    container.info.ID,
+			Names: []string{container.info.Name},
+			Image: container.info.Image,
+		})
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (d *fakeDaemon) commit(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	container := d.lookup(query.Get("container"))
+	if container == nil {
+		http.Error(w, "No such container", http.StatusNotFound)
+		return
+	}
+	image := query.Get("repo")
+	if tag := query.Get("tag"); tag != "" {
+		image += ":" + tag
+	}
+	d.images[image] = true
+	writeJSON(w, http.StatusCreated, map[string]string{"Id": image})
+}
+
+// build reads the Dockerfile from the tar context and checks its base
+// image exists, recording the Dockerfile of each image built.
+func (d *fakeDaemon) build(w http.ResponseWriter, req *http.Request) {
+	tr := tar.NewReader(req.Body)
+	dockerfile := ""
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if hdr.Name == "Dockerfile" {
+			data, err := ioutil.ReadAll(tr)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			dockerfile = string(data)
+		}
+	}
+	name := req.URL.Query().Get("t")
+	for _, line := range strings.Split(dockerfile, "\n") {
+		if !strings.HasPrefix(line, "FROM ") {
+			continue
+		}
+		from := strings.TrimPrefix(line, "FROM ")
+		if !d.images[from] {
+			// Build failures are reported in the stream.
+			writeJSON(w, http.StatusOK, map[string]string{"error": "No such image: " + from})
+			return
+		}
+	}
+	d.images[name] = true
+	d.dockerfiles[name] = dockerfile
+	writeJSON(w, http.StatusOK, map[string]string{"stream": "Successfully built " + name})
+}
+
+func (d *fakeDaemon) containerAction(w http.ResponseWriter, req *http.Request, container *fakeContainer, action string) {
+	state := &container.info.State
+	switch {
+	case req.Method == "GET" && action == "json":
+		writeJSON(w, http.StatusOK, container.info)
+	case req.Method == "POST" && action == "start":
+		if state.Running {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		// A container running false exits as soon as it is started.
+		if cmd := container.info.Config.Cmd; len(cmd) > 0 && cmd[0] == "/bin/false" {
+			state.ExitCode = 1
+			state.StartedAt = time.Now()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		d.nextIP++
+		state.Running = true
+		state.Pid = 1000 + d.nextIP
+		state.StartedAt = time.Now()
+		container.info.NetworkSettings = &godocker.NetworkSettings{
+			IPAddress:   fmt.Sprintf("172.17.0.%d", d.nextIP+1),
+			IPPrefixLen: 16,
+			Gateway:     "172.17.42.1",
+			Bridge:      "docker0",
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case req.Method == "POST" && action == "stop":
+		if !state.Running {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		state.Running = false
+		state.Paused = false
+		state.Pid = 0
+		container.info.NetworkSettings = &godocker.NetworkSettings{}
+		w.WriteHeader(http.StatusNoContent)
+	case req.Method == "POST" && action == "wait":
+		writeJSON(w, http.StatusOK, map[string]int{"StatusCode": state.ExitCode})
+	case req.Method == "POST" && (action == "pause" || action == "unpause"):
+		if !state.Running {
+			http.Error(w, "Container is not running", http.StatusInternalServerError)
+			return
+		}
+		state.Paused = action == "pause"
+		w.WriteHeader(http.StatusNoContent)
+	case req.Method == "DELETE" && action == "":
+		if state.Running {
+			http.Error(w, "Impossible to remove a running container", http.StatusConflict)
+			return
+		}
+		delete(d.containers, strings.TrimPrefix(container.info.Name, "/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
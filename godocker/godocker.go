@@ -5,173 +5,135 @@
 //
 // (https://github.com/dotcloud/docker).
 //
-package main
-//package godocker
+package godocker
 
 import (
+	"encoding/json"
 	"fmt"
-	"os/exec"
+	"io/ioutil"
 	"strings"
-    "strconv"
-    "io/ioutil"
-    "encoding/json"
-)
-
-// Error reports the failure of a LXC command.
-type Error struct {
-	Name   string
-	Err    error
-	Output []string
-}
-
-func (e Error) Error() string {
-	if e.Output == nil {
-		return fmt.Sprintf("error executing %q: %v", e.Name, e.Err)
-	}
-	if len(e.Output) == 1 {
-		return fmt.Sprintf("error executing %q: %v", e.Name, e.Output[0])
-	}
-	return fmt.Sprintf("error executing %q: %s", e.Name, strings.Join(e.Output, "; "))
-}
+	"time"
 
-// State represents a container state.
-type State string
+	"launchpad.net/gocontainer"
+)
 
 const (
-    StateUnknown  State = "UNKNOWN"
-    StateStopped  State = "STOPPED"
-    StateStarting State = "STARTING"
-    StateRunning  State = "RUNNING"
-    StateAborting State = "ABORTING"
-    StateStopping State = "STOPPING"
+	StateUnknown  = gocontainer.StateUnknown
+	StateStopped  = gocontainer.StateStopped
+	StateStarting = gocontainer.StateStarting
+	StateRunning  = gocontainer.StateRunning
+	StateAborting = gocontainer.StateAborting
+	StateStopping = gocontainer.StateStopping
+	StateFrozen   = gocontainer.StateFrozen
 )
 
-// LogLevel represents a container's log level.
-type LogLevel string
-
 const (
-	LogDebug    LogLevel = "DEBUG"
-	LogInfo     LogLevel = "INFO"
-	LogNotice   LogLevel = "NOTICE"
-	LogWarning  LogLevel = "WARN"
-	LogError    LogLevel = "ERROR"
-	LogCritical LogLevel = "CRIT"
-	LogFatal    LogLevel = "FATAL"
+	LogDebug    = gocontainer.LogDebug
+	LogInfo     = gocontainer.LogInfo
+	LogNotice   = gocontainer.LogNotice
+	LogWarning  = gocontainer.LogWarning
+	LogError    = gocontainer.LogError
+	LogCritical = gocontainer.LogCritical
+	LogFatal    = gocontainer.LogFatal
 )
 
-// Container represents a linux container instance and provides
-// operations to create, maintain and destroy the container.
-type Container interface {
-
-	// Name returns the name of the container.
-	Name() string
-
-    // After creation, change Name() to docker container id
-    SetId(id string)
-
-	// Create creates a new container based on the given template.
-	Create(configFile, template string, templateArgs ...string) error
-
-	// Start runs the container as a daemon.
-	Start(configFile, consoleFile string) error
-
-	// Stop terminates the running container.
-	Stop() error
-
-	// Clone creates a copy of the container, giving the copy the specified name.
-	Clone(name string) (Container, error)
-
-	// Freeze freezes all the container's processes.
-	Freeze() error
-
-	// Unfreeze thaws all frozen container's processes.
-	Unfreeze() error
-
-	// Destroy stops and removes the container.
-	Destroy() error
-
-	// Wait waits for one of the specified container states.
-	Wait(states ...State) error
-
-	// Info returns the status and the process id of the container.
-	Info() (State, int, error)
-
-	// IsConstructed checks if the container image exists.
-	IsConstructed() bool
-
-	// IsRunning checks if the state of the container is 'RUNNING'.
-	IsRunning() bool
-
-	// String returns information about the container, like the name, state,
-	// and process id.
-	String() string
+// capabilities lists the optional features provided by docker.
+var capabilities = []gocontainer.Capability{
+	gocontainer.Freezable,
+	gocontainer.Cloneable,
+	gocontainer.Snapshot,
+	gocontainer.LiveSnapshot,
+	gocontainer.ImageBased,
+}
 
-	// LogFile returns the current filename used for the LogFile.
-	LogFile() string
+func init() {
+	gocontainer.RegisterBackend("docker", Factory())
+}
 
-	// LogLevel returns the current logging level (only used if the
-	// LogFile is not "").
-	LogLevel() LogLevel
+var (
+	// pollInterval is how often Wait checks the container state.
+	pollInterval = 100 * time.Millisecond
+	// stopTimeout is how long the daemon waits for a container to stop
+	// before killing it.
+	stopTimeout = 10 * time.Second
+	// waitTimeout is how long Wait waits for the container to reach
+	// one of the requested states.
+	waitTimeout = 30 * time.Second
+)
 
-	// SetLogFile sets both the LogFile and LogLevel.
-	SetLogFile(filename string, level LogLevel)
+// backupTag is the tag of the image a container is committed to before
+// it is restored from a snapshot, so that it can be put back if the
+// restore fails.
+const backupTag = "restore-backup"
 
-    // Get container informations
-    GetKeyInfo(key string) (interface{}, error)
+// Inspector is implemented by the docker containers, giving access to
+// the details the daemon holds about them.
+type Inspector interface {
+	// Inspect returns the details the daemon holds about the container.
+	Inspect() (*ContainerInfo, error)
 }
 
-// ContainerFactory represents the methods used to create Containers.
-type ContainerFactory interface {
-	// New returns a container instance which can then be used for operations
-	// like Create(), Start(), Stop() or Destroy().
-	New(string) Container
+var (
+	_ Inspector               = (*container)(nil)
+	_ gocontainer.Snapshotter = (*container)(nil)
+)
 
-	// List returns all the existing containers on the system.
-	List() ([]Container, error)
+// Factory provides the standard ContainerFactory, talking to the
+// docker daemon on the default socket.
+func Factory() gocontainer.ContainerFactory {
+	return NewFactory(NewClient(DefaultSocket))
 }
 
-// Factory provides the standard ContainerFactory.
-func Factory() ContainerFactory {
-	return &containerFactory{}
+// NewFactory returns a ContainerFactory whose containers are managed
+// through the given client.
+func NewFactory(client *Client) gocontainer.ContainerFactory {
+	return &containerFactory{client}
 }
 
 type container struct {
+	client   *Client
 	name     string
-	nickname string
 	logFile  string
-	logLevel LogLevel
+	logLevel gocontainer.LogLevel
 }
 
-type containerFactory struct{}
+type containerFactory struct {
+	client *Client
+}
 
-func (*containerFactory) New(name string) Container {
+func (factory *containerFactory) New(name string) gocontainer.Container {
 	return &container{
-		name     : name,
-		nickname : name,
-		logLevel : LogWarning,
+		client:   factory.client,
+		name:     name,
+		logLevel: LogWarning,
 	}
 }
 
-func (factory *containerFactory) List() ([]Container, error) {
-    out, err := run("docker", "ps", "-a")
-    if err != nil {
-        return nil, err
-    }
-    names := nameSet(out)
-	containers := make([]Container, len(names))
-	for i, name := range names {
-		containers[i] = factory.New(name)
+// Capabilities returns the optional features provided by docker.
+func (*containerFactory) Capabilities() []gocontainer.Capability {
+	return capabilities
+}
+
+// List returns all the existing containers on the system.
+func (factory *containerFactory) List() ([]gocontainer.Container, error) {
+	apiContainers, err := factory.client.ListContainers(true)
+	if err != nil {
+		return nil, err
+	}
+	var containers []gocontainer.Container
+	for _, apiContainer := range apiContainers {
+		name := apiContainer.ID
+		if len(apiContainer.Names) > 0 {
+			name = strings.TrimPrefix(apiContainer.Names[0], "/")
+		}
+		containers = append(containers, factory.New(name))
 	}
 	return containers, nil
 }
 
 // Name returns the name of the container.
 func (c *container) Name() string {
-	return c.nickname
-}
-
-func (c *container) SetId(id string) {
-    c.name = id
+	return c.name
 }
 
 // LogFile returns the current filename used for the LogFile.
@@ -181,97 +143,75 @@ func (c *container) LogFile() string {
 
 // LogLevel returns the current logging level, this is only used if the
 // LogFile is not "".
-func (c *container) LogLevel() LogLevel {
+func (c *container) LogLevel() gocontainer.LogLevel {
 	return c.logLevel
 }
 
 // SetLogFile sets both the LogFile and LogLevel.
-func (c *container) SetLogFile(filename string, level LogLevel) {
+func (c *container) SetLogFile(filename string, level gocontainer.LogLevel) {
 	c.logFile = filename
 	c.logLevel = level
 }
 
-// Create creates a new container based on the given template.
+// Create creates a new container from the given image. The image and
+// command given override those of the config file.
 func (c *container) Create(configFile, template string, templateArgs ...string) error {
-    //NOTE 1st pass: configFile and templateArgs ignored
-    configFile = ""
-    templateArgs = []string{}
-    //NOTE run -h hostname can be used with hostid in templateArgs
-
-    // template = image ! ex: ubuntu or app/node-js-sample
-    // series the tag !   ex: v1 in app/node-js-sample:v1
-
-    /*
-     * Is template:(-r series) on the system ?
-     *  oui, mais pas container    -> docker run template:series /bin/bash -c "/check_validity.sh"
-     *  oui, mais container & run  -> alors erreur "already created and runnning"
-     *                      & stop -> 'void'
-     *  !!TODO : if template exist but series doesn't 
-     *  
-     *  non     -> docker build -t c.name dir/with/dockerfile_qui_contient | url
-     *  ou: non -> docker pull c.name && docker run c.name /bin/bash -c "echo \"pouet\""
-     */
-    if ! IsExisting(template) {
-		return fmt.Errorf("No base image found for container %q", c.Name())
-    }
-
 	if c.IsConstructed() {
-		return fmt.Errorf("container %q is already created", c.Name())
+		return fmt.Errorf("container %q is already created", c.name)
 	}
-
-    cid_filename_array := []string{"/tmp/", c.name, ".cid"}
-    cid_filename := strings.Join(cid_filename_array, "")
-	args := []string{
-        "run", "-d",
-        "-cidfile", cid_filename,
-        template,
-        "/bin/bash", "-c", "\"ls\"",
+	exists, err := c.client.ImageExists(template)
+	if err != nil {
+		return err
 	}
-
+	if !exists {
+		return fmt.Errorf("no image %q found for container %q", template, c.name)
+	}
+	config := &Config{}
 	if configFile != "" {
-		args = append(args, "-f", configFile)
+		data, err := ioutil.ReadFile(configFile)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, config); err != nil {
+			return fmt.Errorf("cannot parse container config %q: %v", configFile, err)
+		}
 	}
-	if len(templateArgs) != 0 {
-        args = append(args, "--")
-		args = append(args, templateArgs...)
+	if config.Hostname == "" {
+		config.Hostname = c.name
 	}
-	_, err := run("docker", args...)
-	if err != nil {
-		return err
+	config.Image = template
+	if len(templateArgs) != 0 {
+		config.Cmd = templateArgs
 	}
-
-    id, err := ioutil.ReadFile(cid_filename)
-    if err != nil {
-        panic(err)
-    }
-    c.SetId(string(id))
-	return nil
+	_, err = c.client.CreateContainer(c.name, config)
+	return err
 }
 
-// Start runs the container as a daemon.
+// Start runs the container as a daemon. Docker captures the console
+// output itself, so no config or console file is accepted.
 func (c *container) Start(configFile, consoleFile string) error {
-    // With docker log and config files ahve automatic location
+	if configFile != "" || consoleFile != "" {
+		return fmt.Errorf("container %q: docker does not support config or console files", c.name)
+	}
 	if !c.IsConstructed() {
 		return fmt.Errorf("container %q is not yet created", c.name)
 	}
-	args := []string{
-		"--daemon",
-		"-n", c.name,
-	}
-	if configFile != "" {
-		args = append(args, "-f", configFile)
-	}
-	if consoleFile != "" {
-		args = append(args, "-c", consoleFile)
-	}
-	if c.logFile != "" {
-		args = append(args, "-o", c.logFile, "-l", string(c.logLevel))
+	if err := c.client.StartContainer(c.name, nil); err != nil {
+		return err
 	}
-	_, err := run("docker run", args...)
-	if err != nil {
+	// The daemon only returns once the command of the container has
+	// been started, so a container that is not running by now has
+	// already exited.
+	info, err := c.Inspect()
+	if IsNotFound(err) {
+		return fmt.Errorf("container %q was removed while starting", c.name)
+	} else if err != nil {
 		return err
 	}
-	return c.Wait(StateRunning)
+	if !info.State.Running {
+		return fmt.Errorf("container %q exited with code %d after starting", c.name, info.State.ExitCode)
+	}
+	return nil
 }
 
 // Stop terminates the running container.
@@ -279,46 +219,131 @@ func (c *container) Stop() error {
 	if !c.IsConstructed() {
 		return fmt.Errorf("container %q is not yet created", c.name)
 	}
-	args := []string{
-		"stop", c.name,
-	}
-	_, err := run("docker", args...)
-	if err != nil {
+	if err := c.client.StopContainer(c.name, stopTimeout); err != nil {
 		return err
 	}
 	return c.Wait(StateStopped)
 }
 
-// Clone creates a copy of the container, it gets the given name.
-func (c *container) Clone(name string) (Container, error) {
-	if !c.IsConstructed() {
+// Clone creates a copy of the container, it gets the given name. The
+// container is committed to an image of the same name, from which the
+// copy is created.
+func (c *container) Clone(name string) (gocontainer.Container, error) {
+	info, err := c.Inspect()
+	if IsNotFound(err) {
 		return nil, fmt.Errorf("container %q is not yet created", c.name)
+	} else if err != nil {
+		return nil, err
 	}
 	cc := &container{
-		name: name,
+		client:   c.client,
+		name:     name,
+		logLevel: LogWarning,
 	}
 	if cc.IsConstructed() {
 		return cc, nil
 	}
-	args := []string{
-		c.name,
-		name,
-	}
-	_, err := run("docker commit", args...)
+	image, err := c.client.CommitContainer(c.name, name, "")
 	if err != nil {
 		return nil, err
 	}
+	config := &Config{
+		Hostname: name,
+		Image:    image,
+	}
+	if info.Config != nil {
+		config.Cmd = info.Config.Cmd
+		config.Env = info.Config.Env
+	}
+	if _, err := c.client.CreateContainer(name, config); err != nil {
+		return nil, err
+	}
 	return cc, nil
 }
 
-// Docker doesn't need you !
+// Snapshot commits the container to an image tagged with the given
+// name, in the repository named after the container.
+func (c *container) Snapshot(name string) error {
+	if !c.IsConstructed() {
+		return fmt.Errorf("container %q is not yet created", c.name)
+	}
+	_, err := c.client.CommitContainer(c.name, c.name, name)
+	return err
+}
+
+// Restore replaces the container with one created from the image of
+// the named snapshot, keeping its configuration. The container is
+// committed to a backup image first, from which it is created again if
+// the snapshot cannot be restored; the backup image is removed once the
+// container is restored.
+func (c *container) Restore(name string) error {
+	info, err := c.Inspect()
+	if IsNotFound(err) {
+		return fmt.Errorf("container %q is not yet created", c.name)
+	} else if err != nil {
+		return err
+	}
+	if info.State.Running {
+		return fmt.Errorf("container %q is running", c.name)
+	}
+	image := c.name + ":" + name
+	exists, err := c.client.ImageExists(image)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("no snapshot %q found for container %q", name, c.name)
+	}
+	config := &Config{}
+	if info.Config != nil {
+		*config = *info.Config
+	}
+	config.HostConfig = info.HostConfig
+	backup, err := c.client.CommitContainer(c.name, c.name, backupTag)
+	if err != nil {
+		return fmt.Errorf("cannot back up container %q: %v", c.name, err)
+	}
+	backupConfig := *config
+	backupConfig.Image = backup
+	config.Image = image
+	if err := c.client.RemoveContainer(c.name); err != nil {
+		return err
+	}
+	if _, err := c.client.CreateContainer(c.name, config); err != nil {
+		if _, berr := c.client.CreateContainer(c.name, &backupConfig); berr != nil {
+			return fmt.Errorf("cannot restore container %q: %v (and cannot re-create it from its backup %q: %v)", c.name, err, backup, berr)
+		}
+		return fmt.Errorf("cannot restore container %q: %v", c.name, err)
+	}
+	if err := c.client.RemoveImage(c.name + ":" + backupTag); err != nil {
+		return fmt.Errorf("cannot remove backup image of container %q: %v", c.name, err)
+	}
+	return nil
+}
+
+// Freeze freezes all the container's processes.
 func (c *container) Freeze() error {
-    return fmt.Errorf("not implemented")
+	if !c.IsConstructed() {
+		return fmt.Errorf("container %q is not yet created", c.name)
+	}
+	if !c.IsRunning() {
+		return fmt.Errorf("container %q is not running", c.name)
+	}
+	return c.client.PauseContainer(c.name)
 }
 
-// Docker doesn't need you !
+// Unfreeze thaws all frozen container's processes.
 func (c *container) Unfreeze() error {
-    return fmt.Errorf("not implemented")
+	state, _, err := c.Info()
+	if IsNotFound(err) {
+		return fmt.Errorf("container %q is not yet created", c.name)
+	} else if err != nil {
+		return err
+	}
+	if state != StateFrozen {
+		return fmt.Errorf("container %q is not frozen", c.name)
+	}
+	return c.client.UnpauseContainer(c.name)
 }
 
 // Destroy stops and removes the container.
@@ -326,78 +351,72 @@ func (c *container) Destroy() error {
 	if !c.IsConstructed() {
 		return fmt.Errorf("container %q is not yet created", c.name)
 	}
-	if err := c.Stop(); err != nil {
-		return err
-	}
-    _, err := run("docker", "rm", c.name)
-	if err != nil {
-		return err
+	if c.IsRunning() {
+		if err := c.Stop(); err != nil {
+			return err
+		}
 	}
-	return nil
+	return c.client.RemoveContainer(c.name)
 }
 
-// Wait waits for one of the specified container states.
-func (c *container) Wait(states ...State) error {
-    //NOTE Only STOPPED state is implemented
+// Wait waits for one of the specified container states. A container
+// that does not exist is only waited for when it is in the stopped
+// state, and the wait fails if no state is reached within waitTimeout.
+func (c *container) Wait(states ...gocontainer.State) error {
 	if len(states) == 0 {
 		return fmt.Errorf("no states specified")
 	}
-	stateStrs := make([]string, len(states))
-	for i, state := range states {
-        //TODO Check if STOPPED is present
-		stateStrs[i] = string(state)
-	}
-	waitStates := strings.Join(stateStrs, "|")
-    fmt.Printf(waitStates)  //my awesome log
-	_, err := run("docker", "wait", c.name) 
-	if err != nil {
-		return err
+	timeout := time.After(waitTimeout)
+	for {
+		state, _, err := c.Info()
+		missing := IsNotFound(err)
+		if err != nil && !missing {
+			return err
+		}
+		for _, s := range states {
+			if s == state {
+				return nil
+			}
+		}
+		if missing {
+			return fmt.Errorf("container %q does not exist", c.name)
+		}
+		select {
+		case <-time.After(pollInterval):
+		case <-timeout:
+			return fmt.Errorf("timed out waiting for container %q to be %v", c.name, states)
+		}
 	}
-	return nil
 }
 
 // Info returns the status and the process id of the container.
-func (c *container) Info() (State, int, error) {
-    //TODO Use GetKeyInfo
-    // The pid first
-    args := []string{"-ef", "|", "grep", c.name, "|", "grev", "-v", "grep", "|", "awk", "'{printf $2}'"}
-	pid_str, err := run("ps", args...)
-	if err != nil {
+func (c *container) Info() (gocontainer.State, int, error) {
+	info, err := c.Inspect()
+	if IsNotFound(err) {
+		// A container that does not exist is reported as stopped,
+		// matching lxc.
+		return StateStopped, -1, err
+	} else if err != nil {
 		return StateUnknown, -1, err
 	}
-
-    // And now container state
-    state := State("UNKNOWN")
-    if out, _ := run("docker", "ps", "|", "grep", c.name); out != "" {
-        state = State("RUNNING")
-    } else if out, _ := run("docker", "ps", "-a", "|", "grep", c.name); out != "" {
-        state = State("STOPPED")
-    }
-
-	pid, err := strconv.Atoi(pid_str)
-	if err != nil {
-		return StateUnknown, -1, fmt.Errorf("cannot read the pid: %v", err)
+	switch {
+	case info.State.Paused:
+		return StateFrozen, info.State.Pid, nil
+	case info.State.Running:
+		return StateRunning, info.State.Pid, nil
 	}
-	return state, pid, nil
+	return StateStopped, -1, nil
 }
 
-// IsConstructed checks if the container image exists.
-func (c *container) IsConstructed() bool {
-    out, err := run("docker", "ps", "-a", "|", "grep", c.name)
-	if out == "" || err != nil {
-		return false
-	}
-	return true
+// Inspect returns the details the daemon holds about the container.
+func (c *container) Inspect() (*ContainerInfo, error) {
+	return c.client.InspectContainer(c.name)
 }
 
-// IsExisting checks if the image exists.
-func IsExisting(name string) bool {
-    //TODO Check repos as well: return false if not on the system and pull fails
-    out, err := run("docker", "images", "|", "grep", name)
-	if out == "" || err != nil {
-		return false
-	}
-	return true
+// IsConstructed checks if the container exists.
+func (c *container) IsConstructed() bool {
+	_, err := c.Inspect()
+	return err == nil
 }
 
 // IsRunning checks if the state of the container is 'RUNNING'.
@@ -417,111 +436,3 @@ func (c *container) String() string {
 	}
 	return fmt.Sprintf("container %q (%s, pid %d)", c.name, state, pid)
 }
-
-// containerHome returns the name of the container directory.
-func (c *container) containerHome() string {
-	return "/var/lib/lxc/" + c.name
-}
-
-// rootfs returns the name of the directory containing the
-// root filesystem of the container.
-func (c *container) rootfs() string {
-	return c.containerHome() + "/rootfs/"
-}
-
-func (c *container) GetKeyInfo(key string) (interface{}, error) {
-    args := []string{"inspect", c.name}
-	cmd := exec.Command("docker", args...)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", runError("docker", err, out)
-	}
-
-    // Parse the json answer
-    var f interface{}
-    //NOTE Trick to avoid root array
-    json.Unmarshal(out[1:len(out)-1], &f)
-    msg := f.(map[string]interface{})
-
-    return msg[key], nil
-}
-
-// run executes the passed command and returns the out.
-func run(name string, args ...string) (string, error) {
-	cmd := exec.Command(name, args...)
-	// LXC tools do not use stdout and stderr in a predictable
-	// way; based on experimentation, the most convenient
-	// solution is to combine them and leave the client to
-	// determine sanity as best it can.
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", runError(name, err, out)
-	}
-	return string(out), nil
-}
-
-// runError creates an error if run fails.
-func runError(name string, err error, out []byte) error {
-	e := &Error{name, err, nil}
-	for _, l := range strings.Split(string(out), "\n") {
-		if strings.HasPrefix(l, name+": ") {
-			// LXC tools do not always print their output with
-			// the command name as prefix. The name is part of
-			// the error struct, so stip it from the output if
-			// printed.
-			l = l[len(name)+2:]
-		}
-		if l != "" {
-			e.Output = append(e.Output, l)
-		}
-	}
-	return e
-}
-
-// nameSet retrieves a set of names out of a command out.
-func nameSet(raw string) []string {
-    collector := map[string]struct{}{}
-    set := []string{}
-	lines := strings.Split(raw, "\n")
-	for i, line := range lines {
-        // Skip header and last empty line
-        if (i != 0 && i < 6) {
-            fields := strings.Fields(line)
-            var name string = fields[0]
-            if name != "" {
-                collector[name] = struct{}{}
-            }
-        }
-    }
-    for name := range collector {
-        set = append(set, name)
-    }
-    return set
-}
-
-type LXCSuite struct {
-    factory ContainerFactory
-}
-
-func main() {
-    var f containerFactory = containerFactory{}
-	ids, err := f.List()
-    if (err != nil) {
-        fmt.Println("ERROR")
-    }
-    for _, box := range ids {
-        if box.IsConstructed() {
-            fmt.Printf("Box %s exists\n", box.Name())
-            id, _ := box.GetKeyInfo("ID")
-            fmt.Printf("\tId:\n", id)
-            path, _ := box.GetKeyInfo("Path")
-            fmt.Printf("\tPath:\n", path)
-            state, _ := box.GetKeyInfo("State")
-            fmt.Printf("\tState:\n", state)
-            if box.Name() == "6ef7bc15a11b" {
-                fmt.Println("Stopping This f*** box")
-                //box.Stop()
-            }
-        }
-    }
-}
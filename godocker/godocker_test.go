@@ -1,189 +1,490 @@
 package godocker_test
 
 import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
 	"io/ioutil"
-	. "launchpad.net/gocheck"
-	"os"
-	"os/user"
 	"path/filepath"
 	"testing"
+	"time"
+
+	. "launchpad.net/gocheck"
 
+	"launchpad.net/gocontainer"
 	"launchpad.net/godocker"
 )
 
 func Test(t *testing.T) { TestingT(t) }
 
-type ConfigSuite struct{}
+type DockerSuite struct {
+	daemon          *fakeDaemon
+	client          *godocker.Client
+	factory         gocontainer.ContainerFactory
+	oldPollInterval time.Duration
+}
 
-var _ = Suite(&ConfigSuite{})
+var _ = Suite(&DockerSuite{})
 
-type LXCSuite struct {
-	factory godocker.ContainerFactory
+func (s *DockerSuite) SetUpSuite(c *C) {
+	s.oldPollInterval = godocker.SetPollInterval(time.Millisecond)
 }
 
-var _ = Suite(&LXCSuite{godocker.Factory()})
+func (s *DockerSuite) TearDownSuite(c *C) {
+	godocker.SetPollInterval(s.oldPollInterval)
+}
 
-func (s *LXCSuite) SetUpSuite(c *C) {
-	u, err := user.Current()
-	c.Assert(err, IsNil)
-	if u.Uid != "0" {
-		// Has to be run as root!
-		c.Skip("tests must run as root")
-	}
+func (s *DockerSuite) SetUpTest(c *C) {
+	s.daemon = startFakeDaemon(c, c.MkDir(), "ubuntu")
+	s.client = godocker.NewClient(s.daemon.socket)
+	s.factory = godocker.NewFactory(s.client)
 }
 
-func (s *LXCSuite) TestCreateDestroy(c *C) {
+func (s *DockerSuite) TearDownTest(c *C) {
+	s.daemon.Close()
+}
+
+func (s *DockerSuite) TestCreateDestroy(c *C) {
 	// Test clean creation and destroying of a container.
-	lc := s.factory.New("godocker")
-	c.Assert(lc.IsConstructed(), Equals, false)
-	home := godocker.ContainerHome(lc)
-	_, err := os.Stat(home)
-	c.Assert(err, ErrorMatches, "stat .*: no such file or directory")
-	err = lc.Create("", "ubuntu")
-	c.Assert(err, IsNil)
-	c.Assert(lc.IsConstructed(), Equals, true)
-	defer func() {
-		err = lc.Destroy()
-		c.Assert(err, IsNil)
-		_, err = os.Stat(home)
-		c.Assert(err, ErrorMatches, "stat .*: no such file or directory")
-	}()
-	fi, err := os.Stat(godocker.ContainerHome(lc))
+	dc := s.factory.New("godocker")
+	c.Assert(dc.IsConstructed(), Equals, false)
+	err := dc.Create("", "ubuntu", "/sbin/init")
+	c.Assert(err, IsNil)
+	c.Assert(dc.IsConstructed(), Equals, true)
+	info, err := inspect(dc)
 	c.Assert(err, IsNil)
-	c.Assert(fi.IsDir(), Equals, true)
+	c.Assert(info.Config.Image, Equals, "ubuntu")
+	c.Assert(info.Config.Cmd, DeepEquals, []string{"/sbin/init"})
+	c.Assert(info.Config.Hostname, Equals, "godocker")
+	err = dc.Destroy()
+	c.Assert(err, IsNil)
+	c.Assert(dc.IsConstructed(), Equals, false)
 }
 
-func (s *LXCSuite) TestCreateTwice(c *C) {
+func (s *DockerSuite) TestCreateTwice(c *C) {
 	// Test that a container cannot be created twice.
-	lc1 := s.factory.New("godocker")
-	c.Assert(lc1.IsConstructed(), Equals, false)
-	err := lc1.Create("", "ubuntu")
-	c.Assert(err, IsNil)
-	c.Assert(lc1.IsConstructed(), Equals, true)
-	defer func() {
-		c.Assert(lc1.Destroy(), IsNil)
-	}()
-	lc2 := s.factory.New("godocker")
-	err = lc2.Create("", "ubuntu")
-	c.Assert(err, ErrorMatches, "container .* is already created")
+	dc1 := s.factory.New("godocker")
+	c.Assert(dc1.Create("", "ubuntu"), IsNil)
+	dc2 := s.factory.New("godocker")
+	err := dc2.Create("", "ubuntu")
+	c.Assert(err, ErrorMatches, `container "godocker" is already created`)
 }
 
-func (s *LXCSuite) TestCreateIllegalTemplate(c *C) {
+func (s *DockerSuite) TestCreateIllegalImage(c *C) {
 	// Test that a container creation fails correctly in
-	// case of an illegal template.
-	lc := s.factory.New("godocker")
-	c.Assert(lc.IsConstructed(), Equals, false)
-	err := lc.Create("", "name-of-a-not-existing-template-for-godocker")
-	c.Assert(err, ErrorMatches, `error executing "lxc-create": No config file specified, .*`)
-	c.Assert(lc.IsConstructed(), Equals, false)
+	// case of a missing image.
+	dc := s.factory.New("godocker")
+	err := dc.Create("", "name-of-a-not-existing-image-for-godocker")
+	c.Assert(err, ErrorMatches, `no image "name-of-a-not-existing-image-for-godocker" found for container "godocker"`)
+	c.Assert(dc.IsConstructed(), Equals, false)
 }
 
-func (s *LXCSuite) TestDestroyNotCreated(c *C) {
+func (s *DockerSuite) TestCreateWithConfigFile(c *C) {
+	configFile := filepath.Join(c.MkDir(), "docker.json")
+	config := `{"Hostname": "box", "Image": "ignored", "Cmd": ["/bin/true"], "HostConfig": {"Binds": ["/tmp:/mnt:ro"]}}`
+	c.Assert(ioutil.WriteFile(configFile, []byte(config), 0644), IsNil)
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create(configFile, "ubuntu", "/sbin/init"), IsNil)
+	info, err := inspect(dc)
+	c.Assert(err, IsNil)
+	c.Assert(info.Config.Hostname, Equals, "box")
+	c.Assert(info.Config.Image, Equals, "ubuntu")
+	c.Assert(info.Config.Cmd, DeepEquals, []string{"/sbin/init"})
+	c.Assert(info.Config.HostConfig.Binds, DeepEquals, []string{"/tmp:/mnt:ro"})
+}
+
+func (s *DockerSuite) TestCreateWithBadConfigFile(c *C) {
+	configFile := filepath.Join(c.MkDir(), "docker.json")
+	c.Assert(ioutil.WriteFile(configFile, []byte("lxc.network.type = veth"), 0644), IsNil)
+	dc := s.factory.New("godocker")
+	err := dc.Create(configFile, "ubuntu")
+	c.Assert(err, ErrorMatches, `cannot parse container config ".*/docker.json": .*`)
+	c.Assert(dc.IsConstructed(), Equals, false)
+}
+
+func (s *DockerSuite) TestDestroyNotCreated(c *C) {
 	// Test that a non-existing container can't be destroyed.
-	lc := s.factory.New("godocker")
-	c.Assert(lc.IsConstructed(), Equals, false)
-	err := lc.Destroy()
-	c.Assert(err, ErrorMatches, "container .* is not yet created")
+	dc := s.factory.New("godocker")
+	err := dc.Destroy()
+	c.Assert(err, ErrorMatches, `container "godocker" is not yet created`)
+}
+
+func inspect(dc gocontainer.Container) (*godocker.ContainerInfo, error) {
+	return dc.(godocker.Inspector).Inspect()
 }
 
-func contains(lcs []godocker.Container, lc godocker.Container) bool {
-	for _, clc := range lcs {
-		if clc.Name() == lc.Name() {
+func contains(dcs []gocontainer.Container, dc gocontainer.Container) bool {
+	for _, cdc := range dcs {
+		if cdc.Name() == dc.Name() {
 			return true
 		}
 	}
 	return false
 }
 
-func (s *LXCSuite) TestList(c *C) {
+func (s *DockerSuite) TestList(c *C) {
 	// Test the listing of created containers.
-	lcs, err := s.factory.List()
-	oldLen := len(lcs)
-	c.Assert(err, IsNil)
-	c.Assert(oldLen >= 0, Equals, true)
-	lc := s.factory.New("godocker")
-	c.Assert(lc.IsConstructed(), Equals, false)
-	c.Assert(lc.Create("", "ubuntu"), IsNil)
-	c.Assert(lc.IsConstructed(), Equals, true)
-	defer func() {
-		c.Assert(lc.Destroy(), IsNil)
-	}()
-	lcs, _ = s.factory.List()
-	newLen := len(lcs)
-	c.Assert(newLen == oldLen+1, Equals, true)
-	c.Assert(contains(lcs, lc), Equals, true)
+	dcs, err := s.factory.List()
+	c.Assert(err, IsNil)
+	c.Assert(dcs, HasLen, 0)
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create("", "ubuntu"), IsNil)
+	dcs, err = s.factory.List()
+	c.Assert(err, IsNil)
+	c.Assert(dcs, HasLen, 1)
+	c.Assert(contains(dcs, dc), Equals, true)
 }
 
-func (s *LXCSuite) TestClone(c *C) {
+func (s *DockerSuite) TestClone(c *C) {
 	// Test the cloning of an existing container.
-	lc1 := s.factory.New("godocker")
-	c.Assert(lc1.IsConstructed(), Equals, false)
-	c.Assert(lc1.Create("", "ubuntu"), IsNil)
-	c.Assert(lc1.IsConstructed(), Equals, true)
-	defer func() {
-		c.Assert(lc1.Destroy(), IsNil)
-	}()
-	lcs, _ := s.factory.List()
-	oldLen := len(lcs)
-	lc2, err := lc1.Clone("godockerclone")
+	dc1 := s.factory.New("godocker")
+	c.Assert(dc1.Create("", "ubuntu", "/sbin/init"), IsNil)
+	dc2, err := dc1.Clone("godockerclone")
 	c.Assert(err, IsNil)
-	c.Assert(lc2.IsConstructed(), Equals, true)
-	defer func() {
-		c.Assert(lc2.Destroy(), IsNil)
-	}()
-	lcs, _ = s.factory.List()
-	newLen := len(lcs)
-	c.Assert(newLen == oldLen+1, Equals, true)
-	c.Assert(contains(lcs, lc1), Equals, true)
-	c.Assert(contains(lcs, lc2), Equals, true)
+	c.Assert(dc2.Name(), Equals, "godockerclone")
+	c.Assert(dc2.IsConstructed(), Equals, true)
+	info, err := inspect(dc2)
+	c.Assert(err, IsNil)
+	c.Assert(info.Config.Image, Equals, "godockerclone")
+	c.Assert(info.Config.Cmd, DeepEquals, []string{"/sbin/init"})
+	dcs, err := s.factory.List()
+	c.Assert(err, IsNil)
+	c.Assert(dcs, HasLen, 2)
+	c.Assert(contains(dcs, dc1), Equals, true)
+	c.Assert(contains(dcs, dc2), Equals, true)
+}
+
+func (s *DockerSuite) TestCloneNotCreated(c *C) {
+	dc := s.factory.New("godocker")
+	_, err := dc.Clone("godockerclone")
+	c.Assert(err, ErrorMatches, `container "godocker" is not yet created`)
+}
+
+func (s *DockerSuite) TestCapabilities(c *C) {
+	for _, capability := range []gocontainer.Capability{
+		gocontainer.Freezable,
+		gocontainer.Cloneable,
+		gocontainer.Snapshot,
+		gocontainer.LiveSnapshot,
+		gocontainer.ImageBased,
+	} {
+		c.Assert(gocontainer.HasCapability(s.factory, capability), Equals, true)
+	}
+	factory, err := gocontainer.Backend("docker")
+	c.Assert(err, IsNil)
+	c.Assert(factory.Capabilities(), DeepEquals, s.factory.Capabilities())
+}
+
+func (s *DockerSuite) TestSnapshotRestore(c *C) {
+	configFile := filepath.Join(c.MkDir(), "docker.json")
+	config := `{"HostConfig": {"Binds": ["/tmp:/mnt:ro"]}}`
+	c.Assert(ioutil.WriteFile(configFile, []byte(config), 0644), IsNil)
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create(configFile, "ubuntu", "/sbin/init"), IsNil)
+	snapshotter := dc.(gocontainer.Snapshotter)
+	c.Assert(snapshotter.Snapshot("before"), IsNil)
+	c.Assert(s.daemon.images["godocker:before"], Equals, true)
+
+	c.Assert(snapshotter.Restore("before"), IsNil)
+	info, err := inspect(dc)
+	c.Assert(err, IsNil)
+	c.Assert(info.Config.Image, Equals, "godocker:before")
+	c.Assert(info.Config.Cmd, DeepEquals, []string{"/sbin/init"})
+	c.Assert(info.HostConfig.Binds, DeepEquals, []string{"/tmp:/mnt:ro"})
+	c.Assert(s.daemon.images["godocker:restore-backup"], Equals, false)
+}
+
+func (s *DockerSuite) TestSnapshotNotCreated(c *C) {
+	dc := s.factory.New("godocker")
+	err := dc.(gocontainer.Snapshotter).Snapshot("before")
+	c.Assert(err, ErrorMatches, `container "godocker" is not yet created`)
+}
+
+func (s *DockerSuite) TestRestoreRunning(c *C) {
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create("", "ubuntu"), IsNil)
+	snapshotter := dc.(gocontainer.Snapshotter)
+	c.Assert(snapshotter.Snapshot("before"), IsNil)
+	c.Assert(dc.Start("", ""), IsNil)
+	err := snapshotter.Restore("before")
+	c.Assert(err, ErrorMatches, `container "godocker" is running`)
+}
+
+func (s *DockerSuite) TestRestoreUnknownSnapshot(c *C) {
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create("", "ubuntu"), IsNil)
+	err := dc.(gocontainer.Snapshotter).Restore("missing")
+	c.Assert(err, ErrorMatches, `no snapshot "missing" found for container "godocker"`)
+	c.Assert(dc.IsConstructed(), Equals, true)
 }
 
-func (s *LXCSuite) TestStartStop(c *C) {
+func (s *DockerSuite) TestRestoreFailureKeepsContainer(c *C) {
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create("", "ubuntu", "/sbin/init"), IsNil)
+	snapshotter := dc.(gocontainer.Snapshotter)
+	c.Assert(snapshotter.Snapshot("before"), IsNil)
+	s.daemon.brokenImages["godocker:before"] = true
+	err := snapshotter.Restore("before")
+	c.Assert(err, ErrorMatches, `cannot restore container "godocker": .*Cannot mount image: godocker:before`)
+	info, err := inspect(dc)
+	c.Assert(err, IsNil)
+	c.Assert(info.Config.Image, Equals, "godocker:restore-backup")
+	c.Assert(info.Config.Cmd, DeepEquals, []string{"/sbin/init"})
+}
+
+func (s *DockerSuite) TestStartStop(c *C) {
 	// Test starting and stopping a container.
-	lc := s.factory.New("godocker")
-	c.Assert(lc.IsConstructed(), Equals, false)
-	c.Assert(lc.Create("", "ubuntu"), IsNil)
-	defer func() {
-		c.Assert(lc.Destroy(), IsNil)
-	}()
-	c.Assert(lc.Start("", ""), IsNil)
-	c.Assert(lc.IsRunning(), Equals, true)
-	c.Assert(lc.Stop(), IsNil)
-	c.Assert(lc.IsRunning(), Equals, false)
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create("", "ubuntu"), IsNil)
+	c.Assert(dc.Start("", ""), IsNil)
+	c.Assert(dc.IsRunning(), Equals, true)
+	state, pid, err := dc.Info()
+	c.Assert(err, IsNil)
+	c.Assert(state, Equals, godocker.StateRunning)
+	c.Assert(pid > 0, Equals, true)
+	info, err := inspect(dc)
+	c.Assert(err, IsNil)
+	c.Assert(info.State.Pid, Equals, pid)
+	c.Assert(info.NetworkSettings.IPAddress, Matches, `172\.17\.0\.\d+`)
+	c.Assert(info.NetworkSettings.Bridge, Equals, "docker0")
+	c.Assert(dc.Stop(), IsNil)
+	c.Assert(dc.IsRunning(), Equals, false)
+	state, pid, err = dc.Info()
+	c.Assert(err, IsNil)
+	c.Assert(state, Equals, godocker.StateStopped)
+	c.Assert(pid, Equals, -1)
 }
 
-func (s *LXCSuite) TestStartNotCreated(c *C) {
+func (s *DockerSuite) TestStartRunning(c *C) {
+	// Test that starting a running container is harmless.
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create("", "ubuntu"), IsNil)
+	c.Assert(dc.Start("", ""), IsNil)
+	c.Assert(dc.Start("", ""), IsNil)
+	c.Assert(dc.IsRunning(), Equals, true)
+}
+
+func (s *DockerSuite) TestStartExiting(c *C) {
+	// Test that a container whose command exits at once fails to start.
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create("", "ubuntu", "/bin/false"), IsNil)
+	err := dc.Start("", "")
+	c.Assert(err, ErrorMatches, `container "godocker" exited with code 1 after starting`)
+}
+
+func (s *DockerSuite) TestStartNotCreated(c *C) {
 	// Test that a non-existing container can't be started.
-	lc := s.factory.New("godocker")
-	c.Assert(lc.IsConstructed(), Equals, false)
-	c.Assert(lc.Start("", ""), ErrorMatches, "container .* is not yet created")
-}
-
-func (s *LXCSuite) TestStopNotRunning(c *C) {
-	// Test that a not running container can't be stopped.
-	lc := s.factory.New("godocker")
-	c.Assert(lc.IsConstructed(), Equals, false)
-	c.Assert(lc.Create("", "ubuntu"), IsNil)
-	defer func() {
-		c.Assert(lc.Destroy(), IsNil)
-	}()
-	c.Assert(lc.Stop(), IsNil)
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Start("", ""), ErrorMatches, `container "godocker" is not yet created`)
+}
+
+func (s *DockerSuite) TestStopNotRunning(c *C) {
+	// Test that a not running container can be stopped.
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create("", "ubuntu"), IsNil)
+	c.Assert(dc.Stop(), IsNil)
+}
+
+func (s *DockerSuite) TestDestroyRunning(c *C) {
+	// Test that destroying a running container stops it first.
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create("", "ubuntu"), IsNil)
+	c.Assert(dc.Start("", ""), IsNil)
+	c.Assert(dc.Destroy(), IsNil)
+	c.Assert(dc.IsConstructed(), Equals, false)
+}
+
+func (s *DockerSuite) TestFreezeUnfreeze(c *C) {
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create("", "ubuntu"), IsNil)
+	c.Assert(dc.Freeze(), ErrorMatches, `container "godocker" is not running`)
+	c.Assert(dc.Start("", ""), IsNil)
+	c.Assert(dc.Unfreeze(), ErrorMatches, `container "godocker" is not frozen`)
+	c.Assert(dc.Freeze(), IsNil)
+	state, _, err := dc.Info()
+	c.Assert(err, IsNil)
+	c.Assert(state, Equals, godocker.StateFrozen)
+	c.Assert(dc.IsRunning(), Equals, false)
+	c.Assert(dc.Unfreeze(), IsNil)
+	c.Assert(dc.IsRunning(), Equals, true)
 }
 
-func (s *LXCSuite) TestWait(c *C) {
+func (s *DockerSuite) TestWait(c *C) {
 	// Test waiting for one of a number of states of a container.
-	// ATTN: Using a not reached state blocks the test until timeout!
-	lc := s.factory.New("godocker")
-	c.Assert(lc.IsConstructed(), Equals, false)
-	c.Assert(lc.Wait(), ErrorMatches, "no states specified")
-	c.Assert(lc.Wait(godocker.StateStopped), IsNil)
-	c.Assert(lc.Wait(godocker.StateStopped, godocker.StateRunning), IsNil)
-	c.Assert(lc.Create("", "ubuntu"), IsNil)
-	defer func() {
-		c.Assert(lc.Destroy(), IsNil)
-	}()
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Wait(), ErrorMatches, "no states specified")
+	c.Assert(dc.Wait(godocker.StateStopped), IsNil)
+	c.Assert(dc.Wait(godocker.StateStopped, godocker.StateRunning), IsNil)
+	c.Assert(dc.Create("", "ubuntu"), IsNil)
+	done := make(chan error)
 	go func() {
-		c.Assert(lc.Start("", ""), IsNil)
+		done <- dc.Wait(godocker.StateRunning)
 	}()
-	c.Assert(lc.Wait(godocker.StateRunning), IsNil)
+	c.Assert(s.client.StartContainer("godocker", nil), IsNil)
+	select {
+	case err := <-done:
+		c.Assert(err, IsNil)
+	case <-time.After(5 * time.Second):
+		c.Fatalf("timed out waiting for the container to run")
+	}
+}
+
+func (s *DockerSuite) TestWaitMissing(c *C) {
+	// Test that waiting for a container that does not exist to run fails.
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Wait(godocker.StateRunning), ErrorMatches, `container "godocker" does not exist`)
+}
+
+func (s *DockerSuite) TestWaitTimeout(c *C) {
+	defer godocker.SetWaitTimeout(godocker.SetWaitTimeout(10 * time.Millisecond))
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create("", "ubuntu"), IsNil)
+	err := dc.Wait(godocker.StateRunning)
+	c.Assert(err, ErrorMatches, `timed out waiting for container "godocker" to be \[RUNNING\]`)
+}
+
+func (s *DockerSuite) TestString(c *C) {
+	dc := s.factory.New("godocker")
+	c.Assert(dc.Create("", "ubuntu"), IsNil)
+	c.Assert(dc.String(), Equals, `container "godocker" (STOPPED, pid -1)`)
+}
+
+type ClientSuite struct {
+	daemon *fakeDaemon
+	client *godocker.Client
+}
+
+var _ = Suite(&ClientSuite{})
+
+func (s *ClientSuite) SetUpTest(c *C) {
+	s.daemon = startFakeDaemon(c, c.MkDir(), "ubuntu")
+	s.client = godocker.NewClient(s.daemon.socket)
+}
+
+func (s *ClientSuite) TearDownTest(c *C) {
+	s.daemon.Close()
+}
+
+func (s *ClientSuite) TestContainerLifecycle(c *C) {
+	id, err := s.client.CreateContainer("box", &godocker.Config{Image: "ubuntu"})
+	c.Assert(err, IsNil)
+	c.Assert(id, HasLen, 64)
+
+	c.Assert(s.client.StartContainer(id, nil), IsNil)
+	containers, err := s.client.ListContainers(false)
+	c.Assert(err, IsNil)
+	c.Assert(containers, HasLen, 1)
+	c.Assert(containers[0].ID, Equals, id)
+	c.Assert(containers[0].Names, DeepEquals, []string{"/box"})
+
+	c.Assert(s.client.StopContainer("box", time.Second), IsNil)
+	code, err := s.client.WaitContainer("box")
+	c.Assert(err, IsNil)
+	c.Assert(code, Equals, 0)
+	containers, err = s.client.ListContainers(false)
+	c.Assert(err, IsNil)
+	c.Assert(containers, HasLen, 0)
+	containers, err = s.client.ListContainers(true)
+	c.Assert(err, IsNil)
+	c.Assert(containers, HasLen, 1)
+
+	image, err := s.client.CommitContainer("box", "boximage", "v1")
+	c.Assert(err, IsNil)
+	exists, err := s.client.ImageExists(image)
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, true)
+
+	c.Assert(s.client.RemoveContainer("box"), IsNil)
+	_, err = s.client.InspectContainer("box")
+	c.Assert(godocker.IsNotFound(err), Equals, true)
+}
+
+func buildContext(c *C, dockerfile string) io.Reader {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	err := tw.WriteHeader(&tar.Header{
+		Name: "Dockerfile",
+		Mode: 0644,
+		Size: int64(len(dockerfile)),
+	})
+	c.Assert(err, IsNil)
+	_, err = tw.Write([]byte(dockerfile))
+	c.Assert(err, IsNil)
+	c.Assert(tw.Close(), IsNil)
+	return &buf
+}
+
+func (s *ClientSuite) TestBuildImage(c *C) {
+	dockerfile := "FROM ubuntu\nRUN true\n"
+	err := s.client.BuildImage("built:v1", buildContext(c, dockerfile))
+	c.Assert(err, IsNil)
+	exists, err := s.client.ImageExists("built:v1")
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, true)
+	c.Assert(s.daemon.dockerfiles["built:v1"], Equals, dockerfile)
+}
+
+func (s *ClientSuite) TestBuildImageFailure(c *C) {
+	err := s.client.BuildImage("built:v1", buildContext(c, "FROM missing\n"))
+	c.Assert(err, ErrorMatches, `cannot build image "built:v1": No such image: missing`)
+	exists, err := s.client.ImageExists("built:v1")
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, false)
+}
+
+func (s *ClientSuite) TestErrors(c *C) {
+	_, err := s.client.CreateContainer("box", &godocker.Config{Image: "missing"})
+	c.Assert(err, ErrorMatches, `docker POST /containers/create: No such image: missing`)
+	c.Assert(godocker.IsNotFound(err), Equals, true)
+
+	_, err = s.client.CreateContainer("box", &godocker.Config{Image: "ubuntu"})
+	c.Assert(err, IsNil)
+	c.Assert(s.client.StartContainer("box", nil), IsNil)
+	err = s.client.RemoveContainer("box")
+	c.Assert(err, ErrorMatches, `docker DELETE /containers/box: Impossible to remove a running container`)
+	c.Assert(godocker.IsNotFound(err), Equals, false)
+}
+
+func (s *ClientSuite) TestVersion(c *C) {
+	version, err := s.client.Version()
+	c.Assert(err, IsNil)
+	c.Assert(version, DeepEquals, &godocker.Version{
+		Version:   "0.7.0",
+		GitCommit: "0d078b6",
+		GoVersion: "go1.2",
+	})
+}
+
+func (s *ClientSuite) TestInfo(c *C) {
+	_, err := s.client.CreateContainer("box", &godocker.Config{Image: "ubuntu"})
+	c.Assert(err, IsNil)
+	info, err := s.client.Info()
+	c.Assert(err, IsNil)
+	c.Assert(info.Containers, Equals, 1)
+	c.Assert(info.Images, Equals, 1)
+	c.Assert(info.Driver, Equals, "aufs")
+	c.Assert(info.DriverStatus, DeepEquals, [][2]string{{"Root Dir", "/var/lib/docker/aufs"}})
+}
+
+func (s *ClientSuite) TestNoDaemon(c *C) {
+	s.daemon.Close()
+	_, err := s.client.ListContainers(true)
+	c.Assert(err, ErrorMatches, `cannot talk to the docker daemon at ".*/docker.sock": .*`)
+}
+
+func (s *ClientSuite) TestDecodeContainerInfo(c *C) {
+	data, err := ioutil.ReadFile("testdata/inspect.json")
+	c.Assert(err, IsNil)
+	var infos []godocker.ContainerInfo
+	c.Assert(json.Unmarshal(data, &infos), IsNil)
+	c.Assert(infos, HasLen, 1)
+	info := infos[0]
+	c.Assert(info.ID, Equals, "91ea9af00e63508c18b13eba8f00d5ff44867b0c1913414808e142410af809fe")
+	c.Assert(info.Path, Equals, "/bin/bash")
+	c.Assert(info.Config.Image, Equals, "xavier/docker_env")
+	c.Assert(info.Config.Env, DeepEquals, []string{"PORT=5000"})
+	c.Assert(info.State.Running, Equals, false)
+	c.Assert(info.State.ExitCode, Equals, 255)
+	c.Assert(info.NetworkSettings.IPAddress, Equals, "")
 }
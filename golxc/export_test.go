@@ -3,8 +3,12 @@
 
 package golxc
 
+import (
+	"launchpad.net/gocontainer"
+)
+
 // ContainerHome returns the name of the container directory.
-func ContainerHome(c Container) string {
+func ContainerHome(c gocontainer.Container) string {
 	return c.(*container).containerHome()
 }
 
@@ -15,3 +19,11 @@ func SetConfPath(cp string) string {
 	confPath = cp
 	return orig
 }
+
+// SetAutostartDir allows the manipulation of the directory
+// holding the links to the containers started on boot.
+func SetAutostartDir(dir string) string {
+	orig := autostartDir
+	autostartDir = dir
+	return orig
+}
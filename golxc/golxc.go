@@ -11,8 +11,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
+
+	"launchpad.net/gocontainer"
 )
 
 // Error reports the failure of a LXC command.
@@ -32,124 +35,81 @@ func (e Error) Error() string {
 	return fmt.Sprintf("error executing %q: %s", e.Name, strings.Join(e.Output, "; "))
 }
 
-// State represents a container state.
-type State string
-
 const (
-	StateUnknown  State = "UNKNOWN"
-	StateStopped  State = "STOPPED"
-	StateStarting State = "STARTING"
-	StateRunning  State = "RUNNING"
-	StateAborting State = "ABORTING"
-	StateStopping State = "STOPPING"
+	StateUnknown  = gocontainer.StateUnknown
+	StateStopped  = gocontainer.StateStopped
+	StateStarting = gocontainer.StateStarting
+	StateRunning  = gocontainer.StateRunning
+	StateAborting = gocontainer.StateAborting
+	StateStopping = gocontainer.StateStopping
+	StateFrozen   = gocontainer.StateFrozen
 )
 
-// LogLevel represents a container's log level.
-type LogLevel string
-
 const (
-	LogDebug    LogLevel = "DEBUG"
-	LogInfo     LogLevel = "INFO"
-	LogNotice   LogLevel = "NOTICE"
-	LogWarning  LogLevel = "WARN"
-	LogError    LogLevel = "ERROR"
-	LogCritical LogLevel = "CRIT"
-	LogFatal    LogLevel = "FATAL"
+	LogDebug    = gocontainer.LogDebug
+	LogInfo     = gocontainer.LogInfo
+	LogNotice   = gocontainer.LogNotice
+	LogWarning  = gocontainer.LogWarning
+	LogError    = gocontainer.LogError
+	LogCritical = gocontainer.LogCritical
+	LogFatal    = gocontainer.LogFatal
 )
 
-// Container represents a linux container instance and provides
-// operations to create, maintain and destroy the container.
-type Container interface {
-
-	// Name returns the name of the container.
-	Name() string
-
-	// Create creates a new container based on the given template.
-	Create(configFile, template string, templateArgs ...string) error
-
-	// Start runs the container as a daemon.
-	Start(configFile, consoleFile string) error
-
-	// Stop terminates the running container.
-	Stop() error
-
-	// Clone creates a copy of the container, giving the copy the specified name.
-	Clone(name string) (Container, error)
-
-	// Freeze freezes all the container's processes.
-	Freeze() error
-
-	// Unfreeze thaws all frozen container's processes.
-	Unfreeze() error
-
-	// Destroy stops and removes the container.
-	Destroy() error
-
-	// Wait waits for one of the specified container states.
-	Wait(states ...State) error
-
-	// Info returns the status and the process id of the container.
-	Info() (State, int, error)
-
-	// IsConstructed checks if the container image exists.
-	IsConstructed() bool
-
-	// IsRunning checks if the state of the container is 'RUNNING'.
-	IsRunning() bool
-
-	// String returns information about the container, like the name, state,
-	// and process id.
-	String() string
-
-	// LogFile returns the current filename used for the LogFile.
-	LogFile() string
+// capabilities lists the optional features provided by lxc.
+var capabilities = []gocontainer.Capability{
+	gocontainer.Freezable,
+	gocontainer.Cloneable,
+	gocontainer.Snapshot,
+}
 
-	// LogLevel returns the current logging level (only used if the
-	// LogFile is not "").
-	LogLevel() LogLevel
+var _ gocontainer.Snapshotter = (*container)(nil)
 
-	// SetLogFile sets both the LogFile and LogLevel.
-	SetLogFile(filename string, level LogLevel)
-}
+// backupSuffix is appended to the name of a container to name the clone
+// made of it before it is restored from a snapshot, so that it can be
+// put back if the restore fails.
+const backupSuffix = "restore-backup"
 
-// ContainerFactory represents the methods used to create Containers.
-type ContainerFactory interface {
-	// New returns a container instance which can then be used for operations
-	// like Create(), Start(), Stop() or Destroy().
-	New(string) Container
+// autostartDir holds the links to the configuration of the containers
+// started when the host boots.
+var autostartDir = "/etc/lxc/auto"
 
-	// List returns all the existing containers on the system.
-	List() ([]Container, error)
+func init() {
+	gocontainer.RegisterBackend("lxc", Factory())
 }
 
 // Factory provides the standard ContainerFactory.
-func Factory() ContainerFactory {
+func Factory() gocontainer.ContainerFactory {
 	return &containerFactory{}
 }
 
 type container struct {
 	name     string
 	logFile  string
-	logLevel LogLevel
+	logLevel gocontainer.LogLevel
 }
 
 type containerFactory struct{}
 
-func (*containerFactory) New(name string) Container {
+func (*containerFactory) New(name string) gocontainer.Container {
 	return &container{
 		name:     name,
 		logLevel: LogWarning,
 	}
 }
 
+// Capabilities returns the optional features provided by lxc.
+func (*containerFactory) Capabilities() []gocontainer.Capability {
+	return capabilities
+}
+
 // List returns all the existing containers on the system.
-func (factory *containerFactory) List() ([]Container, error) {
+func (factory *containerFactory) List() ([]gocontainer.Container, error) {
 	out, err := run("lxc-ls", "-1")
 	if err != nil {
 		return nil, err
 	}
 	names := nameSet(out)
-	containers := make([]Container, len(names))
+	containers := make([]gocontainer.Container, len(names))
 	for i, name := range names {
 		containers[i] = factory.New(name)
 	}
@@ -168,12 +128,12 @@ func (c *container) LogFile() string {
 
 // LogLevel returns the current logging level, this is only used if the
 // LogFile is not "".
-func (c *container) LogLevel() LogLevel {
+func (c *container) LogLevel() gocontainer.LogLevel {
 	return c.logLevel
 }
 
 // SetLogFile sets both the LogFile and LogLevel.
-func (c *container) SetLogFile(filename string, level LogLevel) {
+func (c *container) SetLogFile(filename string, level gocontainer.LogLevel) {
 	c.logFile = filename
 	c.logLevel = level
 }
@@ -245,7 +205,7 @@ func (c *container) Stop() error {
 }
 
 // Clone creates a copy of the container, it gets the given name.
-func (c *container) Clone(name string) (Container, error) {
+func (c *container) Clone(name string) (gocontainer.Container, error) {
 	if !c.IsConstructed() {
 		return nil, fmt.Errorf("container %q is not yet created", c.name)
 	}
@@ -266,6 +226,104 @@ func (c *container) Clone(name string) (Container, error) {
 	return cc, nil
 }
 
+// snapshotName returns the name of the clone holding the named snapshot
+// of the container.
+func (c *container) snapshotName(name string) string {
+	return fmt.Sprintf("%s-snapshot-%s", c.name, name)
+}
+
+// Snapshot clones the container to a container named after it and the
+// snapshot. lxc cannot clone a running container, so the container must
+// be stopped first; it is left to the caller to start it again as it
+// was started.
+func (c *container) Snapshot(name string) error {
+	if !c.IsConstructed() {
+		return fmt.Errorf("container %q is not yet created", c.name)
+	}
+	if c.IsRunning() {
+		return fmt.Errorf("container %q is running", c.name)
+	}
+	snapshot := &container{name: c.snapshotName(name)}
+	if snapshot.IsConstructed() {
+		if err := snapshot.Destroy(); err != nil {
+			return err
+		}
+	}
+	_, err := c.Clone(snapshot.name)
+	return err
+}
+
+// autostartLink returns the link that makes lxc start the container
+// when the host boots.
+func (c *container) autostartLink() string {
+	return filepath.Join(autostartDir, c.name+".conf")
+}
+
+// Restore replaces the container with a clone of the named snapshot.
+// The container is cloned to a backup first, from which it is cloned
+// again if the snapshot cannot be restored; the backup is destroyed
+// once the container is restored. The link starting the container when
+// the host boots, if any, is kept.
+func (c *container) Restore(name string) error {
+	if !c.IsConstructed() {
+		return fmt.Errorf("container %q is not yet created", c.name)
+	}
+	if c.IsRunning() {
+		return fmt.Errorf("container %q is running", c.name)
+	}
+	snapshot := &container{name: c.snapshotName(name)}
+	if !snapshot.IsConstructed() {
+		return fmt.Errorf("no snapshot %q found for container %q", name, c.name)
+	}
+	// lxc-destroy removes the autostart link along with the container.
+	autostart, err := os.Readlink(c.autostartLink())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	backup := &container{name: c.name + "-" + backupSuffix}
+	if backup.IsConstructed() {
+		if err := backup.Destroy(); err != nil {
+			return err
+		}
+	}
+	if _, err := c.Clone(backup.name); err != nil {
+		return fmt.Errorf("cannot back up container %q: %v", c.name, err)
+	}
+	if err := c.Destroy(); err != nil {
+		return err
+	}
+	if _, err := snapshot.Clone(c.name); err != nil {
+		// A clone that failed part way is not used in place of the
+		// backup.
+		if c.IsConstructed() {
+			c.Destroy()
+		}
+		if _, berr := backup.Clone(c.name); berr != nil {
+			return fmt.Errorf("cannot restore container %q: %v (and cannot clone it again from its backup %q: %v)", c.name, err, backup.name, berr)
+		}
+		if lerr := c.restoreAutostartLink(autostart); lerr != nil {
+			return lerr
+		}
+		return fmt.Errorf("cannot restore container %q: %v", c.name, err)
+	}
+	if err := c.restoreAutostartLink(autostart); err != nil {
+		return err
+	}
+	return backup.Destroy()
+}
+
+// restoreAutostartLink makes the link starting the container when the
+// host boots point to the given target again, if any.
+func (c *container) restoreAutostartLink(target string) error {
+	if target == "" {
+		return nil
+	}
+	if err := os.Remove(c.autostartLink()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(target, c.autostartLink())
+}
+
 // Freeze freezes all the container's processes.
 func (c *container) Freeze() error {
 	if !c.IsConstructed() {
@@ -324,7 +382,7 @@ func (c *container) Destroy() error {
 }
 
 // Wait waits for one of the specified container states.
-func (c *container) Wait(states ...State) error {
+func (c *container) Wait(states ...gocontainer.State) error {
 	if len(states) == 0 {
 		return fmt.Errorf("no states specified")
 	}
@@ -341,13 +399,13 @@ func (c *container) Wait(states ...State) error {
 }
 
 // Info returns the status and the process id of the container.
-func (c *container) Info() (State, int, error) {
+func (c *container) Info() (gocontainer.State, int, error) {
 	out, err := run("lxc-info", "-n", c.name)
 	if err != nil {
 		return StateUnknown, -1, err
 	}
 	kv := keyValues(out, ": ")
-	state := State(kv["state"])
+	state := gocontainer.State(kv["state"])
 	pid, err := strconv.Atoi(kv["pid"])
 	if err != nil {
 		return StateUnknown, -1, fmt.Errorf("cannot read the pid: %v", err)
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"launchpad.net/gocontainer"
 	"launchpad.net/golxc"
 )
 
@@ -124,8 +125,21 @@ func (s *NetworkSuite) TestNotExistingNetworkAttributes(c *C) {
 	c.Assert(err, ErrorMatches, "open .*: no such file or directory")
 }
 
+type BackendSuite struct{}
+
+var _ = Suite(&BackendSuite{})
+
+func (s *BackendSuite) TestRegistered(c *C) {
+	factory, err := gocontainer.Backend("lxc")
+	c.Assert(err, IsNil)
+	c.Assert(gocontainer.HasCapability(factory, gocontainer.Freezable), Equals, true)
+	c.Assert(gocontainer.HasCapability(factory, gocontainer.Cloneable), Equals, true)
+	c.Assert(gocontainer.HasCapability(factory, gocontainer.Snapshot), Equals, true)
+	c.Assert(gocontainer.HasCapability(factory, gocontainer.ImageBased), Equals, false)
+}
+
 type LXCSuite struct {
-	factory golxc.ContainerFactory
+	factory gocontainer.ContainerFactory
 }
 
 var _ = Suite(&LXCSuite{golxc.Factory()})
@@ -193,7 +207,7 @@ func (s *LXCSuite) TestDestroyNotCreated(c *C) {
 	c.Assert(err, ErrorMatches, "container .* is not yet created")
 }
 
-func contains(lcs []golxc.Container, lc golxc.Container) bool {
+func contains(lcs []gocontainer.Container, lc gocontainer.Container) bool {
 	for _, clc := range lcs {
 		if clc.Name() == lc.Name() {
 			return true
@@ -253,6 +267,64 @@ func (s *LXCSuite) TestCloneNotCreated(c *C) {
 	c.Assert(err, ErrorMatches, "container .* is not yet created")
 }
 
+func (s *LXCSuite) TestSnapshotRestore(c *C) {
+	// Test snapshotting a container and restoring the snapshot.
+	autostartDir := c.MkDir()
+	defer golxc.SetAutostartDir(golxc.SetAutostartDir(autostartDir))
+	lc := s.factory.New("golxc")
+	c.Assert(lc.Create("", "ubuntu"), IsNil)
+	defer func() {
+		c.Assert(lc.Destroy(), IsNil)
+	}()
+	config := filepath.Join(golxc.ContainerHome(lc), "config")
+	link := filepath.Join(autostartDir, "golxc.conf")
+	c.Assert(os.Symlink(config, link), IsNil)
+	snapshotter := lc.(gocontainer.Snapshotter)
+	c.Assert(snapshotter.Snapshot("before"), IsNil)
+	snapshot := s.factory.New("golxc-snapshot-before")
+	c.Assert(snapshot.IsConstructed(), Equals, true)
+	defer func() {
+		c.Assert(snapshot.Destroy(), IsNil)
+	}()
+	c.Assert(snapshotter.Restore("before"), IsNil)
+	c.Assert(lc.IsConstructed(), Equals, true)
+	c.Assert(snapshot.IsConstructed(), Equals, true)
+	c.Assert(s.factory.New("golxc-restore-backup").IsConstructed(), Equals, false)
+	target, err := os.Readlink(link)
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, config)
+}
+
+func (s *LXCSuite) TestSnapshotRunning(c *C) {
+	// Test that a running container can't be snapshotted.
+	lc := s.factory.New("golxc")
+	c.Assert(lc.Create("", "ubuntu"), IsNil)
+	defer func() {
+		c.Assert(lc.Destroy(), IsNil)
+	}()
+	c.Assert(lc.Start("", ""), IsNil)
+	err := lc.(gocontainer.Snapshotter).Snapshot("before")
+	c.Assert(err, ErrorMatches, `container "golxc" is running`)
+}
+
+func (s *LXCSuite) TestSnapshotNotCreated(c *C) {
+	// Test that a non-existing container can't be snapshotted.
+	lc := s.factory.New("golxc")
+	err := lc.(gocontainer.Snapshotter).Snapshot("before")
+	c.Assert(err, ErrorMatches, "container .* is not yet created")
+}
+
+func (s *LXCSuite) TestRestoreUnknownSnapshot(c *C) {
+	// Test that only existing snapshots can be restored.
+	lc := s.factory.New("golxc")
+	c.Assert(lc.Create("", "ubuntu"), IsNil)
+	defer func() {
+		c.Assert(lc.Destroy(), IsNil)
+	}()
+	err := lc.(gocontainer.Snapshotter).Restore("unknown")
+	c.Assert(err, ErrorMatches, `no snapshot "unknown" found for container "golxc"`)
+}
+
 func (s *LXCSuite) TestStartStop(c *C) {
 	// Test starting and stopping a container.
 	lc := s.factory.New("golxc")
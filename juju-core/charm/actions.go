@@ -0,0 +1,137 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+
+	"launchpad.net/goyaml"
+)
+
+// ActionSpec describes an action a charm can be asked to perform, and
+// the parameters it takes.
+type ActionSpec struct {
+	Description string
+	Params      map[string]Option
+}
+
+// Actions represents the actions a charm can be asked to perform, as
+// declared in its actions.yaml file.
+type Actions struct {
+	ActionSpecs map[string]ActionSpec `yaml:"actions" bson:"actions"`
+}
+
+// NewActions returns a new Actions without any actions.
+func NewActions() *Actions {
+	return &Actions{map[string]ActionSpec{}}
+}
+
+var validActionName = regexp.MustCompile("^[a-z][a-z0-9]*(-[a-z0-9]+)*$")
+
+// ReadActions reads an Actions in YAML format.
+func ReadActions(r io.Reader) (*Actions, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var actions *Actions
+	if err := goyaml.Unmarshal(data, &actions); err != nil {
+		return nil, err
+	}
+	if actions == nil {
+		return nil, fmt.Errorf("invalid actions: empty actions definition")
+	}
+	if actions.ActionSpecs == nil {
+		actions.ActionSpecs = map[string]ActionSpec{}
+	}
+	for name, spec := range actions.ActionSpecs {
+		if !validActionName.MatchString(name) {
+			return nil, fmt.Errorf("invalid actions: bad action name %q", name)
+		}
+		if spec.Params == nil {
+			spec.Params = map[string]Option{}
+		}
+		for pname, param := range spec.Params {
+			switch param.Type {
+			case "string", "int", "float", "boolean":
+			case "":
+				param.Type = "string"
+			default:
+				return nil, fmt.Errorf("invalid actions: parameter %q of action %q has unknown type %q", pname, name, param.Type)
+			}
+			def := param.Default
+			if param.Default, err = param.validate(pname, def); err != nil {
+				param.error(&err, pname, def)
+				return nil, fmt.Errorf("invalid actions: action %q: %v", name, err)
+			}
+			spec.Params[pname] = param
+		}
+		actions.ActionSpecs[name] = spec
+	}
+	return actions, nil
+}
+
+// spec returns the named action, or an error if none such exists.
+func (a *Actions) spec(name string) (ActionSpec, error) {
+	if spec, ok := a.ActionSpecs[name]; ok {
+		return spec, nil
+	}
+	return ActionSpec{}, fmt.Errorf("unknown action %q", name)
+}
+
+// ValidateParams returns a copy of the supplied parameters of the named
+// action with a consistent type for each value, and with the default
+// value of every parameter not supplied. It returns an error if the
+// action is unknown, or if the parameters contain unknown keys or
+// invalid values.
+func (a *Actions) ValidateParams(name string, params map[string]interface{}) (map[string]interface{}, error) {
+	spec, err := a.spec(name)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]interface{})
+	for pname, param := range spec.Params {
+		if param.Default != nil {
+			out[pname] = param.Default
+		}
+	}
+	for pname, value := range params {
+		param, ok := spec.Params[pname]
+		if !ok {
+			return nil, fmt.Errorf("unknown parameter %q of action %q", pname, name)
+		}
+		if value, err = param.validate(pname, value); err != nil {
+			return nil, err
+		}
+		if value != nil {
+			out[pname] = value
+		}
+	}
+	return out, nil
+}
+
+// ParseParamsStrings returns the parameters of the named action derived
+// from the supplied map, as validated by ValidateParams. Every value in
+// the map must be parseable to the correct type for the parameter
+// identified by its key.
+func (a *Actions) ParseParamsStrings(name string, values map[string]string) (map[string]interface{}, error) {
+	spec, err := a.spec(name)
+	if err != nil {
+		return nil, err
+	}
+	params := make(map[string]interface{})
+	for pname, str := range values {
+		param, ok := spec.Params[pname]
+		if !ok {
+			return nil, fmt.Errorf("unknown parameter %q of action %q", pname, name)
+		}
+		if params[pname], err = param.parse(pname, str); err != nil {
+			return nil, err
+		}
+	}
+	return a.ValidateParams(name, params)
+}
@@ -0,0 +1,153 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"bytes"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/charm"
+)
+
+type ActionsSuite struct {
+	actions *charm.Actions
+}
+
+var _ = Suite(&ActionsSuite{})
+
+func (s *ActionsSuite) SetUpSuite(c *C) {
+	var err error
+	s.actions, err = charm.ReadActions(bytes.NewBuffer([]byte(`
+actions:
+  snapshot:
+    description: Take a snapshot of the database.
+    params:
+      outfile:
+        description: The file to write out to.
+        default: foo.bz2
+      compression:
+        description: The bzip2 compression level.
+        type: int
+        default: 9
+      verify:
+        description: Whether to verify the snapshot once written.
+        type: boolean
+  reindex:
+    description: Rebuild the indexes of the database.
+`)))
+	c.Assert(err, IsNil)
+}
+
+func (s *ActionsSuite) TestReadSample(c *C) {
+	c.Assert(s.actions.ActionSpecs, DeepEquals, map[string]charm.ActionSpec{
+		"snapshot": {
+			Description: "Take a snapshot of the database.",
+			Params: map[string]charm.Option{
+				"outfile": {
+					Description: "The file to write out to.",
+					Type:        "string",
+					Default:     "foo.bz2",
+				},
+				"compression": {
+					Description: "The bzip2 compression level.",
+					Type:        "int",
+					Default:     int64(9),
+				},
+				"verify": {
+					Description: "Whether to verify the snapshot once written.",
+					Type:        "boolean",
+				},
+			},
+		},
+		"reindex": {
+			Description: "Rebuild the indexes of the database.",
+			Params:      map[string]charm.Option{},
+		},
+	})
+}
+
+func (s *ActionsSuite) TestValidateParams(c *C) {
+	for i, t := range []struct {
+		name   string
+		params map[string]interface{}
+		expect map[string]interface{}
+		err    string
+	}{{
+		name:   "snapshot",
+		expect: map[string]interface{}{"outfile": "foo.bz2", "compression": int64(9)},
+	}, {
+		name:   "snapshot",
+		params: map[string]interface{}{"outfile": "bar.bz2", "compression": 3, "verify": true},
+		expect: map[string]interface{}{"outfile": "bar.bz2", "compression": int64(3), "verify": true},
+	}, {
+		name:   "snapshot",
+		params: map[string]interface{}{"verify": nil},
+		expect: map[string]interface{}{"outfile": "foo.bz2", "compression": int64(9)},
+	}, {
+		name:   "reindex",
+		expect: map[string]interface{}{},
+	}, {
+		name:   "snapshot",
+		params: map[string]interface{}{"compression": "high"},
+		err:    `option "compression" expected int, got "high"`,
+	}, {
+		name:   "snapshot",
+		params: map[string]interface{}{"destination": "here"},
+		err:    `unknown parameter "destination" of action "snapshot"`,
+	}, {
+		name: "restore",
+		err:  `unknown action "restore"`,
+	}} {
+		c.Logf("test %d", i)
+		params, err := s.actions.ValidateParams(t.name, t.params)
+		if t.err != "" {
+			c.Check(err, ErrorMatches, t.err)
+			continue
+		}
+		c.Check(err, IsNil)
+		c.Check(params, DeepEquals, t.expect)
+	}
+}
+
+func (s *ActionsSuite) TestParseParamsStrings(c *C) {
+	params, err := s.actions.ParseParamsStrings("snapshot", map[string]string{
+		"compression": "3",
+		"verify":      "true",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(params, DeepEquals, map[string]interface{}{
+		"outfile":     "foo.bz2",
+		"compression": int64(3),
+		"verify":      true,
+	})
+
+	_, err = s.actions.ParseParamsStrings("snapshot", map[string]string{"verify": "maybe"})
+	c.Assert(err, ErrorMatches, `option "verify" expected boolean, got "maybe"`)
+	_, err = s.actions.ParseParamsStrings("snapshot", map[string]string{"destination": "here"})
+	c.Assert(err, ErrorMatches, `unknown parameter "destination" of action "snapshot"`)
+}
+
+func (s *ActionsSuite) TestReadActionsErrors(c *C) {
+	for i, t := range []struct {
+		yaml string
+		err  string
+	}{{
+		yaml: "",
+		err:  "invalid actions: empty actions definition",
+	}, {
+		yaml: "actions:\n  Snap_Shot: {}\n",
+		err:  `invalid actions: bad action name "Snap_Shot"`,
+	}, {
+		yaml: "actions:\n  snapshot:\n    params:\n      outfile: {type: file}\n",
+		err:  `invalid actions: parameter "outfile" of action "snapshot" has unknown type "file"`,
+	}, {
+		yaml: "actions:\n  snapshot:\n    params:\n      compression: {type: int, default: high}\n",
+		err:  `invalid actions: action "snapshot": option "compression" expected int, got "high"`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := charm.ReadActions(bytes.NewBuffer([]byte(t.yaml)))
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
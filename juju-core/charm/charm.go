@@ -14,6 +14,7 @@ import (
 type Charm interface {
 	Meta() *Meta
 	Config() *Config
+	Actions() *Actions
 	Revision() int
 }
 
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"launchpad.net/goyaml"
 
@@ -20,24 +22,56 @@ import (
 type Settings map[string]interface{}
 
 // Option represents a single charm config option.
+//
+// Besides the scalar types string, int, float and boolean, an option may
+// be a list of strings, a map of strings to strings, an enum whose value
+// must be one of Values, or a secret, which is a string that is never
+// shown back to the user.
 type Option struct {
 	Type        string
 	Description string
 	Default     interface{}
+	// Values holds the values allowed for an enum option.
+	Values []string `bson:",omitempty"`
+	// Min and Max bound the values of an int or float option. When set,
+	// they hold values of the option's type.
+	Min interface{} `bson:",omitempty"`
+	Max interface{} `bson:",omitempty"`
+	// Pattern is a regular expression that every value of a string,
+	// secret or list option must match in its entirety.
+	Pattern string `bson:",omitempty"`
 }
 
 // error replaces any supplied non-nil error with a new error describing a
 // validation failure for the supplied value.
 func (option Option) error(err *error, name string, value interface{}) {
 	if *err != nil {
-		*err = fmt.Errorf("option %q expected %s, got %#v", name, option.Type, value)
+		if option.Type == "secret" {
+			*err = fmt.Errorf("option %q expected secret", name)
+		} else {
+			*err = fmt.Errorf("option %q expected %s, got %#v", name, option.Type, value)
+		}
 	}
 }
 
 // validate returns an appropriately-typed value for the supplied value, or
-// returns an error if it cannot be converted to the correct type. Nil values
-// are always considered valid, and empty string values are converted to nil.
-func (option Option) validate(name string, value interface{}) (_ interface{}, err error) {
+// returns an error if it cannot be converted to the correct type or violates
+// the constraints of the option. Nil values are always considered valid,
+// and empty string values are converted to nil.
+func (option Option) validate(name string, value interface{}) (interface{}, error) {
+	value, err := option.coerce(name, value)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	if err := option.check(name, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// coerce returns an appropriately-typed value for the supplied value, or
+// returns an error if it cannot be converted to the correct type.
+func (option Option) coerce(name string, value interface{}) (_ interface{}, err error) {
 	if value == nil {
 		return nil, nil
 	}
@@ -58,18 +92,35 @@ var optionTypeCheckers = map[string]schema.Checker{
 	"int":     schema.Int(),
 	"float":   schema.Float(),
 	"boolean": schema.Bool(),
+	"list":    schema.List(schema.String()),
+	"map":     schema.StringMap(schema.String()),
+	"enum":    schema.String(),
+	"secret":  schema.String(),
 }
 
 // parse returns an appropriately-typed value for the supplied string, or
-// returns an error if it cannot be parsed to the correct type. Empty
-// string values are returned as nil.
-func (option Option) parse(name, str string) (_ interface{}, err error) {
+// returns an error if it cannot be parsed to the correct type or violates
+// the constraints of the option. Empty string values are returned as nil.
+// Lists are given as comma-separated values, and maps as comma-separated
+// key=value pairs.
+func (option Option) parse(name, str string) (interface{}, error) {
+	value, err := option.parseString(name, str)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	if err := option.check(name, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (option Option) parseString(name, str string) (_ interface{}, err error) {
 	if str == "" {
 		return nil, nil
 	}
 	defer option.error(&err, name, str)
 	switch option.Type {
-	case "string":
+	case "string", "enum", "secret":
 		return str, nil
 	case "int":
 		return strconv.ParseInt(str, 10, 64)
@@ -77,10 +128,126 @@ func (option Option) parse(name, str string) (_ interface{}, err error) {
 		return strconv.ParseFloat(str, 64)
 	case "boolean":
 		return strconv.ParseBool(str)
+	case "list":
+		var list []interface{}
+		for _, elem := range strings.Split(str, ",") {
+			list = append(list, strings.TrimSpace(elem))
+		}
+		return list, nil
+	case "map":
+		m := make(map[string]interface{})
+		for _, pair := range strings.Split(str, ",") {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("expected key=value, got %q", pair)
+			}
+			m[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+		return m, nil
 	}
 	panic(fmt.Errorf("option %q has unknown type %q", name, option.Type))
 }
 
+// check returns an error if the supplied value, which must already have
+// the option's type, violates the constraints of the option.
+func (option Option) check(name string, value interface{}) error {
+	switch option.Type {
+	case "enum":
+		for _, allowed := range option.Values {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("option %q expected one of %s, got %q", name, quoteAll(option.Values), value)
+	case "int", "float":
+		if option.Min != nil && toFloat(value) < toFloat(option.Min) {
+			return fmt.Errorf("option %q expected at least %v, got %v", name, option.Min, value)
+		}
+		if option.Max != nil && toFloat(value) > toFloat(option.Max) {
+			return fmt.Errorf("option %q expected at most %v, got %v", name, option.Max, value)
+		}
+	case "string", "secret":
+		return option.checkPattern(name, value.(string))
+	case "list":
+		for _, elem := range value.([]interface{}) {
+			if err := option.checkPattern(name, elem.(string)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkPattern returns an error if the option has a pattern that the
+// supplied string does not match. Secret values are not included in
+// the error.
+func (option Option) checkPattern(name, str string) error {
+	if option.Pattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile("^(?:" + option.Pattern + ")$")
+	if err != nil {
+		return fmt.Errorf("option %q has invalid pattern: %v", name, err)
+	}
+	if re.MatchString(str) {
+		return nil
+	}
+	if option.Type == "secret" {
+		return fmt.Errorf("option %q expected value matching %q", name, option.Pattern)
+	}
+	return fmt.Errorf("option %q expected value matching %q, got %q", name, option.Pattern, str)
+}
+
+// prepare checks that the constraints declared for the option suit its
+// type, and coerces its minimum and maximum to that type.
+func (option *Option) prepare(name string) (err error) {
+	if len(option.Values) > 0 && option.Type != "enum" {
+		return fmt.Errorf("option %q of type %q cannot have allowed values", name, option.Type)
+	}
+	if len(option.Values) == 0 && option.Type == "enum" {
+		return fmt.Errorf("enum option %q has no allowed values", name)
+	}
+	if option.Min != nil || option.Max != nil {
+		if option.Type != "int" && option.Type != "float" {
+			return fmt.Errorf("option %q of type %q cannot have a minimum or maximum", name, option.Type)
+		}
+		if option.Min, err = option.coerce(name, option.Min); err != nil {
+			return fmt.Errorf("invalid minimum: %v", err)
+		}
+		if option.Max, err = option.coerce(name, option.Max); err != nil {
+			return fmt.Errorf("invalid maximum: %v", err)
+		}
+	}
+	if option.Pattern != "" {
+		switch option.Type {
+		case "string", "secret", "list":
+		default:
+			return fmt.Errorf("option %q of type %q cannot have a pattern", name, option.Type)
+		}
+		if _, err := regexp.Compile(option.Pattern); err != nil {
+			return fmt.Errorf("option %q has invalid pattern: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// toFloat returns the supplied int64 or float64 value as a float64.
+func toFloat(value interface{}) float64 {
+	if i, ok := value.(int64); ok {
+		return float64(i)
+	}
+	return value.(float64)
+}
+
+// quoteAll returns the supplied strings quoted and separated by commas.
+func quoteAll(strs []string) string {
+	quoted := make([]string, len(strs))
+	for i, str := range strs {
+		quoted[i] = strconv.Quote(str)
+	}
+	return strings.Join(quoted, ", ")
+}
+
 // Config represents the supported configuration options for a charm,
 // as declared in its config.yaml file.
 type Config struct {
@@ -107,18 +274,20 @@ func ReadConfig(r io.Reader) (*Config, error) {
 	}
 	for name, option := range config.Options {
 		switch option.Type {
-		case "string", "int", "float", "boolean":
+		case "string", "int", "float", "boolean", "list", "map", "enum", "secret":
 		case "":
 			// Missing type is valid in python.
 			option.Type = "string"
 		default:
 			return nil, fmt.Errorf("invalid config: option %q has unknown type %q", name, option.Type)
 		}
+		if err := option.prepare(name); err != nil {
+			return nil, fmt.Errorf("invalid config: %v", err)
+		}
 		def := option.Default
 		if def == "" && option.Type == "string" {
 			// Skip normal validation for compatibility with pyjuju.
 		} else if option.Default, err = option.validate(name, def); err != nil {
-			option.error(&err, name, def)
 			return nil, fmt.Errorf("invalid config default: %v", err)
 		}
 		config.Options[name] = option
@@ -411,3 +411,190 @@ func (s *ConfigSuite) TestEmptyConfigReturnsError(c *C) {
 	c.Assert(result, IsNil)
 	c.Assert(err, ErrorMatches, "invalid config: empty configuration")
 }
+
+var typedConfig = `
+options:
+  flavour:
+    type: enum
+    values: [vanilla, chocolate]
+    default: vanilla
+  mirrors:
+    type: list
+    pattern: "https?://.*"
+  labels:
+    type: map
+  workers:
+    type: int
+    min: 1
+    max: 64
+  ratio:
+    type: float
+    max: 1.0
+  password:
+    type: secret
+    pattern: ".{8,}"
+`
+
+func (s *ConfigSuite) TestReadTypedConfig(c *C) {
+	config, err := charm.ReadConfig(bytes.NewBuffer([]byte(typedConfig)))
+	c.Assert(err, IsNil)
+	c.Assert(config.Options, DeepEquals, map[string]charm.Option{
+		"flavour": {
+			Type:    "enum",
+			Values:  []string{"vanilla", "chocolate"},
+			Default: "vanilla",
+		},
+		"mirrors": {
+			Type:    "list",
+			Pattern: "https?://.*",
+		},
+		"labels": {
+			Type: "map",
+		},
+		"workers": {
+			Type: "int",
+			Min:  int64(1),
+			Max:  int64(64),
+		},
+		"ratio": {
+			Type: "float",
+			Max:  1.0,
+		},
+		"password": {
+			Type:    "secret",
+			Pattern: ".{8,}",
+		},
+	})
+}
+
+func (s *ConfigSuite) TestValidateTypedSettings(c *C) {
+	config, err := charm.ReadConfig(bytes.NewBuffer([]byte(typedConfig)))
+	c.Assert(err, IsNil)
+	for i, test := range []struct {
+		info   string
+		input  charm.Settings
+		expect charm.Settings
+		err    string
+	}{{
+		info: "valid values",
+		input: charm.Settings{
+			"flavour":  "chocolate",
+			"mirrors":  []string{"http://archive.testing.invalid"},
+			"labels":   map[interface{}]interface{}{"tier": "web"},
+			"workers":  64,
+			"ratio":    0.5,
+			"password": "sekrit-password",
+		},
+		expect: charm.Settings{
+			"flavour":  "chocolate",
+			"mirrors":  []interface{}{"http://archive.testing.invalid"},
+			"labels":   map[string]interface{}{"tier": "web"},
+			"workers":  int64(64),
+			"ratio":    0.5,
+			"password": "sekrit-password",
+		},
+	}, {
+		info:  "enum value not allowed",
+		input: charm.Settings{"flavour": "strawberry"},
+		err:   `option "flavour" expected one of "vanilla", "chocolate", got "strawberry"`,
+	}, {
+		info:  "bad list",
+		input: charm.Settings{"mirrors": "http://archive.testing.invalid"},
+		err:   `option "mirrors" expected list, got "http://archive.testing.invalid"`,
+	}, {
+		info:  "list element not matching pattern",
+		input: charm.Settings{"mirrors": []interface{}{"ftp://archive.testing.invalid"}},
+		err:   `option "mirrors" expected value matching "https\?://\.\*", got "ftp://archive.testing.invalid"`,
+	}, {
+		info:  "bad map",
+		input: charm.Settings{"labels": map[string]interface{}{"tier": 1}},
+		err:   `option "labels" expected map, got map\[string\]interface \{\}\{"tier":1\}`,
+	}, {
+		info:  "int below minimum",
+		input: charm.Settings{"workers": 0},
+		err:   `option "workers" expected at least 1, got 0`,
+	}, {
+		info:  "float above maximum",
+		input: charm.Settings{"ratio": 1.5},
+		err:   `option "ratio" expected at most 1, got 1.5`,
+	}, {
+		info:  "secret not matching pattern is not shown",
+		input: charm.Settings{"password": "short"},
+		err:   `option "password" expected value matching "\.\{8,\}"`,
+	}, {
+		info:  "bad secret is not shown",
+		input: charm.Settings{"password": 12345678},
+		err:   `option "password" expected secret`,
+	}} {
+		c.Logf("test %d: %s", i, test.info)
+		result, err := config.ValidateSettings(test.input)
+		if test.err != "" {
+			c.Check(err, ErrorMatches, test.err)
+		} else {
+			c.Check(err, IsNil)
+			c.Check(result, DeepEquals, test.expect)
+		}
+	}
+}
+
+func (s *ConfigSuite) TestParseTypedSettingsStrings(c *C) {
+	config, err := charm.ReadConfig(bytes.NewBuffer([]byte(typedConfig)))
+	c.Assert(err, IsNil)
+	settings, err := config.ParseSettingsStrings(map[string]string{
+		"flavour":  "chocolate",
+		"mirrors":  "http://one.testing.invalid, https://two.testing.invalid",
+		"labels":   "tier=web, zone=a",
+		"workers":  "8",
+		"password": "sekrit-password",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, charm.Settings{
+		"flavour":  "chocolate",
+		"mirrors":  []interface{}{"http://one.testing.invalid", "https://two.testing.invalid"},
+		"labels":   map[string]interface{}{"tier": "web", "zone": "a"},
+		"workers":  int64(8),
+		"password": "sekrit-password",
+	})
+
+	_, err = config.ParseSettingsStrings(map[string]string{"labels": "tier"})
+	c.Assert(err, ErrorMatches, `option "labels" expected map, got "tier"`)
+	_, err = config.ParseSettingsStrings(map[string]string{"workers": "100"})
+	c.Assert(err, ErrorMatches, `option "workers" expected at most 64, got 100`)
+	_, err = config.ParseSettingsStrings(map[string]string{"flavour": "strawberry"})
+	c.Assert(err, ErrorMatches, `option "flavour" expected one of "vanilla", "chocolate", got "strawberry"`)
+}
+
+func (s *ConfigSuite) TestConstraintErrors(c *C) {
+	for i, test := range []struct {
+		config string
+		err    string
+	}{{
+		config: `options: {t: {type: enum}}`,
+		err:    `invalid config: enum option "t" has no allowed values`,
+	}, {
+		config: `options: {t: {type: string, values: [a, b]}}`,
+		err:    `invalid config: option "t" of type "string" cannot have allowed values`,
+	}, {
+		config: `options: {t: {type: boolean, min: 1}}`,
+		err:    `invalid config: option "t" of type "boolean" cannot have a minimum or maximum`,
+	}, {
+		config: `options: {t: {type: int, max: 2.5}}`,
+		err:    `invalid config: invalid maximum: option "t" expected int, got 2.5`,
+	}, {
+		config: `options: {t: {type: int, pattern: "[0-9]+"}}`,
+		err:    `invalid config: option "t" of type "int" cannot have a pattern`,
+	}, {
+		config: `options: {t: {type: string, pattern: "("}}`,
+		err:    `invalid config: option "t" has invalid pattern: .*`,
+	}, {
+		config: `options: {t: {type: enum, values: [a, b], default: c}}`,
+		err:    `invalid config default: option "t" expected one of "a", "b", got "c"`,
+	}, {
+		config: `options: {t: {type: int, min: 1, default: 0}}`,
+		err:    `invalid config default: option "t" expected at least 1, got 0`,
+	}} {
+		c.Logf("test %d: %s", i, test.config)
+		_, err := charm.ReadConfig(bytes.NewBuffer([]byte(test.config)))
+		c.Check(err, ErrorMatches, test.err)
+	}
+}
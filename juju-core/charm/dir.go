@@ -23,6 +23,7 @@ type Dir struct {
 	Path     string
 	meta     *Meta
 	config   *Config
+	actions  *Actions
 	revision int
 }
 
@@ -53,6 +54,18 @@ func ReadDir(path string) (dir *Dir, err error) {
 			return nil, err
 		}
 	}
+	file, err = os.Open(dir.join("actions.yaml"))
+	if _, ok := err.(*os.PathError); ok {
+		dir.actions = NewActions()
+	} else if err != nil {
+		return nil, err
+	} else {
+		dir.actions, err = ReadActions(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
 	if file, err = os.Open(dir.join("revision")); err == nil {
 		_, err = fmt.Fscan(file, &dir.revision)
 		file.Close()
@@ -91,6 +104,12 @@ func (dir *Dir) Config() *Config {
 	return dir.config
 }
 
+// Actions returns the Actions representing the actions.yaml file
+// for the charm expanded in dir.
+func (dir *Dir) Actions() *Actions {
+	return dir.actions
+}
+
 // SetRevision changes the charm revision number. This affects
 // the revision reported by Revision and the revision of the
 // charm bundled by BundleTo.
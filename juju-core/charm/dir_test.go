@@ -40,6 +40,7 @@ func (s *DirSuite) TestReadDirWithoutConfig(c *C) {
 	// A lacking config.yaml file still causes a proper
 	// Config value to be returned.
 	c.Assert(dir.Config().Options, HasLen, 0)
+	c.Assert(dir.Actions().ActionSpecs, HasLen, 0)
 }
 
 func (s *DirSuite) TestBundleTo(c *C) {
@@ -17,6 +17,13 @@ const (
 	UpgradeCharm  Kind = "upgrade-charm"
 	Stop          Kind = "stop"
 
+	// These hooks are associated with the leadership of the unit's service.
+	// The leader-elected hook runs on the unit when it is elected leader; the
+	// leader-settings-changed hook runs on the other units when the leader
+	// writes new settings.
+	LeaderElected         Kind = "leader-elected"
+	LeaderSettingsChanged Kind = "leader-settings-changed"
+
 	// These hooks require an associated relation, and the name of the relation
 	// unit whose change triggered the hook. The hook file names that these
 	// kinds represent will be prefixed by the relation name; for example,
@@ -29,6 +36,17 @@ const (
 	// will be prefixed by the relation name, just like the other Relation* Kind
 	// values.
 	RelationBroken Kind = "relation-broken"
+
+	// These hooks require an associated store. The hook file names that
+	// these kinds represent will be prefixed by the storage name; for
+	// example, "data-storage-attached".
+	StorageAttached  Kind = "storage-attached"
+	StorageDetaching Kind = "storage-detaching"
+
+	// This hook runs an action requested by the user. The represented hook
+	// file is the action of the same name in the actions directory of the
+	// charm, rather than in its hooks directory.
+	Action Kind = "action"
 )
 
 var unitHooks = []Kind{
@@ -37,6 +55,8 @@ var unitHooks = []Kind{
 	ConfigChanged,
 	UpgradeCharm,
 	Stop,
+	LeaderElected,
+	LeaderSettingsChanged,
 }
 
 // UnitHooks returns all known unit hook kinds.
@@ -60,6 +80,18 @@ func RelationHooks() []Kind {
 	return hooks
 }
 
+var storageHooks = []Kind{
+	StorageAttached,
+	StorageDetaching,
+}
+
+// StorageHooks returns all known storage hook kinds.
+func StorageHooks() []Kind {
+	hooks := make([]Kind, len(storageHooks))
+	copy(hooks, storageHooks)
+	return hooks
+}
+
 // IsRelation returns whether the Kind represents a relation hook.
 func (kind Kind) IsRelation() bool {
 	switch kind {
@@ -68,3 +100,12 @@ func (kind Kind) IsRelation() bool {
 	}
 	return false
 }
+
+// IsStorage returns whether the Kind represents a storage hook.
+func (kind Kind) IsStorage() bool {
+	switch kind {
+	case StorageAttached, StorageDetaching:
+		return true
+	}
+	return false
+}
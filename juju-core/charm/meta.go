@@ -48,6 +48,47 @@ type Relation struct {
 	Scope     RelationScope
 }
 
+// ImplementedBy returns whether the relation is implemented by the supplied charm.
+func (r Relation) ImplementedBy(ch Charm) bool {
+	if r.IsImplicit() {
+		return true
+	}
+	var m map[string]Relation
+	switch r.Role {
+	case RoleProvider:
+		m = ch.Meta().Provides
+	case RoleRequirer:
+		m = ch.Meta().Requires
+	case RolePeer:
+		m = ch.Meta().Peers
+	default:
+		panic(fmt.Errorf("unknown relation role %q", r.Role))
+	}
+	rel, found := m[r.Name]
+	if !found {
+		return false
+	}
+	if rel.Interface == r.Interface {
+		switch r.Scope {
+		case ScopeGlobal:
+			return rel.Scope != ScopeContainer
+		case ScopeContainer:
+			return true
+		default:
+			panic(fmt.Errorf("unknown relation scope %q", r.Scope))
+		}
+	}
+	return false
+}
+
+// IsImplicit returns whether the relation is supplied by juju itself,
+// rather than by a charm.
+func (r Relation) IsImplicit() bool {
+	return (r.Name == "juju-info" &&
+		r.Interface == "juju-info" &&
+		r.Role == RoleProvider)
+}
+
 // Meta represents all the known content that may be defined
 // within a charm's metadata.yaml file.
 type Meta struct {
@@ -61,6 +102,8 @@ type Meta struct {
 	Format      int                 `bson:",omitempty"`
 	OldRevision int                 `bson:",omitempty"` // Obsolete
 	Categories  []string            `bson:",omitempty"`
+	Storage     map[string]Storage  `bson:",omitempty"`
+	Resources   map[string]Resource `bson:",omitempty"`
 }
 
 func generateRelationHooks(relName string, allHooks map[string]bool) {
@@ -69,9 +112,15 @@ func generateRelationHooks(relName string, allHooks map[string]bool) {
 	}
 }
 
+func generateStorageHooks(storageName string, allHooks map[string]bool) {
+	for _, hookName := range hooks.StorageHooks() {
+		allHooks[fmt.Sprintf("%s-%s", storageName, hookName)] = true
+	}
+}
+
 // Hooks returns a map of all possible valid hooks, taking relations
-// into account. It's a map to enable fast lookups, and the value is
-// always true.
+// and storage into account. It's a map to enable fast lookups, and the
+// value is always true.
 func (m Meta) Hooks() map[string]bool {
 	allHooks := make(map[string]bool)
 	// Unit hooks
@@ -88,6 +137,10 @@ func (m Meta) Hooks() map[string]bool {
 	for hookName := range m.Peers {
 		generateRelationHooks(hookName, allHooks)
 	}
+	// Storage hooks
+	for storageName := range m.Storage {
+		generateStorageHooks(storageName, allHooks)
+	}
 	return allHooks
 }
 
@@ -131,6 +184,8 @@ func ReadMeta(r io.Reader) (meta *Meta, err error) {
 	meta.Peers = parseRelations(m["peers"], RolePeer)
 	meta.Format = int(m["format"].(int64))
 	meta.Categories = parseCategories(m["categories"])
+	meta.Storage = parseStorage(m["storage"])
+	meta.Resources = parseResources(m["resources"])
 	if subordinate := m["subordinate"]; subordinate != nil {
 		meta.Subordinate = subordinate.(bool)
 	}
@@ -184,6 +239,12 @@ func (meta Meta) Check() error {
 	if err := checkRelations(meta.Peers, RolePeer); err != nil {
 		return err
 	}
+	if err := meta.checkStorage(); err != nil {
+		return err
+	}
+	if err := meta.checkResources(); err != nil {
+		return err
+	}
 
 	// Subordinate charms must have at least one relation that
 	// has container scope, otherwise they can't relate to the
@@ -316,6 +377,8 @@ var charmSchema = schema.FieldMap(
 		"format":      schema.Int(),
 		"subordinate": schema.Bool(),
 		"categories":  schema.List(schema.String()),
+		"storage":     schema.StringMap(storageSchema),
+		"resources":   schema.StringMap(resourceSchema),
 	},
 	schema.Defaults{
 		"provides":    schema.Omit,
@@ -325,5 +388,7 @@ var charmSchema = schema.FieldMap(
 		"format":      1,
 		"subordinate": schema.Omit,
 		"categories":  schema.Omit,
+		"storage":     schema.Omit,
+		"resources":   schema.Omit,
 	},
 )
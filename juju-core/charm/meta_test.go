@@ -239,6 +239,143 @@ requires:
   innocuous: juju-info`, "")
 }
 
+func (s *MetaSuite) TestReadStorage(c *C) {
+	meta, err := charm.ReadMeta(repoMeta("storage"))
+	c.Assert(err, IsNil)
+	c.Assert(meta.Storage, DeepEquals, map[string]charm.Storage{
+		"data": {
+			Name:        "data",
+			Description: "The data directory.",
+			Type:        charm.StorageFilesystem,
+			Location:    "/srv/data",
+			MinimumSize: 1024,
+		},
+		"disks": {
+			Name:        "disks",
+			Type:        charm.StorageBlock,
+			MinimumSize: 512,
+		},
+	})
+
+	meta, err = charm.ReadMeta(repoMeta("dummy"))
+	c.Assert(err, IsNil)
+	c.Assert(meta.Storage, IsNil)
+}
+
+var storageConstraintsTests = []struct {
+	storage string
+	err     string
+}{
+	{
+		"storage:\n  data:\n    type: filesystem\n    location: /srv\n    minimum-size: 1.5G\n    read-only: true",
+		"",
+	}, {
+		"storage:\n  data:\n    location: /srv",
+		`metadata: storage.data.type: unexpected value <nil>`,
+	}, {
+		"storage:\n  data:\n    type: tape",
+		`metadata: storage.data.type: unexpected value "tape"`,
+	}, {
+		"storage:\n  data:\n    type: filesystem\n    location: /srv\n    minimum-size: lots",
+		`metadata: storage.data.minimum-size: expected non-negative size with optional M/G/T suffix, got "lots"`,
+	}, {
+		"storage:\n  data:\n    type: filesystem\n    location: /srv\n    minimum-size: -1",
+		`metadata: storage.data.minimum-size: expected non-negative size, got -1`,
+	}, {
+		"storage:\n  data:\n    type: filesystem",
+		`charm "a" filesystem storage "data" has no location`,
+	}, {
+		"storage:\n  data:\n    type: filesystem\n    location: srv",
+		`charm "a" filesystem storage "data" has a relative location: "srv"`,
+	}, {
+		"storage:\n  data:\n    type: block\n    location: /srv",
+		`charm "a" block storage "data" cannot have a location`,
+	}, {
+		"storage:\n  juju-data:\n    type: block",
+		`charm "a" using a reserved storage name: "juju-data"`,
+	},
+}
+
+func (s *MetaSuite) TestStorageConstraints(c *C) {
+	prefix := "name: a\nsummary: b\ndescription: c\n"
+	for i, t := range storageConstraintsTests {
+		c.Logf("test %d", i)
+		meta, err := charm.ReadMeta(strings.NewReader(prefix + t.storage))
+		if t.err != "" {
+			c.Assert(err, ErrorMatches, t.err)
+			c.Assert(meta, IsNil)
+		} else {
+			c.Assert(err, IsNil)
+			c.Assert(meta.Storage["data"].MinimumSize, Equals, uint64(1536))
+			c.Assert(meta.Storage["data"].ReadOnly, Equals, true)
+		}
+	}
+}
+
+func (s *MetaSuite) TestReadResources(c *C) {
+	meta, err := charm.ReadMeta(repoMeta("resources"))
+	c.Assert(err, IsNil)
+	c.Assert(meta.Resources, DeepEquals, map[string]charm.Resource{
+		"jdk": {
+			Name:        "jdk",
+			Description: "The Java runtime.",
+			Filename:    "jdk.tar.gz",
+		},
+		"vendor": {
+			Name:        "vendor",
+			Description: "The vendor libraries.",
+			Filename:    "vendor",
+		},
+	})
+
+	meta, err = charm.ReadMeta(repoMeta("dummy"))
+	c.Assert(err, IsNil)
+	c.Assert(meta.Resources, IsNil)
+}
+
+var resourceConstraintsTests = []struct {
+	resources string
+	err       string
+}{
+	{
+		"resources:\n  jdk:\n    filename: jdk.tgz",
+		"",
+	}, {
+		"resources:\n  jdk:\n    filename: 42",
+		`metadata: resources.jdk.filename: expected string, got 42`,
+	}, {
+		"resources:\n  jdk:\n    filename: lib/jdk.tgz",
+		`charm "a" resource "jdk" has an invalid filename: "lib/jdk.tgz"`,
+	}, {
+		"resources:\n  jdk:\n    filename: ..",
+		`charm "a" resource "jdk" has an invalid filename: ".."`,
+	}, {
+		"resources:\n  juju-jdk:\n    filename: jdk.tgz",
+		`charm "a" using a reserved resource name: "juju-jdk"`,
+	}, {
+		"resources:\n  jdk.tgz:\n    filename: jdk.tgz",
+		`charm "a" has an invalid resource name: "jdk.tgz"`,
+	}, {
+		"resources:\n  $jdk:\n    filename: jdk.tgz",
+		`charm "a" has an invalid resource name: "\$jdk"`,
+	},
+}
+
+func (s *MetaSuite) TestResourceConstraints(c *C) {
+	prefix := "name: a\nsummary: b\ndescription: c\n"
+	for i, t := range resourceConstraintsTests {
+		c.Logf("test %d", i)
+		meta, err := charm.ReadMeta(strings.NewReader(prefix + t.resources))
+		if t.err != "" {
+			c.Assert(err, ErrorMatches, t.err)
+			c.Assert(meta, IsNil)
+		} else {
+			c.Assert(err, IsNil)
+			c.Assert(meta.Resources["jdk"].Filename, Equals, "jdk.tgz")
+		}
+	}
+}
+
 func (s *MetaSuite) TestCheckMismatchedRelationName(c *C) {
 	// This  Check case cannot be covered by the above
 	// TestRelationsConstraints tests.
@@ -336,6 +473,8 @@ func (s *MetaSuite) TestMetaHooks(c *C) {
 		"config-changed":                    true,
 		"upgrade-charm":                     true,
 		"stop":                              true,
+		"leader-elected":                    true,
+		"leader-settings-changed":           true,
 		"cache-relation-joined":             true,
 		"cache-relation-changed":            true,
 		"cache-relation-departed":           true,
@@ -406,6 +545,23 @@ func (s *MetaSuite) TestCodecRoundTrip(c *C) {
 		Categories:  []string{"quxxxx", "quxxxxx"},
 		Format:      10,
 		OldRevision: 11,
+		Storage: map[string]charm.Storage{
+			"qux": {
+				Name:        "qux",
+				Description: "quxx",
+				Type:        charm.StorageFilesystem,
+				Location:    "/quxxx",
+				MinimumSize: 42,
+				ReadOnly:    true,
+			},
+		},
+		Resources: map[string]charm.Resource{
+			"qux": {
+				Name:        "qux",
+				Description: "quxx",
+				Filename:    "quxxx",
+			},
+		},
 	}
 	for i, codec := range codecs {
 		c.Logf("codec %d", i)
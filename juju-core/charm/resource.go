@@ -0,0 +1,76 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"strings"
+
+	"launchpad.net/juju-core/schema"
+)
+
+// Resource represents a file needed by a charm, as declared in the
+// resources section of its metadata. The content of a resource is
+// uploaded with the charm, so that the charm need not fetch it itself.
+type Resource struct {
+	Name        string
+	Description string
+	// Filename is the name under which the resource is made available
+	// to the charm. It defaults to the name of the resource.
+	Filename string
+}
+
+func parseResources(resources interface{}) map[string]Resource {
+	if resources == nil {
+		return nil
+	}
+	result := make(map[string]Resource)
+	for name, res := range resources.(map[string]interface{}) {
+		resMap := res.(map[string]interface{})
+		resource := Resource{
+			Name:     name,
+			Filename: name,
+		}
+		if desc := resMap["description"]; desc != nil {
+			resource.Description = desc.(string)
+		}
+		if filename := resMap["filename"]; filename != nil {
+			resource.Filename = filename.(string)
+		}
+		result[name] = resource
+	}
+	return result
+}
+
+// checkResources checks that the resources declared by the charm are
+// well-formed.
+func (meta Meta) checkResources() error {
+	for name, res := range meta.Resources {
+		if res.Name != name {
+			return fmt.Errorf("charm %q has mismatched resource name %q; expected %q", meta.Name, res.Name, name)
+		}
+		if reservedName(name) {
+			return fmt.Errorf("charm %q using a reserved resource name: %q", meta.Name, name)
+		}
+		// Resources are recorded in state keyed by name.
+		if strings.ContainsAny(name, ".$") {
+			return fmt.Errorf("charm %q has an invalid resource name: %q", meta.Name, name)
+		}
+		if res.Filename == "" || res.Filename == "." || res.Filename == ".." || strings.Contains(res.Filename, "/") {
+			return fmt.Errorf("charm %q resource %q has an invalid filename: %q", meta.Name, name, res.Filename)
+		}
+	}
+	return nil
+}
+
+var resourceSchema = schema.FieldMap(
+	schema.Fields{
+		"description": schema.String(),
+		"filename":    schema.String(),
+	},
+	schema.Defaults{
+		"description": schema.Omit,
+		"filename":    schema.Omit,
+	},
+)
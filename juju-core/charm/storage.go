@@ -0,0 +1,158 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"launchpad.net/juju-core/schema"
+)
+
+// StorageType defines how a store is presented to the charm.
+type StorageType string
+
+const (
+	// StorageBlock is a raw block device, which the charm is
+	// responsible for formatting and mounting.
+	StorageBlock StorageType = "block"
+
+	// StorageFilesystem is a formatted filesystem, mounted at the
+	// location requested by the charm.
+	StorageFilesystem StorageType = "filesystem"
+)
+
+// Storage represents a store required by a charm, as declared in the
+// storage section of its metadata.
+type Storage struct {
+	// Name is the name of the store. The storage hooks of the charm
+	// are prefixed by it; for example, "data-storage-attached".
+	Name        string
+	Description string
+	Type        StorageType
+	// Location is the path at which a filesystem store is mounted.
+	// It is empty for block stores.
+	Location string
+	// MinimumSize is the minimum size of the store, in MiB.
+	MinimumSize uint64
+	ReadOnly    bool
+}
+
+func parseStorage(stores interface{}) map[string]Storage {
+	if stores == nil {
+		return nil
+	}
+	result := make(map[string]Storage)
+	for name, store := range stores.(map[string]interface{}) {
+		storeMap := store.(map[string]interface{})
+		storage := Storage{
+			Name:     name,
+			Type:     StorageType(storeMap["type"].(string)),
+			ReadOnly: storeMap["read-only"].(bool),
+		}
+		if desc := storeMap["description"]; desc != nil {
+			storage.Description = desc.(string)
+		}
+		if location := storeMap["location"]; location != nil {
+			storage.Location = location.(string)
+		}
+		if size := storeMap["minimum-size"]; size != nil {
+			storage.MinimumSize = size.(uint64)
+		}
+		result[name] = storage
+	}
+	return result
+}
+
+// checkStorage checks that the stores declared by the charm are
+// well-formed.
+func (meta Meta) checkStorage() error {
+	for name, store := range meta.Storage {
+		if store.Name != name {
+			return fmt.Errorf("charm %q has mismatched storage name %q; expected %q", meta.Name, store.Name, name)
+		}
+		if reservedName(name) {
+			return fmt.Errorf("charm %q using a reserved storage name: %q", meta.Name, name)
+		}
+		switch store.Type {
+		case StorageFilesystem:
+			if store.Location == "" {
+				return fmt.Errorf("charm %q filesystem storage %q has no location", meta.Name, name)
+			}
+			if !strings.HasPrefix(store.Location, "/") {
+				return fmt.Errorf("charm %q filesystem storage %q has a relative location: %q", meta.Name, name, store.Location)
+			}
+		case StorageBlock:
+			if store.Location != "" {
+				return fmt.Errorf("charm %q block storage %q cannot have a location", meta.Name, name)
+			}
+		default:
+			return fmt.Errorf("charm %q storage %q has unknown type %q", meta.Name, name, store.Type)
+		}
+	}
+	return nil
+}
+
+var storageSchema = schema.FieldMap(
+	schema.Fields{
+		"type":         schema.OneOf(schema.Const(string(StorageBlock)), schema.Const(string(StorageFilesystem))),
+		"description":  schema.String(),
+		"location":     schema.String(),
+		"minimum-size": storageSizeC{},
+		"read-only":    schema.Bool(),
+	},
+	schema.Defaults{
+		"description":  schema.Omit,
+		"location":     schema.Omit,
+		"minimum-size": schema.Omit,
+		"read-only":    false,
+	},
+)
+
+// storageSizeC coerces a store size, given either as a number of MiB
+// or as a number with an M, G or T suffix, to a number of MiB.
+type storageSizeC struct{}
+
+var sizeSuffixes = map[string]float64{
+	"M": 1,
+	"G": 1024,
+	"T": 1024 * 1024,
+}
+
+func (c storageSizeC) Coerce(v interface{}, path []string) (interface{}, error) {
+	if n, err := schema.Int().Coerce(v, path); err == nil {
+		if n.(int64) < 0 {
+			return nil, fmt.Errorf("%s: expected non-negative size, got %d", pathString(path), n)
+		}
+		return uint64(n.(int64)), nil
+	}
+	s, err := schema.String().Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	str := s.(string)
+	mult := 1.0
+	if str != "" {
+		if m, ok := sizeSuffixes[str[len(str)-1:]]; ok {
+			str = str[:len(str)-1]
+			mult = m
+		}
+	}
+	val, err := strconv.ParseFloat(str, 64)
+	if err != nil || val < 0 {
+		return nil, fmt.Errorf("%s: expected non-negative size with optional M/G/T suffix, got %q", pathString(path), s)
+	}
+	return uint64(math.Ceil(val * mult)), nil
+}
+
+// pathString formats a schema path the way the schema package does in
+// its own errors.
+func pathString(path []string) string {
+	if len(path) > 0 && path[0] == "." {
+		return strings.Join(path[1:], "")
+	}
+	return strings.Join(path, "")
+}
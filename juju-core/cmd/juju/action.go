@@ -0,0 +1,138 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/names"
+)
+
+// DoCommand queues an action to be run by the agent of a unit.
+type DoCommand struct {
+	cmd.EnvCommandBase
+	UnitName     string
+	ActionName   string
+	ParamStrings map[string]string
+}
+
+const doDoc = `
+Queue the action, as defined by the charm of the unit, to be run by the
+agent of the unit with the parameters given as key=value pairs. The
+parameters not given take their default value. The id of the queued action
+is printed, and can be passed to juju action-result to get the outcome of
+the action once it ran.
+`
+
+func (c *DoCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "do",
+		Args:    "<unit> <action> [key=value ...]",
+		Purpose: "queue an action on a unit",
+		Doc:     doDoc,
+	}
+}
+
+func (c *DoCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no unit specified")
+	}
+	c.UnitName, args = args[0], args[1:]
+	if !names.IsUnit(c.UnitName) {
+		return fmt.Errorf("invalid unit name %q", c.UnitName)
+	}
+	if len(args) == 0 {
+		return errors.New("no action specified")
+	}
+	c.ActionName = args[0]
+	params, err := parse(args[1:])
+	if err != nil {
+		return err
+	}
+	c.ParamStrings = params
+	return nil
+}
+
+func (c *DoCommand) Run(ctx *cmd.Context) error {
+	client, err := juju.NewAPIClientFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	id, err := client.EnqueueAction(c.UnitName, c.ActionName, c.ParamStrings)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.Stdout, id)
+	return nil
+}
+
+// ActionResultCommand reports the outcome of an action.
+type ActionResultCommand struct {
+	cmd.EnvCommandBase
+	out      cmd.Output
+	ActionId string
+}
+
+// actionResult holds the outcome of an action.
+type actionResult struct {
+	Id      string                 `json:"id" yaml:"id"`
+	Unit    string                 `json:"unit" yaml:"unit"`
+	Action  string                 `json:"action" yaml:"action"`
+	Params  map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+	Status  string                 `json:"status" yaml:"status"`
+	Results map[string]interface{} `json:"results,omitempty" yaml:"results,omitempty"`
+	Message string                 `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func (c *ActionResultCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "action-result",
+		Args:    "<action id>",
+		Purpose: "show the outcome of an action",
+		Doc:     "Show the status of the action queued by juju do, and its results once it ran.",
+	}
+}
+
+func (c *ActionResultCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *ActionResultCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no action id specified")
+	}
+	c.ActionId = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *ActionResultCommand) Run(ctx *cmd.Context) error {
+	client, err := juju.NewAPIClientFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	info, err := client.ActionInfo(c.ActionId)
+	if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, actionResult{
+		Id:      info.Id,
+		Unit:    info.UnitName,
+		Action:  info.Name,
+		Params:  info.Params,
+		Status:  info.Status,
+		Results: info.Results,
+		Message: info.Message,
+	})
+}
@@ -0,0 +1,120 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	. "launchpad.net/gocheck"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/testing"
+)
+
+type ActionSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&ActionSuite{})
+
+func (s *ActionSuite) SetUpTest(c *C) {
+	s.RepoSuite.SetUpTest(c)
+	svc, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	_, err = svc.AddUnit()
+	c.Assert(err, IsNil)
+}
+
+func (s *ActionSuite) TestDoInitErrors(c *C) {
+	for i, t := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no unit specified",
+	}, {
+		args: []string{"dummy"},
+		err:  `invalid unit name "dummy"`,
+	}, {
+		args: []string{"dummy/0"},
+		err:  "no action specified",
+	}, {
+		args: []string{"dummy/0", "snapshot", "outfile"},
+		err:  `invalid option: "outfile"`,
+	}} {
+		c.Logf("test %d: %q", i, t.args)
+		err := testing.InitCommand(&DoCommand{}, t.args)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *ActionSuite) TestDo(c *C) {
+	ctx, err := testing.RunCommand(c, &DoCommand{}, []string{"dummy/0", "snapshot", "compression=3"})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, "dummy/0:0\n")
+	action, err := s.State.Action("dummy/0:0")
+	c.Assert(err, IsNil)
+	c.Assert(action.Name(), Equals, "snapshot")
+	c.Assert(action.Params(), DeepEquals, map[string]interface{}{
+		"outfile":     "foo.bz2",
+		"compression": int64(3),
+	})
+	c.Assert(action.Status(), Equals, params.ActionPending)
+}
+
+func (s *ActionSuite) TestDoErrors(c *C) {
+	for i, t := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"dummy/1", "snapshot"},
+		err:  `unit "dummy/1" not found`,
+	}, {
+		args: []string{"dummy/0", "restore"},
+		err:  `unknown action "restore"`,
+	}, {
+		args: []string{"dummy/0", "snapshot", "compression=high"},
+		err:  `option "compression" expected int, got "high"`,
+	}, {
+		args: []string{"dummy/0", "snapshot", "level=3"},
+		err:  `unknown parameter "level" of action "snapshot"`,
+	}} {
+		c.Logf("test %d: %q", i, t.args)
+		_, err := testing.RunCommand(c, &DoCommand{}, t.args)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *ActionSuite) TestActionResult(c *C) {
+	unit, err := s.State.Unit("dummy/0")
+	c.Assert(err, IsNil)
+	action, err := unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+
+	ctx, err := testing.RunCommand(c, &ActionResultCommand{}, []string{action.Id()})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, `
+id: dummy/0:0
+unit: dummy/0
+action: snapshot
+params:
+  outfile: foo.bz2
+status: pending
+`[1:])
+
+	err = action.Complete(map[string]interface{}{"size": "42"})
+	c.Assert(err, IsNil)
+	ctx, err = testing.RunCommand(c, &ActionResultCommand{}, []string{"--format", "json", action.Id()})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals,
+		`{"id":"dummy/0:0","unit":"dummy/0","action":"snapshot","params":{"outfile":"foo.bz2"},"status":"completed","results":{"size":"42"}}`+"\n")
+
+	_, err = testing.RunCommand(c, &ActionResultCommand{}, []string{"dummy/0:1"})
+	c.Assert(err, ErrorMatches, `action "dummy/0:1" not found`)
+}
+
+func (s *ActionSuite) TestActionResultInitErrors(c *C) {
+	err := testing.InitCommand(&ActionResultCommand{}, nil)
+	c.Assert(err, ErrorMatches, "no action id specified")
+	err = testing.InitCommand(&ActionResultCommand{}, []string{"dummy/0:0", "dummy/0:1"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["dummy/0:1"\]`)
+}
@@ -15,9 +15,20 @@ import (
 	"launchpad.net/juju-core/juju"
 	"launchpad.net/juju-core/log"
 	"launchpad.net/juju-core/names"
-	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
 )
 
+const addMachineDoc = `
+Machines are created in a clean state and ready to have units deployed.
+
+Supported container types are lxc and docker.
+
+Examples:
+   juju add-machine              (starts a new machine)
+   juju add-machine lxc          (starts a new machine with an lxc container)
+   juju add-machine docker:4     (starts a new docker container on machine 4)
+`
+
 // AddMachineCommand starts a new machine and registers it in the environment.
 type AddMachineCommand struct {
 	cmd.EnvCommandBase
@@ -34,7 +45,7 @@ func (c *AddMachineCommand) Info() *cmd.Info {
 		Name:    "add-machine",
 		Args:    "[<container>:machine | <container>]",
 		Purpose: "start a new, empty machine and optionally a container, or add a container to a machine",
-		Doc:     "Machines are created in a clean state and ready to have units deployed.",
+		Doc:     addMachineDoc,
 	}
 }
 
@@ -68,34 +79,32 @@ func (c *AddMachineCommand) Init(args []string) error {
 }
 
 func (c *AddMachineCommand) Run(_ *cmd.Context) error {
-	conn, err := juju.NewConnFromName(c.EnvName)
+	client, err := juju.NewAPIClientFromName(c.EnvName)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
+	defer client.Close()
 
-	series := c.Series
-	if series == "" {
-		conf, err := conn.State.EnvironConfig()
-		if err != nil {
-			return err
-		}
-		series = conf.DefaultSeries()
-	}
-	params := state.AddMachineParams{
+	machineParams := params.AddMachineParams{
 		ParentId:      c.MachineId,
 		ContainerType: c.ContainerType,
-		Series:        series,
+		Series:        c.Series,
 		Constraints:   c.Constraints,
-		Jobs:          []state.MachineJob{state.JobHostUnits},
+		Jobs:          []params.MachineJob{params.JobHostUnits},
 	}
-	m, err := conn.State.AddMachineWithConstraints(&params)
-	if err == nil {
-		if c.ContainerType == "" {
-			log.Infof("created machine %v", m)
-		} else {
-			log.Infof("created %q container on machine %v", c.ContainerType, m)
-		}
+	results, err := client.AddMachines([]params.AddMachineParams{machineParams})
+	if err != nil {
+		return err
 	}
-	return err
+	// Currently, only one machine is added, but in future there may be several added in one call.
+	machineInfo := results[0]
+	if machineInfo.Error != nil {
+		return machineInfo.Error
+	}
+	if c.ContainerType == "" {
+		log.Infof("created machine %v", machineInfo.Machine)
+	} else {
+		log.Infof("created %q container on machine %v", c.ContainerType, machineInfo.Machine)
+	}
+	return nil
 }
@@ -75,22 +75,38 @@ func (s *AddMachineSuite) TestAddContainerToNewMachine(c *C) {
 	for i, ctype := range instance.SupportedContainerTypes {
 		err := runAddMachine(c, fmt.Sprintf("%s", ctype))
 		c.Assert(err, IsNil)
-		s._assertAddContainer(c, strconv.Itoa(2*i), fmt.Sprintf("0/%s/0", ctype), ctype)
+		machineId := strconv.Itoa(i)
+		s._assertAddContainer(c, machineId, fmt.Sprintf("%s/%s/0", machineId, ctype), ctype)
 	}
 }
 
 func (s *AddMachineSuite) TestAddContainerToExistingMachine(c *C) {
 	err := runAddMachine(c)
 	c.Assert(err, IsNil)
-	err = runAddMachine(c)
-	c.Assert(err, IsNil)
 	for i, container := range instance.SupportedContainerTypes {
-		err := runAddMachine(c, fmt.Sprintf("%s:1", container))
+		machineId := strconv.Itoa(i + 1)
+		err = runAddMachine(c)
+		c.Assert(err, IsNil)
+		err = runAddMachine(c, fmt.Sprintf("%s:%s", container, machineId))
 		c.Assert(err, IsNil)
-		s._assertAddContainer(c, "1", fmt.Sprintf("1/%s/%d", container, i), container)
+		s._assertAddContainer(c, machineId, fmt.Sprintf("%s/%s/0", machineId, container), container)
 	}
 }
 
+func (s *AddMachineSuite) TestAddContainerToUnsupportedMachine(c *C) {
+	err := runAddMachine(c)
+	c.Assert(err, IsNil)
+	m, err := s.State.Machine("0")
+	c.Assert(err, IsNil)
+	err = m.SetSupportedContainers([]instance.ContainerType{instance.LXC})
+	c.Assert(err, IsNil)
+	err = runAddMachine(c, "docker:0")
+	c.Assert(err, ErrorMatches, "cannot add a new container: machine 0 cannot host docker containers")
+	err = runAddMachine(c, "lxc:0")
+	c.Assert(err, IsNil)
+	s._assertAddContainer(c, "0", "0/lxc/0", instance.LXC)
+}
+
 func (s *AddMachineSuite) TestAddMachineErrors(c *C) {
 	err := runAddMachine(c, ":lxc")
 	c.Assert(err, ErrorMatches, `malformed container argument ":lxc"`)
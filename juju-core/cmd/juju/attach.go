@@ -0,0 +1,132 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state/api"
+)
+
+// resourcesValue implements gnuflag.Value on a map from resource names
+// to the paths of the files to upload for them.
+type resourcesValue map[string]string
+
+// Set parses a name=path pair; the flag may be given several times.
+func (v *resourcesValue) Set(value string) error {
+	kv, err := parseResources([]string{value})
+	if err != nil {
+		return err
+	}
+	if *v == nil {
+		*v = make(resourcesValue)
+	}
+	for name, path := range kv {
+		(*v)[name] = path
+	}
+	return nil
+}
+
+func (v *resourcesValue) String() string {
+	var pairs []string
+	for name, path := range *v {
+		pairs = append(pairs, name+"="+path)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
+// parseResources parses name=path pairs into a map.
+func parseResources(args []string) (map[string]string, error) {
+	resources := make(map[string]string)
+	for _, arg := range args {
+		s := strings.SplitN(arg, "=", 2)
+		if len(s) != 2 || s[0] == "" || s[1] == "" {
+			return nil, fmt.Errorf("invalid resource %q, expected name=path", arg)
+		}
+		resources[s[0]] = s[1]
+	}
+	return resources, nil
+}
+
+// putResources uploads the files for the given resources of the
+// service, in name order.
+func putResources(ctx *cmd.Context, client *api.Client, service string, resources map[string]string) error {
+	var resourceNames []string
+	for name := range resources {
+		resourceNames = append(resourceNames, name)
+	}
+	sort.Strings(resourceNames)
+	for _, name := range resourceNames {
+		if err := putResource(ctx, client, service, name, resources[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putResource uploads the content of the file at path for the named
+// resource of the service.
+func putResource(ctx *cmd.Context, client *api.Client, service, name, path string) error {
+	f, err := os.Open(ctx.AbsPath(path))
+	if err != nil {
+		return fmt.Errorf("cannot read resource %q: %v", name, err)
+	}
+	defer f.Close()
+	return client.ServicePutResource(service, name, f)
+}
+
+// AttachCommand uploads new content for resources of a service.
+type AttachCommand struct {
+	cmd.EnvCommandBase
+	ServiceName string
+	Resources   map[string]string
+}
+
+const attachDoc = `
+Upload the given files as new content for resources declared by the charm
+of the service. The files are made available to the units of the service
+through the resource-get hook tool, and the revision of each resource is
+incremented.
+`
+
+func (c *AttachCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "attach",
+		Args:    "<service> <resource>=<path> ...",
+		Purpose: "upload resources of a service",
+		Doc:     attachDoc,
+	}
+}
+
+func (c *AttachCommand) Init(args []string) (err error) {
+	if len(args) == 0 {
+		return errors.New("no service specified")
+	}
+	c.ServiceName, args = args[0], args[1:]
+	if !names.IsService(c.ServiceName) {
+		return fmt.Errorf("invalid service name %q", c.ServiceName)
+	}
+	if len(args) == 0 {
+		return errors.New("no resources specified")
+	}
+	c.Resources, err = parseResources(args)
+	return err
+}
+
+func (c *AttachCommand) Run(ctx *cmd.Context) error {
+	client, err := juju.NewAPIClientFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return putResources(ctx, client, c.ServiceName, c.Resources)
+}
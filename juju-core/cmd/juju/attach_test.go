@@ -0,0 +1,101 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	coretesting "launchpad.net/juju-core/testing"
+)
+
+type AttachSuite struct {
+	testing.RepoSuite
+	svc *state.Service
+}
+
+var _ = Suite(&AttachSuite{})
+
+func (s *AttachSuite) SetUpTest(c *C) {
+	s.RepoSuite.SetUpTest(c)
+	var err error
+	s.svc, err = s.State.AddService("resources", s.AddTestingCharm(c, "resources"))
+	c.Assert(err, IsNil)
+}
+
+func writeResource(c *C, content string) string {
+	path := filepath.Join(c.MkDir(), "resource")
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	c.Assert(err, IsNil)
+	return path
+}
+
+func assertResourceContent(c *C, res *state.Resource, content string) {
+	resp, err := http.Get(res.URL().String())
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, content)
+}
+
+func (s *AttachSuite) TestInitErrors(c *C) {
+	for i, t := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no service specified",
+	}, {
+		args: []string{"resources/0"},
+		err:  `invalid service name "resources/0"`,
+	}, {
+		args: []string{"resources"},
+		err:  "no resources specified",
+	}, {
+		args: []string{"resources", "jdk"},
+		err:  `invalid resource "jdk", expected name=path`,
+	}, {
+		args: []string{"resources", "jdk="},
+		err:  `invalid resource "jdk=", expected name=path`,
+	}} {
+		c.Logf("test %d: %q", i, t.args)
+		err := coretesting.InitCommand(&AttachCommand{}, t.args)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *AttachSuite) TestAttach(c *C) {
+	jdk := writeResource(c, "jdk content")
+	vendor := writeResource(c, "vendor content")
+	_, err := coretesting.RunCommand(c, &AttachCommand{}, []string{"resources", "jdk=" + jdk, "vendor=" + vendor})
+	c.Assert(err, IsNil)
+	res, err := s.svc.Resource("jdk")
+	c.Assert(err, IsNil)
+	c.Assert(res.Revision(), Equals, 0)
+	assertResourceContent(c, res, "jdk content")
+	res, err = s.svc.Resource("vendor")
+	c.Assert(err, IsNil)
+	assertResourceContent(c, res, "vendor content")
+
+	jdk = writeResource(c, "new jdk content")
+	_, err = coretesting.RunCommand(c, &AttachCommand{}, []string{"resources", "jdk=" + jdk})
+	c.Assert(err, IsNil)
+	res, err = s.svc.Resource("jdk")
+	c.Assert(err, IsNil)
+	c.Assert(res.Revision(), Equals, 1)
+	assertResourceContent(c, res, "new jdk content")
+}
+
+func (s *AttachSuite) TestAttachErrors(c *C) {
+	jdk := writeResource(c, "jdk content")
+	_, err := coretesting.RunCommand(c, &AttachCommand{}, []string{"unknown", "jdk=" + jdk})
+	c.Assert(err, ErrorMatches, `service "unknown" not found`)
+	_, err = coretesting.RunCommand(c, &AttachCommand{}, []string{"resources", "jre=" + jdk})
+	c.Assert(err, ErrorMatches, `cannot set resource "jre" of service "resources": charm ".*" declares no such resource`)
+}
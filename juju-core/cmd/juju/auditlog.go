@@ -0,0 +1,137 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// AuditLogCommand shows the calls made by clients to change the
+// environment.
+type AuditLogCommand struct {
+	cmd.EnvCommandBase
+	since  string
+	user   string
+	entity string
+	limit  int
+	params params.AuditLog
+}
+
+const auditLogDoc = `
+Show the calls made by the users of the environment to change it, oldest
+first, with the user that made each call, its arguments, with secrets
+redacted, and its error if it failed. Only admin users can see the audit
+log.
+
+The calls shown can be restricted to those made since a given time, given
+in RFC3339 format or as a duration before now, to those made by a user, and
+to those concerning an entity, given as a machine id, a unit or service
+name, or a tag.
+
+Examples:
+ juju audit-log --since 24h --user bob
+ juju audit-log --since 2013-10-16T12:00:00Z --entity mysql/0
+ juju audit-log --entity wordpress --limit 10
+`
+
+func (c *AuditLogCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "audit-log",
+		Purpose: "show the changes made to the environment",
+		Doc:     auditLogDoc,
+	}
+}
+
+func (c *AuditLogCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	f.StringVar(&c.since, "since", "", "only show calls made since this time")
+	f.StringVar(&c.user, "user", "", "only show calls made by this user")
+	f.StringVar(&c.entity, "entity", "", "only show calls concerning this entity")
+	f.IntVar(&c.limit, "limit", 0, "only show this many of the most recent calls")
+}
+
+func (c *AuditLogCommand) Init(args []string) error {
+	if c.limit < 0 {
+		return fmt.Errorf("invalid limit %d", c.limit)
+	}
+	c.params = params.AuditLog{
+		User:  c.user,
+		Limit: c.limit,
+	}
+	if c.since != "" {
+		since, err := parseSince(c.since, time.Now())
+		if err != nil {
+			return err
+		}
+		c.params.Since = since
+	}
+	if c.entity != "" {
+		tag, err := entityTag(c.entity)
+		if err != nil {
+			return err
+		}
+		c.params.Entity = tag
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// parseSince returns the time given either in RFC3339 format or as a
+// duration before now.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// entityTag returns the tag of the entity given as a machine id, a unit
+// or service name, or a tag.
+func entityTag(entity string) (string, error) {
+	if _, err := names.TagKind(entity); err == nil {
+		return entity, nil
+	}
+	switch {
+	case names.IsMachine(entity):
+		return names.MachineTag(entity), nil
+	case names.IsUnit(entity):
+		return names.UnitTag(entity), nil
+	case names.IsService(entity):
+		return names.ServiceTag(entity), nil
+	}
+	return "", fmt.Errorf("invalid entity %q", entity)
+}
+
+func (c *AuditLogCommand) Run(ctx *cmd.Context) error {
+	client, err := juju.NewAPIClientFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	entries, err := client.AuditLog(c.params)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		line := fmt.Sprintf("%s %s %s", entry.Time.UTC().Format(time.RFC3339), entry.Tag, entry.Method)
+		if entry.Args != "" {
+			line += " " + entry.Args
+		}
+		if entry.Error != "" {
+			line += " failed: " + entry.Error
+		}
+		fmt.Fprintln(ctx.Stdout, line)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing"
+)
+
+type AuditLogSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = Suite(&AuditLogSuite{})
+
+func initAuditLog(args ...string) (*AuditLogCommand, error) {
+	com := &AuditLogCommand{}
+	return com, testing.InitCommand(com, args)
+}
+
+func (s *AuditLogSuite) TestInit(c *C) {
+	com, err := initAuditLog("--user", "bob", "--entity", "mysql/0", "--limit", "3")
+	c.Assert(err, IsNil)
+	c.Assert(com.params.User, Equals, "bob")
+	c.Assert(com.params.Entity, Equals, "unit-mysql-0")
+	c.Assert(com.params.Limit, Equals, 3)
+	c.Assert(com.params.Since.IsZero(), Equals, true)
+
+	com, err = initAuditLog("--since", "2013-10-16T12:00:00Z")
+	c.Assert(err, IsNil)
+	c.Assert(com.params.Since.Equal(time.Date(2013, 10, 16, 12, 0, 0, 0, time.UTC)), Equals, true)
+
+	for i, t := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"--since", "yesterday"},
+		err:  `invalid time "yesterday"`,
+	}, {
+		args: []string{"--entity", "mysql/foo"},
+		err:  `invalid entity "mysql/foo"`,
+	}, {
+		args: []string{"--limit", "-1"},
+		err:  "invalid limit -1",
+	}, {
+		args: []string{"bob"},
+		err:  `unrecognized args: \["bob"\]`,
+	}} {
+		c.Logf("test %d: %q", i, t.args)
+		_, err := initAuditLog(t.args...)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *AuditLogSuite) TestParseSince(c *C) {
+	now := time.Date(2013, 10, 16, 12, 0, 0, 0, time.UTC)
+	since, err := parseSince("90m", now)
+	c.Assert(err, IsNil)
+	c.Assert(since.Equal(now.Add(-90*time.Minute)), Equals, true)
+	_, err = parseSince("-1h", now)
+	c.Assert(err, ErrorMatches, `invalid time "-1h"`)
+}
+
+func (s *AuditLogSuite) TestEntityTag(c *C) {
+	for entity, tag := range map[string]string{
+		"0":                 "machine-0",
+		"0/lxc/1":           "machine-0-lxc-1",
+		"mysql/0":           "unit-mysql-0",
+		"wordpress":         "service-wordpress",
+		"service-wordpress": "service-wordpress",
+		"user-bob":          "user-bob",
+	} {
+		obtained, err := entityTag(entity)
+		c.Check(err, IsNil)
+		c.Check(obtained, Equals, tag)
+	}
+}
+
+func (s *AuditLogSuite) TestAuditLog(c *C) {
+	_, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	since := time.Now().Add(-time.Second).UTC().Format(time.RFC3339)
+	_, err = testing.RunCommand(c, &ExposeCommand{}, []string{"dummy"})
+	c.Assert(err, IsNil)
+	_, err = testing.RunCommand(c, &ExposeCommand{}, []string{"unknown"})
+	c.Assert(err, NotNil)
+
+	ctx, err := testing.RunCommand(c, &AuditLogCommand{}, []string{"--since", since, "--entity", "dummy"})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Matches,
+		`\S+ user-admin ServiceExpose {"ServiceName":"dummy"}\n`)
+
+	ctx, err = testing.RunCommand(c, &AuditLogCommand{}, []string{"--user", "admin", "--limit", "2"})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Matches,
+		`\S+ user-admin ServiceExpose {"ServiceName":"dummy"}\n`+
+			`\S+ user-admin ServiceExpose {"ServiceName":"unknown"} failed: service "unknown" not found\n`)
+}
+
+func (s *AuditLogSuite) TestAuditLogAdminOnly(c *C) {
+	_, err := s.State.AddUser("bob", "s3cret", state.WriteAccess)
+	c.Assert(err, IsNil)
+	defer asUser("bob", "s3cret")()
+	_, err = testing.RunCommand(c, &AuditLogCommand{}, nil)
+	c.Assert(err, ErrorMatches, "permission denied")
+}
@@ -12,8 +12,6 @@ import (
 	"launchpad.net/juju-core/constraints"
 	"launchpad.net/juju-core/juju"
 	"launchpad.net/juju-core/names"
-	"launchpad.net/juju-core/state/api/params"
-	"launchpad.net/juju-core/state/statecmd"
 )
 
 // GetConstraintsCommand shows the constraints for a service or environment.
@@ -55,22 +53,17 @@ func (c *GetConstraintsCommand) Init(args []string) error {
 }
 
 func (c *GetConstraintsCommand) Run(ctx *cmd.Context) error {
-	conn, err := juju.NewConnFromName(c.EnvName)
+	client, err := juju.NewAPIClientFromName(c.EnvName)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
+	defer client.Close()
 
 	var cons constraints.Value
 	if c.ServiceName != "" {
-		args := params.GetServiceConstraints{
-			ServiceName: c.ServiceName,
-		}
-		var results params.GetServiceConstraintsResults
-		results, err = statecmd.GetServiceConstraints(conn.State, args)
-		cons = results.Constraints
+		cons, err = client.GetServiceConstraints(c.ServiceName)
 	} else {
-		cons, err = conn.State.EnvironConstraints()
+		cons, err = client.GetEnvironmentConstraints()
 	}
 	if err != nil {
 		return err
@@ -108,17 +101,13 @@ func (c *SetConstraintsCommand) Init(args []string) (err error) {
 }
 
 func (c *SetConstraintsCommand) Run(_ *cmd.Context) (err error) {
-	conn, err := juju.NewConnFromName(c.EnvName)
+	client, err := juju.NewAPIClientFromName(c.EnvName)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
+	defer client.Close()
 	if c.ServiceName == "" {
-		return conn.State.SetEnvironConstraints(c.Constraints)
-	}
-	params := params.SetServiceConstraints{
-		ServiceName: c.ServiceName,
-		Constraints: c.Constraints,
+		return client.SetEnvironmentConstraints(c.Constraints)
 	}
-	return statecmd.SetServiceConstraints(conn.State, params)
+	return client.SetServiceConstraints(c.ServiceName, c.Constraints)
 }
@@ -7,10 +7,12 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strings"
 
 	"launchpad.net/juju-core/charm/hooks"
 	"launchpad.net/juju-core/cmd"
-	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state/api/params"
 	unitdebug "launchpad.net/juju-core/worker/uniter/debug"
 )
 
@@ -50,15 +52,12 @@ func (c *DebugHooksCommand) Init(args []string) error {
 	return nil
 }
 
-func (c *DebugHooksCommand) validateHooks(unit *state.Unit) error {
+func (c *DebugHooksCommand) validateHooks() error {
 	if len(c.hooks) == 0 {
 		return nil
 	}
-	service, err := unit.Service()
-	if err != nil {
-		return err
-	}
-	eps, err := service.Endpoints()
+	service := strings.Split(c.Target, "/")[0]
+	relations, err := c.apiClient.ServiceCharmRelations(service)
 	if err != nil {
 		return err
 	}
@@ -66,15 +65,15 @@ func (c *DebugHooksCommand) validateHooks(unit *state.Unit) error {
 	for _, hook := range hooks.UnitHooks() {
 		validHooks[string(hook)] = true
 	}
-	for _, ep := range eps {
+	for _, relation := range relations {
 		for _, hook := range hooks.RelationHooks() {
-			hook := fmt.Sprintf("%s-%s", ep.Relation.Name, hook)
+			hook := fmt.Sprintf("%s-%s", relation, hook)
 			validHooks[hook] = true
 		}
 	}
 	for _, hook := range c.hooks {
 		if !validHooks[hook] {
-			return fmt.Errorf("unit %q does not contain hook %q", unit.Name(), hook)
+			return fmt.Errorf("unit %q does not contain hook %q", c.Target, hook)
 		}
 	}
 	return nil
@@ -84,16 +83,18 @@ func (c *DebugHooksCommand) validateHooks(unit *state.Unit) error {
 // and connects to it via SSH to execute the debug-hooks
 // script.
 func (c *DebugHooksCommand) Run(ctx *cmd.Context) error {
-	conn, err := c.initConn()
+	if !names.IsUnit(c.Target) {
+		return fmt.Errorf("%q is not a valid unit name", c.Target)
+	}
+	client, err := c.initAPIClient()
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	unit, err := conn.State.Unit(c.Target)
-	if err != nil {
+	defer client.Close()
+	if _, err := client.PublicAddress(c.Target); params.ErrCode(err) == params.CodeNotFound {
 		return err
 	}
-	err = c.validateHooks(unit)
+	err = c.validateHooks()
 	if err != nil {
 		return err
 	}
@@ -4,42 +4,145 @@
 package main
 
 import (
+	"fmt"
+
 	"launchpad.net/gnuflag"
+
 	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/log/syslog"
+	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state/api/params"
 )
 
+// DebugLogCommand shows the consolidated log of the environment.
 type DebugLogCommand struct {
-	// The debug log command simply invokes juju ssh with the required arguments.
-	sshCmd cmd.Command
+	cmd.EnvCommandBase
+	includeEntity stringList
+	excludeEntity stringList
+	includeModule stringList
+	excludeModule stringList
+	level         string
+	lines         int
+	replay        bool
+	limit         int
+	params        params.DebugLog
 }
 
 const debuglogDoc = `
-Launch an ssh shell on the state server machine and tail the consolidated log file.
-The consolidated log file contains log messages from all nodes in the environment.
+Stream the consolidated log of the environment, which contains the log
+messages of the agents of all the machines and units, as they are written.
+
+The messages shown can be selected by entity and by module. Entities are
+given as machine ids, unit names or tags, and tags may be patterns such as
+"unit-mysql-*"; modules include their submodules. The --include and
+--exclude arguments take comma separated lists of entities, and likewise
+--include-module and --exclude-module take lists of modules.
+
+Examples:
+ juju debug-log --include mysql/0,1 --level WARNING
+ juju debug-log --include-module juju.worker.uniter --exclude unit-mysql-1
+ juju debug-log --replay --limit 100
 `
 
 func (c *DebugLogCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "debug-log",
-		Args:    "[<ssh args>...]",
-		Purpose: "display the consolidated log file",
+		Purpose: "display the consolidated log of the environment",
 		Doc:     debuglogDoc,
 	}
 }
 
 func (c *DebugLogCommand) SetFlags(f *gnuflag.FlagSet) {
-	c.sshCmd.SetFlags(f)
+	c.EnvCommandBase.SetFlags(f)
+	f.Var(&c.includeEntity, "i", "only show messages of these entities")
+	f.Var(&c.includeEntity, "include", "")
+	f.Var(&c.excludeEntity, "x", "do not show messages of these entities")
+	f.Var(&c.excludeEntity, "exclude", "")
+	f.Var(&c.includeModule, "include-module", "only show messages of these modules")
+	f.Var(&c.excludeModule, "exclude-module", "do not show messages of these modules")
+	f.StringVar(&c.level, "l", "", "only show messages of this level or above")
+	f.StringVar(&c.level, "level", "", "")
+	f.IntVar(&c.lines, "n", 10, "show this many messages already logged first")
+	f.IntVar(&c.lines, "lines", 10, "")
+	f.BoolVar(&c.replay, "replay", false, "show all the messages already logged first")
+	f.IntVar(&c.limit, "limit", 0, "exit after showing this many messages")
 }
 
 func (c *DebugLogCommand) Init(args []string) error {
-	args = append([]string{"0"}, args...)
-	args = append(args, "tail -f /var/log/juju/all-machines.log")
-	return c.sshCmd.Init(args)
+	if c.lines < 0 {
+		return fmt.Errorf("invalid number of lines %d", c.lines)
+	}
+	if c.limit < 0 {
+		return fmt.Errorf("invalid limit %d", c.limit)
+	}
+	c.params = params.DebugLog{
+		IncludeEntity: entityTags(c.includeEntity),
+		ExcludeEntity: entityTags(c.excludeEntity),
+		IncludeModule: c.includeModule,
+		ExcludeModule: c.excludeModule,
+		Backlog:       c.lines,
+		Replay:        c.replay,
+	}
+	if c.level != "" {
+		level, ok := syslog.ParseLevel(c.level)
+		if !ok {
+			return fmt.Errorf("invalid level %q", c.level)
+		}
+		c.params.Level = level
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// entityTags returns the tags of the given entities, which may be
+// given as machine ids or unit names.
+func entityTags(entities []string) []string {
+	var tags []string
+	for _, entity := range entities {
+		switch {
+		case names.IsMachine(entity):
+			entity = names.MachineTag(entity)
+		case names.IsUnit(entity):
+			entity = names.UnitTag(entity)
+		}
+		tags = append(tags, entity)
+	}
+	return tags
 }
 
-// Run uses "juju ssh" to log into the state server node
-// and tails the consolidated log file which captures log
-// messages from all nodes.
+// Run streams the selected records of the consolidated log from the API
+// server until the limit, if any, is reached.
 func (c *DebugLogCommand) Run(ctx *cmd.Context) error {
-	return c.sshCmd.Run(ctx)
+	client, err := juju.NewAPIClientFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	watcher, err := client.WatchDebugLog(c.params)
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+	count := 0
+	for {
+		records, err := watcher.Next()
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			record := &syslog.Record{
+				Entity:   r.Entity,
+				Time:     r.Time,
+				Level:    r.Level,
+				Module:   r.Module,
+				Location: r.Location,
+				Message:  r.Message,
+			}
+			fmt.Fprintln(ctx.Stdout, record)
+			count++
+			if count == c.limit {
+				return nil
+			}
+		}
+	}
 }
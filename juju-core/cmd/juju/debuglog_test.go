@@ -4,42 +4,110 @@
 package main
 
 import (
+	"io/ioutil"
+	"path/filepath"
+
 	. "launchpad.net/gocheck"
-	"launchpad.net/juju-core/cmd"
+	"launchpad.net/loggo"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/apiserver/client"
 	"launchpad.net/juju-core/testing"
 )
 
 type DebugLogSuite struct {
+	jujutesting.JujuConnSuite
+	oldLogPath string
 }
 
 var _ = Suite(&DebugLogSuite{})
 
-func runDebugLog(c *C, args ...string) (*DebugLogCommand, error) {
-	cmd := &DebugLogCommand{
-		sshCmd: &dummySSHCommand{},
-	}
-	_, err := testing.RunCommand(c, cmd, args)
-	return cmd, err
+func (s *DebugLogSuite) SetUpTest(c *C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.oldLogPath = client.DebugLogPath
+	client.DebugLogPath = filepath.Join(c.MkDir(), "all-machines.log")
 }
 
-type dummySSHCommand struct {
-	SSHCommand
-	runCalled bool
+func (s *DebugLogSuite) TearDownTest(c *C) {
+	client.DebugLogPath = s.oldLogPath
+	s.JujuConnSuite.TearDownTest(c)
 }
 
-func (c *dummySSHCommand) Run(ctx *cmd.Context) error {
-	c.runCalled = true
-	return nil
+func initDebugLog(args ...string) (*DebugLogCommand, error) {
+	com := &DebugLogCommand{}
+	return com, testing.InitCommand(com, args)
 }
 
-// debug-log is implemented by invoking juju ssh with the correct arguments.
-// This test checks for the expected invocation.
-func (s *DebugLogSuite) TestDebugLogInvokesSSHCommand(c *C) {
-	defer testing.MakeEmptyFakeHome(c).Restore()
-	debugLogCmd, err := runDebugLog(c)
+func (s *DebugLogSuite) TestInit(c *C) {
+	com, err := initDebugLog()
+	c.Assert(err, IsNil)
+	c.Assert(com.params, DeepEquals, params.DebugLog{Backlog: 10})
+
+	com, err = initDebugLog(
+		"-i", "0,mysql/1,unit-wordpress-*",
+		"--exclude", "machine-1",
+		"--include-module", "juju.worker",
+		"--exclude-module", "juju.worker.uniter,juju.provisioner",
+		"--level", "warning",
+		"-n", "3",
+		"--replay",
+	)
 	c.Assert(err, IsNil)
-	debugCmd := debugLogCmd.sshCmd.(*dummySSHCommand)
-	c.Assert(debugCmd.runCalled, Equals, true)
-	c.Assert(debugCmd.Target, Equals, "0")
-	c.Assert([]string{"tail -f /var/log/juju/all-machines.log"}, DeepEquals, debugCmd.Args)
+	c.Assert(com.params, DeepEquals, params.DebugLog{
+		IncludeEntity: []string{"machine-0", "unit-mysql-1", "unit-wordpress-*"},
+		ExcludeEntity: []string{"machine-1"},
+		IncludeModule: []string{"juju.worker"},
+		ExcludeModule: []string{"juju.worker.uniter", "juju.provisioner"},
+		Level:         loggo.WARNING,
+		Backlog:       3,
+		Replay:        true,
+	})
+}
+
+func (s *DebugLogSuite) TestInitErrors(c *C) {
+	for i, t := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"--level", "LOUD"},
+		err:  `invalid level "LOUD"`,
+	}, {
+		args: []string{"-n", "-1"},
+		err:  "invalid number of lines -1",
+	}, {
+		args: []string{"--limit", "-2"},
+		err:  "invalid limit -2",
+	}, {
+		args: []string{"tail -f /var/log/juju/all-machines.log"},
+		err:  `unrecognized args: \["tail -f /var/log/juju/all-machines.log"\]`,
+	}} {
+		c.Logf("test %d: %q", i, t.args)
+		_, err := initDebugLog(t.args...)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+const debugLogContent = `machine-0: 2013-10-16 12:00:00 INFO juju.worker runner.go:42 starting
+unit-mysql-0: 2013-10-16 12:00:01 DEBUG juju.worker.uniter uniter.go:12 hook started
+machine-1: 2013-10-16 12:00:02 WARNING juju.provisioner provisioner.go:7 no tools
+unit-mysql-0: 2013-10-16 12:00:03 ERROR juju.worker.uniter uniter.go:99 hook failed
+`
+
+func (s *DebugLogSuite) TestDebugLog(c *C) {
+	err := ioutil.WriteFile(client.DebugLogPath, []byte(debugLogContent), 0644)
+	c.Assert(err, IsNil)
+	ctx, err := testing.RunCommand(c, &DebugLogCommand{}, []string{"--replay", "--limit", "4"})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, debugLogContent)
+
+	ctx, err = testing.RunCommand(c, &DebugLogCommand{}, []string{"-i", "mysql/0", "-l", "INFO", "--limit", "1"})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals,
+		"unit-mysql-0: 2013-10-16 12:00:03 ERROR juju.worker.uniter uniter.go:99 hook failed\n")
+}
+
+func (s *DebugLogSuite) TestDebugLogMissingLog(c *C) {
+	_, err := testing.RunCommand(c, &DebugLogCommand{}, nil)
+	c.Assert(err, ErrorMatches, "cannot read debug log: .*")
 }
@@ -16,6 +16,7 @@ import (
 	"launchpad.net/juju-core/juju"
 	"launchpad.net/juju-core/juju/osenv"
 	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state/api"
 )
 
 type DeployCommand struct {
@@ -27,6 +28,7 @@ type DeployCommand struct {
 	Constraints  constraints.Value
 	BumpRevision bool
 	RepoPath     string // defaults to JUJU_REPOSITORY
+	Resources    map[string]string
 }
 
 const deployDoc = `
@@ -54,6 +56,11 @@ Examples:
  juju deploy mysql --to 23       (Deploy to machine 23)
  juju deploy mysql --to 24/lxc/3 (Deploy to lxc container 3 on host machine 24)
  juju deploy mysql --to lxc:25   (Deploy to a new lxc container on host machine 25)
+
+Files for the resources declared by the charm can be uploaded with the
+service using the --resource argument, which may be given several times.
+Example:
+ juju deploy tomcat --resource jdk=./jdk.tar.gz
 `
 
 func (c *DeployCommand) Info() *cmd.Info {
@@ -74,6 +81,7 @@ func (c *DeployCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(&c.Config, "config", "path to yaml-formatted service config")
 	f.Var(constraints.ConstraintsValue{&c.Constraints}, "constraints", "set service constraints")
 	f.StringVar(&c.RepoPath, "repository", os.Getenv(osenv.JujuRepository), "local charm repository")
+	f.Var((*resourcesValue)(&c.Resources), "resource", "upload a file for a charm resource, as name=path")
 }
 
 func (c *DeployCommand) Init(args []string) error {
@@ -98,16 +106,16 @@ func (c *DeployCommand) Init(args []string) error {
 }
 
 func (c *DeployCommand) Run(ctx *cmd.Context) error {
-	conn, err := juju.NewConnFromName(c.EnvName)
+	client, err := juju.NewAPIClientFromName(c.EnvName)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	conf, err := conn.State.EnvironConfig()
+	defer client.Close()
+	env, err := client.EnvironmentInfo()
 	if err != nil {
 		return err
 	}
-	curl, err := charm.InferURL(c.CharmName, conf.DefaultSeries())
+	curl, err := charm.InferURL(c.CharmName, env.DefaultSeries)
 	if err != nil {
 		return err
 	}
@@ -115,17 +123,16 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return err
 	}
-	// TODO(fwereade) it's annoying to roundtrip the bytes through the client
-	// here, but it's the original behaviour and not convenient to change.
-	// PutCharm will always be required in some form for local charms; and we
-	// will need an EnsureStoreCharm method somewhere that gets the state.Charm
-	// for use in the following checks.
-	ch, err := conn.PutCharm(curl, repo, c.BumpRevision)
+	curl, err = addCharmViaAPI(client, curl, repo, c.BumpRevision)
+	if err != nil {
+		return err
+	}
+	charmInfo, err := client.CharmInfo(curl.String())
 	if err != nil {
 		return err
 	}
 	numUnits := c.NumUnits
-	if ch.Meta().Subordinate {
+	if charmInfo.Meta.Subordinate {
 		empty := constraints.Value{}
 		if c.Constraints != empty {
 			return errors.New("cannot use --constraints with subordinate service")
@@ -136,28 +143,75 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 			return errors.New("cannot use --num-units or --to with subordinate service")
 		}
 	}
+	for name := range c.Resources {
+		if _, ok := charmInfo.Meta.Resources[name]; !ok {
+			return fmt.Errorf("charm %q declares no resource %q", curl, name)
+		}
+	}
 	serviceName := c.ServiceName
 	if serviceName == "" {
-		serviceName = ch.Meta().Name
+		serviceName = charmInfo.Meta.Name
 	}
-	var settings charm.Settings
+	var configYAML []byte
 	if c.Config.Path != "" {
-		configYAML, err := c.Config.Read(ctx)
+		configYAML, err = c.Config.Read(ctx)
 		if err != nil {
 			return err
 		}
-		settings, err = ch.Config().ParseSettingsYAML(configYAML, serviceName)
+	}
+	err = client.ServiceDeploy(
+		curl.String(),
+		serviceName,
+		numUnits,
+		string(configYAML),
+		c.Constraints,
+		c.ToMachineSpec,
+	)
+	if err != nil {
+		return err
+	}
+	return putResources(ctx, client, serviceName, c.Resources)
+}
+
+// addCharmViaAPI resolves the revision of the charm if it is not given,
+// increments the revision of a local charm directory if bumpRevision is
+// set, and makes sure the charm is available in the environment,
+// uploading it from the local repository when needed. It returns the
+// URL of the charm to use.
+func addCharmViaAPI(client *api.Client, curl *charm.URL, repo charm.Repository, bumpRevision bool) (*charm.URL, error) {
+	if curl.Revision == -1 {
+		rev, err := repo.Latest(curl)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("cannot get latest charm revision: %v", err)
+		}
+		curl = curl.WithRevision(rev)
+	}
+	if curl.Schema != "local" {
+		if bumpRevision {
+			return nil, fmt.Errorf("cannot increment revision of charm %q: not a directory", curl)
 		}
+		return curl, client.AddCharm(curl)
+	}
+	ch, err := repo.Get(curl)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get charm: %v", err)
+	}
+	if bumpRevision {
+		chd, ok := ch.(*charm.Dir)
+		if !ok {
+			return nil, fmt.Errorf("cannot increment revision of charm %q: not a directory", curl)
+		}
+		if err = chd.SetDiskRevision(chd.Revision() + 1); err != nil {
+			return nil, fmt.Errorf("cannot increment revision of charm %q: %v", curl, err)
+		}
+		curl = curl.WithRevision(chd.Revision())
+	}
+	if _, err := client.CharmInfo(curl.String()); err == nil {
+		// The charm is already in the environment.
+		return curl, nil
+	}
+	if err := client.AddLocalCharm(curl, ch); err != nil {
+		return nil, err
 	}
-	_, err = conn.DeployService(juju.DeployServiceParams{
-		ServiceName:    serviceName,
-		Charm:          ch,
-		NumUnits:       numUnits,
-		ConfigSettings: settings,
-		Constraints:    c.Constraints,
-		ToMachineSpec:  c.ToMachineSpec,
-	})
-	return err
+	return curl, nil
 }
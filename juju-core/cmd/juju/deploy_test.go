@@ -51,6 +51,9 @@ var initErrorTests = []struct {
 	}, {
 		args: []string{"craziness", "burble1", "--constraints", "gibber=plop"},
 		err:  `invalid value "gibber=plop" for flag --constraints: unknown constraint "gibber"`,
+	}, {
+		args: []string{"craziness", "burble1", "--resource", "jdk"},
+		err:  `invalid value "jdk" for flag --resource: invalid resource "jdk", expected name=path`,
 	},
 }
 
@@ -150,6 +153,29 @@ func (s *DeploySuite) TestConstraints(c *C) {
 	c.Assert(cons, DeepEquals, constraints.MustParse("mem=2G cpu-cores=2"))
 }
 
+func (s *DeploySuite) TestResources(c *C) {
+	coretesting.Charms.BundlePath(s.SeriesPath, "resources")
+	jdk := writeResource(c, "jdk content")
+	vendor := writeResource(c, "vendor content")
+	err := runDeploy(c, "local:resources", "--resource", "jdk="+jdk, "--resource", "vendor="+vendor)
+	c.Assert(err, IsNil)
+	curl := charm.MustParseURL("local:precise/resources-1")
+	service, _ := s.AssertService(c, "resources", curl, 1, 0)
+	resources, err := service.Resources()
+	c.Assert(err, IsNil)
+	c.Assert(resources, HasLen, 2)
+	assertResourceContent(c, resources[0], "jdk content")
+	assertResourceContent(c, resources[1], "vendor content")
+}
+
+func (s *DeploySuite) TestResourcesError(c *C) {
+	coretesting.Charms.BundlePath(s.SeriesPath, "resources")
+	err := runDeploy(c, "local:resources", "--resource", "jre="+writeResource(c, "jre content"))
+	c.Assert(err, ErrorMatches, `charm "local:precise/resources-1" declares no resource "jre"`)
+	_, err = s.State.Service("resources")
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+}
+
 func (s *DeploySuite) TestSubordinateConstraints(c *C) {
 	coretesting.Charms.BundlePath(s.SeriesPath, "logging")
 	err := runDeploy(c, "local:logging", "--constraints", "mem=1G")
@@ -12,6 +12,9 @@ import (
 
 	"launchpad.net/juju-core/cmd"
 	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/state/api"
 )
 
 // DestroyEnvironmentCommand destroys an environment.
@@ -49,6 +52,16 @@ func (c *DestroyEnvironmentCommand) Run(ctx *cmd.Context) error {
 		}
 	}
 
+	// Let the API server stop the instances it knows about first; the
+	// environment may not be bootstrapped, so failure is not fatal.
+	if conn, err := juju.NewAPIConn(environ, api.DefaultDialOpts()); err != nil {
+		log.Debugf("cannot connect to the API server: %v", err)
+	} else {
+		if err := conn.State.Client().DestroyEnvironment(); err != nil {
+			log.Warningf("cannot destroy the environment through the API: %v", err)
+		}
+		conn.Close()
+	}
 	return environ.Destroy(nil)
 }
 
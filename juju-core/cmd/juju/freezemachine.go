@@ -0,0 +1,113 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/utils"
+)
+
+// containerOpAttempt governs how long the container operation commands
+// wait for the agent of the host machine to carry out the operation.
+var containerOpAttempt = utils.AttemptStrategy{
+	Total: 10 * time.Minute,
+	Delay: time.Second,
+}
+
+// runContainerOperation asks the agent of the machine hosting the
+// container to carry out the operation, and waits for it to be done.
+func runContainerOperation(envName, machineId string, op state.ContainerOp, snapshot string) error {
+	client, err := juju.NewAPIClientFromName(envName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := client.ContainerOperation(machineId, string(op), snapshot); err != nil {
+		return err
+	}
+	for a := containerOpAttempt.Start(); a.Next(); {
+		info, err := client.ContainerOperationInfo(machineId)
+		if params.ErrCode(err) == params.CodeNotFound {
+			// The operation succeeded.
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if info.Error != "" {
+			return fmt.Errorf("cannot %s machine %s: %s", op, machineId, info.Error)
+		}
+	}
+	return fmt.Errorf("timed out waiting for the %s operation on machine %s; see juju status", op, machineId)
+}
+
+// parseMachineId returns the container machine id at the start of args.
+func parseMachineId(args []string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("no machine specified")
+	}
+	if !names.IsMachine(args[0]) {
+		return "", nil, fmt.Errorf("invalid machine id %q", args[0])
+	}
+	return args[0], args[1:], nil
+}
+
+// FreezeMachineCommand freezes all the processes of a container machine.
+type FreezeMachineCommand struct {
+	cmd.EnvCommandBase
+	MachineId string
+}
+
+func (c *FreezeMachineCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "freeze-machine",
+		Args:    "<machine>",
+		Purpose: "freeze a container machine",
+		Doc:     "The processes of the container are suspended until the machine is thawed.",
+	}
+}
+
+func (c *FreezeMachineCommand) Init(args []string) (err error) {
+	c.MachineId, args, err = parseMachineId(args)
+	if err != nil {
+		return err
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *FreezeMachineCommand) Run(_ *cmd.Context) error {
+	return runContainerOperation(c.EnvName, c.MachineId, state.ContainerFreeze, "")
+}
+
+// ThawMachineCommand thaws the processes of a frozen container machine.
+type ThawMachineCommand struct {
+	cmd.EnvCommandBase
+	MachineId string
+}
+
+func (c *ThawMachineCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "thaw-machine",
+		Args:    "<machine>",
+		Purpose: "thaw a frozen container machine",
+	}
+}
+
+func (c *ThawMachineCommand) Init(args []string) (err error) {
+	c.MachineId, args, err = parseMachineId(args)
+	if err != nil {
+		return err
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *ThawMachineCommand) Run(_ *cmd.Context) error {
+	return runContainerOperation(c.EnvName, c.MachineId, state.ContainerThaw, "")
+}
@@ -0,0 +1,160 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/instance"
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/utils"
+)
+
+type ContainerOpsSuite struct {
+	jujutesting.JujuConnSuite
+	oldAttempt utils.AttemptStrategy
+}
+
+var _ = Suite(&ContainerOpsSuite{})
+
+func (s *ContainerOpsSuite) SetUpTest(c *C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.oldAttempt = containerOpAttempt
+	containerOpAttempt = utils.AttemptStrategy{
+		Total: testing.LongWait,
+		Delay: 10 * time.Millisecond,
+	}
+}
+
+func (s *ContainerOpsSuite) TearDownTest(c *C) {
+	containerOpAttempt = s.oldAttempt
+	s.JujuConnSuite.TearDownTest(c)
+}
+
+func (s *ContainerOpsSuite) addContainer(c *C) *state.Machine {
+	host, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	params := state.AddMachineParams{
+		ParentId:      host.Id(),
+		ContainerType: instance.LXC,
+		Series:        "series",
+		Jobs:          []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineWithConstraints(&params)
+	c.Assert(err, IsNil)
+	return container
+}
+
+// runOp runs the command while playing the part of the agent of the
+// host machine, which completes the operation requested on the
+// container with opErr. It returns the operation completed and the
+// error of the command.
+func (s *ContainerOpsSuite) runOp(c *C, container *state.Machine, opErr error, com cmd.Command, args ...string) (*state.ContainerOperation, error) {
+	done := make(chan *state.ContainerOperation, 1)
+	go func() {
+		defer close(done)
+		for a := containerOpAttempt.Start(); a.Next(); {
+			op, err := container.ContainerOp()
+			if err != nil || op.Error != "" {
+				continue
+			}
+			if err := container.CompleteContainerOp(opErr); err != nil {
+				c.Errorf("cannot complete operation: %v", err)
+				return
+			}
+			done <- op
+			return
+		}
+	}()
+	_, err := testing.RunCommand(c, com, args)
+	return <-done, err
+}
+
+func (s *ContainerOpsSuite) TestFreezeThawMachine(c *C) {
+	container := s.addContainer(c)
+	op, err := s.runOp(c, container, nil, &FreezeMachineCommand{}, container.Id())
+	c.Assert(err, IsNil)
+	c.Assert(op, DeepEquals, &state.ContainerOperation{Op: state.ContainerFreeze})
+
+	op, err = s.runOp(c, container, nil, &ThawMachineCommand{}, container.Id())
+	c.Assert(err, IsNil)
+	c.Assert(op, DeepEquals, &state.ContainerOperation{Op: state.ContainerThaw})
+}
+
+func (s *ContainerOpsSuite) TestSnapshotRestoreMachine(c *C) {
+	container := s.addContainer(c)
+	op, err := s.runOp(c, container, nil, &SnapshotMachineCommand{}, container.Id(), "before-upgrade")
+	c.Assert(err, IsNil)
+	c.Assert(op, DeepEquals, &state.ContainerOperation{
+		Op:       state.ContainerSnapshot,
+		Snapshot: "before-upgrade",
+	})
+
+	op, err = s.runOp(c, container, nil, &RestoreMachineCommand{}, container.Id(), "before-upgrade")
+	c.Assert(err, IsNil)
+	c.Assert(op, DeepEquals, &state.ContainerOperation{
+		Op:       state.ContainerRestore,
+		Snapshot: "before-upgrade",
+	})
+}
+
+func (s *ContainerOpsSuite) TestOperationFailed(c *C) {
+	container := s.addContainer(c)
+	_, err := s.runOp(c, container, fmt.Errorf("disk full"), &SnapshotMachineCommand{}, container.Id(), "before-upgrade")
+	c.Assert(err, ErrorMatches, `cannot snapshot machine 0/lxc/0: disk full`)
+}
+
+func (s *ContainerOpsSuite) TestOperationTimeout(c *C) {
+	containerOpAttempt.Total = 50 * time.Millisecond
+	container := s.addContainer(c)
+	_, err := testing.RunCommand(c, &FreezeMachineCommand{}, []string{container.Id()})
+	c.Assert(err, ErrorMatches, `timed out waiting for the freeze operation on machine 0/lxc/0; see juju status`)
+	op, err := container.ContainerOp()
+	c.Assert(err, IsNil)
+	c.Assert(op, DeepEquals, &state.ContainerOperation{Op: state.ContainerFreeze})
+}
+
+func (s *ContainerOpsSuite) TestNotAContainer(c *C) {
+	m, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	_, err = testing.RunCommand(c, &FreezeMachineCommand{}, []string{m.Id()})
+	c.Assert(err, ErrorMatches, `cannot freeze machine 0: machine is not a container`)
+}
+
+func (s *ContainerOpsSuite) TestInit(c *C) {
+	for i, t := range []struct {
+		command cmd.Command
+		args    []string
+		err     string
+	}{{
+		command: &FreezeMachineCommand{},
+		err:     `no machine specified`,
+	}, {
+		command: &ThawMachineCommand{},
+		args:    []string{"foo"},
+		err:     `invalid machine id "foo"`,
+	}, {
+		command: &FreezeMachineCommand{},
+		args:    []string{"0/lxc/0", "snap"},
+		err:     `unrecognized args: \["snap"\]`,
+	}, {
+		command: &SnapshotMachineCommand{},
+		args:    []string{"0/lxc/0"},
+		err:     `no snapshot name specified`,
+	}, {
+		command: &RestoreMachineCommand{},
+		args:    []string{"0/lxc/0", "snap", "extra"},
+		err:     `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %v", i, t.args)
+		err := testing.InitCommand(t.command, t.args)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
@@ -9,8 +9,6 @@ import (
 	"launchpad.net/gnuflag"
 	"launchpad.net/juju-core/cmd"
 	"launchpad.net/juju-core/juju"
-	"launchpad.net/juju-core/state/api/params"
-	"launchpad.net/juju-core/state/statecmd"
 )
 
 // GetCommand retrieves the configuration of a service.
@@ -48,21 +46,15 @@ func (c *GetCommand) Init(args []string) error {
 // Run fetches the configuration of the service and formats
 // the result as a YAML string.
 func (c *GetCommand) Run(ctx *cmd.Context) error {
-	conn, err := juju.NewConnFromName(c.EnvName)
+	client, err := juju.NewAPIClientFromName(c.EnvName)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-
-	params := params.ServiceGet{
-		ServiceName: c.ServiceName,
-	}
-
-	results, err := statecmd.ServiceGet(conn.State, params)
+	defer client.Close()
+	results, err := client.ServiceGet(c.ServiceName)
 	if err != nil {
 		return err
 	}
-
 	resultsMap := map[string]interface{}{
 		"service":  results.Service,
 		"charm":    results.Charm,
@@ -10,6 +10,8 @@ import (
 
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/worker/uniter/jujuc"
 )
 
@@ -47,6 +49,39 @@ func (dummyHookContext) Relation(id int) (jujuc.ContextRelation, bool) {
 func (dummyHookContext) RelationIds() []int {
 	return []int{}
 }
+func (dummyHookContext) WorkloadStatus() (params.WorkloadStatus, string, error) {
+	return params.WorkloadUnknown, "", nil
+}
+func (dummyHookContext) SetWorkloadStatus(status params.WorkloadStatus, info string) error {
+	return nil
+}
+func (dummyHookContext) IsLeader() (bool, error) {
+	return false, nil
+}
+func (dummyHookContext) LeaderSettings() (map[string]string, error) {
+	return map[string]string{}, nil
+}
+func (dummyHookContext) WriteLeaderSettings(settings map[string]string) error {
+	return nil
+}
+func (dummyHookContext) ActionParams() (map[string]interface{}, error) {
+	return nil, nil
+}
+func (dummyHookContext) UpdateActionResults(results map[string]interface{}) error {
+	return nil
+}
+func (dummyHookContext) HookStorageId() (string, bool) {
+	return "", false
+}
+func (dummyHookContext) Storage(id string) (jujuc.ContextStorage, error) {
+	return nil, nil
+}
+func (dummyHookContext) Resource(name string) (string, error) {
+	return "", nil
+}
+func (dummyHookContext) NetworkAddresses() ([]instance.Address, error) {
+	return nil, nil
+}
 
 type HelpToolCommand struct {
 	cmd.CommandBase
@@ -33,14 +33,24 @@ purpose: show help on a juju charm tool
 
 func (suite *HelpToolSuite) TestHelpTool(c *C) {
 	expectedNames := []string{
+		"action-get",
+		"action-set",
 		"close-port",
 		"config-get",
+		"is-leader",
 		"juju-log",
+		"leader-get",
+		"leader-set",
+		"network-get",
 		"open-port",
 		"relation-get",
 		"relation-ids",
 		"relation-list",
 		"relation-set",
+		"resource-get",
+		"status-get",
+		"status-set",
+		"storage-get",
 		"unit-get",
 	}
 	output := badrun(c, 0, "help-tool")
@@ -0,0 +1,120 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+const setHookPolicyDoc = `
+Changes how the units of a service run their hooks. Options not given
+keep their current value.
+
+  timeout=<duration>
+    Hooks still running after the given time (e.g. 5m) are killed,
+    along with any process they started, and reported as failed.
+    Zero, the default, lets hooks run for as long as they need.
+
+  retry=<bool>
+    Failed hooks are retried automatically, without waiting for
+    "juju resolved --retry", after a delay that doubles with every
+    attempt. The delay is reported in the status of the unit.
+
+  retry-delay=<duration>
+    The delay before the first automatic retry (default 10s).
+
+  max-retry-delay=<duration>
+    The maximum delay between automatic retries (default 5m).
+
+Example:
+
+  juju set-hook-policy mysql timeout=10m retry=true
+`
+
+// SetHookPolicyCommand changes the hook policy of a service.
+type SetHookPolicyCommand struct {
+	cmd.EnvCommandBase
+	ServiceName string
+	Options     map[string]string
+}
+
+func (c *SetHookPolicyCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "set-hook-policy",
+		Args:    "<service> name=value ...",
+		Purpose: "set the hook timeout and retry policy of a service",
+		Doc:     setHookPolicyDoc,
+	}
+}
+
+func (c *SetHookPolicyCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no service name specified")
+	}
+	if !names.IsService(args[0]) {
+		return fmt.Errorf("invalid service name %q", args[0])
+	}
+	c.ServiceName = args[0]
+	options, err := parse(args[1:])
+	if err != nil {
+		return err
+	}
+	if len(options) == 0 {
+		return errors.New("no options specified")
+	}
+	// Check the options now, to report mistakes before connecting.
+	if err := applyHookPolicy(&params.HookPolicy{}, options); err != nil {
+		return err
+	}
+	c.Options = options
+	return nil
+}
+
+// Run updates the hook policy of the service.
+func (c *SetHookPolicyCommand) Run(_ *cmd.Context) error {
+	client, err := juju.NewAPIClientFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	policy, err := client.ServiceGetHookPolicy(c.ServiceName)
+	if err != nil {
+		return err
+	}
+	if err := applyHookPolicy(&policy, c.Options); err != nil {
+		return err
+	}
+	return client.ServiceSetHookPolicy(c.ServiceName, policy)
+}
+
+// applyHookPolicy sets the fields of policy named by options.
+func applyHookPolicy(policy *params.HookPolicy, options map[string]string) error {
+	for name, value := range options {
+		var err error
+		switch name {
+		case "timeout":
+			policy.Timeout, err = time.ParseDuration(value)
+		case "retry":
+			policy.Retry, err = strconv.ParseBool(value)
+		case "retry-delay":
+			policy.RetryDelay, err = time.ParseDuration(value)
+		case "max-retry-delay":
+			policy.MaxRetryDelay, err = time.ParseDuration(value)
+		default:
+			return fmt.Errorf("unknown option %q", name)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid value for %q: %q", name, value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state/api/params"
+	coretesting "launchpad.net/juju-core/testing"
+)
+
+type SetHookPolicySuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = Suite(&SetHookPolicySuite{})
+
+func runSetHookPolicy(c *C, args ...string) error {
+	_, err := coretesting.RunCommand(c, &SetHookPolicyCommand{}, args)
+	return err
+}
+
+func (s *SetHookPolicySuite) TestSetHookPolicy(c *C) {
+	svc, err := s.State.AddService("svc", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+
+	err = runSetHookPolicy(c, "svc", "timeout=5m", "retry=true")
+	c.Assert(err, IsNil)
+	err = svc.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(svc.HookPolicy(), Equals, params.HookPolicy{
+		Timeout: 5 * time.Minute,
+		Retry:   true,
+	})
+
+	// Options not given are left alone.
+	err = runSetHookPolicy(c, "svc", "retry-delay=30s", "max-retry-delay=1h")
+	c.Assert(err, IsNil)
+	err = svc.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(svc.HookPolicy(), Equals, params.HookPolicy{
+		Timeout:       5 * time.Minute,
+		Retry:         true,
+		RetryDelay:    30 * time.Second,
+		MaxRetryDelay: time.Hour,
+	})
+
+	err = runSetHookPolicy(c, "svc", "max-retry-delay=10s")
+	c.Assert(err, ErrorMatches, `cannot set hook policy for service "svc": maximum retry delay 10s is less than retry delay 30s`)
+
+	err = runSetHookPolicy(c, "nonexistent", "retry=true")
+	c.Assert(err, ErrorMatches, `service "nonexistent" not found`)
+}
+
+var setHookPolicyInitErrorTests = []struct {
+	args []string
+	err  string
+}{
+	{nil, "no service name specified"},
+	{[]string{"svc!"}, `invalid service name "svc!"`},
+	{[]string{"svc"}, "no options specified"},
+	{[]string{"svc", "timeout"}, `invalid option: "timeout"`},
+	{[]string{"svc", "timeout=soon"}, `invalid value for "timeout": "soon"`},
+	{[]string{"svc", "retry=maybe"}, `invalid value for "retry": "maybe"`},
+	{[]string{"svc", "colour=blue"}, `unknown option "colour"`},
+}
+
+func (s *SetHookPolicySuite) TestInitErrors(c *C) {
+	for i, t := range setHookPolicyInitErrorTests {
+		c.Logf("test %d: %q", i, t.args)
+		err := coretesting.InitCommand(&SetHookPolicyCommand{}, t.args)
+		c.Assert(err, ErrorMatches, t.err)
+	}
+}
@@ -54,6 +54,7 @@ func Main(args []string) {
 	jujucmd.Register(&DeployCommand{})
 	jujucmd.Register(&AddRelationCommand{})
 	jujucmd.Register(&AddUnitCommand{})
+	jujucmd.Register(&AttachCommand{})
 
 	// Destruction commands.
 	jujucmd.Register(&DestroyMachineCommand{})
@@ -65,13 +66,21 @@ func Main(args []string) {
 	// Reporting commands.
 	jujucmd.Register(&StatusCommand{})
 	jujucmd.Register(&SwitchCommand{})
+	jujucmd.Register(&AuditLogCommand{})
 
 	// Error resolution and debugging commands.
 	jujucmd.Register(&SCPCommand{})
 	jujucmd.Register(&SSHCommand{})
 	jujucmd.Register(&ResolvedCommand{})
-	jujucmd.Register(&DebugLogCommand{sshCmd: &SSHCommand{}})
+	jujucmd.Register(&FreezeMachineCommand{})
+	jujucmd.Register(&ThawMachineCommand{})
+	jujucmd.Register(&SnapshotMachineCommand{})
+	jujucmd.Register(&RestoreMachineCommand{})
+	jujucmd.Register(&DebugLogCommand{})
 	jujucmd.Register(&DebugHooksCommand{})
+	jujucmd.Register(&RunCommand{})
+	jujucmd.Register(&DoCommand{})
+	jujucmd.Register(&ActionResultCommand{})
 
 	// Configuration commands.
 	jujucmd.Register(&InitCommand{})
@@ -81,12 +90,16 @@ func Main(args []string) {
 	jujucmd.Register(&SetConstraintsCommand{})
 	jujucmd.Register(&GetEnvironmentCommand{})
 	jujucmd.Register(&SetEnvironmentCommand{})
+	jujucmd.Register(&SetHookPolicyCommand{})
 	jujucmd.Register(&ExposeCommand{})
 	jujucmd.Register(&SyncToolsCommand{})
 	jujucmd.Register(&UnexposeCommand{})
 	jujucmd.Register(&UpgradeJujuCommand{})
 	jujucmd.Register(&UpgradeCharmCommand{})
 
+	// User management commands.
+	jujucmd.Register(newUserCommand())
+
 	// Charm publishing commands.
 	jujucmd.Register(&PublishCommand{})
 
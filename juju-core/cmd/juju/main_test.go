@@ -214,9 +214,12 @@ func (s *MainSuite) TestActualRunJujuArgsAfterCommand(c *C) {
 }
 
 var commandNames = []string{
+	"action-result",
 	"add-machine",
 	"add-relation",
 	"add-unit",
+	"attach",
+	"audit-log",
 	"bootstrap",
 	"debug-hooks",
 	"debug-log",
@@ -226,8 +229,10 @@ var commandNames = []string{
 	"destroy-relation",
 	"destroy-service",
 	"destroy-unit",
+	"do",
 	"env", // alias for switch
 	"expose",
+	"freeze-machine",
 	"generate-config", // alias for init
 	"get",
 	"get-constraints",
@@ -240,20 +245,26 @@ var commandNames = []string{
 	"remove-relation", // alias for destroy-relation
 	"remove-unit",     // alias for destroy-unit
 	"resolved",
+	"restore-machine",
+	"run",
 	"scp",
 	"set",
 	"set-constraints",
 	"set-env", // alias for set-environment
 	"set-environment",
+	"set-hook-policy",
+	"snapshot-machine",
 	"ssh",
 	"stat", // alias for status
 	"status",
 	"switch",
 	"sync-tools",
 	"terminate-machine", // alias for destroy-machine
+	"thaw-machine",
 	"unexpose",
 	"upgrade-charm",
 	"upgrade-juju",
+	"user",
 	"version",
 }
 
@@ -0,0 +1,281 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/agent/tools"
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state/api"
+)
+
+// RunCommand runs commands on machines, or in the hook context of units.
+type RunCommand struct {
+	cmd.EnvCommandBase
+	out      cmd.Output
+	machines stringList
+	services stringList
+	units    stringList
+	commands string
+}
+
+const runDoc = `
+Run the commands on the specified targets, and report the output and exit
+code of the commands for each of them.
+
+Commands run on a unit are run in a hook context of the unit, with the hook
+tools such as relation-get and config-get available, and never at the same
+time as a hook of a unit on the same machine. Commands run on a machine are
+run as root, outside any hook context.
+
+The targets are given as comma separated lists of machine ids, service names
+and unit names. The commands are run on all the units of the services given.
+
+A target that cannot be reached over ssh is reported with an error rather
+than an exit code; as ssh exits with code 255 when it fails, commands
+exiting with that code are reported the same way.
+`
+
+func (c *RunCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "run",
+		Args:    "<commands>",
+		Purpose: "run commands on machines or units",
+		Doc:     runDoc,
+	}
+}
+
+func (c *RunCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+	f.Var(&c.machines, "machine", "one or more machine ids")
+	f.Var(&c.services, "service", "one or more service names")
+	f.Var(&c.units, "unit", "one or more unit names")
+}
+
+func (c *RunCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no commands specified")
+	}
+	c.commands, args = args[0], args[1:]
+	if len(c.machines) == 0 && len(c.services) == 0 && len(c.units) == 0 {
+		return errors.New("no target specified: use --machine, --service or --unit")
+	}
+	for _, id := range c.machines {
+		if !names.IsMachine(id) {
+			return fmt.Errorf("invalid machine id %q", id)
+		}
+	}
+	for _, name := range c.services {
+		if !names.IsService(name) {
+			return fmt.Errorf("invalid service name %q", name)
+		}
+	}
+	for _, name := range c.units {
+		if !names.IsUnit(name) {
+			return fmt.Errorf("invalid unit name %q", name)
+		}
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// runResult holds the outcome of the commands run on a target.
+type runResult struct {
+	MachineId string `json:"machine-id" yaml:"machine-id"`
+	UnitId    string `json:"unit-id,omitempty" yaml:"unit-id,omitempty"`
+	Code      int    `json:"code" yaml:"code"`
+	Stdout    string `json:"stdout,omitempty" yaml:"stdout,omitempty"`
+	Stderr    string `json:"stderr,omitempty" yaml:"stderr,omitempty"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// runTarget identifies a machine or a unit the commands are run on, and
+// the address of the host they are run on.
+type runTarget struct {
+	machineId string
+	unitName  string
+	host      string
+	err       error
+}
+
+func (c *RunCommand) Run(ctx *cmd.Context) error {
+	client, err := juju.NewAPIClientFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	targets, err := c.targets(client)
+	if err != nil {
+		return err
+	}
+	results := make([]runResult, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target runTarget) {
+			defer wg.Done()
+			results[i] = c.runOn(target)
+		}(i, target)
+	}
+	wg.Wait()
+	return c.out.Write(ctx, results)
+}
+
+// targets returns the targets of the commands, the units of the services
+// included.
+func (c *RunCommand) targets(client *api.Client) ([]runTarget, error) {
+	var targets []runTarget
+	for _, id := range c.machines {
+		target := runTarget{machineId: id}
+		target.host, target.err = client.PublicAddress(id)
+		targets = append(targets, target)
+	}
+	status, err := client.FullStatus(nil)
+	if err != nil {
+		return nil, err
+	}
+	units := make(map[string]api.UnitStatus)
+	for _, service := range status.Services {
+		for name, unit := range service.Units {
+			units[name] = unit
+			for subName, sub := range unit.Subordinates {
+				// A subordinate runs on the machine of its principal.
+				sub.Machine = unit.Machine
+				if sub.PublicAddress == "" {
+					sub.PublicAddress = unit.PublicAddress
+				}
+				units[subName] = sub
+			}
+		}
+	}
+	unitNames := append([]string(nil), c.units...)
+	for _, name := range c.services {
+		if _, ok := status.Services[name]; !ok {
+			return nil, fmt.Errorf("service %q not found", name)
+		}
+		var serviceUnits []string
+		for unitName := range units {
+			if strings.HasPrefix(unitName, name+"/") {
+				serviceUnits = append(serviceUnits, unitName)
+			}
+		}
+		sort.Sort(byUnitNumber(serviceUnits))
+		unitNames = append(unitNames, serviceUnits...)
+	}
+	seen := make(map[string]bool)
+	for _, name := range unitNames {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		unit, ok := units[name]
+		if !ok {
+			return nil, fmt.Errorf("unit %q not found", name)
+		}
+		target := runTarget{unitName: name, machineId: unit.Machine}
+		switch {
+		case unit.Machine == "":
+			target.err = fmt.Errorf("unit %q is not assigned to a machine", name)
+		case unit.PublicAddress == "":
+			target.err = fmt.Errorf("unit %q has no public address", name)
+		default:
+			target.host = unit.PublicAddress
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// byUnitNumber sorts the names of the units of a service by unit number.
+type byUnitNumber []string
+
+func (b byUnitNumber) Len() int      { return len(b) }
+func (b byUnitNumber) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byUnitNumber) Less(i, j int) bool {
+	return unitNumber(b[i]) < unitNumber(b[j])
+}
+
+func unitNumber(name string) int {
+	n, _ := strconv.Atoi(name[strings.LastIndex(name, "/")+1:])
+	return n
+}
+
+// remoteCommand returns the shell command running the commands on the
+// host of the target.
+func (c *RunCommand) remoteCommand(target runTarget) string {
+	commands := base64.StdEncoding.EncodeToString([]byte(c.commands))
+	if target.unitName == "" {
+		return fmt.Sprintf("echo %s | base64 -d | sudo /bin/bash -s", commands)
+	}
+	jujuRun := path.Join(tools.ToolsDir(environs.DataDir, names.UnitTag(target.unitName)), "juju-run")
+	return fmt.Sprintf(`sudo %s %s "$(echo %s | base64 -d)"`, jujuRun, target.unitName, commands)
+}
+
+// sshFailureCode is the exit code of ssh when it fails itself, rather
+// than exiting with the exit code of the remote command.
+const sshFailureCode = 255
+
+// runOn runs the commands on the target over ssh. A failure of ssh to
+// run the commands is reported as an error, rather than as the exit code
+// of the commands.
+func (c *RunCommand) runOn(target runTarget) runResult {
+	result := runResult{
+		MachineId: target.machineId,
+		UnitId:    target.unitName,
+	}
+	if target.err != nil {
+		result.Error = target.err.Error()
+		return result
+	}
+	args := []string{"-l", "ubuntu", "-o", "StrictHostKeyChecking no", "-o", "PasswordAuthentication no", target.host, c.remoteCommand(target)}
+	ssh := exec.Command("ssh", args...)
+	var stdout, stderr bytes.Buffer
+	ssh.Stdout = &stdout
+	ssh.Stderr = &stderr
+	err := ssh.Run()
+	if ee, ok := err.(*exec.ExitError); ok {
+		code := ee.Sys().(syscall.WaitStatus).ExitStatus()
+		if code == sshFailureCode {
+			result.Error = fmt.Sprintf("cannot run commands over ssh: %s", strings.TrimSpace(stderr.String()))
+			return result
+		}
+		result.Code = code
+	} else if err != nil {
+		result.Error = err.Error()
+	}
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	return result
+}
+
+// stringList is a flag value holding a comma separated list of strings.
+type stringList []string
+
+func (v *stringList) Set(value string) error {
+	*v = strings.Split(value, ",")
+	return nil
+}
+
+func (v *stringList) String() string {
+	return strings.Join(*v, ",")
+}
@@ -0,0 +1,159 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	coretesting "launchpad.net/juju-core/testing"
+)
+
+type RunSuite struct {
+	SSHCommonSuite
+}
+
+var _ = Suite(&RunSuite{})
+
+// fakeRunSSH reports the host and the command it is given, and exits with
+// an error code.
+var fakeRunSSH = `#!/bin/bash
+echo "$8"
+echo "$7" >&2
+exit 3
+`
+
+func (s *RunSuite) SetUpTest(c *C) {
+	s.SSHCommonSuite.SetUpTest(c)
+	// Replace the fake ssh installed by SSHCommonSuite.
+	installFakeSSH(c, fakeRunSSH)
+}
+
+// installFakeSSH puts an ssh running the given script first in the PATH.
+func installFakeSSH(c *C, script string) {
+	path := c.MkDir()
+	err := ioutil.WriteFile(filepath.Join(path, "ssh"), []byte(script), 0777)
+	c.Assert(err, IsNil)
+	os.Setenv("PATH", path+":"+os.Getenv("PATH"))
+}
+
+func (s *RunSuite) TestInitErrors(c *C) {
+	for i, t := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no commands specified",
+	}, {
+		args: []string{"hostname"},
+		err:  "no target specified: use --machine, --service or --unit",
+	}, {
+		args: []string{"--machine", "0,foo", "hostname"},
+		err:  `invalid machine id "foo"`,
+	}, {
+		args: []string{"--service", "mysql/0", "hostname"},
+		err:  `invalid service name "mysql/0"`,
+	}, {
+		args: []string{"--unit", "mysql", "hostname"},
+		err:  `invalid unit name "mysql"`,
+	}, {
+		args: []string{"--unit", "mysql/0", "hostname", "uname"},
+		err:  `unrecognized args: \["uname"\]`,
+	}} {
+		c.Logf("test %d: %q", i, t.args)
+		err := coretesting.InitCommand(&RunCommand{}, t.args)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *RunSuite) TestRun(c *C) {
+	m := s.makeMachines(3, c)
+	ch := s.AddTestingCharm(c, "mysql")
+	mysql, err := s.State.AddService("mysql", ch)
+	c.Assert(err, IsNil)
+	s.addUnit(mysql, m[0], c)
+	mongodb, err := s.State.AddService("mongodb", ch)
+	c.Assert(err, IsNil)
+	s.addUnit(mongodb, m[1], c)
+	s.addUnit(mongodb, m[2], c)
+	_, err = mongodb.AddUnit()
+	c.Assert(err, IsNil)
+
+	ctx := coretesting.Context(c)
+	code := cmd.Main(&RunCommand{}, ctx, []string{
+		"--format", "json",
+		"--machine", "0",
+		"--unit", "mysql/0,mongodb/1",
+		"--service", "mongodb",
+		"hostname",
+	})
+	c.Assert(code, Equals, 0)
+	var results []map[string]interface{}
+	err = json.Unmarshal([]byte(coretesting.Stdout(ctx)), &results)
+	c.Assert(err, IsNil)
+	// The commands are passed base64 encoded.
+	c.Assert(results, DeepEquals, []map[string]interface{}{{
+		"machine-id": "0",
+		"code":       3.0,
+		"stdout":     "echo aG9zdG5hbWU= | base64 -d | sudo /bin/bash -s\n",
+		"stderr":     "dummyenv-0.dns\n",
+	}, {
+		"machine-id": "0",
+		"unit-id":    "mysql/0",
+		"code":       3.0,
+		"stdout":     `sudo /var/lib/juju/tools/unit-mysql-0/juju-run mysql/0 "$(echo aG9zdG5hbWU= | base64 -d)"` + "\n",
+		"stderr":     "dummyenv-0.dns\n",
+	}, {
+		"machine-id": "2",
+		"unit-id":    "mongodb/1",
+		"code":       3.0,
+		"stdout":     `sudo /var/lib/juju/tools/unit-mongodb-1/juju-run mongodb/1 "$(echo aG9zdG5hbWU= | base64 -d)"` + "\n",
+		"stderr":     "dummyenv-2.dns\n",
+	}, {
+		"machine-id": "1",
+		"unit-id":    "mongodb/0",
+		"code":       3.0,
+		"stdout":     `sudo /var/lib/juju/tools/unit-mongodb-0/juju-run mongodb/0 "$(echo aG9zdG5hbWU= | base64 -d)"` + "\n",
+		"stderr":     "dummyenv-1.dns\n",
+	}, {
+		"machine-id": "",
+		"unit-id":    "mongodb/2",
+		"code":       0.0,
+		"error":      `unit "mongodb/2" is not assigned to a machine`,
+	}})
+}
+
+func (s *RunSuite) TestRunSSHFailure(c *C) {
+	installFakeSSH(c, `#!/bin/bash
+echo "ssh: connect to host $7 port 22: Connection refused" >&2
+exit 255
+`)
+	s.makeMachines(1, c)
+
+	ctx := coretesting.Context(c)
+	code := cmd.Main(&RunCommand{}, ctx, []string{"--format", "json", "--machine", "0", "hostname"})
+	c.Assert(code, Equals, 0)
+	var results []map[string]interface{}
+	err := json.Unmarshal([]byte(coretesting.Stdout(ctx)), &results)
+	c.Assert(err, IsNil)
+	c.Assert(results, DeepEquals, []map[string]interface{}{{
+		"machine-id": "0",
+		"code":       0.0,
+		"error":      "cannot run commands over ssh: ssh: connect to host dummyenv-0.dns port 22: Connection refused",
+	}})
+}
+
+func (s *RunSuite) TestRunUnknownService(c *C) {
+	err := runRun(c, "--service", "foo", "hostname")
+	c.Assert(err, ErrorMatches, `service "foo" not found`)
+}
+
+func runRun(c *C, args ...string) error {
+	_, err := coretesting.RunCommand(c, &RunCommand{}, args)
+	return err
+}
@@ -9,7 +9,6 @@ import (
 	"strings"
 
 	"launchpad.net/gnuflag"
-	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/cmd"
 	"launchpad.net/juju-core/juju"
 )
@@ -54,38 +53,21 @@ func (c *SetCommand) Init(args []string) error {
 
 // Run updates the configuration of a service.
 func (c *SetCommand) Run(ctx *cmd.Context) error {
-	conn, err := juju.NewConnFromName(c.EnvName)
+	client, err := juju.NewAPIClientFromName(c.EnvName)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	service, err := conn.State.Service(c.ServiceName)
-	if err != nil {
-		return err
-	}
-	ch, _, err := service.Charm()
-	if err != nil {
-		return err
-	}
-	var settings charm.Settings
+	defer client.Close()
 	if c.SettingsYAML.Path != "" {
 		settingsYAML, err := c.SettingsYAML.Read(ctx)
 		if err != nil {
 			return err
 		}
-		settings, err = ch.Config().ParseSettingsYAML(settingsYAML, c.ServiceName)
-		if err != nil {
-			return err
-		}
+		return client.ServiceSetYAML(c.ServiceName, string(settingsYAML))
 	} else if len(c.SettingsStrings) > 0 {
-		settings, err = ch.Config().ParseSettingsStrings(c.SettingsStrings)
-		if err != nil {
-			return err
-		}
-	} else {
-		return nil
+		return client.ServiceSet(c.ServiceName, c.SettingsStrings)
 	}
-	return service.UpdateConfigSettings(settings)
+	return nil
 }
 
 // parse parses the option k=v strings into a map of options to be
@@ -0,0 +1,83 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/state"
+)
+
+// parseSnapshot returns the container machine id and the snapshot name
+// given in args.
+func parseSnapshot(args []string) (machineId, snapshot string, err error) {
+	machineId, args, err = parseMachineId(args)
+	if err != nil {
+		return "", "", err
+	}
+	if len(args) == 0 {
+		return "", "", fmt.Errorf("no snapshot name specified")
+	}
+	return machineId, args[0], cmd.CheckEmpty(args[1:])
+}
+
+// SnapshotMachineCommand records the state of a container machine under
+// a name, so that it can be restored later.
+type SnapshotMachineCommand struct {
+	cmd.EnvCommandBase
+	MachineId string
+	Snapshot  string
+}
+
+const snapshotMachineDoc = `
+The snapshot is taken by the agent of the machine hosting the container:
+lxc containers are stopped while they are cloned, and docker containers
+are committed to an image. Taking a snapshot with the name of an existing
+one replaces it. The command returns once the snapshot is taken, and
+fails if it could not be.
+`
+
+func (c *SnapshotMachineCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "snapshot-machine",
+		Args:    "<machine> <snapshot>",
+		Purpose: "snapshot a container machine",
+		Doc:     snapshotMachineDoc,
+	}
+}
+
+func (c *SnapshotMachineCommand) Init(args []string) (err error) {
+	c.MachineId, c.Snapshot, err = parseSnapshot(args)
+	return err
+}
+
+func (c *SnapshotMachineCommand) Run(_ *cmd.Context) error {
+	return runContainerOperation(c.EnvName, c.MachineId, state.ContainerSnapshot, c.Snapshot)
+}
+
+// RestoreMachineCommand returns a container machine to a snapshot.
+type RestoreMachineCommand struct {
+	cmd.EnvCommandBase
+	MachineId string
+	Snapshot  string
+}
+
+func (c *RestoreMachineCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "restore-machine",
+		Args:    "<machine> <snapshot>",
+		Purpose: "restore a container machine to a snapshot",
+		Doc:     "A running container is stopped while it is restored, and started again.",
+	}
+}
+
+func (c *RestoreMachineCommand) Init(args []string) (err error) {
+	c.MachineId, c.Snapshot, err = parseSnapshot(args)
+	return err
+}
+
+func (c *RestoreMachineCommand) Run(_ *cmd.Context) error {
+	return runContainerOperation(c.EnvName, c.MachineId, state.ContainerRestore, c.Snapshot)
+}
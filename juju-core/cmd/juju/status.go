@@ -6,22 +6,14 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"path"
-	"regexp"
-	"strings"
 
 	"launchpad.net/gnuflag"
 
-	"launchpad.net/juju-core/agent/tools"
-	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/cmd"
-	"launchpad.net/juju-core/environs"
-	"launchpad.net/juju-core/errors"
 	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/juju"
-	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api"
 	"launchpad.net/juju-core/state/api/params"
-	"launchpad.net/juju-core/utils/set"
 )
 
 type StatusCommand struct {
@@ -66,102 +58,6 @@ func (c *StatusCommand) Init(args []string) error {
 	return nil
 }
 
-type statusContext struct {
-	instances map[instance.Id]instance.Instance
-	machines  map[string][]*state.Machine
-	services  map[string]*state.Service
-	units     map[string]map[string]*state.Unit
-}
-
-type unitMatcher struct {
-	patterns []string
-}
-
-// matchesAny returns true if the unitMatcher will
-// match any unit, regardless of its attributes.
-func (m unitMatcher) matchesAny() bool {
-	return len(m.patterns) == 0
-}
-
-// matchUnit attempts to match a state.Unit to one of
-// a set of patterns, taking into account subordinate
-// relationships.
-func (m unitMatcher) matchUnit(u *state.Unit) bool {
-	if m.matchesAny() {
-		return true
-	}
-
-	// Keep the unit if:
-	//  (a) its name matches a pattern, or
-	//  (b) it's a principal and one of its subordinates matches, or
-	//  (c) it's a subordinate and its principal matches.
-	//
-	// Note: do *not* include a second subordinate if the principal is
-	// only matched on account of a first subordinate matching.
-	if m.matchString(u.Name()) {
-		return true
-	}
-	if u.IsPrincipal() {
-		for _, s := range u.SubordinateNames() {
-			if m.matchString(s) {
-				return true
-			}
-		}
-		return false
-	}
-	principal, valid := u.PrincipalName()
-	if !valid {
-		panic("PrincipalName failed for subordinate unit")
-	}
-	return m.matchString(principal)
-}
-
-// matchString matches a string to one of the patterns in
-// the unit matcher, returning an error if a pattern with
-// invalid syntax is encountered.
-func (m unitMatcher) matchString(s string) bool {
-	for _, pattern := range m.patterns {
-		ok, err := path.Match(pattern, s)
-		if err != nil {
-			// We validate patterns, so should never get here.
-			panic(fmt.Errorf("pattern syntax error in %q", pattern))
-		} else if ok {
-			return true
-		}
-	}
-	return false
-}
-
-// validPattern must match the parts of a unit or service name
-// pattern either side of the '/' for it to be valid.
-var validPattern = regexp.MustCompile("^[a-z0-9-*]+$")
-
-// newUnitMatcher returns a unitMatcher that matches units
-// with one of the specified patterns, or all units if no
-// patterns are specified.
-//
-// An error will be returned if any of the specified patterns
-// is invalid. Patterns are valid if they contain only
-// alpha-numeric characters, hyphens, or asterisks (and one
-// optional '/' to separate service/unit).
-func newUnitMatcher(patterns []string) (unitMatcher, error) {
-	for i, pattern := range patterns {
-		fields := strings.Split(pattern, "/")
-		if len(fields) > 2 {
-			return unitMatcher{}, fmt.Errorf("pattern %q contains too many '/' characters", pattern)
-		}
-		for _, f := range fields {
-			if !validPattern.MatchString(f) {
-				return unitMatcher{}, fmt.Errorf("pattern %q contains invalid characters", pattern)
-			}
-		}
-		if len(fields) == 1 {
-			patterns[i] += "/*"
-		}
-	}
-	return unitMatcher{patterns}, nil
-}
-
 var connectionError = `Unable to connect to environment "%s".
 Please check your credentials or use 'juju bootstrap' to create a new environment.
 
@@ -170,371 +66,141 @@ Error details:
 `
 
 func (c *StatusCommand) Run(ctx *cmd.Context) error {
-	conn, err := juju.NewConnFromName(c.EnvName)
+	conn, err := juju.NewAPIConnFromName(c.EnvName)
 	if err != nil {
 		return fmt.Errorf(connectionError, c.EnvName, err)
 	}
 	defer conn.Close()
 
-	var context statusContext
-	unitMatcher, err := newUnitMatcher(c.patterns)
+	status, err := conn.State.Client().FullStatus(c.patterns)
 	if err != nil {
 		return err
 	}
-	if context.services, context.units, err = fetchAllServicesAndUnits(conn.State, unitMatcher); err != nil {
-		return err
-	}
-
-	// Filter machines by units in scope.
-	var machineIds *set.Strings
-	if !unitMatcher.matchesAny() {
-		machineIds, err = fetchUnitMachineIds(context.units)
-		if err != nil {
-			return err
-		}
-	}
-	if context.machines, err = fetchMachines(conn.State, machineIds); err != nil {
-		return err
-	}
-
-	context.instances, err = fetchAllInstances(conn.Environ)
-	if err != nil {
+	if status.InstancesError != "" {
 		// We cannot see instances from the environment, but
 		// there's still lots of potentially useful info to print.
-		fmt.Fprintf(ctx.Stderr, "cannot retrieve instances from the environment: %v\n", err)
+		fmt.Fprintf(ctx.Stderr, "cannot retrieve instances from the environment: %v\n", status.InstancesError)
 	}
 	result := struct {
 		Environment string                   `json:"environment"`
 		Machines    map[string]machineStatus `json:"machines"`
 		Services    map[string]serviceStatus `json:"services"`
 	}{
-		Environment: conn.Environ.Name(),
-		Machines:    context.processMachines(),
-		Services:    context.processServices(),
+		Environment: status.EnvironmentName,
+		Machines:    formatMachines(status.Machines),
+		Services:    formatServices(status.Services),
 	}
 	return c.out.Write(ctx, result)
 }
 
-// fetchAllInstances returns a map from instance id to instance.
-func fetchAllInstances(env environs.Environ) (map[instance.Id]instance.Instance, error) {
-	m := make(map[instance.Id]instance.Instance)
-	insts, err := env.AllInstances()
-	if err != nil {
-		return nil, err
-	}
-	for _, i := range insts {
-		m[i.Id()] = i
-	}
-	return m, nil
-}
-
-// fetchMachines returns a map from top level machine id to machines, where machines[0] is the host
-// machine and machines[1..n] are any containers (including nested ones).
-//
-// If machineIds is non-nil, only machines whose IDs are in the set are returned.
-func fetchMachines(st *state.State, machineIds *set.Strings) (map[string][]*state.Machine, error) {
-	v := make(map[string][]*state.Machine)
-	machines, err := st.AllMachines()
-	if err != nil {
-		return nil, err
-	}
-	// AllMachines gives us machines sorted by id.
-	for _, m := range machines {
-		if machineIds != nil && !machineIds.Contains(m.Id()) {
-			continue
-		}
-		parentId, ok := m.ParentId()
-		if !ok {
-			// Only top level host machines go directly into the machine map.
-			v[m.Id()] = []*state.Machine{m}
-		} else {
-			topParentId := state.TopParentId(m.Id())
-			machines, ok := v[topParentId]
-			if !ok {
-				panic(fmt.Errorf("unexpected machine id %q", parentId))
-			}
-			machines = append(machines, m)
-			v[topParentId] = machines
-		}
-	}
-	return v, nil
-}
-
-// fetchAllServicesAndUnits returns a map from service name to service
-// and a map from service name to unit name to unit.
-func fetchAllServicesAndUnits(st *state.State, unitMatcher unitMatcher) (map[string]*state.Service, map[string]map[string]*state.Unit, error) {
-	svcMap := make(map[string]*state.Service)
-	unitMap := make(map[string]map[string]*state.Unit)
-	services, err := st.AllServices()
-	if err != nil {
-		return nil, nil, err
-	}
-	for _, s := range services {
-		units, err := s.AllUnits()
-		if err != nil {
-			return nil, nil, err
-		}
-		svcUnitMap := make(map[string]*state.Unit)
-		for _, u := range units {
-			if !unitMatcher.matchUnit(u) {
-				continue
-			}
-			svcUnitMap[u.Name()] = u
-		}
-		if unitMatcher.matchesAny() || len(svcUnitMap) > 0 {
-			unitMap[s.Name()] = svcUnitMap
-			svcMap[s.Name()] = s
-		}
-	}
-	return svcMap, unitMap, nil
-}
-
-// fetchUnitMachineIds returns a set of IDs for machines that
-// the specified units reside on, and those machines' ancestors.
-func fetchUnitMachineIds(units map[string]map[string]*state.Unit) (*set.Strings, error) {
-	machineIds := new(set.Strings)
-	for _, svcUnitMap := range units {
-		for _, unit := range svcUnitMap {
-			if !unit.IsPrincipal() {
-				continue
-			}
-			mid, err := unit.AssignedMachineId()
-			if err != nil {
-				return nil, err
-			}
-			for mid != "" {
-				machineIds.Add(mid)
-				mid = state.ParentId(mid)
-			}
-		}
+// statusError returns err as an error, or nil if it is nil.
+func statusError(err *params.Error) error {
+	if err == nil {
+		return nil
 	}
-	return machineIds, nil
+	return err
 }
 
-func (context *statusContext) processMachines() map[string]machineStatus {
+func formatMachines(machines map[string]api.MachineStatus) map[string]machineStatus {
 	machinesMap := make(map[string]machineStatus)
-	for id, machines := range context.machines {
-		hostStatus := context.makeMachineStatus(machines[0])
-		context.processMachine(machines, &hostStatus, 0)
-		machinesMap[id] = hostStatus
+	for id, m := range machines {
+		machinesMap[id] = formatMachine(m)
 	}
 	return machinesMap
 }
 
-func (context *statusContext) processMachine(machines []*state.Machine, host *machineStatus, startIndex int) (nextIndex int) {
-	nextIndex = startIndex + 1
-	currentHost := host
-	var previousContainer *machineStatus
-	for nextIndex < len(machines) {
-		machine := machines[nextIndex]
-		container := context.makeMachineStatus(machine)
-		if currentHost.Id == state.ParentId(machine.Id()) {
-			currentHost.Containers[machine.Id()] = container
-			previousContainer = &container
-			nextIndex++
-		} else {
-			if state.NestingLevel(machine.Id()) > state.NestingLevel(previousContainer.Id) {
-				nextIndex = context.processMachine(machines, previousContainer, nextIndex-1)
-			} else {
-				break
-			}
+func formatMachine(machine api.MachineStatus) machineStatus {
+	status := machineStatus{
+		Err:            statusError(machine.Err),
+		AgentState:     machine.AgentState,
+		AgentStateInfo: machine.AgentStateInfo,
+		AgentVersion:   machine.AgentVersion,
+		DNSName:        machine.DNSName,
+		InstanceId:     machine.InstanceId,
+		InstanceState:  machine.InstanceState,
+		Life:           machine.Life,
+		Series:         machine.Series,
+		Id:             machine.Id,
+		Containers:     make(map[string]machineStatus),
+		Hardware:       machine.Hardware,
+	}
+	if op := machine.ContainerOperation; op != nil {
+		status.ContainerOperation = &containerOperationStatus{
+			Operation: op.Operation,
+			Snapshot:  op.Snapshot,
+			Status:    "pending",
+			Error:     op.Error,
 		}
-	}
-	return
-}
-
-func (context *statusContext) makeMachineStatus(machine *state.Machine) (status machineStatus) {
-	status.Id = machine.Id()
-	status.Life,
-		status.AgentVersion,
-		status.AgentState,
-		status.AgentStateInfo,
-		status.Err = processAgent(machine)
-	status.Series = machine.Series()
-	instid, err := machine.InstanceId()
-	if err == nil {
-		status.InstanceId = instid
-		inst, ok := context.instances[instid]
-		if ok {
-			status.DNSName, _ = inst.DNSName()
-		} else {
-			// Double plus ungood.  There is an instance id recorded
-			// for this machine in the state, yet the environ cannot
-			// find that id.
-			status.InstanceState = "missing"
+		if op.Error != "" {
+			status.ContainerOperation.Status = "failed"
 		}
-	} else {
-		if state.IsNotProvisionedError(err) {
-			status.InstanceId = "pending"
-		} else {
-			status.InstanceId = "error"
-		}
-		// There's no point in reporting a pending agent state
-		// if the machine hasn't been provisioned. This
-		// also makes unprovisioned machines visually distinct
-		// in the output.
-		status.AgentState = ""
 	}
-	hc, err := machine.HardwareCharacteristics()
-	if err != nil {
-		if !errors.IsNotFoundError(err) {
-			status.Hardware = "error"
-		}
-	} else {
-		status.Hardware = hc.String()
+	for id, container := range machine.Containers {
+		status.Containers[id] = formatMachine(container)
 	}
-	status.Containers = make(map[string]machineStatus)
-	return
+	return status
 }
 
-func (context *statusContext) processServices() map[string]serviceStatus {
+func formatServices(services map[string]api.ServiceStatus) map[string]serviceStatus {
 	servicesMap := make(map[string]serviceStatus)
-	for _, s := range context.services {
-		servicesMap[s.Name()] = context.processService(s)
+	for name, s := range services {
+		servicesMap[name] = serviceStatus{
+			Err:                statusError(s.Err),
+			Charm:              s.Charm,
+			Exposed:            s.Exposed,
+			Life:               s.Life,
+			WorkloadStatus:     s.WorkloadStatus,
+			WorkloadStatusInfo: s.WorkloadStatusInfo,
+			HookPolicy:         formatHookPolicy(s.HookPolicy),
+			Relations:          s.Relations,
+			SubordinateTo:      s.SubordinateTo,
+			Units:              formatUnits(s.Units),
+		}
 	}
 	return servicesMap
 }
 
-func (context *statusContext) processService(service *state.Service) (status serviceStatus) {
-	url, _ := service.CharmURL()
-	status.Charm = url.String()
-	status.Exposed = service.IsExposed()
-	status.Life = processLife(service)
-	var err error
-	status.Relations, status.SubordinateTo, err = context.processRelations(service)
-	if err != nil {
-		status.Err = err
-		return
+func formatUnits(units map[string]api.UnitStatus) map[string]unitStatus {
+	if len(units) == 0 {
+		return nil
 	}
-	if service.IsPrincipal() {
-		status.Units = context.processUnits(context.units[service.Name()])
-	}
-	return status
-}
-
-func (context *statusContext) processUnits(units map[string]*state.Unit) map[string]unitStatus {
 	unitsMap := make(map[string]unitStatus)
-	for _, unit := range units {
-		unitsMap[unit.Name()] = context.processUnit(unit)
-	}
-	return unitsMap
-}
-
-func (context *statusContext) processUnit(unit *state.Unit) (status unitStatus) {
-	status.PublicAddress, _ = unit.PublicAddress()
-	for _, port := range unit.OpenedPorts() {
-		status.OpenedPorts = append(status.OpenedPorts, port.String())
-	}
-	if unit.IsPrincipal() {
-		status.Machine, _ = unit.AssignedMachineId()
-	}
-	status.Life,
-		status.AgentVersion,
-		status.AgentState,
-		status.AgentStateInfo,
-		status.Err = processAgent(unit)
-	if subUnits := unit.SubordinateNames(); len(subUnits) > 0 {
-		status.Subordinates = make(map[string]unitStatus)
-		for _, name := range subUnits {
-			subUnit := context.unitByName(name)
-			// subUnit may be nil if subordinate was filtered out.
-			if subUnit != nil {
-				status.Subordinates[name] = context.processUnit(subUnit)
-			}
-		}
-	}
-	return
-}
-
-func (context *statusContext) unitByName(name string) *state.Unit {
-	serviceName := strings.Split(name, "/")[0]
-	return context.units[serviceName][name]
-}
-
-func (*statusContext) processRelations(service *state.Service) (related map[string][]string, subord []string, err error) {
-	// TODO(mue) This way the same relation is read twice (for each service).
-	// Maybe add Relations() to state, read them only once and pass them to each
-	// call of this function.
-	relations, err := service.Relations()
-	if err != nil {
-		return nil, nil, err
-	}
-	var subordSet set.Strings
-	related = make(map[string][]string)
-	for _, relation := range relations {
-		ep, err := relation.Endpoint(service.Name())
-		if err != nil {
-			return nil, nil, err
-		}
-		relationName := ep.Relation.Name
-		eps, err := relation.RelatedEndpoints(service.Name())
-		if err != nil {
-			return nil, nil, err
-		}
-		for _, ep := range eps {
-			if ep.Scope == charm.ScopeContainer && !service.IsPrincipal() {
-				subordSet.Add(ep.ServiceName)
-			}
-			related[relationName] = append(related[relationName], ep.ServiceName)
+	for name, u := range units {
+		unitsMap[name] = unitStatus{
+			Err:                statusError(u.Err),
+			AgentState:         u.AgentState,
+			AgentStateInfo:     u.AgentStateInfo,
+			AgentVersion:       u.AgentVersion,
+			WorkloadStatus:     u.WorkloadStatus,
+			WorkloadStatusInfo: u.WorkloadStatusInfo,
+			Life:               u.Life,
+			Machine:            u.Machine,
+			OpenedPorts:        u.OpenedPorts,
+			PublishedPorts:     u.PublishedPorts,
+			PublicAddress:      u.PublicAddress,
+			Subordinates:       formatUnits(u.Subordinates),
 		}
 	}
-	for relationName, serviceNames := range related {
-		sn := set.NewStrings(serviceNames...)
-		related[relationName] = sn.SortedValues()
-	}
-	return related, subordSet.SortedValues(), nil
-}
-
-type lifer interface {
-	Life() state.Life
-}
-
-type stateAgent interface {
-	lifer
-	AgentAlive() (bool, error)
-	AgentTools() (*tools.Tools, error)
-	Status() (params.Status, string, error)
+	return unitsMap
 }
 
-// processAgent retrieves version and status information from the given entity
-// and sets the destination version, status and info values accordingly.
-func processAgent(entity stateAgent) (life string, version string, status params.Status, info string, err error) {
-	life = processLife(entity)
-	if t, err := entity.AgentTools(); err == nil {
-		version = t.Version.Number.String()
+// formatHookPolicy returns the status of the given hook policy, or
+// nil if it is the default one.
+func formatHookPolicy(policy params.HookPolicy) *hookPolicyStatus {
+	if policy == (params.HookPolicy{}) {
+		return nil
 	}
-	status, info, err = entity.Status()
-	if err != nil {
-		return
-	}
-	if status == params.StatusPending {
-		// The status is pending - there's no point
-		// in enquiring about the agent liveness.
-		return
+	status := &hookPolicyStatus{Retry: policy.Retry}
+	if policy.Timeout != 0 {
+		status.Timeout = policy.Timeout.String()
 	}
-	agentAlive, err := entity.AgentAlive()
-	if err != nil {
-		return
+	if policy.RetryDelay != 0 {
+		status.RetryDelay = policy.RetryDelay.String()
 	}
-	if entity.Life() != state.Dead && !agentAlive {
-		// The agent *should* be alive but is not.
-		// Add the original status to the info, so it's not lost.
-		if info != "" {
-			info = fmt.Sprintf("(%s: %s)", status, info)
-		} else {
-			info = fmt.Sprintf("(%s)", status)
-		}
-		status = params.StatusDown
-	}
-	return
-}
-
-func processLife(entity lifer) string {
-	if life := entity.Life(); life != state.Alive {
-		// alive is the usual state so omit it by default.
-		return life.String()
+	if policy.MaxRetryDelay != 0 {
+		status.MaxRetryDelay = policy.MaxRetryDelay.String()
 	}
-	return ""
+	return status
 }
 
 type machineStatus struct {
@@ -550,6 +216,17 @@ type machineStatus struct {
 	Id             string                   `json:"-" yaml:"-"`
 	Containers     map[string]machineStatus `json:"containers,omitempty" yaml:"containers,omitempty"`
 	Hardware       string                   `json:"hardware,omitempty" yaml:"hardware,omitempty"`
+
+	ContainerOperation *containerOperationStatus `json:"container-operation,omitempty" yaml:"container-operation,omitempty"`
+}
+
+// containerOperationStatus describes the operation requested on a
+// container machine, while it is pending or once it has failed.
+type containerOperationStatus struct {
+	Operation string `json:"operation" yaml:"operation"`
+	Snapshot  string `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+	Status    string `json:"status" yaml:"status"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
 // A goyaml bug means we can't declare these types
@@ -579,13 +256,16 @@ func (s machineStatus) GetYAML() (tag string, value interface{}) {
 }
 
 type serviceStatus struct {
-	Err           error                 `json:"-" yaml:",omitempty"`
-	Charm         string                `json:"charm" yaml:"charm"`
-	Exposed       bool                  `json:"exposed" yaml:"exposed"`
-	Life          string                `json:"life,omitempty" yaml:"life,omitempty"`
-	Relations     map[string][]string   `json:"relations,omitempty" yaml:"relations,omitempty"`
-	SubordinateTo []string              `json:"subordinate-to,omitempty" yaml:"subordinate-to,omitempty"`
-	Units         map[string]unitStatus `json:"units,omitempty" yaml:"units,omitempty"`
+	Err                error                 `json:"-" yaml:",omitempty"`
+	Charm              string                `json:"charm" yaml:"charm"`
+	Exposed            bool                  `json:"exposed" yaml:"exposed"`
+	Life               string                `json:"life,omitempty" yaml:"life,omitempty"`
+	WorkloadStatus     params.WorkloadStatus `json:"workload-status,omitempty" yaml:"workload-status,omitempty"`
+	WorkloadStatusInfo string                `json:"workload-status-info,omitempty" yaml:"workload-status-info,omitempty"`
+	HookPolicy         *hookPolicyStatus     `json:"hook-policy,omitempty" yaml:"hook-policy,omitempty"`
+	Relations          map[string][]string   `json:"relations,omitempty" yaml:"relations,omitempty"`
+	SubordinateTo      []string              `json:"subordinate-to,omitempty" yaml:"subordinate-to,omitempty"`
+	Units              map[string]unitStatus `json:"units,omitempty" yaml:"units,omitempty"`
 }
 type serviceStatusNoMarshal serviceStatus
 
@@ -605,16 +285,26 @@ func (s serviceStatus) GetYAML() (tag string, value interface{}) {
 	return "", sNoMethods(s)
 }
 
+type hookPolicyStatus struct {
+	Timeout       string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Retry         bool   `json:"retry" yaml:"retry"`
+	RetryDelay    string `json:"retry-delay,omitempty" yaml:"retry-delay,omitempty"`
+	MaxRetryDelay string `json:"max-retry-delay,omitempty" yaml:"max-retry-delay,omitempty"`
+}
+
 type unitStatus struct {
-	Err            error                 `json:"-" yaml:",omitempty"`
-	AgentState     params.Status         `json:"agent-state,omitempty" yaml:"agent-state,omitempty"`
-	AgentStateInfo string                `json:"agent-state-info,omitempty" yaml:"agent-state-info,omitempty"`
-	AgentVersion   string                `json:"agent-version,omitempty" yaml:"agent-version,omitempty"`
-	Life           string                `json:"life,omitempty" yaml:"life,omitempty"`
-	Machine        string                `json:"machine,omitempty" yaml:"machine,omitempty"`
-	OpenedPorts    []string              `json:"open-ports,omitempty" yaml:"open-ports,omitempty"`
-	PublicAddress  string                `json:"public-address,omitempty" yaml:"public-address,omitempty"`
-	Subordinates   map[string]unitStatus `json:"subordinates,omitempty" yaml:"subordinates,omitempty"`
+	Err                error                 `json:"-" yaml:",omitempty"`
+	AgentState         params.Status         `json:"agent-state,omitempty" yaml:"agent-state,omitempty"`
+	AgentStateInfo     string                `json:"agent-state-info,omitempty" yaml:"agent-state-info,omitempty"`
+	AgentVersion       string                `json:"agent-version,omitempty" yaml:"agent-version,omitempty"`
+	WorkloadStatus     params.WorkloadStatus `json:"workload-status,omitempty" yaml:"workload-status,omitempty"`
+	WorkloadStatusInfo string                `json:"workload-status-info,omitempty" yaml:"workload-status-info,omitempty"`
+	Life               string                `json:"life,omitempty" yaml:"life,omitempty"`
+	Machine            string                `json:"machine,omitempty" yaml:"machine,omitempty"`
+	OpenedPorts        []string              `json:"open-ports,omitempty" yaml:"open-ports,omitempty"`
+	PublishedPorts     []string              `json:"published-ports,omitempty" yaml:"published-ports,omitempty"`
+	PublicAddress      string                `json:"public-address,omitempty" yaml:"public-address,omitempty"`
+	Subordinates       map[string]unitStatus `json:"subordinates,omitempty" yaml:"subordinates,omitempty"`
 }
 
 type unitStatusNoMarshal unitStatus
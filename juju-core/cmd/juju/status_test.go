@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	. "launchpad.net/gocheck"
 	"launchpad.net/goyaml"
@@ -66,8 +67,11 @@ type context struct {
 }
 
 func (s *StatusSuite) newContext() *context {
+	// The status command talks to the API server, whose presence
+	// watcher is run against BackingState, so that is the state the
+	// tests manipulate and sync.
 	return &context{
-		st:      s.State,
+		st:      s.BackingState,
 		conn:    s.Conn,
 		charms:  make(map[string]*state.Charm),
 		pingers: make(map[string]*presence.Pinger),
@@ -1221,6 +1225,263 @@ var statusTests = []testCase{
 				},
 			},
 		},
+	), test(
+		"machines with docker containers",
+		addMachine{machineId: "0", job: state.JobManageEnviron},
+		startAliveMachine{"0"},
+		setMachineStatus{"0", params.StatusStarted, ""},
+
+		addMachine{machineId: "1", job: state.JobHostUnits},
+		startAliveMachine{"1"},
+		setMachineStatus{"1", params.StatusStarted, ""},
+
+		// A started docker container and a pending lxc one on machine 1.
+		addContainer{"1", "1/docker/0", state.JobHostUnits},
+		startAliveMachine{"1/docker/0"},
+		setMachineStatus{"1/docker/0", params.StatusStarted, ""},
+		addContainer{"1", "1/lxc/0", state.JobHostUnits},
+
+		// The ports of the docker container are published on the host.
+		addCharm{"wordpress"},
+		addService{"wordpress", "wordpress"},
+		setServiceExposed{"wordpress", true},
+		addAliveUnit{"wordpress", "1/docker/0"},
+		setUnitStatus{"wordpress/0", params.StatusStarted, ""},
+		openUnitPort{"wordpress/0", "tcp", 80},
+		openUnitPort{"wordpress/0", "tcp", 443},
+		setPublishedPorts{"1/docker/0", []instance.Port{{"tcp", 80}}},
+
+		expect{
+			"docker containers are nested under their host machine",
+			M{
+				"environment": "dummyenv",
+				"machines": M{
+					"0": machine0,
+					"1": M{
+						"agent-state": "started",
+						"containers": M{
+							"1/docker/0": M{
+								"agent-state": "started",
+								"dns-name":    "dummyenv-2.dns",
+								"instance-id": "dummyenv-2",
+								"series":      "series",
+							},
+							"1/lxc/0": M{
+								"instance-id": "pending",
+								"series":      "series",
+							},
+						},
+						"dns-name":    "dummyenv-1.dns",
+						"instance-id": "dummyenv-1",
+						"series":      "series",
+						"hardware":    "arch=amd64 cpu-cores=1 mem=1024M",
+					},
+				},
+				"services": M{
+					"wordpress": M{
+						"charm":   "local:series/wordpress-3",
+						"exposed": true,
+						"units": M{
+							"wordpress/0": M{
+								"machine":     "1/docker/0",
+								"agent-state": "started",
+								"open-ports": L{
+									"80/tcp", "443/tcp",
+								},
+								"published-ports": L{
+									"dummyenv-1.dns:80/tcp",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	), test(
+		"operations requested on containers",
+		addMachine{machineId: "0", job: state.JobManageEnviron},
+		startAliveMachine{"0"},
+		setMachineStatus{"0", params.StatusStarted, ""},
+		addMachine{machineId: "1", job: state.JobHostUnits},
+		startAliveMachine{"1"},
+		setMachineStatus{"1", params.StatusStarted, ""},
+		addContainer{"1", "1/lxc/0", state.JobHostUnits},
+		addContainer{"1", "1/lxc/1", state.JobHostUnits},
+		addContainer{"1", "1/lxc/2", state.JobHostUnits},
+
+		requestContainerOp{"1/lxc/0", state.ContainerFreeze, "", ""},
+		requestContainerOp{"1/lxc/1", state.ContainerSnapshot, "before", "no space left on device"},
+		requestContainerOp{"1/lxc/2", state.ContainerThaw, "", "-"},
+
+		expect{
+			"pending and failed operations are shown, succeeded ones are not",
+			M{
+				"environment": "dummyenv",
+				"machines": M{
+					"0": machine0,
+					"1": M{
+						"agent-state": "started",
+						"containers": M{
+							"1/lxc/0": M{
+								"instance-id": "pending",
+								"series":      "series",
+								"container-operation": M{
+									"operation": "freeze",
+									"status":    "pending",
+								},
+							},
+							"1/lxc/1": M{
+								"instance-id": "pending",
+								"series":      "series",
+								"container-operation": M{
+									"operation": "snapshot",
+									"snapshot":  "before",
+									"status":    "failed",
+									"error":     "no space left on device",
+								},
+							},
+							"1/lxc/2": M{
+								"instance-id": "pending",
+								"series":      "series",
+							},
+						},
+						"dns-name":    "dummyenv-1.dns",
+						"instance-id": "dummyenv-1",
+						"series":      "series",
+						"hardware":    "arch=amd64 cpu-cores=1 mem=1024M",
+					},
+				},
+				"services": M{},
+			},
+		},
+	), test(
+		"workload status reported by charms",
+		addMachine{machineId: "0", job: state.JobManageEnviron},
+		startAliveMachine{"0"},
+		setMachineStatus{"0", params.StatusStarted, ""},
+		addMachine{machineId: "1", job: state.JobHostUnits},
+		startAliveMachine{"1"},
+		setMachineStatus{"1", params.StatusStarted, ""},
+		addMachine{machineId: "2", job: state.JobHostUnits},
+		startAliveMachine{"2"},
+		setMachineStatus{"2", params.StatusStarted, ""},
+
+		addCharm{"mysql"},
+		addService{"mysql", "mysql"},
+		addAliveUnit{"mysql", "1"},
+		setUnitStatus{"mysql/0", params.StatusStarted, ""},
+		addAliveUnit{"mysql", "2"},
+		setUnitStatus{"mysql/1", params.StatusStarted, ""},
+
+		expect{
+			"no workload status is shown until the charm reports one",
+			M{
+				"environment": "dummyenv",
+				"machines": M{
+					"0": machine0,
+					"1": machine1,
+					"2": machine2,
+				},
+				"services": M{
+					"mysql": M{
+						"charm":   "local:series/mysql-1",
+						"exposed": false,
+						"units": M{
+							"mysql/0": M{
+								"machine":     "1",
+								"agent-state": "started",
+							},
+							"mysql/1": M{
+								"machine":     "2",
+								"agent-state": "started",
+							},
+						},
+					},
+				},
+			},
+		},
+
+		setUnitWorkloadStatus{"mysql/0", params.WorkloadActive, "ready"},
+		setUnitWorkloadStatus{"mysql/1", params.WorkloadBlocked, "missing config"},
+		expect{
+			"the service shows the workload status most in need of attention",
+			M{
+				"environment": "dummyenv",
+				"machines": M{
+					"0": machine0,
+					"1": machine1,
+					"2": machine2,
+				},
+				"services": M{
+					"mysql": M{
+						"charm":                "local:series/mysql-1",
+						"exposed":              false,
+						"workload-status":      "blocked",
+						"workload-status-info": "missing config",
+						"units": M{
+							"mysql/0": M{
+								"machine":              "1",
+								"agent-state":          "started",
+								"workload-status":      "active",
+								"workload-status-info": "ready",
+							},
+							"mysql/1": M{
+								"machine":              "2",
+								"agent-state":          "started",
+								"workload-status":      "blocked",
+								"workload-status-info": "missing config",
+							},
+						},
+					},
+				},
+			},
+		},
+	), test(
+		"hook policy of services",
+		addMachine{machineId: "0", job: state.JobManageEnviron},
+		startAliveMachine{"0"},
+		setMachineStatus{"0", params.StatusStarted, ""},
+		addMachine{machineId: "1", job: state.JobHostUnits},
+		startAliveMachine{"1"},
+		setMachineStatus{"1", params.StatusStarted, ""},
+
+		addCharm{"mysql"},
+		addService{"mysql", "mysql"},
+		addAliveUnit{"mysql", "1"},
+		setUnitStatus{"mysql/0", params.StatusError, `hook failed: "install"; retrying in 20s (attempt 2)`},
+		setServiceHookPolicy{"mysql", params.HookPolicy{
+			Timeout:    10 * time.Minute,
+			Retry:      true,
+			RetryDelay: 10 * time.Second,
+		}},
+		expect{
+			"the hook policy is shown once set",
+			M{
+				"environment": "dummyenv",
+				"machines": M{
+					"0": machine0,
+					"1": machine1,
+				},
+				"services": M{
+					"mysql": M{
+						"charm":   "local:series/mysql-1",
+						"exposed": false,
+						"hook-policy": M{
+							"timeout":     "10m0s",
+							"retry":       true,
+							"retry-delay": "10s",
+						},
+						"units": M{
+							"mysql/0": M{
+								"machine":          "1",
+								"agent-state":      "error",
+								"agent-state-info": `hook failed: "install"; retrying in 20s (attempt 2)`,
+							},
+						},
+					},
+				},
+			},
+		},
 	),
 }
 
@@ -1252,7 +1513,7 @@ type addContainer struct {
 func (ac addContainer) step(c *C, ctx *context) {
 	params := &state.AddMachineParams{
 		ParentId:      ac.parentId,
-		ContainerType: instance.LXC,
+		ContainerType: state.ContainerTypeFromId(ac.machineId),
 		Series:        "series",
 		Jobs:          []state.MachineJob{ac.job},
 	}
@@ -1365,6 +1626,18 @@ func (sse setServiceExposed) step(c *C, ctx *context) {
 	}
 }
 
+type setServiceHookPolicy struct {
+	name   string
+	policy params.HookPolicy
+}
+
+func (shp setServiceHookPolicy) step(c *C, ctx *context) {
+	s, err := ctx.st.Service(shp.name)
+	c.Assert(err, IsNil)
+	err = s.SetHookPolicy(shp.policy)
+	c.Assert(err, IsNil)
+}
+
 type addUnit struct {
 	serviceName string
 	machineId   string
@@ -1441,6 +1714,19 @@ func (sus setUnitStatus) step(c *C, ctx *context) {
 	c.Assert(err, IsNil)
 }
 
+type setUnitWorkloadStatus struct {
+	unitName string
+	status   params.WorkloadStatus
+	info     string
+}
+
+func (sws setUnitWorkloadStatus) step(c *C, ctx *context) {
+	u, err := ctx.st.Unit(sws.unitName)
+	c.Assert(err, IsNil)
+	err = u.SetWorkloadStatus(sws.status, sws.info)
+	c.Assert(err, IsNil)
+}
+
 type openUnitPort struct {
 	unitName string
 	protocol string
@@ -1454,6 +1740,18 @@ func (oup openUnitPort) step(c *C, ctx *context) {
 	c.Assert(err, IsNil)
 }
 
+type setPublishedPorts struct {
+	machineId string
+	ports     []instance.Port
+}
+
+func (spp setPublishedPorts) step(c *C, ctx *context) {
+	m, err := ctx.st.Machine(spp.machineId)
+	c.Assert(err, IsNil)
+	err = m.SetPublishedPorts(spp.ports)
+	c.Assert(err, IsNil)
+}
+
 type ensureDyingUnit struct {
 	unitName string
 }
@@ -1505,6 +1803,31 @@ func (sms setMachineStatus) step(c *C, ctx *context) {
 	c.Assert(err, IsNil)
 }
 
+// requestContainerOp requests the operation on the container, and
+// completes it with the given error, unless it is empty. It is
+// completed successfully when the error is "-".
+type requestContainerOp struct {
+	machineId string
+	op        state.ContainerOp
+	snapshot  string
+	err       string
+}
+
+func (rco requestContainerOp) step(c *C, ctx *context) {
+	m, err := ctx.st.Machine(rco.machineId)
+	c.Assert(err, IsNil)
+	err = m.RequestContainerOp(rco.op, rco.snapshot)
+	c.Assert(err, IsNil)
+	switch rco.err {
+	case "":
+	case "-":
+		err = m.CompleteContainerOp(nil)
+	default:
+		err = m.CompleteContainerOp(fmt.Errorf("%s", rco.err))
+	}
+	c.Assert(err, IsNil)
+}
+
 type relateServices struct {
 	ep1, ep2 string
 }
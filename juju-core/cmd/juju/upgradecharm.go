@@ -106,24 +106,23 @@ func (c *UpgradeCharmCommand) Init(args []string) error {
 // Run connects to the specified environment and starts the charm
 // upgrade process.
 func (c *UpgradeCharmCommand) Run(ctx *cmd.Context) error {
-	conn, err := juju.NewConnFromName(c.EnvName)
+	client, err := juju.NewAPIClientFromName(c.EnvName)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	service, err := conn.State.Service(c.ServiceName)
+	defer client.Close()
+	oldURL, err := client.ServiceGetCharmURL(c.ServiceName)
 	if err != nil {
 		return err
 	}
-	oldURL, _ := service.CharmURL()
 	var newURL *charm.URL
 	if c.SwitchURL != "" {
 		// A new charm URL was explicitly specified.
-		conf, err := conn.State.EnvironConfig()
+		env, err := client.EnvironmentInfo()
 		if err != nil {
 			return err
 		}
-		newURL, err = charm.InferURL(c.SwitchURL, conf.DefaultSeries())
+		newURL, err = charm.InferURL(c.SwitchURL, env.DefaultSeries)
 		if err != nil {
 			return err
 		}
@@ -166,9 +165,9 @@ func (c *UpgradeCharmCommand) Run(ctx *cmd.Context) error {
 			return fmt.Errorf("cannot increment revision of charm %q: not a directory", newURL)
 		}
 	}
-	sch, err := conn.PutCharm(newURL, repo, bumpRevision)
+	newURL, err = addCharmViaAPI(client, newURL, repo, bumpRevision)
 	if err != nil {
 		return err
 	}
-	return service.SetCharm(sch, c.Force)
+	return client.ServiceSetCharm(c.ServiceName, newURL.String(), c.Force)
 }
@@ -96,11 +96,11 @@ var errUpToDate = stderrors.New("no upgrades available")
 
 // Run changes the version proposed for the juju tools.
 func (c *UpgradeJujuCommand) Run(_ *cmd.Context) (err error) {
-	conn, err := juju.NewConnFromName(c.EnvName)
+	client, err := juju.NewAPIClientFromName(c.EnvName)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
+	defer client.Close()
 	defer func() {
 		if err == errUpToDate {
 			log.Noticef(err.Error())
@@ -109,8 +109,17 @@ func (c *UpgradeJujuCommand) Run(_ *cmd.Context) (err error) {
 	}()
 
 	// Determine the version to upgrade to, uploading tools if necessary.
-	env := conn.Environ
-	cfg, err := conn.State.EnvironConfig()
+	// The tools are found in, and uploaded to, the storage of the
+	// environment opened with the configuration held by the API server.
+	attrs, err := client.EnvironmentConfig()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.New(attrs)
+	if err != nil {
+		return err
+	}
+	env, err := environs.New(cfg)
 	if err != nil {
 		return err
 	}
@@ -131,19 +140,7 @@ func (c *UpgradeJujuCommand) Run(_ *cmd.Context) (err error) {
 	// TODO(fwereade): this list may be incomplete, pending tools.Upload change.
 	log.Infof("available tools: %s", v.tools)
 
-	// Write updated config back to state if necessary. Note that this is
-	// crackful and racy, because we have no idea what incompatible agent-
-	// version might be set by another administrator in the meantime. If
-	// this happens, tough: I'm not going to pretend to do it right when
-	// I'm not.
-	// TODO(fwereade): Do this right. Warning: scope unclear.
-	cfg, err = cfg.Apply(map[string]interface{}{
-		"agent-version": v.chosen.String(),
-	})
-	if err != nil {
-		return err
-	}
-	if err := conn.State.SetEnvironConfig(cfg); err != nil {
+	if err := client.UpgradeJuju(v.chosen); err != nil {
 		return err
 	}
 	log.Noticef("started upgrade to %s", v.chosen)
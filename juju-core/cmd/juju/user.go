@@ -0,0 +1,278 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/juju/osenv"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/utils"
+)
+
+const userDoc = `
+Manage the users of the environment. Each user has read, write or admin
+access to the environment:
+
+ read   users can see the status and the configuration of services, but
+        cannot change anything.
+ write  users can also deploy and manage services, units, relations and
+        machines.
+ admin  users can also change the configuration of the environment,
+        upgrade or destroy it, and manage its users.
+
+Commands connect to the environment as the user named by $JUJU_USER, with
+the password in $JUJU_PASSWORD; the admin user, with the admin-secret of
+the environment, is used when $JUJU_USER is not set.
+`
+
+// newUserCommand returns the super command holding the user management
+// commands.
+func newUserCommand() cmd.Command {
+	usercmd := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:        "user",
+		UsagePrefix: "juju",
+		Doc:         userDoc,
+		Purpose:     "manage the users of the environment",
+	})
+	usercmd.Register(&UserAddCommand{})
+	usercmd.Register(&UserRemoveCommand{})
+	usercmd.Register(&UserListCommand{})
+	usercmd.Register(&UserChangePasswordCommand{})
+	return usercmd
+}
+
+// generatePassword returns password, or a new random password when it
+// is empty, and whether it generated one.
+func generatePassword(password string) (string, bool, error) {
+	if password != "" {
+		return password, false, nil
+	}
+	password, err := utils.RandomPassword()
+	if err != nil {
+		return "", false, fmt.Errorf("cannot generate password: %v", err)
+	}
+	return password, true, nil
+}
+
+// UserAddCommand adds a user to the environment.
+type UserAddCommand struct {
+	cmd.EnvCommandBase
+	Name     string
+	Password string
+	Access   string
+}
+
+const userAddDoc = `
+Add a user with the given access to the environment, read by default. A
+random password is generated, and shown, unless one is given.
+
+Examples:
+ juju user add bob --access write
+ juju user add alice --access admin --password s3cret
+`
+
+func (c *UserAddCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "add",
+		Args:    "<user name>",
+		Purpose: "add a user to the environment",
+		Doc:     userAddDoc,
+	}
+}
+
+func (c *UserAddCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	f.StringVar(&c.Access, "access", string(state.ReadAccess), "access of the user: read, write or admin")
+	f.StringVar(&c.Password, "password", "", "password of the user")
+}
+
+func (c *UserAddCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no user name specified")
+	}
+	c.Name, args = args[0], args[1:]
+	if !state.UserAccess(c.Access).Valid() {
+		return fmt.Errorf("invalid access %q", c.Access)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *UserAddCommand) Run(ctx *cmd.Context) error {
+	client, err := juju.NewAPIClientFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	password, generated, err := generatePassword(c.Password)
+	if err != nil {
+		return err
+	}
+	if err := client.AddUser(c.Name, password, c.Access); err != nil {
+		return err
+	}
+	if generated {
+		fmt.Fprintf(ctx.Stdout, "password: %s\n", password)
+	}
+	return nil
+}
+
+// UserRemoveCommand removes a user from the environment.
+type UserRemoveCommand struct {
+	cmd.EnvCommandBase
+	Name string
+}
+
+func (c *UserRemoveCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "remove",
+		Args:    "<user name>",
+		Purpose: "remove a user from the environment",
+		Doc:     "The admin user cannot be removed.",
+	}
+}
+
+func (c *UserRemoveCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no user name specified")
+	}
+	c.Name, args = args[0], args[1:]
+	return cmd.CheckEmpty(args)
+}
+
+func (c *UserRemoveCommand) Run(ctx *cmd.Context) error {
+	client, err := juju.NewAPIClientFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.RemoveUser(c.Name)
+}
+
+// UserListCommand shows the users of the environment.
+type UserListCommand struct {
+	cmd.EnvCommandBase
+	out cmd.Output
+}
+
+func (c *UserListCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "list",
+		Purpose: "list the users of the environment",
+		Doc:     "Show the access of each user, when they were added and when they last logged in.",
+	}
+}
+
+func (c *UserListCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", cmd.DefaultFormatters)
+}
+
+func (c *UserListCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+type userInfo struct {
+	Access      string `json:"access" yaml:"access"`
+	DateCreated string `json:"date-created,omitempty" yaml:"date-created,omitempty"`
+	LastLogin   string `json:"last-login,omitempty" yaml:"last-login,omitempty"`
+}
+
+// formatTime returns the time in the format shown by the user
+// commands, or the empty string for the zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func (c *UserListCommand) Run(ctx *cmd.Context) error {
+	client, err := juju.NewAPIClientFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	users, err := client.ListUsers()
+	if err != nil {
+		return err
+	}
+	result := make(map[string]userInfo)
+	for _, u := range users {
+		result[u.Name] = userInfo{
+			Access:      u.Access,
+			DateCreated: formatTime(u.DateCreated),
+			LastLogin:   formatTime(u.LastLogin),
+		}
+	}
+	return c.out.Write(ctx, result)
+}
+
+// UserChangePasswordCommand changes the password of a user.
+type UserChangePasswordCommand struct {
+	cmd.EnvCommandBase
+	Name     string
+	Password string
+}
+
+const userChangePasswordDoc = `
+Change the password of a user, by default the one the command connects as.
+Users may change their own password; only admin users may change that of
+others. A random password is generated, and shown, unless one is given.
+
+The password of the admin user is the admin-secret of the environment, and
+cannot be changed with this command.
+`
+
+func (c *UserChangePasswordCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "change-password",
+		Args:    "[<user name>]",
+		Purpose: "change the password of a user",
+		Doc:     userChangePasswordDoc,
+	}
+}
+
+func (c *UserChangePasswordCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	f.StringVar(&c.Password, "password", "", "new password of the user")
+}
+
+func (c *UserChangePasswordCommand) Init(args []string) (err error) {
+	if c.Name, err = cmd.ZeroOrOneArgs(args); err != nil {
+		return err
+	}
+	if c.Name == "" {
+		c.Name = os.Getenv(osenv.JujuUser)
+	}
+	if c.Name == "" || c.Name == state.AdminUser {
+		return fmt.Errorf("cannot change the password of the admin user")
+	}
+	return nil
+}
+
+func (c *UserChangePasswordCommand) Run(ctx *cmd.Context) error {
+	client, err := juju.NewAPIClientFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	password, generated, err := generatePassword(c.Password)
+	if err != nil {
+		return err
+	}
+	if err := client.SetUserPassword(c.Name, password); err != nil {
+		return err
+	}
+	if generated {
+		fmt.Fprintf(ctx.Stdout, "password: %s\n", password)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"strings"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/juju/osenv"
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing"
+)
+
+type UserSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = Suite(&UserSuite{})
+
+func runUser(c *C, args ...string) (*cmd.Context, error) {
+	return testing.RunCommand(c, newUserCommand(), args)
+}
+
+// asUser makes the commands connect as the given user, and returns a
+// function restoring the previous user.
+func asUser(name, password string) func() {
+	restoreUser := testing.PatchEnvironment(osenv.JujuUser, name)
+	restorePassword := testing.PatchEnvironment(osenv.JujuPassword, password)
+	return func() {
+		restoreUser()
+		restorePassword()
+	}
+}
+
+func (s *UserSuite) TestInitErrors(c *C) {
+	for i, t := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"add"},
+		err:  "no user name specified",
+	}, {
+		args: []string{"add", "bob", "--access", "root"},
+		err:  `invalid access "root"`,
+	}, {
+		args: []string{"remove"},
+		err:  "no user name specified",
+	}, {
+		args: []string{"list", "bob"},
+		err:  `unrecognized args: \["bob"\]`,
+	}, {
+		args: []string{"change-password", "admin"},
+		err:  "cannot change the password of the admin user",
+	}} {
+		c.Logf("test %d: %q", i, t.args)
+		_, err := runUser(c, t.args...)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *UserSuite) TestAddUser(c *C) {
+	ctx, err := runUser(c, "add", "bob", "--access", "write", "--password", "s3cret")
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, "")
+	u, err := s.State.User("bob")
+	c.Assert(err, IsNil)
+	c.Assert(u.Access(), Equals, state.WriteAccess)
+	c.Assert(u.PasswordValid("s3cret"), Equals, true)
+
+	ctx, err = runUser(c, "add", "alice")
+	c.Assert(err, IsNil)
+	out := testing.Stdout(ctx)
+	c.Assert(out, Matches, "password: .+\n")
+	u, err = s.State.User("alice")
+	c.Assert(err, IsNil)
+	c.Assert(u.Access(), Equals, state.ReadAccess)
+	password := strings.TrimSpace(strings.TrimPrefix(out, "password: "))
+	c.Assert(u.PasswordValid(password), Equals, true)
+}
+
+func (s *UserSuite) TestRemoveUser(c *C) {
+	_, err := s.State.AddUser("bob", "s3cret", state.WriteAccess)
+	c.Assert(err, IsNil)
+	_, err = runUser(c, "remove", "bob")
+	c.Assert(err, IsNil)
+	_, err = s.State.User("bob")
+	c.Assert(err, ErrorMatches, `user "bob" not found`)
+
+	_, err = runUser(c, "remove", "admin")
+	c.Assert(err, ErrorMatches, `cannot remove user "admin"`)
+}
+
+func (s *UserSuite) TestListUsers(c *C) {
+	_, err := s.State.AddUser("bob", "s3cret", state.ReadAccess)
+	c.Assert(err, IsNil)
+	ctx, err := runUser(c, "list")
+	c.Assert(err, IsNil)
+	out := testing.Stdout(ctx)
+	c.Assert(out, Matches, `(?s)admin:\n  access: admin\n.*bob:\n  access: read\n  date-created: .*`)
+	// Bob never logged in.
+	c.Assert(strings.Count(out, "last-login"), Equals, 1)
+}
+
+func (s *UserSuite) TestChangePassword(c *C) {
+	_, err := s.State.AddUser("bob", "s3cret", state.ReadAccess)
+	c.Assert(err, IsNil)
+	defer asUser("bob", "s3cret")()
+	_, err = runUser(c, "change-password", "--password", "n3w")
+	c.Assert(err, IsNil)
+	u, err := s.State.User("bob")
+	c.Assert(err, IsNil)
+	c.Assert(u.PasswordValid("n3w"), Equals, true)
+	c.Assert(u.LastLogin().IsZero(), Equals, false)
+}
+
+func (s *UserSuite) TestReadAccess(c *C) {
+	_, err := s.State.AddUser("bob", "s3cret", state.ReadAccess)
+	c.Assert(err, IsNil)
+	defer asUser("bob", "s3cret")()
+	_, err = runUser(c, "add", "alice", "--access", "admin")
+	c.Assert(err, ErrorMatches, "permission denied")
+	_, err = testing.RunCommand(c, &StatusCommand{}, nil)
+	c.Assert(err, IsNil)
+}
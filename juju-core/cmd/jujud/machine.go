@@ -14,6 +14,7 @@ import (
 
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/container/docker"
 	localstorage "launchpad.net/juju-core/environs/local/storage"
 	"launchpad.net/juju-core/environs/provider"
 	"launchpad.net/juju-core/instance"
@@ -25,11 +26,13 @@ import (
 	"launchpad.net/juju-core/state/apiserver"
 	"launchpad.net/juju-core/worker"
 	"launchpad.net/juju-core/worker/cleaner"
+	"launchpad.net/juju-core/worker/containerops"
 	"launchpad.net/juju-core/worker/firewaller"
 	"launchpad.net/juju-core/worker/machiner"
 	"launchpad.net/juju-core/worker/minunitsworker"
 	"launchpad.net/juju-core/worker/provisioner"
 	"launchpad.net/juju-core/worker/resumer"
+	"launchpad.net/juju-core/worker/storageprovisioner"
 	"launchpad.net/juju-core/worker/upgrader"
 )
 
@@ -213,11 +216,41 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 	// machine, and once we get nested LXC containers, we can remove this
 	// check.
 	providerType := os.Getenv(osenv.JujuProviderType)
+	if err := recordSupportedContainers(m, providerType); err != nil {
+		st.Close()
+		return nil, err
+	}
 	if providerType != provider.Local && m.ContainerType() != instance.LXC {
 		workerName := fmt.Sprintf("%s-provisioner", provisioner.LXC)
 		runner.StartWorker(workerName, func() (worker.Worker, error) {
 			return provisioner.NewProvisioner(provisioner.LXC, st, a.MachineId, dataDir), nil
 		})
+		// The operations requested on the containers are carried out by
+		// the agent of their host.
+		runner.StartWorker("containerops", func() (worker.Worker, error) {
+			return containerops.NewContainerOps(st, a.MachineId), nil
+		})
+	}
+	// Docker cannot run inside another container, so only machines that
+	// are not lxc or docker containers themselves get a docker provisioner.
+	// It refuses to start containers when no docker daemon was found.
+	if providerType != provider.Local && m.ContainerType() != instance.LXC && m.ContainerType() != instance.DOCKER {
+		workerName := fmt.Sprintf("%s-provisioner", provisioner.DOCKER)
+		runner.StartWorker(workerName, func() (worker.Worker, error) {
+			return provisioner.NewProvisioner(provisioner.DOCKER, st, a.MachineId, dataDir), nil
+		})
+		// The ports of the docker containers are published on the host,
+		// so they are managed by the agent of the host.
+		runner.StartWorker("docker-firewaller", func() (worker.Worker, error) {
+			return firewaller.NewContainerFirewaller(st, a.MachineId)
+		})
+	}
+	// Containers cannot set up devices or mounts, so the storage of
+	// their units is provisioned by the agent of their host.
+	if providerType != provider.Local && m.ContainerType() == "" {
+		runner.StartWorker("storageprovisioner", func() (worker.Worker, error) {
+			return storageprovisioner.NewStorageProvisioner(st, a.MachineId), nil
+		})
 	}
 	// Take advantage of special knowledge here in that we will only ever want
 	// the storage provider on one machine, and that is the "bootstrap" node.
@@ -225,11 +258,14 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 		runner.StartWorker("local-storage", func() (worker.Worker, error) {
 			return localstorage.NewWorker(), nil
 		})
+		runner.StartWorker("storageprovisioner", func() (worker.Worker, error) {
+			return storageprovisioner.NewLocalStorageProvisioner(st, a.MachineId), nil
+		})
 	}
 	for _, job := range m.Jobs() {
 		switch job {
 		case state.JobHostUnits:
-			// Implemented in APIWorker.
+			// The deployer is implemented in APIWorker.
 		case state.JobManageEnviron:
 			runner.StartWorker("environ-provisioner", func() (worker.Worker, error) {
 				return provisioner.NewProvisioner(provisioner.ENVIRON, st, a.MachineId, dataDir), nil
@@ -269,6 +305,29 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 	return newCloseWorker(runner, st), nil
 }
 
+// discoverDocker is called to find the docker daemon of the machine.
+var discoverDocker = docker.Discover
+
+// recordSupportedContainers checks which container types the machine can
+// host and records them in the state, so that containers of the other
+// types are refused rather than left pending.
+func recordSupportedContainers(m *state.Machine, providerType string) error {
+	var containers []instance.ContainerType
+	if providerType != provider.Local && m.ContainerType() != instance.LXC {
+		containers = append(containers, instance.LXC)
+	}
+	if providerType != provider.Local && m.ContainerType() != instance.LXC && m.ContainerType() != instance.DOCKER {
+		info, err := discoverDocker()
+		if err != nil {
+			log.Infof("machine %v cannot host docker containers: %v", m, err)
+		} else {
+			log.Infof("found docker daemon %s using the %s storage driver", info.Version, info.StorageDriver)
+			containers = append(containers, instance.DOCKER)
+		}
+	}
+	return m.SetSupportedContainers(containers)
+}
+
 func (a *MachineAgent) Entity(st *state.State) (AgentState, error) {
 	m, err := st.Machine(a.MachineId)
 	if err != nil {
@@ -16,6 +16,7 @@ import (
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/cmd"
 	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/container/docker"
 	"launchpad.net/juju-core/container/lxc"
 	"launchpad.net/juju-core/environs/dummy"
 	envtesting "launchpad.net/juju-core/environs/testing"
@@ -131,6 +132,44 @@ func (s *MachineSuite) TestRunStop(c *C) {
 	c.Assert(charm.CacheDir, Equals, filepath.Join(ac.DataDir, "charmcache"))
 }
 
+func (s *MachineSuite) assertSupportedContainers(c *C, discoverErr error, expected []instance.ContainerType) {
+	defer func(old func() (*docker.DaemonInfo, error)) { discoverDocker = old }(discoverDocker)
+	discoverDocker = func() (*docker.DaemonInfo, error) {
+		if discoverErr != nil {
+			return nil, discoverErr
+		}
+		return &docker.DaemonInfo{Version: "0.7.0", StorageDriver: "aufs"}, nil
+	}
+	m, _, _ := s.primeAgent(c, state.JobHostUnits)
+	a := s.newAgent(c, m)
+	go func() { c.Check(a.Run(nil), IsNil) }()
+	defer func() { c.Check(a.Stop(), IsNil) }()
+
+	timeout := time.After(testing.LongWait)
+	for {
+		select {
+		case <-timeout:
+			c.Fatalf("supported containers not recorded")
+		case <-time.After(testing.ShortWait):
+			err := m.Refresh()
+			c.Assert(err, IsNil)
+			containers, known := m.SupportedContainers()
+			if known {
+				c.Assert(containers, DeepEquals, expected)
+				return
+			}
+		}
+	}
+}
+
+func (s *MachineSuite) TestRecordsSupportedContainers(c *C) {
+	s.assertSupportedContainers(c, nil, []instance.ContainerType{instance.LXC, instance.DOCKER})
+}
+
+func (s *MachineSuite) TestRecordsSupportedContainersWithoutDocker(c *C) {
+	s.assertSupportedContainers(c, fmt.Errorf("docker daemon not available"), []instance.ContainerType{instance.LXC})
+}
+
 func (s *MachineSuite) TestWithDeadMachine(c *C) {
 	m, _, _ := s.primeAgent(c, state.JobHostUnits, state.JobManageState)
 	err := m.EnsureDead()
@@ -0,0 +1,95 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"net/rpc"
+	"os"
+	"path/filepath"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/worker/uniter"
+)
+
+// RunCommand asks the agent of a unit to run commands in a hook context,
+// on behalf of juju run. It is invoked through a symlink named juju-run.
+type RunCommand struct {
+	cmd.CommandBase
+	dataDir  string
+	unit     string
+	commands string
+
+	// code holds the exit code of the commands, once run.
+	code int
+}
+
+const runCommandDoc = `
+Run the commands in the hook context of the unit, as its agent runs hooks,
+and exit with the exit code of the commands. The agent runs no hook while
+the commands run, so juju-run cannot be called from a hook, or from the
+commands it runs.
+`
+
+func (c *RunCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "juju-run",
+		Args:    "<unit-name> <commands>",
+		Purpose: "run commands in a unit's hook context",
+		Doc:     runCommandDoc,
+	}
+}
+
+func (c *RunCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.dataDir, "data-dir", "/var/lib/juju", "directory for juju data")
+}
+
+func (c *RunCommand) Init(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("missing unit-name")
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("missing commands")
+	}
+	c.unit, c.commands = args[0], args[1]
+	if !names.IsUnit(c.unit) {
+		return fmt.Errorf("invalid unit name %q", c.unit)
+	}
+	return cmd.CheckEmpty(args[2:])
+}
+
+func (c *RunCommand) Run(ctx *cmd.Context) error {
+	// Waiting for the agent from within a hook context would never end.
+	if os.Getenv("JUJU_CONTEXT_ID") != "" {
+		return fmt.Errorf("juju-run cannot be called from a hook context")
+	}
+	socketPath := filepath.Join(c.dataDir, "agents", names.UnitTag(c.unit), uniter.RunListenerFile)
+	client, err := rpc.Dial("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("cannot contact agent of unit %q: %v", c.unit, err)
+	}
+	defer client.Close()
+	var result uniter.RunResult
+	if err := client.Call(uniter.JujuRunEndpoint, c.commands, &result); err != nil {
+		return err
+	}
+	ctx.Stdout.Write(result.Stdout)
+	ctx.Stderr.Write(result.Stderr)
+	c.code = result.Code
+	return nil
+}
+
+// jujuRunMain runs a RunCommand, and returns the exit code of the
+// commands it ran.
+func jujuRunMain(args []string) (code int, err error) {
+	c := &RunCommand{}
+	code = cmd.Main(c, cmd.DefaultContext(), args[1:])
+	if code == 0 {
+		code = c.code
+	}
+	return code, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter"
+)
+
+type RunSuite struct {
+	testing.LoggingSuite
+	dataDir string
+}
+
+var _ = Suite(&RunSuite{})
+
+func (s *RunSuite) SetUpTest(c *C) {
+	s.LoggingSuite.SetUpTest(c)
+	s.dataDir = c.MkDir()
+}
+
+func (s *RunSuite) startListener(c *C, unitTag string) *uniter.RunListener {
+	agentDir := filepath.Join(s.dataDir, "agents", unitTag)
+	err := os.MkdirAll(agentDir, 0755)
+	c.Assert(err, IsNil)
+	listener, err := uniter.NewRunListener(&echoRunner{}, filepath.Join(agentDir, uniter.RunListenerFile))
+	c.Assert(err, IsNil)
+	go listener.Run()
+	return listener
+}
+
+func (s *RunSuite) TestInitErrors(c *C) {
+	for i, t := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "missing unit-name",
+	}, {
+		args: []string{"foo/0"},
+		err:  "missing commands",
+	}, {
+		args: []string{"foo", "hostname"},
+		err:  `invalid unit name "foo"`,
+	}, {
+		args: []string{"foo/0", "hostname", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %q", i, t.args)
+		err := testing.InitCommand(&RunCommand{}, t.args)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *RunSuite) TestRunCommands(c *C) {
+	listener := s.startListener(c, "unit-foo-0")
+	defer listener.Close()
+
+	command := &RunCommand{}
+	ctx := testing.Context(c)
+	code := cmd.Main(command, ctx, []string{"--data-dir", s.dataDir, "foo/0", "exit 3"})
+	c.Assert(code, Equals, 0)
+	c.Assert(command.code, Equals, 3)
+	c.Assert(testing.Stdout(ctx), Equals, "exit 3")
+	c.Assert(testing.Stderr(ctx), Equals, "running commands of foo/0")
+}
+
+func (s *RunSuite) TestNoAgent(c *C) {
+	ctx := testing.Context(c)
+	code := cmd.Main(&RunCommand{}, ctx, []string{"--data-dir", s.dataDir, "foo/0", "hostname"})
+	c.Assert(code, Equals, 1)
+	c.Assert(testing.Stderr(ctx), Matches, `error: cannot contact agent of unit "foo/0": .*\n`)
+}
+
+func (s *RunSuite) TestInHookContext(c *C) {
+	listener := s.startListener(c, "unit-foo-0")
+	defer listener.Close()
+	defer testing.PatchEnvironment("JUJU_CONTEXT_ID", "foo/0:install:42")()
+
+	ctx := testing.Context(c)
+	code := cmd.Main(&RunCommand{}, ctx, []string{"--data-dir", s.dataDir, "foo/0", "hostname"})
+	c.Assert(code, Equals, 1)
+	c.Assert(testing.Stderr(ctx), Equals, "error: juju-run cannot be called from a hook context\n")
+	c.Assert(testing.Stdout(ctx), Equals, "")
+}
+
+// echoRunner writes the commands it is asked to run to stdout.
+type echoRunner struct{}
+
+func (*echoRunner) RunCommands(commands string) (*uniter.RunResult, error) {
+	return &uniter.RunResult{
+		Code:   3,
+		Stdout: []byte(commands),
+		Stderr: []byte("running commands of foo/0"),
+	}, nil
+}
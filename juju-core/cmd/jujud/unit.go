@@ -70,28 +70,13 @@ func (a *UnitAgent) Run(ctx *cmd.Context) error {
 		return err
 	}
 	agentLogger.Infof("unit agent %v start", a.Tag())
-	a.runner.StartWorker("state", a.StateWorkers)
 	a.runner.StartWorker("api", a.APIWorkers)
 	err := agentDone(a.runner.Wait())
 	a.tomb.Kill(err)
 	return err
 }
 
-// StateWorkers returns a worker that runs the unit agent workers.
-func (a *UnitAgent) StateWorkers() (worker.Worker, error) {
-	st, entity, err := openState(a.Conf.Conf, a)
-	if err != nil {
-		return nil, err
-	}
-	unit := entity.(*state.Unit)
-	dataDir := a.Conf.DataDir
-	runner := worker.NewRunner(allFatal, moreImportant)
-	runner.StartWorker("uniter", func() (worker.Worker, error) {
-		return uniter.NewUniter(st, unit.Name(), dataDir), nil
-	})
-	return newCloseWorker(runner, st), nil
-}
-
+// APIWorkers returns a worker that runs the unit agent workers.
 func (a *UnitAgent) APIWorkers() (worker.Worker, error) {
 	st, entity, err := openAPIState(a.Conf.Conf, a)
 	if err != nil {
@@ -102,6 +87,9 @@ func (a *UnitAgent) APIWorkers() (worker.Worker, error) {
 	runner.StartWorker("upgrader", func() (worker.Worker, error) {
 		return upgrader.New(st.Upgrader(), entity.Tag(), dataDir), nil
 	})
+	runner.StartWorker("uniter", func() (worker.Worker, error) {
+		return uniter.NewUniter(st.Uniter(), entity.Tag(), dataDir), nil
+	})
 	return newCloseWorker(runner, st), nil
 }
 
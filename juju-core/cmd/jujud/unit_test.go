@@ -148,7 +148,6 @@ func (s *UnitSuite) TestWithDeadUnit(c *C) {
 }
 
 func (s *UnitSuite) TestOpenAPIState(c *C) {
-	c.Skip("unit agent API connection not implemented yet")
 	unit, _, _ := s.primeAgent(c)
 	s.testOpenAPIState(c, unit, s.newAgent(c, unit))
 }
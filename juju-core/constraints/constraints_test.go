@@ -57,6 +57,9 @@ var parseConstraintsTests = []struct {
 	}, {
 		summary: "set container lxc",
 		args:    []string{"container=lxc"},
+	}, {
+		summary: "set container docker",
+		args:    []string{"container=docker"},
 	}, {
 		summary: "set nonsense container",
 		args:    []string{"container=foo"},
@@ -411,6 +414,9 @@ var hasContainerTests = []struct {
 	}, {
 		constraints:  "container=lxc",
 		hasContainer: true,
+	}, {
+		constraints:  "container=docker",
+		hasContainer: true,
 	}, {
 		constraints:  "container=none",
 		hasContainer: false,
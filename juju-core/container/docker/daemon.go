@@ -0,0 +1,47 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker
+
+import (
+	"fmt"
+
+	"launchpad.net/godocker"
+)
+
+// DaemonClient holds the operations of the docker daemon used to check
+// whether the machine can host docker containers.
+type DaemonClient interface {
+	// Version returns the version of the daemon.
+	Version() (*godocker.Version, error)
+	// Info returns the system wide information held by the daemon.
+	Info() (*godocker.Info, error)
+}
+
+var daemonClient DaemonClient = godocker.NewClient(godocker.DefaultSocket)
+
+// DaemonInfo describes the docker daemon of the machine.
+type DaemonInfo struct {
+	Version       string
+	StorageDriver string
+}
+
+// Discover checks that the docker daemon of the machine is reachable,
+// and returns its version and storage driver.
+func Discover() (*DaemonInfo, error) {
+	version, err := daemonClient.Version()
+	if err != nil {
+		return nil, fmt.Errorf("docker daemon not available: %v", err)
+	}
+	info, err := daemonClient.Info()
+	if err != nil {
+		return nil, fmt.Errorf("docker daemon not available: %v", err)
+	}
+	if info.Driver == "" {
+		return nil, fmt.Errorf("docker daemon %s has no storage driver", version.Version)
+	}
+	return &DaemonInfo{
+		Version:       version.Version,
+		StorageDriver: info.Driver,
+	}, nil
+}
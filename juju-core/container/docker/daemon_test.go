@@ -0,0 +1,51 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker_test
+
+import (
+	"fmt"
+
+	gc "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/container/docker"
+	"launchpad.net/juju-core/testing"
+)
+
+type DaemonSuite struct {
+	testing.LoggingSuite
+	docker.TestSuite
+}
+
+var _ = gc.Suite(&DaemonSuite{})
+
+func (s *DaemonSuite) SetUpTest(c *gc.C) {
+	s.LoggingSuite.SetUpTest(c)
+	s.TestSuite.SetUpTest(c)
+}
+
+func (s *DaemonSuite) TearDownTest(c *gc.C) {
+	s.TestSuite.TearDownTest(c)
+	s.LoggingSuite.TearDownTest(c)
+}
+
+func (s *DaemonSuite) TestDiscover(c *gc.C) {
+	info, err := docker.Discover()
+	c.Assert(err, gc.IsNil)
+	c.Assert(info, gc.DeepEquals, &docker.DaemonInfo{
+		Version:       "0.7.0",
+		StorageDriver: "aufs",
+	})
+}
+
+func (s *DaemonSuite) TestDiscoverNoDaemon(c *gc.C) {
+	s.Daemon.Err = fmt.Errorf(`cannot talk to the docker daemon at "/var/run/docker.sock": no such file`)
+	_, err := docker.Discover()
+	c.Assert(err, gc.ErrorMatches, `docker daemon not available: cannot talk to the docker daemon .*`)
+}
+
+func (s *DaemonSuite) TestDiscoverNoStorageDriver(c *gc.C) {
+	s.Daemon.Driver = ""
+	_, err := docker.Discover()
+	c.Assert(err, gc.ErrorMatches, `docker daemon 0.7.0 has no storage driver`)
+}
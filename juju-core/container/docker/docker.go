@@ -0,0 +1,347 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"launchpad.net/godocker"
+	"launchpad.net/loggo"
+
+	"launchpad.net/juju-core/agent/tools"
+	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/environs/cloudinit"
+	"launchpad.net/juju-core/environs/config"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api"
+)
+
+var logger = loggo.GetLogger("juju.container.docker")
+
+var (
+	defaultImage        = "ubuntu"
+	containerDir        = "/var/lib/juju/containers"
+	removedContainerDir = "/var/lib/juju/removed-containers"
+	dockerObjectFactory = godocker.Factory()
+)
+
+const (
+	// DefaultDockerBridge is the bridge created by the docker daemon.
+	DefaultDockerBridge = "docker0"
+	// JujuBaseImage is the repository of the images with the juju
+	// tools and dependencies preinstalled, tagged with the tools version.
+	JujuBaseImage = "juju-base"
+	// seedMountPoint is where cloud-init's NoCloud data source looks
+	// for the user data inside the container.
+	seedMountPoint = "/var/lib/cloud/seed/nocloud-net"
+	// logMountPoint is where the agents inside the container write their
+	// logs.
+	logMountPoint = "/var/log/juju"
+)
+
+// ManagerConfig contains the initialization parameters for the ContainerManager.
+type ManagerConfig struct {
+	Name string
+	// Image is the repository the juju base images are built from,
+	// tagged with the machine series. It defaults to "ubuntu".
+	Image string
+	// LogDir is the host directory mounted as the log directory of the
+	// containers. It defaults to a directory per container.
+	LogDir string
+}
+
+// ContainerManager is responsible for starting containers, and stopping and
+// listing containers that it has started.  The name of the manager is used to
+// namespace the docker containers on the machine.
+//
+// Unlike lxc, docker attaches every container to its own bridge, so
+// there is no network configuration to pass.
+type ContainerManager interface {
+	// StartContainer creates and starts a new docker container for the specified machine.
+	StartContainer(
+		machineId, series, nonce string,
+		tools *tools.Tools,
+		environConfig *config.Config,
+		stateInfo *state.Info,
+		apiInfo *api.Info) (instance.Instance, error)
+	// StopContainer stops and destroys the docker container identified by Instance.
+	StopContainer(instance.Instance) error
+	// ListContainers return a list of containers that have been started by
+	// this manager.
+	ListContainers() ([]instance.Instance, error)
+}
+
+type containerManager struct {
+	name   string
+	image  string
+	logdir string
+}
+
+// NewContainerManager returns a manager object that can start and stop docker
+// containers. The containers that are created are namespaced by the name
+// parameter.
+func NewContainerManager(conf ManagerConfig) ContainerManager {
+	image := defaultImage
+	if conf.Image != "" {
+		image = conf.Image
+	}
+	return &containerManager{name: conf.Name, image: image, logdir: conf.LogDir}
+}
+
+func (manager *containerManager) StartContainer(
+	machineId, series, nonce string,
+	tools *tools.Tools,
+	environConfig *config.Config,
+	stateInfo *state.Info,
+	apiInfo *api.Info) (instance.Instance, error) {
+
+	name := names.MachineTag(machineId)
+	if manager.name != "" {
+		name = fmt.Sprintf("%s-%s", manager.name, name)
+	}
+	container := dockerObjectFactory.New(name)
+
+	// Create the cloud-init seed, which is mounted into the container.
+	directory := jujuContainerDirectory(name)
+	logger.Tracef("create directory: %s", directory)
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		logger.Errorf("failed to create container directory: %v", err)
+		return nil, err
+	}
+	logger.Tracef("write cloud-init")
+	if err := writeUserData(directory, name, machineId, nonce, tools, environConfig, stateInfo, apiInfo); err != nil {
+		logger.Errorf("failed to write user data: %v", err)
+		return nil, err
+	}
+	logDir := manager.logdir
+	if logDir == "" {
+		logDir = containerLogDir(name)
+	}
+	logger.Tracef("make the container log dir")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		logger.Errorf("failed to create container log dir: %v", err)
+		return nil, err
+	}
+	logger.Tracef("write the docker.json file")
+	configFile, err := writeDockerConfig(directory, name, logDir)
+	if err != nil {
+		logger.Errorf("failed to write config file: %v", err)
+		return nil, err
+	}
+	// The tools are preinstalled in the base image, so that nothing is
+	// downloaded when the container boots.
+	logger.Tracef("ensure the base image")
+	image, err := EnsureBaseImage(manager.image, tools)
+	if err != nil {
+		logger.Errorf("failed to ensure base image: %v", err)
+		return nil, err
+	}
+	// Create the container, booting upstart so that cloud-init picks up
+	// the seed and installs the machine agent.
+	logger.Tracef("create the container")
+	if err := container.Create(configFile, image, "/sbin/init"); err != nil {
+		logger.Errorf("docker container creation failed: %v", err)
+		return nil, err
+	}
+	logger.Tracef("start the container")
+	if err := container.Start("", ""); err != nil {
+		logger.Errorf("container failed to start: %v", err)
+		return nil, err
+	}
+	logger.Tracef("container started")
+	return &dockerInstance{name}, nil
+}
+
+func (manager *containerManager) StopContainer(instance instance.Instance) error {
+	name := string(instance.Id())
+	container := dockerObjectFactory.New(name)
+	if err := unpublishContainerPorts(name); err != nil {
+		logger.Errorf("failed to unpublish docker container ports: %v", err)
+		return err
+	}
+	// The container may have been frozen by the user, or have exited.
+	state, _, err := container.Info()
+	if err != nil {
+		logger.Errorf("failed to get docker container state: %v", err)
+		return err
+	}
+	if state == godocker.StateFrozen {
+		if err := container.Unfreeze(); err != nil {
+			logger.Errorf("failed to thaw docker container: %v", err)
+			return err
+		}
+	}
+	if state == godocker.StateRunning || state == godocker.StateFrozen {
+		if err := container.Stop(); err != nil {
+			logger.Errorf("failed to stop docker container: %v", err)
+			return err
+		}
+	}
+	if err := container.Destroy(); err != nil {
+		logger.Errorf("failed to destroy docker container: %v", err)
+		return err
+	}
+
+	// Move the directory, keeping the container logs around.
+	logger.Tracef("create old container dir: %s", removedContainerDir)
+	if err := os.MkdirAll(removedContainerDir, 0755); err != nil {
+		logger.Errorf("failed to create removed container directory: %v", err)
+		return err
+	}
+	removedDir, err := uniqueDirectory(removedContainerDir, name)
+	if err != nil {
+		logger.Errorf("was not able to generate a unique directory: %v", err)
+		return err
+	}
+	if err := os.Rename(jujuContainerDirectory(name), removedDir); err != nil {
+		logger.Errorf("failed to rename container directory: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (manager *containerManager) ListContainers() (result []instance.Instance, err error) {
+	containers, err := dockerObjectFactory.List()
+	if err != nil {
+		logger.Errorf("failed getting all instances: %v", err)
+		return
+	}
+	managerPrefix := ""
+	if manager.name != "" {
+		managerPrefix = fmt.Sprintf("%s-", manager.name)
+	}
+
+	for _, container := range containers {
+		// Filter out those not starting with our name. Containers
+		// that are frozen or have exited are still ours to stop.
+		name := container.Name()
+		if strings.HasPrefix(name, managerPrefix) {
+			result = append(result, &dockerInstance{name})
+		}
+	}
+	return
+}
+
+func jujuContainerDirectory(containerName string) string {
+	return filepath.Join(containerDir, containerName)
+}
+
+// seedDirectory holds the cloud-init user data and meta data of the
+// container.
+func seedDirectory(containerName string) string {
+	return filepath.Join(jujuContainerDirectory(containerName), "seed")
+}
+
+// containerLogDir holds the logs written inside the container.
+func containerLogDir(containerName string) string {
+	return filepath.Join(jujuContainerDirectory(containerName), "log")
+}
+
+func writeDockerConfig(directory, containerName, logDir string) (string, error) {
+	config := &godocker.Config{
+		Hostname: containerName,
+		HostConfig: &godocker.HostConfig{
+			Binds: []string{
+				seedDirectory(containerName) + ":" + seedMountPoint + ":ro",
+				logDir + ":" + logMountPoint,
+			},
+		},
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	configFilename := filepath.Join(directory, "docker.json")
+	if err := ioutil.WriteFile(configFilename, data, 0644); err != nil {
+		return "", err
+	}
+	return configFilename, nil
+}
+
+const metaDataTemplate = `instance-id: %s
+local-hostname: %s
+`
+
+func writeUserData(
+	directory, containerName, machineId, nonce string,
+	tools *tools.Tools,
+	environConfig *config.Config,
+	stateInfo *state.Info,
+	apiInfo *api.Info,
+) error {
+	userData, err := cloudInitUserData(machineId, nonce, tools, environConfig, stateInfo, apiInfo)
+	if err != nil {
+		logger.Errorf("failed to create user data: %v", err)
+		return err
+	}
+	seedDir := seedDirectory(containerName)
+	if err := os.MkdirAll(seedDir, 0755); err != nil {
+		return err
+	}
+	// Keep a copy alongside the other container files, as lxc does.
+	if err := ioutil.WriteFile(filepath.Join(directory, "cloud-init"), userData, 0644); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(seedDir, "user-data"), userData, 0644); err != nil {
+		return err
+	}
+	metaData := fmt.Sprintf(metaDataTemplate, containerName, containerName)
+	return ioutil.WriteFile(filepath.Join(seedDir, "meta-data"), []byte(metaData), 0644)
+}
+
+func cloudInitUserData(
+	machineId, nonce string,
+	tools *tools.Tools,
+	environConfig *config.Config,
+	stateInfo *state.Info,
+	apiInfo *api.Info,
+) ([]byte, error) {
+	machineConfig := &cloudinit.MachineConfig{
+		MachineId:            machineId,
+		MachineNonce:         nonce,
+		MachineContainerType: instance.DOCKER,
+		StateInfo:            stateInfo,
+		APIInfo:              apiInfo,
+		DataDir:              dataDir,
+		Tools:                tools,
+	}
+	if err := environs.FinishMachineConfig(machineConfig, environConfig, constraints.Value{}); err != nil {
+		return nil, err
+	}
+	cloudConfig, err := cloudinit.New(machineConfig)
+	if err != nil {
+		return nil, err
+	}
+	// Run ifconfig to get the addresses of the internal container at least
+	// logged in the host.
+	cloudConfig.AddRunCmd("ifconfig")
+	return cloudConfig.Render()
+}
+
+// uniqueDirectory returns "path/name" if that directory doesn't exist.  If it
+// does, the method starts appending .1, .2, etc until a unique name is found.
+func uniqueDirectory(path, name string) (string, error) {
+	dir := filepath.Join(path, name)
+	_, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return dir, nil
+	}
+	for i := 1; ; i++ {
+		dir := filepath.Join(path, fmt.Sprintf("%s.%d", name, i))
+		_, err := os.Stat(dir)
+		if os.IsNotExist(err) {
+			return dir, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	stdtesting "testing"
+
+	gc "launchpad.net/gocheck"
+	"launchpad.net/godocker"
+	"launchpad.net/goyaml"
+	"launchpad.net/loggo"
+
+	"launchpad.net/juju-core/agent/tools"
+	"launchpad.net/juju-core/container/docker"
+	"launchpad.net/juju-core/container/docker/mock"
+	"launchpad.net/juju-core/instance"
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/testing"
+	jc "launchpad.net/juju-core/testing/checkers"
+	"launchpad.net/juju-core/version"
+)
+
+func Test(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type DockerSuite struct {
+	testing.LoggingSuite
+	docker.TestSuite
+}
+
+var _ = gc.Suite(&DockerSuite{})
+
+func (s *DockerSuite) SetUpSuite(c *gc.C) {
+	s.LoggingSuite.SetUpSuite(c)
+	s.TestSuite.SetUpSuite(c)
+}
+
+func (s *DockerSuite) TearDownSuite(c *gc.C) {
+	s.TestSuite.TearDownSuite(c)
+	s.LoggingSuite.TearDownSuite(c)
+}
+
+func (s *DockerSuite) SetUpTest(c *gc.C) {
+	s.LoggingSuite.SetUpTest(c)
+	s.TestSuite.SetUpTest(c)
+	s.AddTools(c, fakeTools)
+	loggo.GetLogger("juju.container.docker").SetLogLevel(loggo.TRACE)
+}
+
+func (s *DockerSuite) TearDownTest(c *gc.C) {
+	s.TestSuite.TearDownTest(c)
+	s.LoggingSuite.TearDownTest(c)
+}
+
+var fakeTools = &tools.Tools{
+	Version: version.MustParseBinary("2.3.4-foo-bar"),
+	URL:     "http://tools.testing.invalid/2.3.4-foo-bar.tgz",
+}
+
+func StartContainer(c *gc.C, manager docker.ContainerManager, machineId string) instance.Instance {
+	config := testing.EnvironConfig(c)
+	stateInfo := jujutesting.FakeStateInfo(machineId)
+	apiInfo := jujutesting.FakeAPIInfo(machineId)
+
+	series := "foo"
+	nonce := "fake-nonce"
+
+	inst, err := manager.StartContainer(machineId, series, nonce, fakeTools, config, stateInfo, apiInfo)
+	c.Assert(err, gc.IsNil)
+	return inst
+}
+
+func (s *DockerSuite) TestStartContainer(c *gc.C) {
+	manager := docker.NewContainerManager(docker.ManagerConfig{})
+	instance := StartContainer(c, manager, "1/docker/0")
+
+	name := string(instance.Id())
+	c.Assert(name, gc.Equals, "machine-1-docker-0")
+
+	// Check the container has been created from the base image of the
+	// tools, booting upstart.
+	creation := mock.Creation(s.Factory, name)
+	c.Assert(creation, gc.NotNil)
+	c.Assert(creation.Image, gc.Equals, "juju-base:2.3.4-foo-bar")
+	build := s.Images.Build("juju-base:2.3.4-foo-bar")
+	c.Assert(build, gc.NotNil)
+	c.Assert(build["Dockerfile"], jc.HasPrefix, "FROM ubuntu:foo\n")
+	c.Assert(creation.Cmd, gc.DeepEquals, []string{"/sbin/init"})
+	c.Assert(creation.ConfigFile, gc.Equals, filepath.Join(s.ContainerDir, name, "docker.json"))
+
+	// Check the seed and log directories are mounted into the container.
+	data, err := ioutil.ReadFile(creation.ConfigFile)
+	c.Assert(err, gc.IsNil)
+	var config godocker.Config
+	err = json.Unmarshal(data, &config)
+	c.Assert(err, gc.IsNil)
+	c.Assert(config.Hostname, gc.Equals, name)
+	c.Assert(config.HostConfig, gc.NotNil)
+	c.Assert(config.HostConfig.Binds, gc.DeepEquals, []string{
+		filepath.Join(s.ContainerDir, name, "seed") + ":/var/lib/cloud/seed/nocloud-net:ro",
+		filepath.Join(s.ContainerDir, name, "log") + ":/var/log/juju",
+	})
+	c.Assert(filepath.Join(s.ContainerDir, name, "log"), jc.IsDirectory)
+
+	userDataFilename := filepath.Join(s.ContainerDir, name, "seed", "user-data")
+	c.Assert(userDataFilename, jc.IsNonEmptyFile)
+	data, err = ioutil.ReadFile(userDataFilename)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), jc.HasPrefix, "#cloud-config\n")
+
+	x := make(map[interface{}]interface{})
+	err = goyaml.Unmarshal(data, &x)
+	c.Assert(err, gc.IsNil)
+	var scripts []string
+	for _, s := range x["runcmd"].([]interface{}) {
+		scripts = append(scripts, s.(string))
+	}
+	c.Assert(scripts[len(scripts)-2:], gc.DeepEquals, []string{
+		"start jujud-machine-1-docker-0",
+		"ifconfig",
+	})
+	// The tools are in the image already.
+	for _, script := range scripts {
+		c.Assert(script, gc.Not(jc.Contains), "wget")
+	}
+	// Docker containers must not pull in lxc.
+	packages, _ := x["packages"].([]interface{})
+	for _, pkg := range packages {
+		c.Assert(pkg, gc.Not(gc.Equals), "lxc")
+	}
+
+	metaData, err := ioutil.ReadFile(filepath.Join(s.ContainerDir, name, "seed", "meta-data"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(metaData), gc.Equals, fmt.Sprintf("instance-id: %s\nlocal-hostname: %s\n", name, name))
+}
+
+func (s *DockerSuite) TestStartContainerWithImage(c *gc.C) {
+	manager := docker.NewContainerManager(docker.ManagerConfig{Image: "juju"})
+	instance := StartContainer(c, manager, "1/docker/0")
+	creation := mock.Creation(s.Factory, string(instance.Id()))
+	c.Assert(creation, gc.NotNil)
+	c.Assert(creation.Image, gc.Equals, "juju-base:2.3.4-foo-bar")
+	build := s.Images.Build("juju-base:2.3.4-foo-bar")
+	c.Assert(build["Dockerfile"], jc.HasPrefix, "FROM juju:foo\n")
+}
+
+func (s *DockerSuite) TestStartContainerReusesBaseImage(c *gc.C) {
+	manager := docker.NewContainerManager(docker.ManagerConfig{})
+	s.Images.AddImage("juju-base:2.3.4-foo-bar")
+	instance := StartContainer(c, manager, "1/docker/0")
+	creation := mock.Creation(s.Factory, string(instance.Id()))
+	c.Assert(creation.Image, gc.Equals, "juju-base:2.3.4-foo-bar")
+	c.Assert(s.Images.Build("juju-base:2.3.4-foo-bar"), gc.IsNil)
+}
+
+func (s *DockerSuite) TestStartContainerWithLogDir(c *gc.C) {
+	logDir := filepath.Join(c.MkDir(), "log")
+	manager := docker.NewContainerManager(docker.ManagerConfig{LogDir: logDir})
+	instance := StartContainer(c, manager, "1/docker/0")
+	c.Assert(logDir, jc.IsDirectory)
+
+	creation := mock.Creation(s.Factory, string(instance.Id()))
+	c.Assert(creation, gc.NotNil)
+	data, err := ioutil.ReadFile(creation.ConfigFile)
+	c.Assert(err, gc.IsNil)
+	var config godocker.Config
+	err = json.Unmarshal(data, &config)
+	c.Assert(err, gc.IsNil)
+	c.Assert(config.HostConfig.Binds[1], gc.Equals, logDir+":/var/log/juju")
+}
+
+func (s *DockerSuite) TestStopContainer(c *gc.C) {
+	manager := docker.NewContainerManager(docker.ManagerConfig{})
+	instance := StartContainer(c, manager, "1/docker/0")
+
+	err := manager.StopContainer(instance)
+	c.Assert(err, gc.IsNil)
+
+	name := string(instance.Id())
+	// Check that the container dir is no longer in the container dir
+	c.Assert(filepath.Join(s.ContainerDir, name), jc.DoesNotExist)
+	// but instead, in the removed container dir
+	c.Assert(filepath.Join(s.RemovedDir, name), jc.IsDirectory)
+	c.Assert(mock.Creation(s.Factory, name), gc.IsNil)
+}
+
+func (s *DockerSuite) TestStopContainerNotRunning(c *gc.C) {
+	manager := docker.NewContainerManager(docker.ManagerConfig{})
+	frozen := StartContainer(c, manager, "1/docker/0")
+	c.Assert(s.Factory.New(string(frozen.Id())).Freeze(), gc.IsNil)
+	exited := StartContainer(c, manager, "1/docker/1")
+	c.Assert(s.Factory.New(string(exited.Id())).Stop(), gc.IsNil)
+
+	result, err := manager.ListContainers()
+	c.Assert(err, gc.IsNil)
+	testing.MatchInstances(c, result, frozen, exited)
+
+	for _, inst := range []instance.Instance{frozen, exited} {
+		err := manager.StopContainer(inst)
+		c.Assert(err, gc.IsNil)
+		c.Assert(mock.Creation(s.Factory, string(inst.Id())), gc.IsNil)
+	}
+	result, err = manager.ListContainers()
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.HasLen, 0)
+}
+
+func (s *DockerSuite) TestStopContainerNameClash(c *gc.C) {
+	manager := docker.NewContainerManager(docker.ManagerConfig{})
+	instance := StartContainer(c, manager, "1/docker/0")
+
+	name := string(instance.Id())
+	targetDir := filepath.Join(s.RemovedDir, name)
+	err := os.MkdirAll(targetDir, 0755)
+	c.Assert(err, gc.IsNil)
+
+	err = manager.StopContainer(instance)
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(filepath.Join(s.ContainerDir, name), jc.DoesNotExist)
+	c.Assert(filepath.Join(s.RemovedDir, fmt.Sprintf("%s.1", name)), jc.IsDirectory)
+}
+
+func (s *DockerSuite) TestNamedManagerPrefix(c *gc.C) {
+	manager := docker.NewContainerManager(docker.ManagerConfig{Name: "eric"})
+	instance := StartContainer(c, manager, "1/docker/0")
+	c.Assert(string(instance.Id()), gc.Equals, "eric-machine-1-docker-0")
+}
+
+func (s *DockerSuite) TestListContainers(c *gc.C) {
+	foo := docker.NewContainerManager(docker.ManagerConfig{Name: "foo"})
+	bar := docker.NewContainerManager(docker.ManagerConfig{Name: "bar"})
+
+	foo1 := StartContainer(c, foo, "1/docker/0")
+	foo2 := StartContainer(c, foo, "1/docker/1")
+	foo3 := StartContainer(c, foo, "1/docker/2")
+
+	bar1 := StartContainer(c, bar, "1/docker/0")
+	bar2 := StartContainer(c, bar, "1/docker/1")
+
+	result, err := foo.ListContainers()
+	c.Assert(err, gc.IsNil)
+	testing.MatchInstances(c, result, foo1, foo2, foo3)
+
+	result, err = bar.ListContainers()
+	c.Assert(err, gc.IsNil)
+	testing.MatchInstances(c, result, bar1, bar2)
+}
+
+func (s *DockerSuite) TestAddresses(c *gc.C) {
+	manager := docker.NewContainerManager(docker.ManagerConfig{})
+	inst := StartContainer(c, manager, "1/docker/0")
+
+	addresses, err := inst.Addresses()
+	c.Assert(err, gc.IsNil)
+	c.Assert(addresses, gc.HasLen, 1)
+	c.Assert(addresses[0].Value, gc.Matches, `172\.17\.0\.\d+`)
+	c.Assert(addresses[0].Type, gc.Equals, instance.Ipv4Address)
+	c.Assert(addresses[0].NetworkName, gc.Equals, docker.DefaultDockerBridge)
+	c.Assert(addresses[0].NetworkScope, gc.Equals, instance.NetworkMachineLocal)
+
+	dnsName, err := inst.DNSName()
+	c.Assert(err, gc.IsNil)
+	c.Assert(dnsName, gc.Equals, addresses[0].Value)
+}
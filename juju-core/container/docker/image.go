@@ -0,0 +1,173 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"text/template"
+
+	"launchpad.net/godocker"
+
+	"launchpad.net/juju-core/agent/tools"
+	"launchpad.net/juju-core/version"
+)
+
+// ImageClient holds the image operations of the docker daemon used to
+// build the juju base images.
+type ImageClient interface {
+	// ImageExists reports whether the named image exists.
+	ImageExists(name string) (bool, error)
+	// BuildImage builds the named image from the tar archive context.
+	BuildImage(name string, context io.Reader) error
+}
+
+var imageClient ImageClient = godocker.NewClient(godocker.DefaultSocket)
+
+// dataDir is the data directory of the agents in the containers.
+const dataDir = "/var/lib/juju"
+
+// toolsCacheDir is the data directory holding the tools the base images
+// are built from, kept so that other images for the same tools version
+// need no download.
+var toolsCacheDir = "/var/lib/juju/docker-tools"
+
+// BaseImage returns the name of the juju base image holding the given
+// tools version.
+func BaseImage(vers version.Binary) string {
+	return fmt.Sprintf("%s:%s", JujuBaseImage, vers)
+}
+
+// The base image holds everything a machine agent needs that does not
+// depend on the machine: the packages, upgraded, the tools and the
+// directories of the agent configuration, of the upstart jobs and of
+// the logs. The agent.conf file and the upstart job of the machine
+// agent are still written by cloud-init from the seed of each
+// container, since they name the machine and hold its nonce, its
+// password and the CA certificate of the environment, which are only
+// known once the machine is provisioned. They need no download, so
+// cloud-init does not update or install packages in docker containers.
+var dockerfileTemplate = template.Must(template.New("").Parse(`
+FROM {{.From}}:{{.Version.Series}}
+RUN apt-get update && apt-get upgrade -y && apt-get install -y cloud-init git
+RUN mkdir -p {{.ToolsDir}} {{.DataDir}}/agents /etc/init /var/log/juju
+ADD tools {{.ToolsDir}}
+`[1:]))
+
+// Dockerfile returns the Dockerfile building the juju base image from
+// the from repository, with the given tools installed in the shared
+// tools directory.
+func Dockerfile(from string, vers version.Binary) (string, error) {
+	var buf bytes.Buffer
+	err := dockerfileTemplate.Execute(&buf, struct {
+		From     string
+		Version  version.Binary
+		DataDir  string
+		ToolsDir string
+	}{from, vers, dataDir, tools.SharedToolsDir(dataDir, vers)})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// EnsureBaseImage returns the name of the juju base image for the
+// given tools, building it from the from repository if it does not
+// exist yet. The image is tagged with the tools version, so it is only
+// built once for each version.
+func EnsureBaseImage(from string, agentTools *tools.Tools) (string, error) {
+	image := BaseImage(agentTools.Version)
+	exists, err := imageClient.ImageExists(image)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		logger.Debugf("using existing base image %q", image)
+		return image, nil
+	}
+	if _, err := tools.ReadTools(toolsCacheDir, agentTools.Version); err != nil {
+		if err := fetchTools(agentTools); err != nil {
+			return "", fmt.Errorf("cannot fetch tools %v: %v", agentTools.Version, err)
+		}
+	}
+	dockerfile, err := Dockerfile(from, agentTools.Version)
+	if err != nil {
+		return "", err
+	}
+	context, err := buildContext(dockerfile, tools.SharedToolsDir(toolsCacheDir, agentTools.Version))
+	if err != nil {
+		return "", err
+	}
+	logger.Infof("building base image %q", image)
+	if err := imageClient.BuildImage(image, context); err != nil {
+		return "", err
+	}
+	return image, nil
+}
+
+func fetchTools(agentTools *tools.Tools) error {
+	logger.Infof("fetching tools from %q", agentTools.URL)
+	resp, err := http.Get(agentTools.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad HTTP response: %v", resp.Status)
+	}
+	if err := tools.UnpackTools(toolsCacheDir, agentTools, resp.Body); err != nil {
+		return fmt.Errorf("cannot unpack tools: %v", err)
+	}
+	return nil
+}
+
+// buildContext returns the tar archive sent to the docker daemon,
+// holding the Dockerfile and the files of the tools directory.
+func buildContext(dockerfile, toolsDir string) (io.Reader, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := writeTarFile(tw, "Dockerfile", 0644, []byte(dockerfile)); err != nil {
+		return nil, err
+	}
+	infos, err := ioutil.ReadDir(toolsDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(toolsDir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if err := writeTarFile(tw, path.Join("tools", info.Name()), info.Mode(), data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, mode os.FileMode, data []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name: name,
+		Mode: int64(mode & 0777),
+		Size: int64(len(data)),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
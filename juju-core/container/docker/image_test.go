@@ -0,0 +1,108 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+
+	gc "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/agent/tools"
+	"launchpad.net/juju-core/container/docker"
+	"launchpad.net/juju-core/testing"
+	jc "launchpad.net/juju-core/testing/checkers"
+	"launchpad.net/juju-core/version"
+)
+
+type ImageSuite struct {
+	testing.LoggingSuite
+	docker.TestSuite
+}
+
+var _ = gc.Suite(&ImageSuite{})
+
+func (s *ImageSuite) SetUpTest(c *gc.C) {
+	s.LoggingSuite.SetUpTest(c)
+	s.TestSuite.SetUpTest(c)
+}
+
+func (s *ImageSuite) TearDownTest(c *gc.C) {
+	s.TestSuite.TearDownTest(c)
+	s.LoggingSuite.TearDownTest(c)
+}
+
+func (s *ImageSuite) TestBaseImage(c *gc.C) {
+	vers := version.MustParseBinary("1.2.3-precise-amd64")
+	c.Assert(docker.BaseImage(vers), gc.Equals, "juju-base:1.2.3-precise-amd64")
+}
+
+func (s *ImageSuite) TestDockerfile(c *gc.C) {
+	dockerfile, err := docker.Dockerfile("ubuntu", version.MustParseBinary("1.2.3-precise-amd64"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(dockerfile, gc.Equals, `
+FROM ubuntu:precise
+RUN apt-get update && apt-get upgrade -y && apt-get install -y cloud-init git
+RUN mkdir -p /var/lib/juju/tools/1.2.3-precise-amd64 /var/lib/juju/agents /etc/init /var/log/juju
+ADD tools /var/lib/juju/tools/1.2.3-precise-amd64
+`[1:])
+}
+
+func (s *ImageSuite) TestEnsureBaseImageCachedTools(c *gc.C) {
+	s.AddTools(c, fakeTools)
+	image, err := docker.EnsureBaseImage("ubuntu", fakeTools)
+	c.Assert(err, gc.IsNil)
+	c.Assert(image, gc.Equals, "juju-base:2.3.4-foo-bar")
+
+	build := s.Images.Build(image)
+	c.Assert(build, gc.DeepEquals, map[string]string{
+		"Dockerfile":               build["Dockerfile"],
+		"tools/jujud":              "fake jujud",
+		"tools/downloaded-url.txt": fakeTools.URL,
+	})
+	c.Assert(build["Dockerfile"], jc.HasPrefix, "FROM ubuntu:foo\n")
+}
+
+func (s *ImageSuite) TestEnsureBaseImageFetchesTools(c *gc.C) {
+	archive := testing.TarGz(testing.NewTarFile("jujud", 0755, "served jujud"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+	agentTools := &tools.Tools{
+		Version: version.MustParseBinary("2.3.4-foo-bar"),
+		URL:     server.URL + "/tools.tgz",
+	}
+
+	image, err := docker.EnsureBaseImage("ubuntu", agentTools)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.Images.Build(image)["tools/jujud"], gc.Equals, "served jujud")
+
+	// The tools are kept for the next build.
+	toolsDir := tools.SharedToolsDir(s.ToolsCacheDir, agentTools.Version)
+	c.Assert(filepath.Join(toolsDir, "jujud"), jc.IsNonEmptyFile)
+}
+
+func (s *ImageSuite) TestEnsureBaseImageFetchFailure(c *gc.C) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	agentTools := &tools.Tools{
+		Version: version.MustParseBinary("2.3.4-foo-bar"),
+		URL:     server.URL + "/tools.tgz",
+	}
+
+	_, err := docker.EnsureBaseImage("ubuntu", agentTools)
+	c.Assert(err, gc.ErrorMatches, `cannot fetch tools 2.3.4-foo-bar: bad HTTP response: 404 Not Found`)
+	c.Assert(s.Images.Build("juju-base:2.3.4-foo-bar"), gc.IsNil)
+}
+
+func (s *ImageSuite) TestEnsureBaseImageExisting(c *gc.C) {
+	s.Images.AddImage("juju-base:2.3.4-foo-bar")
+	// No tools are needed when the image exists.
+	image, err := docker.EnsureBaseImage("ubuntu", fakeTools)
+	c.Assert(err, gc.IsNil)
+	c.Assert(image, gc.Equals, "juju-base:2.3.4-foo-bar")
+	c.Assert(s.Images.Build(image), gc.IsNil)
+}
@@ -0,0 +1,69 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker
+
+import (
+	"fmt"
+
+	"launchpad.net/godocker"
+
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/instance"
+)
+
+type dockerInstance struct {
+	id string
+}
+
+var _ instance.Instance = (*dockerInstance)(nil)
+
+// Id implements instance.Instance.Id.
+func (docker *dockerInstance) Id() instance.Id {
+	return instance.Id(docker.id)
+}
+
+// Addresses implements instance.Instance.Addresses, reporting the
+// address of the container on the docker bridge.
+func (docker *dockerInstance) Addresses() ([]instance.Address, error) {
+	container := dockerObjectFactory.New(docker.id)
+	inspector, ok := container.(godocker.Inspector)
+	if !ok {
+		return nil, fmt.Errorf("cannot inspect container %q", docker.id)
+	}
+	info, err := inspector.Inspect()
+	if err != nil {
+		return nil, err
+	}
+	network := info.NetworkSettings
+	if network == nil || network.IPAddress == "" {
+		return nil, nil
+	}
+	addr := instance.NewAddress(network.IPAddress)
+	addr.NetworkName = network.Bridge
+	addr.NetworkScope = instance.NetworkMachineLocal
+	return []instance.Address{addr}, nil
+}
+
+// DNSName implements instance.Instance.DNSName. Containers have no
+// dns name of their own, so their bridge address is used.
+func (docker *dockerInstance) DNSName() (string, error) {
+	addresses, err := docker.Addresses()
+	if err != nil {
+		return "", err
+	}
+	if len(addresses) == 0 {
+		return "", instance.ErrNoDNSName
+	}
+	return addresses[0].Value, nil
+}
+
+// WaitDNSName implements instance.Instance.WaitDNSName.
+func (docker *dockerInstance) WaitDNSName() (string, error) {
+	return environs.WaitDNSName(docker)
+}
+
+// Add a string representation of the id.
+func (docker *dockerInstance) String() string {
+	return fmt.Sprintf("docker:%s", docker.id)
+}
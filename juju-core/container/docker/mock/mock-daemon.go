@@ -0,0 +1,42 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mock
+
+import (
+	"launchpad.net/godocker"
+)
+
+// DaemonClient is a mock implementation of the operations of the docker
+// daemon reporting its version and system information.
+type DaemonClient struct {
+	// DaemonVersion and Driver are reported by the daemon.
+	DaemonVersion string
+	Driver        string
+	// Err, when not nil, is returned as if the daemon was unreachable.
+	Err error
+}
+
+// MockDaemonClient returns a DaemonClient reporting a running daemon.
+func MockDaemonClient() *DaemonClient {
+	return &DaemonClient{
+		DaemonVersion: "0.7.0",
+		Driver:        "aufs",
+	}
+}
+
+// Version returns the version of the daemon.
+func (mock *DaemonClient) Version() (*godocker.Version, error) {
+	if mock.Err != nil {
+		return nil, mock.Err
+	}
+	return &godocker.Version{Version: mock.DaemonVersion}, nil
+}
+
+// Info returns the system wide information held by the daemon.
+func (mock *DaemonClient) Info() (*godocker.Info, error) {
+	if mock.Err != nil {
+		return nil, mock.Err
+	}
+	return &godocker.Info{Driver: mock.Driver}, nil
+}
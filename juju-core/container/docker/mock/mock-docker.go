@@ -0,0 +1,343 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mock
+
+import (
+	"fmt"
+	"sync"
+
+	"launchpad.net/gocontainer"
+	"launchpad.net/godocker"
+)
+
+// This file provides a mock implementation of the gocontainer interfaces
+// ContainerFactory and Container, along with godocker's Inspector.
+
+type Action int
+
+const (
+	// A container has been started.
+	Started Action = iota
+	// A container has been stopped.
+	Stopped
+)
+
+func (action Action) String() string {
+	switch action {
+	case Started:
+		return "Started"
+	case Stopped:
+		return "Stopped"
+	}
+	return "unknown"
+}
+
+type Event struct {
+	Action     Action
+	InstanceId string
+}
+
+type ContainerFactory interface {
+	gocontainer.ContainerFactory
+
+	AddListener(chan<- Event)
+	RemoveListener(chan<- Event)
+}
+
+type mockFactory struct {
+	mu        sync.Mutex
+	instances map[string]*mockContainer
+	listeners []chan<- Event
+	nextIP    int
+}
+
+func MockFactory() ContainerFactory {
+	return &mockFactory{
+		instances: make(map[string]*mockContainer),
+	}
+}
+
+type mockContainer struct {
+	factory    *mockFactory
+	name       string
+	state      gocontainer.State
+	configFile string
+	image      string
+	cmd        []string
+	ipAddress  string
+	logFile    string
+	logLevel   gocontainer.LogLevel
+}
+
+// ContainerCreation describes how a mock container was created.
+type ContainerCreation struct {
+	ConfigFile string
+	Image      string
+	Cmd        []string
+}
+
+// Creation returns how the container with the given name was created,
+// or nil if there is no such container.
+func Creation(factory ContainerFactory, name string) *ContainerCreation {
+	mock := factory.(*mockFactory)
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	container, ok := mock.instances[name]
+	if !ok {
+		return nil
+	}
+	return &ContainerCreation{
+		ConfigFile: container.configFile,
+		Image:      container.image,
+		Cmd:        container.cmd,
+	}
+}
+
+// Name returns the name of the container.
+func (mock *mockContainer) Name() string {
+	return mock.name
+}
+
+// Create creates a new container from the given image.
+func (mock *mockContainer) Create(configFile, template string, templateArgs ...string) error {
+	mock.factory.mu.Lock()
+	defer mock.factory.mu.Unlock()
+	if mock.state != godocker.StateUnknown {
+		return fmt.Errorf("container is already created")
+	}
+	mock.state = godocker.StateStopped
+	mock.configFile = configFile
+	mock.image = template
+	mock.cmd = templateArgs
+	mock.factory.instances[mock.name] = mock
+	return nil
+}
+
+// Start runs the container as a daemon.
+func (mock *mockContainer) Start(configFile, consoleFile string) error {
+	mock.factory.mu.Lock()
+	if mock.state == godocker.StateUnknown {
+		mock.factory.mu.Unlock()
+		return fmt.Errorf("container has not been created")
+	} else if mock.state == godocker.StateRunning {
+		mock.factory.mu.Unlock()
+		return fmt.Errorf("container is already running")
+	}
+	mock.state = godocker.StateRunning
+	mock.factory.nextIP++
+	mock.ipAddress = fmt.Sprintf("172.17.0.%d", mock.factory.nextIP+1)
+	mock.factory.mu.Unlock()
+	mock.factory.notify(Started, mock.name)
+	return nil
+}
+
+// Stop terminates the running container.
+func (mock *mockContainer) Stop() error {
+	mock.factory.mu.Lock()
+	if mock.state == godocker.StateUnknown {
+		mock.factory.mu.Unlock()
+		return fmt.Errorf("container has not been created")
+	} else if mock.state == godocker.StateStopped {
+		mock.factory.mu.Unlock()
+		return fmt.Errorf("container is already stopped")
+	}
+	mock.state = godocker.StateStopped
+	mock.ipAddress = ""
+	mock.factory.mu.Unlock()
+	mock.factory.notify(Stopped, mock.name)
+	return nil
+}
+
+// Clone creates a copy of the container, giving the copy the specified name.
+func (mock *mockContainer) Clone(name string) (gocontainer.Container, error) {
+	mock.factory.mu.Lock()
+	defer mock.factory.mu.Unlock()
+	container := &mockContainer{
+		factory:  mock.factory,
+		name:     name,
+		state:    godocker.StateStopped,
+		image:    name,
+		cmd:      mock.cmd,
+		logLevel: godocker.LogWarning,
+	}
+	mock.factory.instances[name] = container
+	return container, nil
+}
+
+// Freeze freezes all the container's processes.
+func (mock *mockContainer) Freeze() error {
+	mock.factory.mu.Lock()
+	defer mock.factory.mu.Unlock()
+	if mock.state != godocker.StateRunning {
+		return fmt.Errorf("container is not running")
+	}
+	mock.state = godocker.StateFrozen
+	return nil
+}
+
+// Unfreeze thaws all frozen container's processes.
+func (mock *mockContainer) Unfreeze() error {
+	mock.factory.mu.Lock()
+	defer mock.factory.mu.Unlock()
+	if mock.state != godocker.StateFrozen {
+		return fmt.Errorf("container is not frozen")
+	}
+	mock.state = godocker.StateRunning
+	return nil
+}
+
+// Destroy stops and removes the container.
+func (mock *mockContainer) Destroy() error {
+	mock.factory.mu.Lock()
+	defer mock.factory.mu.Unlock()
+	if mock.state == godocker.StateUnknown {
+		return fmt.Errorf("container has not been created")
+	} else if mock.state == godocker.StateRunning {
+		return fmt.Errorf("container is running")
+	}
+	mock.state = godocker.StateUnknown
+	delete(mock.factory.instances, mock.name)
+	return nil
+}
+
+// Wait waits for one of the specified container states.
+func (mock *mockContainer) Wait(states ...gocontainer.State) error {
+	return nil
+}
+
+// Info returns the status and the process id of the container.
+func (mock *mockContainer) Info() (gocontainer.State, int, error) {
+	mock.factory.mu.Lock()
+	defer mock.factory.mu.Unlock()
+	pid := -1
+	if mock.state == godocker.StateRunning {
+		pid = 42
+	}
+	return mock.state, pid, nil
+}
+
+// Inspect returns the details the daemon holds about the container.
+func (mock *mockContainer) Inspect() (*godocker.ContainerInfo, error) {
+	state, pid, _ := mock.Info()
+	if state == godocker.StateUnknown {
+		return nil, fmt.Errorf("no such container %q", mock.name)
+	}
+	mock.factory.mu.Lock()
+	defer mock.factory.mu.Unlock()
+	info := &godocker.ContainerInfo{
+		ID:   mock.name,
+		Name: "/" + mock.name,
+		Config: &godocker.Config{
+			Hostname: mock.name,
+			Image:    mock.image,
+			Cmd:      mock.cmd,
+		},
+		Image:           mock.image,
+		NetworkSettings: &godocker.NetworkSettings{},
+	}
+	if state == godocker.StateRunning {
+		info.State.Running = true
+		info.State.Pid = pid
+		info.NetworkSettings = &godocker.NetworkSettings{
+			IPAddress:   mock.ipAddress,
+			IPPrefixLen: 16,
+			Gateway:     "172.17.42.1",
+			Bridge:      "docker0",
+		}
+	}
+	return info, nil
+}
+
+// IsConstructed checks if the container exists.
+func (mock *mockContainer) IsConstructed() bool {
+	state, _, _ := mock.Info()
+	return state != godocker.StateUnknown
+}
+
+// IsRunning checks if the state of the container is 'RUNNING'.
+func (mock *mockContainer) IsRunning() bool {
+	state, _, _ := mock.Info()
+	return state == godocker.StateRunning
+}
+
+// String returns information about the container, like the name, state,
+// and process id.
+func (mock *mockContainer) String() string {
+	state, pid, _ := mock.Info()
+	return fmt.Sprintf("<MockContainer %q, state: %s, pid %d>", mock.name, string(state), pid)
+}
+
+// LogFile returns the current filename used for the LogFile.
+func (mock *mockContainer) LogFile() string {
+	return mock.logFile
+}
+
+// LogLevel returns the current logging level (only used if the
+// LogFile is not "").
+func (mock *mockContainer) LogLevel() gocontainer.LogLevel {
+	return mock.logLevel
+}
+
+// SetLogFile sets both the LogFile and LogLevel.
+func (mock *mockContainer) SetLogFile(filename string, level gocontainer.LogLevel) {
+	mock.logFile = filename
+	mock.logLevel = level
+}
+
+func (mock *mockFactory) String() string {
+	return fmt.Sprintf("mock docker factory")
+}
+
+func (mock *mockFactory) New(name string) gocontainer.Container {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	container, ok := mock.instances[name]
+	if ok {
+		return container
+	}
+	return &mockContainer{
+		factory:  mock,
+		name:     name,
+		state:    godocker.StateUnknown,
+		logLevel: godocker.LogWarning,
+	}
+}
+
+func (mock *mockFactory) Capabilities() []gocontainer.Capability {
+	return []gocontainer.Capability{
+		gocontainer.Freezable,
+		gocontainer.Cloneable,
+		gocontainer.ImageBased,
+	}
+}
+
+func (mock *mockFactory) List() (result []gocontainer.Container, err error) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	for _, container := range mock.instances {
+		result = append(result, container)
+	}
+	return
+}
+
+func (mock *mockFactory) notify(action Action, instanceId string) {
+	event := Event{action, instanceId}
+	for _, c := range mock.listeners {
+		c <- event
+	}
+}
+
+func (mock *mockFactory) AddListener(listener chan<- Event) {
+	mock.listeners = append(mock.listeners, listener)
+}
+
+func (mock *mockFactory) RemoveListener(listener chan<- Event) {
+	pos := 0
+	for i, c := range mock.listeners {
+		if c == listener {
+			pos = i
+		}
+	}
+	mock.listeners = append(mock.listeners[:pos], mock.listeners[pos+1:]...)
+}
@@ -0,0 +1,79 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mock
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// ImageClient is a mock implementation of the image operations of the
+// docker daemon, recording the images it builds.
+type ImageClient struct {
+	mu     sync.Mutex
+	images map[string]bool
+	builds map[string]map[string]string
+}
+
+// MockImageClient returns an ImageClient that knows no images.
+func MockImageClient() *ImageClient {
+	return &ImageClient{
+		images: make(map[string]bool),
+		builds: make(map[string]map[string]string),
+	}
+}
+
+// AddImage records the named image as existing.
+func (mock *ImageClient) AddImage(name string) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	mock.images[name] = true
+}
+
+// ImageExists reports whether the named image exists.
+func (mock *ImageClient) ImageExists(name string) (bool, error) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	return mock.images[name], nil
+}
+
+// BuildImage records the contents of the build context, and the image
+// as existing.
+func (mock *ImageClient) BuildImage(name string, context io.Reader) error {
+	files := make(map[string]string)
+	tr := tar.NewReader(context)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		files[hdr.Name] = string(data)
+	}
+	if _, ok := files["Dockerfile"]; !ok {
+		return fmt.Errorf("cannot build image %q: no Dockerfile", name)
+	}
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	mock.images[name] = true
+	mock.builds[name] = files
+	return nil
+}
+
+// Build returns the files of the context the named image was built
+// from, or nil if it was not built.
+func (mock *ImageClient) Build(name string) map[string]string {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	return mock.builds[name]
+}
@@ -0,0 +1,71 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mock
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Iptables is a mock implementation of the iptables commands used to
+// publish container ports, keeping the rules of the nat table.
+type Iptables struct {
+	mu    sync.Mutex
+	rules map[string][]string
+}
+
+// MockIptables returns an Iptables with empty chains.
+func MockIptables() *Iptables {
+	return &Iptables{
+		rules: make(map[string][]string),
+	}
+}
+
+// Run emulates running iptables with the given arguments. Only the
+// nat table and the -A, -D and -S commands are supported.
+func (mock *Iptables) Run(args ...string) (string, error) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	if len(args) < 4 || args[0] != "-t" || args[1] != "nat" {
+		return "", fmt.Errorf("unsupported iptables command %q", args)
+	}
+	chain := args[3]
+	spec := strings.Join(args[4:], " ")
+	switch args[2] {
+	case "-A":
+		mock.rules[chain] = append(mock.rules[chain], spec)
+		return "", nil
+	case "-D":
+		var kept []string
+		deleted := false
+		for _, rule := range mock.rules[chain] {
+			if rule == spec && !deleted {
+				deleted = true
+				continue
+			}
+			kept = append(kept, rule)
+		}
+		if !deleted {
+			return "", fmt.Errorf("iptables: bad rule (does a matching rule exist in that chain?)")
+		}
+		mock.rules[chain] = kept
+		return "", nil
+	case "-S":
+		out := fmt.Sprintf("-P %s ACCEPT\n", chain)
+		for _, rule := range mock.rules[chain] {
+			out += fmt.Sprintf("-A %s %s\n", chain, rule)
+		}
+		return out, nil
+	}
+	return "", fmt.Errorf("unsupported iptables command %q", args)
+}
+
+// Rules returns the rules of the chain of the nat table, as given when
+// they were appended.
+func (mock *Iptables) Rules(chain string) []string {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	return append([]string(nil), mock.rules[chain]...)
+}
@@ -0,0 +1,234 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/state"
+)
+
+// The ports of a container are published on the same port of its host
+// with NAT rules in the nat table, as the docker daemon does itself for
+// the ports given when a container starts. The daemon can only publish
+// ports before starting a container, while units open their ports long
+// after that, so the rules are managed here. Each rule carries a comment
+// naming the container it forwards to.
+
+// publishChain is the chain of the nat table holding the rules.
+const publishChain = "PREROUTING"
+
+// runIptables runs iptables with the given arguments and returns its
+// output.
+var runIptables = func(args ...string) (string, error) {
+	out, err := exec.Command("iptables", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("iptables %s: %v (%s)", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// publishedPort describes a rule publishing a container port.
+type publishedPort struct {
+	container   string
+	port        instance.Port
+	destination string
+}
+
+// ruleComment returns the comment marking the rules of the container.
+func ruleComment(containerName string) string {
+	return "juju:" + containerName
+}
+
+// ruleSpec returns the rule specification forwarding the port of the
+// host to the same port of the container.
+func (p publishedPort) ruleSpec() []string {
+	return []string{
+		"-p", p.port.Protocol,
+		"-m", "addrtype", "--dst-type", "LOCAL",
+		"--dport", strconv.Itoa(p.port.Number),
+		"-m", "comment", "--comment", ruleComment(p.container),
+		"-j", "DNAT", "--to-destination", p.destination,
+	}
+}
+
+// publishedPorts returns the ports published on the host for all the
+// containers.
+func publishedPorts() ([]publishedPort, error) {
+	out, err := runIptables("-t", "nat", "-S", publishChain)
+	if err != nil {
+		return nil, err
+	}
+	var published []publishedPort
+	for _, line := range strings.Split(out, "\n") {
+		if p, ok := parseRule(line); ok {
+			published = append(published, p)
+		}
+	}
+	return published, nil
+}
+
+// parseRule returns the published port described by a line of the
+// chain listing, if it is one of the rules managed here.
+func parseRule(line string) (p publishedPort, ok bool) {
+	fields := strings.Fields(line)
+	for i := 0; i+1 < len(fields); i++ {
+		value := strings.Trim(fields[i+1], `"`)
+		switch fields[i] {
+		case "-p":
+			p.port.Protocol = value
+		case "--dport":
+			p.port.Number, _ = strconv.Atoi(value)
+		case "--comment":
+			p.container = strings.TrimPrefix(value, "juju:")
+			ok = value != p.container
+		case "--to-destination":
+			p.destination = value
+		}
+	}
+	if p.port.Protocol == "" || p.port.Number == 0 || p.destination == "" {
+		return publishedPort{}, false
+	}
+	return p, ok
+}
+
+// containerPorts returns the published ports of the named container.
+func containerPorts(containerName string) ([]publishedPort, error) {
+	published, err := publishedPorts()
+	if err != nil {
+		return nil, err
+	}
+	var result []publishedPort
+	for _, p := range published {
+		if p.container == containerName {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// unpublish removes the rule publishing the port.
+func (p publishedPort) unpublish() error {
+	args := append([]string{"-t", "nat", "-D", publishChain}, p.ruleSpec()...)
+	_, err := runIptables(args...)
+	return err
+}
+
+// unpublishContainerPorts removes the rules publishing the ports of
+// the named container, so that the ports of the host are freed once it
+// is destroyed.
+func unpublishContainerPorts(containerName string) error {
+	published, err := containerPorts(containerName)
+	if err != nil {
+		return err
+	}
+	for _, p := range published {
+		if err := p.unpublish(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OpenPorts implements instance.Instance.OpenPorts, publishing the
+// ports of the container on the same ports of its host. A port already
+// published for another container cannot be published again.
+func (docker *dockerInstance) OpenPorts(machineId string, ports []instance.Port) error {
+	addresses, err := docker.Addresses()
+	if err != nil {
+		return err
+	}
+	if len(addresses) == 0 {
+		return fmt.Errorf("cannot publish ports of container %q: container has no address", docker.id)
+	}
+	published, err := publishedPorts()
+	if err != nil {
+		return err
+	}
+	for _, port := range ports {
+		destination := fmt.Sprintf("%s:%d", addresses[0].Value, port.Number)
+		found := false
+		for _, p := range published {
+			if p.port != port {
+				continue
+			}
+			if p.container != docker.id {
+				return fmt.Errorf("cannot publish port %v of container %q: port published for container %q", port, docker.id, p.container)
+			}
+			if p.destination == destination {
+				found = true
+				continue
+			}
+			// The container got a new address since the port was
+			// published, as when it is restarted or restored.
+			if err := p.unpublish(); err != nil {
+				return err
+			}
+		}
+		if found {
+			continue
+		}
+		p := publishedPort{
+			container:   docker.id,
+			port:        port,
+			destination: destination,
+		}
+		args := append([]string{"-t", "nat", "-A", publishChain}, p.ruleSpec()...)
+		if _, err := runIptables(args...); err != nil {
+			return err
+		}
+		logger.Infof("published port %v of container %q on %s", port, docker.id, p.destination)
+	}
+	return nil
+}
+
+// ClosePorts implements instance.Instance.ClosePorts, removing the
+// publication of the ports of the container.
+func (docker *dockerInstance) ClosePorts(machineId string, ports []instance.Port) error {
+	published, err := containerPorts(docker.id)
+	if err != nil {
+		return err
+	}
+	for _, port := range ports {
+		for _, p := range published {
+			if p.port != port {
+				continue
+			}
+			if err := p.unpublish(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Ports implements instance.Instance.Ports, returning the ports of the
+// container published on its host.
+func (docker *dockerInstance) Ports(machineId string) ([]instance.Port, error) {
+	published, err := containerPorts(docker.id)
+	if err != nil {
+		return nil, err
+	}
+	var ports []instance.Port
+	for _, p := range published {
+		ports = append(ports, p.port)
+	}
+	state.SortPorts(ports)
+	return ports, nil
+}
+
+// Instances returns the instances of the docker containers on this
+// machine with the given ids, so that their ports can be published
+// by a firewaller running on the host.
+func Instances(ids []instance.Id) ([]instance.Instance, error) {
+	insts := make([]instance.Instance, len(ids))
+	for i, id := range ids {
+		insts[i] = &dockerInstance{string(id)}
+	}
+	return insts, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package docker_test
+
+import (
+	gc "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/container/docker"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/testing"
+)
+
+type PortsSuite struct {
+	testing.LoggingSuite
+	docker.TestSuite
+	manager docker.ContainerManager
+}
+
+var _ = gc.Suite(&PortsSuite{})
+
+func (s *PortsSuite) SetUpTest(c *gc.C) {
+	s.LoggingSuite.SetUpTest(c)
+	s.TestSuite.SetUpTest(c)
+	s.AddTools(c, fakeTools)
+	s.manager = docker.NewContainerManager(docker.ManagerConfig{})
+}
+
+func (s *PortsSuite) TearDownTest(c *gc.C) {
+	s.TestSuite.TearDownTest(c)
+	s.LoggingSuite.TearDownTest(c)
+}
+
+var (
+	httpPort  = instance.Port{"tcp", 80}
+	httpsPort = instance.Port{"tcp", 443}
+	dnsPort   = instance.Port{"udp", 53}
+)
+
+func (s *PortsSuite) TestOpenPorts(c *gc.C) {
+	inst := StartContainer(c, s.manager, "1/docker/0")
+	ports, err := inst.Ports("1/docker/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.HasLen, 0)
+
+	err = inst.OpenPorts("1/docker/0", []instance.Port{httpsPort, httpPort, dnsPort})
+	c.Assert(err, gc.IsNil)
+	ports, err = inst.Ports("1/docker/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.DeepEquals, []instance.Port{httpPort, httpsPort, dnsPort})
+	c.Assert(s.Iptables.Rules("PREROUTING")[0], gc.Equals,
+		"-p tcp -m addrtype --dst-type LOCAL --dport 443 -m comment --comment juju:machine-1-docker-0 -j DNAT --to-destination 172.17.0.2:443")
+
+	// Opening a published port again does nothing.
+	err = inst.OpenPorts("1/docker/0", []instance.Port{httpPort})
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.Iptables.Rules("PREROUTING"), gc.HasLen, 3)
+}
+
+func (s *PortsSuite) TestClosePorts(c *gc.C) {
+	inst := StartContainer(c, s.manager, "1/docker/0")
+	err := inst.OpenPorts("1/docker/0", []instance.Port{httpPort, httpsPort})
+	c.Assert(err, gc.IsNil)
+
+	err = inst.ClosePorts("1/docker/0", []instance.Port{httpPort, dnsPort})
+	c.Assert(err, gc.IsNil)
+	ports, err := inst.Ports("1/docker/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.DeepEquals, []instance.Port{httpsPort})
+}
+
+func (s *PortsSuite) TestOpenPortsConflict(c *gc.C) {
+	inst0 := StartContainer(c, s.manager, "1/docker/0")
+	inst1 := StartContainer(c, s.manager, "1/docker/1")
+	err := inst0.OpenPorts("1/docker/0", []instance.Port{httpPort})
+	c.Assert(err, gc.IsNil)
+
+	err = inst1.OpenPorts("1/docker/1", []instance.Port{httpsPort, httpPort})
+	c.Assert(err, gc.ErrorMatches, `cannot publish port 80/tcp of container "machine-1-docker-1": port published for container "machine-1-docker-0"`)
+	ports, err := inst1.Ports("1/docker/1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.DeepEquals, []instance.Port{httpsPort})
+
+	// Once closed, the port can be published for the other container.
+	err = inst0.ClosePorts("1/docker/0", []instance.Port{httpPort})
+	c.Assert(err, gc.IsNil)
+	err = inst1.OpenPorts("1/docker/1", []instance.Port{httpPort})
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *PortsSuite) TestOpenPortsNewAddress(c *gc.C) {
+	inst := StartContainer(c, s.manager, "1/docker/0")
+	err := inst.OpenPorts("1/docker/0", []instance.Port{httpPort})
+	c.Assert(err, gc.IsNil)
+	rules := s.Iptables.Rules("PREROUTING")
+	c.Assert(rules, gc.HasLen, 1)
+	c.Assert(rules[0], gc.Matches, ".* --to-destination 172.17.0.2:80")
+
+	// Once restarted, the container has a new address, to which the
+	// port is forwarded when opened again.
+	container := s.Factory.New(string(inst.Id()))
+	c.Assert(container.Stop(), gc.IsNil)
+	c.Assert(container.Start("", ""), gc.IsNil)
+	err = inst.OpenPorts("1/docker/0", []instance.Port{httpPort})
+	c.Assert(err, gc.IsNil)
+	rules = s.Iptables.Rules("PREROUTING")
+	c.Assert(rules, gc.HasLen, 1)
+	c.Assert(rules[0], gc.Matches, ".* --to-destination 172.17.0.3:80")
+}
+
+func (s *PortsSuite) TestStopContainerUnpublishesPorts(c *gc.C) {
+	inst0 := StartContainer(c, s.manager, "1/docker/0")
+	inst1 := StartContainer(c, s.manager, "1/docker/1")
+	err := inst0.OpenPorts("1/docker/0", []instance.Port{httpPort, httpsPort})
+	c.Assert(err, gc.IsNil)
+
+	err = s.manager.StopContainer(inst0)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.Iptables.Rules("PREROUTING"), gc.HasLen, 0)
+	err = inst1.OpenPorts("1/docker/1", []instance.Port{httpPort})
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *PortsSuite) TestIptablesListing(c *gc.C) {
+	// Rules are read back as iptables prints them, with other rules of
+	// the chain ignored.
+	inst, err := docker.Instances([]instance.Id{"machine-1-docker-0"})
+	c.Assert(err, gc.IsNil)
+	s.Iptables.Run("-t", "nat", "-A", "PREROUTING", "-m", "addrtype", "--dst-type", "LOCAL", "-j", "DOCKER")
+	s.Iptables.Run("-t", "nat", "-A", "PREROUTING",
+		"-p", "tcp", "-m", "addrtype", "--dst-type", "LOCAL", "-m", "tcp", "--dport", "8080",
+		"-m", "comment", "--comment", `"juju:machine-1-docker-0"`,
+		"-j", "DNAT", "--to-destination", "172.17.0.5:8080")
+	ports, err := inst[0].Ports("1/docker/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.DeepEquals, []instance.Port{{"tcp", 8080}})
+}
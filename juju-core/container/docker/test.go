@@ -0,0 +1,131 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Functions defined in this file should *ONLY* be used for testing.  These
+// functions are exported for testing purposes only, and shouldn't be called
+// from code that isn't in a test file.
+
+package docker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	gc "launchpad.net/gocheck"
+	"launchpad.net/gocontainer"
+
+	"launchpad.net/juju-core/agent/tools"
+	"launchpad.net/juju-core/container/docker/mock"
+)
+
+// SetContainerDir allows tests in other packages to override the
+// containerDir.
+func SetContainerDir(dir string) (old string) {
+	old, containerDir = containerDir, dir
+	return
+}
+
+// SetRemovedContainerDir allows tests in other packages to override the
+// removedContainerDir.
+func SetRemovedContainerDir(dir string) (old string) {
+	old, removedContainerDir = removedContainerDir, dir
+	return
+}
+
+// SetDockerFactory allows tests in other packages to override the
+// dockerObjectFactory.
+func SetDockerFactory(factory gocontainer.ContainerFactory) (old gocontainer.ContainerFactory) {
+	logger.Infof("dockerObjectFactory replaced with %v", factory)
+	old, dockerObjectFactory = dockerObjectFactory, factory
+	return
+}
+
+// SetToolsCacheDir allows tests in other packages to override the
+// toolsCacheDir.
+func SetToolsCacheDir(dir string) (old string) {
+	old, toolsCacheDir = toolsCacheDir, dir
+	return
+}
+
+// SetImageClient allows tests in other packages to override the
+// imageClient.
+func SetImageClient(client ImageClient) (old ImageClient) {
+	old, imageClient = imageClient, client
+	return
+}
+
+// SetDaemonClient allows tests in other packages to override the
+// daemonClient.
+func SetDaemonClient(client DaemonClient) (old DaemonClient) {
+	old, daemonClient = daemonClient, client
+	return
+}
+
+// SetIptables allows tests in other packages to override how iptables
+// is run.
+func SetIptables(run func(args ...string) (string, error)) (old func(args ...string) (string, error)) {
+	old, runIptables = runIptables, run
+	return
+}
+
+// TestSuite replaces the docker factory, daemon and image clients and
+// iptables that the broker and instances use with mock implementations.
+type TestSuite struct {
+	Factory         mock.ContainerFactory
+	oldFactory      gocontainer.ContainerFactory
+	Images          *mock.ImageClient
+	oldImages       ImageClient
+	Daemon          *mock.DaemonClient
+	oldDaemon       DaemonClient
+	Iptables        *mock.Iptables
+	oldIptables     func(args ...string) (string, error)
+	ContainerDir    string
+	RemovedDir      string
+	ToolsCacheDir   string
+	oldContainerDir string
+	oldRemovedDir   string
+	oldToolsDir     string
+}
+
+func (s *TestSuite) SetUpSuite(c *gc.C) {}
+
+func (s *TestSuite) TearDownSuite(c *gc.C) {}
+
+func (s *TestSuite) SetUpTest(c *gc.C) {
+	s.ContainerDir = c.MkDir()
+	s.oldContainerDir = SetContainerDir(s.ContainerDir)
+	s.RemovedDir = c.MkDir()
+	s.oldRemovedDir = SetRemovedContainerDir(s.RemovedDir)
+	s.ToolsCacheDir = c.MkDir()
+	s.oldToolsDir = SetToolsCacheDir(s.ToolsCacheDir)
+	s.Factory = mock.MockFactory()
+	s.oldFactory = SetDockerFactory(s.Factory)
+	s.Images = mock.MockImageClient()
+	s.oldImages = SetImageClient(s.Images)
+	s.Daemon = mock.MockDaemonClient()
+	s.oldDaemon = SetDaemonClient(s.Daemon)
+	s.Iptables = mock.MockIptables()
+	s.oldIptables = SetIptables(s.Iptables.Run)
+}
+
+func (s *TestSuite) TearDownTest(c *gc.C) {
+	SetContainerDir(s.oldContainerDir)
+	SetRemovedContainerDir(s.oldRemovedDir)
+	SetDockerFactory(s.oldFactory)
+	SetImageClient(s.oldImages)
+	SetToolsCacheDir(s.oldToolsDir)
+	SetIptables(s.oldIptables)
+	SetDaemonClient(s.oldDaemon)
+}
+
+// AddTools puts fake tools in the tools cache, so that base images can be
+// built for them without a download.
+func (s *TestSuite) AddTools(c *gc.C, agentTools *tools.Tools) {
+	dir := tools.SharedToolsDir(s.ToolsCacheDir, agentTools.Version)
+	c.Assert(os.MkdirAll(dir, 0755), gc.IsNil)
+	err := ioutil.WriteFile(filepath.Join(dir, "jujud"), []byte("fake jujud"), 0755)
+	c.Assert(err, gc.IsNil)
+	err = ioutil.WriteFile(filepath.Join(dir, "downloaded-url.txt"), []byte(agentTools.URL), 0644)
+	c.Assert(err, gc.IsNil)
+}
@@ -11,7 +11,9 @@ import (
 	"regexp"
 	"strings"
 
-	"launchpad.net/golxc"
+	"launchpad.net/gocontainer"
+	// Register the lxc container backend.
+	_ "launchpad.net/golxc"
 	"launchpad.net/loggo"
 
 	"launchpad.net/juju-core/agent/tools"
@@ -34,7 +36,7 @@ var (
 	removedContainerDir = "/var/lib/juju/removed-containers"
 	lxcContainerDir     = "/var/lib/lxc"
 	lxcRestartDir       = "/etc/lxc/auto"
-	lxcObjectFactory    = golxc.Factory()
+	lxcObjectFactory    = defaultFactory()
 	aptHTTPProxyRE      = regexp.MustCompile(`(?i)^Acquire::HTTP::Proxy\s+"([^"]+)";$`)
 )
 
@@ -45,8 +47,20 @@ const (
 	physicalNetwork = "physical"
 	// DefaultLxcBridge is the package created container bridge
 	DefaultLxcBridge = "lxcbr0"
+	// DefaultBackend is the container backend used when the manager
+	// config does not name one.
+	DefaultBackend = "lxc"
 )
 
+// defaultFactory returns the factory of the default container backend.
+func defaultFactory() gocontainer.ContainerFactory {
+	factory, err := gocontainer.Backend(DefaultBackend)
+	if err != nil {
+		panic(err)
+	}
+	return factory
+}
+
 // NetworkConfig defines how the container network will be configured.
 type NetworkConfig struct {
 	networkType string
@@ -75,6 +89,9 @@ func PhysicalNetworkConfig(device string) *NetworkConfig {
 type ManagerConfig struct {
 	Name   string
 	LogDir string
+	// Backend names the registered container backend used to create
+	// the containers. It defaults to DefaultBackend.
+	Backend string
 }
 
 // ContainerManager is responsible for starting containers, and stopping and
@@ -97,8 +114,9 @@ type ContainerManager interface {
 }
 
 type containerManager struct {
-	name   string
-	logdir string
+	name    string
+	logdir  string
+	backend string
 }
 
 // NewContainerManager returns a manager object that can start and stop lxc
@@ -109,7 +127,24 @@ func NewContainerManager(conf ManagerConfig) ContainerManager {
 	if conf.LogDir != "" {
 		logdir = conf.LogDir
 	}
-	return &containerManager{name: conf.Name, logdir: logdir}
+	return &containerManager{name: conf.Name, logdir: logdir, backend: conf.Backend}
+}
+
+// factory returns the container factory of the manager's backend. The
+// containers are created from lxc templates, so image based backends
+// are refused.
+func (manager *containerManager) factory() (gocontainer.ContainerFactory, error) {
+	if manager.backend == "" || manager.backend == DefaultBackend {
+		return lxcObjectFactory, nil
+	}
+	factory, err := gocontainer.Backend(manager.backend)
+	if err != nil {
+		return nil, err
+	}
+	if gocontainer.HasCapability(factory, gocontainer.ImageBased) {
+		return nil, fmt.Errorf("container backend %q is image based and cannot create lxc containers", manager.backend)
+	}
+	return factory, nil
 }
 
 func (manager *containerManager) StartContainer(
@@ -124,10 +159,15 @@ func (manager *containerManager) StartContainer(
 	if manager.name != "" {
 		name = fmt.Sprintf("%s-%s", manager.name, name)
 	}
-	// Note here that the lxcObjectFacotry only returns a valid container
+	factory, err := manager.factory()
+	if err != nil {
+		logger.Errorf("failed to get the container backend: %v", err)
+		return nil, err
+	}
+	// Note here that the factory only returns a valid container
 	// object, and doesn't actually construct the underlying lxc container on
 	// disk.
-	container := lxcObjectFactory.New(name)
+	container := factory.New(name)
 
 	// Create the cloud-init.
 	directory := jujuContainerDirectory(name)
@@ -174,16 +214,8 @@ func (manager *containerManager) StartContainer(
 	}
 	logger.Tracef("auto-restart link created")
 
-	// Start the lxc container with the appropriate settings for grabbing the
-	// console output and a log file.
-	consoleFile := filepath.Join(directory, "console.log")
-	container.SetLogFile(filepath.Join(directory, "container.log"), golxc.LogDebug)
 	logger.Tracef("start the container")
-	// We explicitly don't pass through the config file to the container.Start
-	// method as we have passed it through at container creation time.  This
-	// is necessary to get the appropriate rootfs reference without explicitly
-	// setting it ourselves.
-	if err = container.Start("", consoleFile); err != nil {
+	if err = StartExistingContainer(container); err != nil {
 		logger.Errorf("container failed to start: %v", err)
 		return nil, err
 	}
@@ -191,9 +223,28 @@ func (manager *containerManager) StartContainer(
 	return &lxcInstance{name}, nil
 }
 
+// StartExistingContainer starts the created container with the
+// appropriate settings for grabbing the console output and a log file in
+// the juju directory of the container. It is used both to start new
+// containers and to start again the ones stopped by juju.
+func StartExistingContainer(container gocontainer.Container) error {
+	directory := jujuContainerDirectory(container.Name())
+	consoleFile := filepath.Join(directory, "console.log")
+	container.SetLogFile(filepath.Join(directory, "container.log"), gocontainer.LogDebug)
+	// We explicitly don't pass through the config file to the container.Start
+	// method as we have passed it through at container creation time.  This
+	// is necessary to get the appropriate rootfs reference without explicitly
+	// setting it ourselves.
+	return container.Start("", consoleFile)
+}
+
 func (manager *containerManager) StopContainer(instance instance.Instance) error {
+	factory, err := manager.factory()
+	if err != nil {
+		return err
+	}
 	name := string(instance.Id())
-	container := lxcObjectFactory.New(name)
+	container := factory.New(name)
 	if err := container.Stop(); err != nil {
 		logger.Errorf("failed to stop lxc container: %v", err)
 		return err
@@ -223,7 +274,11 @@ func (manager *containerManager) StopContainer(instance instance.Instance) error
 }
 
 func (manager *containerManager) ListContainers() (result []instance.Instance, err error) {
-	containers, err := lxcObjectFactory.List()
+	factory, err := manager.factory()
+	if err != nil {
+		return nil, err
+	}
+	containers, err := factory.List()
 	if err != nil {
 		logger.Errorf("failed getting all instances: %v", err)
 		return
@@ -250,6 +305,12 @@ func jujuContainerDirectory(containerName string) string {
 	return filepath.Join(containerDir, containerName)
 }
 
+// RootFS returns the directory of the root filesystem of the named
+// container on its host.
+func RootFS(containerName string) string {
+	return filepath.Join(lxcContainerDir, containerName, "rootfs")
+}
+
 const internalLogDirTemplate = "%s/%s/rootfs/var/log/juju"
 
 func internalLogDir(containerName string) string {
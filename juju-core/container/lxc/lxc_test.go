@@ -11,11 +11,13 @@ import (
 	stdtesting "testing"
 
 	gc "launchpad.net/gocheck"
+	"launchpad.net/gocontainer"
 	"launchpad.net/goyaml"
 	"launchpad.net/loggo"
 
 	"launchpad.net/juju-core/agent/tools"
 	"launchpad.net/juju-core/container/lxc"
+	"launchpad.net/juju-core/container/lxc/mock"
 	"launchpad.net/juju-core/instance"
 	jujutesting "launchpad.net/juju-core/juju/testing"
 	"launchpad.net/juju-core/testing"
@@ -200,6 +202,55 @@ func (s *LxcSuite) TestListContainers(c *gc.C) {
 	testing.MatchInstances(c, result, bar1, bar2)
 }
 
+// imageBasedFactory is a mock backend declaring that it creates its
+// containers from images.
+type imageBasedFactory struct {
+	mock.ContainerFactory
+}
+
+func (*imageBasedFactory) Capabilities() []gocontainer.Capability {
+	return []gocontainer.Capability{gocontainer.ImageBased}
+}
+
+func init() {
+	gocontainer.RegisterBackend("lxc-test-image-based", &imageBasedFactory{mock.MockFactory()})
+}
+
+func (s *LxcSuite) startContainerError(c *gc.C, manager lxc.ContainerManager) error {
+	config := testing.EnvironConfig(c)
+	tools := &tools.Tools{
+		Version: version.MustParseBinary("2.3.4-foo-bar"),
+		URL:     "http://tools.testing.invalid/2.3.4-foo-bar.tgz",
+	}
+	network := lxc.BridgeNetworkConfig("nic42")
+	_, err := manager.StartContainer("1/lxc/0", "series", "fake-nonce", network, tools, config,
+		jujutesting.FakeStateInfo("1/lxc/0"), jujutesting.FakeAPIInfo("1/lxc/0"))
+	return err
+}
+
+func (s *LxcSuite) TestImageBasedBackendRefused(c *gc.C) {
+	manager := lxc.NewContainerManager(lxc.ManagerConfig{Backend: "lxc-test-image-based"})
+	err := s.startContainerError(c, manager)
+	c.Assert(err, gc.ErrorMatches, `container backend "lxc-test-image-based" is image based and cannot create lxc containers`)
+	_, err = manager.ListContainers()
+	c.Assert(err, gc.ErrorMatches, `container backend "lxc-test-image-based" is image based .*`)
+}
+
+func (s *LxcSuite) TestUnknownBackend(c *gc.C) {
+	manager := lxc.NewContainerManager(lxc.ManagerConfig{Backend: "no-such-backend"})
+	err := s.startContainerError(c, manager)
+	c.Assert(err, gc.ErrorMatches, `no registered container backend "no-such-backend"`)
+}
+
+func (s *LxcSuite) TestDefaultBackend(c *gc.C) {
+	// The default backend is the one replaced by the test suite.
+	manager := lxc.NewContainerManager(lxc.ManagerConfig{Backend: lxc.DefaultBackend})
+	instance := StartContainer(c, manager, "1/lxc/0")
+	result, err := manager.ListContainers()
+	c.Assert(err, gc.IsNil)
+	testing.MatchInstances(c, result, instance)
+}
+
 type NetworkSuite struct {
 	testing.LoggingSuite
 }
@@ -6,11 +6,11 @@ package mock
 import (
 	"fmt"
 
-	"launchpad.net/golxc"
+	"launchpad.net/gocontainer"
 )
 
-// This file provides a mock implementation of the golxc interfaces
-// ContainerFactory and Container.
+// This file provides a mock implementation of the gocontainer interfaces
+// ContainerFactory and Container, behaving like the lxc backend.
 
 type Action int
 
@@ -37,29 +37,29 @@ type Event struct {
 }
 
 type ContainerFactory interface {
-	golxc.ContainerFactory
+	gocontainer.ContainerFactory
 
 	AddListener(chan<- Event)
 	RemoveListener(chan<- Event)
 }
 
 type mockFactory struct {
-	instances map[string]golxc.Container
+	instances map[string]gocontainer.Container
 	listeners []chan<- Event
 }
 
 func MockFactory() ContainerFactory {
 	return &mockFactory{
-		instances: make(map[string]golxc.Container),
+		instances: make(map[string]gocontainer.Container),
 	}
 }
 
 type mockContainer struct {
 	factory  *mockFactory
 	name     string
-	state    golxc.State
+	state    gocontainer.State
 	logFile  string
-	logLevel golxc.LogLevel
+	logLevel gocontainer.LogLevel
 }
 
 // Name returns the name of the container.
@@ -69,45 +69,45 @@ func (mock *mockContainer) Name() string {
 
 // Create creates a new container based on the given template.
 func (mock *mockContainer) Create(configFile, template string, templateArgs ...string) error {
-	if mock.state != golxc.StateUnknown {
+	if mock.state != gocontainer.StateUnknown {
 		return fmt.Errorf("container is already created")
 	}
-	mock.state = golxc.StateStopped
+	mock.state = gocontainer.StateStopped
 	mock.factory.instances[mock.name] = mock
 	return nil
 }
 
 // Start runs the container as a daemon.
 func (mock *mockContainer) Start(configFile, consoleFile string) error {
-	if mock.state == golxc.StateUnknown {
+	if mock.state == gocontainer.StateUnknown {
 		return fmt.Errorf("container has not been created")
-	} else if mock.state == golxc.StateRunning {
+	} else if mock.state == gocontainer.StateRunning {
 		return fmt.Errorf("container is already running")
 	}
-	mock.state = golxc.StateRunning
+	mock.state = gocontainer.StateRunning
 	mock.factory.notify(Started, mock.name)
 	return nil
 }
 
 // Stop terminates the running container.
 func (mock *mockContainer) Stop() error {
-	if mock.state == golxc.StateUnknown {
+	if mock.state == gocontainer.StateUnknown {
 		return fmt.Errorf("container has not been created")
-	} else if mock.state == golxc.StateStopped {
+	} else if mock.state == gocontainer.StateStopped {
 		return fmt.Errorf("container is already stopped")
 	}
-	mock.state = golxc.StateStopped
+	mock.state = gocontainer.StateStopped
 	mock.factory.notify(Stopped, mock.name)
 	return nil
 }
 
 // Clone creates a copy of the container, giving the copy the specified name.
-func (mock *mockContainer) Clone(name string) (golxc.Container, error) {
+func (mock *mockContainer) Clone(name string) (gocontainer.Container, error) {
 	container := &mockContainer{
 		factory:  mock.factory,
 		name:     name,
-		state:    golxc.StateStopped,
-		logLevel: golxc.LogWarning,
+		state:    gocontainer.StateStopped,
+		logLevel: gocontainer.LogWarning,
 	}
 	mock.factory.instances[name] = container
 	return container, nil
@@ -125,25 +125,25 @@ func (mock *mockContainer) Unfreeze() error {
 
 // Destroy stops and removes the container.
 func (mock *mockContainer) Destroy() error {
-	if mock.state == golxc.StateUnknown {
+	if mock.state == gocontainer.StateUnknown {
 		return fmt.Errorf("container has not been created")
-	} else if mock.state == golxc.StateRunning {
+	} else if mock.state == gocontainer.StateRunning {
 		return fmt.Errorf("container is running")
 	}
-	mock.state = golxc.StateUnknown
+	mock.state = gocontainer.StateUnknown
 	delete(mock.factory.instances, mock.name)
 	return nil
 }
 
 // Wait waits for one of the specified container states.
-func (mock *mockContainer) Wait(states ...golxc.State) error {
+func (mock *mockContainer) Wait(states ...gocontainer.State) error {
 	return nil
 }
 
 // Info returns the status and the process id of the container.
-func (mock *mockContainer) Info() (golxc.State, int, error) {
+func (mock *mockContainer) Info() (gocontainer.State, int, error) {
 	pid := -1
-	if mock.state == golxc.StateRunning {
+	if mock.state == gocontainer.StateRunning {
 		pid = 42
 	}
 	return mock.state, pid, nil
@@ -151,12 +151,12 @@ func (mock *mockContainer) Info() (golxc.State, int, error) {
 
 // IsConstructed checks if the container image exists.
 func (mock *mockContainer) IsConstructed() bool {
-	return mock.state != golxc.StateUnknown
+	return mock.state != gocontainer.StateUnknown
 }
 
 // IsRunning checks if the state of the container is 'RUNNING'.
 func (mock *mockContainer) IsRunning() bool {
-	return mock.state == golxc.StateRunning
+	return mock.state == gocontainer.StateRunning
 }
 
 // String returns information about the container, like the name, state,
@@ -173,12 +173,12 @@ func (mock *mockContainer) LogFile() string {
 
 // LogLevel returns the current logging level (only used if the
 // LogFile is not "").
-func (mock *mockContainer) LogLevel() golxc.LogLevel {
+func (mock *mockContainer) LogLevel() gocontainer.LogLevel {
 	return mock.logLevel
 }
 
 // SetLogFile sets both the LogFile and LogLevel.
-func (mock *mockContainer) SetLogFile(filename string, level golxc.LogLevel) {
+func (mock *mockContainer) SetLogFile(filename string, level gocontainer.LogLevel) {
 	mock.logFile = filename
 	mock.logLevel = level
 }
@@ -187,7 +187,7 @@ func (mock *mockFactory) String() string {
 	return fmt.Sprintf("mock lxc factory")
 }
 
-func (mock *mockFactory) New(name string) golxc.Container {
+func (mock *mockFactory) New(name string) gocontainer.Container {
 	container, ok := mock.instances[name]
 	if ok {
 		return container
@@ -195,13 +195,17 @@ func (mock *mockFactory) New(name string) golxc.Container {
 	container = &mockContainer{
 		factory:  mock,
 		name:     name,
-		state:    golxc.StateUnknown,
-		logLevel: golxc.LogWarning,
+		state:    gocontainer.StateUnknown,
+		logLevel: gocontainer.LogWarning,
 	}
 	return container
 }
 
-func (mock *mockFactory) List() (result []golxc.Container, err error) {
+func (mock *mockFactory) Capabilities() []gocontainer.Capability {
+	return []gocontainer.Capability{gocontainer.Freezable, gocontainer.Cloneable}
+}
+
+func (mock *mockFactory) List() (result []gocontainer.Container, err error) {
 	for _, container := range mock.instances {
 		result = append(result, container)
 	}
@@ -9,7 +9,7 @@ package lxc
 
 import (
 	gc "launchpad.net/gocheck"
-	"launchpad.net/golxc"
+	"launchpad.net/gocontainer"
 
 	"launchpad.net/juju-core/container/lxc/mock"
 )
@@ -44,7 +44,7 @@ func SetRemovedContainerDir(dir string) (old string) {
 }
 
 // SetLxcFactory allows tests in other packages to override the lxcObjectFactory
-func SetLxcFactory(factory golxc.ContainerFactory) (old golxc.ContainerFactory) {
+func SetLxcFactory(factory gocontainer.ContainerFactory) (old gocontainer.ContainerFactory) {
 	logger.Infof("lxcObjectFactory replaced with %v", factory)
 	old, lxcObjectFactory = lxcObjectFactory, factory
 	return
@@ -54,7 +54,7 @@ func SetLxcFactory(factory golxc.ContainerFactory) (old golxc.ContainerFactory)
 // implementation.
 type TestSuite struct {
 	Factory            mock.ContainerFactory
-	oldFactory         golxc.ContainerFactory
+	oldFactory         gocontainer.ContainerFactory
 	ContainerDir       string
 	RemovedDir         string
 	LxcDir             string
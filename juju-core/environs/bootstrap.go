@@ -87,7 +87,7 @@ func verifyBootstrapInit(env Environ) error {
 func BootstrapUsers(st *state.State, cfg *config.Config, passwordHash string) error {
 	logger.Debugf("adding admin user")
 	// Set up initial authentication.
-	u, err := st.AddUser("admin", "")
+	u, err := st.AddUser(state.AdminUser, "", state.AdminAccess)
 	if err != nil {
 		return err
 	}
@@ -125,10 +125,16 @@ func Configure(cfg *MachineConfig, c *cloudinit.Config) (*cloudinit.Config, erro
 		return nil, err
 	}
 	c.AddSSHAuthorizedKeys(cfg.AuthorizedKeys)
-	c.AddPackage("git")
+	// Docker containers are created from an up to date image that
+	// already holds the packages needed, so nothing is downloaded
+	// when they boot.
+	isDocker := cfg.MachineContainerType == instance.DOCKER
+	if !isDocker {
+		c.AddPackage("git")
+	}
 	// Perfectly reasonable to install lxc on environment instances and kvm
-	// containers.
-	if cfg.MachineContainerType != instance.LXC {
+	// containers, but not inside lxc or docker containers.
+	if cfg.MachineContainerType != instance.LXC && cfg.MachineContainerType != instance.DOCKER {
 		c.AddPackage("lxc")
 	}
 
@@ -138,13 +144,16 @@ func Configure(cfg *MachineConfig, c *cloudinit.Config) (*cloudinit.Config, erro
 		"mkdir -p /var/log/juju")
 
 	// Make a directory for the tools to live in, then fetch the
-	// tools and unarchive them into it.
-	c.AddScripts(
-		"bin="+shquote(cfg.jujuTools()),
-		"mkdir -p $bin",
-		fmt.Sprintf("wget --no-verbose -O - %s | tar xz -C $bin", shquote(cfg.Tools.URL)),
-		fmt.Sprintf("echo -n %s > $bin/downloaded-url.txt", shquote(cfg.Tools.URL)),
-	)
+	// tools and unarchive them into it. Docker containers are created
+	// from an image that already holds the tools.
+	if !isDocker {
+		c.AddScripts(
+			"bin="+shquote(cfg.jujuTools()),
+			"mkdir -p $bin",
+			fmt.Sprintf("wget --no-verbose -O - %s | tar xz -C $bin", shquote(cfg.Tools.URL)),
+			fmt.Sprintf("echo -n %s > $bin/downloaded-url.txt", shquote(cfg.Tools.URL)),
+		)
+	}
 
 	// TODO (thumper): work out how to pass the logging config to the children
 	debugFlag := ""
@@ -202,8 +211,10 @@ func Configure(cfg *MachineConfig, c *cloudinit.Config) (*cloudinit.Config, erro
 	}
 
 	// general options
-	c.SetAptUpgrade(true)
-	c.SetAptUpdate(true)
+	if !isDocker {
+		c.SetAptUpgrade(true)
+		c.SetAptUpdate(true)
+	}
 	c.SetOutput(cloudinit.OutAll, "| tee -a /var/log/cloud-init-output.log", "")
 	return c, nil
 }
@@ -92,7 +92,7 @@ mkdir -p \$bin
 wget --no-verbose -O - 'http://foo\.com/tools/juju1\.2\.3-precise-amd64\.tgz' \| tar xz -C \$bin
 echo -n 'http://foo\.com/tools/juju1\.2\.3-precise-amd64\.tgz' > \$bin/downloaded-url\.txt
 install -m 600 /dev/null '/etc/rsyslog\.d/25-juju\.conf'
-echo '\\n\$ModLoad imfile\\n\\n\$InputFileStateFile /var/spool/rsyslog/juju-machine-0-state\\n\$InputFilePersistStateInterval 50\\n\$InputFilePollInterval 5\\n\$InputFileName /var/log/juju/machine-0\.log\\n\$InputFileTag local-juju-machine-0:\\n\$InputFileStateFile machine-0\\n\$InputRunFileMonitor\\n\\n\$ModLoad imudp\\n\$UDPServerRun 514\\n\\n# Messages received from remote rsyslog machines contain a leading space so we\\n# need to account for that.\\n\$template JujuLogFormatLocal,\"%HOSTNAME%:%msg:::drop-last-lf%\\n\"\\n\$template JujuLogFormat,\"%HOSTNAME%:%msg:2:2048:drop-last-lf%\\n\"\\n\\n:syslogtag, startswith, \"juju-\" /var/log/juju/all-machines\.log;JujuLogFormat\\n:syslogtag, startswith, \"local-juju-\" /var/log/juju/all-machines\.log;JujuLogFormatLocal\\n& ~\\n' > '/etc/rsyslog\.d/25-juju\.conf'
+echo '\\n\$ModLoad imfile\\n\\n\$InputFileStateFile /var/spool/rsyslog/juju-machine-0-state\\n\$InputFilePersistStateInterval 50\\n\$InputFilePollInterval 5\\n\$InputFileName /var/log/juju/machine-0\.log\\n\$InputFileTag local-juju-machine-0:\\n\$InputFileStateFile machine-0\\n\$InputRunFileMonitor\\n\\n\$ModLoad imudp\\n\$UDPServerRun 514\\n\\n# Each line is prefixed with the tag of the entity that logged it, taken\\n# from the syslog tag without its \"juju-\" or \"local-juju-\" prefix\.\\n\$template JujuLogFormatLocal,\"%syslogtag:12:\$%%msg:::sp-if-no-1st-sp%%msg:::drop-last-lf%\\n\"\\n\$template JujuLogFormat,\"%syslogtag:6:\$%%msg:::sp-if-no-1st-sp%%msg:::drop-last-lf%\\n\"\\n\\n:syslogtag, startswith, \"juju-\" /var/log/juju/all-machines\.log;JujuLogFormat\\n:syslogtag, startswith, \"local-juju-\" /var/log/juju/all-machines\.log;JujuLogFormatLocal\\n& ~\\n' > '/etc/rsyslog\.d/25-juju\.conf'
 restart rsyslog
 mkdir -p '/var/lib/juju/agents/machine-0'
 install -m 600 /dev/null '/var/lib/juju/agents/machine-0/agent\.conf'
@@ -151,7 +151,7 @@ mkdir -p \$bin
 wget --no-verbose -O - 'http://foo\.com/tools/juju1\.2\.3-raring-amd64\.tgz' \| tar xz -C \$bin
 echo -n 'http://foo\.com/tools/juju1\.2\.3-raring-amd64\.tgz' > \$bin/downloaded-url\.txt
 install -m 600 /dev/null '/etc/rsyslog\.d/25-juju\.conf'
-echo '\\n\$ModLoad imfile\\n\\n\$InputFileStateFile /var/spool/rsyslog/juju-machine-0-state\\n\$InputFilePersistStateInterval 50\\n\$InputFilePollInterval 5\\n\$InputFileName /var/log/juju/machine-0.log\\n\$InputFileTag local-juju-machine-0:\\n\$InputFileStateFile machine-0\\n\$InputRunFileMonitor\\n\\n\$ModLoad imudp\\n\$UDPServerRun 514\\n\\n# Messages received from remote rsyslog machines contain a leading space so we\\n# need to account for that.\\n\$template JujuLogFormatLocal,\"%HOSTNAME%:%msg:::drop-last-lf%\\n\"\\n\$template JujuLogFormat,\"%HOSTNAME%:%msg:2:2048:drop-last-lf%\\n\"\\n\\n:syslogtag, startswith, \"juju-\" /var/log/juju/all-machines.log;JujuLogFormat\\n:syslogtag, startswith, \"local-juju-\" /var/log/juju/all-machines.log;JujuLogFormatLocal\\n& ~\\n' > '/etc/rsyslog\.d/25-juju\.conf'
+echo '\\n\$ModLoad imfile\\n\\n\$InputFileStateFile /var/spool/rsyslog/juju-machine-0-state\\n\$InputFilePersistStateInterval 50\\n\$InputFilePollInterval 5\\n\$InputFileName /var/log/juju/machine-0.log\\n\$InputFileTag local-juju-machine-0:\\n\$InputFileStateFile machine-0\\n\$InputRunFileMonitor\\n\\n\$ModLoad imudp\\n\$UDPServerRun 514\\n\\n# Each line is prefixed with the tag of the entity that logged it, taken\\n# from the syslog tag without its \"juju-\" or \"local-juju-\" prefix\.\\n\$template JujuLogFormatLocal,\"%syslogtag:12:\$%%msg:::sp-if-no-1st-sp%%msg:::drop-last-lf%\\n\"\\n\$template JujuLogFormat,\"%syslogtag:6:\$%%msg:::sp-if-no-1st-sp%%msg:::drop-last-lf%\\n\"\\n\\n:syslogtag, startswith, \"juju-\" /var/log/juju/all-machines.log;JujuLogFormat\\n:syslogtag, startswith, \"local-juju-\" /var/log/juju/all-machines.log;JujuLogFormatLocal\\n& ~\\n' > '/etc/rsyslog\.d/25-juju\.conf'
 restart rsyslog
 mkdir -p '/var/lib/juju/agents/machine-0'
 install -m 600 /dev/null '/var/lib/juju/agents/machine-0/agent\.conf'
@@ -254,6 +254,44 @@ echo 'datadir: /var/lib/juju\\noldpassword: arble\\nmachinenonce: FAKE_NONCE\\ns
 ln -s 1\.2\.3-linux-amd64 '/var/lib/juju/tools/machine-2-lxc-1'
 cat >> /etc/init/jujud-machine-2-lxc-1\.conf << 'EOF'\\ndescription "juju machine-2-lxc-1 agent"\\nauthor "Juju Team <juju@lists\.ubuntu\.com>"\\nstart on runlevel \[2345\]\\nstop on runlevel \[!2345\]\\nrespawn\\nnormal exit 0\\nenv JUJU_PROVIDER_TYPE="dummy"\\n\\nlimit nofile 20000 20000\\n\\nexec /var/lib/juju/tools/machine-2-lxc-1/jujud machine --log-file '/var/log/juju/machine-2-lxc-1\.log' --data-dir '/var/lib/juju' --machine-id 2/lxc/1  --debug >> /var/log/juju/machine-2-lxc-1\.log 2>&1\\nEOF\\n
 start jujud-machine-2-lxc-1
+`,
+	}, {
+		// Docker containers are created with the tools preinstalled.
+		cfg: cloudinit.MachineConfig{
+			MachineId:            "2/docker/1",
+			MachineContainerType: "docker",
+			AuthorizedKeys:       "sshkey1",
+			MachineEnvironment:   map[string]string{osenv.JujuProviderType: "dummy"},
+			DataDir:              environs.DataDir,
+			StateServer:          false,
+			Tools:                newSimpleTools("1.2.3-linux-amd64"),
+			MachineNonce:         "FAKE_NONCE",
+			StateInfo: &state.Info{
+				Addrs:    []string{"state-addr.testing.invalid:12345"},
+				Tag:      "machine-2-docker-1",
+				Password: "arble",
+				CACert:   []byte("CA CERT\n" + testing.CACert),
+			},
+			APIInfo: &api.Info{
+				Addrs:    []string{"state-addr.testing.invalid:54321"},
+				Tag:      "machine-2-docker-1",
+				Password: "bletch",
+				CACert:   []byte("CA CERT\n" + testing.CACert),
+			},
+		},
+		expectScripts: `
+set -xe
+mkdir -p /var/lib/juju
+mkdir -p /var/log/juju
+install -m 600 /dev/null '/etc/rsyslog\.d/25-juju\.conf'
+echo '\\n\$ModLoad imfile\\n\\n\$InputFileStateFile /var/spool/rsyslog/juju-machine-2-docker-1-state\\n\$InputFilePersistStateInterval 50\\n\$InputFilePollInterval 5\\n\$InputFileName /var/log/juju/machine-2-docker-1.log\\n\$InputFileTag juju-machine-2-docker-1:\\n\$InputFileStateFile machine-2-docker-1\\n\$InputRunFileMonitor\\n\\n:syslogtag, startswith, \\"juju-\\" @state-addr.testing.invalid:514\\n& ~\\n' > '/etc/rsyslog\.d/25-juju\.conf'
+restart rsyslog
+mkdir -p '/var/lib/juju/agents/machine-2-docker-1'
+install -m 600 /dev/null '/var/lib/juju/agents/machine-2-docker-1/agent\.conf'
+echo 'datadir: /var/lib/juju\\noldpassword: arble\\nmachinenonce: FAKE_NONCE\\nstateinfo:\\n  addrs:\\n  - state-addr\.testing\.invalid:12345\\n  cacert:\\n[^']+  tag: machine-2-docker-1\\n  password: ""\\noldapipassword: ""\\napiinfo:\\n  addrs:\\n  - state-addr\.testing\.invalid:54321\\n  cacert:\\n[^']+  tag: machine-2-docker-1\\n  password: ""\\n' > '/var/lib/juju/agents/machine-2-docker-1/agent\.conf'
+ln -s 1\.2\.3-linux-amd64 '/var/lib/juju/tools/machine-2-docker-1'
+cat >> /etc/init/jujud-machine-2-docker-1\.conf << 'EOF'\\ndescription "juju machine-2-docker-1 agent"\\nauthor "Juju Team <juju@lists\.ubuntu\.com>"\\nstart on runlevel \[2345\]\\nstop on runlevel \[!2345\]\\nrespawn\\nnormal exit 0\\nenv JUJU_PROVIDER_TYPE="dummy"\\n\\nlimit nofile 20000 20000\\n\\nexec /var/lib/juju/tools/machine-2-docker-1/jujud machine --log-file '/var/log/juju/machine-2-docker-1\.log' --data-dir '/var/lib/juju' --machine-id 2/docker/1  --debug >> /var/log/juju/machine-2-docker-1\.log 2>&1\\nEOF\\n
+start jujud-machine-2-docker-1
 `,
 	},
 }
@@ -307,17 +345,26 @@ func (*cloudinitSuite) TestCloudInit(c *C) {
 		err = goyaml.Unmarshal(data, &x)
 		c.Assert(err, IsNil)
 
-		c.Check(x["apt_upgrade"], Equals, true)
-		c.Check(x["apt_update"], Equals, true)
+		// Docker containers are created from an up to date image
+		// holding the packages needed.
+		isDocker := test.cfg.MachineContainerType == "docker"
+		if isDocker {
+			c.Check(x["apt_upgrade"], IsNil)
+			c.Check(x["apt_update"], IsNil)
+		} else {
+			c.Check(x["apt_upgrade"], Equals, true)
+			c.Check(x["apt_update"], Equals, true)
+		}
 
 		scripts := getScripts(x)
 		scriptDiff(c, scripts, test.expectScripts)
 		if test.cfg.Config != nil {
 			checkEnvConfig(c, test.cfg.Config, x, scripts)
 		}
-		checkPackage(c, x, "git", true)
-		// The lxc package should only be there if the machine container type is not lxc.
-		hasLxc := test.cfg.MachineContainerType != "lxc"
+		checkPackage(c, x, "git", !isDocker)
+		// The lxc package should only be there if the machine is not a container.
+		containerType := test.cfg.MachineContainerType
+		hasLxc := containerType != "lxc" && containerType != "docker"
 		checkPackage(c, x, "lxc", hasLxc)
 		if test.cfg.StateServer {
 			checkPackage(c, x, "mongodb-server", true)
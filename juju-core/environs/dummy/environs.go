@@ -103,6 +103,17 @@ type OpClosePorts struct {
 	Ports      []instance.Port
 }
 
+type OpCreateVolume struct {
+	Env    string
+	Params environs.VolumeParams
+	Volume environs.Volume
+}
+
+type OpDestroyVolume struct {
+	Env      string
+	VolumeId string
+}
+
 type OpPutFile struct {
 	Env      string
 	FileName string
@@ -128,6 +139,8 @@ type environState struct {
 	mu            sync.Mutex
 	maxId         int // maximum instance id allocated so far.
 	insts         map[instance.Id]*dummyInstance
+	maxVolumeId   int // maximum volume id allocated so far.
+	volumes       map[string]environs.Volume
 	globalPorts   map[instance.Port]bool
 	firewallMode  config.FirewallMode
 	bootstrapped  bool
@@ -240,6 +253,7 @@ func newState(name string, ops chan<- Operation, fwmode config.FirewallMode) *en
 		name:         name,
 		ops:          ops,
 		insts:        make(map[instance.Id]*dummyInstance),
+		volumes:      make(map[string]environs.Volume),
 		globalPorts:  make(map[instance.Port]bool),
 		firewallMode: fwmode,
 	}
@@ -472,7 +486,7 @@ func (e *environ) Bootstrap(cons constraints.Value) error {
 		if err := st.SetAdminMongoPassword(utils.PasswordHash(password)); err != nil {
 			panic(err)
 		}
-		_, err = st.AddUser("admin", password)
+		_, err = st.AddUser(state.AdminUser, password, state.AdminAccess)
 		if err != nil {
 			panic(err)
 		}
@@ -710,6 +724,70 @@ func (e *environ) Ports() (ports []instance.Port, err error) {
 	return
 }
 
+// environ implements VolumeSource, keeping track of its volumes in
+// memory.
+var _ environs.VolumeSource = (*environ)(nil)
+
+func (e *environ) CreateVolume(params environs.VolumeParams) (environs.Volume, error) {
+	defer delay()
+	if err := e.checkBroken("CreateVolume"); err != nil {
+		return environs.Volume{}, err
+	}
+	e.state.mu.Lock()
+	defer e.state.mu.Unlock()
+	if _, ok := e.state.insts[params.Instance]; !ok {
+		return environs.Volume{}, fmt.Errorf("cannot attach volume to unknown instance %q", params.Instance)
+	}
+	v := environs.Volume{
+		Id:     fmt.Sprintf("vol-%d", e.state.maxVolumeId),
+		Size:   params.Size,
+		Device: fmt.Sprintf("/dev/dummy-%d", e.state.maxVolumeId),
+	}
+	e.state.volumes[v.Id] = v
+	e.state.maxVolumeId++
+	e.state.ops <- OpCreateVolume{
+		Env:    e.state.name,
+		Params: params,
+		Volume: v,
+	}
+	return v, nil
+}
+
+func (e *environ) AttachVolume(id string, inst instance.Id) (string, error) {
+	defer delay()
+	if err := e.checkBroken("AttachVolume"); err != nil {
+		return "", err
+	}
+	e.state.mu.Lock()
+	defer e.state.mu.Unlock()
+	if _, ok := e.state.insts[inst]; !ok {
+		return "", fmt.Errorf("cannot attach volume to unknown instance %q", inst)
+	}
+	v, ok := e.state.volumes[id]
+	if !ok {
+		return "", fmt.Errorf("volume %q not found", id)
+	}
+	return v.Device, nil
+}
+
+func (e *environ) DestroyVolume(id string) error {
+	defer delay()
+	if err := e.checkBroken("DestroyVolume"); err != nil {
+		return err
+	}
+	e.state.mu.Lock()
+	defer e.state.mu.Unlock()
+	if _, ok := e.state.volumes[id]; !ok {
+		return fmt.Errorf("volume %q not found", id)
+	}
+	delete(e.state.volumes, id)
+	e.state.ops <- OpDestroyVolume{
+		Env:      e.state.name,
+		VolumeId: id,
+	}
+	return nil
+}
+
 func (*environ) Provider() environs.EnvironProvider {
 	return &providerInstance
 }
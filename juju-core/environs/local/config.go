@@ -10,6 +10,7 @@ import (
 	"strconv"
 
 	"launchpad.net/juju-core/environs/config"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/schema"
 )
 
@@ -21,8 +22,9 @@ var (
 	configFields = schema.Fields{
 		"root-dir":            schema.String(),
 		"bootstrap-ip":        schema.String(),
-		"storage-port":        schema.Int(),
-		"shared-storage-port": schema.Int(),
+		"storage-port":        schema.ForceInt(),
+		"shared-storage-port": schema.ForceInt(),
+		"container":           schema.String(),
 	}
 	// The port defaults below are not entirely arbitrary.  Local user web
 	// frameworks often use 8000 or 8080, so I didn't want to use either of
@@ -33,6 +35,7 @@ var (
 		"bootstrap-ip":        schema.Omit,
 		"storage-port":        8040,
 		"shared-storage-port": 8041,
+		"container":           string(instance.LXC),
 	}
 )
 
@@ -83,6 +86,10 @@ func (c *environConfig) mongoDir() string {
 	return filepath.Join(c.rootDir(), "db")
 }
 
+func (c *environConfig) volumesDir() string {
+	return filepath.Join(c.rootDir(), "volumes")
+}
+
 func (c *environConfig) logDir() string {
 	return filepath.Join(c.rootDir(), "log")
 }
@@ -102,11 +109,11 @@ func (c *environConfig) bootstrapIPAddress() string {
 }
 
 func (c *environConfig) storagePort() int {
-	return int(c.attrs["storage-port"].(int64))
+	return c.attrs["storage-port"].(int)
 }
 
 func (c *environConfig) sharedStoragePort() int {
-	return int(c.attrs["shared-storage-port"].(int64))
+	return c.attrs["shared-storage-port"].(int)
 }
 
 func (c *environConfig) storageAddr() string {
@@ -117,6 +124,12 @@ func (c *environConfig) sharedStorageAddr() string {
 	return fmt.Sprintf("%s:%d", c.bootstrapIPAddress(), c.sharedStoragePort())
 }
 
+// container returns the type of the containers the machines of the
+// environment are hosted in.
+func (c *environConfig) container() instance.ContainerType {
+	return instance.ContainerType(c.attrs["container"].(string))
+}
+
 func (c *environConfig) configFile(filename string) string {
 	return filepath.Join(c.rootDir(), filename)
 }
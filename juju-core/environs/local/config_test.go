@@ -78,6 +78,53 @@ func (s *configSuite) TestValidateConfigWithRootDir(c *gc.C) {
 	c.Assert(unknownAttrs["root-dir"], gc.Equals, root)
 }
 
+func (s *configSuite) TestValidateConfigContainer(c *gc.C) {
+	for _, test := range []struct {
+		container string
+		err       string
+	}{{
+		container: "lxc",
+	}, {
+		container: "docker",
+	}, {
+		container: "kvm",
+		err:       `unsupported container "kvm", expected "lxc" or "docker"`,
+	}, {
+		container: "",
+		err:       `unsupported container "", expected "lxc" or "docker"`,
+	}} {
+		c.Logf("container %q", test.container)
+		values := minimalConfigValues()
+		values["container"] = test.container
+		testConfig, err := config.New(values)
+		c.Assert(err, gc.IsNil)
+		valid, err := local.Provider.Validate(testConfig, nil)
+		if test.err != "" {
+			c.Assert(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Assert(err, gc.IsNil)
+		c.Assert(valid.UnknownAttrs()["container"], gc.Equals, test.container)
+	}
+}
+
+func (s *configSuite) TestValidateConfigDefaultContainer(c *gc.C) {
+	valid, err := local.Provider.Validate(minimalConfig(c), nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(valid.UnknownAttrs()["container"], gc.Equals, "lxc")
+}
+
+func (s *configSuite) TestContainerCannotChange(c *gc.C) {
+	old, err := local.Provider.Validate(minimalConfig(c), nil)
+	c.Assert(err, gc.IsNil)
+	testConfig, err := old.Apply(map[string]interface{}{
+		"container": "docker",
+	})
+	c.Assert(err, gc.IsNil)
+	_, err = local.Provider.Validate(testConfig, old)
+	c.Assert(err, gc.ErrorMatches, `cannot change container from "lxc" to "docker"`)
+}
+
 func (s *configSuite) TestNamespace(c *gc.C) {
 	testConfig := minimalConfig(c)
 	c.Assert(local.ConfigNamespace(testConfig), gc.Equals, "tester-test")
@@ -16,6 +16,7 @@ import (
 	"launchpad.net/juju-core/agent"
 	"launchpad.net/juju-core/agent/tools"
 	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/container/docker"
 	"launchpad.net/juju-core/container/lxc"
 	"launchpad.net/juju-core/environs"
 	"launchpad.net/juju-core/environs/config"
@@ -35,6 +36,36 @@ import (
 // containers being created are able to communicate with it simply.
 const lxcBridgeName = "lxcbr0"
 
+// containerManager is the part of the lxc and docker container managers
+// used by the local provider.
+type containerManager interface {
+	StartContainer(
+		machineId, series, nonce string,
+		tools *tools.Tools,
+		environConfig *config.Config,
+		stateInfo *state.Info,
+		apiInfo *api.Info) (instance.Instance, error)
+	StopContainer(instance.Instance) error
+	ListContainers() ([]instance.Instance, error)
+}
+
+// lxcContainerManager starts the lxc containers on the default lxc
+// network.
+type lxcContainerManager struct {
+	lxc.ContainerManager
+}
+
+func (manager lxcContainerManager) StartContainer(
+	machineId, series, nonce string,
+	tools *tools.Tools,
+	environConfig *config.Config,
+	stateInfo *state.Info,
+	apiInfo *api.Info) (instance.Instance, error) {
+	network := lxc.DefaultNetworkConfig()
+	return manager.ContainerManager.StartContainer(
+		machineId, series, nonce, network, tools, environConfig, stateInfo, apiInfo)
+}
+
 // boostrapInstanceId is just the name we give to the bootstrap machine.
 // Using "localhost" because it is, and it makes sense.
 const boostrapInstanceId = "localhost"
@@ -53,7 +84,7 @@ type localEnviron struct {
 	name                  string
 	sharedStorageListener net.Listener
 	storageListener       net.Listener
-	containerManager      lxc.ContainerManager
+	containerManager      containerManager
 }
 
 // Name is specified in the Environ interface.
@@ -175,11 +206,7 @@ func (env *localEnviron) SetConfig(cfg *config.Config) error {
 	env.config = config
 	env.name = config.Name()
 
-	env.containerManager = lxc.NewContainerManager(
-		lxc.ManagerConfig{
-			Name:   env.config.namespace(),
-			LogDir: env.config.logDir(),
-		})
+	env.containerManager = newContainerManager(env.config)
 
 	// Here is the end of normal config setting.
 	if config.bootstrapped() {
@@ -203,7 +230,7 @@ func (env *localEnviron) bootstrapAddressAndStorage(cfg *config.Config) error {
 	if err != nil {
 		return err
 	}
-	logger.Debugf("found %q as address for %q", bridgeAddress, lxcBridgeName)
+	logger.Debugf("found %q as address for %q", bridgeAddress, env.bridgeName())
 	cfg, err = cfg.Apply(map[string]interface{}{
 		"bootstrap-ip": bridgeAddress,
 	})
@@ -269,9 +296,8 @@ func (env *localEnviron) StartInstance(
 	tools := possibleTools[0]
 	logger.Debugf("tools: %#v", tools)
 
-	network := lxc.DefaultNetworkConfig()
 	inst, err := env.containerManager.StartContainer(
-		machineId, series, machineNonce, network,
+		machineId, series, machineNonce,
 		tools, env.config.Config,
 		stateInfo, apiInfo)
 	if err != nil {
@@ -313,11 +339,11 @@ func (env *localEnviron) Instances(ids []instance.Id) ([]instance.Instance, erro
 func (env *localEnviron) AllInstances() (instances []instance.Instance, err error) {
 	instances = append(instances, &localInstance{boostrapInstanceId, env})
 	// Add in all the containers as well.
-	lxcInstances, err := env.containerManager.ListContainers()
+	containers, err := env.containerManager.ListContainers()
 	if err != nil {
 		return nil, err
 	}
-	for _, inst := range lxcInstances {
+	for _, inst := range containers {
 		instances = append(instances, &localInstance{inst.Id(), env})
 	}
 	return instances, nil
@@ -488,8 +514,36 @@ func (env *localEnviron) setupLocalMachineAgent(cons constraints.Value) error {
 	return nil
 }
 
+// newContainerManager returns the manager of the containers hosting the
+// machines of the environment, as chosen by the container config key.
+func newContainerManager(config *environConfig) containerManager {
+	if config.container() == instance.DOCKER {
+		return docker.NewContainerManager(
+			docker.ManagerConfig{
+				Name:   config.namespace(),
+				LogDir: config.logDir(),
+			})
+	}
+	return lxcContainerManager{
+		lxc.NewContainerManager(
+			lxc.ManagerConfig{
+				Name:   config.namespace(),
+				LogDir: config.logDir(),
+			}),
+	}
+}
+
+// bridgeName returns the name of the bridge the containers of the
+// environment are attached to.
+func (env *localEnviron) bridgeName() string {
+	if env.config.container() == instance.DOCKER {
+		return docker.DefaultDockerBridge
+	}
+	return lxcBridgeName
+}
+
 func (env *localEnviron) findBridgeAddress() (string, error) {
-	return getAddressForInterface(lxcBridgeName)
+	return getAddressForInterface(env.bridgeName())
 }
 
 func (env *localEnviron) writeBootstrapAgentConfFile(cert, key []byte) error {
@@ -7,9 +7,13 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	gc "launchpad.net/gocheck"
+	"launchpad.net/gocontainer"
 
+	"launchpad.net/juju-core/environs"
 	"launchpad.net/juju-core/environs/jujutest"
 	"launchpad.net/juju-core/environs/local"
 )
@@ -41,6 +45,116 @@ func (s *environSuite) TestNameAndStorage(c *gc.C) {
 	c.Assert(environ.PublicStorage(), gc.NotNil)
 }
 
+func (s *environSuite) TestLxcContainers(c *gc.C) {
+	testConfig := minimalConfig(c)
+	environ, err := local.Provider.Open(testConfig)
+	c.Assert(err, gc.IsNil)
+	c.Assert(local.BridgeName(environ), gc.Equals, "lxcbr0")
+
+	name := local.ConfigNamespace(testConfig) + "-machine-1"
+	container := s.Factory.New(name)
+	c.Assert(container.Create("", "ubuntu-cloud"), gc.IsNil)
+	c.Assert(container.Start("", ""), gc.IsNil)
+	s.assertInstanceIds(c, environ, "localhost", name)
+}
+
+func (s *environSuite) TestDockerContainers(c *gc.C) {
+	testConfig, err := minimalConfig(c).Apply(map[string]interface{}{
+		"container": "docker",
+	})
+	c.Assert(err, gc.IsNil)
+	environ, err := local.Provider.Open(testConfig)
+	c.Assert(err, gc.IsNil)
+	c.Assert(local.BridgeName(environ), gc.Equals, "docker0")
+
+	// Only the docker containers of the environment are instances.
+	name := local.ConfigNamespace(testConfig) + "-machine-1"
+	for _, factory := range []gocontainer.ContainerFactory{s.Factory, s.dockerSuite.Factory} {
+		container := factory.New(name)
+		c.Assert(container.Create("", "juju-base:precise"), gc.IsNil)
+		c.Assert(container.Start("", ""), gc.IsNil)
+	}
+	other := s.dockerSuite.Factory.New("someone-else-machine-2")
+	c.Assert(other.Create("", "juju-base:precise"), gc.IsNil)
+	c.Assert(other.Start("", ""), gc.IsNil)
+	s.assertInstanceIds(c, environ, "localhost", name)
+}
+
+func (s *environSuite) TestVolumes(c *gc.C) {
+	var commands []string
+	attached := false
+	defer local.SetRunCommand(func(name string, args ...string) (string, error) {
+		cmd := strings.Join(append([]string{name}, args...), " ")
+		commands = append(commands, cmd)
+		switch {
+		case strings.HasPrefix(cmd, "losetup --find"):
+			attached = true
+			return "/dev/loop3", nil
+		case strings.HasPrefix(cmd, "losetup -j") && attached:
+			return "/dev/loop3: [0801]:1234 (" + args[1] + ")", nil
+		}
+		return "", nil
+	})()
+	testConfig := minimalConfig(c)
+	environ, err := local.Provider.Open(testConfig)
+	c.Assert(err, gc.IsNil)
+	source, ok := environ.(environs.VolumeSource)
+	c.Assert(ok, gc.Equals, true)
+
+	volume, err := source.CreateVolume(environs.VolumeParams{
+		Name:     "data/0",
+		Size:     16,
+		Instance: "localhost",
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(volume, gc.Equals, environs.Volume{
+		Id:     "data-0",
+		Size:   16,
+		Device: "/dev/loop3",
+	})
+	file := filepath.Join(environ.Config().AllAttrs()["root-dir"].(string), "volumes", "data-0.img")
+	info, err := os.Stat(file)
+	c.Assert(err, gc.IsNil)
+	c.Assert(info.Size(), gc.Equals, int64(16*1024*1024))
+
+	// An attached volume is left alone, and one detached, as by a
+	// reboot, is attached again.
+	device, err := source.AttachVolume("data-0", "localhost")
+	c.Assert(err, gc.IsNil)
+	c.Assert(device, gc.Equals, "/dev/loop3")
+	attached = false
+	device, err = source.AttachVolume("data-0", "localhost")
+	c.Assert(err, gc.IsNil)
+	c.Assert(device, gc.Equals, "/dev/loop3")
+
+	err = source.DestroyVolume("data-0")
+	c.Assert(err, gc.IsNil)
+	_, err = os.Stat(file)
+	c.Assert(os.IsNotExist(err), gc.Equals, true)
+	c.Assert(commands, gc.DeepEquals, []string{
+		"losetup --find --show " + file,
+		"losetup -j " + file,
+		"losetup -j " + file,
+		"losetup --find --show " + file,
+		"losetup -j " + file,
+		"losetup -d /dev/loop3",
+	})
+	_, err = source.AttachVolume("data-0", "localhost")
+	c.Assert(err, gc.ErrorMatches, `cannot attach volume "data-0": .*`)
+}
+
+func (s *environSuite) assertInstanceIds(c *gc.C, environ environs.Environ, expected ...string) {
+	instances, err := environ.AllInstances()
+	c.Assert(err, gc.IsNil)
+	var ids []string
+	for _, inst := range instances {
+		ids = append(ids, string(inst.Id()))
+	}
+	sort.Strings(ids)
+	sort.Strings(expected)
+	c.Assert(ids, gc.DeepEquals, expected)
+}
+
 type localJujuTestSuite struct {
 	baseProviderSuite
 	jujutest.Tests
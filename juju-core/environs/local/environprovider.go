@@ -11,6 +11,7 @@ import (
 	"launchpad.net/juju-core/environs"
 	"launchpad.net/juju-core/environs/config"
 	constants "launchpad.net/juju-core/environs/provider"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/utils"
 	"launchpad.net/juju-core/version"
 )
@@ -58,6 +59,12 @@ func (provider environProvider) Validate(cfg, old *config.Config) (valid *config
 		return nil, err
 	}
 	localConfig := newEnvironConfig(cfg, validated)
+	switch container := localConfig.container(); container {
+	case instance.LXC, instance.DOCKER:
+	default:
+		return nil, fmt.Errorf("unsupported container %q, expected %q or %q",
+			container, instance.LXC, instance.DOCKER)
+	}
 	// Before potentially creating directories, make sure that the
 	// root directory has not changed.
 	if old != nil {
@@ -80,6 +87,11 @@ func (provider environProvider) Validate(cfg, old *config.Config) (valid *config
 				oldLocalConfig.sharedStoragePort(),
 				localConfig.sharedStoragePort())
 		}
+		if localConfig.container() != oldLocalConfig.container() {
+			return nil, fmt.Errorf("cannot change container from %q to %q",
+				oldLocalConfig.container(),
+				localConfig.container())
+		}
 	}
 	dir := utils.NormalizePath(localConfig.rootDir())
 	if dir == "." {
@@ -108,6 +120,9 @@ local:
   # Override the shared storage port if you have multiple local providers, or if the
   # default port is used by another program.
   # shared-storage-port: 8041
+  # Override the type of the containers hosting the machines, either lxc or
+  # docker. Docker containers start in seconds, from a juju base image.
+  # container: lxc
 
 `[1:]
 }
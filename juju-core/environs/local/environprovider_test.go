@@ -7,6 +7,7 @@ import (
 	gc "launchpad.net/gocheck"
 	"launchpad.net/loggo"
 
+	"launchpad.net/juju-core/container/docker"
 	"launchpad.net/juju-core/container/lxc"
 	"launchpad.net/juju-core/environs/local"
 	"launchpad.net/juju-core/testing"
@@ -15,13 +16,15 @@ import (
 type baseProviderSuite struct {
 	testing.LoggingSuite
 	lxc.TestSuite
-	home    *testing.FakeHome
-	restore func()
+	dockerSuite docker.TestSuite
+	home        *testing.FakeHome
+	restore     func()
 }
 
 func (s *baseProviderSuite) SetUpTest(c *gc.C) {
 	s.LoggingSuite.SetUpTest(c)
 	s.TestSuite.SetUpTest(c)
+	s.dockerSuite.SetUpTest(c)
 	s.home = testing.MakeFakeHomeNoEnvironments(c, "test")
 	loggo.GetLogger("juju.environs.local").SetLogLevel(loggo.TRACE)
 	s.restore = local.MockAddressForInterface()
@@ -30,6 +33,7 @@ func (s *baseProviderSuite) SetUpTest(c *gc.C) {
 func (s *baseProviderSuite) TearDownTest(c *gc.C) {
 	s.restore()
 	s.home.Restore()
+	s.dockerSuite.TearDownTest(c)
 	s.TestSuite.TearDownTest(c)
 	s.LoggingSuite.TearDownTest(c)
 }
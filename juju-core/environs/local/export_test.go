@@ -5,6 +5,7 @@ package local
 import (
 	gc "launchpad.net/gocheck"
 
+	"launchpad.net/juju-core/environs"
 	"launchpad.net/juju-core/environs/config"
 )
 
@@ -65,3 +66,18 @@ func MockAddressForInterface() func() {
 		getAddressForInterface = getAddressForInterfaceImpl
 	}
 }
+
+// SetRunCommand replaces the function used to run the commands managing
+// loop devices. The return value is the function to restore the old
+// value.
+func SetRunCommand(f func(name string, args ...string) (string, error)) func() {
+	old := runCommand
+	runCommand = f
+	return func() { runCommand = old }
+}
+
+// BridgeName returns the name of the bridge the containers of the
+// environment are attached to.
+func BridgeName(env environs.Environ) string {
+	return env.(*localEnviron).bridgeName()
+}
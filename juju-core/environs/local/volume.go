@@ -0,0 +1,131 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package local
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/instance"
+)
+
+// localEnviron implements VolumeSource with loop devices backed by
+// sparse files under the root directory of the environment. Loop
+// devices can only be set up by root on the host, so the volumes of
+// all the machines are created by the storage provisioner of the
+// bootstrap machine, which runs on the host, and their filesystems are
+// mounted into the root filesystems of the containers from there. Loop
+// devices do not survive a reboot of the host, so they are attached
+// again when the storage provisioner starts.
+var _ environs.VolumeSource = (*localEnviron)(nil)
+
+// runCommand runs the named command and returns its combined output.
+// It is a variable so that tests can avoid touching the host's loop
+// devices.
+var runCommand = func(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		if output != "" {
+			err = fmt.Errorf("%v (%s)", err, output)
+		}
+		return "", fmt.Errorf("%s failed: %v", name, err)
+	}
+	return output, nil
+}
+
+func (env *localEnviron) volumeFile(id string) string {
+	return filepath.Join(env.config.volumesDir(), id+".img")
+}
+
+// CreateVolume is specified in the VolumeSource interface.
+func (env *localEnviron) CreateVolume(params environs.VolumeParams) (environs.Volume, error) {
+	if err := os.MkdirAll(env.config.volumesDir(), 0755); err != nil {
+		return environs.Volume{}, err
+	}
+	id := strings.Replace(params.Name, "/", "-", -1)
+	file := env.volumeFile(id)
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return environs.Volume{}, fmt.Errorf("cannot create volume %q: %v", id, err)
+	}
+	err = f.Truncate(int64(params.Size) * 1024 * 1024)
+	f.Close()
+	if err != nil {
+		os.Remove(file)
+		return environs.Volume{}, fmt.Errorf("cannot create volume %q: %v", id, err)
+	}
+	device, err := runCommand("losetup", "--find", "--show", file)
+	if err != nil {
+		os.Remove(file)
+		return environs.Volume{}, fmt.Errorf("cannot attach volume %q: %v", id, err)
+	}
+	logger.Infof("created volume %q on %s", id, device)
+	return environs.Volume{
+		Id:     id,
+		Size:   params.Size,
+		Device: device,
+	}, nil
+}
+
+// loopDevices returns the loop devices backed by the file of the
+// volume with the given id.
+func (env *localEnviron) loopDevices(id string) ([]string, error) {
+	// losetup -j reports the loop devices backed by the file, one per
+	// line, as "/dev/loop0: [0801]:1234 (/path/to/file)".
+	out, err := runCommand("losetup", "-j", env.volumeFile(id))
+	if err != nil {
+		return nil, fmt.Errorf("cannot find volume %q: %v", id, err)
+	}
+	var devices []string
+	for _, line := range strings.Split(out, "\n") {
+		if i := strings.Index(line, ":"); i > 0 {
+			devices = append(devices, line[:i])
+		}
+	}
+	return devices, nil
+}
+
+// AttachVolume is specified in the VolumeSource interface.
+func (env *localEnviron) AttachVolume(id string, inst instance.Id) (string, error) {
+	file := env.volumeFile(id)
+	if _, err := os.Stat(file); err != nil {
+		return "", fmt.Errorf("cannot attach volume %q: %v", id, err)
+	}
+	devices, err := env.loopDevices(id)
+	if err != nil {
+		return "", err
+	}
+	if len(devices) > 0 {
+		return devices[0], nil
+	}
+	device, err := runCommand("losetup", "--find", "--show", file)
+	if err != nil {
+		return "", fmt.Errorf("cannot attach volume %q: %v", id, err)
+	}
+	logger.Infof("attached volume %q on %s", id, device)
+	return device, nil
+}
+
+// DestroyVolume is specified in the VolumeSource interface.
+func (env *localEnviron) DestroyVolume(id string) error {
+	file := env.volumeFile(id)
+	devices, err := env.loopDevices(id)
+	if err != nil {
+		return err
+	}
+	for _, device := range devices {
+		if _, err := runCommand("losetup", "-d", device); err != nil {
+			return fmt.Errorf("cannot detach volume %q: %v", id, err)
+		}
+	}
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot destroy volume %q: %v", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"launchpad.net/juju-core/instance"
+)
+
+// VolumeParams holds the parameters of a volume to be created.
+type VolumeParams struct {
+	// Name identifies the store the volume is created for, such as
+	// "data/0".
+	Name string
+	// Size is the minimum size of the volume, in MiB.
+	Size uint64
+	// Instance is the instance the volume is attached to.
+	Instance instance.Id
+}
+
+// Volume describes a volume created by a VolumeSource.
+type Volume struct {
+	// Id is the provider-specific id of the volume.
+	Id string
+	// Size is the actual size of the volume, in MiB.
+	Size uint64
+	// Device is the path of the block device through which the volume
+	// is reached on its instance.
+	Device string
+}
+
+// A VolumeSource creates and attaches block devices to instances.
+// Environs that support charm storage implement it.
+type VolumeSource interface {
+	// CreateVolume creates a volume and attaches it to the instance
+	// given in the parameters.
+	CreateVolume(params VolumeParams) (Volume, error)
+
+	// AttachVolume attaches the existing volume with the given id to
+	// the instance, if it is not attached already, as after a reboot,
+	// and returns the path of its block device.
+	AttachVolume(id string, inst instance.Id) (device string, err error)
+
+	// DestroyVolume detaches and destroys the volume with the given id.
+	DestroyVolume(id string) error
+}
@@ -76,3 +76,75 @@ func SelectPublicAddress(addresses []Address) string {
 	}
 	return mostpublic
 }
+
+// privateNetworks holds the IPv4 ranges reserved for private networks.
+var privateNetworks = []*net.IPNet{
+	mustParseCIDR("10.0.0.0/8"),
+	mustParseCIDR("172.16.0.0/12"),
+	mustParseCIDR("192.168.0.0/16"),
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return ipNet
+}
+
+// NewScopedAddress returns an address like NewAddress, with its network
+// scope derived from the range the IP address belongs to. The scope of
+// hostnames is unknown.
+func NewScopedAddress(value string) Address {
+	addr := NewAddress(value)
+	ip := net.ParseIP(value)
+	switch {
+	case ip == nil:
+	case ip.IsLoopback(), ip.IsLinkLocalUnicast():
+		addr.NetworkScope = NetworkMachineLocal
+	case ip.To4() == nil:
+		// Global IPv6 addresses are not told apart from unique local ones.
+		addr.NetworkScope = NetworkUnknown
+	default:
+		addr.NetworkScope = NetworkPublic
+		for _, ipNet := range privateNetworks {
+			if ipNet.Contains(ip) {
+				addr.NetworkScope = NetworkCloudLocal
+				break
+			}
+		}
+	}
+	return addr
+}
+
+// SelectInternalAddress picks one address from a slice that can be used
+// to reach the machine from other machines of the environment; if
+// machineLocal is true, addresses only reachable from the machine itself
+// are preferred. If there are no suitable addresses, the empty string is
+// returned.
+func SelectInternalAddress(addresses []Address, machineLocal bool) string {
+	var best string
+	bestRank := 0
+	for _, addr := range addresses {
+		if addr.Type == Ipv6Address {
+			continue
+		}
+		rank := 0
+		switch addr.NetworkScope {
+		case NetworkMachineLocal:
+			if machineLocal {
+				rank = 4
+			}
+		case NetworkCloudLocal:
+			rank = 3
+		case NetworkUnknown:
+			rank = 2
+		case NetworkPublic:
+			rank = 1
+		}
+		if rank > bestRank {
+			best, bestRank = addr.Value, rank
+		}
+	}
+	return best
+}
@@ -30,3 +30,46 @@ func (s *AddressSuite) TestNewAddressHostname(c *gc.C) {
 	c.Check(addr.Value, gc.Equals, "localhost")
 	c.Check(addr.Type, gc.Equals, instance.HostName)
 }
+
+func (s *AddressSuite) TestNewScopedAddress(c *gc.C) {
+	for i, t := range []struct {
+		value string
+		scope instance.NetworkScope
+	}{
+		{"127.0.0.1", instance.NetworkMachineLocal},
+		{"169.254.1.2", instance.NetworkMachineLocal},
+		{"10.0.3.1", instance.NetworkCloudLocal},
+		{"172.17.42.1", instance.NetworkCloudLocal},
+		{"192.168.1.10", instance.NetworkCloudLocal},
+		{"8.8.8.8", instance.NetworkPublic},
+		{"::1", instance.NetworkMachineLocal},
+		{"fe80::1", instance.NetworkMachineLocal},
+		{"2001:db8::1", instance.NetworkUnknown},
+		{"example.com", instance.NetworkUnknown},
+	} {
+		c.Logf("test %d: %s", i, t.value)
+		addr := instance.NewScopedAddress(t.value)
+		c.Check(addr.Value, gc.Equals, t.value)
+		c.Check(addr.NetworkScope, gc.Equals, t.scope)
+	}
+}
+
+func (s *AddressSuite) TestSelectInternalAddress(c *gc.C) {
+	addresses := []instance.Address{
+		instance.NewScopedAddress("8.8.8.8"),
+		instance.NewScopedAddress("2001:db8::1"),
+		instance.NewScopedAddress("127.0.0.1"),
+	}
+	c.Check(instance.SelectInternalAddress(addresses, false), gc.Equals, "8.8.8.8")
+	c.Check(instance.SelectInternalAddress(addresses, true), gc.Equals, "127.0.0.1")
+
+	addresses = append(addresses, instance.NewAddress("node-0.example.com"))
+	c.Check(instance.SelectInternalAddress(addresses, false), gc.Equals, "node-0.example.com")
+
+	addresses = append(addresses, instance.NewScopedAddress("10.0.0.2"))
+	c.Check(instance.SelectInternalAddress(addresses, false), gc.Equals, "10.0.0.2")
+	c.Check(instance.SelectInternalAddress(addresses, true), gc.Equals, "127.0.0.1")
+
+	c.Check(instance.SelectInternalAddress(nil, false), gc.Equals, "")
+	c.Check(instance.SelectInternalAddress(addresses[2:3], false), gc.Equals, "")
+}
@@ -10,14 +10,16 @@ import (
 type ContainerType string
 
 const (
-	NONE = ContainerType("none")
-	LXC  = ContainerType("lxc")
-	KVM  = ContainerType("kvm")
+	NONE   = ContainerType("none")
+	LXC    = ContainerType("lxc")
+	KVM    = ContainerType("kvm")
+	DOCKER = ContainerType("docker")
 )
 
 // SupportedContainerTypes is used to validate add-machine arguments.
 var SupportedContainerTypes []ContainerType = []ContainerType{
 	LXC,
+	DOCKER,
 }
 
 // ParseSupportedContainerTypeOrNone converts the specified string into a supported
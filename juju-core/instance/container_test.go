@@ -23,6 +23,11 @@ func (s *InstanceSuite) TestParseSupportedContainerType(c *C) {
 	ctype, err := instance.ParseSupportedContainerType("lxc")
 	c.Assert(err, IsNil)
 	c.Assert(ctype, Equals, instance.ContainerType("lxc"))
+	ctype, err = instance.ParseSupportedContainerType("docker")
+	c.Assert(err, IsNil)
+	c.Assert(ctype, Equals, instance.DOCKER)
+	ctype, err = instance.ParseSupportedContainerType("kvm")
+	c.Assert(err, ErrorMatches, `invalid container type "kvm"`)
 	ctype, err = instance.ParseSupportedContainerType("none")
 	c.Assert(err, Not(IsNil))
 }
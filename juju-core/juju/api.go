@@ -5,9 +5,13 @@ package juju
 
 import (
 	"fmt"
+	"os"
 
 	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/juju/osenv"
+	"launchpad.net/juju-core/names"
 	"launchpad.net/juju-core/state/api"
+	"launchpad.net/juju-core/state/api/params"
 )
 
 // APIConn holds a connection to a juju environment and its
@@ -19,29 +23,85 @@ type APIConn struct {
 
 // NewAPIConn returns a new Conn that uses the
 // given environment. The environment must have already
-// been bootstrapped.
+// been bootstrapped. The connection is made as the user
+// named by $JUJU_USER, with the password in $JUJU_PASSWORD,
+// or as the admin user with the admin-secret when unset.
 func NewAPIConn(environ environs.Environ, dialOpts api.DialOpts) (*APIConn, error) {
 	_, info, err := environ.StateInfo()
 	if err != nil {
 		return nil, err
 	}
-	info.Tag = "user-admin"
-	password := environ.Config().AdminSecret()
-	if password == "" {
-		return nil, fmt.Errorf("cannot connect without admin-secret")
+	if user := os.Getenv(osenv.JujuUser); user != "" {
+		info.Tag = names.UserTag(user)
+		info.Password = os.Getenv(osenv.JujuPassword)
+	} else {
+		info.Tag = "user-admin"
+		password := environ.Config().AdminSecret()
+		if password == "" {
+			return nil, fmt.Errorf("cannot connect without admin-secret")
+		}
+		info.Password = password
 	}
-	info.Password = password
 
 	st, err := api.Open(info, dialOpts)
 	// TODO(rog): handle errUnauthorized when the API handles passwords.
 	if err != nil {
 		return nil, err
 	}
-	// TODO(rog): implement updateSecrets (see Conn.updateSecrets)
-	return &APIConn{
+	conn := &APIConn{
 		Environ: environ,
 		State:   st,
-	}, nil
+	}
+	if err := conn.updateSecrets(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("unable to push secrets: %v", err)
+	}
+	return conn, nil
+}
+
+// updateSecrets writes secrets into the environment when there are none.
+// Only admin users can see and change the environment configuration, so
+// nothing is done for others.
+func (c *APIConn) updateSecrets() error {
+	secrets, err := c.Environ.Provider().SecretAttrs(c.Environ.Config())
+	if err != nil {
+		return err
+	}
+	client := c.State.Client()
+	attrs, err := client.EnvironmentConfig()
+	if params.ErrCode(err) == params.CodeUnauthorized {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for k := range secrets {
+		if _, exists := attrs[k]; exists {
+			// Environment already has secrets. Won't send again.
+			return nil
+		}
+	}
+	return client.SetEnvironmentConfig(secrets)
+}
+
+// NewAPIConnFromName returns an APIConn pointing at the environName
+// environment, or the default environment if not specified.
+func NewAPIConnFromName(environName string) (*APIConn, error) {
+	environ, err := environs.NewFromName(environName)
+	if err != nil {
+		return nil, err
+	}
+	return NewAPIConn(environ, api.DefaultDialOpts())
+}
+
+// NewAPIClientFromName returns an api.Client connected to the API
+// server of the environName environment, or the default environment if
+// not specified.
+func NewAPIClientFromName(environName string) (*api.Client, error) {
+	conn, err := NewAPIConnFromName(environName)
+	if err != nil {
+		return nil, err
+	}
+	return conn.State.Client(), nil
 }
 
 // Close terminates the connection to the environment and releases
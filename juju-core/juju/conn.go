@@ -192,6 +192,23 @@ func (conn *Conn) PutCharm(curl *charm.URL, repo charm.Repository, bumpRevision
 	return conn.addCharm(curl, ch)
 }
 
+// AddLocalCharm uploads the given charm, read by a client from its
+// local repository, to provider storage, and adds a state.Charm to the
+// state. The charm is not uploaded if a charm with the same URL already
+// exists in the state.
+func (conn *Conn) AddLocalCharm(curl *charm.URL, ch charm.Charm) (*state.Charm, error) {
+	if curl.Schema != "local" {
+		return nil, fmt.Errorf("expected charm URL with local schema, got %q", curl)
+	}
+	if curl.Revision < 0 {
+		return nil, fmt.Errorf("charm url must include revision")
+	}
+	if sch, err := conn.State.Charm(curl); err == nil {
+		return sch, nil
+	}
+	return conn.addCharm(curl, ch)
+}
+
 // DeployServiceParams contains the arguments required to deploy the referenced
 // charm.
 type DeployServiceParams struct {
@@ -309,6 +326,59 @@ func (conn *Conn) addCharm(curl *charm.URL, ch charm.Charm) (*state.Charm, error
 	return sch, nil
 }
 
+// PutResource uploads the file at path to the environment storage as
+// new content for the named resource of the service, and records it in
+// the state.
+func (conn *Conn) PutResource(svc *state.Service, name, path string) (*state.Resource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read resource %q: %v", name, err)
+	}
+	defer f.Close()
+	return conn.PutResourceContent(svc, name, f)
+}
+
+// PutResourceContent is like PutResource, but reads the content of the
+// resource from f.
+func (conn *Conn) PutResourceContent(svc *state.Service, name string, f io.ReadSeeker) (*state.Resource, error) {
+	u, digest, size, err := conn.StoreResourceContent(svc, name, f)
+	if err != nil {
+		return nil, err
+	}
+	return svc.SetResource(name, u, digest, size)
+}
+
+// StoreResourceContent writes the content of the named resource of the
+// service, read from f, to the environment storage. It returns the URL,
+// SHA256 digest and size of the content, which are recorded for the
+// resource with SetResource.
+func (conn *Conn) StoreResourceContent(svc *state.Service, name string, f io.ReadSeeker) (u *url.URL, digest string, size int64, err error) {
+	h := sha256.New()
+	size, err = io.Copy(h, f)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	digest = hex.EncodeToString(h.Sum(nil))
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil, "", 0, err
+	}
+	storage := conn.Environ.Storage()
+	storageName := fmt.Sprintf("resources/%s/%s-%s", svc.Name(), name, digest)
+	log.Infof("writing resource %q to storage [%d bytes]", name, size)
+	if err := storage.Put(storageName, f, size); err != nil {
+		return nil, "", 0, fmt.Errorf("cannot put resource %q: %v", name, err)
+	}
+	ustr, err := storage.URL(storageName)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("cannot get storage URL for resource %q: %v", name, err)
+	}
+	u, err = url.Parse(ustr)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("cannot parse storage URL: %v", err)
+	}
+	return u, digest, size, nil
+}
+
 // AddUnits starts n units of the given service and allocates machines
 // to them as necessary.
 func (conn *Conn) AddUnits(svc *state.Service, n int, machineIdSpec string) ([]*state.Unit, error) {
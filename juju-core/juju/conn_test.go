@@ -4,8 +4,11 @@
 package juju_test
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
 	stdtesting "testing"
@@ -383,6 +386,63 @@ func (s *ConnSuite) TestPutCharmUpload(c *C) {
 	c.Assert(sch.Revision(), Equals, rev+1)
 }
 
+func (s *ConnSuite) TestAddLocalCharm(c *C) {
+	bundle := coretesting.Charms.Bundle(c.MkDir(), "riak")
+	curl := charm.MustParseURL("local:series/riak-7")
+	sch, err := s.conn.AddLocalCharm(curl, bundle)
+	c.Assert(err, IsNil)
+	c.Assert(sch.URL(), DeepEquals, curl)
+	c.Assert(sch.Meta().Summary, Equals, "K/V storage engine")
+	sha256 := sch.BundleSha256()
+
+	// Adding the charm again leaves it unchanged.
+	sch, err = s.conn.AddLocalCharm(curl, coretesting.Charms.Bundle(c.MkDir(), "dummy"))
+	c.Assert(err, IsNil)
+	c.Assert(sch.BundleSha256(), Equals, sha256)
+	c.Assert(sch.Meta().Name, Equals, "riak")
+
+	_, err = s.conn.AddLocalCharm(charm.MustParseURL("cs:series/riak-7"), bundle)
+	c.Assert(err, ErrorMatches, `expected charm URL with local schema, got "cs:series/riak-7"`)
+	_, err = s.conn.AddLocalCharm(charm.MustParseURL("local:series/riak"), bundle)
+	c.Assert(err, ErrorMatches, "charm url must include revision")
+}
+
+func (s *ConnSuite) TestPutResource(c *C) {
+	curl := coretesting.Charms.ClonedURL(s.repo.Path, "series", "resources")
+	sch, err := s.conn.PutCharm(curl, s.repo, false)
+	c.Assert(err, IsNil)
+	svc, err := s.conn.State.AddService("resources", sch)
+	c.Assert(err, IsNil)
+
+	path := filepath.Join(c.MkDir(), "jdk.tar.gz")
+	err = ioutil.WriteFile(path, []byte("jdk content"), 0644)
+	c.Assert(err, IsNil)
+	res, err := s.conn.PutResource(svc, "jdk", path)
+	c.Assert(err, IsNil)
+	c.Assert(res.Revision(), Equals, 0)
+	c.Assert(res.Size(), Equals, int64(len("jdk content")))
+	hash := sha256.New()
+	hash.Write([]byte("jdk content"))
+	c.Assert(res.Sha256(), Equals, hex.EncodeToString(hash.Sum(nil)))
+
+	// The content is available at the recorded url.
+	resp, err := http.Get(res.URL().String())
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "jdk content")
+
+	res, err = s.conn.PutResource(svc, "jdk", path)
+	c.Assert(err, IsNil)
+	c.Assert(res.Revision(), Equals, 1)
+
+	_, err = s.conn.PutResource(svc, "jre", path)
+	c.Assert(err, ErrorMatches, `cannot set resource "jre" of service "resources": charm ".*" declares no such resource`)
+	_, err = s.conn.PutResource(svc, "vendor", filepath.Join(c.MkDir(), "missing"))
+	c.Assert(err, ErrorMatches, `cannot read resource "vendor": .*`)
+}
+
 func (s *ConnSuite) TestAddUnits(c *C) {
 	curl := coretesting.Charms.ClonedURL(s.repo.Path, "series", "riak")
 	sch, err := s.conn.PutCharm(curl, s.repo, false)
@@ -13,4 +13,6 @@ const (
 	JujuStorageAddr       = "JUJU_STORAGE_ADDR"
 	JujuSharedStorageDir  = "JUJU_SHARED_STORAGE_DIR"
 	JujuSharedStorageAddr = "JUJU_SHARED_STORAGE_ADDR"
+	JujuUser              = "JUJU_USER"
+	JujuPassword          = "JUJU_PASSWORD"
 )
@@ -28,10 +28,10 @@ $InputRunFileMonitor
 $ModLoad imudp
 $UDPServerRun 514
 
-# Messages received from remote rsyslog machines contain a leading space so we
-# need to account for that.
-$template JujuLogFormatLocal,"%HOSTNAME%:%msg:::drop-last-lf%\n"
-$template JujuLogFormat,"%HOSTNAME%:%msg:2:2048:drop-last-lf%\n"
+# Each line is prefixed with the tag of the entity that logged it, taken
+# from the syslog tag without its "juju-" or "local-juju-" prefix.
+$template JujuLogFormatLocal,"%syslogtag:12:$%%msg:::sp-if-no-1st-sp%%msg:::drop-last-lf%\n"
+$template JujuLogFormat,"%syslogtag:6:$%%msg:::sp-if-no-1st-sp%%msg:::drop-last-lf%\n"
 
 :syslogtag, startswith, "juju-" /var/log/juju/all-machines.log;JujuLogFormat
 :syslogtag, startswith, "local-juju-" /var/log/juju/all-machines.log;JujuLogFormatLocal
@@ -0,0 +1,11 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package syslog
+
+var TailerPollInterval = &tailerPollInterval
+
+var (
+	TailerMaxBatch   = &tailerMaxBatch
+	TailerMaxPending = &tailerMaxPending
+)
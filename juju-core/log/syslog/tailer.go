@@ -0,0 +1,406 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package syslog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"launchpad.net/loggo"
+	"launchpad.net/tomb"
+)
+
+// AllMachinesLogPath is the path of the file into which rsyslog on a
+// state server accumulates the log messages of all the agents of the
+// environment.
+const AllMachinesLogPath = "/var/log/juju/all-machines.log"
+
+// loggoTimeFormat is the format of the timestamps written by the
+// default loggo formatter.
+const loggoTimeFormat = "2006-01-02 15:04:05"
+
+var levels = []loggo.Level{
+	loggo.TRACE,
+	loggo.DEBUG,
+	loggo.INFO,
+	loggo.WARNING,
+	loggo.ERROR,
+	loggo.CRITICAL,
+}
+
+// ParseLevel returns the log level with the given name, ignoring case.
+func ParseLevel(name string) (loggo.Level, bool) {
+	name = strings.ToUpper(name)
+	for _, level := range levels {
+		if level.String() == name {
+			return level, true
+		}
+	}
+	return loggo.UNSPECIFIED, false
+}
+
+// Record holds a log message written by an agent, as found in the
+// accumulated log.
+type Record struct {
+	Entity   string
+	Time     time.Time
+	Level    loggo.Level
+	Module   string
+	Location string
+	Message  string
+}
+
+// String returns the record formatted as the line it was parsed from.
+func (r *Record) String() string {
+	return fmt.Sprintf("%s: %s %s %s %s %s",
+		r.Entity, r.Time.Format(loggoTimeFormat), r.Level, r.Module, r.Location, r.Message)
+}
+
+// ParseRecord parses a line of the accumulated log, as written by
+// rsyslog with the templates in this package, of the form:
+//
+//	machine-0: 2013-10-16 12:00:00 INFO juju.worker runner.go:42 message
+func ParseRecord(line string) (*Record, error) {
+	fail := func() (*Record, error) {
+		return nil, fmt.Errorf("cannot parse log line %q", line)
+	}
+	i := strings.Index(line, ":")
+	if i <= 0 {
+		return fail()
+	}
+	r := &Record{Entity: line[:i]}
+	fields := strings.SplitN(strings.TrimSpace(line[i+1:]), " ", 6)
+	if len(fields) < 5 {
+		return fail()
+	}
+	var err error
+	r.Time, err = time.Parse(loggoTimeFormat, fields[0]+" "+fields[1])
+	if err != nil {
+		return fail()
+	}
+	var ok bool
+	if r.Level, ok = ParseLevel(fields[2]); !ok {
+		return fail()
+	}
+	r.Module = fields[3]
+	r.Location = fields[4]
+	if len(fields) == 6 {
+		r.Message = fields[5]
+	}
+	return r, nil
+}
+
+// Filter selects the log records of interest.
+type Filter struct {
+	// IncludeEntity holds the tags of the entities whose records are
+	// selected; all entities are selected when empty. The tags may be
+	// patterns as understood by path.Match, such as "unit-mysql-*".
+	IncludeEntity []string
+
+	// ExcludeEntity holds the tags or patterns of entities whose
+	// records are never selected.
+	ExcludeEntity []string
+
+	// IncludeModule holds the modules whose records are selected,
+	// including those of their submodules; all modules are selected
+	// when empty.
+	IncludeModule []string
+
+	// ExcludeModule holds the modules whose records, and those of
+	// their submodules, are never selected.
+	ExcludeModule []string
+
+	// Level holds the minimum level of the selected records.
+	Level loggo.Level
+}
+
+// Match returns whether the record is selected by the filter.
+func (f *Filter) Match(r *Record) bool {
+	if r.Level < f.Level {
+		return false
+	}
+	if len(f.IncludeEntity) > 0 && !matchEntity(f.IncludeEntity, r.Entity) {
+		return false
+	}
+	if matchEntity(f.ExcludeEntity, r.Entity) {
+		return false
+	}
+	if len(f.IncludeModule) > 0 && !matchModule(f.IncludeModule, r.Module) {
+		return false
+	}
+	return !matchModule(f.ExcludeModule, r.Module)
+}
+
+func matchEntity(patterns []string, entity string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, entity); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchModule(modules []string, module string) bool {
+	for _, m := range modules {
+		if module == m || strings.HasPrefix(module, m+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// tailerPollInterval is how often a Tailer checks the log for new
+// lines.
+var tailerPollInterval = 500 * time.Millisecond
+
+// Bounds on the records held by a Tailer, so that neither a large log
+// nor a slow client makes it use unbounded memory.
+var (
+	// tailerMaxBatch is the maximum number of records sent at once.
+	tailerMaxBatch = 1000
+
+	// tailerMaxPending is the maximum number of records read ahead of
+	// the client; the log is not read further until some are sent.
+	tailerMaxPending = 10000
+)
+
+// Tailer follows the accumulated log and sends the records selected by
+// its filter as they are written.
+type Tailer struct {
+	tomb    tomb.Tomb
+	path    string
+	filter  Filter
+	backlog int
+	out     chan []*Record
+}
+
+// NewTailer returns a Tailer following the log at the given path. The
+// last backlog selected records already in the log are sent first; all
+// of them are when backlog is negative.
+func NewTailer(logPath string, filter Filter, backlog int) *Tailer {
+	t := &Tailer{
+		path:    logPath,
+		filter:  filter,
+		backlog: backlog,
+		out:     make(chan []*Record),
+	}
+	go func() {
+		defer t.tomb.Done()
+		defer close(t.out)
+		t.tomb.Kill(t.loop())
+	}()
+	return t
+}
+
+// Changes returns a channel that receives the selected records, in the
+// order they were written, in batches of at least one.
+func (t *Tailer) Changes() <-chan []*Record {
+	return t.out
+}
+
+// Stop stops the tailer and returns any error encountered while it was
+// running.
+func (t *Tailer) Stop() error {
+	t.tomb.Kill(nil)
+	return t.tomb.Wait()
+}
+
+// Err returns any error encountered while the tailer was running.
+func (t *Tailer) Err() error {
+	return t.tomb.Err()
+}
+
+func (t *Tailer) loop() error {
+	lf, err := openLogFile(t.path)
+	if err != nil {
+		return fmt.Errorf("cannot open log: %v", err)
+	}
+	defer func() {
+		lf.Close()
+	}()
+	var pending []*Record
+	collect := func(r *Record) bool {
+		if t.filter.Match(r) {
+			pending = append(pending, r)
+		}
+		return len(pending) < tailerMaxPending
+	}
+	var eof bool
+	if t.backlog >= 0 {
+		// Only the last records are kept while the log is read, however
+		// large it is.
+		size := t.backlog
+		if size > tailerMaxPending {
+			size = tailerMaxPending
+		}
+		backlog := &recordRing{buf: make([]*Record, size)}
+		eof, err = lf.scan(func(r *Record) bool {
+			if t.filter.Match(r) {
+				backlog.add(r)
+			}
+			return true
+		})
+		pending = backlog.records()
+	} else {
+		eof, err = lf.scan(collect)
+	}
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(tailerPollInterval)
+	defer ticker.Stop()
+	for {
+		var out chan []*Record
+		var batch []*Record
+		if len(pending) > 0 {
+			out = t.out
+			batch = pending
+			if len(batch) > tailerMaxBatch {
+				batch = batch[:tailerMaxBatch]
+			}
+		}
+		select {
+		case <-t.tomb.Dying():
+			return tomb.ErrDying
+		case <-ticker.C:
+			if len(pending) >= tailerMaxPending {
+				break
+			}
+			if eof, err = lf.scan(collect); err != nil {
+				return err
+			}
+			if !eof {
+				break
+			}
+			rotated, err := lf.rotated(t.path)
+			if err != nil {
+				return err
+			}
+			if !rotated {
+				break
+			}
+			// The old log has been read to its end; follow the new one
+			// from the top.
+			newlf, err := openLogFile(t.path)
+			if err != nil {
+				return fmt.Errorf("cannot open log: %v", err)
+			}
+			lf.Close()
+			lf = newlf
+			if eof, err = lf.scan(collect); err != nil {
+				return err
+			}
+		case out <- batch:
+			pending = pending[len(batch):]
+			if len(pending) == 0 {
+				pending = nil
+			}
+		}
+	}
+}
+
+// logFile reads the records appended to a log file.
+type logFile struct {
+	f       *os.File
+	r       *bufio.Reader
+	offset  int64
+	partial string
+}
+
+func openLogFile(path string) (*logFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &logFile{f: f, r: bufio.NewReader(f)}, nil
+}
+
+func (lf *logFile) Close() error {
+	return lf.f.Close()
+}
+
+// scan calls visit with the records in the lines appended to the log
+// since it was last scanned, until visit returns false. It returns
+// whether the end of the log was reached.
+func (lf *logFile) scan(visit func(*Record) bool) (eof bool, err error) {
+	info, err := lf.f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() < lf.offset {
+		// The log was truncated; start again from the top.
+		if _, err := lf.f.Seek(0, 0); err != nil {
+			return false, err
+		}
+		lf.r = bufio.NewReader(lf.f)
+		lf.offset, lf.partial = 0, ""
+	}
+	for {
+		line, err := lf.r.ReadString('\n')
+		lf.offset += int64(len(line))
+		if err == io.EOF {
+			// Keep the incomplete line until the rest is written.
+			lf.partial += line
+			return true, nil
+		} else if err != nil {
+			return false, err
+		}
+		line, lf.partial = lf.partial+strings.TrimRight(line, "\n"), ""
+		record, err := ParseRecord(line)
+		if err != nil {
+			// Lines not written by juju agents, such as the
+			// continuations of multi-line messages, are skipped.
+			continue
+		}
+		if !visit(record) {
+			return false, nil
+		}
+	}
+}
+
+// rotated returns whether the file at the given path is no longer the
+// one being read, as happens when the log is rotated. A missing file,
+// as while the log is being rotated, is not reported.
+func (lf *logFile) rotated(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	current, err := lf.f.Stat()
+	if err != nil {
+		return false, err
+	}
+	return !os.SameFile(info, current), nil
+}
+
+// recordRing holds the last records added to it, up to the size of its
+// buffer.
+type recordRing struct {
+	buf []*Record
+	n   int
+}
+
+func (r *recordRing) add(record *Record) {
+	if len(r.buf) == 0 {
+		return
+	}
+	r.buf[r.n%len(r.buf)] = record
+	r.n++
+}
+
+// records returns the records held, oldest first.
+func (r *recordRing) records() []*Record {
+	if r.n <= len(r.buf) {
+		return append([]*Record(nil), r.buf[:r.n]...)
+	}
+	i := r.n % len(r.buf)
+	return append(append([]*Record(nil), r.buf[i:]...), r.buf[:i]...)
+}
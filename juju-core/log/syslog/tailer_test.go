@@ -0,0 +1,253 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package syslog_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "launchpad.net/gocheck"
+	"launchpad.net/loggo"
+
+	"launchpad.net/juju-core/log/syslog"
+	coretesting "launchpad.net/juju-core/testing"
+)
+
+type TailerSuite struct {
+	logPath         string
+	oldPollInterval time.Duration
+	oldMaxBatch     int
+	oldMaxPending   int
+}
+
+var _ = Suite(&TailerSuite{})
+
+func (s *TailerSuite) SetUpTest(c *C) {
+	s.logPath = filepath.Join(c.MkDir(), "all-machines.log")
+	s.oldPollInterval = *syslog.TailerPollInterval
+	*syslog.TailerPollInterval = 10 * time.Millisecond
+	s.oldMaxBatch = *syslog.TailerMaxBatch
+	s.oldMaxPending = *syslog.TailerMaxPending
+}
+
+func (s *TailerSuite) TearDownTest(c *C) {
+	*syslog.TailerPollInterval = s.oldPollInterval
+	*syslog.TailerMaxBatch = s.oldMaxBatch
+	*syslog.TailerMaxPending = s.oldMaxPending
+}
+
+func (s *TailerSuite) appendLog(c *C, lines ...string) {
+	f, err := os.OpenFile(s.logPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	for _, line := range lines {
+		_, err := f.WriteString(line)
+		c.Assert(err, IsNil)
+	}
+}
+
+func assertRecords(c *C, t *syslog.Tailer, expect ...string) {
+	var got []string
+	timeout := time.After(coretesting.LongWait)
+	for len(got) < len(expect) {
+		select {
+		case records, ok := <-t.Changes():
+			c.Assert(ok, Equals, true)
+			for _, r := range records {
+				got = append(got, r.String())
+			}
+		case <-timeout:
+			c.Fatalf("timed out waiting for records; got %q", got)
+		}
+	}
+	c.Assert(got, DeepEquals, expect)
+}
+
+func assertNoRecords(c *C, t *syslog.Tailer) {
+	select {
+	case records := <-t.Changes():
+		c.Fatalf("unexpected records %v", records)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+const (
+	line0 = "machine-0: 2013-10-16 12:00:00 INFO juju.worker runner.go:42 starting\n"
+	line1 = "unit-mysql-0: 2013-10-16 12:00:01 DEBUG juju.worker.uniter uniter.go:12 hook started\n"
+	line2 = "machine-1: 2013-10-16 12:00:02 WARNING juju.provisioner provisioner.go:7 no tools\n"
+	line3 = "unit-mysql-1: 2013-10-16 12:00:03 ERROR juju.worker.uniter uniter.go:99 hook failed\n"
+)
+
+func (s *TailerSuite) TestParseRecord(c *C) {
+	r, err := syslog.ParseRecord("unit-mysql-0: 2013-10-16 12:00:01 DEBUG juju.worker.uniter uniter.go:12 hook started")
+	c.Assert(err, IsNil)
+	c.Assert(r, DeepEquals, &syslog.Record{
+		Entity:   "unit-mysql-0",
+		Time:     time.Date(2013, 10, 16, 12, 0, 1, 0, time.UTC),
+		Level:    loggo.DEBUG,
+		Module:   "juju.worker.uniter",
+		Location: "uniter.go:12",
+		Message:  "hook started",
+	})
+	r, err = syslog.ParseRecord("machine-0:2013-10-16 12:00:01 INFO juju.worker runner.go:1")
+	c.Assert(err, IsNil)
+	c.Assert(r.Entity, Equals, "machine-0")
+	c.Assert(r.Message, Equals, "")
+
+	for _, line := range []string{
+		"",
+		"no entity",
+		"machine-0: continuation of a message",
+		"machine-0: 2013-10-16 12:00:01 LOUD juju.worker runner.go:1 message",
+	} {
+		_, err := syslog.ParseRecord(line)
+		c.Check(err, ErrorMatches, "cannot parse log line .*")
+	}
+}
+
+var filterTests = []struct {
+	filter syslog.Filter
+	match  []bool
+}{{
+	filter: syslog.Filter{},
+	match:  []bool{true, true, true, true},
+}, {
+	filter: syslog.Filter{Level: loggo.WARNING},
+	match:  []bool{false, false, true, true},
+}, {
+	filter: syslog.Filter{IncludeEntity: []string{"unit-mysql-*", "machine-0"}},
+	match:  []bool{true, true, false, true},
+}, {
+	filter: syslog.Filter{ExcludeEntity: []string{"unit-mysql-1"}},
+	match:  []bool{true, true, true, false},
+}, {
+	filter: syslog.Filter{IncludeModule: []string{"juju.worker"}},
+	match:  []bool{true, true, false, true},
+}, {
+	filter: syslog.Filter{
+		IncludeModule: []string{"juju.worker"},
+		ExcludeModule: []string{"juju.worker.uniter"},
+	},
+	match: []bool{true, false, false, false},
+}, {
+	filter: syslog.Filter{IncludeModule: []string{"juju.work"}},
+	match:  []bool{false, false, false, false},
+}}
+
+func (s *TailerSuite) TestFilterMatch(c *C) {
+	var records []*syslog.Record
+	for _, line := range []string{line0, line1, line2, line3} {
+		r, err := syslog.ParseRecord(line[:len(line)-1])
+		c.Assert(err, IsNil)
+		records = append(records, r)
+	}
+	for i, t := range filterTests {
+		c.Logf("test %d: %#v", i, t.filter)
+		for j, r := range records {
+			c.Check(t.filter.Match(r), Equals, t.match[j], Commentf("record %d", j))
+		}
+	}
+}
+
+func (s *TailerSuite) TestTailer(c *C) {
+	s.appendLog(c, line0, "not from an agent\n", line1, line2)
+	t := syslog.NewTailer(s.logPath, syslog.Filter{}, 2)
+	defer func() { c.Assert(t.Stop(), IsNil) }()
+	assertRecords(c, t, line1[:len(line1)-1], line2[:len(line2)-1])
+	assertNoRecords(c, t)
+
+	// An incomplete line is held back until the rest is written.
+	s.appendLog(c, line3[:20])
+	assertNoRecords(c, t)
+	s.appendLog(c, line3[20:])
+	assertRecords(c, t, line3[:len(line3)-1])
+}
+
+func (s *TailerSuite) TestTailerFilter(c *C) {
+	s.appendLog(c, line0, line1, line2)
+	filter := syslog.Filter{IncludeEntity: []string{"unit-*"}}
+	t := syslog.NewTailer(s.logPath, filter, -1)
+	defer func() { c.Assert(t.Stop(), IsNil) }()
+	assertRecords(c, t, line1[:len(line1)-1])
+	s.appendLog(c, line0, line3)
+	assertRecords(c, t, line3[:len(line3)-1])
+}
+
+func (s *TailerSuite) TestTailerTruncatedLog(c *C) {
+	s.appendLog(c, line0)
+	t := syslog.NewTailer(s.logPath, syslog.Filter{}, 0)
+	defer func() { c.Assert(t.Stop(), IsNil) }()
+	assertNoRecords(c, t)
+	err := os.Truncate(s.logPath, 0)
+	c.Assert(err, IsNil)
+	// The new content must be shorter than the old for the truncation
+	// to be noticed.
+	short := "machine-2: 2013-10-16 12:00:04 INFO juju a.go:1 up\n"
+	c.Assert(len(short) < len(line0), Equals, true)
+	s.appendLog(c, short)
+	assertRecords(c, t, short[:len(short)-1])
+}
+
+func (s *TailerSuite) TestTailerRotatedLog(c *C) {
+	s.appendLog(c, line0)
+	t := syslog.NewTailer(s.logPath, syslog.Filter{}, 0)
+	defer func() { c.Assert(t.Stop(), IsNil) }()
+	assertNoRecords(c, t)
+
+	// The lines written to the old log before it is replaced are sent,
+	// followed by those of the new log.
+	s.appendLog(c, line1)
+	err := os.Rename(s.logPath, s.logPath+".1")
+	c.Assert(err, IsNil)
+	s.appendLog(c, line0, line2)
+	assertRecords(c, t, line1[:len(line1)-1], line0[:len(line0)-1], line2[:len(line2)-1])
+	assertNoRecords(c, t)
+}
+
+func (s *TailerSuite) TestTailerBounds(c *C) {
+	*syslog.TailerMaxBatch = 2
+	*syslog.TailerMaxPending = 3
+	s.appendLog(c, line0, line1, line2, line3)
+	t := syslog.NewTailer(s.logPath, syslog.Filter{}, -1)
+	defer func() { c.Assert(t.Stop(), IsNil) }()
+
+	// No more than the pending records are read ahead, and they are
+	// sent in batches no larger than the maximum; the rest of the log
+	// is read as they are sent.
+	var got []string
+	timeout := time.After(coretesting.LongWait)
+	for len(got) < 4 {
+		select {
+		case records, ok := <-t.Changes():
+			c.Assert(ok, Equals, true)
+			c.Assert(len(records) <= 2, Equals, true)
+			for _, r := range records {
+				got = append(got, r.String())
+			}
+		case <-timeout:
+			c.Fatalf("timed out waiting for records; got %q", got)
+		}
+	}
+	c.Assert(got, DeepEquals, []string{
+		line0[:len(line0)-1], line1[:len(line1)-1], line2[:len(line2)-1], line3[:len(line3)-1],
+	})
+}
+
+func (s *TailerSuite) TestTailerLargeBacklog(c *C) {
+	*syslog.TailerMaxPending = 2
+	s.appendLog(c, line0, line1, line2, line3)
+	t := syslog.NewTailer(s.logPath, syslog.Filter{}, 3)
+	defer func() { c.Assert(t.Stop(), IsNil) }()
+	// The backlog is bounded by the records that may be held.
+	assertRecords(c, t, line2[:len(line2)-1], line3[:len(line3)-1])
+	assertNoRecords(c, t)
+}
+
+func (s *TailerSuite) TestTailerMissingLog(c *C) {
+	t := syslog.NewTailer(s.logPath, syslog.Filter{}, 0)
+	_, ok := <-t.Changes()
+	c.Assert(ok, Equals, false)
+	c.Assert(t.Stop(), ErrorMatches, "cannot open log: .*")
+}
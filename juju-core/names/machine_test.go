@@ -46,6 +46,7 @@ var machineIdTests = []struct {
 	{pattern: "4/foo/bar", valid: false},
 	{pattern: "5/lxc/42/foo", valid: false},
 	{pattern: "6/lxc/42/kvm/0", valid: true},
+	{pattern: "7/docker/3", valid: true},
 	{pattern: "06/lxc/42/kvm/0", valid: false},
 	{pattern: "6/lxc/042/kvm/0", valid: false},
 	{pattern: "6/lxc/42/kvm/00", valid: false},
@@ -10,3 +10,8 @@ import "strings"
 func IsUser(name string) bool {
 	return !strings.Contains(name, "/")
 }
+
+// UserTag returns the tag for the user with the given name.
+func UserTag(userName string) string {
+	return makeTag(UserTagKind, userName)
+}
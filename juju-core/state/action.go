@@ -0,0 +1,196 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/txn"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/utils"
+)
+
+// actionDoc records an invocation of an action on a unit, and its
+// outcome once the action has been run.
+type actionDoc struct {
+	Id      string `bson:"_id"`
+	Unit    string
+	Name    string
+	Params  map[string]interface{}
+	Status  params.ActionStatus
+	Message string
+	Results map[string]interface{}
+}
+
+// Action represents an invocation of an action defined by the charm of
+// a unit.
+type Action struct {
+	st  *State
+	doc actionDoc
+}
+
+func newAction(st *State, doc *actionDoc) *Action {
+	return &Action{st: st, doc: *doc}
+}
+
+// Id returns the id of the action, which is unique in the environment.
+func (a *Action) Id() string {
+	return a.doc.Id
+}
+
+// UnitName returns the name of the unit the action runs on.
+func (a *Action) UnitName() string {
+	return a.doc.Unit
+}
+
+// Name returns the name of the action, as defined by the charm.
+func (a *Action) Name() string {
+	return a.doc.Name
+}
+
+// Params returns the parameters of the action, including the default
+// value of the parameters not given.
+func (a *Action) Params() map[string]interface{} {
+	return a.doc.Params
+}
+
+// Status returns the progress of the action.
+func (a *Action) Status() params.ActionStatus {
+	return a.doc.Status
+}
+
+// Message returns why the action failed, if it did.
+func (a *Action) Message() string {
+	return a.doc.Message
+}
+
+// Results returns the results recorded by the action when it ran.
+func (a *Action) Results() map[string]interface{} {
+	return a.doc.Results
+}
+
+// Refresh refreshes the contents of the action from the underlying state.
+func (a *Action) Refresh() error {
+	err := a.st.actions.FindId(a.doc.Id).One(&a.doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("action %q", a.doc.Id)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot refresh action %q: %v", a.doc.Id, err)
+	}
+	return nil
+}
+
+// Complete records that the action ran successfully, with the given
+// results.
+func (a *Action) Complete(results map[string]interface{}) error {
+	return a.finish(params.ActionCompleted, results, "")
+}
+
+// Fail records that the action failed, and why.
+func (a *Action) Fail(message string) error {
+	return a.finish(params.ActionFailed, nil, message)
+}
+
+func (a *Action) finish(status params.ActionStatus, results map[string]interface{}, message string) (err error) {
+	defer utils.ErrorContextf(&err, "cannot record outcome of action %q", a.doc.Id)
+	ops := []txn.Op{{
+		C:      a.st.actions.Name,
+		Id:     a.doc.Id,
+		Assert: D{{"status", params.ActionPending}},
+		Update: D{{"$set", D{
+			{"status", status},
+			{"results", results},
+			{"message", message},
+		}}},
+	}}
+	if err := a.st.runTransaction(ops); err != nil {
+		return onAbort(err, fmt.Errorf("action is not pending"))
+	}
+	a.doc.Status = status
+	a.doc.Results = results
+	a.doc.Message = message
+	return nil
+}
+
+// Action returns the action with the given id.
+func (st *State) Action(id string) (*Action, error) {
+	doc := &actionDoc{}
+	err := st.actions.FindId(id).One(doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("action %q", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get action %q: %v", id, err)
+	}
+	return newAction(st, doc), nil
+}
+
+// AddAction queues the named action, defined by the charm of the
+// unit's service, to be run by the agent of the unit with the given
+// parameters.
+func (u *Unit) AddAction(name string, args map[string]interface{}) (action *Action, err error) {
+	defer utils.ErrorContextf(&err, "cannot add action %q to unit %q", name, u)
+	if u.doc.Life != Alive {
+		return nil, fmt.Errorf("unit is not alive")
+	}
+	svc, err := u.Service()
+	if err != nil {
+		return nil, err
+	}
+	ch, _, err := svc.Charm()
+	if err != nil {
+		return nil, err
+	}
+	if args, err = ch.Actions().ValidateParams(name, args); err != nil {
+		return nil, err
+	}
+	seq, err := u.st.sequence(u.globalKey() + "#actions")
+	if err != nil {
+		return nil, err
+	}
+	doc := &actionDoc{
+		Id:     fmt.Sprintf("%s:%d", u.doc.Name, seq),
+		Unit:   u.doc.Name,
+		Name:   name,
+		Params: args,
+		Status: params.ActionPending,
+	}
+	ops := []txn.Op{{
+		C:      u.st.units.Name,
+		Id:     u.doc.Name,
+		Assert: isAliveDoc,
+	}, {
+		C:      u.st.actions.Name,
+		Id:     doc.Id,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}}
+	if err := u.st.runTransaction(ops); err != nil {
+		return nil, onAbort(err, fmt.Errorf("unit is not alive"))
+	}
+	return newAction(u.st, doc), nil
+}
+
+// removeActionsOps returns the operations removing the actions queued on
+// the unit.
+func removeActionsOps(st *State, unitName string) ([]txn.Op, error) {
+	var docs []actionDoc
+	err := st.actions.Find(D{{"unit", unitName}}).Select(D{{"_id", 1}}).All(&docs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get actions of unit %q: %v", unitName, err)
+	}
+	ops := make([]txn.Op, len(docs))
+	for i, doc := range docs {
+		ops[i] = txn.Op{
+			C:      st.actions.Name,
+			Id:     doc.Id,
+			Remove: true,
+		}
+	}
+	return ops, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	statetesting "launchpad.net/juju-core/state/testing"
+	"launchpad.net/juju-core/testing/checkers"
+)
+
+type ActionSuite struct {
+	ConnSuite
+	service *state.Service
+	unit    *state.Unit
+}
+
+var _ = Suite(&ActionSuite{})
+
+func (s *ActionSuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.service, err = s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	s.unit, err = s.service.AddUnit()
+	c.Assert(err, IsNil)
+}
+
+func (s *ActionSuite) TestAddAction(c *C) {
+	action, err := s.unit.AddAction("snapshot", map[string]interface{}{"compression": 5})
+	c.Assert(err, IsNil)
+	c.Assert(action.Id(), Equals, "dummy/0:0")
+	c.Assert(action.UnitName(), Equals, "dummy/0")
+	c.Assert(action.Name(), Equals, "snapshot")
+	c.Assert(action.Status(), Equals, params.ActionPending)
+
+	action, err = s.State.Action(action.Id())
+	c.Assert(err, IsNil)
+	c.Assert(action.Name(), Equals, "snapshot")
+	c.Assert(action.Params(), DeepEquals, map[string]interface{}{
+		"outfile":     "foo.bz2",
+		"compression": int64(5),
+	})
+	c.Assert(action.Status(), Equals, params.ActionPending)
+
+	action, err = s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	c.Assert(action.Id(), Equals, "dummy/0:1")
+
+	_, err = s.State.Action("dummy/0:2")
+	c.Assert(err, ErrorMatches, `action "dummy/0:2" not found`)
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+}
+
+func (s *ActionSuite) TestAddActionErrors(c *C) {
+	_, err := s.unit.AddAction("restore", nil)
+	c.Assert(err, ErrorMatches, `cannot add action "restore" to unit "dummy/0": unknown action "restore"`)
+	_, err = s.unit.AddAction("snapshot", map[string]interface{}{"compression": "high"})
+	c.Assert(err, ErrorMatches, `cannot add action "snapshot" to unit "dummy/0": option "compression" expected int, got "high"`)
+
+	err = s.unit.Destroy()
+	c.Assert(err, IsNil)
+	_, err = s.unit.AddAction("snapshot", nil)
+	c.Assert(err, ErrorMatches, `cannot add action "snapshot" to unit "dummy/0": unit is not alive`)
+}
+
+func (s *ActionSuite) TestCompleteAndFail(c *C) {
+	action0, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	action1, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+
+	err = action0.Complete(map[string]interface{}{"outfile": "/tmp/foo.bz2"})
+	c.Assert(err, IsNil)
+	err = action1.Fail("no space left on device")
+	c.Assert(err, IsNil)
+
+	err = action0.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(action0.Status(), Equals, params.ActionCompleted)
+	c.Assert(action0.Results(), DeepEquals, map[string]interface{}{"outfile": "/tmp/foo.bz2"})
+	c.Assert(action0.Message(), Equals, "")
+	err = action1.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(action1.Status(), Equals, params.ActionFailed)
+	c.Assert(action1.Message(), Equals, "no space left on device")
+
+	err = action0.Fail("too late")
+	c.Assert(err, ErrorMatches, `cannot record outcome of action "dummy/0:0": action is not pending`)
+}
+
+func (s *ActionSuite) TestRemoveUnit(c *C) {
+	action, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, IsNil)
+	err = s.unit.Remove()
+	c.Assert(err, IsNil)
+	_, err = s.State.Action(action.Id())
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+}
+
+func (s *ActionSuite) TestWatchActions(c *C) {
+	action0, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+
+	w := s.unit.WatchActions()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange(action0.Id())
+	wc.AssertNoChange()
+
+	// Queueing an action is reported.
+	action1, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	wc.AssertChange(action1.Id())
+	wc.AssertNoChange()
+
+	// Recording its outcome is not.
+	err = action0.Complete(nil)
+	c.Assert(err, IsNil)
+	err = action1.Fail("failed")
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	// Actions queued on other units are ignored.
+	other, err := s.service.AddUnit()
+	c.Assert(err, IsNil)
+	_, err = other.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
@@ -24,6 +24,17 @@ type State struct {
 	client *rpc.Conn
 	conn   *websocket.Conn
 
+	// authTag holds the tag of the authenticated entity.
+	authTag string
+
+	// addr and tlsConfig hold the address of the server and the TLS
+	// configuration used to reach it, and password the password of
+	// the authenticated entity, so that requests can be made to the
+	// HTTP endpoints of the server.
+	addr      string
+	tlsConfig *tls.Config
+	password  string
+
 	// broken is a channel that gets closed when the connection is
 	// broken.
 	broken chan struct{}
@@ -118,8 +129,10 @@ func Open(info *Info, opts DialOpts) (*State, error) {
 	client := rpc.NewConn(jsoncodec.NewWebsocket(conn))
 	client.Start()
 	st := &State{
-		client: client,
-		conn:   conn,
+		client:    client,
+		conn:      conn,
+		addr:      info.Addrs[0],
+		tlsConfig: cfg.TlsConfig,
 	}
 	if info.Tag != "" || info.Password != "" {
 		if err := st.Login(info.Tag, info.Password, info.Nonce); err != nil {
@@ -4,9 +4,19 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/version"
 )
 
 // Client represents the client-accessible part of the state.
@@ -14,20 +24,100 @@ type Client struct {
 	st *State
 }
 
-// MachineInfo holds information about a machine.
-type MachineInfo struct {
-	InstanceId string // blank if not set.
-}
-
 // Status holds information about the status of a juju environment.
 type Status struct {
-	Machines map[string]MachineInfo
-	// TODO the rest
+	EnvironmentName string
+	Machines        map[string]MachineStatus
+	Services        map[string]ServiceStatus
+
+	// InstancesError holds why the instances could not be retrieved
+	// from the environment, if they could not; the status of the
+	// machines then lacks their addresses.
+	InstancesError string
+}
+
+// MachineStatus holds the status of a machine, and of the containers
+// it hosts.
+type MachineStatus struct {
+	Err            *params.Error
+	AgentState     params.Status
+	AgentStateInfo string
+	AgentVersion   string
+	DNSName        string
+	InstanceId     instance.Id
+	InstanceState  string
+	Life           string
+	Series         string
+	Id             string
+	Containers     map[string]MachineStatus
+	Hardware       string
+
+	// ContainerOperation holds the operation last requested on a
+	// container machine, while it is pending or once it has failed.
+	ContainerOperation *ContainerOperationInfo
 }
 
-// Status returns the status of the juju environment.
-func (c *Client) Status() (*Status, error) {
+// ServiceStatus holds the status of a service and of its units.
+type ServiceStatus struct {
+	Err                *params.Error
+	Charm              string
+	Exposed            bool
+	Life               string
+	WorkloadStatus     params.WorkloadStatus
+	WorkloadStatusInfo string
+	HookPolicy         params.HookPolicy
+	Relations          map[string][]string
+	SubordinateTo      []string
+	Units              map[string]UnitStatus
+}
+
+// UnitStatus holds the status of a unit and of its subordinates.
+type UnitStatus struct {
+	Err                *params.Error
+	AgentState         params.Status
+	AgentStateInfo     string
+	AgentVersion       string
+	WorkloadStatus     params.WorkloadStatus
+	WorkloadStatusInfo string
+	Life               string
+	Machine            string
+	OpenedPorts        []string
+	PublishedPorts     []string
+	PublicAddress      string
+	Subordinates       map[string]UnitStatus
+}
+
+// Close closes the connection to the API server.
+func (c *Client) Close() error {
+	return c.st.Close()
+}
+
+// FullStatus returns the status of the juju environment, restricted to
+// the services and units matching the given patterns, if any.
+func (c *Client) FullStatus(patterns []string) (*Status, error) {
 	var s Status
+	p := params.StatusParams{Patterns: patterns}
+	if err := c.st.Call("Client", "", "FullStatus", p, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// LegacyMachineInfo holds information about a machine.
+type LegacyMachineInfo struct {
+	InstanceId string // blank if not set.
+}
+
+// LegacyStatus holds the instance ids of the machines of a juju
+// environment. It is superseded by Status.
+type LegacyStatus struct {
+	Machines map[string]LegacyMachineInfo
+}
+
+// Status returns the instance ids of the machines of the juju
+// environment. Use FullStatus for the complete status.
+func (c *Client) Status() (*LegacyStatus, error) {
+	var s LegacyStatus
 	if err := c.st.Call("Client", "", "Status", nil, &s); err != nil {
 		return nil, err
 	}
@@ -84,6 +174,39 @@ func (c *Client) DestroyRelation(endpoints ...string) error {
 	return c.st.Call("Client", "", "DestroyRelation", params, nil)
 }
 
+// ContainerOperation asks the agent of the machine hosting a container to
+// freeze, thaw, snapshot or restore it. The snapshot name is only used by
+// the "snapshot" and "restore" operations.
+func (c *Client) ContainerOperation(machineId, operation, snapshot string) error {
+	params := params.ContainerOperation{
+		MachineId: machineId,
+		Operation: operation,
+		Snapshot:  snapshot,
+	}
+	return c.st.Call("Client", "", "ContainerOperation", params, nil)
+}
+
+// ContainerOperationInfo describes the operation last requested on a
+// container machine. Error holds why the operation failed, and is empty
+// while it is pending.
+type ContainerOperationInfo struct {
+	Operation string
+	Snapshot  string
+	Error     string
+}
+
+// ContainerOperationInfo returns the operation last requested on the
+// container machine with the given id. It returns an error with the
+// params.CodeNotFound code once the operation has succeeded.
+func (c *Client) ContainerOperationInfo(machineId string) (*ContainerOperationInfo, error) {
+	info := new(ContainerOperationInfo)
+	args := params.ContainerOperationInfo{MachineId: machineId}
+	if err := c.st.Call("Client", "", "ContainerOperationInfo", args, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
 // ServiceExpose changes the juju-managed firewall to expose any ports that
 // were also explicitly marked by units as open.
 func (c *Client) ServiceExpose(service string) error {
@@ -99,14 +222,16 @@ func (c *Client) ServiceUnexpose(service string) error {
 }
 
 // ServiceDeploy obtains the charm, either locally or from the charm store,
-// and deploys it.
-func (c *Client) ServiceDeploy(charmUrl string, serviceName string, numUnits int, configYAML string, cons constraints.Value) error {
+// and deploys it, on the machine or container given by toMachineSpec if
+// not empty.
+func (c *Client) ServiceDeploy(charmUrl string, serviceName string, numUnits int, configYAML string, cons constraints.Value, toMachineSpec string) error {
 	params := params.ServiceDeploy{
-		ServiceName: serviceName,
-		CharmUrl:    charmUrl,
-		NumUnits:    numUnits,
-		ConfigYAML:  configYAML,
-		Constraints: cons,
+		ServiceName:   serviceName,
+		CharmUrl:      charmUrl,
+		NumUnits:      numUnits,
+		ConfigYAML:    configYAML,
+		Constraints:   cons,
+		ToMachineSpec: toMachineSpec,
 	}
 	return c.st.Call("Client", "", "ServiceDeploy", params, nil)
 }
@@ -128,11 +253,13 @@ func (c *Client) ServiceSetCharm(serviceName string, charmUrl string, force bool
 	return c.st.Call("Client", "", "ServiceSetCharm", args, nil)
 }
 
-// AddServiceUnits adds a given number of units to a service.
-func (c *Client) AddServiceUnits(service string, numUnits int) ([]string, error) {
+// AddServiceUnits adds a given number of units to a service, on the
+// machine or container given by machineSpec if not empty.
+func (c *Client) AddServiceUnits(service string, numUnits int, machineSpec string) ([]string, error) {
 	args := params.AddServiceUnits{
-		ServiceName: service,
-		NumUnits:    numUnits,
+		ServiceName:   service,
+		NumUnits:      numUnits,
+		ToMachineSpec: machineSpec,
 	}
 	results := new(params.AddServiceUnitsResults)
 	err := c.st.Call("Client", "", "AddServiceUnits", args, results)
@@ -175,6 +302,7 @@ type CharmInfo struct {
 	URL      string
 	Config   *charm.Config
 	Meta     *charm.Meta
+	Actions  *charm.Actions
 }
 
 // CharmInfo returns information about the requested charm.
@@ -217,6 +345,17 @@ func (c *Client) WatchAll() (*AllWatcher, error) {
 	return newAllWatcher(c, &info.AllWatcherId), nil
 }
 
+// WatchDebugLog returns a DebugLogWatcher, from which the records of
+// the accumulated log of the environment selected by args can be read
+// as they are written.
+func (c *Client) WatchDebugLog(args params.DebugLog) (*DebugLogWatcher, error) {
+	info := new(params.DebugLogWatcherId)
+	if err := c.st.Call("Client", "", "WatchDebugLog", args, info); err != nil {
+		return nil, err
+	}
+	return &DebugLogWatcher{c, info.DebugLogWatcherId}, nil
+}
+
 // GetAnnotations returns annotations that have been set on the given entity.
 func (c *Client) GetAnnotations(tag string) (map[string]string, error) {
 	args := params.GetAnnotations{tag}
@@ -232,3 +371,264 @@ func (c *Client) SetAnnotations(tag string, pairs map[string]string) error {
 	args := params.SetAnnotations{tag, pairs}
 	return c.st.Call("Client", "", "SetAnnotations", args, nil)
 }
+
+// AddCharm adds the given charm to the environment, fetching it from
+// the charm store when needed. Local charms must be added with
+// AddLocalCharm instead.
+func (c *Client) AddCharm(curl *charm.URL) error {
+	args := params.CharmURL{URL: curl.String()}
+	return c.st.Call("Client", "", "AddCharm", args, nil)
+}
+
+// AddLocalCharm adds to the environment the given charm, read from a
+// local repository, under the given local charm URL.
+func (c *Client) AddLocalCharm(curl *charm.URL, ch charm.Charm) error {
+	var bundle []byte
+	switch ch := ch.(type) {
+	case *charm.Dir:
+		var buf bytes.Buffer
+		if err := ch.BundleTo(&buf); err != nil {
+			return fmt.Errorf("cannot bundle charm: %v", err)
+		}
+		bundle = buf.Bytes()
+	case *charm.Bundle:
+		var err error
+		if bundle, err = ioutil.ReadFile(ch.Path); err != nil {
+			return fmt.Errorf("cannot read charm bundle: %v", err)
+		}
+	default:
+		return fmt.Errorf("unknown charm type %T", ch)
+	}
+	args := params.AddLocalCharm{
+		CharmURL: curl.String(),
+		Bundle:   bundle,
+	}
+	return c.st.Call("Client", "", "AddLocalCharm", args, nil)
+}
+
+// ServicePutResource uploads the content read from r as new content for
+// the named resource of the service. The content is streamed to the
+// resources endpoint of the server, and then recorded for the resource.
+func (c *Client) ServicePutResource(service, name string, r io.Reader) error {
+	result, err := c.uploadResource(service, name, r)
+	if err != nil {
+		return err
+	}
+	args := params.ServiceSetResource{
+		ServiceName: service,
+		Name:        name,
+		URL:         result.URL,
+		Sha256:      result.Sha256,
+		Size:        result.Size,
+	}
+	return c.st.Call("Client", "", "ServiceSetResource", args, nil)
+}
+
+// uploadResource streams the content read from r for the named
+// resource of the service to the resources endpoint of the server.
+func (c *Client) uploadResource(service, name string, r io.Reader) (*params.ResourceUploadResult, error) {
+	query := url.Values{"service": {service}, "name": {name}}
+	req, err := http.NewRequest("PUT", "https://"+c.st.addr+"/resources?"+query.Encode(), r)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.st.authTag, c.st.password)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: c.st.tlsConfig},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot upload resource %q: %v", name, err)
+	}
+	defer resp.Body.Close()
+	var result params.ResourceUploadResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("cannot upload resource %q: %v (%s)", name, err, resp.Status)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &result, nil
+}
+
+// ServiceGetCharmURL returns the charm URL the given service is
+// running at present.
+func (c *Client) ServiceGetCharmURL(service string) (*charm.URL, error) {
+	result := new(params.StringResult)
+	args := params.ServiceGetCharmURL{ServiceName: service}
+	if err := c.st.Call("Client", "", "ServiceGetCharmURL", args, result); err != nil {
+		return nil, err
+	}
+	return charm.ParseURL(result.Result)
+}
+
+// ServiceCharmRelations returns the names of the relations of the
+// charm of the given service.
+func (c *Client) ServiceCharmRelations(service string) ([]string, error) {
+	results := new(params.ServiceCharmRelationsResults)
+	args := params.ServiceCharmRelations{ServiceName: service}
+	err := c.st.Call("Client", "", "ServiceCharmRelations", args, results)
+	return results.CharmRelations, err
+}
+
+// ServiceGetHookPolicy returns the hook policy of the given service.
+func (c *Client) ServiceGetHookPolicy(service string) (params.HookPolicy, error) {
+	var policy params.HookPolicy
+	args := params.ServiceGetHookPolicy{ServiceName: service}
+	err := c.st.Call("Client", "", "ServiceGetHookPolicy", args, &policy)
+	return policy, err
+}
+
+// ServiceSetHookPolicy sets the hook policy of the given service.
+func (c *Client) ServiceSetHookPolicy(service string, policy params.HookPolicy) error {
+	args := params.ServiceSetHookPolicy{
+		ServiceName: service,
+		Policy:      policy,
+	}
+	return c.st.Call("Client", "", "ServiceSetHookPolicy", args, nil)
+}
+
+// AddMachines adds new machines with the supplied parameters.
+func (c *Client) AddMachines(machineParams []params.AddMachineParams) ([]params.AddMachinesResult, error) {
+	args := params.AddMachines{MachineParams: machineParams}
+	results := new(params.AddMachinesResults)
+	err := c.st.Call("Client", "", "AddMachines", args, results)
+	return results.Machines, err
+}
+
+// DestroyMachines removes the given set of machines.
+func (c *Client) DestroyMachines(machines ...string) error {
+	args := params.DestroyMachines{MachineNames: machines}
+	return c.st.Call("Client", "", "DestroyMachines", args, nil)
+}
+
+// DestroyEnvironment stops the instances of all the machines of the
+// environment but the ones managing it.
+func (c *Client) DestroyEnvironment() error {
+	return c.st.Call("Client", "", "DestroyEnvironment", nil, nil)
+}
+
+// EnvironmentConfig returns the attributes of the configuration of the
+// environment.
+func (c *Client) EnvironmentConfig() (map[string]interface{}, error) {
+	results := new(params.EnvironmentConfigResults)
+	err := c.st.Call("Client", "", "EnvironmentConfig", nil, results)
+	return results.Config, err
+}
+
+// SetEnvironmentConfig applies the given attributes to the
+// configuration of the environment.
+func (c *Client) SetEnvironmentConfig(config map[string]interface{}) error {
+	args := params.SetEnvironmentConfig{Config: config}
+	return c.st.Call("Client", "", "SetEnvironmentConfig", args, nil)
+}
+
+// UpgradeJuju sets the agent version the agents of the environment
+// run.
+func (c *Client) UpgradeJuju(version version.Number) error {
+	args := params.UpgradeJuju{Version: version}
+	return c.st.Call("Client", "", "UpgradeJuju", args, nil)
+}
+
+// GetEnvironmentConstraints returns the constraints of the environment.
+func (c *Client) GetEnvironmentConstraints() (constraints.Value, error) {
+	results := new(params.GetEnvironmentConstraintsResults)
+	err := c.st.Call("Client", "", "GetEnvironmentConstraints", nil, results)
+	return results.Constraints, err
+}
+
+// SetEnvironmentConstraints specifies the constraints of the
+// environment.
+func (c *Client) SetEnvironmentConstraints(constraints constraints.Value) error {
+	args := params.SetEnvironmentConstraints{Constraints: constraints}
+	return c.st.Call("Client", "", "SetEnvironmentConstraints", args, nil)
+}
+
+// PublicAddress returns the public address of the given machine or
+// unit.
+func (c *Client) PublicAddress(target string) (string, error) {
+	results := new(params.PublicAddressResults)
+	args := params.PublicAddress{Target: target}
+	err := c.st.Call("Client", "", "PublicAddress", args, results)
+	return results.PublicAddress, err
+}
+
+// PrivateAddress returns the private address of the given machine or
+// unit.
+func (c *Client) PrivateAddress(target string) (string, error) {
+	results := new(params.PrivateAddressResults)
+	args := params.PrivateAddress{Target: target}
+	err := c.st.Call("Client", "", "PrivateAddress", args, results)
+	return results.PrivateAddress, err
+}
+
+// EnqueueAction queues the named action to be run by the agent of the
+// unit, with the given parameters, and returns the id of the action.
+func (c *Client) EnqueueAction(unitName, action string, actionParams map[string]string) (string, error) {
+	results := new(params.EnqueueActionResults)
+	args := params.EnqueueAction{
+		UnitName: unitName,
+		Action:   action,
+		Params:   actionParams,
+	}
+	err := c.st.Call("Client", "", "EnqueueAction", args, results)
+	return results.Id, err
+}
+
+// ActionInfo holds the status of an action, and its results once it
+// ran.
+type ActionInfo struct {
+	Id       string
+	UnitName string
+	Name     string
+	Params   map[string]interface{}
+	Status   string
+	Results  map[string]interface{}
+	Message  string
+}
+
+// ActionInfo returns the status of the action with the given id.
+func (c *Client) ActionInfo(id string) (*ActionInfo, error) {
+	info := new(ActionInfo)
+	args := params.ActionInfo{Id: id}
+	if err := c.st.Call("Client", "", "ActionInfo", args, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// AddUser adds a user with the given access to the environment, which
+// is one of "read", "write" or "admin".
+func (c *Client) AddUser(name, password, access string) error {
+	args := params.AddUser{Name: name, Password: password, Access: access}
+	return c.st.Call("Client", "", "AddUser", args, nil)
+}
+
+// RemoveUser removes the given user.
+func (c *Client) RemoveUser(name string) error {
+	args := params.UserName{Name: name}
+	return c.st.Call("Client", "", "RemoveUser", args, nil)
+}
+
+// ListUsers returns information about all the users of the
+// environment.
+func (c *Client) ListUsers() ([]params.UserInfo, error) {
+	var results params.ListUsersResults
+	err := c.st.Call("Client", "", "ListUsers", nil, &results)
+	return results.Users, err
+}
+
+// SetUserPassword changes the password of the given user.
+func (c *Client) SetUserPassword(name, password string) error {
+	args := params.SetUserPassword{Name: name, Password: password}
+	return c.st.Call("Client", "", "SetUserPassword", args, nil)
+}
+
+// AuditLog returns the entries of the audit log of the environment
+// selected by the given parameters, oldest first.
+func (c *Client) AuditLog(args params.AuditLog) ([]params.AuditEntry, error) {
+	var results params.AuditLogResults
+	err := c.st.Call("Client", "", "AuditLog", args, &results)
+	return results.Entries, err
+}
@@ -0,0 +1,27 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api
+
+import (
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// DebugLogWatcher holds information allowing us to get the records of
+// the accumulated log of the environment as they are written.
+type DebugLogWatcher struct {
+	client *Client
+	id     string
+}
+
+// Next blocks until there are new records selected by the watcher, and
+// returns them.
+func (watcher *DebugLogWatcher) Next() ([]params.LogRecord, error) {
+	info := new(params.DebugLogNextResults)
+	err := watcher.client.st.Call("DebugLogWatcher", watcher.id, "Next", nil, info)
+	return info.Records, err
+}
+
+func (watcher *DebugLogWatcher) Stop() error {
+	return watcher.client.st.Call("DebugLogWatcher", watcher.id, "Stop", nil, nil)
+}
@@ -6,6 +6,7 @@ package machiner
 import (
 	"fmt"
 
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/api/watcher"
 )
@@ -52,6 +53,22 @@ func (m *Machine) SetStatus(status params.Status, info string) error {
 	return result.OneError()
 }
 
+// SetMachineAddresses records the addresses of the network interfaces
+// of the machine.
+func (m *Machine) SetMachineAddresses(addresses []instance.Address) error {
+	var result params.ErrorResults
+	args := params.SetMachinesAddresses{
+		MachineAddresses: []params.MachineAddresses{
+			{Tag: m.tag, Addresses: addresses},
+		},
+	}
+	err := m.st.caller.Call("Machiner", "", "SetMachineAddresses", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
 // EnsureDead sets the machine lifecycle to Dead if it is Alive or
 // Dying. It does nothing otherwise.
 func (m *Machine) EnsureDead() error {
@@ -9,6 +9,7 @@ import (
 	gc "launchpad.net/gocheck"
 
 	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/juju/testing"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api"
@@ -86,6 +87,20 @@ func (s *machinerSuite) TestSetStatus(c *gc.C) {
 	c.Assert(info, gc.Equals, "blah")
 }
 
+func (s *machinerSuite) TestSetMachineAddresses(c *gc.C) {
+	machine, err := s.machiner.Machine("machine-0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.machine.MachineAddresses(), gc.HasLen, 0)
+
+	addresses := []instance.Address{instance.NewScopedAddress("10.0.0.2")}
+	err = machine.SetMachineAddresses(addresses)
+	c.Assert(err, gc.IsNil)
+
+	err = s.machine.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.machine.MachineAddresses(), gc.DeepEquals, addresses)
+}
+
 func (s *machinerSuite) TestEnsureDead(c *gc.C) {
 	c.Assert(s.machine.Life(), gc.Equals, state.Alive)
 
@@ -13,6 +13,16 @@ const (
 	Dead  Life = "dead"
 )
 
+// ResolvedMode describes the way state transition errors of a unit
+// are resolved.
+type ResolvedMode string
+
+const (
+	ResolvedNone       ResolvedMode = ""
+	ResolvedRetryHooks ResolvedMode = "retry-hooks"
+	ResolvedNoHooks    ResolvedMode = "no-hooks"
+)
+
 // MachineJob values define responsibilities that machines may be
 // expected to fulfil.
 type MachineJob string
@@ -66,3 +76,73 @@ func (status Status) Valid() bool {
 	}
 	return true
 }
+
+// ActionStatus describes the progress of an action.
+type ActionStatus string
+
+const (
+	// The action is waiting to be run by the agent of its unit.
+	ActionPending ActionStatus = "pending"
+
+	// The action ran successfully.
+	ActionCompleted ActionStatus = "completed"
+
+	// The action could not be run, or exited with an error.
+	ActionFailed ActionStatus = "failed"
+)
+
+// WorkloadStatus represents the status of the workload of a unit, as
+// reported by its charm.
+type WorkloadStatus string
+
+const (
+	// The charm has not reported the status of the workload.
+	WorkloadUnknown WorkloadStatus = "unknown"
+
+	// The charm is performing an operation, such as installing or
+	// reconfiguring the software, that does not need human attention.
+	WorkloadMaintenance WorkloadStatus = "maintenance"
+
+	// The workload is waiting for something, such as a relation, that
+	// the environment will eventually provide.
+	WorkloadWaiting WorkloadStatus = "waiting"
+
+	// The workload requires human intervention, such as setting a
+	// missing configuration option, before it can proceed.
+	WorkloadBlocked WorkloadStatus = "blocked"
+
+	// The workload is ready and serving.
+	WorkloadActive WorkloadStatus = "active"
+)
+
+// Valid returns true if status has a known value.
+func (status WorkloadStatus) Valid() bool {
+	switch status {
+	case
+		WorkloadUnknown,
+		WorkloadMaintenance,
+		WorkloadWaiting,
+		WorkloadBlocked,
+		WorkloadActive:
+	default:
+		return false
+	}
+	return true
+}
+
+// workloadSeverity orders the workload statuses from the one least to
+// the one most in need of attention.
+var workloadSeverity = map[WorkloadStatus]int{
+	WorkloadUnknown:     0,
+	WorkloadActive:      1,
+	WorkloadMaintenance: 2,
+	WorkloadWaiting:     3,
+	WorkloadBlocked:     4,
+}
+
+// MoreSevere returns true if status is more in need of attention than
+// other, so that it takes precedence when summarizing the workload
+// statuses of the units of a service.
+func (status WorkloadStatus) MoreSevere(other WorkloadStatus) bool {
+	return workloadSeverity[status] > workloadSeverity[other]
+}
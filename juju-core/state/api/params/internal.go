@@ -5,6 +5,7 @@ package params
 
 import (
 	"launchpad.net/juju-core/agent/tools"
+	"launchpad.net/juju-core/instance"
 )
 
 // Entity identifies a single entity.
@@ -161,3 +162,423 @@ type StringsWatchResult struct {
 type StringsWatchResults struct {
 	Results []StringsWatchResult
 }
+
+// StringResult holds a string or an error.
+type StringResult struct {
+	Error  *Error
+	Result string
+}
+
+// StringResults holds the bulk operation result for an API call
+// that returns a string or an error.
+type StringResults struct {
+	Results []StringResult
+}
+
+// StringBoolResult holds the result of an API call that returns a
+// string and a boolean, or an error.
+type StringBoolResult struct {
+	Error  *Error
+	Result string
+	Ok     bool
+}
+
+// StringBoolResults holds multiple results with a string and a bool
+// in each.
+type StringBoolResults struct {
+	Results []StringBoolResult
+}
+
+// BoolResult holds the result of an API call that returns a boolean
+// or an error.
+type BoolResult struct {
+	Error  *Error
+	Result bool
+}
+
+// BoolResults holds multiple results with a bool in each.
+type BoolResults struct {
+	Results []BoolResult
+}
+
+// ResolvedModeResult holds a resolved mode or an error.
+type ResolvedModeResult struct {
+	Error *Error
+	Mode  ResolvedMode
+}
+
+// ResolvedModeResults holds the bulk operation result for an API call
+// that returns a resolved mode or an error.
+type ResolvedModeResults struct {
+	Results []ResolvedModeResult
+}
+
+// SetEntityAddress holds an entity tag and an address to set.
+type SetEntityAddress struct {
+	Tag     string
+	Address string
+}
+
+// SetEntityAddresses holds the parameters for making a
+// SetPublicAddress or SetPrivateAddress call.
+type SetEntityAddresses struct {
+	Entities []SetEntityAddress
+}
+
+// MachineAddresses holds a machine tag and the addresses of its
+// network interfaces.
+type MachineAddresses struct {
+	Tag       string
+	Addresses []instance.Address
+}
+
+// SetMachinesAddresses holds the parameters for making a
+// SetMachineAddresses call.
+type SetMachinesAddresses struct {
+	MachineAddresses []MachineAddresses
+}
+
+// AddressesResult holds a slice of addresses or an error.
+type AddressesResult struct {
+	Error     *Error
+	Addresses []instance.Address
+}
+
+// AddressesResults holds the bulk operation result for an API call
+// that returns a slice of addresses or an error.
+type AddressesResults struct {
+	Results []AddressesResult
+}
+
+// EntityCharmURL holds an entity's tag and a charm URL.
+type EntityCharmURL struct {
+	Tag      string
+	CharmURL string
+}
+
+// EntitiesCharmURL holds the parameters for making a SetCharmURL call.
+type EntitiesCharmURL struct {
+	Entities []EntityCharmURL
+}
+
+// EntityPort holds an entity's tag, a protocol and a port.
+type EntityPort struct {
+	Tag      string
+	Protocol string
+	Port     int
+}
+
+// EntitiesPorts holds the parameters for making an OpenPort or
+// ClosePort call.
+type EntitiesPorts struct {
+	Entities []EntityPort
+}
+
+// ConfigSettingsResult holds the configuration settings of a unit's
+// service, or an error.
+type ConfigSettingsResult struct {
+	Error    *Error
+	Settings map[string]interface{}
+}
+
+// ConfigSettingsResults holds multiple configuration settings results.
+type ConfigSettingsResults struct {
+	Results []ConfigSettingsResult
+}
+
+// CharmURL identifies a single charm URL.
+type CharmURL struct {
+	URL string
+}
+
+// CharmURLs identifies multiple charm URLs.
+type CharmURLs struct {
+	URLs []CharmURL
+}
+
+// CharmBundleResult holds the location and the hash of a charm bundle,
+// or an error.
+type CharmBundleResult struct {
+	Error        *Error
+	BundleURL    string
+	BundleSha256 string
+}
+
+// CharmBundleResults holds multiple charm bundle results.
+type CharmBundleResults struct {
+	Results []CharmBundleResult
+}
+
+// RelationUnit holds a relation key and a unit tag.
+type RelationUnit struct {
+	Relation string
+	Unit     string
+}
+
+// RelationUnits holds the parameters for API calls expecting a pair
+// of relation key and unit tag.
+type RelationUnits struct {
+	RelationUnits []RelationUnit
+}
+
+// RelationIds holds multiple relation ids.
+type RelationIds struct {
+	RelationIds []int
+}
+
+// RelationResult holds the life, the key, the id and the endpoint of a
+// relation, as seen by a unit of one of its services, or an error.
+type RelationResult struct {
+	Error    *Error
+	Life     Life
+	Id       int
+	Key      string
+	Endpoint Endpoint
+}
+
+// RelationResults holds multiple relation results.
+type RelationResults struct {
+	Results []RelationResult
+}
+
+// RelationUnitPair holds a relation key, and the tags of a local and
+// a remote unit.
+type RelationUnitPair struct {
+	Relation   string
+	LocalUnit  string
+	RemoteUnit string
+}
+
+// RelationUnitPairs holds the parameters for a ReadRemoteSettings
+// call.
+type RelationUnitPairs struct {
+	RelationUnitPairs []RelationUnitPair
+}
+
+// RelationSettings holds the settings of a unit in a relation.
+type RelationSettings map[string]interface{}
+
+// RelationSettingsResult holds the settings of a unit in a relation,
+// or an error.
+type RelationSettingsResult struct {
+	Error    *Error
+	Settings RelationSettings
+}
+
+// RelationSettingsResults holds multiple relation settings results.
+type RelationSettingsResults struct {
+	Results []RelationSettingsResult
+}
+
+// RelationUnitSettings holds a relation key, a unit tag and the
+// changes to make to the unit's relation settings; settings with a nil
+// value are deleted.
+type RelationUnitSettings struct {
+	Relation string
+	Unit     string
+	Settings RelationSettings
+}
+
+// RelationUnitsSettings holds the parameters for an UpdateSettings
+// call.
+type RelationUnitsSettings struct {
+	RelationUnits []RelationUnitSettings
+}
+
+// UnitSettings holds the version and the contents of the relation
+// settings of a unit.
+type UnitSettings struct {
+	Version  int64
+	Settings map[string]interface{}
+}
+
+// RelationUnitsChange holds notifications of units entering and
+// leaving the scope of a relation, and of changes to their settings.
+type RelationUnitsChange struct {
+	Changed  map[string]UnitSettings
+	Departed []string
+}
+
+// RelationUnitsWatchResult holds a RelationUnitsWatcher id, the
+// initial changes and an error (if any).
+type RelationUnitsWatchResult struct {
+	RelationUnitsWatcherId string
+	Changes                RelationUnitsChange
+	Error                  *Error
+}
+
+// RelationUnitsWatchResults holds the results for any API call which
+// ends up returning a list of RelationUnitsWatchers.
+type RelationUnitsWatchResults struct {
+	Results []RelationUnitsWatchResult
+}
+
+// SetEntityWorkloadStatus holds an entity tag and the status and
+// message of its workload.
+type SetEntityWorkloadStatus struct {
+	Tag    string
+	Status WorkloadStatus
+	Info   string
+}
+
+// SetWorkloadStatus holds the parameters for making a
+// SetWorkloadStatus call.
+type SetWorkloadStatus struct {
+	Entities []SetEntityWorkloadStatus
+}
+
+// WorkloadStatusResult holds the status of a workload and its
+// message, or an error.
+type WorkloadStatusResult struct {
+	Error  *Error
+	Status WorkloadStatus
+	Info   string
+}
+
+// WorkloadStatusResults holds multiple workload status results.
+type WorkloadStatusResults struct {
+	Results []WorkloadStatusResult
+}
+
+// HookPolicyResult holds the hook policy of a service, or an error.
+type HookPolicyResult struct {
+	Error  *Error
+	Policy HookPolicy
+}
+
+// HookPolicyResults holds multiple hook policy results.
+type HookPolicyResults struct {
+	Results []HookPolicyResult
+}
+
+// LeaderSettingsResult holds the leader settings of a service, or an
+// error.
+type LeaderSettingsResult struct {
+	Error    *Error
+	Settings map[string]string
+}
+
+// LeaderSettingsResults holds multiple leader settings results.
+type LeaderSettingsResults struct {
+	Results []LeaderSettingsResult
+}
+
+// EntityLeaderSettings holds a unit tag and the leader settings it
+// writes.
+type EntityLeaderSettings struct {
+	Tag      string
+	Settings map[string]string
+}
+
+// SetLeaderSettings holds the parameters for making a
+// SetLeaderSettings call.
+type SetLeaderSettings struct {
+	Entities []EntityLeaderSettings
+}
+
+// ActionIds holds the ids of multiple actions.
+type ActionIds struct {
+	Ids []string
+}
+
+// ActionResult holds the name, the parameters and the status of an
+// action, or an error.
+type ActionResult struct {
+	Error  *Error
+	Name   string
+	Params map[string]interface{}
+	Status ActionStatus
+}
+
+// ActionResults holds multiple action results.
+type ActionResults struct {
+	Results []ActionResult
+}
+
+// ActionComplete holds the id of a completed action and its results.
+type ActionComplete struct {
+	Id      string
+	Results map[string]interface{}
+}
+
+// ActionsComplete holds the parameters for making a CompleteActions
+// call.
+type ActionsComplete struct {
+	Actions []ActionComplete
+}
+
+// ActionFail holds the id of a failed action and the reason it failed.
+type ActionFail struct {
+	Id      string
+	Message string
+}
+
+// ActionsFail holds the parameters for making a FailActions call.
+type ActionsFail struct {
+	Actions []ActionFail
+}
+
+// ServiceResource holds a service tag and the name of a resource of
+// the service.
+type ServiceResource struct {
+	Tag  string
+	Name string
+}
+
+// ServiceResources holds the parameters for making a Resource call.
+type ServiceResources struct {
+	Resources []ServiceResource
+}
+
+// ResourceResult holds the location, hash and revision of the content
+// uploaded for a resource, or an error.
+type ResourceResult struct {
+	Error    *Error
+	Revision int
+	URL      string
+	Sha256   string
+	Size     int64
+}
+
+// ResourceResults holds multiple resource results.
+type ResourceResults struct {
+	Results []ResourceResult
+}
+
+// StorageInstance describes a store of a unit, and the volume
+// provisioned for it.
+type StorageInstance struct {
+	Id          string
+	Name        string
+	Kind        string
+	Location    string
+	Size        uint64
+	Provisioned bool
+	Device      string
+	Attached    bool
+}
+
+// StorageInstancesResult holds the storage instances of a unit, or an
+// error.
+type StorageInstancesResult struct {
+	Error   *Error
+	Storage []StorageInstance
+}
+
+// StorageInstancesResults holds multiple storage instances results.
+type StorageInstancesResults struct {
+	Results []StorageInstancesResult
+}
+
+// StorageAttachment holds the id of a storage instance, and whether the
+// charm of its unit has been told that it is available.
+type StorageAttachment struct {
+	Id       string
+	Attached bool
+}
+
+// SetStorageAttached holds the parameters for making a
+// SetStorageAttached call.
+type SetStorageAttached struct {
+	Storage []StorageAttachment
+}
@@ -10,6 +10,9 @@ import (
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/constraints"
 	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/version"
+	"launchpad.net/loggo"
+	"time"
 )
 
 // ErrorResults holds the results of calling a bulk operation which
@@ -84,6 +87,13 @@ type ServiceSetCharm struct {
 	Force       bool
 }
 
+// StatusParams holds the parameters for the FullStatus call.
+type StatusParams struct {
+	// Patterns restricts the status to the services and units
+	// whose names match any of them.
+	Patterns []string
+}
+
 // ServiceExpose holds the parameters for making the ServiceExpose call.
 type ServiceExpose struct {
 	ServiceName string
@@ -117,6 +127,21 @@ type ServiceGetResults struct {
 	Constraints constraints.Value
 }
 
+// HookPolicy controls how the units of a service run their hooks.
+type HookPolicy struct {
+	// Timeout is how long a hook may run before it is killed. Hooks
+	// may run indefinitely when it is zero.
+	Timeout time.Duration
+	// Retry holds whether failed hooks are retried automatically,
+	// instead of waiting for the unit to be resolved.
+	Retry bool
+	// RetryDelay is the delay before the first retry of a failed hook.
+	// It doubles after every further failure, up to MaxRetryDelay.
+	// The uniter chooses the delays when they are zero.
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+}
+
 // ServiceUnexpose holds parameters for the ServiceUnexpose call.
 type ServiceUnexpose struct {
 	ServiceName string
@@ -128,6 +153,21 @@ type Resolved struct {
 	Retry    bool
 }
 
+// ContainerOperation holds the parameters for making the
+// ContainerOperation call. Snapshot names the snapshot taken or restored
+// by the "snapshot" and "restore" operations.
+type ContainerOperation struct {
+	MachineId string
+	Operation string
+	Snapshot  string
+}
+
+// ContainerOperationInfo holds the parameters for making the
+// ContainerOperationInfo call.
+type ContainerOperationInfo struct {
+	MachineId string
+}
+
 // ResolvedResults holds results of the Resolved call.
 type ResolvedResults struct {
 	Service  string
@@ -202,6 +242,173 @@ type CharmInfo struct {
 	CharmURL string
 }
 
+// AddLocalCharm holds the parameters for making the AddLocalCharm call.
+// Bundle holds the content of the charm bundle read by the client from
+// its local repository.
+type AddLocalCharm struct {
+	CharmURL string
+	Bundle   []byte
+}
+
+// ServiceSetResource holds the parameters for making the
+// ServiceSetResource call, which records the content uploaded for a
+// resource to the resources endpoint of the API server.
+type ServiceSetResource struct {
+	ServiceName string
+	Name        string
+	URL         string
+	Sha256      string
+	Size        int64
+}
+
+// ResourceUploadResult holds the result of an upload to the resources
+// endpoint of the API server: where the content was stored, its SHA256
+// digest and its size in bytes.
+type ResourceUploadResult struct {
+	URL    string
+	Sha256 string
+	Size   int64
+	Error  *Error
+}
+
+// ServiceGetCharmURL holds the parameters for making the
+// ServiceGetCharmURL call.
+type ServiceGetCharmURL struct {
+	ServiceName string
+}
+
+// ServiceCharmRelations holds the parameters for making the
+// ServiceCharmRelations call.
+type ServiceCharmRelations struct {
+	ServiceName string
+}
+
+// ServiceCharmRelationsResults holds the results of the
+// ServiceCharmRelations call.
+type ServiceCharmRelationsResults struct {
+	CharmRelations []string
+}
+
+// ServiceGetHookPolicy holds the parameters for making the
+// ServiceGetHookPolicy call.
+type ServiceGetHookPolicy struct {
+	ServiceName string
+}
+
+// ServiceSetHookPolicy holds the parameters for making the
+// ServiceSetHookPolicy call.
+type ServiceSetHookPolicy struct {
+	ServiceName string
+	Policy      HookPolicy
+}
+
+// AddMachineParams encapsulates the parameters used to create a new
+// machine. A new container is created on the machine ParentId when
+// ContainerType is set.
+type AddMachineParams struct {
+	Series        string
+	Constraints   constraints.Value
+	Jobs          []MachineJob
+	ParentId      string
+	ContainerType instance.ContainerType
+}
+
+// AddMachines holds the parameters for making the AddMachines call.
+type AddMachines struct {
+	MachineParams []AddMachineParams
+}
+
+// AddMachinesResult holds the id of a machine added by the AddMachines
+// call, or an error.
+type AddMachinesResult struct {
+	Machine string
+	Error   *Error
+}
+
+// AddMachinesResults holds the results of the AddMachines call.
+type AddMachinesResults struct {
+	Machines []AddMachinesResult
+}
+
+// DestroyMachines holds the parameters for making the DestroyMachines
+// call.
+type DestroyMachines struct {
+	MachineNames []string
+}
+
+// EnvironmentConfigResults holds the results of the EnvironmentConfig
+// call.
+type EnvironmentConfigResults struct {
+	Config map[string]interface{}
+}
+
+// SetEnvironmentConfig holds the parameters for making the
+// SetEnvironmentConfig call. The attributes are applied to the current
+// configuration.
+type SetEnvironmentConfig struct {
+	Config map[string]interface{}
+}
+
+// UpgradeJuju holds the parameters for making the UpgradeJuju call.
+// Version is the agent version the agents of the environment upgrade
+// to.
+type UpgradeJuju struct {
+	Version version.Number
+}
+
+// GetEnvironmentConstraintsResults holds the results of the
+// GetEnvironmentConstraints call.
+type GetEnvironmentConstraintsResults struct {
+	Constraints constraints.Value
+}
+
+// SetEnvironmentConstraints holds the parameters for making the
+// SetEnvironmentConstraints call.
+type SetEnvironmentConstraints struct {
+	Constraints constraints.Value
+}
+
+// PublicAddress holds the parameters for making the PublicAddress
+// call. Target is a machine id or a unit name.
+type PublicAddress struct {
+	Target string
+}
+
+// PublicAddressResults holds the results of the PublicAddress call.
+type PublicAddressResults struct {
+	PublicAddress string
+}
+
+// PrivateAddress holds the parameters for making the PrivateAddress
+// call. Target is a machine id or a unit name.
+type PrivateAddress struct {
+	Target string
+}
+
+// PrivateAddressResults holds the results of the PrivateAddress call.
+type PrivateAddressResults struct {
+	PrivateAddress string
+}
+
+// EnqueueAction holds the parameters for making the EnqueueAction
+// call. The parameters of the action are parsed according to the
+// actions of the charm of the unit.
+type EnqueueAction struct {
+	UnitName string
+	Action   string
+	Params   map[string]string
+}
+
+// EnqueueActionResults holds the results of the EnqueueAction call.
+type EnqueueActionResults struct {
+	Id string
+}
+
+// ActionInfo holds the parameters for making the ActionInfo call.
+type ActionInfo struct {
+	Id string
+}
+
 // AllWatcherId holds the id of an AllWatcher.
 type AllWatcherId struct {
 	AllWatcherId string
@@ -212,6 +419,116 @@ type AllWatcherNextResults struct {
 	Deltas []Delta
 }
 
+// DebugLog holds the parameters for making the WatchDebugLog call.
+// Entities are given by tag, or by patterns of tags such as
+// "unit-mysql-*"; modules include their submodules.
+type DebugLog struct {
+	IncludeEntity []string
+	ExcludeEntity []string
+	IncludeModule []string
+	ExcludeModule []string
+	Level         loggo.Level
+
+	// Backlog holds the number of the selected records already logged
+	// to send first.
+	Backlog int
+
+	// Replay specifies that all the selected records already logged
+	// are sent first, regardless of Backlog.
+	Replay bool
+}
+
+// DebugLogWatcherId holds the id of a DebugLogWatcher.
+type DebugLogWatcherId struct {
+	DebugLogWatcherId string
+}
+
+// LogRecord holds a log message written by an agent.
+type LogRecord struct {
+	Entity   string
+	Time     time.Time
+	Level    loggo.Level
+	Module   string
+	Location string
+	Message  string
+}
+
+// DebugLogNextResults holds the records returned from calling
+// DebugLogWatcher.Next().
+type DebugLogNextResults struct {
+	Records []LogRecord
+}
+
+// AddUser holds the parameters for making the AddUser call.
+type AddUser struct {
+	Name     string
+	Password string
+	// Access holds the access the user has to the environment:
+	// "read", "write" or "admin".
+	Access string
+}
+
+// UserName holds the name of a user.
+type UserName struct {
+	Name string
+}
+
+// SetUserPassword holds the parameters for making the
+// SetUserPassword call.
+type SetUserPassword struct {
+	Name     string
+	Password string
+}
+
+// UserInfo holds information about a user of the environment.
+// LastLogin is the zero time when the user never logged in.
+type UserInfo struct {
+	Name        string
+	Access      string
+	DateCreated time.Time
+	LastLogin   time.Time
+}
+
+// ListUsersResults holds the results of the ListUsers call.
+type ListUsersResults struct {
+	Users []UserInfo
+}
+
+// AuditLog holds the parameters for making the AuditLog call. Zero
+// fields select all entries.
+type AuditLog struct {
+	// Since selects the entries recorded at or after the given time.
+	Since time.Time
+
+	// User selects the entries of calls made by the named user.
+	User string
+
+	// Entity selects the entries of calls concerning the entity with
+	// the given tag.
+	Entity string
+
+	// Limit selects only the given number of most recent entries.
+	Limit int
+}
+
+// AuditEntry records a call made by a client to change the
+// environment. Args holds the arguments of the call encoded as JSON,
+// with secrets redacted, and Error the error returned by the call,
+// if any.
+type AuditEntry struct {
+	Time     time.Time
+	Tag      string
+	Method   string
+	Entities []string
+	Args     string
+	Error    string
+}
+
+// AuditLogResults holds the results of the AuditLog call.
+type AuditLogResults struct {
+	Entries []AuditEntry
+}
+
 // Delta holds details of a change to the environment.
 type Delta struct {
 	// If Removed is true, the entity has been removed;
@@ -361,6 +678,8 @@ type UnitInfo struct {
 	Ports          []instance.Port
 	Status         Status
 	StatusInfo     string
+	WorkloadStatus WorkloadStatus
+	WorkloadInfo   string
 }
 
 func (i *UnitInfo) EntityId() EntityId {
@@ -75,9 +75,11 @@ var marshalTestCases = []struct {
 			MachineId:      "1",
 			Status:         "error",
 			StatusInfo:     "foo",
+			WorkloadStatus: "blocked",
+			WorkloadInfo:   "bar",
 		},
 	},
-	json: `["unit", "change", {"CharmURL": "cs:~user/precise/wordpress-42", "MachineId": "1", "Series": "precise", "Name": "Benji", "PublicAddress": "testing.invalid", "Service": "Shazam", "PrivateAddress": "10.0.0.1", "Ports": [{"Protocol": "http", "Number": 80}], "Status": "error", "StatusInfo": "foo"}]`,
+	json: `["unit", "change", {"CharmURL": "cs:~user/precise/wordpress-42", "MachineId": "1", "Series": "precise", "Name": "Benji", "PublicAddress": "testing.invalid", "Service": "Shazam", "PrivateAddress": "10.0.0.1", "Ports": [{"Protocol": "http", "Number": 80}], "Status": "error", "StatusInfo": "foo", "WorkloadStatus": "blocked", "WorkloadInfo": "bar"}]`,
 }, {
 	about: "RelationInfo Delta",
 	value: params.Delta{
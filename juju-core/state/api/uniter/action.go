@@ -0,0 +1,65 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// Action represents an action queued on the unit of a uniter worker.
+type Action struct {
+	st     *State
+	id     string
+	name   string
+	params map[string]interface{}
+	status params.ActionStatus
+}
+
+// Id returns the id of the action.
+func (a *Action) Id() string {
+	return a.id
+}
+
+// Name returns the name of the action, as defined in the charm.
+func (a *Action) Name() string {
+	return a.name
+}
+
+// Params returns the parameters the action was queued with.
+func (a *Action) Params() map[string]interface{} {
+	return a.params
+}
+
+// Status returns the status of the action when it was fetched.
+func (a *Action) Status() params.ActionStatus {
+	return a.status
+}
+
+// Complete records the results of the action, which ran
+// successfully.
+func (a *Action) Complete(results map[string]interface{}) error {
+	var result params.ErrorResults
+	args := params.ActionsComplete{
+		Actions: []params.ActionComplete{{Id: a.id, Results: results}},
+	}
+	err := a.st.call("CompleteActions", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
+// Fail records the failure of the action, and the message explaining
+// it.
+func (a *Action) Fail(message string) error {
+	var result params.ErrorResults
+	args := params.ActionsFail{
+		Actions: []params.ActionFail{{Id: a.id, Message: message}},
+	}
+	err := a.st.call("FailActions", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
@@ -0,0 +1,52 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"net/url"
+
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// Charm represents the state of a charm in the environment, as seen by
+// a uniter worker.
+type Charm struct {
+	curl         *charm.URL
+	bundleURL    *url.URL
+	bundleSha256 string
+}
+
+func newCharm(curl *charm.URL, result params.CharmBundleResult) (*Charm, error) {
+	bundleURL, err := url.Parse(result.BundleURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Charm{
+		curl:         curl,
+		bundleURL:    bundleURL,
+		bundleSha256: result.BundleSha256,
+	}, nil
+}
+
+// String returns the charm URL as a string.
+func (c *Charm) String() string {
+	return c.curl.String()
+}
+
+// URL returns the URL that identifies the charm.
+func (c *Charm) URL() *charm.URL {
+	return c.curl
+}
+
+// BundleURL returns the URL from which the charm's bundle can be
+// downloaded.
+func (c *Charm) BundleURL() *url.URL {
+	return c.bundleURL
+}
+
+// BundleSha256 returns the SHA256 digest of the charm's bundle.
+func (c *Charm) BundleSha256() string {
+	return c.bundleSha256
+}
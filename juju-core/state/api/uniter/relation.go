@@ -0,0 +1,73 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// Relation represents a relation between one or two service
+// endpoints.
+type Relation struct {
+	st   *State
+	key  string
+	id   int
+	life params.Life
+	ep   Endpoint
+}
+
+func newRelation(st *State, result params.RelationResult) *Relation {
+	return &Relation{
+		st:   st,
+		key:  result.Key,
+		id:   result.Id,
+		life: result.Life,
+		ep:   Endpoint{result.Endpoint.Relation},
+	}
+}
+
+// String returns the relation as a string.
+func (r *Relation) String() string {
+	return r.key
+}
+
+// Id returns the integer internal relation key. This is exposed
+// because the unit agent needs to expose a value derived from this
+// (as JUJU_RELATION_ID) to allow relation hooks to differentiate
+// between relations with different services.
+func (r *Relation) Id() int {
+	return r.id
+}
+
+// Life returns the relation's current life state.
+func (r *Relation) Life() params.Life {
+	return r.life
+}
+
+// Endpoint returns the endpoint of the relation for the service the
+// uniter's managed unit belongs to.
+func (r *Relation) Endpoint() Endpoint {
+	return r.ep
+}
+
+// Refresh refreshes the contents of the relation from the underlying
+// state. It returns an error with code params.CodeNotFound if the
+// relation has been removed.
+func (r *Relation) Refresh() error {
+	result, err := r.st.relation(r.key)
+	if err != nil {
+		return err
+	}
+	r.life = result.Life
+	return nil
+}
+
+// Unit returns a RelationUnit for the supplied unit.
+func (r *Relation) Unit(u *Unit) *RelationUnit {
+	return &RelationUnit{
+		relation: r,
+		unit:     u,
+		st:       r.st,
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"fmt"
+
+	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/api/watcher"
+)
+
+// RelationUnit holds information about a single unit in a relation,
+// and allows clients to conveniently access unit-specific
+// functionality.
+type RelationUnit struct {
+	st       *State
+	relation *Relation
+	unit     *Unit
+}
+
+// Relation returns the relation associated with the unit.
+func (ru *RelationUnit) Relation() *Relation {
+	return ru.relation
+}
+
+// Endpoint returns the relation endpoint that defines the unit's
+// participation in the relation.
+func (ru *RelationUnit) Endpoint() Endpoint {
+	return ru.relation.Endpoint()
+}
+
+// args returns the parameters identifying the relation unit.
+func (ru *RelationUnit) args() params.RelationUnits {
+	return params.RelationUnits{
+		RelationUnits: []params.RelationUnit{{
+			Relation: ru.relation.key,
+			Unit:     ru.unit.tag,
+		}},
+	}
+}
+
+// EnterScope ensures that the unit has entered its scope in the
+// relation, with its private address as its initial relation
+// settings. When the unit has already entered its relation scope,
+// EnterScope will report success but make no changes to state.
+//
+// An error with code params.CodeCannotEnterScope is returned if the
+// relation or the unit is not alive, and one with code
+// params.CodeCannotEnterScopeYet if a subordinate unit is still
+// leaving the unit's container.
+func (ru *RelationUnit) EnterScope() error {
+	var result params.ErrorResults
+	err := ru.st.call("EnterScope", ru.args(), &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
+// LeaveScope signals that the unit has left its scope in the relation.
+// After the unit has left its relation scope, it is no longer a member
+// of the relation; if the relation is dying when its last member unit
+// leaves, it is removed immediately. It is not an error to leave a
+// scope that the unit is not, or never was, a member of.
+func (ru *RelationUnit) LeaveScope() error {
+	var result params.ErrorResults
+	err := ru.st.call("LeaveScope", ru.args(), &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
+// IngressAddress returns the address the other units of the relation
+// should use to reach the unit, or the empty string if no suitable
+// address is known.
+func (ru *RelationUnit) IngressAddress() (string, error) {
+	var results params.StringResults
+	err := ru.st.call("IngressAddress", ru.args(), &results)
+	if err != nil {
+		return "", err
+	}
+	if len(results.Results) != 1 {
+		return "", fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return result.Result, nil
+}
+
+// Settings returns a Settings which allows access to the unit's
+// settings within the relation.
+func (ru *RelationUnit) Settings() (*Settings, error) {
+	var results params.RelationSettingsResults
+	err := ru.st.call("ReadSettings", ru.args(), &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return newSettings(ru.st, ru.relation.key, ru.unit.tag, result.Settings), nil
+}
+
+// ReadSettings returns a map holding the settings of the unit with the
+// supplied name within this relation. An error will be returned if the
+// relation no longer exists, or if the unit's service is not part of
+// the relation, or the settings are invalid; but mere non-existence of
+// the unit is not grounds for an error, because the unit settings are
+// guaranteed to persist for the lifetime of the relation, regardless
+// of the lifetime of the unit.
+func (ru *RelationUnit) ReadSettings(uname string) (map[string]interface{}, error) {
+	var results params.RelationSettingsResults
+	args := params.RelationUnitPairs{
+		RelationUnitPairs: []params.RelationUnitPair{{
+			Relation:   ru.relation.key,
+			LocalUnit:  ru.unit.tag,
+			RemoteUnit: names.UnitTag(uname),
+		}},
+	}
+	err := ru.st.call("ReadRemoteSettings", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Settings, nil
+}
+
+// Watch returns a watcher that notifies of changes to counterpart
+// units in the relation.
+func (ru *RelationUnit) Watch() (*watcher.RelationUnitsWatcher, error) {
+	var results params.RelationUnitsWatchResults
+	err := ru.st.call("WatchRelationUnits", ru.args(), &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	w := watcher.NewRelationUnitsWatcher(ru.st.caller, result)
+	return w, nil
+}
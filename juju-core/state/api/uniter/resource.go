@@ -0,0 +1,60 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"net/url"
+
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// Resource represents the content uploaded for a resource of the
+// service of a uniter worker.
+type Resource struct {
+	name     string
+	revision int
+	url      *url.URL
+	sha256   string
+	size     int64
+}
+
+func newResource(name string, result params.ResourceResult) (*Resource, error) {
+	u, err := url.Parse(result.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &Resource{
+		name:     name,
+		revision: result.Revision,
+		url:      u,
+		sha256:   result.Sha256,
+		size:     result.Size,
+	}, nil
+}
+
+// Name returns the name of the resource, as declared by the charm.
+func (r *Resource) Name() string {
+	return r.name
+}
+
+// Revision returns the revision of the content of the resource.
+func (r *Resource) Revision() int {
+	return r.revision
+}
+
+// URL returns the URL from which the content of the resource can be
+// downloaded.
+func (r *Resource) URL() *url.URL {
+	return r.url
+}
+
+// Sha256 returns the SHA256 digest of the content of the resource.
+func (r *Resource) Sha256() string {
+	return r.sha256
+}
+
+// Size returns the size of the content of the resource, in bytes.
+func (r *Resource) Size() int64 {
+	return r.size
+}
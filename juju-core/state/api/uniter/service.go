@@ -0,0 +1,205 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"fmt"
+
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/api/watcher"
+)
+
+// Service represents the state of a service as seen by a uniter
+// worker.
+type Service struct {
+	tag  string
+	life params.Life
+	st   *State
+}
+
+// Tag returns the service's tag.
+func (s *Service) Tag() string {
+	return s.tag
+}
+
+// Name returns the service name.
+func (s *Service) Name() string {
+	_, name, err := names.ParseTag(s.tag, names.ServiceTagKind)
+	if err != nil {
+		panic(err)
+	}
+	return name
+}
+
+// String returns the service as a string.
+func (s *Service) String() string {
+	return s.Name()
+}
+
+// Life returns the service's current life state.
+func (s *Service) Life() params.Life {
+	return s.life
+}
+
+// Refresh refreshes the contents of the Service from the underlying
+// state.
+func (s *Service) Refresh() error {
+	life, err := s.st.life(s.tag)
+	if err != nil {
+		return err
+	}
+	s.life = life
+	return nil
+}
+
+// notifyWatch invokes the named watching method with the service as
+// its only argument, and returns the resulting watcher.
+func (s *Service) notifyWatch(method string) (*watcher.NotifyWatcher, error) {
+	var results params.NotifyWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: s.tag}},
+	}
+	err := s.st.call(method, args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	w := watcher.NewNotifyWatcher(s.st.caller, result)
+	return w, nil
+}
+
+// Watch returns a watcher for observing changes to a service.
+func (s *Service) Watch() (*watcher.NotifyWatcher, error) {
+	return s.notifyWatch("Watch")
+}
+
+// WatchRelations returns a watcher for observing the keys of the
+// relations of the service.
+func (s *Service) WatchRelations() (*watcher.StringsWatcher, error) {
+	var results params.StringsWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: s.tag}},
+	}
+	err := s.st.call("WatchServiceRelations", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	w := watcher.NewStringsWatcher(s.st.caller, result)
+	return w, nil
+}
+
+// WatchLeadership returns a watcher for observing changes to the
+// leadership of the service.
+func (s *Service) WatchLeadership() (*watcher.NotifyWatcher, error) {
+	return s.notifyWatch("WatchLeadership")
+}
+
+// WatchLeaderSettings returns a watcher for observing changes to the
+// settings written by the leader of the service.
+func (s *Service) WatchLeaderSettings() (*watcher.NotifyWatcher, error) {
+	return s.notifyWatch("WatchLeaderSettings")
+}
+
+// CharmURL returns the service's charm URL, and whether units should
+// upgrade to the charm with that URL even if they are in an error
+// state.
+func (s *Service) CharmURL() (*charm.URL, bool, error) {
+	var results params.StringBoolResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: s.tag}},
+	}
+	err := s.st.call("CharmURL", args, &results)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(results.Results) != 1 {
+		return nil, false, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	curl, err := charm.ParseURL(result.Result)
+	if err != nil {
+		return nil, false, err
+	}
+	return curl, result.Ok, nil
+}
+
+// Resource returns the content last uploaded for the named resource of
+// the service.
+func (s *Service) Resource(name string) (*Resource, error) {
+	var results params.ResourceResults
+	args := params.ServiceResources{
+		Resources: []params.ServiceResource{{Tag: s.tag, Name: name}},
+	}
+	err := s.st.call("Resource", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return newResource(name, result)
+}
+
+// LeaderSettings returns the settings written by the leader of the
+// service.
+func (s *Service) LeaderSettings() (map[string]string, error) {
+	var results params.LeaderSettingsResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: s.tag}},
+	}
+	err := s.st.call("LeaderSettings", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Settings, nil
+}
+
+// HookPolicy returns how the units of the service run their hooks.
+func (s *Service) HookPolicy() (params.HookPolicy, error) {
+	var results params.HookPolicyResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: s.tag}},
+	}
+	err := s.st.call("HookPolicy", args, &results)
+	if err != nil {
+		return params.HookPolicy{}, err
+	}
+	if len(results.Results) != 1 {
+		return params.HookPolicy{}, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.HookPolicy{}, result.Error
+	}
+	return result.Policy, nil
+}
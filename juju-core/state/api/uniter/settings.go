@@ -0,0 +1,85 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// Settings manages changes to the relation settings of a unit. Changes
+// are kept locally until Write is called.
+type Settings struct {
+	st          *State
+	relationKey string
+	unitTag     string
+	settings    params.RelationSettings
+	// changes holds the keys set or deleted since the last Write;
+	// deleted keys have nil values.
+	changes params.RelationSettings
+}
+
+func newSettings(st *State, relationKey, unitTag string, settings params.RelationSettings) *Settings {
+	if settings == nil {
+		settings = make(params.RelationSettings)
+	}
+	return &Settings{
+		st:          st,
+		relationKey: relationKey,
+		unitTag:     unitTag,
+		settings:    settings,
+		changes:     make(params.RelationSettings),
+	}
+}
+
+// Map returns all keys and values of the node.
+func (s *Settings) Map() map[string]interface{} {
+	settingsCopy := make(map[string]interface{})
+	for k, v := range s.settings {
+		settingsCopy[k] = v
+	}
+	return settingsCopy
+}
+
+// Get returns the value of key and whether it was found.
+func (s *Settings) Get(key string) (value interface{}, found bool) {
+	value, found = s.settings[key]
+	return
+}
+
+// Set sets key to value.
+func (s *Settings) Set(key string, value interface{}) {
+	s.settings[key] = value
+	s.changes[key] = value
+}
+
+// Delete removes key.
+func (s *Settings) Delete(key string) {
+	delete(s.settings, key)
+	s.changes[key] = nil
+}
+
+// Write writes changes made to s back onto its node. Deleted keys
+// are removed, others are updated to their new values.
+func (s *Settings) Write() error {
+	if len(s.changes) == 0 {
+		return nil
+	}
+	var result params.ErrorResults
+	args := params.RelationUnitsSettings{
+		RelationUnits: []params.RelationUnitSettings{{
+			Relation: s.relationKey,
+			Unit:     s.unitTag,
+			Settings: s.changes,
+		}},
+	}
+	err := s.st.call("UpdateSettings", args, &result)
+	if err != nil {
+		return err
+	}
+	if err := result.OneError(); err != nil {
+		return err
+	}
+	s.changes = make(params.RelationSettings)
+	return nil
+}
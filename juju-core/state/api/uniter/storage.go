@@ -0,0 +1,76 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// StorageInstance represents a store of the unit of a uniter worker.
+type StorageInstance struct {
+	st  *State
+	doc params.StorageInstance
+}
+
+// Id returns the id of the storage instance, such as "data/0".
+func (s *StorageInstance) Id() string {
+	return s.doc.Id
+}
+
+// Name returns the name of the store, as declared by the charm.
+func (s *StorageInstance) Name() string {
+	return s.doc.Name
+}
+
+// Kind returns how the store is presented to the charm.
+func (s *StorageInstance) Kind() charm.StorageType {
+	return charm.StorageType(s.doc.Kind)
+}
+
+// Location returns the path at which a filesystem store is mounted.
+func (s *StorageInstance) Location() string {
+	return s.doc.Location
+}
+
+// Size returns the minimum size of the store, in MiB.
+func (s *StorageInstance) Size() uint64 {
+	return s.doc.Size
+}
+
+// Provisioned returns whether a volume had been provisioned for the
+// store when it was fetched.
+func (s *StorageInstance) Provisioned() bool {
+	return s.doc.Provisioned
+}
+
+// Device returns the path of the block device of the volume
+// provisioned for the store.
+func (s *StorageInstance) Device() string {
+	return s.doc.Device
+}
+
+// Attached returns whether the charm had been told that the store is
+// available when it was fetched.
+func (s *StorageInstance) Attached() bool {
+	return s.doc.Attached
+}
+
+// SetAttached records whether the charm has been told that the store
+// is available.
+func (s *StorageInstance) SetAttached(attached bool) error {
+	var result params.ErrorResults
+	args := params.SetStorageAttached{
+		Storage: []params.StorageAttachment{{Id: s.doc.Id, Attached: attached}},
+	}
+	err := s.st.call("SetStorageAttached", args, &result)
+	if err != nil {
+		return err
+	}
+	if err := result.OneError(); err != nil {
+		return err
+	}
+	s.doc.Attached = attached
+	return nil
+}
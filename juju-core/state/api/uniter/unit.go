@@ -4,12 +4,21 @@
 package uniter
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/names"
 	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/api/watcher"
 )
 
+// ErrNoCharmURLSet is returned by Unit.CharmURL when the unit has not
+// yet deployed a charm.
+var ErrNoCharmURLSet = errors.New("unit has no charm url set")
+
 // Unit represents a juju unit as seen by a uniter worker.
 type Unit struct {
 	tag  string
@@ -22,6 +31,30 @@ func (u *Unit) Tag() string {
 	return u.tag
 }
 
+// Name returns the name of the unit.
+func (u *Unit) Name() string {
+	_, name, err := names.ParseTag(u.tag, names.UnitTagKind)
+	if err != nil {
+		panic(err)
+	}
+	return name
+}
+
+// String returns the unit as a string.
+func (u *Unit) String() string {
+	return u.Name()
+}
+
+// ServiceName returns the name of the unit's service.
+func (u *Unit) ServiceName() string {
+	return strings.Split(u.Name(), "/")[0]
+}
+
+// ServiceTag returns the tag of the unit's service.
+func (u *Unit) ServiceTag() string {
+	return names.ServiceTag(u.ServiceName())
+}
+
 // Life returns the unit's lifecycle value.
 func (u *Unit) Life() params.Life {
 	return u.life
@@ -29,7 +62,7 @@ func (u *Unit) Life() params.Life {
 
 // Refresh updates the cached local copy of the unit's data.
 func (u *Unit) Refresh() error {
-	life, err := u.st.unitLife(u.tag)
+	life, err := u.st.life(u.tag)
 	if err != nil {
 		return err
 	}
@@ -37,6 +70,62 @@ func (u *Unit) Refresh() error {
 	return nil
 }
 
+// entityCall invokes the named method with the unit as its only
+// argument, and returns the error reported for the unit.
+func (u *Unit) entityCall(method string) error {
+	var result params.ErrorResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	err := u.st.call(method, args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
+// stringBoolCall invokes the named method with the unit as its only
+// argument, and returns the string and boolean reported for the unit.
+func (u *Unit) stringBoolCall(method string) (string, bool, error) {
+	var results params.StringBoolResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	err := u.st.call(method, args, &results)
+	if err != nil {
+		return "", false, err
+	}
+	if len(results.Results) != 1 {
+		return "", false, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return "", false, result.Error
+	}
+	return result.Result, result.Ok, nil
+}
+
+// boolCall invokes the named method with the unit as its only
+// argument, and returns the boolean reported for the unit.
+func (u *Unit) boolCall(method string) (bool, error) {
+	var results params.BoolResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	err := u.st.call(method, args, &results)
+	if err != nil {
+		return false, err
+	}
+	if len(results.Results) != 1 {
+		return false, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.Result, nil
+}
+
 // SetStatus sets the status of the unit.
 func (u *Unit) SetStatus(status params.Status, info string) error {
 	var result params.ErrorResults
@@ -45,7 +134,7 @@ func (u *Unit) SetStatus(status params.Status, info string) error {
 			{Tag: u.tag, Status: status, Info: info},
 		},
 	}
-	err := u.st.caller.Call("Uniter", "", "SetStatus", args, &result)
+	err := u.st.call("SetStatus", args, &result)
 	if err != nil {
 		return err
 	}
@@ -55,24 +144,239 @@ func (u *Unit) SetStatus(status params.Status, info string) error {
 // EnsureDead sets the unit lifecycle to Dead if it is Alive or
 // Dying. It does nothing otherwise.
 func (u *Unit) EnsureDead() error {
+	return u.entityCall("EnsureDead")
+}
+
+// Watch returns a watcher for observing changes to the unit.
+func (u *Unit) Watch() (*watcher.NotifyWatcher, error) {
+	var results params.NotifyWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	err := u.st.call("Watch", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	w := watcher.NewNotifyWatcher(u.st.caller, result)
+	return w, nil
+}
+
+// Service returns the service of the unit.
+func (u *Unit) Service() (*Service, error) {
+	return u.st.Service(u.ServiceTag())
+}
+
+// PublicAddress returns the public address of the unit and whether it
+// is valid.
+func (u *Unit) PublicAddress() (string, bool, error) {
+	return u.stringBoolCall("PublicAddress")
+}
+
+// PrivateAddress returns the private address of the unit and whether
+// it is valid.
+func (u *Unit) PrivateAddress() (string, bool, error) {
+	return u.stringBoolCall("PrivateAddress")
+}
+
+// NetworkAddresses returns the addresses of the machine the unit is
+// assigned to.
+func (u *Unit) NetworkAddresses() ([]instance.Address, error) {
+	var results params.AddressesResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	err := u.st.call("NetworkAddresses", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Addresses, nil
+}
+
+// setAddress invokes the named method to set an address of the unit.
+func (u *Unit) setAddress(method, address string) error {
 	var result params.ErrorResults
+	args := params.SetEntityAddresses{
+		Entities: []params.SetEntityAddress{
+			{Tag: u.tag, Address: address},
+		},
+	}
+	err := u.st.call(method, args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
+// SetPublicAddress sets the public address of the unit.
+func (u *Unit) SetPublicAddress(address string) error {
+	return u.setAddress("SetPublicAddress", address)
+}
+
+// SetPrivateAddress sets the private address of the unit.
+func (u *Unit) SetPrivateAddress(address string) error {
+	return u.setAddress("SetPrivateAddress", address)
+}
+
+// Resolved returns the resolved mode for the unit.
+func (u *Unit) Resolved() (params.ResolvedMode, error) {
+	var results params.ResolvedModeResults
 	args := params.Entities{
 		Entities: []params.Entity{{Tag: u.tag}},
 	}
-	err := u.st.caller.Call("Uniter", "", "EnsureDead", args, &result)
+	err := u.st.call("Resolved", args, &results)
+	if err != nil {
+		return "", err
+	}
+	if len(results.Results) != 1 {
+		return "", fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return result.Mode, nil
+}
+
+// ClearResolved removes any resolved setting on the unit.
+func (u *Unit) ClearResolved() error {
+	return u.entityCall("ClearResolved")
+}
+
+// IsPrincipal returns whether the unit is deployed in its own
+// container, and can therefore have subordinate services deployed
+// alongside it.
+func (u *Unit) IsPrincipal() (bool, error) {
+	_, isSubordinate, err := u.stringBoolCall("GetPrincipal")
+	if err != nil {
+		return false, err
+	}
+	return !isSubordinate, nil
+}
+
+// HasSubordinates returns whether the unit has any subordinate units.
+func (u *Unit) HasSubordinates() (bool, error) {
+	return u.boolCall("HasSubordinates")
+}
+
+// Destroy, when called on a Alive unit, advances its lifecycle as far
+// as possible; it otherwise has no effect.
+func (u *Unit) Destroy() error {
+	return u.entityCall("Destroy")
+}
+
+// DestroyAllSubordinates destroys all subordinates of the unit.
+func (u *Unit) DestroyAllSubordinates() error {
+	return u.entityCall("DestroyAllSubordinates")
+}
+
+// CharmURL returns the charm URL this unit is currently using, or
+// ErrNoCharmURLSet if it has none.
+func (u *Unit) CharmURL() (*charm.URL, error) {
+	curl, ok, err := u.stringBoolCall("CharmURL")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNoCharmURLSet
+	}
+	return charm.ParseURL(curl)
+}
+
+// SetCharmURL marks the unit as currently using the supplied charm
+// URL. An error will be returned if the unit is dead, or the charm URL
+// not known.
+func (u *Unit) SetCharmURL(curl *charm.URL) error {
+	if curl == nil {
+		return fmt.Errorf("charm URL cannot be nil")
+	}
+	var result params.ErrorResults
+	args := params.EntitiesCharmURL{
+		Entities: []params.EntityCharmURL{
+			{Tag: u.tag, CharmURL: curl.String()},
+		},
+	}
+	err := u.st.call("SetCharmURL", args, &result)
 	if err != nil {
 		return err
 	}
 	return result.OneError()
 }
 
-// Watch returns a watcher for observing changes to the unit.
-func (u *Unit) Watch() (*watcher.NotifyWatcher, error) {
+// portCall invokes the named method to open or close a port of the
+// unit.
+func (u *Unit) portCall(method, protocol string, number int) error {
+	var result params.ErrorResults
+	args := params.EntitiesPorts{
+		Entities: []params.EntityPort{
+			{Tag: u.tag, Protocol: protocol, Port: number},
+		},
+	}
+	err := u.st.call(method, args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
+// OpenPort sets the policy of the port with protocol and number to be
+// opened.
+func (u *Unit) OpenPort(protocol string, number int) error {
+	return u.portCall("OpenPort", protocol, number)
+}
+
+// ClosePort sets the policy of the port with protocol and number to
+// be closed.
+func (u *Unit) ClosePort(protocol string, number int) error {
+	return u.portCall("ClosePort", protocol, number)
+}
+
+// ConfigSettings returns the complete set of service charm config
+// settings available to the unit. Unset values will be replaced with
+// the default value for the associated option, and may thus be nil
+// when no default is specified.
+func (u *Unit) ConfigSettings() (charm.Settings, error) {
+	var results params.ConfigSettingsResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	err := u.st.call("ConfigSettings", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return charm.Settings(result.Settings), nil
+}
+
+// WatchConfigSettings returns a watcher for observing changes to the
+// unit's service configuration settings. The unit must have a charm
+// URL set before this method is called, and the returned watcher will
+// be valid only while the unit's charm URL is not changed.
+func (u *Unit) WatchConfigSettings() (*watcher.NotifyWatcher, error) {
 	var results params.NotifyWatchResults
 	args := params.Entities{
 		Entities: []params.Entity{{Tag: u.tag}},
 	}
-	err := u.st.caller.Call("Uniter", "", "Watch", args, &results)
+	err := u.st.call("WatchConfigSettings", args, &results)
 	if err != nil {
 		return nil, err
 	}
@@ -86,3 +390,136 @@ func (u *Unit) Watch() (*watcher.NotifyWatcher, error) {
 	w := watcher.NewNotifyWatcher(u.st.caller, result)
 	return w, nil
 }
+
+// WatchActions returns a watcher for observing the ids of the actions
+// queued on the unit.
+func (u *Unit) WatchActions() (*watcher.StringsWatcher, error) {
+	var results params.StringsWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	err := u.st.call("WatchActions", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	w := watcher.NewStringsWatcher(u.st.caller, result)
+	return w, nil
+}
+
+// StorageInstances returns the unit's storage instances, ordered by id.
+func (u *Unit) StorageInstances() ([]*StorageInstance, error) {
+	var results params.StorageInstancesResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	err := u.st.call("StorageInstances", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	stores := make([]*StorageInstance, len(result.Storage))
+	for i, doc := range result.Storage {
+		stores[i] = &StorageInstance{st: u.st, doc: doc}
+	}
+	return stores, nil
+}
+
+// WatchStorageInstances returns a watcher for observing changes to the
+// unit's storage instances.
+func (u *Unit) WatchStorageInstances() (*watcher.NotifyWatcher, error) {
+	var results params.NotifyWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	err := u.st.call("WatchStorageInstances", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	w := watcher.NewNotifyWatcher(u.st.caller, result)
+	return w, nil
+}
+
+// WorkloadStatus returns the status of the unit's workload, as last
+// reported by its charm, and the message explaining it.
+func (u *Unit) WorkloadStatus() (params.WorkloadStatus, string, error) {
+	var results params.WorkloadStatusResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag}},
+	}
+	err := u.st.call("WorkloadStatus", args, &results)
+	if err != nil {
+		return "", "", err
+	}
+	if len(results.Results) != 1 {
+		return "", "", fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return "", "", result.Error
+	}
+	return result.Status, result.Info, nil
+}
+
+// SetWorkloadStatus records the status of the unit's workload and a
+// message explaining it.
+func (u *Unit) SetWorkloadStatus(status params.WorkloadStatus, info string) error {
+	var result params.ErrorResults
+	args := params.SetWorkloadStatus{
+		Entities: []params.SetEntityWorkloadStatus{
+			{Tag: u.tag, Status: status, Info: info},
+		},
+	}
+	err := u.st.call("SetWorkloadStatus", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
+// IsLeader returns whether the unit leads its service.
+func (u *Unit) IsLeader() (bool, error) {
+	return u.boolCall("IsLeader")
+}
+
+// ClaimLeadership makes the unit the leader of its service if the
+// service has no live leader, and returns whether it leads the service.
+func (u *Unit) ClaimLeadership() (bool, error) {
+	return u.boolCall("ClaimLeadership")
+}
+
+// SetLeaderSettings updates the settings the unit's service shares
+// with its followers; the unit must lead the service. Settings set to
+// the empty string are removed.
+func (u *Unit) SetLeaderSettings(settings map[string]string) error {
+	var result params.ErrorResults
+	args := params.SetLeaderSettings{
+		Entities: []params.EntityLeaderSettings{
+			{Tag: u.tag, Settings: settings},
+		},
+	}
+	err := u.st.call("SetLeaderSettings", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
@@ -6,6 +6,7 @@ package uniter
 import (
 	"fmt"
 
+	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/state/api/common"
 	"launchpad.net/juju-core/state/api/params"
 )
@@ -13,20 +14,27 @@ import (
 // State provides access to the Uniter API facade.
 type State struct {
 	caller common.Caller
+	// unitTag contains the authenticated unit's tag.
+	unitTag string
 }
 
 // NewState creates a new client-side Uniter facade.
-func NewState(caller common.Caller) *State {
-	return &State{caller}
+func NewState(caller common.Caller, authTag string) *State {
+	return &State{caller, authTag}
 }
 
-// unitLife requests the lifecycle of the given unit from the server.
-func (st *State) unitLife(tag string) (params.Life, error) {
+// call invokes the named method of the Uniter facade.
+func (st *State) call(method string, args, result interface{}) error {
+	return st.caller.Call("Uniter", "", method, args, result)
+}
+
+// life requests the lifecycle of the given entity from the server.
+func (st *State) life(tag string) (params.Life, error) {
 	var result params.LifeResults
 	args := params.Entities{
 		Entities: []params.Entity{{Tag: tag}},
 	}
-	err := st.caller.Call("Uniter", "", "Life", args, &result)
+	err := st.call("Life", args, &result)
 	if err != nil {
 		return "", err
 	}
@@ -39,9 +47,31 @@ func (st *State) unitLife(tag string) (params.Life, error) {
 	return result.Results[0].Life, nil
 }
 
+// relation requests the details of the relation with the given key,
+// as seen by the authenticated unit, from the server.
+func (st *State) relation(relationKey string) (params.RelationResult, error) {
+	var result params.RelationResults
+	args := params.RelationUnits{
+		RelationUnits: []params.RelationUnit{
+			{Relation: relationKey, Unit: st.unitTag},
+		},
+	}
+	err := st.call("Relation", args, &result)
+	if err != nil {
+		return params.RelationResult{}, err
+	}
+	if len(result.Results) != 1 {
+		return params.RelationResult{}, fmt.Errorf("expected one result, got %d", len(result.Results))
+	}
+	if err := result.Results[0].Error; err != nil {
+		return params.RelationResult{}, err
+	}
+	return result.Results[0], nil
+}
+
 // Unit provides access to methods of a state.Unit through the facade.
 func (st *State) Unit(tag string) (*Unit, error) {
-	life, err := st.unitLife(tag)
+	life, err := st.life(tag)
 	if err != nil {
 		return nil, err
 	}
@@ -51,3 +81,126 @@ func (st *State) Unit(tag string) (*Unit, error) {
 		st:   st,
 	}, nil
 }
+
+// Service returns a service state by tag.
+func (st *State) Service(tag string) (*Service, error) {
+	life, err := st.life(tag)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		tag:  tag,
+		life: life,
+		st:   st,
+	}, nil
+}
+
+// Charm returns the charm with the given URL.
+func (st *State) Charm(curl *charm.URL) (*Charm, error) {
+	if curl == nil {
+		return nil, fmt.Errorf("charm url cannot be nil")
+	}
+	var results params.CharmBundleResults
+	args := params.CharmURLs{
+		URLs: []params.CharmURL{{URL: curl.String()}},
+	}
+	err := st.call("CharmBundles", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return newCharm(curl, result)
+}
+
+// Relation returns the existing relation with the given key.
+func (st *State) Relation(relationKey string) (*Relation, error) {
+	result, err := st.relation(relationKey)
+	if err != nil {
+		return nil, err
+	}
+	return newRelation(st, result), nil
+}
+
+// RelationById returns the existing relation with the given id.
+func (st *State) RelationById(id int) (*Relation, error) {
+	var results params.RelationResults
+	args := params.RelationIds{
+		RelationIds: []int{id},
+	}
+	err := st.call("RelationById", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return newRelation(st, result), nil
+}
+
+// Action returns the action with the given id, which must be queued on
+// the authenticated unit.
+func (st *State) Action(id string) (*Action, error) {
+	var results params.ActionResults
+	args := params.ActionIds{
+		Ids: []string{id},
+	}
+	err := st.call("Actions", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected one result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &Action{
+		id:     id,
+		name:   result.Name,
+		params: result.Params,
+		status: result.Status,
+		st:     st,
+	}, nil
+}
+
+// EnvironUUID returns the universally unique identifier of the
+// environment.
+func (st *State) EnvironUUID() (string, error) {
+	var result params.StringResult
+	err := st.call("EnvironUUID", nil, &result)
+	if err != nil {
+		return "", err
+	}
+	return result.Result, nil
+}
+
+// ProviderType returns the type of the provider of the environment.
+func (st *State) ProviderType() (string, error) {
+	var result params.StringResult
+	err := st.call("ProviderType", nil, &result)
+	if err != nil {
+		return "", err
+	}
+	return result.Result, nil
+}
+
+// APIAddresses returns the addresses of the API servers.
+func (st *State) APIAddresses() ([]string, error) {
+	var result params.StringsResult
+	err := st.call("APIAddresses", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Result, nil
+}
@@ -4,11 +4,14 @@
 package uniter_test
 
 import (
+	"net/url"
 	stdtesting "testing"
+	"time"
 
 	gc "launchpad.net/gocheck"
 
 	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/juju/testing"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api"
@@ -163,3 +166,296 @@ func (s *uniterSuite) TestWatch(c *gc.C) {
 	statetesting.AssertStop(c, w)
 	wc.AssertClosed()
 }
+
+func (s *uniterSuite) TestAddresses(c *gc.C) {
+	unit, err := s.uniter.Unit("unit-wordpress-0")
+	c.Assert(err, gc.IsNil)
+
+	address, ok, err := unit.PublicAddress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(ok, gc.Equals, false)
+	c.Assert(address, gc.Equals, "")
+
+	err = unit.SetPublicAddress("1.2.3.4")
+	c.Assert(err, gc.IsNil)
+	err = unit.SetPrivateAddress("4.3.2.1")
+	c.Assert(err, gc.IsNil)
+
+	address, ok, err = unit.PublicAddress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(ok, gc.Equals, true)
+	c.Assert(address, gc.Equals, "1.2.3.4")
+	address, ok, err = unit.PrivateAddress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(ok, gc.Equals, true)
+	c.Assert(address, gc.Equals, "4.3.2.1")
+}
+
+func (s *uniterSuite) TestNetworkAddresses(c *gc.C) {
+	unit, err := s.uniter.Unit("unit-wordpress-0")
+	c.Assert(err, gc.IsNil)
+
+	addresses, err := unit.NetworkAddresses()
+	c.Assert(err, gc.IsNil)
+	c.Assert(addresses, gc.HasLen, 0)
+
+	expect := []instance.Address{instance.NewScopedAddress("10.0.0.2")}
+	err = s.machine.SetMachineAddresses(expect)
+	c.Assert(err, gc.IsNil)
+	addresses, err = unit.NetworkAddresses()
+	c.Assert(err, gc.IsNil)
+	c.Assert(addresses, gc.DeepEquals, expect)
+}
+
+func (s *uniterSuite) TestIngressAddress(c *gc.C) {
+	_, err := s.State.AddService("mysql", s.AddTestingCharm(c, "mysql"))
+	c.Assert(err, gc.IsNil)
+	eps, err := s.State.InferEndpoints([]string{"wordpress", "mysql"})
+	c.Assert(err, gc.IsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, gc.IsNil)
+	apiRel, err := s.uniter.Relation(rel.String())
+	c.Assert(err, gc.IsNil)
+	unit, err := s.uniter.Unit("unit-wordpress-0")
+	c.Assert(err, gc.IsNil)
+	ru := apiRel.Unit(unit)
+
+	address, err := ru.IngressAddress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(address, gc.Equals, "")
+
+	err = s.machine.SetMachineAddresses([]instance.Address{
+		instance.NewScopedAddress("127.0.0.1"),
+		instance.NewScopedAddress("10.0.0.2"),
+	})
+	c.Assert(err, gc.IsNil)
+	address, err = ru.IngressAddress()
+	c.Assert(err, gc.IsNil)
+	c.Assert(address, gc.Equals, "10.0.0.2")
+}
+
+func (s *uniterSuite) TestCharmURL(c *gc.C) {
+	unit, err := s.uniter.Unit("unit-wordpress-0")
+	c.Assert(err, gc.IsNil)
+
+	curl, err := unit.CharmURL()
+	c.Assert(err, gc.Equals, uniter.ErrNoCharmURLSet)
+	c.Assert(curl, gc.IsNil)
+
+	service, err := unit.Service()
+	c.Assert(err, gc.IsNil)
+	c.Assert(service.Name(), gc.Equals, "wordpress")
+	curl, force, err := service.CharmURL()
+	c.Assert(err, gc.IsNil)
+	c.Assert(force, gc.Equals, false)
+	expectCurl, _ := s.service.CharmURL()
+	c.Assert(curl, gc.DeepEquals, expectCurl)
+
+	err = unit.SetCharmURL(curl)
+	c.Assert(err, gc.IsNil)
+	unitCurl, err := unit.CharmURL()
+	c.Assert(err, gc.IsNil)
+	c.Assert(unitCurl, gc.DeepEquals, curl)
+
+	sch, err := s.State.Charm(curl)
+	c.Assert(err, gc.IsNil)
+	ch, err := s.uniter.Charm(curl)
+	c.Assert(err, gc.IsNil)
+	c.Assert(ch.URL(), gc.DeepEquals, curl)
+	c.Assert(ch.BundleURL(), gc.DeepEquals, sch.BundleURL())
+	c.Assert(ch.BundleSha256(), gc.Equals, sch.BundleSha256())
+}
+
+func (s *uniterSuite) TestHookPolicy(c *gc.C) {
+	unit, err := s.uniter.Unit("unit-wordpress-0")
+	c.Assert(err, gc.IsNil)
+	service, err := unit.Service()
+	c.Assert(err, gc.IsNil)
+
+	policy, err := service.HookPolicy()
+	c.Assert(err, gc.IsNil)
+	c.Assert(policy, gc.Equals, params.HookPolicy{})
+
+	expect := params.HookPolicy{
+		Timeout:    time.Minute,
+		Retry:      true,
+		RetryDelay: 5 * time.Second,
+	}
+	err = s.service.SetHookPolicy(expect)
+	c.Assert(err, gc.IsNil)
+	policy, err = service.HookPolicy()
+	c.Assert(err, gc.IsNil)
+	c.Assert(policy, gc.Equals, expect)
+}
+
+func (s *uniterSuite) TestRelationUnit(c *gc.C) {
+	mysql, err := s.State.AddService("mysql", s.AddTestingCharm(c, "mysql"))
+	c.Assert(err, gc.IsNil)
+	mysqlUnit, err := mysql.AddUnit()
+	c.Assert(err, gc.IsNil)
+	eps, err := s.State.InferEndpoints([]string{"wordpress", "mysql"})
+	c.Assert(err, gc.IsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, gc.IsNil)
+
+	_, err = s.uniter.Relation("wordpress:db mysql:foo")
+	c.Assert(err, gc.ErrorMatches, `relation "wordpress:db mysql:foo" not found`)
+	c.Assert(params.ErrCode(err), gc.Equals, params.CodeNotFound)
+
+	apiRel, err := s.uniter.Relation(rel.String())
+	c.Assert(err, gc.IsNil)
+	c.Assert(apiRel.Id(), gc.Equals, rel.Id())
+	c.Assert(apiRel.String(), gc.Equals, rel.String())
+	c.Assert(apiRel.Life(), gc.Equals, params.Alive)
+	c.Assert(apiRel.Endpoint().Name, gc.Equals, "db")
+	byId, err := s.uniter.RelationById(rel.Id())
+	c.Assert(err, gc.IsNil)
+	c.Assert(byId.String(), gc.Equals, rel.String())
+
+	unit, err := s.uniter.Unit("unit-wordpress-0")
+	c.Assert(err, gc.IsNil)
+	ru := apiRel.Unit(unit)
+	w, err := ru.Watch()
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, w)
+
+	// Initial event: no counterpart units.
+	s.BackingState.StartSync()
+	select {
+	case change, ok := <-w.Changes():
+		c.Assert(ok, gc.Equals, true)
+		c.Assert(change.Changed, gc.HasLen, 0)
+		c.Assert(change.Departed, gc.HasLen, 0)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for initial event")
+	}
+
+	// The unit enters scope with its private address.
+	err = ru.EnterScope()
+	c.Assert(err, gc.ErrorMatches, "cannot enter scope: private-address not set")
+	err = unit.SetPrivateAddress("1.2.3.4")
+	c.Assert(err, gc.IsNil)
+	err = ru.EnterScope()
+	c.Assert(err, gc.IsNil)
+	settings, err := ru.Settings()
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings.Map(), gc.DeepEquals, map[string]interface{}{
+		"private-address": "1.2.3.4",
+	})
+	settings.Set("some", "thing")
+	settings.Delete("private-address")
+	err = settings.Write()
+	c.Assert(err, gc.IsNil)
+
+	// The counterpart unit sees the new settings.
+	mysqlRelUnit, err := rel.Unit(mysqlUnit)
+	c.Assert(err, gc.IsNil)
+	remote, err := mysqlRelUnit.ReadSettings("wordpress/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(remote, gc.DeepEquals, map[string]interface{}{"some": "thing"})
+
+	// When the counterpart enters scope, the watcher reports it.
+	err = mysqlRelUnit.EnterScope(map[string]interface{}{"foo": "bar"})
+	c.Assert(err, gc.IsNil)
+	s.BackingState.StartSync()
+	select {
+	case change, ok := <-w.Changes():
+		c.Assert(ok, gc.Equals, true)
+		c.Assert(change.Changed, gc.HasLen, 1)
+		_, found := change.Changed["mysql/0"]
+		c.Assert(found, gc.Equals, true)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for relation units change")
+	}
+	remote, err = ru.ReadSettings("mysql/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(remote, gc.DeepEquals, map[string]interface{}{"foo": "bar"})
+
+	err = ru.LeaveScope()
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *uniterSuite) TestStorageInstances(c *gc.C) {
+	svc, err := s.State.AddService("storage", s.AddTestingCharm(c, "storage"))
+	c.Assert(err, gc.IsNil)
+	stateUnit, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = stateUnit.SetPassword("password")
+	c.Assert(err, gc.IsNil)
+	st := s.OpenAPIAs(c, stateUnit.Tag(), "password")
+	defer st.Close()
+	unit, err := st.Uniter().Unit(stateUnit.Tag())
+	c.Assert(err, gc.IsNil)
+
+	w, err := unit.WatchStorageInstances()
+	c.Assert(err, gc.IsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.BackingState, w)
+	wc.AssertOneChange()
+
+	stores, err := unit.StorageInstances()
+	c.Assert(err, gc.IsNil)
+	c.Assert(stores, gc.HasLen, 2)
+	c.Assert(stores[0].Id(), gc.Equals, "data/0")
+	c.Assert(stores[0].Provisioned(), gc.Equals, false)
+	c.Assert(stores[1].Id(), gc.Equals, "disks/0")
+
+	stateStore, err := s.BackingState.StorageInstance("data/0")
+	c.Assert(err, gc.IsNil)
+	err = stateStore.SetProvisioned("vol-0", "/dev/sdb")
+	c.Assert(err, gc.IsNil)
+	wc.AssertOneChange()
+
+	stores, err = unit.StorageInstances()
+	c.Assert(err, gc.IsNil)
+	data := stores[0]
+	c.Assert(data.Name(), gc.Equals, "data")
+	c.Assert(data.Location(), gc.Equals, "/srv/data")
+	c.Assert(data.Size(), gc.Equals, uint64(1024))
+	c.Assert(data.Provisioned(), gc.Equals, true)
+	c.Assert(data.Device(), gc.Equals, "/dev/sdb")
+	c.Assert(data.Attached(), gc.Equals, false)
+
+	err = data.SetAttached(true)
+	c.Assert(err, gc.IsNil)
+	c.Assert(data.Attached(), gc.Equals, true)
+	wc.AssertOneChange()
+	err = stateStore.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(stateStore.Attached(), gc.Equals, true)
+
+	err = stores[1].SetAttached(true)
+	c.Assert(err, gc.ErrorMatches, `cannot set storage instance "disks/0" attachment: not provisioned`)
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
+func (s *uniterSuite) TestResource(c *gc.C) {
+	svc, err := s.State.AddService("resources", s.AddTestingCharm(c, "resources"))
+	c.Assert(err, gc.IsNil)
+	stateUnit, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = stateUnit.SetPassword("password")
+	c.Assert(err, gc.IsNil)
+	st := s.OpenAPIAs(c, stateUnit.Tag(), "password")
+	defer st.Close()
+	service, err := st.Uniter().Service(svc.Tag())
+	c.Assert(err, gc.IsNil)
+
+	_, err = service.Resource("jdk")
+	c.Assert(err, gc.ErrorMatches, `resource "jdk" of service "resources" not found`)
+	c.Assert(params.ErrCode(err), gc.Equals, params.CodeNotFound)
+
+	jdkURL, err := url.Parse("http://example.com/jdk")
+	c.Assert(err, gc.IsNil)
+	_, err = svc.SetResource("jdk", jdkURL, "jdk-sha256", 42)
+	c.Assert(err, gc.IsNil)
+	res, err := service.Resource("jdk")
+	c.Assert(err, gc.IsNil)
+	c.Assert(res.Name(), gc.Equals, "jdk")
+	c.Assert(res.Revision(), gc.Equals, 0)
+	c.Assert(res.URL(), gc.DeepEquals, jdkURL)
+	c.Assert(res.Sha256(), gc.Equals, "jdk-sha256")
+	c.Assert(res.Size(), gc.Equals, int64(42))
+}
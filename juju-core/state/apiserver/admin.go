@@ -82,6 +82,11 @@ func (a *srvAdmin) Login(c params.Creds) error {
 	if err != nil || !entity.PasswordValid(c.Password) {
 		return common.ErrBadCreds
 	}
+	if user, ok := entity.(*state.User); ok {
+		if err := user.UpdateLastLogin(); err != nil {
+			return err
+		}
+	}
 	// We have authenticated the user; now choose an appropriate API
 	// to serve to them.
 	newRoot, err := a.apiRootForEntity(entity, c)
@@ -95,20 +100,26 @@ func (a *srvAdmin) Login(c params.Creds) error {
 	return nil
 }
 
-// machinePinger wraps a presence.Pinger.
-type machinePinger struct {
+// agentPinger wraps a presence.Pinger.
+type agentPinger struct {
 	*presence.Pinger
 }
 
 // Stop implements Pinger.Stop() as Pinger.Kill(), needed at
 // connection closing time to properly stop the wrapped pinger.
-func (p *machinePinger) Stop() error {
+func (p *agentPinger) Stop() error {
 	if err := p.Pinger.Stop(); err != nil {
 		return err
 	}
 	return p.Pinger.Kill()
 }
 
+// agentAliver is implemented by the entities whose agents announce
+// their presence.
+type agentAliver interface {
+	SetAgentAlive() (*presence.Pinger, error)
+}
+
 func (a *srvAdmin) apiRootForEntity(entity taggedAuthenticator, c params.Creds) (interface{}, error) {
 	// TODO(rog) choose appropriate object to serve.
 	newRoot := newSrvRoot(a.root.srv, entity)
@@ -121,13 +132,16 @@ func (a *srvAdmin) apiRootForEntity(entity taggedAuthenticator, c params.Creds)
 		if !machine.CheckProvisioned(c.Nonce) {
 			return nil, common.ErrNotProvisioned
 		}
-		// The machine agent has connected, so start a pinger to announce
-		// it's now alive.
-		pinger, err := machine.SetAgentAlive()
+	}
+	// If an agent has connected, start a pinger to announce it's now
+	// alive; unit agents reach state through the API only, so this is
+	// the only way their presence is known.
+	if agent, ok := entity.(agentAliver); ok {
+		pinger, err := agent.SetAgentAlive()
 		if err != nil {
 			return nil, err
 		}
-		newRoot.resources.Register(&machinePinger{pinger})
+		newRoot.resources.Register(&agentPinger{pinger})
 	}
 	return newRoot, nil
 }
@@ -97,8 +97,11 @@ func (srv *Server) run(lis net.Listener) {
 			log.Errorf("state/api: error serving RPCs: %v", err)
 		}
 	})
+	mux := http.NewServeMux()
+	mux.Handle("/", handler)
+	mux.Handle("/resources", &resourcesHandler{srv: srv})
 	// The error from http.Serve is not interesting.
-	http.Serve(lis, handler)
+	http.Serve(lis, mux)
 }
 
 // Addr returns the address that the server is listening on.
@@ -135,8 +135,8 @@ func (s *baseSuite) openAs(c *C, tag string) *api.State {
 
 // scenarioStatus describes the expected state
 // of the juju environment set up by setUpScenario.
-var scenarioStatus = &api.Status{
-	Machines: map[string]api.MachineInfo{
+var scenarioStatus = &api.LegacyStatus{
+	Machines: map[string]api.LegacyMachineInfo{
 		"0": {
 			InstanceId: "i-machine-0",
 		},
@@ -155,7 +155,8 @@ var scenarioStatus = &api.Status{
 //
 // When the scenario is initialized, we have:
 // user-admin
-// user-other
+// user-other (write access)
+// user-reader (read access)
 // machine-0
 //  instance-id="i-machine-0"
 //  nonce="fake_nonce"
@@ -200,7 +201,12 @@ func (s *baseSuite) setUpScenario(c *C) (entities []string) {
 	setDefaultPassword(c, u)
 	add(u)
 
-	u, err = s.State.AddUser("other", "")
+	u, err = s.State.AddUser("other", "", state.WriteAccess)
+	c.Assert(err, IsNil)
+	setDefaultPassword(c, u)
+	add(u)
+
+	u, err = s.State.AddUser("reader", "", state.ReadAccess)
 	c.Assert(err, IsNil)
 	setDefaultPassword(c, u)
 	add(u)
@@ -0,0 +1,302 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"launchpad.net/goyaml"
+
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// redactedArgs holds the names of arguments whose values are secret
+// whatever the provider of the environment.
+var redactedArgs = map[string]bool{
+	"Password":     true,
+	"admin-secret": true,
+}
+
+// omittedArgs holds the names of arguments whose values are too large
+// to be worth recording.
+var omittedArgs = map[string]bool{
+	"Bundle": true,
+}
+
+// audit records a call to a Client method that changes the environment
+// in the audit log. It is deferred at the start of such methods, as in
+//
+//	defer c.audit("ServiceExpose", args, tags(names.ServiceTag, args.ServiceName)...)(&err)
+//
+// so that the outcome of the call is recorded along with the given
+// arguments and the tags of the entities the call concerns. A failure to
+// record the call is logged, since the call has been made by then.
+func (c *Client) audit(method string, args interface{}, entities ...string) func(*error) {
+	now := time.Now()
+	return func(errp *error) {
+		entry := state.AuditEntry{
+			Time:     now,
+			Tag:      c.api.auth.GetAuthTag(),
+			Method:   method,
+			Entities: entities,
+			Args:     c.auditArgs(args),
+		}
+		if *errp != nil {
+			entry.Error = (*errp).Error()
+		}
+		if err := c.api.state.AddAuditEntry(entry); err != nil {
+			log.Errorf("state/api: cannot audit call to %s: %v", method, err)
+		}
+	}
+}
+
+// auditArgs returns the arguments of a call encoded as JSON, with the
+// values of secret arguments, including the secret attributes of the
+// environment configuration and the secret options of charms, redacted.
+func (c *Client) auditArgs(args interface{}) string {
+	if args == nil {
+		return ""
+	}
+	data, err := json.Marshal(c.redactSecretOptions(args))
+	if err != nil {
+		return fmt.Sprintf("cannot encode arguments: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Sprintf("cannot encode arguments: %v", err)
+	}
+	redact(fields, c.secretAttrs())
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return fmt.Sprintf("cannot encode arguments: %v", err)
+	}
+	return string(data)
+}
+
+// secretAttrs returns the names of the secret attributes of the
+// environment configuration, as reported by its provider.
+func (c *Client) secretAttrs() map[string]bool {
+	secrets := make(map[string]bool)
+	for name := range redactedArgs {
+		secrets[name] = true
+	}
+	cfg, err := c.api.state.EnvironConfig()
+	if err != nil {
+		log.Warningf("state/api: cannot get secret attributes: %v", err)
+		return secrets
+	}
+	provider, err := environs.Provider(cfg.Type())
+	if err != nil {
+		log.Warningf("state/api: cannot get secret attributes: %v", err)
+		return secrets
+	}
+	attrs, err := provider.SecretAttrs(cfg)
+	if err != nil {
+		log.Warningf("state/api: cannot get secret attributes: %v", err)
+		return secrets
+	}
+	for name := range attrs {
+		secrets[name] = true
+	}
+	return secrets
+}
+
+// redact replaces the values of the secret and omitted arguments found
+// in fields, at any depth.
+func redact(fields map[string]interface{}, secrets map[string]bool) {
+	for name, value := range fields {
+		switch {
+		case secrets[name]:
+			fields[name] = "[redacted]"
+		case omittedArgs[name]:
+			fields[name] = "[omitted]"
+		default:
+			redactValue(value, secrets)
+		}
+	}
+}
+
+func redactValue(value interface{}, secrets map[string]bool) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		redact(value, secrets)
+	case []interface{}:
+		for _, v := range value {
+			redactValue(v, secrets)
+		}
+	}
+}
+
+// redactSecretOptions returns the arguments of the calls that set the
+// configuration of a service with the values of the options its charm
+// declares secret redacted. The whole configuration is redacted when
+// the charm cannot be found.
+func (c *Client) redactSecretOptions(args interface{}) interface{} {
+	switch args := args.(type) {
+	case params.ServiceSet:
+		config := c.charmConfig(args.ServiceName, "")
+		args.Options = redactOptions(config, args.Options)
+		return args
+	case params.ServiceSetYAML:
+		config := c.charmConfig(args.ServiceName, "")
+		args.Config = redactOptionsYAML(config, args.ServiceName, args.Config)
+		return args
+	case params.ServiceDeploy:
+		config := c.charmConfig(args.ServiceName, args.CharmUrl)
+		args.Config = redactOptions(config, args.Config)
+		args.ConfigYAML = redactOptionsYAML(config, args.ServiceName, args.ConfigYAML)
+		return args
+	case params.ServiceUpdate:
+		config := c.charmConfig(args.ServiceName, "")
+		args.SettingsStrings = redactOptions(config, args.SettingsStrings)
+		args.SettingsYAML = redactOptionsYAML(config, args.ServiceName, args.SettingsYAML)
+		return args
+	}
+	return args
+}
+
+// charmConfig returns the configuration of the charm of the named
+// service or, when there is no such service, as when it failed to be
+// deployed, of the charm with the given URL. It returns nil when
+// neither can be found.
+func (c *Client) charmConfig(serviceName, charmURL string) *charm.Config {
+	if svc, err := c.api.state.Service(serviceName); err == nil {
+		if ch, _, err := svc.Charm(); err == nil {
+			return ch.Config()
+		}
+		return nil
+	}
+	curl, err := charm.ParseURL(charmURL)
+	if err != nil {
+		return nil
+	}
+	ch, err := c.api.state.Charm(curl)
+	if err != nil {
+		return nil
+	}
+	return ch.Config()
+}
+
+// isSecretOption returns whether the named option is declared secret
+// by the given charm configuration, or is of unknown type since the
+// configuration is nil.
+func isSecretOption(config *charm.Config, name string) bool {
+	if config == nil {
+		return true
+	}
+	option, ok := config.Options[name]
+	return ok && option.Type == "secret"
+}
+
+// redactOptions returns a copy of the given options with the values of
+// the secret ones redacted.
+func redactOptions(config *charm.Config, options map[string]string) map[string]string {
+	if options == nil {
+		return nil
+	}
+	redacted := make(map[string]string, len(options))
+	for name, value := range options {
+		if isSecretOption(config, name) {
+			value = "[redacted]"
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// redactOptionsYAML returns the given YAML settings, as understood by
+// charm.Config.ParseSettingsYAML, with the values of the secret options
+// of the named service redacted. The settings given for other services
+// are ignored by the call, and redacted entirely, as is data that
+// cannot be parsed.
+func redactOptionsYAML(config *charm.Config, serviceName, data string) string {
+	if data == "" {
+		return ""
+	}
+	var allSettings map[string]interface{}
+	if err := goyaml.Unmarshal([]byte(data), &allSettings); err != nil {
+		return "[redacted]"
+	}
+	for key, value := range allSettings {
+		settings, ok := value.(map[interface{}]interface{})
+		if key != serviceName || !ok {
+			allSettings[key] = "[redacted]"
+			continue
+		}
+		for key := range settings {
+			if name, ok := key.(string); !ok || isSecretOption(config, name) {
+				settings[key] = "[redacted]"
+			}
+		}
+	}
+	redacted, err := goyaml.Marshal(allSettings)
+	if err != nil {
+		return "[redacted]"
+	}
+	return string(redacted)
+}
+
+// tags returns the tags, made with tagf, of the entities with the given
+// ids, ignoring empty ones.
+func tags(tagf func(string) string, ids ...string) []string {
+	var result []string
+	for _, id := range ids {
+		if id != "" {
+			result = append(result, tagf(id))
+		}
+	}
+	return result
+}
+
+// endpointServices returns the names of the services of the given
+// relation endpoints, given as "<service>[:<relation>]".
+func endpointServices(endpoints []string) []string {
+	services := make([]string, len(endpoints))
+	for i, ep := range endpoints {
+		services[i] = strings.SplitN(ep, ":", 2)[0]
+	}
+	return services
+}
+
+// AuditLog returns the entries of the audit log of the environment
+// selected by the given parameters, oldest first.
+func (c *Client) AuditLog(args params.AuditLog) (params.AuditLogResults, error) {
+	if err := c.requireAccess(state.AdminAccess); err != nil {
+		return params.AuditLogResults{}, err
+	}
+	filter := state.AuditLogFilter{
+		Since:  args.Since,
+		Entity: args.Entity,
+		Limit:  args.Limit,
+	}
+	if args.User != "" {
+		filter.Tag = names.UserTag(args.User)
+	}
+	entries, err := c.api.state.AuditLog(filter)
+	if err != nil {
+		return params.AuditLogResults{}, err
+	}
+	results := params.AuditLogResults{
+		Entries: make([]params.AuditEntry, len(entries)),
+	}
+	for i, entry := range entries {
+		results.Entries[i] = params.AuditEntry{
+			Time:     entry.Time,
+			Tag:      entry.Tag,
+			Method:   entry.Method,
+			Entities: entry.Entities,
+			Args:     entry.Args,
+			Error:    entry.Error,
+		}
+	}
+	return results, nil
+}
@@ -5,9 +5,17 @@ package client
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
 
 	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/log/syslog"
+	"launchpad.net/juju-core/names"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api"
 	"launchpad.net/juju-core/state/api/params"
@@ -53,20 +61,34 @@ func (r *API) Client(id string) (*Client, error) {
 	return r.client, nil
 }
 
-func (c *Client) Status() (api.Status, error) {
+// requireAccess returns an error unless the authenticated user has at
+// least the given access to the environment.
+func (c *Client) requireAccess(access state.UserAccess) error {
+	if !c.api.auth.AuthUserAccess(access) {
+		return common.ErrPerm
+	}
+	return nil
+}
+
+// Status returns the instance ids of the machines of the environment.
+// It is superseded by FullStatus.
+func (c *Client) Status() (api.LegacyStatus, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return api.LegacyStatus{}, err
+	}
 	ms, err := c.api.state.AllMachines()
 	if err != nil {
-		return api.Status{}, err
+		return api.LegacyStatus{}, err
 	}
-	status := api.Status{
-		Machines: make(map[string]api.MachineInfo),
+	status := api.LegacyStatus{
+		Machines: make(map[string]api.LegacyMachineInfo),
 	}
 	for _, m := range ms {
 		instId, err := m.InstanceId()
 		if err != nil && !state.IsNotProvisionedError(err) {
-			return api.Status{}, err
+			return api.LegacyStatus{}, err
 		}
-		status.Machines[m.Id()] = api.MachineInfo{
+		status.Machines[m.Id()] = api.LegacyMachineInfo{
 			InstanceId: string(instId),
 		}
 	}
@@ -74,14 +96,53 @@ func (c *Client) Status() (api.Status, error) {
 }
 
 func (c *Client) WatchAll() (params.AllWatcherId, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return params.AllWatcherId{}, err
+	}
 	w := c.api.state.Watch()
 	return params.AllWatcherId{
 		AllWatcherId: c.api.resources.Register(w),
 	}, nil
 }
 
+// DebugLogPath holds the path of the accumulated log of the
+// environment, read by WatchDebugLog.
+var DebugLogPath = syslog.AllMachinesLogPath
+
+// WatchDebugLog returns a DebugLogWatcher that sends the records of the
+// accumulated log selected by the given parameters as they are written.
+func (c *Client) WatchDebugLog(args params.DebugLog) (params.DebugLogWatcherId, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return params.DebugLogWatcherId{}, err
+	}
+	if _, err := os.Stat(DebugLogPath); err != nil {
+		return params.DebugLogWatcherId{}, fmt.Errorf("cannot read debug log: %v", err)
+	}
+	backlog := args.Backlog
+	if args.Replay {
+		backlog = -1
+	} else if backlog < 0 {
+		backlog = 0
+	}
+	filter := syslog.Filter{
+		IncludeEntity: args.IncludeEntity,
+		ExcludeEntity: args.ExcludeEntity,
+		IncludeModule: args.IncludeModule,
+		ExcludeModule: args.ExcludeModule,
+		Level:         args.Level,
+	}
+	t := syslog.NewTailer(DebugLogPath, filter, backlog)
+	return params.DebugLogWatcherId{
+		DebugLogWatcherId: c.api.resources.Register(t),
+	}, nil
+}
+
 // ServiceSet implements the server side of Client.ServiceSet.
-func (c *Client) ServiceSet(p params.ServiceSet) error {
+func (c *Client) ServiceSet(p params.ServiceSet) (err error) {
+	defer c.audit("ServiceSet", p, tags(names.ServiceTag, p.ServiceName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
 	svc, err := c.api.state.Service(p.ServiceName)
 	if err != nil {
 		return err
@@ -90,7 +151,11 @@ func (c *Client) ServiceSet(p params.ServiceSet) error {
 }
 
 // ServiceSetYAML implements the server side of Client.ServerSetYAML.
-func (c *Client) ServiceSetYAML(p params.ServiceSetYAML) error {
+func (c *Client) ServiceSetYAML(p params.ServiceSetYAML) (err error) {
+	defer c.audit("ServiceSetYAML", p, tags(names.ServiceTag, p.ServiceName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
 	svc, err := c.api.state.Service(p.ServiceName)
 	if err != nil {
 		return err
@@ -100,11 +165,18 @@ func (c *Client) ServiceSetYAML(p params.ServiceSetYAML) error {
 
 // ServiceGet returns the configuration for a service.
 func (c *Client) ServiceGet(args params.ServiceGet) (params.ServiceGetResults, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return params.ServiceGetResults{}, err
+	}
 	return statecmd.ServiceGet(c.api.state, args)
 }
 
 // Resolved implements the server side of Client.Resolved.
-func (c *Client) Resolved(p params.Resolved) error {
+func (c *Client) Resolved(p params.Resolved) (err error) {
+	defer c.audit("Resolved", p, tags(names.UnitTag, p.UnitName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
 	unit, err := c.api.state.Unit(p.UnitName)
 	if err != nil {
 		return err
@@ -114,36 +186,93 @@ func (c *Client) Resolved(p params.Resolved) error {
 
 // ServiceExpose changes the juju-managed firewall to expose any ports that
 // were also explicitly marked by units as open.
-func (c *Client) ServiceExpose(args params.ServiceExpose) error {
+func (c *Client) ServiceExpose(args params.ServiceExpose) (err error) {
+	defer c.audit("ServiceExpose", args, tags(names.ServiceTag, args.ServiceName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
 	return statecmd.ServiceExpose(c.api.state, args)
 }
 
 // ServiceUnexpose changes the juju-managed firewall to unexpose any ports that
 // were also explicitly marked by units as open.
-func (c *Client) ServiceUnexpose(args params.ServiceUnexpose) error {
+func (c *Client) ServiceUnexpose(args params.ServiceUnexpose) (err error) {
+	defer c.audit("ServiceUnexpose", args, tags(names.ServiceTag, args.ServiceName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
 	return statecmd.ServiceUnexpose(c.api.state, args)
 }
 
+// ContainerOperation asks the agent of the machine hosting a container to
+// freeze, thaw, snapshot or restore it.
+func (c *Client) ContainerOperation(args params.ContainerOperation) (err error) {
+	defer c.audit("ContainerOperation", args, tags(names.MachineTag, args.MachineId)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
+	return statecmd.ContainerOperation(c.api.state, args)
+}
+
+// ContainerOperationInfo returns the operation last requested on a
+// container machine, while it is pending or once it has failed.
+func (c *Client) ContainerOperationInfo(args params.ContainerOperationInfo) (api.ContainerOperationInfo, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return api.ContainerOperationInfo{}, err
+	}
+	machine, err := c.api.state.Machine(args.MachineId)
+	if err != nil {
+		return api.ContainerOperationInfo{}, err
+	}
+	op, err := machine.ContainerOp()
+	if err != nil {
+		return api.ContainerOperationInfo{}, err
+	}
+	return containerOperationInfo(op), nil
+}
+
+func containerOperationInfo(op *state.ContainerOperation) api.ContainerOperationInfo {
+	return api.ContainerOperationInfo{
+		Operation: string(op.Op),
+		Snapshot:  op.Snapshot,
+		Error:     op.Error,
+	}
+}
+
 var CharmStore charm.Repository = charm.Store
 
-// ServiceDeploy fetches the charm from the charm store and deploys it. Local
-// charms are not supported.
-func (c *Client) ServiceDeploy(args params.ServiceDeploy) error {
-	curl, err := charm.ParseURL(args.CharmUrl)
+// ensureCharm returns the charm with the given URL, fetching it from the
+// charm store when needed. Local charms must have been added with
+// AddLocalCharm beforehand.
+func ensureCharm(conn *juju.Conn, url string) (*state.Charm, error) {
+	curl, err := charm.ParseURL(url)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if curl.Schema != "cs" {
-		return fmt.Errorf(`charm url has unsupported schema %q`, curl.Schema)
+	if curl.Schema != "cs" && curl.Schema != "local" {
+		return nil, fmt.Errorf(`charm url has unsupported schema %q`, curl.Schema)
 	}
 	if curl.Revision < 0 {
-		return fmt.Errorf("charm url must include revision")
+		return nil, fmt.Errorf("charm url must include revision")
+	}
+	if curl.Schema == "local" {
+		return conn.State.Charm(curl)
+	}
+	return conn.PutCharm(curl, CharmStore, false)
+}
+
+// ServiceDeploy fetches the charm from the charm store, unless it is a
+// local charm added with AddLocalCharm, and deploys it.
+func (c *Client) ServiceDeploy(args params.ServiceDeploy) (err error) {
+	defer c.audit("ServiceDeploy", args, tags(names.ServiceTag, args.ServiceName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
 	}
 	conn, err := juju.NewConnFromState(c.api.state)
 	if err != nil {
 		return err
 	}
-	ch, err := conn.PutCharm(curl, CharmStore, false)
+	ch, err := ensureCharm(conn, args.CharmUrl)
 	if err != nil {
 		return err
 	}
@@ -170,7 +299,11 @@ func (c *Client) ServiceDeploy(args params.ServiceDeploy) error {
 // ServiceUpdate updates the service attributes, including charm URL,
 // minimum number of units, settings and constraints.
 // All parameters in params.ServiceUpdate except the service name are optional.
-func (c *Client) ServiceUpdate(args params.ServiceUpdate) error {
+func (c *Client) ServiceUpdate(args params.ServiceUpdate) (err error) {
+	defer c.audit("ServiceUpdate", args, tags(names.ServiceTag, args.ServiceName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
 	service, err := c.api.state.Service(args.ServiceName)
 	if err != nil {
 		return err
@@ -206,21 +339,11 @@ func (c *Client) ServiceUpdate(args params.ServiceUpdate) error {
 
 // serviceSetCharm sets the charm for the given service.
 func serviceSetCharm(state *state.State, service *state.Service, url string, force bool) error {
-	curl, err := charm.ParseURL(url)
-	if err != nil {
-		return err
-	}
-	if curl.Schema != "cs" {
-		return fmt.Errorf(`charm url has unsupported schema %q`, curl.Schema)
-	}
-	if curl.Revision < 0 {
-		return fmt.Errorf("charm url must include revision")
-	}
 	conn, err := juju.NewConnFromState(state)
 	if err != nil {
 		return err
 	}
-	ch, err := conn.PutCharm(curl, CharmStore, false)
+	ch, err := ensureCharm(conn, url)
 	if err != nil {
 		return err
 	}
@@ -256,7 +379,11 @@ func serviceSetSettingsStrings(service *state.Service, settings map[string]strin
 }
 
 // ServiceSetCharm sets the charm for a given service.
-func (c *Client) ServiceSetCharm(args params.ServiceSetCharm) error {
+func (c *Client) ServiceSetCharm(args params.ServiceSetCharm) (err error) {
+	defer c.audit("ServiceSetCharm", args, tags(names.ServiceTag, args.ServiceName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
 	service, err := c.api.state.Service(args.ServiceName)
 	if err != nil {
 		return err
@@ -265,7 +392,11 @@ func (c *Client) ServiceSetCharm(args params.ServiceSetCharm) error {
 }
 
 // AddServiceUnits adds a given number of units to a service.
-func (c *Client) AddServiceUnits(args params.AddServiceUnits) (params.AddServiceUnitsResults, error) {
+func (c *Client) AddServiceUnits(args params.AddServiceUnits) (result params.AddServiceUnitsResults, err error) {
+	defer c.audit("AddServiceUnits", args, tags(names.ServiceTag, args.ServiceName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return params.AddServiceUnitsResults{}, err
+	}
 	units, err := statecmd.AddServiceUnits(c.api.state, args)
 	if err != nil {
 		return params.AddServiceUnitsResults{}, err
@@ -278,37 +409,63 @@ func (c *Client) AddServiceUnits(args params.AddServiceUnits) (params.AddService
 }
 
 // DestroyServiceUnits removes a given set of service units.
-func (c *Client) DestroyServiceUnits(args params.DestroyServiceUnits) error {
+func (c *Client) DestroyServiceUnits(args params.DestroyServiceUnits) (err error) {
+	defer c.audit("DestroyServiceUnits", args, tags(names.UnitTag, args.UnitNames...)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
 	return statecmd.DestroyServiceUnits(c.api.state, args)
 }
 
 // ServiceDestroy destroys a given service.
-func (c *Client) ServiceDestroy(args params.ServiceDestroy) error {
+func (c *Client) ServiceDestroy(args params.ServiceDestroy) (err error) {
+	defer c.audit("ServiceDestroy", args, tags(names.ServiceTag, args.ServiceName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
 	return statecmd.ServiceDestroy(c.api.state, args)
 }
 
 // GetServiceConstraints returns the constraints for a given service.
 func (c *Client) GetServiceConstraints(args params.GetServiceConstraints) (params.GetServiceConstraintsResults, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return params.GetServiceConstraintsResults{}, err
+	}
 	return statecmd.GetServiceConstraints(c.api.state, args)
 }
 
 // SetServiceConstraints sets the constraints for a given service.
-func (c *Client) SetServiceConstraints(args params.SetServiceConstraints) error {
+func (c *Client) SetServiceConstraints(args params.SetServiceConstraints) (err error) {
+	defer c.audit("SetServiceConstraints", args, tags(names.ServiceTag, args.ServiceName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
 	return statecmd.SetServiceConstraints(c.api.state, args)
 }
 
 // AddRelation adds a relation between the specified endpoints and returns the relation info.
-func (c *Client) AddRelation(args params.AddRelation) (params.AddRelationResults, error) {
+func (c *Client) AddRelation(args params.AddRelation) (result params.AddRelationResults, err error) {
+	defer c.audit("AddRelation", args, tags(names.ServiceTag, endpointServices(args.Endpoints)...)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return params.AddRelationResults{}, err
+	}
 	return statecmd.AddRelation(c.api.state, args)
 }
 
 // DestroyRelation removes the relation between the specified endpoints.
-func (c *Client) DestroyRelation(args params.DestroyRelation) error {
+func (c *Client) DestroyRelation(args params.DestroyRelation) (err error) {
+	defer c.audit("DestroyRelation", args, tags(names.ServiceTag, endpointServices(args.Endpoints)...)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
 	return statecmd.DestroyRelation(c.api.state, args)
 }
 
 // CharmInfo returns information about the requested charm.
 func (c *Client) CharmInfo(args params.CharmInfo) (api.CharmInfo, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return api.CharmInfo{}, err
+	}
 	curl, err := charm.ParseURL(args.CharmURL)
 	if err != nil {
 		return api.CharmInfo{}, err
@@ -322,6 +479,7 @@ func (c *Client) CharmInfo(args params.CharmInfo) (api.CharmInfo, error) {
 		URL:      curl.String(),
 		Config:   charm.Config(),
 		Meta:     charm.Meta(),
+		Actions:  charm.Actions(),
 	}
 	return info, nil
 }
@@ -329,6 +487,9 @@ func (c *Client) CharmInfo(args params.CharmInfo) (api.CharmInfo, error) {
 // EnvironmentInfo returns information about the current environment (default
 // series and type).
 func (c *Client) EnvironmentInfo() (api.EnvironmentInfo, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return api.EnvironmentInfo{}, err
+	}
 	state := c.api.state
 	conf, err := state.EnvironConfig()
 	if err != nil {
@@ -350,6 +511,9 @@ func (c *Client) EnvironmentInfo() (api.EnvironmentInfo, error) {
 
 // GetAnnotations returns annotations about a given entity.
 func (c *Client) GetAnnotations(args params.GetAnnotations) (params.GetAnnotationsResults, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return params.GetAnnotationsResults{}, err
+	}
 	nothing := params.GetAnnotationsResults{}
 	entity, err := c.findEntity(args.Tag)
 	if err != nil {
@@ -375,10 +539,469 @@ func (c *Client) findEntity(tag string) (state.Annotator, error) {
 }
 
 // SetAnnotations stores annotations about a given entity.
-func (c *Client) SetAnnotations(args params.SetAnnotations) error {
+func (c *Client) SetAnnotations(args params.SetAnnotations) (err error) {
+	defer c.audit("SetAnnotations", args, args.Tag)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
 	entity, err := c.findEntity(args.Tag)
 	if err != nil {
 		return err
 	}
 	return entity.SetAnnotations(args.Pairs)
 }
+
+// AddCharm adds the given charm to the environment, fetching it from the
+// charm store when needed.
+func (c *Client) AddCharm(args params.CharmURL) (err error) {
+	defer c.audit("AddCharm", args)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
+	conn, err := juju.NewConnFromState(c.api.state)
+	if err != nil {
+		return err
+	}
+	_, err = ensureCharm(conn, args.URL)
+	return err
+}
+
+// AddLocalCharm adds to the environment a charm read by the client from
+// its local repository, so that it can be deployed.
+func (c *Client) AddLocalCharm(args params.AddLocalCharm) (err error) {
+	defer c.audit("AddLocalCharm", args)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
+	curl, err := charm.ParseURL(args.CharmURL)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile("", charm.Quote(curl.String()))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := f.Write(args.Bundle); err != nil {
+		return err
+	}
+	bundle, err := charm.ReadBundle(f.Name())
+	if err != nil {
+		return fmt.Errorf("cannot read charm bundle: %v", err)
+	}
+	conn, err := juju.NewConnFromState(c.api.state)
+	if err != nil {
+		return err
+	}
+	_, err = conn.AddLocalCharm(curl, bundle)
+	return err
+}
+
+// ServiceSetResource records new content for a resource of a service,
+// uploaded beforehand to the resources endpoint of the API server.
+func (c *Client) ServiceSetResource(args params.ServiceSetResource) (err error) {
+	defer c.audit("ServiceSetResource", args, tags(names.ServiceTag, args.ServiceName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
+	service, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(args.URL)
+	if err != nil {
+		return err
+	}
+	_, err = service.SetResource(args.Name, u, args.Sha256, args.Size)
+	return err
+}
+
+// ServiceGetCharmURL returns the charm URL the given service is
+// running at present.
+func (c *Client) ServiceGetCharmURL(args params.ServiceGetCharmURL) (params.StringResult, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return params.StringResult{}, err
+	}
+	service, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return params.StringResult{}, err
+	}
+	curl, _ := service.CharmURL()
+	return params.StringResult{Result: curl.String()}, nil
+}
+
+// ServiceCharmRelations returns the names of the relations of the
+// charm of the given service, including the implicit ones.
+func (c *Client) ServiceCharmRelations(args params.ServiceCharmRelations) (params.ServiceCharmRelationsResults, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return params.ServiceCharmRelationsResults{}, err
+	}
+	var results params.ServiceCharmRelationsResults
+	service, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return results, err
+	}
+	endpoints, err := service.Endpoints()
+	if err != nil {
+		return results, err
+	}
+	results.CharmRelations = make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		results.CharmRelations[i] = endpoint.Relation.Name
+	}
+	sort.Strings(results.CharmRelations)
+	return results, nil
+}
+
+// ServiceGetHookPolicy returns the hook policy of the given service.
+func (c *Client) ServiceGetHookPolicy(args params.ServiceGetHookPolicy) (params.HookPolicy, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return params.HookPolicy{}, err
+	}
+	service, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return params.HookPolicy{}, err
+	}
+	return service.HookPolicy(), nil
+}
+
+// ServiceSetHookPolicy sets the hook policy of the given service.
+func (c *Client) ServiceSetHookPolicy(args params.ServiceSetHookPolicy) (err error) {
+	defer c.audit("ServiceSetHookPolicy", args, tags(names.ServiceTag, args.ServiceName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
+	service, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return err
+	}
+	return service.SetHookPolicy(args.Policy)
+}
+
+// AddMachines adds new machines with the supplied parameters. The
+// series of the machines default to the default series of the
+// environment.
+func (c *Client) AddMachines(args params.AddMachines) (result params.AddMachinesResults, err error) {
+	defer c.audit("AddMachines", args)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return params.AddMachinesResults{}, err
+	}
+	results := params.AddMachinesResults{
+		Machines: make([]params.AddMachinesResult, len(args.MachineParams)),
+	}
+	for i, p := range args.MachineParams {
+		m, err := c.addOneMachine(p)
+		results.Machines[i].Error = common.ServerError(err)
+		if err == nil {
+			results.Machines[i].Machine = m.Id()
+		}
+	}
+	return results, nil
+}
+
+func (c *Client) addOneMachine(p params.AddMachineParams) (*state.Machine, error) {
+	if p.Series == "" {
+		conf, err := c.api.state.EnvironConfig()
+		if err != nil {
+			return nil, err
+		}
+		p.Series = conf.DefaultSeries()
+	}
+	jobs := make([]state.MachineJob, len(p.Jobs))
+	for i, job := range p.Jobs {
+		var err error
+		if jobs[i], err = state.MachineJobFromParams(job); err != nil {
+			return nil, err
+		}
+	}
+	return c.api.state.AddMachineWithConstraints(&state.AddMachineParams{
+		Series:        p.Series,
+		Constraints:   p.Constraints,
+		ParentId:      p.ParentId,
+		ContainerType: p.ContainerType,
+		Jobs:          jobs,
+	})
+}
+
+// DestroyMachines removes the given set of machines.
+func (c *Client) DestroyMachines(args params.DestroyMachines) (err error) {
+	defer c.audit("DestroyMachines", args, tags(names.MachineTag, args.MachineNames...)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return err
+	}
+	return c.api.state.DestroyMachines(args.MachineNames...)
+}
+
+// DestroyEnvironment stops the instances of all the machines of the
+// environment, except those of the machines managing it. The client
+// destroys those, and the environment storage, itself.
+func (c *Client) DestroyEnvironment() (err error) {
+	defer c.audit("DestroyEnvironment", nil)(&err)
+	if err := c.requireAccess(state.AdminAccess); err != nil {
+		return err
+	}
+	machines, err := c.api.state.AllMachines()
+	if err != nil {
+		return err
+	}
+	var ids []instance.Id
+	for _, m := range machines {
+		if isManager(m) {
+			continue
+		}
+		if id, err := m.InstanceId(); err == nil {
+			ids = append(ids, id)
+		} else if !state.IsNotProvisionedError(err) {
+			return err
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	conn, err := juju.NewConnFromState(c.api.state)
+	if err != nil {
+		return err
+	}
+	insts, err := conn.Environ.Instances(ids)
+	if err != nil && err != environs.ErrPartialInstances {
+		if err == environs.ErrNoInstances {
+			return nil
+		}
+		return err
+	}
+	var found []instance.Instance
+	for _, inst := range insts {
+		if inst != nil {
+			found = append(found, inst)
+		}
+	}
+	return conn.Environ.StopInstances(found)
+}
+
+// isManager returns whether the machine manages the environment.
+func isManager(m *state.Machine) bool {
+	for _, job := range m.Jobs() {
+		if job == state.JobManageEnviron {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvironmentConfig returns the configuration of the environment.
+func (c *Client) EnvironmentConfig() (params.EnvironmentConfigResults, error) {
+	if err := c.requireAccess(state.AdminAccess); err != nil {
+		return params.EnvironmentConfigResults{}, err
+	}
+	cfg, err := c.api.state.EnvironConfig()
+	if err != nil {
+		return params.EnvironmentConfigResults{}, err
+	}
+	return params.EnvironmentConfigResults{Config: cfg.AllAttrs()}, nil
+}
+
+// SetEnvironmentConfig applies the given attributes to the
+// configuration of the environment, once validated by its provider.
+// The agent version can only be changed with UpgradeJuju.
+func (c *Client) SetEnvironmentConfig(args params.SetEnvironmentConfig) (err error) {
+	defer c.audit("SetEnvironmentConfig", args)(&err)
+	if err := c.requireAccess(state.AdminAccess); err != nil {
+		return err
+	}
+	if _, ok := args.Config["agent-version"]; ok {
+		return fmt.Errorf("agent-version must be set with UpgradeJuju")
+	}
+	oldConfig, err := c.api.state.EnvironConfig()
+	if err != nil {
+		return err
+	}
+	newConfig, err := oldConfig.Apply(args.Config)
+	if err != nil {
+		return err
+	}
+	provider, err := environs.Provider(oldConfig.Type())
+	if err != nil {
+		return err
+	}
+	newProviderConfig, err := provider.Validate(newConfig, oldConfig)
+	if err != nil {
+		return err
+	}
+	return c.api.state.SetEnvironConfig(newProviderConfig)
+}
+
+// UpgradeJuju sets the agent version the agents of the environment
+// run. The tools for the version must be available to the environment.
+func (c *Client) UpgradeJuju(args params.UpgradeJuju) (err error) {
+	defer c.audit("UpgradeJuju", args)(&err)
+	if err := c.requireAccess(state.AdminAccess); err != nil {
+		return err
+	}
+	cfg, err := c.api.state.EnvironConfig()
+	if err != nil {
+		return err
+	}
+	cfg, err = cfg.Apply(map[string]interface{}{
+		"agent-version": args.Version.String(),
+	})
+	if err != nil {
+		return err
+	}
+	return c.api.state.SetEnvironConfig(cfg)
+}
+
+// GetEnvironmentConstraints returns the constraints of the environment.
+func (c *Client) GetEnvironmentConstraints() (params.GetEnvironmentConstraintsResults, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return params.GetEnvironmentConstraintsResults{}, err
+	}
+	cons, err := c.api.state.EnvironConstraints()
+	if err != nil {
+		return params.GetEnvironmentConstraintsResults{}, err
+	}
+	return params.GetEnvironmentConstraintsResults{Constraints: cons}, nil
+}
+
+// SetEnvironmentConstraints sets the constraints of the environment.
+func (c *Client) SetEnvironmentConstraints(args params.SetEnvironmentConstraints) (err error) {
+	defer c.audit("SetEnvironmentConstraints", args)(&err)
+	if err := c.requireAccess(state.AdminAccess); err != nil {
+		return err
+	}
+	return c.api.state.SetEnvironConstraints(args.Constraints)
+}
+
+// PublicAddress returns the public address of a machine or a unit.
+// The address of a machine is asked to the provider when it has none
+// recorded.
+func (c *Client) PublicAddress(args params.PublicAddress) (params.PublicAddressResults, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return params.PublicAddressResults{}, err
+	}
+	switch {
+	case names.IsMachine(args.Target):
+		machine, err := c.api.state.Machine(args.Target)
+		if err != nil {
+			return params.PublicAddressResults{}, err
+		}
+		if addr := instance.SelectPublicAddress(machine.Addresses()); addr != "" {
+			return params.PublicAddressResults{PublicAddress: addr}, nil
+		}
+		addr, err := c.instanceDNSName(machine)
+		if err != nil {
+			return params.PublicAddressResults{}, err
+		}
+		return params.PublicAddressResults{PublicAddress: addr}, nil
+	case names.IsUnit(args.Target):
+		unit, err := c.api.state.Unit(args.Target)
+		if err != nil {
+			return params.PublicAddressResults{}, err
+		}
+		addr, ok := unit.PublicAddress()
+		if !ok {
+			return params.PublicAddressResults{}, fmt.Errorf("unit %q has no public address", unit)
+		}
+		return params.PublicAddressResults{PublicAddress: addr}, nil
+	}
+	return params.PublicAddressResults{}, fmt.Errorf("unknown unit or machine %q", args.Target)
+}
+
+// instanceDNSName returns the DNS name of the instance of the machine,
+// waiting for the provider to assign it.
+func (c *Client) instanceDNSName(machine *state.Machine) (string, error) {
+	id, err := machine.InstanceId()
+	if err != nil {
+		return "", err
+	}
+	conn, err := juju.NewConnFromState(c.api.state)
+	if err != nil {
+		return "", err
+	}
+	insts, err := conn.Environ.Instances([]instance.Id{id})
+	if err != nil {
+		return "", err
+	}
+	return insts[0].WaitDNSName()
+}
+
+// PrivateAddress returns the private address of a machine or a unit.
+func (c *Client) PrivateAddress(args params.PrivateAddress) (params.PrivateAddressResults, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return params.PrivateAddressResults{}, err
+	}
+	switch {
+	case names.IsMachine(args.Target):
+		machine, err := c.api.state.Machine(args.Target)
+		if err != nil {
+			return params.PrivateAddressResults{}, err
+		}
+		addr := instance.SelectInternalAddress(machine.Addresses(), false)
+		if addr == "" {
+			return params.PrivateAddressResults{}, fmt.Errorf("machine %q has no internal address", machine)
+		}
+		return params.PrivateAddressResults{PrivateAddress: addr}, nil
+	case names.IsUnit(args.Target):
+		unit, err := c.api.state.Unit(args.Target)
+		if err != nil {
+			return params.PrivateAddressResults{}, err
+		}
+		addr, ok := unit.PrivateAddress()
+		if !ok {
+			return params.PrivateAddressResults{}, fmt.Errorf("unit %q has no internal address", unit)
+		}
+		return params.PrivateAddressResults{PrivateAddress: addr}, nil
+	}
+	return params.PrivateAddressResults{}, fmt.Errorf("unknown unit or machine %q", args.Target)
+}
+
+// EnqueueAction queues an action, as defined by the charm of the unit,
+// to be run by the agent of the unit, and returns the id of the action.
+func (c *Client) EnqueueAction(args params.EnqueueAction) (result params.EnqueueActionResults, err error) {
+	defer c.audit("EnqueueAction", args, tags(names.UnitTag, args.UnitName)...)(&err)
+	if err := c.requireAccess(state.WriteAccess); err != nil {
+		return params.EnqueueActionResults{}, err
+	}
+	unit, err := c.api.state.Unit(args.UnitName)
+	if err != nil {
+		return params.EnqueueActionResults{}, err
+	}
+	service, err := unit.Service()
+	if err != nil {
+		return params.EnqueueActionResults{}, err
+	}
+	ch, _, err := service.Charm()
+	if err != nil {
+		return params.EnqueueActionResults{}, err
+	}
+	actionParams, err := ch.Actions().ParseParamsStrings(args.Action, args.Params)
+	if err != nil {
+		return params.EnqueueActionResults{}, err
+	}
+	action, err := unit.AddAction(args.Action, actionParams)
+	if err != nil {
+		return params.EnqueueActionResults{}, err
+	}
+	return params.EnqueueActionResults{Id: action.Id()}, nil
+}
+
+// ActionInfo returns the status of an action, and its results once it
+// ran.
+func (c *Client) ActionInfo(args params.ActionInfo) (api.ActionInfo, error) {
+	if err := c.requireAccess(state.ReadAccess); err != nil {
+		return api.ActionInfo{}, err
+	}
+	action, err := c.api.state.Action(args.Id)
+	if err != nil {
+		return api.ActionInfo{}, err
+	}
+	return api.ActionInfo{
+		Id:       action.Id(),
+		UnitName: action.UnitName(),
+		Name:     action.Name(),
+		Params:   action.Params(),
+		Status:   string(action.Status()),
+		Results:  action.Results(),
+		Message:  action.Message(),
+	}, nil
+}
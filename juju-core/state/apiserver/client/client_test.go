@@ -4,17 +4,28 @@
 package client_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	. "launchpad.net/gocheck"
+	"launchpad.net/goyaml"
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/constraints"
 	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/juju/testing"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api"
 	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/apiserver/client"
 	coretesting "launchpad.net/juju-core/testing"
 	"launchpad.net/juju-core/testing/checkers"
+	"launchpad.net/juju-core/version"
+	"launchpad.net/loggo"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 type clientSuite struct {
@@ -30,6 +41,50 @@ func (s *clientSuite) TestClientStatus(c *C) {
 	c.Assert(status, DeepEquals, scenarioStatus)
 }
 
+func (s *clientSuite) TestClientFullStatus(c *C) {
+	s.setUpScenario(c)
+	status, err := s.APIState.Client().FullStatus(nil)
+	c.Assert(err, IsNil)
+	c.Assert(status.EnvironmentName, Equals, "dummyenv")
+	c.Assert(status.InstancesError, Equals, "")
+	c.Assert(status.Machines, HasLen, 3)
+	m0 := status.Machines["0"]
+	c.Assert(m0.InstanceId, Equals, instance.Id("i-machine-0"))
+	// The dummy environment knows nothing of the scenario's instances.
+	c.Assert(m0.InstanceState, Equals, "missing")
+	c.Assert(m0.AgentState, Equals, params.StatusDown)
+
+	c.Assert(status.Services, HasLen, 3)
+	wordpress := status.Services["wordpress"]
+	c.Assert(wordpress.Err, IsNil)
+	c.Assert(wordpress.Charm, Equals, "local:series/wordpress-3")
+	c.Assert(wordpress.Relations, DeepEquals, map[string][]string{
+		"logging-dir": {"logging"},
+	})
+	c.Assert(wordpress.Units, HasLen, 2)
+	wu := wordpress.Units["wordpress/1"]
+	c.Assert(wu.Machine, Equals, "2")
+	c.Assert(wu.Subordinates, HasLen, 1)
+	c.Assert(wu.Subordinates["logging/1"].AgentState, Equals, params.StatusPending)
+	logging := status.Services["logging"]
+	c.Assert(logging.SubordinateTo, DeepEquals, []string{"wordpress"})
+	c.Assert(logging.Units, HasLen, 0)
+}
+
+func (s *clientSuite) TestClientFullStatusPatterns(c *C) {
+	s.setUpScenario(c)
+	status, err := s.APIState.Client().FullStatus([]string{"logging/1"})
+	c.Assert(err, IsNil)
+	c.Assert(status.Machines, HasLen, 1)
+	c.Assert(status.Machines["2"].Id, Equals, "2")
+	c.Assert(status.Services, HasLen, 2)
+	c.Assert(status.Services["wordpress"].Units, HasLen, 1)
+	c.Assert(status.Services["wordpress"].Units["wordpress/1"].Machine, Equals, "2")
+
+	_, err = s.APIState.Client().FullStatus([]string{"a/b/c"})
+	c.Assert(err, ErrorMatches, `pattern "a/b/c" contains too many '/' characters`)
+}
+
 func (s *clientSuite) TestClientServerSet(c *C) {
 	dummy, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
 	c.Assert(err, IsNil)
@@ -79,7 +134,7 @@ func (s *clientSuite) TestClientAddServiceUnits(c *C) {
 	c.Assert(err, IsNil)
 	for i, t := range clientAddServiceUnitsTests {
 		c.Logf("test %d. %s", i, t.about)
-		units, err := s.APIState.Client().AddServiceUnits("dummy", len(t.expected))
+		units, err := s.APIState.Client().AddServiceUnits("dummy", len(t.expected), "")
 		if t.err != "" {
 			c.Assert(err, ErrorMatches, t.err)
 			continue
@@ -126,6 +181,7 @@ func (s *clientSuite) TestClientCharmInfo(c *C) {
 			URL:      charm.URL().String(),
 			Config:   charm.Config(),
 			Meta:     charm.Meta(),
+			Actions:  charm.Actions(),
 		}
 		c.Assert(info, DeepEquals, expected)
 	}
@@ -263,6 +319,48 @@ func (s *clientSuite) TestClientServiceExpose(c *C) {
 	c.Assert(service.IsExposed(), Equals, true)
 }
 
+func (s *clientSuite) TestClientContainerOperation(c *C) {
+	s.setUpScenario(c)
+	template := state.AddMachineParams{
+		ParentId:      "0",
+		ContainerType: instance.LXC,
+		Series:        "series",
+		Jobs:          []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineWithConstraints(&template)
+	c.Assert(err, IsNil)
+	err = s.APIState.Client().ContainerOperation(container.Id(), "snapshot", "before-upgrade")
+	c.Assert(err, IsNil)
+	op, err := container.ContainerOp()
+	c.Assert(err, IsNil)
+	c.Assert(op, DeepEquals, &state.ContainerOperation{
+		Op:       state.ContainerSnapshot,
+		Snapshot: "before-upgrade",
+	})
+
+	err = s.APIState.Client().ContainerOperation("42", "freeze", "")
+	c.Assert(err, ErrorMatches, `machine 42 not found`)
+
+	info, err := s.APIState.Client().ContainerOperationInfo(container.Id())
+	c.Assert(err, IsNil)
+	c.Assert(info, DeepEquals, &api.ContainerOperationInfo{
+		Operation: "snapshot",
+		Snapshot:  "before-upgrade",
+	})
+	err = container.CompleteContainerOp(fmt.Errorf("disk full"))
+	c.Assert(err, IsNil)
+	info, err = s.APIState.Client().ContainerOperationInfo(container.Id())
+	c.Assert(err, IsNil)
+	c.Assert(info.Error, Equals, "disk full")
+
+	err = s.APIState.Client().ContainerOperation(container.Id(), "freeze", "")
+	c.Assert(err, IsNil)
+	err = container.CompleteContainerOp(nil)
+	c.Assert(err, IsNil)
+	_, err = s.APIState.Client().ContainerOperationInfo(container.Id())
+	c.Assert(params.ErrCode(err), Equals, params.CodeNotFound)
+}
+
 func (s *clientSuite) TestClientServiceUnexpose(c *C) {
 	s.setUpScenario(c)
 	serviceName := "wordpress"
@@ -318,11 +416,11 @@ func (s *clientSuite) TestClientServiceDeployCharmErrors(c *C) {
 		"cs:wordpress":                   `charm URL without series: "cs:wordpress"`,
 		"cs:precise/wordpress":           "charm url must include revision",
 		"cs:precise/wordpress-999999":    `cannot get charm: charm not found in mock store: cs:precise/wordpress-999999`,
-		"local:precise/wordpress-999999": `charm url has unsupported schema "local"`,
+		"local:precise/wordpress-999999": `charm "local:precise/wordpress-999999" not found`,
 	} {
 		c.Logf("test %s", url)
 		err := s.APIState.Client().ServiceDeploy(
-			url, "service", 1, "", constraints.Value{},
+			url, "service", 1, "", constraints.Value{}, "",
 		)
 		c.Check(err, ErrorMatches, expect)
 		_, err = s.State.Service("service")
@@ -338,7 +436,7 @@ func (s *clientSuite) TestClientServiceDeployPrincipal(c *C) {
 	curl, bundle := addCharm(c, store, "dummy")
 	mem4g := constraints.MustParse("mem=4G")
 	err := s.APIState.Client().ServiceDeploy(
-		curl.String(), "service", 3, "", mem4g,
+		curl.String(), "service", 3, "", mem4g, "",
 	)
 	c.Assert(err, IsNil)
 	service, err := s.State.Service("service")
@@ -366,12 +464,32 @@ func (s *clientSuite) TestClientServiceDeployPrincipal(c *C) {
 	}
 }
 
+func (s *clientSuite) TestClientServiceDeployToMachine(c *C) {
+	store, restore := makeMockCharmStore()
+	defer restore()
+	curl, _ := addCharm(c, store, "dummy")
+	machine, err := s.State.AddMachine("precise", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	err = s.APIState.Client().ServiceDeploy(
+		curl.String(), "service", 1, "", constraints.Value{}, machine.Id(),
+	)
+	c.Assert(err, IsNil)
+	service, err := s.State.Service("service")
+	c.Assert(err, IsNil)
+	units, err := service.AllUnits()
+	c.Assert(err, IsNil)
+	c.Assert(units, HasLen, 1)
+	mid, err := units[0].AssignedMachineId()
+	c.Assert(err, IsNil)
+	c.Assert(mid, Equals, machine.Id())
+}
+
 func (s *clientSuite) TestClientServiceDeploySubordinate(c *C) {
 	store, restore := makeMockCharmStore()
 	defer restore()
 	curl, bundle := addCharm(c, store, "logging")
 	err := s.APIState.Client().ServiceDeploy(
-		curl.String(), "service-name", 0, "", constraints.Value{},
+		curl.String(), "service-name", 0, "", constraints.Value{}, "",
 	)
 	service, err := s.State.Service("service-name")
 	c.Assert(err, IsNil)
@@ -394,7 +512,7 @@ func (s *clientSuite) TestClientServiceDeployConfig(c *C) {
 	defer restore()
 	curl, _ := addCharm(c, store, "dummy")
 	err := s.APIState.Client().ServiceDeploy(
-		curl.String(), "service-name", 1, "service-name:\n  username: fred", constraints.Value{},
+		curl.String(), "service-name", 1, "service-name:\n  username: fred", constraints.Value{}, "",
 	)
 	c.Assert(err, IsNil)
 	service, err := s.State.Service("service-name")
@@ -411,7 +529,7 @@ func (s *clientSuite) TestClientServiceDeployConfigError(c *C) {
 	defer restore()
 	curl, _ := addCharm(c, store, "dummy")
 	err := s.APIState.Client().ServiceDeploy(
-		curl.String(), "service-name", 1, "service-name:\n  skill-level: fred", constraints.Value{},
+		curl.String(), "service-name", 1, "service-name:\n  skill-level: fred", constraints.Value{}, "",
 	)
 	c.Assert(err, ErrorMatches, `option "skill-level" expected int, got "fred"`)
 	_, err = s.State.Service("service-name")
@@ -421,7 +539,7 @@ func (s *clientSuite) TestClientServiceDeployConfigError(c *C) {
 func (s *clientSuite) deployServiceForTests(c *C, store *coretesting.MockCharmStore) {
 	curl, _ := addCharm(c, store, "dummy")
 	err := s.APIState.Client().ServiceDeploy(curl.String(),
-		"service", 1, "", constraints.Value{},
+		"service", 1, "", constraints.Value{}, "",
 	)
 	c.Assert(err, IsNil)
 }
@@ -469,7 +587,7 @@ func (s *clientSuite) TestClientServiceUpdateSetCharmErrors(c *C) {
 		"cs:wordpress":                   `charm URL without series: "cs:wordpress"`,
 		"cs:precise/wordpress":           "charm url must include revision",
 		"cs:precise/wordpress-999999":    `cannot get charm: charm not found in mock store: cs:precise/wordpress-999999`,
-		"local:precise/wordpress-999999": `charm url has unsupported schema "local"`,
+		"local:precise/wordpress-999999": `charm "local:precise/wordpress-999999" not found`,
 	} {
 		c.Logf("test %s", charmUrl)
 		args := params.ServiceUpdate{
@@ -650,7 +768,7 @@ func (s *clientSuite) TestClientServiceSetCharm(c *C) {
 	defer restore()
 	curl, _ := addCharm(c, store, "dummy")
 	err := s.APIState.Client().ServiceDeploy(
-		curl.String(), "service", 3, "", constraints.Value{},
+		curl.String(), "service", 3, "", constraints.Value{}, "",
 	)
 	c.Assert(err, IsNil)
 	addCharm(c, store, "wordpress")
@@ -673,7 +791,7 @@ func (s *clientSuite) TestClientServiceSetCharmForce(c *C) {
 	defer restore()
 	curl, _ := addCharm(c, store, "dummy")
 	err := s.APIState.Client().ServiceDeploy(
-		curl.String(), "service", 3, "", constraints.Value{},
+		curl.String(), "service", 3, "", constraints.Value{}, "",
 	)
 	c.Assert(err, IsNil)
 	addCharm(c, store, "wordpress")
@@ -710,7 +828,7 @@ func (s *clientSuite) TestClientServiceSetCharmErrors(c *C) {
 		"cs:wordpress":                   `charm URL without series: "cs:wordpress"`,
 		"cs:precise/wordpress":           "charm url must include revision",
 		"cs:precise/wordpress-999999":    `cannot get charm: charm not found in mock store: cs:precise/wordpress-999999`,
-		"local:precise/wordpress-999999": `charm url has unsupported schema "local"`,
+		"local:precise/wordpress-999999": `charm "local:precise/wordpress-999999" not found`,
 	} {
 		c.Logf("test %s", url)
 		err := s.APIState.Client().ServiceSetCharm(
@@ -811,3 +929,547 @@ func (s *clientSuite) TestClientWatchAll(c *C) {
 		}
 	}
 }
+
+// setDebugLogPath points WatchDebugLog at a log file in a temporary
+// directory, and returns its path.
+func setDebugLogPath(c *C) (logPath string, restore func()) {
+	logPath = filepath.Join(c.MkDir(), "all-machines.log")
+	origPath := client.DebugLogPath
+	client.DebugLogPath = logPath
+	return logPath, func() { client.DebugLogPath = origPath }
+}
+
+func (s *clientSuite) TestClientWatchDebugLog(c *C) {
+	logPath, restore := setDebugLogPath(c)
+	defer restore()
+	err := ioutil.WriteFile(logPath, []byte(
+		"machine-0: 2013-10-16 12:00:00 INFO juju.worker runner.go:42 starting\n"+
+			"unit-mysql-0: 2013-10-16 12:00:01 DEBUG juju.worker.uniter uniter.go:12 hook started\n"+
+			"unit-mysql-0: 2013-10-16 12:00:02 ERROR juju.worker.uniter uniter.go:99 hook failed\n",
+	), 0644)
+	c.Assert(err, IsNil)
+	watcher, err := s.APIState.Client().WatchDebugLog(params.DebugLog{
+		IncludeEntity: []string{"unit-mysql-*"},
+		Backlog:       1,
+	})
+	c.Assert(err, IsNil)
+	defer func() {
+		err := watcher.Stop()
+		c.Assert(err, IsNil)
+	}()
+	records, err := watcher.Next()
+	c.Assert(err, IsNil)
+	c.Assert(records, DeepEquals, []params.LogRecord{{
+		Entity:   "unit-mysql-0",
+		Time:     time.Date(2013, 10, 16, 12, 0, 2, 0, time.UTC),
+		Level:    loggo.ERROR,
+		Module:   "juju.worker.uniter",
+		Location: "uniter.go:99",
+		Message:  "hook failed",
+	}})
+
+	f, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0)
+	c.Assert(err, IsNil)
+	_, err = f.WriteString(
+		"machine-0: 2013-10-16 12:00:03 INFO juju.worker runner.go:42 stopping\n" +
+			"unit-mysql-0: 2013-10-16 12:00:04 INFO juju.worker.uniter uniter.go:12 hook started\n",
+	)
+	f.Close()
+	c.Assert(err, IsNil)
+	records, err = watcher.Next()
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].Time, DeepEquals, time.Date(2013, 10, 16, 12, 0, 4, 0, time.UTC))
+}
+
+func (s *clientSuite) TestClientWatchDebugLogMissingLog(c *C) {
+	_, restore := setDebugLogPath(c)
+	defer restore()
+	_, err := s.APIState.Client().WatchDebugLog(params.DebugLog{})
+	c.Assert(err, ErrorMatches, "cannot read debug log: .*")
+}
+
+func (s *clientSuite) TestClientAddMachines(c *C) {
+	results, err := s.APIState.Client().AddMachines([]params.AddMachineParams{{
+		Jobs: []params.MachineJob{params.JobHostUnits},
+	}, {
+		Series:      "quantal",
+		Constraints: constraints.MustParse("mem=4G"),
+		Jobs:        []params.MachineJob{params.JobHostUnits},
+	}, {
+		ParentId:      "0",
+		ContainerType: instance.LXC,
+		Jobs:          []params.MachineJob{params.JobHostUnits},
+	}, {
+		Jobs: []params.MachineJob{"JobWhatever"},
+	}})
+	c.Assert(err, IsNil)
+	c.Assert(results, HasLen, 4)
+	c.Assert(results[0], DeepEquals, params.AddMachinesResult{Machine: "0"})
+	c.Assert(results[1], DeepEquals, params.AddMachinesResult{Machine: "1"})
+	c.Assert(results[2], DeepEquals, params.AddMachinesResult{Machine: "0/lxc/0"})
+	c.Assert(results[3].Error, ErrorMatches, `invalid machine job "JobWhatever"`)
+
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, IsNil)
+	m, err := s.State.Machine("0")
+	c.Assert(err, IsNil)
+	c.Assert(m.Series(), Equals, cfg.DefaultSeries())
+	c.Assert(m.Jobs(), DeepEquals, []state.MachineJob{state.JobHostUnits})
+	m, err = s.State.Machine("1")
+	c.Assert(err, IsNil)
+	c.Assert(m.Series(), Equals, "quantal")
+	cons, err := m.Constraints()
+	c.Assert(err, IsNil)
+	c.Assert(cons, DeepEquals, constraints.MustParse("mem=4G"))
+}
+
+func (s *clientSuite) TestClientDestroyMachines(c *C) {
+	m, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	err = s.APIState.Client().DestroyMachines(m.Id())
+	c.Assert(err, IsNil)
+	err = m.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(m.Life(), Equals, state.Dying)
+
+	err = s.APIState.Client().DestroyMachines("42")
+	c.Assert(err, ErrorMatches, "no machines were destroyed: machine 42 does not exist")
+}
+
+func (s *clientSuite) TestClientDestroyEnvironment(c *C) {
+	manager, err := s.State.AddMachine("series", state.JobManageEnviron)
+	c.Assert(err, IsNil)
+	managerInst, md := testing.StartInstance(c, s.Conn.Environ, manager.Id())
+	c.Assert(manager.SetProvisioned(managerInst.Id(), "fake_nonce", md), IsNil)
+	m, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	inst, md := testing.StartInstance(c, s.Conn.Environ, m.Id())
+	c.Assert(m.SetProvisioned(inst.Id(), "fake_nonce", md), IsNil)
+	_, err = s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+
+	err = s.APIState.Client().DestroyEnvironment()
+	c.Assert(err, IsNil)
+
+	// Only the instance of the machine hosting units is stopped.
+	insts, err := s.Conn.Environ.AllInstances()
+	c.Assert(err, IsNil)
+	var ids []instance.Id
+	for _, inst := range insts {
+		ids = append(ids, inst.Id())
+	}
+	c.Assert(ids, DeepEquals, []instance.Id{managerInst.Id()})
+}
+
+func (s *clientSuite) TestClientEnvironmentConfig(c *C) {
+	attrs, err := s.APIState.Client().EnvironmentConfig()
+	c.Assert(err, IsNil)
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, IsNil)
+	c.Assert(attrs["name"], Equals, "dummyenv")
+	c.Assert(attrs["default-series"], Equals, cfg.DefaultSeries())
+}
+
+func (s *clientSuite) TestClientSetEnvironmentConfig(c *C) {
+	err := s.APIState.Client().SetEnvironmentConfig(map[string]interface{}{
+		"default-series": "raring",
+		"some-key":       "value",
+	})
+	c.Assert(err, IsNil)
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, IsNil)
+	c.Assert(cfg.DefaultSeries(), Equals, "raring")
+	c.Assert(cfg.AllAttrs()["some-key"], Equals, "value")
+
+	err = s.APIState.Client().SetEnvironmentConfig(map[string]interface{}{
+		"agent-version": "1.2.3",
+	})
+	c.Assert(err, ErrorMatches, "agent-version must be set with UpgradeJuju")
+	err = s.APIState.Client().SetEnvironmentConfig(map[string]interface{}{
+		"firewall-mode": "global",
+	})
+	c.Assert(err, ErrorMatches, "cannot change firewall-mode from .* to .*")
+}
+
+func (s *clientSuite) TestClientUpgradeJuju(c *C) {
+	err := s.APIState.Client().UpgradeJuju(version.MustParse("9.9.9"))
+	c.Assert(err, IsNil)
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, IsNil)
+	vers, ok := cfg.AgentVersion()
+	c.Assert(ok, Equals, true)
+	c.Assert(vers, Equals, version.MustParse("9.9.9"))
+}
+
+func (s *clientSuite) TestClientEnvironmentConstraints(c *C) {
+	cons := constraints.MustParse("mem=4G cpu-cores=2")
+	err := s.APIState.Client().SetEnvironmentConstraints(cons)
+	c.Assert(err, IsNil)
+	stateCons, err := s.State.EnvironConstraints()
+	c.Assert(err, IsNil)
+	c.Assert(stateCons, DeepEquals, cons)
+	obtained, err := s.APIState.Client().GetEnvironmentConstraints()
+	c.Assert(err, IsNil)
+	c.Assert(obtained, DeepEquals, cons)
+}
+
+func (s *clientSuite) TestClientPublicAndPrivateAddress(c *C) {
+	m, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	_, err = s.APIState.Client().PublicAddress(m.Id())
+	c.Assert(err, ErrorMatches, "machine 0 is not provisioned")
+	c.Assert(params.ErrCode(err), Equals, params.CodeNotProvisioned)
+
+	inst, md := testing.StartInstance(c, s.Conn.Environ, m.Id())
+	c.Assert(m.SetProvisioned(inst.Id(), "fake_nonce", md), IsNil)
+	addr, err := s.APIState.Client().PublicAddress(m.Id())
+	c.Assert(err, IsNil)
+	c.Assert(addr, Equals, "dummyenv-0.dns")
+	_, err = s.APIState.Client().PrivateAddress(m.Id())
+	c.Assert(err, ErrorMatches, `machine "0" has no internal address`)
+
+	// Recorded addresses take precedence over the provider.
+	err = m.SetAddresses([]instance.Address{
+		instance.NewScopedAddress("10.0.0.1"),
+		instance.NewScopedAddress("8.8.8.8"),
+	})
+	c.Assert(err, IsNil)
+	addr, err = s.APIState.Client().PublicAddress(m.Id())
+	c.Assert(err, IsNil)
+	c.Assert(addr, Equals, "8.8.8.8")
+	addr, err = s.APIState.Client().PrivateAddress(m.Id())
+	c.Assert(err, IsNil)
+	c.Assert(addr, Equals, "10.0.0.1")
+
+	service, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	u, err := service.AddUnit()
+	c.Assert(err, IsNil)
+	_, err = s.APIState.Client().PublicAddress(u.Name())
+	c.Assert(err, ErrorMatches, `unit "dummy/0" has no public address`)
+	c.Assert(u.SetPublicAddress("public.example.com"), IsNil)
+	c.Assert(u.SetPrivateAddress("private.example.com"), IsNil)
+	addr, err = s.APIState.Client().PublicAddress(u.Name())
+	c.Assert(err, IsNil)
+	c.Assert(addr, Equals, "public.example.com")
+	addr, err = s.APIState.Client().PrivateAddress(u.Name())
+	c.Assert(err, IsNil)
+	c.Assert(addr, Equals, "private.example.com")
+
+	_, err = s.APIState.Client().PublicAddress("dummy/1")
+	c.Assert(err, ErrorMatches, `unit "dummy/1" not found`)
+	_, err = s.APIState.Client().PublicAddress("foo")
+	c.Assert(err, ErrorMatches, `unknown unit or machine "foo"`)
+}
+
+func (s *clientSuite) TestClientAddCharm(c *C) {
+	store, restore := makeMockCharmStore()
+	defer restore()
+	curl, bundle := addCharm(c, store, "dummy")
+	err := s.APIState.Client().AddCharm(curl)
+	c.Assert(err, IsNil)
+	sch, err := s.State.Charm(curl)
+	c.Assert(err, IsNil)
+	c.Assert(sch.Meta(), DeepEquals, bundle.Meta())
+
+	err = s.APIState.Client().AddCharm(charm.MustParseURL("cs:series/dummy"))
+	c.Assert(err, ErrorMatches, "charm url must include revision")
+}
+
+func (s *clientSuite) TestClientAddLocalCharm(c *C) {
+	curl := charm.MustParseURL("local:series/dummy-1")
+	ch := coretesting.Charms.Dir("dummy")
+	err := s.APIState.Client().AddLocalCharm(curl, ch)
+	c.Assert(err, IsNil)
+	sch, err := s.State.Charm(curl)
+	c.Assert(err, IsNil)
+	c.Assert(sch.Meta().Name, Equals, "dummy")
+
+	// The charm can now be deployed.
+	err = s.APIState.Client().ServiceDeploy(curl.String(), "service", 1, "", constraints.Value{}, "")
+	c.Assert(err, IsNil)
+	service, err := s.State.Service("service")
+	c.Assert(err, IsNil)
+	obtained, err := s.APIState.Client().ServiceGetCharmURL(service.Name())
+	c.Assert(err, IsNil)
+	c.Assert(obtained, DeepEquals, curl)
+
+	err = s.APIState.Client().AddLocalCharm(charm.MustParseURL("cs:series/dummy-1"), ch)
+	c.Assert(err, ErrorMatches, `expected charm URL with local schema, got "cs:series/dummy-1"`)
+}
+
+func (s *clientSuite) TestClientServicePutResource(c *C) {
+	service, err := s.State.AddService("resources", s.AddTestingCharm(c, "resources"))
+	c.Assert(err, IsNil)
+	err = s.APIState.Client().ServicePutResource("resources", "jdk", strings.NewReader("jdk content"))
+	c.Assert(err, IsNil)
+	res, err := service.Resource("jdk")
+	c.Assert(err, IsNil)
+	c.Assert(res.Size(), Equals, int64(len("jdk content")))
+
+	// Only the location of the content is recorded in the audit log.
+	entries, err := s.APIState.Client().AuditLog(params.AuditLog{Limit: 1})
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Method, Equals, "ServiceSetResource")
+	c.Assert(entries[0].Args, Matches, `{"Name":"jdk","ServiceName":"resources","Sha256":"[0-9a-f]+","Size":11,"URL":".*"}`)
+
+	err = s.APIState.Client().ServicePutResource("resources", "jre", strings.NewReader("jre content"))
+	c.Assert(err, ErrorMatches, `charm ".*" declares no resource "jre"`)
+	err = s.APIState.Client().ServicePutResource("unknown", "jdk", strings.NewReader("jdk content"))
+	c.Assert(err, ErrorMatches, `service "unknown" not found`)
+
+	// Uploads require write access.
+	s.setUpScenario(c)
+	st := s.openAs(c, "user-reader")
+	defer st.Close()
+	err = st.Client().ServicePutResource("resources", "jdk", strings.NewReader("jdk content"))
+	c.Assert(err, ErrorMatches, "permission denied")
+}
+
+func (s *clientSuite) TestClientServiceCharmRelations(c *C) {
+	s.setUpScenario(c)
+	relations, err := s.APIState.Client().ServiceCharmRelations("wordpress")
+	c.Assert(err, IsNil)
+	c.Assert(relations, DeepEquals, []string{"cache", "db", "juju-info", "logging-dir", "monitoring-port", "url"})
+	_, err = s.APIState.Client().ServiceCharmRelations("blah")
+	c.Assert(err, ErrorMatches, `service "blah" not found`)
+}
+
+func (s *clientSuite) TestClientServiceHookPolicy(c *C) {
+	_, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	policy := params.HookPolicy{Timeout: time.Minute, Retry: true}
+	err = s.APIState.Client().ServiceSetHookPolicy("dummy", policy)
+	c.Assert(err, IsNil)
+	obtained, err := s.APIState.Client().ServiceGetHookPolicy("dummy")
+	c.Assert(err, IsNil)
+	c.Assert(obtained, DeepEquals, policy)
+}
+
+func (s *clientSuite) TestClientActions(c *C) {
+	service, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	_, err = service.AddUnit()
+	c.Assert(err, IsNil)
+	id, err := s.APIState.Client().EnqueueAction("dummy/0", "snapshot", map[string]string{"compression": "9"})
+	c.Assert(err, IsNil)
+	info, err := s.APIState.Client().ActionInfo(id)
+	c.Assert(err, IsNil)
+	c.Assert(info, DeepEquals, &api.ActionInfo{
+		Id:       id,
+		UnitName: "dummy/0",
+		Name:     "snapshot",
+		Params:   map[string]interface{}{"outfile": "foo.bz2", "compression": 9.0},
+		Status:   "pending",
+	})
+
+	_, err = s.APIState.Client().EnqueueAction("dummy/0", "backup", nil)
+	c.Assert(err, ErrorMatches, `unknown action "backup"`)
+	_, err = s.APIState.Client().ActionInfo("42")
+	c.Assert(err, ErrorMatches, `action "42" not found`)
+}
+
+func (s *clientSuite) TestClientUsers(c *C) {
+	client := s.APIState.Client()
+	err := client.AddUser("bob", "user-bob password", "read")
+	c.Assert(err, IsNil)
+	u, err := s.State.User("bob")
+	c.Assert(err, IsNil)
+	c.Assert(u.Access(), Equals, state.ReadAccess)
+	c.Assert(u.PasswordValid("user-bob password"), Equals, true)
+
+	err = client.AddUser("bob", "other password", "write")
+	c.Assert(err, ErrorMatches, "user already exists")
+	err = client.AddUser("alice", "", "write")
+	c.Assert(err, ErrorMatches, "password is empty")
+	err = client.AddUser("alice", "alice password", "all")
+	c.Assert(err, ErrorMatches, `invalid access "all"`)
+
+	// Logging in records the time of the login.
+	st := s.openAs(c, "user-bob")
+	defer st.Close()
+	users, err := client.ListUsers()
+	c.Assert(err, IsNil)
+	c.Assert(users, HasLen, 2)
+	c.Assert(users[0].Name, Equals, "admin")
+	c.Assert(users[0].Access, Equals, "admin")
+	c.Assert(users[1].Name, Equals, "bob")
+	c.Assert(users[1].Access, Equals, "read")
+	c.Assert(users[1].DateCreated.Equal(u.DateCreated()), Equals, true)
+	c.Assert(users[1].LastLogin.IsZero(), Equals, false)
+
+	// Users may change their own password, but not that of others.
+	err = st.Client().SetUserPassword("bob", "new password")
+	c.Assert(err, IsNil)
+	err = st.Client().SetUserPassword("admin", "new password")
+	c.Assert(err, ErrorMatches, "permission denied")
+	err = u.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(u.PasswordValid("new password"), Equals, true)
+
+	err = client.RemoveUser("bob")
+	c.Assert(err, IsNil)
+	_, err = s.State.User("bob")
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+	err = client.RemoveUser("bob")
+	c.Assert(err, ErrorMatches, `user "bob" not found`)
+	err = client.RemoveUser("admin")
+	c.Assert(err, ErrorMatches, `cannot remove user "admin"`)
+}
+
+func (s *clientSuite) TestClientUserAccessChanged(c *C) {
+	s.setUpScenario(c)
+	st := s.openAs(c, "user-other")
+	defer st.Close()
+	err := st.Client().ServiceExpose("wordpress")
+	c.Assert(err, IsNil)
+	watcher, err := st.Client().WatchAll()
+	c.Assert(err, IsNil)
+	_, err = watcher.Next()
+	c.Assert(err, IsNil)
+
+	// Changes to the access of a user apply to the connections they
+	// have already opened.
+	u, err := s.State.User("other")
+	c.Assert(err, IsNil)
+	err = u.SetAccess(state.ReadAccess)
+	c.Assert(err, IsNil)
+	err = st.Client().ServiceUnexpose("wordpress")
+	c.Assert(err, ErrorMatches, "permission denied")
+	_, err = st.Client().ServiceGet("wordpress")
+	c.Assert(err, IsNil)
+
+	err = u.Remove()
+	c.Assert(err, IsNil)
+	_, err = st.Client().ServiceGet("wordpress")
+	c.Assert(err, ErrorMatches, "permission denied")
+	_, err = watcher.Next()
+	c.Assert(err, ErrorMatches, "permission denied")
+}
+
+func (s *clientSuite) TestClientAuditLog(c *C) {
+	s.setUpScenario(c)
+	// Times are stored to the millisecond.
+	since := time.Now().Truncate(time.Millisecond)
+	client := s.APIState.Client()
+	err := client.ServiceExpose("wordpress")
+	c.Assert(err, IsNil)
+	err = client.ServiceExpose("unknown")
+	c.Assert(err, NotNil)
+	err = client.AddUser("bob", "s3cret", "read")
+	c.Assert(err, IsNil)
+	err = client.SetEnvironmentConfig(map[string]interface{}{"secret": "pssst"})
+	c.Assert(err, IsNil)
+	st := s.openAs(c, "user-reader")
+	defer st.Close()
+	err = st.Client().ServiceUnexpose("wordpress")
+	c.Assert(err, ErrorMatches, "permission denied")
+
+	entries, err := client.AuditLog(params.AuditLog{Since: since})
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 5)
+	for i := range entries {
+		c.Assert(entries[i].Time.Before(since), Equals, false)
+		entries[i].Time = time.Time{}
+	}
+	c.Assert(entries, DeepEquals, []params.AuditEntry{{
+		Tag:      "user-admin",
+		Method:   "ServiceExpose",
+		Entities: []string{"service-wordpress"},
+		Args:     `{"ServiceName":"wordpress"}`,
+	}, {
+		Tag:      "user-admin",
+		Method:   "ServiceExpose",
+		Entities: []string{"service-unknown"},
+		Args:     `{"ServiceName":"unknown"}`,
+		Error:    `service "unknown" not found`,
+	}, {
+		Tag:      "user-admin",
+		Method:   "AddUser",
+		Entities: []string{"user-bob"},
+		Args:     `{"Access":"read","Name":"bob","Password":"[redacted]"}`,
+	}, {
+		Tag:    "user-admin",
+		Method: "SetEnvironmentConfig",
+		Args:   `{"Config":{"secret":"[redacted]"}}`,
+	}, {
+		Tag:      "user-reader",
+		Method:   "ServiceUnexpose",
+		Entities: []string{"service-wordpress"},
+		Args:     `{"ServiceName":"wordpress"}`,
+		Error:    "permission denied",
+	}})
+
+	entries, err = client.AuditLog(params.AuditLog{Since: since, User: "reader"})
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Method, Equals, "ServiceUnexpose")
+
+	entries, err = client.AuditLog(params.AuditLog{Entity: "service-wordpress", Limit: 1})
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Tag, Equals, "user-reader")
+
+	_, err = st.Client().AuditLog(params.AuditLog{})
+	c.Assert(err, ErrorMatches, "permission denied")
+}
+
+func (s *clientSuite) TestClientAuditLogSecretOptions(c *C) {
+	ch := s.AddTestingCharm(c, "typed-config")
+	since := time.Now().Truncate(time.Millisecond)
+	client := s.APIState.Client()
+	err := client.ServiceDeploy(
+		ch.URL().String(), "secrets", 1,
+		"secrets:\n  password: deploy-secret\n  workers: 2\nothers:\n  password: other-secret\n",
+		constraints.Value{}, "",
+	)
+	c.Assert(err, IsNil)
+	err = client.ServiceSet("secrets", map[string]string{"password": "set-secret", "flavour": "chocolate"})
+	c.Assert(err, IsNil)
+	err = client.ServiceSetYAML("secrets", "secrets:\n  password: yaml-secret\n")
+	c.Assert(err, IsNil)
+	// The options of a charm that cannot be found are all redacted.
+	err = client.ServiceDeploy("local:series/missing-1", "missing", 1, "missing:\n  colour: blue\n", constraints.Value{}, "")
+	c.Assert(err, NotNil)
+
+	entries, err := client.AuditLog(params.AuditLog{Since: since})
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 4)
+	for _, entry := range entries {
+		c.Assert(entry.Args, Not(Matches), ".*-secret.*")
+	}
+	parseYAML := func(data string) map[string]interface{} {
+		var settings map[string]interface{}
+		err := goyaml.Unmarshal([]byte(data), &settings)
+		c.Assert(err, IsNil)
+		return settings
+	}
+
+	var deploy params.ServiceDeploy
+	err = json.Unmarshal([]byte(entries[0].Args), &deploy)
+	c.Assert(err, IsNil)
+	c.Assert(parseYAML(deploy.ConfigYAML), DeepEquals, map[string]interface{}{
+		"secrets": map[interface{}]interface{}{"password": "[redacted]", "workers": 2},
+		"others":  "[redacted]",
+	})
+
+	var set params.ServiceSet
+	err = json.Unmarshal([]byte(entries[1].Args), &set)
+	c.Assert(err, IsNil)
+	c.Assert(set.Options, DeepEquals, map[string]string{"password": "[redacted]", "flavour": "chocolate"})
+
+	var setYAML params.ServiceSetYAML
+	err = json.Unmarshal([]byte(entries[2].Args), &setYAML)
+	c.Assert(err, IsNil)
+	c.Assert(parseYAML(setYAML.Config), DeepEquals, map[string]interface{}{
+		"secrets": map[interface{}]interface{}{"password": "[redacted]"},
+	})
+
+	err = json.Unmarshal([]byte(entries[3].Args), &deploy)
+	c.Assert(err, IsNil)
+	c.Assert(parseYAML(deploy.ConfigYAML), DeepEquals, map[string]interface{}{
+		"missing": map[interface{}]interface{}{"colour": "[redacted]"},
+	})
+}
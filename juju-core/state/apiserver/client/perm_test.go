@@ -35,7 +35,11 @@ var operationPermTests = []struct {
 }{{
 	about: "Client.Status",
 	op:    opClientStatus,
-	allow: []string{"user-admin", "user-other"},
+	allow: []string{"user-admin", "user-other", "user-reader"},
+}, {
+	about: "Client.FullStatus",
+	op:    opClientFullStatus,
+	allow: []string{"user-admin", "user-other", "user-reader"},
 }, {
 	about: "Client.ServiceSet",
 	op:    opClientServiceSet,
@@ -47,11 +51,19 @@ var operationPermTests = []struct {
 }, {
 	about: "Client.ServiceGet",
 	op:    opClientServiceGet,
-	allow: []string{"user-admin", "user-other"},
+	allow: []string{"user-admin", "user-other", "user-reader"},
 }, {
 	about: "Client.Resolved",
 	op:    opClientResolved,
 	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ContainerOperation",
+	op:    opClientContainerOperation,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ContainerOperationInfo",
+	op:    opClientContainerOperationInfo,
+	allow: []string{"user-admin", "user-other", "user-reader"},
 }, {
 	about: "Client.ServiceExpose",
 	op:    opClientServiceExpose,
@@ -75,7 +87,7 @@ var operationPermTests = []struct {
 }, {
 	about: "Client.GetAnnotations",
 	op:    opClientGetAnnotations,
-	allow: []string{"user-admin", "user-other"},
+	allow: []string{"user-admin", "user-other", "user-reader"},
 }, {
 	about: "Client.SetAnnotations",
 	op:    opClientSetAnnotations,
@@ -95,19 +107,27 @@ var operationPermTests = []struct {
 }, {
 	about: "Client.GetServiceConstraints",
 	op:    opClientGetServiceConstraints,
-	allow: []string{"user-admin", "user-other"},
+	allow: []string{"user-admin", "user-other", "user-reader"},
 }, {
 	about: "Client.SetServiceConstraints",
 	op:    opClientSetServiceConstraints,
 	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.EnvironmentConfig",
+	op:    opClientEnvironmentConfig,
+	allow: []string{"user-admin"},
+}, {
+	about: "Client.SetEnvironmentConstraints",
+	op:    opClientSetEnvironmentConstraints,
+	allow: []string{"user-admin"},
 }, {
 	about: "Client.WatchAll",
 	op:    opClientWatchAll,
-	allow: []string{"user-admin", "user-other"},
+	allow: []string{"user-admin", "user-other", "user-reader"},
 }, {
 	about: "Client.CharmInfo",
 	op:    opClientCharmInfo,
-	allow: []string{"user-admin", "user-other"},
+	allow: []string{"user-admin", "user-other", "user-reader"},
 }, {
 	about: "Client.AddRelation",
 	op:    opClientAddRelation,
@@ -116,6 +136,18 @@ var operationPermTests = []struct {
 	about: "Client.DestroyRelation",
 	op:    opClientDestroyRelation,
 	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.AddUser",
+	op:    opClientAddUser,
+	allow: []string{"user-admin"},
+}, {
+	about: "Client.ListUsers",
+	op:    opClientListUsers,
+	allow: []string{"user-admin"},
+}, {
+	about: "Client.AuditLog",
+	op:    opClientAuditLog,
+	allow: []string{"user-admin"},
 }}
 
 // allowed returns the set of allowed entities given an allow list and a
@@ -199,6 +231,16 @@ func opClientStatus(c *C, st *api.State, mst *state.State) (func(), error) {
 	return func() {}, nil
 }
 
+func opClientFullStatus(c *C, st *api.State, mst *state.State) (func(), error) {
+	status, err := st.Client().FullStatus(nil)
+	if err != nil {
+		c.Check(status, IsNil)
+		return func() {}, err
+	}
+	c.Assert(status.Machines, HasLen, len(scenarioStatus.Machines))
+	return func() {}, nil
+}
+
 func resetBlogTitle(c *C, st *api.State) func() {
 	return func() {
 		err := st.Client().ServiceSet("wordpress", map[string]string{
@@ -272,6 +314,26 @@ func opClientResolved(c *C, st *api.State, _ *state.State) (func(), error) {
 	return func() {}, nil
 }
 
+func opClientContainerOperation(c *C, st *api.State, _ *state.State) (func(), error) {
+	err := st.Client().ContainerOperation("0", "freeze", "")
+	if err != nil && params.ErrCode(err) == params.CodeUnauthorized {
+		return func() {}, err
+	}
+	// Machine 0 is not a container, so an authorized call fails too.
+	c.Assert(err, ErrorMatches, `cannot freeze machine 0: machine is not a container`)
+	return func() {}, nil
+}
+
+func opClientContainerOperationInfo(c *C, st *api.State, _ *state.State) (func(), error) {
+	_, err := st.Client().ContainerOperationInfo("0")
+	if err != nil && params.ErrCode(err) == params.CodeUnauthorized {
+		return func() {}, err
+	}
+	// No operation was requested on machine 0.
+	c.Assert(params.ErrCode(err), Equals, params.CodeNotFound)
+	return func() {}, nil
+}
+
 func opClientGetAnnotations(c *C, st *api.State, mst *state.State) (func(), error) {
 	ann, err := st.Client().GetAnnotations("service-wordpress")
 	if err != nil {
@@ -294,7 +356,7 @@ func opClientSetAnnotations(c *C, st *api.State, mst *state.State) (func(), erro
 }
 
 func opClientServiceDeploy(c *C, st *api.State, mst *state.State) (func(), error) {
-	err := st.Client().ServiceDeploy("mad:bad/url-1", "x", 1, "", constraints.Value{})
+	err := st.Client().ServiceDeploy("mad:bad/url-1", "x", 1, "", constraints.Value{}, "")
 	if err.Error() == `charm URL has invalid schema: "mad:bad/url-1"` {
 		err = nil
 	}
@@ -325,7 +387,7 @@ func opClientServiceSetCharm(c *C, st *api.State, mst *state.State) (func(), err
 }
 
 func opClientAddServiceUnits(c *C, st *api.State, mst *state.State) (func(), error) {
-	_, err := st.Client().AddServiceUnits("nosuch", 1)
+	_, err := st.Client().AddServiceUnits("nosuch", 1, "")
 	if params.ErrCode(err) == params.CodeNotFound {
 		err = nil
 	}
@@ -362,6 +424,46 @@ func opClientSetServiceConstraints(c *C, st *api.State, mst *state.State) (func(
 	return func() {}, nil
 }
 
+func opClientEnvironmentConfig(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().EnvironmentConfig()
+	return func() {}, err
+}
+
+func opClientSetEnvironmentConstraints(c *C, st *api.State, mst *state.State) (func(), error) {
+	cons := constraints.MustParse("mem=4G")
+	err := st.Client().SetEnvironmentConstraints(cons)
+	if err != nil {
+		return func() {}, err
+	}
+	return func() {
+		err := mst.SetEnvironConstraints(constraints.Value{})
+		c.Check(err, IsNil)
+	}, nil
+}
+
+func opClientAddUser(c *C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().AddUser("newuser", "password", "read")
+	if err != nil {
+		return func() {}, err
+	}
+	return func() {
+		u, err := mst.User("newuser")
+		c.Assert(err, IsNil)
+		err = u.Remove()
+		c.Check(err, IsNil)
+	}, nil
+}
+
+func opClientListUsers(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().ListUsers()
+	return func() {}, err
+}
+
+func opClientAuditLog(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().AuditLog(params.AuditLog{})
+	return func() {}, err
+}
+
 func opClientWatchAll(c *C, st *api.State, mst *state.State) (func(), error) {
 	watcher, err := st.Client().WatchAll()
 	if err == nil {
@@ -0,0 +1,81 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"fmt"
+
+	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// AddUser adds a user with the given access to the environment.
+func (c *Client) AddUser(args params.AddUser) (err error) {
+	defer c.audit("AddUser", args, tags(names.UserTag, args.Name)...)(&err)
+	if err := c.requireAccess(state.AdminAccess); err != nil {
+		return err
+	}
+	if args.Password == "" {
+		return fmt.Errorf("password is empty")
+	}
+	_, err = c.api.state.AddUser(args.Name, args.Password, state.UserAccess(args.Access))
+	return err
+}
+
+// RemoveUser removes the given user, who can then no longer log in.
+func (c *Client) RemoveUser(args params.UserName) (err error) {
+	defer c.audit("RemoveUser", args, tags(names.UserTag, args.Name)...)(&err)
+	if err := c.requireAccess(state.AdminAccess); err != nil {
+		return err
+	}
+	user, err := c.api.state.User(args.Name)
+	if err != nil {
+		return err
+	}
+	return user.Remove()
+}
+
+// ListUsers returns information about all the users of the
+// environment.
+func (c *Client) ListUsers() (params.ListUsersResults, error) {
+	if err := c.requireAccess(state.AdminAccess); err != nil {
+		return params.ListUsersResults{}, err
+	}
+	users, err := c.api.state.AllUsers()
+	if err != nil {
+		return params.ListUsersResults{}, err
+	}
+	results := params.ListUsersResults{
+		Users: make([]params.UserInfo, len(users)),
+	}
+	for i, user := range users {
+		results.Users[i] = params.UserInfo{
+			Name:        user.Name(),
+			Access:      string(user.Access()),
+			DateCreated: user.DateCreated(),
+			LastLogin:   user.LastLogin(),
+		}
+	}
+	return results, nil
+}
+
+// SetUserPassword changes the password of the given user. Users may
+// change their own password; only admins may change that of others.
+func (c *Client) SetUserPassword(args params.SetUserPassword) (err error) {
+	defer c.audit("SetUserPassword", args, tags(names.UserTag, args.Name)...)(&err)
+	if !c.api.auth.AuthOwner(names.UserTag(args.Name)) {
+		if err := c.requireAccess(state.AdminAccess); err != nil {
+			return err
+		}
+	}
+	if args.Password == "" {
+		return fmt.Errorf("password is empty")
+	}
+	user, err := c.api.state.User(args.Name)
+	if err != nil {
+		return err
+	}
+	return user.SetPassword(args.Password)
+}
@@ -69,6 +69,8 @@ func ServerError(err error) *params.Error {
 		code = params.CodeNotAssigned
 	case state.IsHasAssignedUnitsError(err):
 		code = params.CodeHasAssignedUnits
+	case state.IsNotProvisionedError(err):
+		code = params.CodeNotProvisioned
 	default:
 		code = params.ErrCode(err)
 	}
@@ -3,6 +3,10 @@
 
 package common
 
+import (
+	"launchpad.net/juju-core/state"
+)
+
 // AuthFunc returns whether the given entity is available to some operation.
 type AuthFunc func(tag string) bool
 
@@ -34,6 +38,10 @@ type Authorizer interface {
 	// is a client user.
 	AuthClient() bool
 
+	// AuthUserAccess returns whether the authenticated entity is a
+	// client user with at least the given access to the environment.
+	AuthUserAccess(access state.UserAccess) bool
+
 	// GetAuthTag returns the tag of the authenticated entity.
 	GetAuthTag() string
 }
@@ -71,3 +71,21 @@ func (a *AgentEntityWatcher) Watch(args params.Entities) (params.NotifyWatchResu
 	}
 	return result, nil
 }
+
+// RelationUnitsChange converts a change reported by a
+// state.RelationUnitsWatcher to its API representation.
+func RelationUnitsChange(change state.RelationUnitsChange) params.RelationUnitsChange {
+	result := params.RelationUnitsChange{
+		Departed: change.Departed,
+	}
+	if change.Changed != nil {
+		result.Changed = make(map[string]params.UnitSettings)
+		for name, settings := range change.Changed {
+			result.Changed[name] = params.UnitSettings{
+				Version:  settings.Version,
+				Settings: settings.Settings,
+			}
+		}
+	}
+	return result
+}
@@ -67,6 +67,9 @@ var errorTransformTests = []struct {
 }, {
 	err:  &state.HasAssignedUnitsError{"42", []string{"a"}},
 	code: params.CodeHasAssignedUnits,
+}, {
+	err:  &state.NotProvisionedError{},
+	code: params.CodeNotProvisioned,
 }, {
 	err:  stderrors.New("an error"),
 	code: "",
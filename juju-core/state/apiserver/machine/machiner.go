@@ -6,7 +6,10 @@
 package machine
 
 import (
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/names"
 	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/apiserver/common"
 )
 
@@ -38,3 +41,31 @@ func NewMachinerAPI(st *state.State, resources *common.Resources, authorizer com
 		auth:               authorizer,
 	}, nil
 }
+
+// SetMachineAddresses records the addresses of the network interfaces
+// of each given machine, as reported by its agent.
+func (api *MachinerAPI) SetMachineAddresses(args params.SetMachinesAddresses) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.MachineAddresses)),
+	}
+	for i, arg := range args.MachineAddresses {
+		err := common.ErrPerm
+		if api.auth.AuthOwner(arg.Tag) {
+			err = api.setMachineAddresses(arg.Tag, arg.Addresses)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (api *MachinerAPI) setMachineAddresses(tag string, addresses []instance.Address) error {
+	_, id, err := names.ParseTag(tag, names.MachineTagKind)
+	if err != nil {
+		return err
+	}
+	machine, err := api.st.Machine(id)
+	if err != nil {
+		return err
+	}
+	return machine.SetMachineAddresses(addresses)
+}
@@ -0,0 +1,155 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"launchpad.net/tomb"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/apiserver/common"
+)
+
+// resourcesHandler handles the uploads of the content of service
+// resources, which are too large to be sent as RPC arguments. The
+// content is streamed to the environment storage, and the client then
+// records it for the resource with the ServiceSetResource call.
+type resourcesHandler struct {
+	srv *Server
+}
+
+func (h *resourcesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.srv.wg.Add(1)
+	defer h.srv.wg.Done()
+	// As with RPC connections, no upload is served once the server
+	// is stopping.
+	if h.srv.tomb.Err() != tomb.ErrStillAlive {
+		return
+	}
+	if r.Method != "PUT" {
+		h.sendError(w, http.StatusMethodNotAllowed, fmt.Errorf("unsupported method: %q", r.Method))
+		return
+	}
+	if err := h.authenticate(r); err != nil {
+		h.sendError(w, http.StatusUnauthorized, err)
+		return
+	}
+	query := r.URL.Query()
+	result, err := h.store(query.Get("service"), query.Get("name"), r.Body)
+	if err != nil {
+		h.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+	h.sendResult(w, http.StatusOK, result)
+}
+
+// authenticate checks that the request is made by a user with write
+// access to the environment, authenticated with HTTP basic
+// authentication. The access of the user is read for every upload.
+func (h *resourcesHandler) authenticate(r *http.Request) error {
+	tag, password, ok := basicAuth(r)
+	if !ok {
+		return common.ErrBadCreds
+	}
+	entity, err := h.srv.state.FindEntity(tag)
+	if err != nil && !errors.IsNotFoundError(err) {
+		return err
+	}
+	// As with Login, unknown entities and bad passwords are not told
+	// apart.
+	user, ok := entity.(*state.User)
+	if !ok || err != nil || !user.PasswordValid(password) {
+		return common.ErrBadCreds
+	}
+	if !user.Access().Includes(state.WriteAccess) {
+		return common.ErrPerm
+	}
+	return nil
+}
+
+// basicAuth returns the credentials given in the Authorization header
+// of the request.
+func basicAuth(r *http.Request) (tag, password string, ok bool) {
+	const prefix = "Basic "
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, prefix) {
+		return "", "", false
+	}
+	data, err := base64.StdEncoding.DecodeString(auth[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	parts := strings.SplitN(string(data), ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// store writes the content read from body for the named resource of
+// the service to the environment storage.
+func (h *resourcesHandler) store(serviceName, name string, body io.Reader) (*params.ResourceUploadResult, error) {
+	service, err := h.srv.state.Service(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	ch, _, err := service.Charm()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := ch.Meta().Resources[name]; !ok {
+		return nil, fmt.Errorf("charm %q declares no resource %q", ch, name)
+	}
+	// The content is spooled to disk, since the storage needs its size
+	// and digest before it is written.
+	f, err := ioutil.TempFile("", "juju-resource")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := io.Copy(f, body); err != nil {
+		return nil, fmt.Errorf("cannot read resource %q: %v", name, err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	conn, err := juju.NewConnFromState(h.srv.state)
+	if err != nil {
+		return nil, err
+	}
+	u, digest, size, err := conn.StoreResourceContent(service, name, f)
+	if err != nil {
+		return nil, err
+	}
+	return &params.ResourceUploadResult{
+		URL:    u.String(),
+		Sha256: digest,
+		Size:   size,
+	}, nil
+}
+
+func (h *resourcesHandler) sendError(w http.ResponseWriter, status int, err error) {
+	h.sendResult(w, status, &params.ResourceUploadResult{Error: common.ServerError(err)})
+}
+
+func (h *resourcesHandler) sendResult(w http.ResponseWriter, status int, result *params.ResourceUploadResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Errorf("state/api: cannot send resource upload result: %v", err)
+	}
+}
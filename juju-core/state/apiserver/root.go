@@ -4,6 +4,9 @@
 package apiserver
 
 import (
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/log/syslog"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/apiserver/agent"
 	"launchpad.net/juju-core/state/apiserver/client"
@@ -68,6 +71,15 @@ func (r *srvRoot) requireClient() error {
 	return nil
 }
 
+// requireUserAccess returns an error unless the current client is a
+// juju client user with at least the given access to the environment.
+func (r *srvRoot) requireUserAccess(access state.UserAccess) error {
+	if !r.AuthUserAccess(access) {
+		return common.ErrPerm
+	}
+	return nil
+}
+
 // Machiner returns an object that provides access to the Machiner API
 // facade. The id argument is reserved for future use and currently
 // needs to be empty.
@@ -168,6 +180,24 @@ func (r *srvRoot) StringsWatcher(id string) (*srvStringsWatcher, error) {
 	}, nil
 }
 
+// RelationUnitsWatcher returns an object that provides API access to
+// methods on a state.RelationUnitsWatcher. Each client has its own
+// current set of watchers, stored in r.resources.
+func (r *srvRoot) RelationUnitsWatcher(id string) (*srvRelationUnitsWatcher, error) {
+	if err := r.requireAgent(); err != nil {
+		return nil, err
+	}
+	watcher, ok := r.resources.Get(id).(*state.RelationUnitsWatcher)
+	if !ok {
+		return nil, common.ErrUnknownWatcher
+	}
+	return &srvRelationUnitsWatcher{
+		watcher:   watcher,
+		id:        id,
+		resources: r.resources,
+	}, nil
+}
+
 // AllWatcher returns an object that provides API access to methods on
 // a state/multiwatcher.Watcher, which watches any changes to the
 // state. Each client has its own current set of watchers, stored in
@@ -180,6 +210,12 @@ func (r *srvRoot) AllWatcher(id string) (*srvClientAllWatcher, error) {
 	if !ok {
 		return nil, common.ErrUnknownWatcher
 	}
+	// The access of the user is checked on every call, so that
+	// changes are no longer sent once it has been revoked.
+	if err := r.requireUserAccess(state.ReadAccess); err != nil {
+		r.resources.Stop(id)
+		return nil, err
+	}
 	return &srvClientAllWatcher{
 		watcher:   watcher,
 		id:        id,
@@ -187,6 +223,30 @@ func (r *srvRoot) AllWatcher(id string) (*srvClientAllWatcher, error) {
 	}, nil
 }
 
+// DebugLogWatcher returns an object that provides API access to methods
+// on a syslog.Tailer, which follows the accumulated log of the
+// environment.
+func (r *srvRoot) DebugLogWatcher(id string) (*srvDebugLogWatcher, error) {
+	if err := r.requireClient(); err != nil {
+		return nil, err
+	}
+	tailer, ok := r.resources.Get(id).(*syslog.Tailer)
+	if !ok {
+		return nil, common.ErrUnknownWatcher
+	}
+	// The access of the user is checked on every call, so that
+	// changes are no longer sent once it has been revoked.
+	if err := r.requireUserAccess(state.ReadAccess); err != nil {
+		r.resources.Stop(id)
+		return nil, err
+	}
+	return &srvDebugLogWatcher{
+		tailer:    tailer,
+		id:        id,
+		resources: r.resources,
+	}, nil
+}
+
 // Pinger returns object with a single "Ping" method that does nothing.
 func (r *srvRoot) Pinger(id string) (srvPinger, error) {
 	return srvPinger{}, nil
@@ -227,6 +287,25 @@ func (r *srvRoot) AuthClient() bool {
 	return !isAgent(r.entity)
 }
 
+// AuthUserAccess returns whether the authenticated entity is a client
+// user with at least the given access to the environment. The user is
+// read again every time, so that users removed or given less access
+// lose it on the connections they have already opened.
+func (r *srvRoot) AuthUserAccess(access state.UserAccess) bool {
+	user, ok := r.entity.(*state.User)
+	if !ok {
+		return false
+	}
+	current, err := r.srv.state.User(user.Name())
+	if err != nil {
+		if !errors.IsNotFoundError(err) {
+			log.Errorf("state/api: cannot read user %q: %v", user.Name(), err)
+		}
+		return false
+	}
+	return current.Access().Includes(access)
+}
+
 // GetAuthTag returns the tag of the authenticated entity.
 func (r *srvRoot) GetAuthTag() string {
 	return r.entity.Tag()
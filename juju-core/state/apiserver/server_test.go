@@ -158,3 +158,42 @@ func (s *serverSuite) TestMachineLoginStartsPinger(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(alive, Equals, false)
 }
+
+func (s *serverSuite) TestUnitLoginStartsPinger(c *C) {
+	// Create a new service and unit to verify "agent alive" behavior.
+	service, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	unit, err := service.AddUnit()
+	c.Assert(err, IsNil)
+	err = unit.SetPassword("password")
+	c.Assert(err, IsNil)
+
+	// Not alive yet.
+	s.State.Sync()
+	alive, err := unit.AgentAlive()
+	c.Assert(err, IsNil)
+	c.Assert(alive, Equals, false)
+
+	// Login as the unit agent of the created unit.
+	st := s.OpenAPIAs(c, unit.Tag(), "password")
+	defer st.Close()
+
+	// Make sure the pinger has started.
+	s.State.Sync()
+	unit.WaitAgentAlive(coretesting.LongWait)
+	alive, err = unit.AgentAlive()
+	c.Assert(err, IsNil)
+	c.Assert(alive, Equals, true)
+
+	// Now make sure it stops when connection is closed.
+	c.Assert(st.Close(), IsNil)
+
+	// Sync, then wait for a bit to make sure the state is updated.
+	s.State.Sync()
+	<-time.After(coretesting.ShortWait)
+	s.State.Sync()
+
+	alive, err = unit.AgentAlive()
+	c.Assert(err, IsNil)
+	c.Assert(alive, Equals, false)
+}
@@ -3,6 +3,10 @@
 
 package testing
 
+import (
+	"launchpad.net/juju-core/state"
+)
+
 // FakeAuthorizer implements the common.Authorizer interface.
 type FakeAuthorizer struct {
 	Tag          string
@@ -11,6 +15,7 @@ type FakeAuthorizer struct {
 	MachineAgent bool
 	UnitAgent    bool
 	Client       bool
+	Access       state.UserAccess
 }
 
 func (fa FakeAuthorizer) AuthOwner(tag string) bool {
@@ -33,6 +38,10 @@ func (fa FakeAuthorizer) AuthClient() bool {
 	return fa.Client
 }
 
+func (fa FakeAuthorizer) AuthUserAccess(access state.UserAccess) bool {
+	return fa.Client && fa.Access.Includes(access)
+}
+
 func (fa FakeAuthorizer) GetAuthTag() string {
 	return fa.Tag
 }
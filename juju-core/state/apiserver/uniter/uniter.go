@@ -1,13 +1,21 @@
 // Copyright 2013 Canonical Ltd.
 // Licensed under the AGPLv3, see LICENCE file for details.
 
-// The machiner package implements the API interface
+// The uniter package implements the API interface
 // used by the uniter worker.
 package uniter
 
 import (
+	"fmt"
+	"strings"
+
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/names"
 	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/apiserver/common"
+	"launchpad.net/juju-core/state/watcher"
 )
 
 // UniterAPI implements the API used by the uniter worker.
@@ -17,8 +25,12 @@ type UniterAPI struct {
 	*common.DeadEnsurer
 	*common.AgentEntityWatcher
 
-	st   *state.State
-	auth common.Authorizer
+	st                  *state.State
+	auth                common.Authorizer
+	resources           *common.Resources
+	accessUnit          common.GetAuthFunc
+	accessService       common.GetAuthFunc
+	accessUnitOrService common.GetAuthFunc
 }
 
 // NewUniterAPI creates a new instance of the Uniter API.
@@ -26,17 +38,1214 @@ func NewUniterAPI(st *state.State, resources *common.Resources, authorizer commo
 	if !authorizer.AuthUnitAgent() {
 		return nil, common.ErrPerm
 	}
-	getCanRead := func() (common.AuthFunc, error) {
+	accessUnit := func() (common.AuthFunc, error) {
 		return authorizer.AuthOwner, nil
 	}
+	accessService := func() (common.AuthFunc, error) {
+		serviceTag, err := serviceTagOfUnit(authorizer.GetAuthTag())
+		if err != nil {
+			return nil, err
+		}
+		return func(tag string) bool {
+			return tag == serviceTag
+		}, nil
+	}
+	accessUnitOrService := func() (common.AuthFunc, error) {
+		serviceTag, err := serviceTagOfUnit(authorizer.GetAuthTag())
+		if err != nil {
+			return nil, err
+		}
+		return func(tag string) bool {
+			return authorizer.AuthOwner(tag) || tag == serviceTag
+		}, nil
+	}
 	return &UniterAPI{
-		LifeGetter:         common.NewLifeGetter(st, getCanRead),
-		StatusSetter:       common.NewStatusSetter(st, getCanRead),
-		DeadEnsurer:        common.NewDeadEnsurer(st, getCanRead),
-		AgentEntityWatcher: common.NewAgentEntityWatcher(st, resources, getCanRead),
-		st:                 st,
-		auth:               authorizer,
+		LifeGetter:          common.NewLifeGetter(st, accessUnitOrService),
+		StatusSetter:        common.NewStatusSetter(st, accessUnit),
+		DeadEnsurer:         common.NewDeadEnsurer(st, accessUnit),
+		AgentEntityWatcher:  common.NewAgentEntityWatcher(st, resources, accessUnitOrService),
+		st:                  st,
+		auth:                authorizer,
+		resources:           resources,
+		accessUnit:          accessUnit,
+		accessService:       accessService,
+		accessUnitOrService: accessUnitOrService,
+	}, nil
+}
+
+// serviceTagOfUnit returns the tag of the service of the unit with the
+// given tag.
+func serviceTagOfUnit(unitTag string) (string, error) {
+	_, unitName, err := names.ParseTag(unitTag, names.UnitTagKind)
+	if err != nil {
+		return "", err
+	}
+	return names.ServiceTag(strings.Split(unitName, "/")[0]), nil
+}
+
+func (u *UniterAPI) getUnit(tag string) (*state.Unit, error) {
+	_, name, err := names.ParseTag(tag, names.UnitTagKind)
+	if err != nil {
+		return nil, err
+	}
+	return u.st.Unit(name)
+}
+
+func (u *UniterAPI) getService(tag string) (*state.Service, error) {
+	_, name, err := names.ParseTag(tag, names.ServiceTagKind)
+	if err != nil {
+		return nil, err
+	}
+	return u.st.Service(name)
+}
+
+// watchNotify registers w, once its initial event is consumed, and
+// returns its id.
+func (u *UniterAPI) watchNotify(w state.NotifyWatcher) (string, error) {
+	// Consume the initial event. Technically, API
+	// calls to Watch 'transmit' the initial event
+	// in the Watch response. But NotifyWatchers
+	// have no state to transmit.
+	if _, ok := <-w.Changes(); ok {
+		return u.resources.Register(w), nil
+	}
+	return "", watcher.MustErr(w)
+}
+
+// watchStrings registers w, once its initial event is consumed, and
+// returns its id and the initial event.
+func (u *UniterAPI) watchStrings(w state.StringsWatcher) (params.StringsWatchResult, error) {
+	// Consume the initial event and forward it to the result.
+	if changes, ok := <-w.Changes(); ok {
+		return params.StringsWatchResult{
+			StringsWatcherId: u.resources.Register(w),
+			Changes:          changes,
+		}, nil
+	}
+	return params.StringsWatchResult{}, watcher.MustErr(w)
+}
+
+// address returns the public or private address of each given unit,
+// if set.
+func (u *UniterAPI) address(args params.Entities, public bool) (params.StringBoolResults, error) {
+	result := params.StringBoolResults{
+		Results: make([]params.StringBoolResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.StringBoolResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				if public {
+					result.Results[i].Result, result.Results[i].Ok = unit.PublicAddress()
+				} else {
+					result.Results[i].Result, result.Results[i].Ok = unit.PrivateAddress()
+				}
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// PublicAddress returns the public address of each given unit, and
+// whether it is set.
+func (u *UniterAPI) PublicAddress(args params.Entities) (params.StringBoolResults, error) {
+	return u.address(args, true)
+}
+
+// PrivateAddress returns the private address of each given unit, and
+// whether it is set.
+func (u *UniterAPI) PrivateAddress(args params.Entities) (params.StringBoolResults, error) {
+	return u.address(args, false)
+}
+
+// NetworkAddresses returns the addresses of the machine each given unit
+// is assigned to.
+func (u *UniterAPI) NetworkAddresses(args params.Entities) (params.AddressesResults, error) {
+	result := params.AddressesResults{
+		Results: make([]params.AddressesResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.AddressesResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				result.Results[i].Addresses, err = unit.NetworkAddresses()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// setAddress sets the public or private address of each given unit.
+func (u *UniterAPI) setAddress(args params.SetEntityAddresses, public bool) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				if public {
+					err = unit.SetPublicAddress(entity.Address)
+				} else {
+					err = unit.SetPrivateAddress(entity.Address)
+				}
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetPublicAddress sets the public address of each given unit.
+func (u *UniterAPI) SetPublicAddress(args params.SetEntityAddresses) (params.ErrorResults, error) {
+	return u.setAddress(args, true)
+}
+
+// SetPrivateAddress sets the private address of each given unit.
+func (u *UniterAPI) SetPrivateAddress(args params.SetEntityAddresses) (params.ErrorResults, error) {
+	return u.setAddress(args, false)
+}
+
+// Resolved returns the current resolved setting for each given unit.
+func (u *UniterAPI) Resolved(args params.Entities) (params.ResolvedModeResults, error) {
+	result := params.ResolvedModeResults{
+		Results: make([]params.ResolvedModeResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ResolvedModeResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				result.Results[i].Mode = params.ResolvedMode(unit.Resolved())
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// ClearResolved removes any resolved setting from each given unit.
+func (u *UniterAPI) ClearResolved(args params.Entities) (params.ErrorResults, error) {
+	return u.unitOps(args, (*state.Unit).ClearResolved)
+}
+
+// Destroy advances all given Alive units' lifecycles as far as
+// possible.
+func (u *UniterAPI) Destroy(args params.Entities) (params.ErrorResults, error) {
+	return u.unitOps(args, (*state.Unit).Destroy)
+}
+
+// DestroyAllSubordinates destroys all subordinates of each given unit.
+func (u *UniterAPI) DestroyAllSubordinates(args params.Entities) (params.ErrorResults, error) {
+	return u.unitOps(args, func(unit *state.Unit) error {
+		for _, name := range unit.SubordinateNames() {
+			sub, err := u.st.Unit(name)
+			if errors.IsNotFoundError(err) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			if err := sub.Destroy(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// unitOps runs op on each given unit, and reports its errors.
+func (u *UniterAPI) unitOps(args params.Entities, op func(*state.Unit) error) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				err = op(unit)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// GetPrincipal returns the name of the principal unit of each given
+// unit, and whether it is a subordinate.
+func (u *UniterAPI) GetPrincipal(args params.Entities) (params.StringBoolResults, error) {
+	result := params.StringBoolResults{
+		Results: make([]params.StringBoolResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.StringBoolResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				result.Results[i].Result, result.Results[i].Ok = unit.PrincipalName()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// HasSubordinates returns whether each given unit has any
+// subordinates.
+func (u *UniterAPI) HasSubordinates(args params.Entities) (params.BoolResults, error) {
+	result := params.BoolResults{
+		Results: make([]params.BoolResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.BoolResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				result.Results[i].Result = len(unit.SubordinateNames()) > 0
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// CharmURL returns the charm URL of each given unit or service, and
+// whether the unit should upgrade to the charm with that URL even if
+// it is in an error state. For units, the boolean reports whether the
+// charm URL is set.
+func (u *UniterAPI) CharmURL(args params.Entities) (params.StringBoolResults, error) {
+	result := params.StringBoolResults{
+		Results: make([]params.StringBoolResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnitOrService()
+	if err != nil {
+		return params.StringBoolResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var curl *charm.URL
+			var ok bool
+			curl, ok, err = u.charmURL(entity.Tag)
+			if curl != nil {
+				result.Results[i].Result = curl.String()
+			}
+			result.Results[i].Ok = ok
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (u *UniterAPI) charmURL(tag string) (*charm.URL, bool, error) {
+	entity, err := u.st.FindEntity(tag)
+	if err != nil {
+		return nil, false, err
+	}
+	switch entity := entity.(type) {
+	case *state.Unit:
+		curl, ok := entity.CharmURL()
+		return curl, ok, nil
+	case *state.Service:
+		curl, force := entity.CharmURL()
+		return curl, force, nil
+	}
+	return nil, false, common.NotSupportedError(tag, "charm URLs")
+}
+
+// SetCharmURL sets the charm URL of each given unit. An error is
+// returned for each unit whose service does not have the charm.
+func (u *UniterAPI) SetCharmURL(args params.EntitiesCharmURL) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				var curl *charm.URL
+				curl, err = charm.ParseURL(entity.CharmURL)
+				if err == nil {
+					err = unit.SetCharmURL(curl)
+				}
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// OpenPort sets the policy of the port with protocol and number to be
+// opened, for each given unit.
+func (u *UniterAPI) OpenPort(args params.EntitiesPorts) (params.ErrorResults, error) {
+	return u.portOps(args, (*state.Unit).OpenPort)
+}
+
+// ClosePort sets the policy of the port with protocol and number to
+// be closed, for each given unit.
+func (u *UniterAPI) ClosePort(args params.EntitiesPorts) (params.ErrorResults, error) {
+	return u.portOps(args, (*state.Unit).ClosePort)
+}
+
+func (u *UniterAPI) portOps(args params.EntitiesPorts, op func(*state.Unit, string, int) error) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				err = op(unit, entity.Protocol, entity.Port)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// ConfigSettings returns the complete set of service charm config
+// settings available to each given unit.
+func (u *UniterAPI) ConfigSettings(args params.Entities) (params.ConfigSettingsResults, error) {
+	result := params.ConfigSettingsResults{
+		Results: make([]params.ConfigSettingsResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ConfigSettingsResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				var settings charm.Settings
+				settings, err = unit.ConfigSettings()
+				if err == nil {
+					result.Results[i].Settings = settings
+				}
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// WatchConfigSettings returns a NotifyWatcher for observing changes to
+// the configuration settings of the service of each given unit.
+func (u *UniterAPI) WatchConfigSettings(args params.Entities) (params.NotifyWatchResults, error) {
+	result := params.NotifyWatchResults{
+		Results: make([]params.NotifyWatchResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.NotifyWatchResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				var w state.NotifyWatcher
+				w, err = unit.WatchConfigSettings()
+				if err == nil {
+					result.Results[i].NotifyWatcherId, err = u.watchNotify(w)
+				}
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// WatchActions returns a StringsWatcher for observing the ids of the
+// actions queued on each given unit.
+func (u *UniterAPI) WatchActions(args params.Entities) (params.StringsWatchResults, error) {
+	result := params.StringsWatchResults{
+		Results: make([]params.StringsWatchResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.StringsWatchResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				result.Results[i], err = u.watchStrings(unit.WatchActions())
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// StorageInstances returns the storage instances of each given unit.
+func (u *UniterAPI) StorageInstances(args params.Entities) (params.StorageInstancesResults, error) {
+	result := params.StorageInstancesResults{
+		Results: make([]params.StorageInstancesResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.StorageInstancesResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				var stores []*state.StorageInstance
+				stores, err = unit.StorageInstances()
+				for _, store := range stores {
+					result.Results[i].Storage = append(result.Results[i].Storage, params.StorageInstance{
+						Id:          store.Id(),
+						Name:        store.Name(),
+						Kind:        string(store.Kind()),
+						Location:    store.Location(),
+						Size:        store.Size(),
+						Provisioned: store.Provisioned(),
+						Device:      store.Device(),
+						Attached:    store.Attached(),
+					})
+				}
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// WatchStorageInstances returns a NotifyWatcher for observing changes
+// to the storage instances of each given unit.
+func (u *UniterAPI) WatchStorageInstances(args params.Entities) (params.NotifyWatchResults, error) {
+	result := params.NotifyWatchResults{
+		Results: make([]params.NotifyWatchResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.NotifyWatchResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				result.Results[i].NotifyWatcherId, err = u.watchNotify(unit.WatchStorageInstances())
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetStorageAttached records whether the charm of the unit of each
+// given storage instance has been told that the store is available.
+func (u *UniterAPI) SetStorageAttached(args params.SetStorageAttached) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Storage)),
+	}
+	for i, arg := range args.Storage {
+		store, err := u.st.StorageInstance(arg.Id)
+		if errors.IsNotFoundError(err) {
+			err = common.ErrPerm
+		} else if err == nil {
+			if !u.auth.AuthOwner(names.UnitTag(store.UnitName())) {
+				err = common.ErrPerm
+			} else {
+				err = store.SetAttached(arg.Attached)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// WorkloadStatus returns the status of the workload of each given
+// unit, as last reported by its charm, and the accompanying message.
+func (u *UniterAPI) WorkloadStatus(args params.Entities) (params.WorkloadStatusResults, error) {
+	result := params.WorkloadStatusResults{
+		Results: make([]params.WorkloadStatusResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.WorkloadStatusResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				result.Results[i].Status, result.Results[i].Info, err = unit.WorkloadStatus()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetWorkloadStatus records the status of the workload of each given
+// unit, and a message explaining it.
+func (u *UniterAPI) SetWorkloadStatus(args params.SetWorkloadStatus) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				err = unit.SetWorkloadStatus(entity.Status, entity.Info)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// IsLeader returns whether each given unit leads its service.
+func (u *UniterAPI) IsLeader(args params.Entities) (params.BoolResults, error) {
+	return u.leadershipOps(args, (*state.Unit).IsLeader)
+}
+
+// ClaimLeadership makes each given unit the leader of its service if
+// the service has no live leader, and returns whether it leads the
+// service.
+func (u *UniterAPI) ClaimLeadership(args params.Entities) (params.BoolResults, error) {
+	return u.leadershipOps(args, (*state.Unit).ClaimLeadership)
+}
+
+func (u *UniterAPI) leadershipOps(args params.Entities, op func(*state.Unit) (bool, error)) (params.BoolResults, error) {
+	result := params.BoolResults{
+		Results: make([]params.BoolResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.BoolResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				result.Results[i].Result, err = op(unit)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetLeaderSettings updates the leader settings of the service of
+// each given unit, which must lead the service.
+func (u *UniterAPI) SetLeaderSettings(args params.SetLeaderSettings) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(entity.Tag)
+			if err == nil {
+				err = unit.SetLeaderSettings(entity.Settings)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// LeaderSettings returns the settings written by the leader of each
+// given service.
+func (u *UniterAPI) LeaderSettings(args params.Entities) (params.LeaderSettingsResults, error) {
+	result := params.LeaderSettingsResults{
+		Results: make([]params.LeaderSettingsResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessService()
+	if err != nil {
+		return params.LeaderSettingsResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var service *state.Service
+			service, err = u.getService(entity.Tag)
+			if err == nil {
+				result.Results[i].Settings, err = service.LeaderSettings()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// HookPolicy returns how the units of each given service run their
+// hooks.
+func (u *UniterAPI) HookPolicy(args params.Entities) (params.HookPolicyResults, error) {
+	result := params.HookPolicyResults{
+		Results: make([]params.HookPolicyResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessService()
+	if err != nil {
+		return params.HookPolicyResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var service *state.Service
+			service, err = u.getService(entity.Tag)
+			if err == nil {
+				result.Results[i].Policy = service.HookPolicy()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// WatchLeadership returns a NotifyWatcher for observing changes to the
+// leadership of each given service.
+func (u *UniterAPI) WatchLeadership(args params.Entities) (params.NotifyWatchResults, error) {
+	return u.watchServices(args, (*state.Service).WatchLeadership)
+}
+
+// WatchLeaderSettings returns a NotifyWatcher for observing changes to
+// the leader settings of each given service.
+func (u *UniterAPI) WatchLeaderSettings(args params.Entities) (params.NotifyWatchResults, error) {
+	return u.watchServices(args, (*state.Service).WatchLeaderSettings)
+}
+
+func (u *UniterAPI) watchServices(args params.Entities, watch func(*state.Service) state.NotifyWatcher) (params.NotifyWatchResults, error) {
+	result := params.NotifyWatchResults{
+		Results: make([]params.NotifyWatchResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessService()
+	if err != nil {
+		return params.NotifyWatchResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var service *state.Service
+			service, err = u.getService(entity.Tag)
+			if err == nil {
+				result.Results[i].NotifyWatcherId, err = u.watchNotify(watch(service))
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// Resource returns the location, hash and revision of the content last
+// uploaded for each given resource of a service.
+func (u *UniterAPI) Resource(args params.ServiceResources) (params.ResourceResults, error) {
+	result := params.ResourceResults{
+		Results: make([]params.ResourceResult, len(args.Resources)),
+	}
+	canAccess, err := u.accessService()
+	if err != nil {
+		return params.ResourceResults{}, err
+	}
+	for i, arg := range args.Resources {
+		err := common.ErrPerm
+		if canAccess(arg.Tag) {
+			var service *state.Service
+			service, err = u.getService(arg.Tag)
+			if err == nil {
+				var res *state.Resource
+				res, err = service.Resource(arg.Name)
+				if err == nil {
+					result.Results[i] = params.ResourceResult{
+						Revision: res.Revision(),
+						URL:      res.URL().String(),
+						Sha256:   res.Sha256(),
+						Size:     res.Size(),
+					}
+				}
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// WatchServiceRelations returns a StringsWatcher for observing the
+// keys of the relations of each given service.
+func (u *UniterAPI) WatchServiceRelations(args params.Entities) (params.StringsWatchResults, error) {
+	result := params.StringsWatchResults{
+		Results: make([]params.StringsWatchResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessService()
+	if err != nil {
+		return params.StringsWatchResults{}, err
+	}
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if canAccess(entity.Tag) {
+			var service *state.Service
+			service, err = u.getService(entity.Tag)
+			if err == nil {
+				result.Results[i], err = u.watchStrings(service.WatchRelations())
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// CharmBundles returns the URL and the SHA256 hash of the bundle of
+// each given charm.
+func (u *UniterAPI) CharmBundles(args params.CharmURLs) (params.CharmBundleResults, error) {
+	result := params.CharmBundleResults{
+		Results: make([]params.CharmBundleResult, len(args.URLs)),
+	}
+	for i, arg := range args.URLs {
+		curl, err := charm.ParseURL(arg.URL)
+		if err == nil {
+			var sch *state.Charm
+			sch, err = u.st.Charm(curl)
+			if err == nil {
+				result.Results[i].BundleURL = sch.BundleURL().String()
+				result.Results[i].BundleSha256 = sch.BundleSha256()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// EnvironUUID returns the universally unique identifier of the
+// environment.
+func (u *UniterAPI) EnvironUUID() (params.StringResult, error) {
+	env, err := u.st.Environment()
+	if err != nil {
+		return params.StringResult{}, err
+	}
+	return params.StringResult{Result: env.UUID()}, nil
+}
+
+// ProviderType returns the type of the provider of the environment,
+// which the uniter uses to find the addresses of its unit.
+func (u *UniterAPI) ProviderType() (params.StringResult, error) {
+	cfg, err := u.st.EnvironConfig()
+	if err != nil {
+		return params.StringResult{}, err
+	}
+	return params.StringResult{Result: cfg.Type()}, nil
+}
+
+// APIAddresses returns the addresses of the API servers, which hook
+// tools are told about.
+func (u *UniterAPI) APIAddresses() (params.StringsResult, error) {
+	addrs, err := u.st.APIAddresses()
+	if err != nil {
+		return params.StringsResult{}, err
+	}
+	return params.StringsResult{Result: addrs}, nil
+}
+
+// getRelation returns the endpoint of the service of the authenticated
+// unit in rel, or ErrPerm if the service does not take part in it.
+func (u *UniterAPI) getRelation(rel *state.Relation) (state.Endpoint, error) {
+	serviceTag, err := serviceTagOfUnit(u.auth.GetAuthTag())
+	if err != nil {
+		return state.Endpoint{}, err
+	}
+	_, serviceName, err := names.ParseTag(serviceTag, names.ServiceTagKind)
+	if err != nil {
+		return state.Endpoint{}, err
+	}
+	ep, err := rel.Endpoint(serviceName)
+	if err != nil {
+		// The relation does not involve the service of the unit.
+		return state.Endpoint{}, common.ErrPerm
+	}
+	return ep, nil
+}
+
+// prepareRelationResult returns the details of rel, if the
+// authenticated unit may see it.
+func (u *UniterAPI) prepareRelationResult(rel *state.Relation) (params.RelationResult, error) {
+	ep, err := u.getRelation(rel)
+	if err != nil {
+		return params.RelationResult{}, err
+	}
+	return params.RelationResult{
+		Id:   rel.Id(),
+		Key:  rel.String(),
+		Life: params.Life(rel.Life().String()),
+		Endpoint: params.Endpoint{
+			ServiceName: ep.ServiceName,
+			Relation:    ep.Relation,
+		},
 	}, nil
 }
 
-// TODO(dimitern): Add the other needed API calls.
+// Relation returns information about all given relation/unit pairs,
+// including their id, key and the local endpoint.
+func (u *UniterAPI) Relation(args params.RelationUnits) (params.RelationResults, error) {
+	result := params.RelationResults{
+		Results: make([]params.RelationResult, len(args.RelationUnits)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.RelationResults{}, err
+	}
+	for i, rel := range args.RelationUnits {
+		err := common.ErrPerm
+		if canAccess(rel.Unit) {
+			var relation *state.Relation
+			relation, err = u.st.KeyRelation(rel.Relation)
+			if err == nil {
+				result.Results[i], err = u.prepareRelationResult(relation)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// RelationById returns information about all given relations,
+// specified by their ids, including their key and the local endpoint.
+func (u *UniterAPI) RelationById(args params.RelationIds) (params.RelationResults, error) {
+	result := params.RelationResults{
+		Results: make([]params.RelationResult, len(args.RelationIds)),
+	}
+	for i, id := range args.RelationIds {
+		relation, err := u.st.Relation(id)
+		if err == nil {
+			result.Results[i], err = u.prepareRelationResult(relation)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// getRelationUnit returns the RelationUnit of the given unit in the
+// relation with the given key, if the authenticated unit may use it.
+func (u *UniterAPI) getRelationUnit(canAccess common.AuthFunc, relKey, unitTag string) (*state.RelationUnit, error) {
+	if !canAccess(unitTag) {
+		return nil, common.ErrPerm
+	}
+	rel, err := u.st.KeyRelation(relKey)
+	if errors.IsNotFoundError(err) {
+		return nil, common.ErrPerm
+	} else if err != nil {
+		return nil, err
+	}
+	if _, err := u.getRelation(rel); err != nil {
+		return nil, err
+	}
+	unit, err := u.getUnit(unitTag)
+	if err != nil {
+		return nil, err
+	}
+	return rel.Unit(unit)
+}
+
+// EnterScope ensures each unit has entered its scope in the relation,
+// for all of the given relation/unit pairs, with its private address,
+// and its ingress address if known, as the initial relation settings.
+func (u *UniterAPI) EnterScope(args params.RelationUnits) (params.ErrorResults, error) {
+	return u.relationUnitOps(args, func(ru *state.RelationUnit) error {
+		address, ok := ru.PrivateAddress()
+		if !ok {
+			return fmt.Errorf("cannot enter scope: private-address not set")
+		}
+		settings := map[string]interface{}{"private-address": address}
+		ingress, err := ru.IngressAddress()
+		if err != nil && !state.IsNotAssigned(err) {
+			return err
+		}
+		if ingress != "" {
+			settings["ingress-address"] = ingress
+		}
+		return ru.EnterScope(settings)
+	})
+}
+
+// IngressAddress returns the address the other units of the relation
+// should use to reach the unit, for each given relation/unit pair.
+// The result is empty if no suitable address is known.
+func (u *UniterAPI) IngressAddress(args params.RelationUnits) (params.StringResults, error) {
+	result := params.StringResults{
+		Results: make([]params.StringResult, len(args.RelationUnits)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.StringResults{}, err
+	}
+	for i, arg := range args.RelationUnits {
+		relUnit, err := u.getRelationUnit(canAccess, arg.Relation, arg.Unit)
+		if err == nil {
+			result.Results[i].Result, err = relUnit.IngressAddress()
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// LeaveScope signals each unit has left its scope in the relation,
+// for all of the given relation/unit pairs.
+func (u *UniterAPI) LeaveScope(args params.RelationUnits) (params.ErrorResults, error) {
+	return u.relationUnitOps(args, (*state.RelationUnit).LeaveScope)
+}
+
+func (u *UniterAPI) relationUnitOps(args params.RelationUnits, op func(*state.RelationUnit) error) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.RelationUnits)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.RelationUnits {
+		relUnit, err := u.getRelationUnit(canAccess, arg.Relation, arg.Unit)
+		if err == nil {
+			err = op(relUnit)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// ReadSettings returns the local settings of each given set of
+// relation/unit.
+func (u *UniterAPI) ReadSettings(args params.RelationUnits) (params.RelationSettingsResults, error) {
+	result := params.RelationSettingsResults{
+		Results: make([]params.RelationSettingsResult, len(args.RelationUnits)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.RelationSettingsResults{}, err
+	}
+	for i, arg := range args.RelationUnits {
+		relUnit, err := u.getRelationUnit(canAccess, arg.Relation, arg.Unit)
+		if err == nil {
+			var settings *state.Settings
+			settings, err = relUnit.Settings()
+			if err == nil {
+				result.Results[i].Settings = settings.Map()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// ReadRemoteSettings returns the remote settings of each given set of
+// relation/local unit/remote unit.
+func (u *UniterAPI) ReadRemoteSettings(args params.RelationUnitPairs) (params.RelationSettingsResults, error) {
+	result := params.RelationSettingsResults{
+		Results: make([]params.RelationSettingsResult, len(args.RelationUnitPairs)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.RelationSettingsResults{}, err
+	}
+	for i, arg := range args.RelationUnitPairs {
+		relUnit, err := u.getRelationUnit(canAccess, arg.Relation, arg.LocalUnit)
+		if err == nil {
+			var remoteUnit string
+			_, remoteUnit, err = names.ParseTag(arg.RemoteUnit, names.UnitTagKind)
+			if err == nil {
+				result.Results[i].Settings, err = relUnit.ReadSettings(remoteUnit)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// UpdateSettings persists the changes to the local settings of each
+// given pair of relation and unit; settings with nil values are
+// deleted.
+func (u *UniterAPI) UpdateSettings(args params.RelationUnitsSettings) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.RelationUnits)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.RelationUnits {
+		relUnit, err := u.getRelationUnit(canAccess, arg.Relation, arg.Unit)
+		if err == nil {
+			var settings *state.Settings
+			settings, err = relUnit.Settings()
+			if err == nil {
+				for key, value := range arg.Settings {
+					if value == nil {
+						settings.Delete(key)
+					} else {
+						settings.Set(key, value)
+					}
+				}
+				_, err = settings.Write()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// WatchRelationUnits returns a RelationUnitsWatcher for observing
+// changes to every unit in the supplied relation that is visible to
+// the supplied unit.
+func (u *UniterAPI) WatchRelationUnits(args params.RelationUnits) (params.RelationUnitsWatchResults, error) {
+	result := params.RelationUnitsWatchResults{
+		Results: make([]params.RelationUnitsWatchResult, len(args.RelationUnits)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.RelationUnitsWatchResults{}, err
+	}
+	for i, arg := range args.RelationUnits {
+		relUnit, err := u.getRelationUnit(canAccess, arg.Relation, arg.Unit)
+		if err == nil {
+			w := relUnit.Watch()
+			// Consume the initial event and forward it to the result.
+			if changes, ok := <-w.Changes(); ok {
+				result.Results[i].RelationUnitsWatcherId = u.resources.Register(w)
+				result.Results[i].Changes = common.RelationUnitsChange(changes)
+			} else {
+				err = watcher.MustErr(w)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// getAction returns the action with the given id, if it is queued on
+// the authenticated unit.
+func (u *UniterAPI) getAction(id string) (*state.Action, error) {
+	action, err := u.st.Action(id)
+	if errors.IsNotFoundError(err) {
+		return nil, common.ErrPerm
+	} else if err != nil {
+		return nil, err
+	}
+	if !u.auth.AuthOwner(names.UnitTag(action.UnitName())) {
+		return nil, common.ErrPerm
+	}
+	return action, nil
+}
+
+// Actions returns the name, the parameters and the status of each
+// given action.
+func (u *UniterAPI) Actions(args params.ActionIds) (params.ActionResults, error) {
+	result := params.ActionResults{
+		Results: make([]params.ActionResult, len(args.Ids)),
+	}
+	for i, id := range args.Ids {
+		action, err := u.getAction(id)
+		if err == nil {
+			result.Results[i].Name = action.Name()
+			result.Results[i].Params = action.Params()
+			result.Results[i].Status = action.Status()
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// CompleteActions records the results of each given action, which
+// completed successfully.
+func (u *UniterAPI) CompleteActions(args params.ActionsComplete) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Actions)),
+	}
+	for i, arg := range args.Actions {
+		action, err := u.getAction(arg.Id)
+		if err == nil {
+			err = action.Complete(arg.Results)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// FailActions records the failure of each given action.
+func (u *UniterAPI) FailActions(args params.ActionsFail) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Actions)),
+	}
+	for i, arg := range args.Actions {
+		action, err := u.getAction(arg.Id)
+		if err == nil {
+			err = action.Fail(arg.Message)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
@@ -4,10 +4,14 @@
 package uniter_test
 
 import (
+	"net/url"
 	stdtesting "testing"
+	"time"
 
 	gc "launchpad.net/gocheck"
 
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/juju/testing"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api/params"
@@ -222,3 +226,337 @@ func (s *uniterSuite) TestWatch(c *gc.C) {
 	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
 	wc.AssertNoChange()
 }
+
+func (s *uniterSuite) TestPublicAddress(c *gc.C) {
+	err := s.wordpressUnit.SetPublicAddress("1.2.3.4")
+	c.Assert(err, gc.IsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-foo-42"},
+	}}
+	result, err := s.uniter.PublicAddress(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.StringBoolResults{
+		Results: []params.StringBoolResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Result: "1.2.3.4", Ok: true},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	result, err = s.uniter.PrivateAddress(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.StringBoolResults{
+		Results: []params.StringBoolResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Result: "", Ok: false},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterSuite) TestCharmURL(c *gc.C) {
+	// The unit has no charm URL set yet; the service always has one.
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "service-wordpress"},
+		{Tag: "service-mysql"},
+	}}
+	result, err := s.uniter.CharmURL(args)
+	c.Assert(err, gc.IsNil)
+	curl, force := s.wordpress.CharmURL()
+	c.Assert(result, gc.DeepEquals, params.StringBoolResults{
+		Results: []params.StringBoolResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Result: "", Ok: false},
+			{Result: curl.String(), Ok: force},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterSuite) TestHookPolicy(c *gc.C) {
+	policy := params.HookPolicy{Timeout: time.Minute, Retry: true}
+	err := s.wordpress.SetHookPolicy(policy)
+	c.Assert(err, gc.IsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "service-mysql"},
+		{Tag: "service-wordpress"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "service-foo"},
+	}}
+	result, err := s.uniter.HookPolicy(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.HookPolicyResults{
+		Results: []params.HookPolicyResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Policy: policy},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterSuite) addRelation(c *gc.C) *state.Relation {
+	eps, err := s.State.InferEndpoints([]string{"wordpress", "mysql"})
+	c.Assert(err, gc.IsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, gc.IsNil)
+	return rel
+}
+
+func (s *uniterSuite) TestRelation(c *gc.C) {
+	rel := s.addRelation(c)
+
+	args := params.RelationUnits{RelationUnits: []params.RelationUnit{
+		{Relation: "42", Unit: "unit-wordpress-0"},
+		{Relation: rel.String(), Unit: "unit-mysql-0"},
+		{Relation: rel.String(), Unit: "unit-wordpress-0"},
+	}}
+	result, err := s.uniter.Relation(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results, gc.HasLen, 3)
+	c.Assert(result.Results[0].Error, gc.DeepEquals, &params.Error{
+		Message: `relation "42" not found`,
+		Code:    params.CodeNotFound,
+	})
+	c.Assert(result.Results[1].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+	c.Assert(result.Results[2], gc.DeepEquals, params.RelationResult{
+		Id:   rel.Id(),
+		Key:  rel.String(),
+		Life: params.Alive,
+		Endpoint: params.Endpoint{
+			ServiceName: "wordpress",
+			Relation: charm.Relation{
+				Name:      "db",
+				Role:      charm.RoleRequirer,
+				Interface: "mysql",
+				Optional:  false,
+				Limit:     1,
+				Scope:     charm.ScopeGlobal,
+			},
+		},
+	})
+
+	ids := params.RelationIds{RelationIds: []int{rel.Id(), 42}}
+	result, err = s.uniter.RelationById(ids)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results, gc.HasLen, 2)
+	c.Assert(result.Results[0].Key, gc.Equals, rel.String())
+	c.Assert(result.Results[1].Error, gc.DeepEquals, &params.Error{
+		Message: `relation 42 not found`,
+		Code:    params.CodeNotFound,
+	})
+}
+
+func (s *uniterSuite) TestEnterScopeAndSettings(c *gc.C) {
+	rel := s.addRelation(c)
+	args := params.RelationUnits{RelationUnits: []params.RelationUnit{
+		{Relation: rel.String(), Unit: "unit-wordpress-0"},
+		{Relation: rel.String(), Unit: "unit-mysql-0"},
+		{Relation: "42", Unit: "unit-wordpress-0"},
+	}}
+
+	// The unit cannot enter scope without a private address.
+	result, err := s.uniter.EnterScope(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results, gc.HasLen, 3)
+	c.Assert(result.Results[0].Error, gc.ErrorMatches, "cannot enter scope: private-address not set")
+	c.Assert(result.Results[1].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+	c.Assert(result.Results[2].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+
+	err = s.wordpressUnit.SetPrivateAddress("1.2.3.4")
+	c.Assert(err, gc.IsNil)
+	result, err = s.uniter.EnterScope(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	mysqlRelUnit, err := rel.Unit(s.mysqlUnit)
+	c.Assert(err, gc.IsNil)
+	remote, err := mysqlRelUnit.ReadSettings("wordpress/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(remote, gc.DeepEquals, map[string]interface{}{
+		"private-address": "1.2.3.4",
+	})
+
+	// Update the settings, deleting the private address.
+	update := params.RelationUnitsSettings{RelationUnits: []params.RelationUnitSettings{{
+		Relation: rel.String(),
+		Unit:     "unit-wordpress-0",
+		Settings: params.RelationSettings{"some": "thing", "private-address": nil},
+	}}}
+	errResults, err := s.uniter.UpdateSettings(update)
+	c.Assert(err, gc.IsNil)
+	c.Assert(errResults, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{nil}},
+	})
+
+	settings, err := s.uniter.ReadSettings(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(settings.Results, gc.HasLen, 3)
+	c.Assert(settings.Results[0], gc.DeepEquals, params.RelationSettingsResult{
+		Settings: params.RelationSettings{"some": "thing"},
+	})
+	c.Assert(settings.Results[1].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+	c.Assert(settings.Results[2].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+
+	result, err = s.uniter.LeaveScope(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+}
+
+func (s *uniterSuite) TestEnterScopeSetsIngressAddress(c *gc.C) {
+	rel := s.addRelation(c)
+	err := s.wordpressUnit.SetPrivateAddress("1.2.3.4")
+	c.Assert(err, gc.IsNil)
+	err = s.machine0.SetMachineAddresses([]instance.Address{
+		instance.NewScopedAddress("127.0.0.1"),
+		instance.NewScopedAddress("10.0.0.2"),
+	})
+	c.Assert(err, gc.IsNil)
+
+	args := params.RelationUnits{RelationUnits: []params.RelationUnit{
+		{Relation: rel.String(), Unit: "unit-wordpress-0"},
+		{Relation: rel.String(), Unit: "unit-mysql-0"},
+	}}
+	result, err := s.uniter.IngressAddress(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.StringResults{
+		Results: []params.StringResult{
+			{Result: "10.0.0.2"},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	errResults, err := s.uniter.EnterScope(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(errResults.Results[0].Error, gc.IsNil)
+	mysqlRelUnit, err := rel.Unit(s.mysqlUnit)
+	c.Assert(err, gc.IsNil)
+	remote, err := mysqlRelUnit.ReadSettings("wordpress/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(remote, gc.DeepEquals, map[string]interface{}{
+		"private-address": "1.2.3.4",
+		"ingress-address": "10.0.0.2",
+	})
+}
+
+func (s *uniterSuite) TestNetworkAddresses(c *gc.C) {
+	addresses := []instance.Address{instance.NewScopedAddress("10.0.0.2")}
+	err := s.machine0.SetMachineAddresses(addresses)
+	c.Assert(err, gc.IsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-foo-42"},
+	}}
+	result, err := s.uniter.NetworkAddresses(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.AddressesResults{
+		Results: []params.AddressesResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Addresses: addresses},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterSuite) TestStorageInstances(c *gc.C) {
+	svc, err := s.State.AddService("storage", s.AddTestingCharm(c, "storage"))
+	c.Assert(err, gc.IsNil)
+	unit, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	store, err := s.State.StorageInstance("data/0")
+	c.Assert(err, gc.IsNil)
+	err = store.SetProvisioned("vol-0", "/dev/sdb")
+	c.Assert(err, gc.IsNil)
+
+	auth := s.authorizer
+	auth.Tag = unit.Tag()
+	storageUniter, err := uniter.NewUniterAPI(s.State, s.resources, auth)
+	c.Assert(err, gc.IsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-storage-0"},
+	}}
+	result, err := storageUniter.StorageInstances(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.StorageInstancesResults{
+		Results: []params.StorageInstancesResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Storage: []params.StorageInstance{{
+				Id:          "data/0",
+				Name:        "data",
+				Kind:        "filesystem",
+				Location:    "/srv/data",
+				Size:        1024,
+				Provisioned: true,
+				Device:      "/dev/sdb",
+			}, {
+				Id:   "disks/0",
+				Name: "disks",
+				Kind: "block",
+				Size: 512,
+			}}},
+		},
+	})
+
+	attachArgs := params.SetStorageAttached{Storage: []params.StorageAttachment{
+		{Id: "data/0", Attached: true},
+		{Id: "disks/0", Attached: true},
+		{Id: "data/42", Attached: true},
+	}}
+	errResult, err := storageUniter.SetStorageAttached(attachArgs)
+	c.Assert(err, gc.IsNil)
+	c.Assert(errResult.Results, gc.HasLen, 3)
+	c.Assert(errResult.Results[0].Error, gc.IsNil)
+	c.Assert(errResult.Results[1].Error, gc.ErrorMatches, `cannot set storage instance "disks/0" attachment: not provisioned`)
+	c.Assert(errResult.Results[2].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+	err = store.Refresh()
+	c.Assert(err, gc.IsNil)
+	c.Assert(store.Attached(), gc.Equals, true)
+
+	// The unit of the authorizer cannot change the stores of others.
+	errResult, err = s.uniter.SetStorageAttached(attachArgs)
+	c.Assert(err, gc.IsNil)
+	c.Assert(errResult.Results[0].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+}
+
+func (s *uniterSuite) TestResource(c *gc.C) {
+	svc, err := s.State.AddService("resources", s.AddTestingCharm(c, "resources"))
+	c.Assert(err, gc.IsNil)
+	unit, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	jdkURL, err := url.Parse("http://example.com/jdk")
+	c.Assert(err, gc.IsNil)
+	_, err = svc.SetResource("jdk", jdkURL, "jdk-sha256", 42)
+	c.Assert(err, gc.IsNil)
+
+	auth := s.authorizer
+	auth.Tag = unit.Tag()
+	resourcesUniter, err := uniter.NewUniterAPI(s.State, s.resources, auth)
+	c.Assert(err, gc.IsNil)
+
+	args := params.ServiceResources{Resources: []params.ServiceResource{
+		{Tag: "service-wordpress", Name: "jdk"},
+		{Tag: "service-resources", Name: "jdk"},
+		{Tag: "service-resources", Name: "vendor"},
+	}}
+	result, err := resourcesUniter.Resource(args)
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.DeepEquals, params.ResourceResults{
+		Results: []params.ResourceResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{URL: "http://example.com/jdk", Sha256: "jdk-sha256", Size: 42},
+			{Error: &params.Error{
+				Message: `resource "vendor" of service "resources" not found`,
+				Code:    params.CodeNotFound,
+			}},
+		},
+	})
+}
@@ -4,6 +4,7 @@
 package apiserver
 
 import (
+	"launchpad.net/juju-core/log/syslog"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/apiserver/common"
@@ -27,6 +28,46 @@ func (w *srvClientAllWatcher) Stop() error {
 	return w.resources.Stop(w.id)
 }
 
+// srvDebugLogWatcher sends the records of the accumulated log of the
+// environment selected by a client.
+type srvDebugLogWatcher struct {
+	tailer    *syslog.Tailer
+	id        string
+	resources *common.Resources
+}
+
+// Next returns the selected records written since the most recent call
+// to Next, or the backlog requested by the WatchDebugLog call that
+// created the watcher, blocking until there is at least one.
+func (w *srvDebugLogWatcher) Next() (params.DebugLogNextResults, error) {
+	if records, ok := <-w.tailer.Changes(); ok {
+		result := params.DebugLogNextResults{
+			Records: make([]params.LogRecord, len(records)),
+		}
+		for i, r := range records {
+			result.Records[i] = params.LogRecord{
+				Entity:   r.Entity,
+				Time:     r.Time,
+				Level:    r.Level,
+				Module:   r.Module,
+				Location: r.Location,
+				Message:  r.Message,
+			}
+		}
+		return result, nil
+	}
+	err := w.tailer.Err()
+	if err == nil {
+		err = common.ErrStoppedWatcher
+	}
+	return params.DebugLogNextResults{}, err
+}
+
+// Stop stops the watcher.
+func (w *srvDebugLogWatcher) Stop() error {
+	return w.resources.Stop(w.id)
+}
+
 type srvNotifyWatcher struct {
 	watcher   state.NotifyWatcher
 	id        string
@@ -80,3 +121,33 @@ func (w *srvStringsWatcher) Next() (params.StringsWatchResult, error) {
 func (w *srvStringsWatcher) Stop() error {
 	return w.resources.Stop(w.id)
 }
+
+// srvRelationUnitsWatcher notifies about units entering and leaving
+// the scope of a RelationUnit, and changes to the settings of those
+// units known to have entered.
+type srvRelationUnitsWatcher struct {
+	watcher   *state.RelationUnitsWatcher
+	id        string
+	resources *common.Resources
+}
+
+// Next returns when a change has occured to the units in scope of the
+// relation since the most recent call to Next or the Watch call that
+// created the srvRelationUnitsWatcher.
+func (w *srvRelationUnitsWatcher) Next() (params.RelationUnitsWatchResult, error) {
+	if changes, ok := <-w.watcher.Changes(); ok {
+		return params.RelationUnitsWatchResult{
+			Changes: common.RelationUnitsChange(changes),
+		}, nil
+	}
+	err := w.watcher.Err()
+	if err == nil {
+		err = common.ErrStoppedWatcher
+	}
+	return params.RelationUnitsWatchResult{}, err
+}
+
+// Stop stops the watcher.
+func (w *srvRelationUnitsWatcher) Stop() error {
+	return w.resources.Stop(w.id)
+}
@@ -0,0 +1,94 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"labix.org/v2/mgo/bson"
+)
+
+// AuditEntry records a call made by a client to change the environment.
+type AuditEntry struct {
+	// Time holds when the call was made.
+	Time time.Time
+
+	// Tag holds the tag of the entity that made the call.
+	Tag string
+
+	// Method holds the name of the API method called.
+	Method string
+
+	// Entities holds the tags of the entities the call concerns, if
+	// any.
+	Entities []string `bson:",omitempty"`
+
+	// Args holds the arguments of the call, encoded as JSON, with
+	// secrets redacted.
+	Args string `bson:",omitempty"`
+
+	// Error holds the error returned by the call, if it failed.
+	Error string `bson:",omitempty"`
+}
+
+// AddAuditEntry appends the entry to the audit log of the environment.
+// The log is held in a capped collection, so the oldest entries are
+// discarded once it is full.
+func (st *State) AddAuditEntry(entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Time = entry.Time.UTC()
+	if err := st.auditLog.Insert(&entry); err != nil {
+		return fmt.Errorf("cannot add audit log entry: %v", err)
+	}
+	return nil
+}
+
+// AuditLogFilter selects entries of the audit log. Zero fields select
+// all entries.
+type AuditLogFilter struct {
+	// Since selects the entries recorded at or after the given time.
+	Since time.Time
+
+	// Tag selects the entries of calls made by the given entity.
+	Tag string
+
+	// Entity selects the entries of calls concerning the entity with
+	// the given tag.
+	Entity string
+
+	// Limit selects only the given number of most recent entries.
+	Limit int
+}
+
+// AuditLog returns the entries of the audit log selected by the
+// filter, oldest first.
+func (st *State) AuditLog(filter AuditLogFilter) ([]AuditEntry, error) {
+	sel := D{}
+	if !filter.Since.IsZero() {
+		sel = append(sel, bson.DocElem{"time", D{{"$gte", filter.Since.UTC()}}})
+	}
+	if filter.Tag != "" {
+		sel = append(sel, bson.DocElem{"tag", filter.Tag})
+	}
+	if filter.Entity != "" {
+		sel = append(sel, bson.DocElem{"entities", filter.Entity})
+	}
+	// Entries are held in insertion order, so the most recent ones are
+	// found by reading the collection backwards.
+	query := st.auditLog.Find(sel).Sort("-$natural")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	var entries []AuditEntry
+	if err := query.All(&entries); err != nil {
+		return nil, fmt.Errorf("cannot read audit log: %v", err)
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/state"
+)
+
+type AuditLogSuite struct {
+	ConnSuite
+}
+
+var _ = Suite(&AuditLogSuite{})
+
+func (s *AuditLogSuite) addEntries(c *C, entries ...state.AuditEntry) {
+	for _, entry := range entries {
+		err := s.State.AddAuditEntry(entry)
+		c.Assert(err, IsNil)
+	}
+}
+
+func auditMethods(entries []state.AuditEntry) []string {
+	var methods []string
+	for _, entry := range entries {
+		methods = append(methods, entry.Method)
+	}
+	return methods
+}
+
+func (s *AuditLogSuite) TestAddAuditEntry(c *C) {
+	now := time.Now()
+	s.addEntries(c, state.AuditEntry{
+		Tag:      "user-admin",
+		Method:   "ServiceDeploy",
+		Entities: []string{"service-wordpress"},
+		Args:     `{"CharmUrl":"cs:precise/wordpress-3"}`,
+	})
+	entries, err := s.State.AuditLog(state.AuditLogFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	entry := entries[0]
+	c.Assert(entry.Time.Sub(now) < time.Minute, Equals, true)
+	entry.Time = time.Time{}
+	c.Assert(entry, DeepEquals, state.AuditEntry{
+		Tag:      "user-admin",
+		Method:   "ServiceDeploy",
+		Entities: []string{"service-wordpress"},
+		Args:     `{"CharmUrl":"cs:precise/wordpress-3"}`,
+	})
+}
+
+func (s *AuditLogSuite) TestAuditLogFilter(c *C) {
+	t0 := time.Date(2013, 10, 16, 12, 0, 0, 0, time.UTC)
+	s.addEntries(c,
+		state.AuditEntry{Time: t0, Tag: "user-admin", Method: "AddUser", Entities: []string{"user-bob"}},
+		state.AuditEntry{Time: t0.Add(time.Minute), Tag: "user-bob", Method: "ServiceDeploy", Entities: []string{"service-mysql"}},
+		state.AuditEntry{Time: t0.Add(2 * time.Minute), Tag: "user-bob", Method: "AddRelation", Entities: []string{"service-mysql", "service-wordpress"}},
+		state.AuditEntry{Time: t0.Add(3 * time.Minute), Tag: "user-admin", Method: "UpgradeJuju", Error: "permission denied"},
+	)
+	for i, t := range []struct {
+		filter  state.AuditLogFilter
+		methods []string
+	}{{
+		filter:  state.AuditLogFilter{},
+		methods: []string{"AddUser", "ServiceDeploy", "AddRelation", "UpgradeJuju"},
+	}, {
+		filter:  state.AuditLogFilter{Since: t0.Add(90 * time.Second)},
+		methods: []string{"AddRelation", "UpgradeJuju"},
+	}, {
+		filter:  state.AuditLogFilter{Tag: "user-bob"},
+		methods: []string{"ServiceDeploy", "AddRelation"},
+	}, {
+		filter:  state.AuditLogFilter{Entity: "service-mysql"},
+		methods: []string{"ServiceDeploy", "AddRelation"},
+	}, {
+		filter:  state.AuditLogFilter{Entity: "service-wordpress", Tag: "user-admin"},
+		methods: nil,
+	}, {
+		filter:  state.AuditLogFilter{Limit: 2},
+		methods: []string{"AddRelation", "UpgradeJuju"},
+	}} {
+		c.Logf("test %d: %+v", i, t.filter)
+		entries, err := s.State.AuditLog(t.filter)
+		c.Assert(err, IsNil)
+		c.Check(auditMethods(entries), DeepEquals, t.methods)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"regexp"
+
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/txn"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/utils"
+)
+
+// ContainerOp names an operation on a container machine, carried out by
+// the agent of the machine hosting the container.
+type ContainerOp string
+
+const (
+	// ContainerFreeze freezes all the processes of the container.
+	ContainerFreeze ContainerOp = "freeze"
+	// ContainerThaw thaws the processes of a frozen container.
+	ContainerThaw ContainerOp = "thaw"
+	// ContainerSnapshot records the state of the container under a name.
+	ContainerSnapshot ContainerOp = "snapshot"
+	// ContainerRestore returns the container to a named snapshot.
+	ContainerRestore ContainerOp = "restore"
+)
+
+// ContainerOperation describes an operation requested on a container.
+type ContainerOperation struct {
+	Op ContainerOp
+	// Snapshot names the snapshot taken or restored.
+	Snapshot string
+	// Error holds why the operation failed, if it did.
+	Error string
+}
+
+// containerOpDoc records the operation requested on a container. There
+// is at most one operation per container: the document is removed once
+// the operation succeeds, and keeps the error when it fails, until a new
+// operation is requested.
+type containerOpDoc struct {
+	MachineId string `bson:"_id"`
+	HostId    string
+	Op        ContainerOp
+	Snapshot  string
+	Error     string
+}
+
+var validSnapshot = regexp.MustCompile("^[a-zA-Z0-9][a-zA-Z0-9_.-]*$")
+
+// RequestContainerOp asks the agent of the host machine to carry out the
+// operation on the container. The snapshot names the snapshot to take
+// or to restore, and must be empty for the other operations.
+func (m *Machine) RequestContainerOp(op ContainerOp, snapshot string) (err error) {
+	defer utils.ErrorContextf(&err, "cannot %s machine %s", op, m)
+	hostId, ok := m.ParentId()
+	if !ok {
+		return fmt.Errorf("machine is not a container")
+	}
+	switch op {
+	case ContainerFreeze, ContainerThaw:
+		if snapshot != "" {
+			return fmt.Errorf("unexpected snapshot name %q", snapshot)
+		}
+	case ContainerSnapshot, ContainerRestore:
+		if !validSnapshot.MatchString(snapshot) {
+			return fmt.Errorf("invalid snapshot name %q", snapshot)
+		}
+	default:
+		return fmt.Errorf("unknown container operation %q", op)
+	}
+	machineOp := txn.Op{
+		C:      m.st.machines.Name,
+		Id:     m.doc.Id,
+		Assert: isAliveDoc,
+	}
+	for i := 0; i < 2; i++ {
+		if m.doc.Life != Alive {
+			return fmt.Errorf("machine is not alive")
+		}
+		current, err := m.ContainerOp()
+		var opOp txn.Op
+		if errors.IsNotFoundError(err) {
+			opOp = txn.Op{
+				C:      m.st.containerOps.Name,
+				Id:     m.doc.Id,
+				Assert: txn.DocMissing,
+				Insert: &containerOpDoc{
+					MachineId: m.doc.Id,
+					HostId:    hostId,
+					Op:        op,
+					Snapshot:  snapshot,
+				},
+			}
+		} else if err != nil {
+			return err
+		} else if current.Error == "" {
+			return fmt.Errorf("%s operation still pending", current.Op)
+		} else {
+			// Replace the operation that failed.
+			opOp = txn.Op{
+				C:      m.st.containerOps.Name,
+				Id:     m.doc.Id,
+				Assert: D{{"error", D{{"$ne", ""}}}},
+				Update: D{{"$set", D{
+					{"op", op},
+					{"snapshot", snapshot},
+					{"error", ""},
+				}}},
+			}
+		}
+		if err := m.st.runTransaction([]txn.Op{machineOp, opOp}); err != txn.ErrAborted {
+			return err
+		}
+		if err := m.Refresh(); err != nil {
+			return err
+		}
+	}
+	return ErrExcessiveContention
+}
+
+// ContainerOp returns the operation last requested on the container. It
+// returns an error that satisfies IsNotFound if there is none, or it
+// succeeded.
+func (m *Machine) ContainerOp() (*ContainerOperation, error) {
+	doc := containerOpDoc{}
+	err := m.st.containerOps.FindId(m.doc.Id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("container operation for machine %v", m)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get container operation for machine %v: %v", m, err)
+	}
+	return &ContainerOperation{
+		Op:       doc.Op,
+		Snapshot: doc.Snapshot,
+		Error:    doc.Error,
+	}, nil
+}
+
+// CompleteContainerOp records the outcome of the pending operation on
+// the container: the operation is forgotten if opErr is nil, and the
+// error kept otherwise.
+func (m *Machine) CompleteContainerOp(opErr error) (err error) {
+	defer utils.ErrorContextf(&err, "cannot complete container operation for machine %v", m)
+	op := txn.Op{
+		C:      m.st.containerOps.Name,
+		Id:     m.doc.Id,
+		Assert: D{{"error", ""}},
+	}
+	if opErr == nil {
+		op.Remove = true
+	} else {
+		op.Update = D{{"$set", D{{"error", opErr.Error()}}}}
+	}
+	return onAbort(m.st.runTransaction([]txn.Op{op}), fmt.Errorf("no operation pending"))
+}
+
+// removeContainerOpOp returns the operation removing the container
+// operation of the machine, if there is one.
+func removeContainerOpOp(st *State, machineId string) txn.Op {
+	return txn.Op{
+		C:      st.containerOps.Name,
+		Id:     machineId,
+		Remove: true,
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"fmt"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/state"
+	statetesting "launchpad.net/juju-core/state/testing"
+	"launchpad.net/juju-core/testing/checkers"
+)
+
+type ContainerOpsSuite struct {
+	ConnSuite
+	host *state.Machine
+}
+
+var _ = Suite(&ContainerOpsSuite{})
+
+func (s *ContainerOpsSuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.host, err = s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+}
+
+func (s *ContainerOpsSuite) addContainer(c *C, host *state.Machine) *state.Machine {
+	params := state.AddMachineParams{
+		ParentId:      host.Id(),
+		ContainerType: instance.LXC,
+		Series:        "series",
+		Jobs:          []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineWithConstraints(&params)
+	c.Assert(err, IsNil)
+	return container
+}
+
+func (s *ContainerOpsSuite) TestRequestContainerOp(c *C) {
+	container := s.addContainer(c, s.host)
+	_, err := container.ContainerOp()
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+
+	err = container.RequestContainerOp(state.ContainerSnapshot, "before-upgrade")
+	c.Assert(err, IsNil)
+	op, err := container.ContainerOp()
+	c.Assert(err, IsNil)
+	c.Assert(op, DeepEquals, &state.ContainerOperation{
+		Op:       state.ContainerSnapshot,
+		Snapshot: "before-upgrade",
+	})
+
+	// Only one operation is pending at a time.
+	err = container.RequestContainerOp(state.ContainerFreeze, "")
+	c.Assert(err, ErrorMatches, `cannot freeze machine 0/lxc/0: snapshot operation still pending`)
+}
+
+func (s *ContainerOpsSuite) TestRequestContainerOpErrors(c *C) {
+	container := s.addContainer(c, s.host)
+	for i, t := range []struct {
+		op       state.ContainerOp
+		snapshot string
+		err      string
+	}{{
+		op:       state.ContainerFreeze,
+		snapshot: "foo",
+		err:      `cannot freeze machine 0/lxc/0: unexpected snapshot name "foo"`,
+	}, {
+		op:  state.ContainerSnapshot,
+		err: `cannot snapshot machine 0/lxc/0: invalid snapshot name ""`,
+	}, {
+		op:       state.ContainerRestore,
+		snapshot: "no/slashes",
+		err:      `cannot restore machine 0/lxc/0: invalid snapshot name "no/slashes"`,
+	}, {
+		op:  state.ContainerOp("explode"),
+		err: `cannot explode machine 0/lxc/0: unknown container operation "explode"`,
+	}} {
+		c.Logf("test %d: %s %q", i, t.op, t.snapshot)
+		err := container.RequestContainerOp(t.op, t.snapshot)
+		c.Check(err, ErrorMatches, t.err)
+	}
+
+	err := s.host.RequestContainerOp(state.ContainerFreeze, "")
+	c.Assert(err, ErrorMatches, `cannot freeze machine 0: machine is not a container`)
+
+	err = container.Destroy()
+	c.Assert(err, IsNil)
+	err = container.RequestContainerOp(state.ContainerFreeze, "")
+	c.Assert(err, ErrorMatches, `cannot freeze machine 0/lxc/0: machine is not alive`)
+}
+
+func (s *ContainerOpsSuite) TestCompleteContainerOp(c *C) {
+	container := s.addContainer(c, s.host)
+	err := container.CompleteContainerOp(nil)
+	c.Assert(err, ErrorMatches, `cannot complete container operation for machine 0/lxc/0: no operation pending`)
+
+	err = container.RequestContainerOp(state.ContainerFreeze, "")
+	c.Assert(err, IsNil)
+	err = container.CompleteContainerOp(nil)
+	c.Assert(err, IsNil)
+	_, err = container.ContainerOp()
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+
+	// A failed operation is kept, until another is requested.
+	err = container.RequestContainerOp(state.ContainerRestore, "before-upgrade")
+	c.Assert(err, IsNil)
+	err = container.CompleteContainerOp(fmt.Errorf("no such snapshot"))
+	c.Assert(err, IsNil)
+	op, err := container.ContainerOp()
+	c.Assert(err, IsNil)
+	c.Assert(op, DeepEquals, &state.ContainerOperation{
+		Op:       state.ContainerRestore,
+		Snapshot: "before-upgrade",
+		Error:    "no such snapshot",
+	})
+	err = container.CompleteContainerOp(nil)
+	c.Assert(err, ErrorMatches, `.*: no operation pending`)
+
+	err = container.RequestContainerOp(state.ContainerThaw, "")
+	c.Assert(err, IsNil)
+	op, err = container.ContainerOp()
+	c.Assert(err, IsNil)
+	c.Assert(op, DeepEquals, &state.ContainerOperation{Op: state.ContainerThaw})
+}
+
+func (s *ContainerOpsSuite) TestRemoveMachine(c *C) {
+	container := s.addContainer(c, s.host)
+	err := container.RequestContainerOp(state.ContainerFreeze, "")
+	c.Assert(err, IsNil)
+	err = container.EnsureDead()
+	c.Assert(err, IsNil)
+	err = container.Remove()
+	c.Assert(err, IsNil)
+	_, err = container.ContainerOp()
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+}
+
+func (s *ContainerOpsSuite) TestWatchContainerOps(c *C) {
+	container0 := s.addContainer(c, s.host)
+	err := container0.RequestContainerOp(state.ContainerFreeze, "")
+	c.Assert(err, IsNil)
+	container1 := s.addContainer(c, s.host)
+
+	w := s.host.WatchContainerOps()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange(container0.Id())
+	wc.AssertNoChange()
+
+	// Requesting an operation is reported.
+	err = container1.RequestContainerOp(state.ContainerSnapshot, "snap")
+	c.Assert(err, IsNil)
+	wc.AssertChange(container1.Id())
+	wc.AssertNoChange()
+
+	// Completing one is not.
+	err = container1.CompleteContainerOp(nil)
+	c.Assert(err, IsNil)
+	err = container0.CompleteContainerOp(fmt.Errorf("failed"))
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	// Retrying a failed one is.
+	err = container0.RequestContainerOp(state.ContainerFreeze, "")
+	c.Assert(err, IsNil)
+	wc.AssertChange(container0.Id())
+	wc.AssertNoChange()
+
+	// Operations on the containers of other machines are ignored.
+	other, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	err = s.addContainer(c, other).RequestContainerOp(state.ContainerFreeze, "")
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
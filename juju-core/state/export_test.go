@@ -168,6 +168,7 @@ func SetMachineInstanceId(m *Machine, instanceId string) {
 
 func init() {
 	logSize = logSizeTests
+	auditLogSize = auditLogSizeTests
 }
 
 // MinUnitsRevno returns the Revno of the minUnits document
@@ -0,0 +1,56 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"labix.org/v2/mgo/txn"
+
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/utils"
+)
+
+// HookPolicy returns how the units of the service run their hooks.
+func (s *Service) HookPolicy() params.HookPolicy {
+	if s.doc.HookPolicy == nil {
+		return params.HookPolicy{}
+	}
+	return *s.doc.HookPolicy
+}
+
+// SetHookPolicy changes how the units of the service run their hooks.
+func (s *Service) SetHookPolicy(policy params.HookPolicy) (err error) {
+	defer utils.ErrorContextf(&err, "cannot set hook policy for service %q", s)
+	if err := validateHookPolicy(policy); err != nil {
+		return err
+	}
+	ops := []txn.Op{{
+		C:      s.st.services.Name,
+		Id:     s.doc.Name,
+		Assert: isAliveDoc,
+		Update: D{{"$set", D{{"hookpolicy", &policy}}}},
+	}}
+	if err := s.st.runTransaction(ops); err != nil {
+		return onAbort(err, errNotAlive)
+	}
+	s.doc.HookPolicy = &policy
+	return nil
+}
+
+// validateHookPolicy returns an error if any of the durations of the
+// policy is negative, or if its delays are inconsistent.
+func validateHookPolicy(policy params.HookPolicy) error {
+	switch {
+	case policy.Timeout < 0:
+		return fmt.Errorf("negative timeout %v", policy.Timeout)
+	case policy.RetryDelay < 0:
+		return fmt.Errorf("negative retry delay %v", policy.RetryDelay)
+	case policy.MaxRetryDelay < 0:
+		return fmt.Errorf("negative maximum retry delay %v", policy.MaxRetryDelay)
+	case policy.MaxRetryDelay != 0 && policy.MaxRetryDelay < policy.RetryDelay:
+		return fmt.Errorf("maximum retry delay %v is less than retry delay %v", policy.MaxRetryDelay, policy.RetryDelay)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+type HookPolicySuite struct {
+	ConnSuite
+	service *state.Service
+}
+
+var _ = Suite(&HookPolicySuite{})
+
+func (s *HookPolicySuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.service, err = s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+}
+
+func (s *HookPolicySuite) TestSetHookPolicy(c *C) {
+	c.Assert(s.service.HookPolicy(), Equals, params.HookPolicy{})
+
+	policy := params.HookPolicy{
+		Timeout:       5 * time.Minute,
+		Retry:         true,
+		RetryDelay:    30 * time.Second,
+		MaxRetryDelay: 10 * time.Minute,
+	}
+	err := s.service.SetHookPolicy(policy)
+	c.Assert(err, IsNil)
+	c.Assert(s.service.HookPolicy(), Equals, policy)
+
+	service, err := s.State.Service("wordpress")
+	c.Assert(err, IsNil)
+	c.Assert(service.HookPolicy(), Equals, policy)
+
+	err = service.SetHookPolicy(params.HookPolicy{})
+	c.Assert(err, IsNil)
+	err = s.service.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(s.service.HookPolicy(), Equals, params.HookPolicy{})
+}
+
+var invalidHookPolicyTests = []struct {
+	policy params.HookPolicy
+	err    string
+}{{
+	policy: params.HookPolicy{Timeout: -time.Second},
+	err:    "negative timeout -1s",
+}, {
+	policy: params.HookPolicy{RetryDelay: -time.Second},
+	err:    "negative retry delay -1s",
+}, {
+	policy: params.HookPolicy{MaxRetryDelay: -time.Second},
+	err:    "negative maximum retry delay -1s",
+}, {
+	policy: params.HookPolicy{RetryDelay: time.Minute, MaxRetryDelay: time.Second},
+	err:    "maximum retry delay 1s is less than retry delay 1m0s",
+}}
+
+func (s *HookPolicySuite) TestSetHookPolicyInvalid(c *C) {
+	for i, t := range invalidHookPolicyTests {
+		c.Logf("test %d", i)
+		err := s.service.SetHookPolicy(t.policy)
+		c.Assert(err, ErrorMatches, `cannot set hook policy for service "wordpress": `+t.err)
+		c.Assert(s.service.HookPolicy(), Equals, params.HookPolicy{})
+	}
+}
+
+func (s *HookPolicySuite) TestSetHookPolicyDeadService(c *C) {
+	err := s.service.Destroy()
+	c.Assert(err, IsNil)
+	err = s.service.SetHookPolicy(params.HookPolicy{Retry: true})
+	c.Assert(err, ErrorMatches, `cannot set hook policy for service "wordpress": not found or not alive`)
+}
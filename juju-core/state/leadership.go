@@ -0,0 +1,252 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+
+	"launchpad.net/juju-core/utils"
+)
+
+// leadershipDoc records the unit leading a service. The leader holds
+// its lease for as long as its agent is alive; once the presence of the
+// agent is lost, any other alive unit of the service may take over.
+type leadershipDoc struct {
+	Service string `bson:"_id"`
+	Leader  string
+}
+
+// leaderSettingsDoc holds the settings written by the leader of a
+// service for the other units of the service to read.
+type leaderSettingsDoc struct {
+	Service  string `bson:"_id"`
+	Settings map[string]string
+}
+
+// createLeadershipOps returns the operations creating the leadership
+// and leader settings documents of the named service.
+func createLeadershipOps(st *State, serviceName string) []txn.Op {
+	return []txn.Op{{
+		C:      st.leaderships.Name,
+		Id:     serviceName,
+		Assert: txn.DocMissing,
+		Insert: &leadershipDoc{Service: serviceName},
+	}, {
+		C:      st.leaderSettings.Name,
+		Id:     serviceName,
+		Assert: txn.DocMissing,
+		Insert: &leaderSettingsDoc{
+			Service:  serviceName,
+			Settings: map[string]string{},
+		},
+	}}
+}
+
+// removeLeadershipOps returns the operations removing the leadership
+// and leader settings documents of the named service.
+func removeLeadershipOps(st *State, serviceName string) []txn.Op {
+	return []txn.Op{{
+		C:      st.leaderships.Name,
+		Id:     serviceName,
+		Remove: true,
+	}, {
+		C:      st.leaderSettings.Name,
+		Id:     serviceName,
+		Remove: true,
+	}}
+}
+
+// serviceLeader returns the name of the unit leading the named service,
+// or the empty string if the service has never had a leader. Services
+// added before leadership was recorded have no leadership document
+// until a unit first claims leadership.
+func (st *State) serviceLeader(serviceName string) (string, error) {
+	var doc leadershipDoc
+	err := st.leaderships.FindId(serviceName).One(&doc)
+	if err == mgo.ErrNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot get leader of service %q: %v", serviceName, err)
+	}
+	return doc.Leader, nil
+}
+
+// Leader returns the name of the unit leading the service, or the empty
+// string if no unit was ever elected. The agent of the returned unit may
+// have died since, in which case the next unit claiming leadership takes
+// over.
+func (s *Service) Leader() (string, error) {
+	return s.st.serviceLeader(s.doc.Name)
+}
+
+// LeaderSettings returns the settings written by the leader of the
+// service.
+func (s *Service) LeaderSettings() (map[string]string, error) {
+	var doc leaderSettingsDoc
+	err := s.st.leaderSettings.FindId(s.doc.Name).One(&doc)
+	if err == mgo.ErrNotFound {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get leader settings of service %q: %v", s, err)
+	}
+	settings := map[string]string{}
+	for key, value := range doc.Settings {
+		settings[key] = value
+	}
+	return settings, nil
+}
+
+// IsLeader returns whether the unit leads its service.
+func (u *Unit) IsLeader() (bool, error) {
+	leader, err := u.st.serviceLeader(u.doc.Service)
+	if err != nil {
+		return false, err
+	}
+	return leader == u.doc.Name, nil
+}
+
+// ClaimLeadership makes the unit the leader of its service if the
+// service has no leader, or if the agent of its leader is no longer
+// alive. It returns whether the unit leads the service.
+func (u *Unit) ClaimLeadership() (isLeader bool, err error) {
+	defer utils.ErrorContextf(&err, "cannot claim leadership of service %q for unit %q", u.doc.Service, u)
+	for i := 0; i < 3; i++ {
+		if u.doc.Life != Alive {
+			return false, fmt.Errorf("unit is not alive")
+		}
+		leader, err := u.st.serviceLeader(u.doc.Service)
+		if err != nil {
+			return false, err
+		}
+		if leader == u.doc.Name {
+			return true, nil
+		}
+		if leader != "" {
+			alive, err := u.st.pwatcher.Alive(unitGlobalKey(leader))
+			if err != nil {
+				return false, err
+			}
+			if alive {
+				return false, nil
+			}
+		}
+		ops, err := u.claimLeadershipOps(leader)
+		if err != nil {
+			return false, err
+		}
+		if err := u.st.runTransaction(ops); err != txn.ErrAborted {
+			return err == nil, err
+		}
+		if err := u.Refresh(); err != nil {
+			return false, err
+		}
+	}
+	return false, ErrExcessiveContention
+}
+
+// claimLeadershipOps returns the operations making the unit the leader
+// of its service in place of the given leader. The leadership and
+// leader settings documents of services added before leadership was
+// recorded are created by the first claim.
+func (u *Unit) claimLeadershipOps(leader string) ([]txn.Op, error) {
+	ops := []txn.Op{{
+		C:      u.st.units.Name,
+		Id:     u.doc.Name,
+		Assert: isAliveDoc,
+	}}
+	missing, err := isMissing(u.st.leaderships, u.doc.Service)
+	if err != nil {
+		return nil, err
+	}
+	if missing {
+		ops = append(ops, txn.Op{
+			C:      u.st.leaderships.Name,
+			Id:     u.doc.Service,
+			Assert: txn.DocMissing,
+			Insert: &leadershipDoc{Service: u.doc.Service, Leader: u.doc.Name},
+		})
+	} else {
+		ops = append(ops, txn.Op{
+			C:      u.st.leaderships.Name,
+			Id:     u.doc.Service,
+			Assert: D{{"leader", leader}},
+			Update: D{{"$set", D{{"leader", u.doc.Name}}}},
+		})
+	}
+	missing, err = isMissing(u.st.leaderSettings, u.doc.Service)
+	if err != nil {
+		return nil, err
+	}
+	if missing {
+		ops = append(ops, txn.Op{
+			C:      u.st.leaderSettings.Name,
+			Id:     u.doc.Service,
+			Assert: txn.DocMissing,
+			Insert: &leaderSettingsDoc{
+				Service:  u.doc.Service,
+				Settings: map[string]string{},
+			},
+		})
+	}
+	return ops, nil
+}
+
+// isMissing returns whether the collection has no document with the
+// given id.
+func isMissing(coll *mgo.Collection, id string) (bool, error) {
+	count, err := coll.FindId(id).Count()
+	if err != nil {
+		return false, fmt.Errorf("cannot read %s: %v", coll.Name, err)
+	}
+	return count == 0, nil
+}
+
+// SetLeaderSettings updates the settings of the unit's service that the
+// other units read, given that the unit leads the service. Settings set
+// to the empty string are removed.
+func (u *Unit) SetLeaderSettings(settings map[string]string) (err error) {
+	defer utils.ErrorContextf(&err, "cannot write leader settings of service %q", u.doc.Service)
+	sets, unsets := D{}, D{}
+	for key, value := range settings {
+		if key == "" || strings.ContainsAny(key, ".$") {
+			return fmt.Errorf("invalid key %q", key)
+		}
+		if value == "" {
+			unsets = append(unsets, bson.DocElem{"settings." + key, 1})
+		} else {
+			sets = append(sets, bson.DocElem{"settings." + key, value})
+		}
+	}
+	update := D{}
+	if len(sets) > 0 {
+		update = append(update, bson.DocElem{"$set", sets})
+	}
+	if len(unsets) > 0 {
+		update = append(update, bson.DocElem{"$unset", unsets})
+	}
+	if len(update) == 0 {
+		return nil
+	}
+	ops := []txn.Op{{
+		C:      u.st.leaderships.Name,
+		Id:     u.doc.Service,
+		Assert: D{{"leader", u.doc.Name}},
+	}, {
+		C:      u.st.leaderSettings.Name,
+		Id:     u.doc.Service,
+		Assert: txn.DocExists,
+		Update: update,
+	}}
+	if err := u.st.runTransaction(ops); err != nil {
+		return onAbort(err, fmt.Errorf("unit %q is not the leader", u))
+	}
+	return nil
+}
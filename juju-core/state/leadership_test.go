@@ -0,0 +1,200 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/state"
+	statetesting "launchpad.net/juju-core/state/testing"
+)
+
+type LeadershipSuite struct {
+	ConnSuite
+	service *state.Service
+	unit0   *state.Unit
+	unit1   *state.Unit
+}
+
+var _ = Suite(&LeadershipSuite{})
+
+func (s *LeadershipSuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.service, err = s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	s.unit0, err = s.service.AddUnit()
+	c.Assert(err, IsNil)
+	s.unit1, err = s.service.AddUnit()
+	c.Assert(err, IsNil)
+}
+
+func (s *LeadershipSuite) assertLeader(c *C, expect string) {
+	leader, err := s.service.Leader()
+	c.Assert(err, IsNil)
+	c.Assert(leader, Equals, expect)
+	for _, u := range []*state.Unit{s.unit0, s.unit1} {
+		isLeader, err := u.IsLeader()
+		c.Assert(err, IsNil)
+		c.Assert(isLeader, Equals, u.Name() == expect)
+	}
+}
+
+func (s *LeadershipSuite) TestClaimLeadership(c *C) {
+	s.assertLeader(c, "")
+
+	pinger, err := s.unit0.SetAgentAlive()
+	c.Assert(err, IsNil)
+	defer pinger.Stop()
+	s.State.Sync()
+
+	isLeader, err := s.unit0.ClaimLeadership()
+	c.Assert(err, IsNil)
+	c.Assert(isLeader, Equals, true)
+	s.assertLeader(c, "wordpress/0")
+
+	// Claiming again is a no-op.
+	isLeader, err = s.unit0.ClaimLeadership()
+	c.Assert(err, IsNil)
+	c.Assert(isLeader, Equals, true)
+
+	// The lease is held while the agent of the leader is alive.
+	isLeader, err = s.unit1.ClaimLeadership()
+	c.Assert(err, IsNil)
+	c.Assert(isLeader, Equals, false)
+	s.assertLeader(c, "wordpress/0")
+
+	// Once it dies, another unit takes over.
+	err = pinger.Kill()
+	c.Assert(err, IsNil)
+	s.State.Sync()
+	isLeader, err = s.unit1.ClaimLeadership()
+	c.Assert(err, IsNil)
+	c.Assert(isLeader, Equals, true)
+	s.assertLeader(c, "wordpress/1")
+}
+
+func (s *LeadershipSuite) TestClaimLeadershipNotAlive(c *C) {
+	err := s.unit0.Destroy()
+	c.Assert(err, IsNil)
+	_, err = s.unit0.ClaimLeadership()
+	c.Assert(err, ErrorMatches, `cannot claim leadership of service "wordpress" for unit "wordpress/0": unit is not alive`)
+	s.assertLeader(c, "")
+}
+
+func (s *LeadershipSuite) TestLeaderSettings(c *C) {
+	settings, err := s.service.LeaderSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, map[string]string{})
+
+	err = s.unit0.SetLeaderSettings(map[string]string{"master": "10.0.0.1"})
+	c.Assert(err, ErrorMatches, `cannot write leader settings of service "wordpress": unit "wordpress/0" is not the leader`)
+
+	_, err = s.unit0.ClaimLeadership()
+	c.Assert(err, IsNil)
+	err = s.unit0.SetLeaderSettings(map[string]string{"master": "10.0.0.1", "replicas": "2"})
+	c.Assert(err, IsNil)
+	err = s.unit0.SetLeaderSettings(map[string]string{"replicas": "", "password": "s3kr1t"})
+	c.Assert(err, IsNil)
+	settings, err = s.service.LeaderSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, map[string]string{
+		"master":   "10.0.0.1",
+		"password": "s3kr1t",
+	})
+
+	err = s.unit0.SetLeaderSettings(map[string]string{"a.b": "c"})
+	c.Assert(err, ErrorMatches, `cannot write leader settings of service "wordpress": invalid key "a.b"`)
+	err = s.unit1.SetLeaderSettings(map[string]string{"master": "10.0.0.2"})
+	c.Assert(err, ErrorMatches, `cannot write leader settings of service "wordpress": unit "wordpress/1" is not the leader`)
+}
+
+func (s *LeadershipSuite) TestWatchLeadership(c *C) {
+	w := s.service.WatchLeadership()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	pinger, err := s.unit0.SetAgentAlive()
+	c.Assert(err, IsNil)
+	defer pinger.Stop()
+	s.State.Sync()
+	_, err = s.unit0.ClaimLeadership()
+	c.Assert(err, IsNil)
+	wc.AssertOneChange()
+
+	// Changes unrelated to leadership are ignored.
+	err = s.unit0.SetLeaderSettings(map[string]string{"master": "10.0.0.1"})
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	// The death of the leader's agent is reported.
+	err = pinger.Kill()
+	c.Assert(err, IsNil)
+	wc.AssertOneChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
+func (s *LeadershipSuite) TestWatchLeaderSettings(c *C) {
+	w := s.service.WatchLeaderSettings()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	_, err := s.unit0.ClaimLeadership()
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	err = s.unit0.SetLeaderSettings(map[string]string{"master": "10.0.0.1"})
+	c.Assert(err, IsNil)
+	wc.AssertOneChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
+func (s *LeadershipSuite) TestRemoveService(c *C) {
+	_, err := s.unit0.ClaimLeadership()
+	c.Assert(err, IsNil)
+	for _, u := range []*state.Unit{s.unit0, s.unit1} {
+		err = u.EnsureDead()
+		c.Assert(err, IsNil)
+		err = u.Remove()
+		c.Assert(err, IsNil)
+	}
+	err = s.service.Destroy()
+	c.Assert(err, IsNil)
+	for _, name := range []string{"leaderships", "leadersettings"} {
+		count, err := s.MgoSuite.Session.DB("juju").C(name).FindId("wordpress").Count()
+		c.Assert(err, IsNil)
+		c.Assert(count, Equals, 0)
+	}
+}
+
+func (s *LeadershipSuite) TestServiceAddedBeforeLeadership(c *C) {
+	// Services added before leadership was recorded have no leadership
+	// or leader settings documents.
+	for _, name := range []string{"leaderships", "leadersettings"} {
+		err := s.MgoSuite.Session.DB("juju").C(name).RemoveId("wordpress")
+		c.Assert(err, IsNil)
+	}
+	s.assertLeader(c, "")
+	settings, err := s.service.LeaderSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, map[string]string{})
+	err = s.unit0.SetLeaderSettings(map[string]string{"master": "10.0.0.1"})
+	c.Assert(err, ErrorMatches, `cannot write leader settings of service "wordpress": unit "wordpress/0" is not the leader`)
+
+	isLeader, err := s.unit0.ClaimLeadership()
+	c.Assert(err, IsNil)
+	c.Assert(isLeader, Equals, true)
+	s.assertLeader(c, "wordpress/0")
+	err = s.unit0.SetLeaderSettings(map[string]string{"master": "10.0.0.1"})
+	c.Assert(err, IsNil)
+	settings, err = s.service.LeaderSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, map[string]string{"master": "10.0.0.1"})
+}
@@ -53,6 +53,17 @@ func (job MachineJob) String() string {
 	return string(jobNames[j])
 }
 
+// MachineJobFromParams returns the job corresponding to the given
+// params.MachineJob.
+func MachineJobFromParams(job params.MachineJob) (MachineJob, error) {
+	for j, name := range jobNames {
+		if name == job && j > 0 {
+			return MachineJob(j), nil
+		}
+	}
+	return -1, fmt.Errorf("invalid machine job %q", job)
+}
+
 // machineDoc represents the internal state of a machine in MongoDB.
 // Note the correspondence with MachineInfo in state/api/params.
 type machineDoc struct {
@@ -68,6 +79,16 @@ type machineDoc struct {
 	PasswordHash  string
 	Clean         bool
 	Addresses     []address
+	// MachineAddresses holds the addresses of the network interfaces
+	// of the machine, as reported by its agent.
+	MachineAddresses []address `bson:",omitempty"`
+	// SupportedContainers lists the container types the machine can
+	// host, once SupportedContainersKnown is set by its agent.
+	SupportedContainers      []instance.ContainerType `bson:",omitempty"`
+	SupportedContainersKnown bool
+	// PublishedPorts holds the ports of a container machine published
+	// on the same ports of its host.
+	PublishedPorts []instance.Port `bson:",omitempty"`
 	// Deprecated. InstanceId, now lives on instanceData.
 	// This attribute is retained so that data from existing machines can be read.
 	// SCHEMACHANGE
@@ -103,6 +124,55 @@ func (m *Machine) ContainerType() instance.ContainerType {
 	return instance.ContainerType(m.doc.ContainerType)
 }
 
+// SupportedContainers returns the container types the machine can host,
+// and whether they are known yet: they are recorded by the machine agent
+// once it has checked what the machine provides.
+func (m *Machine) SupportedContainers() ([]instance.ContainerType, bool) {
+	return m.doc.SupportedContainers, m.doc.SupportedContainersKnown
+}
+
+// SupportsContainerType reports whether the machine can host containers
+// of the given type. The type is assumed to be supported until the
+// machine agent records the supported types.
+func (m *Machine) SupportsContainerType(ctype instance.ContainerType) bool {
+	if !m.doc.SupportedContainersKnown {
+		return true
+	}
+	for _, supported := range m.doc.SupportedContainers {
+		if supported == ctype {
+			return true
+		}
+	}
+	return false
+}
+
+// SetSupportedContainers records the container types the machine can
+// host. Containers of other types cannot be added to the machine from
+// then on.
+func (m *Machine) SetSupportedContainers(containers []instance.ContainerType) (err error) {
+	defer utils.ErrorContextf(&err, "cannot set supported containers of machine %v", m)
+	for _, ctype := range containers {
+		if _, err := instance.ParseSupportedContainerType(string(ctype)); err != nil {
+			return err
+		}
+	}
+	ops := []txn.Op{{
+		C:      m.st.machines.Name,
+		Id:     m.doc.Id,
+		Assert: notDeadDoc,
+		Update: D{{"$set", D{
+			{"supportedcontainers", containers},
+			{"supportedcontainersknown", true},
+		}}},
+	}}
+	if err := m.st.runTransaction(ops); err != nil {
+		return onAbort(err, errDead)
+	}
+	m.doc.SupportedContainers = containers
+	m.doc.SupportedContainersKnown = true
+	return nil
+}
+
 // machineGlobalKey returns the global database key for the identified machine.
 func machineGlobalKey(id string) string {
 	return "m#" + id
@@ -418,6 +488,7 @@ func (m *Machine) Remove() (err error) {
 		removeStatusOp(m.st, m.globalKey()),
 		removeConstraintsOp(m.st, m.globalKey()),
 		annotationRemoveOp(m.st, m.globalKey()),
+		removeContainerOpOp(m.st, m.doc.Id),
 	}
 	ops = append(ops, removeContainerRefOps(m.st, m.Id())...)
 	// The only abort conditions in play indicate that the machine has already
@@ -617,6 +688,82 @@ func (m *Machine) SetAddresses(addresses []instance.Address) (err error) {
 	return nil
 }
 
+// MachineAddresses returns the addresses of the network interfaces of
+// the machine, as reported by its agent.
+func (m *Machine) MachineAddresses() (addresses []instance.Address) {
+	for _, address := range m.doc.MachineAddresses {
+		addresses = append(addresses, address.InstanceAddress())
+	}
+	return
+}
+
+// SetMachineAddresses records the addresses of the network interfaces
+// of the machine, as reported by its agent.
+func (m *Machine) SetMachineAddresses(addresses []instance.Address) (err error) {
+	var stateAddresses []address
+	for _, address := range addresses {
+		stateAddresses = append(stateAddresses, NewAddress(address))
+	}
+	ops := []txn.Op{{
+		C:      m.st.machines.Name,
+		Id:     m.doc.Id,
+		Assert: notDeadDoc,
+		Update: D{{"$set", D{{"machineaddresses", stateAddresses}}}},
+	}}
+	if err = m.st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot set machine addresses of machine %v: %v", m, onAbort(err, errDead))
+	}
+	m.doc.MachineAddresses = stateAddresses
+	return nil
+}
+
+// NetworkAddresses returns the addresses of the machine known to the
+// provider, followed by the other addresses reported by its agent.
+func (m *Machine) NetworkAddresses() []instance.Address {
+	addresses := m.Addresses()
+	known := make(map[string]bool)
+	for _, addr := range addresses {
+		known[addr.Value] = true
+	}
+	for _, addr := range m.MachineAddresses() {
+		if !known[addr.Value] {
+			known[addr.Value] = true
+			addresses = append(addresses, addr)
+		}
+	}
+	return addresses
+}
+
+// PublishedPorts returns the ports of the container machine that are
+// published on the same ports of its host.
+func (m *Machine) PublishedPorts() []instance.Port {
+	ports := append([]instance.Port{}, m.doc.PublishedPorts...)
+	SortPorts(ports)
+	return ports
+}
+
+// SetPublishedPorts records the ports of the container machine that are
+// published on the same ports of its host.
+func (m *Machine) SetPublishedPorts(ports []instance.Port) (err error) {
+	defer utils.ErrorContextf(&err, "cannot set published ports of machine %v", m)
+	if _, ok := m.ParentId(); !ok {
+		return fmt.Errorf("machine is not a container")
+	}
+	ports = append([]instance.Port{}, ports...)
+	SortPorts(ports)
+	ops := []txn.Op{{
+		C:      m.st.machines.Name,
+		Id:     m.doc.Id,
+		Assert: notDeadDoc,
+		Update: D{{"$set", D{{"publishedports", ports}}}},
+	}}
+	if err := m.st.runTransaction(ops); err != nil {
+		return onAbort(err, errDead)
+	}
+	m.doc.PublishedPorts = ports
+	return nil
+}
+
 func (e *NotProvisionedError) Error() string {
 	return fmt.Sprintf("machine %v is not provisioned", e.machineId)
 }
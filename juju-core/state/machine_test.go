@@ -950,3 +950,95 @@ func (s *MachineSuite) TestSetAddresses(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(machine.Addresses(), DeepEquals, addresses)
 }
+
+func (s *MachineSuite) TestSetMachineAddresses(c *C) {
+	machine, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	c.Assert(machine.MachineAddresses(), HasLen, 0)
+
+	addresses := []instance.Address{
+		instance.NewScopedAddress("127.0.0.1"),
+		instance.NewScopedAddress("10.0.0.2"),
+	}
+	err = machine.SetMachineAddresses(addresses)
+	c.Assert(err, IsNil)
+	err = machine.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(machine.MachineAddresses(), DeepEquals, addresses)
+	c.Assert(machine.Addresses(), HasLen, 0)
+}
+
+func (s *MachineSuite) TestNetworkAddresses(c *C) {
+	machine, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	c.Assert(machine.NetworkAddresses(), HasLen, 0)
+
+	err = machine.SetAddresses([]instance.Address{
+		instance.NewScopedAddress("8.8.8.8"),
+		instance.NewScopedAddress("10.0.0.2"),
+	})
+	c.Assert(err, IsNil)
+	err = machine.SetMachineAddresses([]instance.Address{
+		instance.NewScopedAddress("10.0.0.2"),
+		instance.NewScopedAddress("192.168.0.4"),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(machine.NetworkAddresses(), DeepEquals, []instance.Address{
+		instance.NewScopedAddress("8.8.8.8"),
+		instance.NewScopedAddress("10.0.0.2"),
+		instance.NewScopedAddress("192.168.0.4"),
+	})
+}
+
+func (s *MachineSuite) TestSetPublishedPorts(c *C) {
+	params := state.AddMachineParams{
+		ParentId:      s.machine.Id(),
+		ContainerType: instance.DOCKER,
+		Series:        "series",
+		Jobs:          []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineWithConstraints(&params)
+	c.Assert(err, IsNil)
+	c.Assert(container.PublishedPorts(), HasLen, 0)
+
+	ports := []instance.Port{{"udp", 53}, {"tcp", 443}, {"tcp", 80}}
+	err = container.SetPublishedPorts(ports)
+	c.Assert(err, IsNil)
+	err = container.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(container.PublishedPorts(), DeepEquals, []instance.Port{{"tcp", 80}, {"tcp", 443}, {"udp", 53}})
+
+	err = s.machine.SetPublishedPorts(ports)
+	c.Assert(err, ErrorMatches, `cannot set published ports of machine 0: machine is not a container`)
+
+	err = container.EnsureDead()
+	c.Assert(err, IsNil)
+	err = container.SetPublishedPorts(nil)
+	c.Assert(err, ErrorMatches, `cannot set published ports of machine 0/docker/0: not found or dead`)
+}
+
+func (s *MachineSuite) TestSetSupportedContainers(c *C) {
+	containers, known := s.machine.SupportedContainers()
+	c.Assert(known, Equals, false)
+	c.Assert(containers, HasLen, 0)
+	// Until they are known, all the container types are allowed.
+	c.Assert(s.machine.SupportsContainerType(instance.DOCKER), Equals, true)
+
+	err := s.machine.SetSupportedContainers([]instance.ContainerType{instance.LXC})
+	c.Assert(err, IsNil)
+	err = s.machine.Refresh()
+	c.Assert(err, IsNil)
+	containers, known = s.machine.SupportedContainers()
+	c.Assert(known, Equals, true)
+	c.Assert(containers, DeepEquals, []instance.ContainerType{instance.LXC})
+	c.Assert(s.machine.SupportsContainerType(instance.LXC), Equals, true)
+	c.Assert(s.machine.SupportsContainerType(instance.DOCKER), Equals, false)
+
+	// A machine may support no containers at all.
+	err = s.machine.SetSupportedContainers(nil)
+	c.Assert(err, IsNil)
+	c.Assert(s.machine.SupportsContainerType(instance.LXC), Equals, false)
+
+	err = s.machine.SetSupportedContainers([]instance.ContainerType{"zone"})
+	c.Assert(err, ErrorMatches, `cannot set supported containers of machine 0: invalid container type "zone"`)
+}
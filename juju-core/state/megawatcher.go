@@ -10,6 +10,7 @@ import (
 
 	"labix.org/v2/mgo"
 
+	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/errors"
 	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/multiwatcher"
@@ -97,11 +98,19 @@ func (u *backingUnit) updated(st *State, store *multiwatcher.Store, id interface
 		}
 		info.Status = sdoc.Status
 		info.StatusInfo = sdoc.StatusInfo
+		wdoc, err := getWorkloadStatus(st, u.Name)
+		if err != nil {
+			return err
+		}
+		info.WorkloadStatus = wdoc.Status
+		info.WorkloadInfo = wdoc.Info
 	} else {
 		// The entry already exists, so preserve the current status.
 		oldInfo := oldInfo.(*params.UnitInfo)
 		info.Status = oldInfo.Status
 		info.StatusInfo = oldInfo.StatusInfo
+		info.WorkloadStatus = oldInfo.WorkloadStatus
+		info.WorkloadInfo = oldInfo.WorkloadInfo
 	}
 	store.Update(info)
 	return nil
@@ -156,10 +165,13 @@ func (svc *backingService) updated(st *State, store *multiwatcher.Store, id inte
 	}
 	if needConfig {
 		var err error
-		info.Config, _, err = readSettingsDoc(st, serviceSettingsKey(svc.Name, svc.CharmURL))
+		config, _, err := readSettingsDoc(st, serviceSettingsKey(svc.Name, svc.CharmURL))
 		if err != nil {
 			return err
 		}
+		if info.Config, err = redactSecretOptions(st, svc.CharmURL, config); err != nil {
+			return err
+		}
 	}
 	store.Update(info)
 	return nil
@@ -273,6 +285,31 @@ func (a *backingStatus) mongoId() interface{} {
 	panic("cannot find mongo id from status document")
 }
 
+type backingWorkloadStatus workloadStatusDoc
+
+func (s *backingWorkloadStatus) updated(st *State, store *multiwatcher.Store, id interface{}) error {
+	info0 := store.Get(params.EntityId{Kind: "unit", Id: id})
+	if info0 == nil {
+		// The unit info doesn't exist. Ignore the status until it does.
+		return nil
+	}
+	newInfo := *info0.(*params.UnitInfo)
+	newInfo.WorkloadStatus = s.Status
+	newInfo.WorkloadInfo = s.Info
+	store.Update(&newInfo)
+	return nil
+}
+
+func (s *backingWorkloadStatus) removed(st *State, store *multiwatcher.Store, id interface{}) error {
+	// If the workload status is removed, the unit will follow not long
+	// after, so do nothing.
+	return nil
+}
+
+func (s *backingWorkloadStatus) mongoId() interface{} {
+	panic("cannot find mongo id from workload status document")
+}
+
 type backingConstraints constraintsDoc
 
 func (s *backingConstraints) updated(st *State, store *multiwatcher.Store, id interface{}) error {
@@ -330,7 +367,13 @@ func (s *backingSettings) updated(st *State, store *multiwatcher.Store, id inter
 		}
 		newInfo := *info
 		cleanSettingsMap(*s)
-		newInfo.Config = *s
+		curl, err := charm.ParseURL(url)
+		if err != nil {
+			return err
+		}
+		if newInfo.Config, err = redactSecretOptions(st, curl, *s); err != nil {
+			return err
+		}
 		info0 = &newInfo
 	default:
 		return nil
@@ -339,6 +382,28 @@ func (s *backingSettings) updated(st *State, store *multiwatcher.Store, id inter
 	return nil
 }
 
+// redactedOption replaces the values of secret options in the service
+// configuration sent to the clients of the AllWatcher.
+const redactedOption = "<redacted>"
+
+// redactSecretOptions returns the settings of a service using the charm
+// with the given URL, with the values of the options of type secret
+// redacted.
+func redactSecretOptions(st *State, curl *charm.URL, settings map[string]interface{}) (map[string]interface{}, error) {
+	ch, err := st.Charm(curl)
+	if err != nil {
+		return nil, err
+	}
+	redacted := make(map[string]interface{}, len(settings))
+	for name, value := range settings {
+		if option, ok := ch.Config().Options[name]; ok && option.Type == "secret" && value != nil {
+			value = redactedOption
+		}
+		redacted[name] = value
+	}
+	return redacted, nil
+}
+
 func (s *backingSettings) removed(st *State, store *multiwatcher.Store, id interface{}) error {
 	return nil
 }
@@ -408,6 +473,7 @@ var (
 	_ backingEntityDoc = (*backingRelation)(nil)
 	_ backingEntityDoc = (*backingAnnotation)(nil)
 	_ backingEntityDoc = (*backingStatus)(nil)
+	_ backingEntityDoc = (*backingWorkloadStatus)(nil)
 	_ backingEntityDoc = (*backingConstraints)(nil)
 	_ backingEntityDoc = (*backingSettings)(nil)
 )
@@ -452,6 +518,10 @@ func newAllWatcherStateBacking(st *State) multiwatcher.Backing {
 		Collection: st.statuses,
 		infoType:   reflect.TypeOf(backingStatus{}),
 		subsidiary: true,
+	}, {
+		Collection: st.workloadStatuses,
+		infoType:   reflect.TypeOf(backingWorkloadStatus{}),
+		subsidiary: true,
 	}, {
 		Collection: st.constraints,
 		infoType:   reflect.TypeOf(backingConstraints{}),
@@ -136,12 +136,13 @@ func (s *storeManagerStateSuite) setUpScenario(c *C) (entities entityInfoSlice)
 		c.Assert(m.Tag(), Equals, fmt.Sprintf("machine-%d", i+1))
 
 		add(&params.UnitInfo{
-			Name:      fmt.Sprintf("wordpress/%d", i),
-			Service:   wordpress.Name(),
-			Series:    m.Series(),
-			MachineId: m.Id(),
-			Ports:     []instance.Port{},
-			Status:    params.StatusPending,
+			Name:           fmt.Sprintf("wordpress/%d", i),
+			Service:        wordpress.Name(),
+			Series:         m.Series(),
+			MachineId:      m.Id(),
+			Ports:          []instance.Port{},
+			Status:         params.StatusPending,
+			WorkloadStatus: params.WorkloadUnknown,
 		})
 		pairs := map[string]string{"name": fmt.Sprintf("bar %d", i)}
 		err = wu.SetAnnotations(pairs)
@@ -183,11 +184,12 @@ func (s *storeManagerStateSuite) setUpScenario(c *C) (entities entityInfoSlice)
 		c.Assert(ok, Equals, true)
 		c.Assert(deployer, Equals, fmt.Sprintf("unit-wordpress-%d", i))
 		add(&params.UnitInfo{
-			Name:    fmt.Sprintf("logging/%d", i),
-			Service: "logging",
-			Series:  "series",
-			Ports:   []instance.Port{},
-			Status:  params.StatusPending,
+			Name:           fmt.Sprintf("logging/%d", i),
+			Service:        "logging",
+			Series:         "series",
+			Ports:          []instance.Port{},
+			Status:         params.StatusPending,
+			WorkloadStatus: params.WorkloadUnknown,
 		})
 	}
 	return
@@ -355,6 +357,7 @@ var allWatcherChangedTests = []struct {
 				Ports:          []instance.Port{{"tcp", 12345}},
 				Status:         params.StatusError,
 				StatusInfo:     "failure",
+				WorkloadStatus: params.WorkloadUnknown,
 			},
 		},
 	}, {
@@ -484,6 +487,26 @@ var allWatcherChangedTests = []struct {
 				Config:   charm.Settings{"blog-title": "boring"},
 			},
 		},
+	}, {
+		about: "secret options of the service config are redacted",
+		setUp: func(c *C, st *State) {
+			svc, err := st.AddService("typed", AddTestingCharm(c, st, "typed-config"))
+			c.Assert(err, IsNil)
+			err = svc.UpdateConfigSettings(charm.Settings{"flavour": "chocolate", "password": "s3cret"})
+			c.Assert(err, IsNil)
+		},
+		change: watcher.Change{
+			C:  "services",
+			Id: "typed",
+		},
+		expectContents: []params.EntityInfo{
+			&params.ServiceInfo{
+				Name:     "typed",
+				CharmURL: "local:series/series-typed-config-1",
+				Life:     params.Life(Alive.String()),
+				Config:   charm.Settings{"flavour": "chocolate", "password": "<redacted>"},
+			},
+		},
 	},
 	// Relation changes
 	{
@@ -648,6 +671,56 @@ var allWatcherChangedTests = []struct {
 			},
 		},
 	},
+	// Workload status changes
+	{
+		about: "no unit in state -> do nothing",
+		setUp: func(c *C, st *State) {},
+		change: watcher.Change{
+			C:  "workloadstatuses",
+			Id: "wordpress/0",
+		},
+	}, {
+		about: "no change if unit is not in store",
+		setUp: func(c *C, st *State) {
+			wordpress, err := st.AddService("wordpress", AddTestingCharm(c, st, "wordpress"))
+			c.Assert(err, IsNil)
+			u, err := wordpress.AddUnit()
+			c.Assert(err, IsNil)
+			err = u.SetWorkloadStatus(params.WorkloadBlocked, "missing config")
+			c.Assert(err, IsNil)
+		},
+		change: watcher.Change{
+			C:  "workloadstatuses",
+			Id: "wordpress/0",
+		},
+	}, {
+		about: "workload status is changed if the unit exists in the store",
+		add: []params.EntityInfo{&params.UnitInfo{
+			Name:           "wordpress/0",
+			Status:         params.StatusStarted,
+			WorkloadStatus: params.WorkloadUnknown,
+		}},
+		setUp: func(c *C, st *State) {
+			wordpress, err := st.AddService("wordpress", AddTestingCharm(c, st, "wordpress"))
+			c.Assert(err, IsNil)
+			u, err := wordpress.AddUnit()
+			c.Assert(err, IsNil)
+			err = u.SetWorkloadStatus(params.WorkloadWaiting, "waiting for database")
+			c.Assert(err, IsNil)
+		},
+		change: watcher.Change{
+			C:  "workloadstatuses",
+			Id: "wordpress/0",
+		},
+		expectContents: []params.EntityInfo{
+			&params.UnitInfo{
+				Name:           "wordpress/0",
+				Status:         params.StatusStarted,
+				WorkloadStatus: params.WorkloadWaiting,
+				WorkloadInfo:   "waiting for database",
+			},
+		},
+	},
 	// Machine status changes
 	{
 		about: "no machine in state -> do nothing",
@@ -816,6 +889,29 @@ var allWatcherChangedTests = []struct {
 				Config:   charm.Settings{"key.dotted": "foo"},
 			},
 		},
+	}, {
+		about: "secret options are redacted when reading from the backing store",
+		add: []params.EntityInfo{&params.ServiceInfo{
+			Name:     "typed",
+			CharmURL: "local:series/series-typed-config-1",
+			Config:   charm.Settings{},
+		}},
+		setUp: func(c *C, st *State) {
+			svc, err := st.AddService("typed", AddTestingCharm(c, st, "typed-config"))
+			c.Assert(err, IsNil)
+			setServiceConfigAttr(c, svc, "password", "s3cret")
+		},
+		change: watcher.Change{
+			C:  "settings",
+			Id: "s#typed#local:series/series-typed-config-1",
+		},
+		expectContents: []params.EntityInfo{
+			&params.ServiceInfo{
+				Name:     "typed",
+				CharmURL: "local:series/series-typed-config-1",
+				Config:   charm.Settings{"password": "<redacted>"},
+			},
+		},
 	}, {
 		about: "service config is unchanged if service exists in the store with a different URL",
 		add: []params.EntityInfo{&params.ServiceInfo{
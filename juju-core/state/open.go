@@ -169,6 +169,8 @@ var indexes = []struct {
 	{"units", []string{"principal"}},
 	{"units", []string{"machineid"}},
 	{"users", []string{"name"}},
+	{"actions", []string{"unit"}},
+	{"storageinstances", []string{"unit"}},
 }
 
 // The capped collection used for transaction logs defaults to 10MB.
@@ -179,6 +181,13 @@ var (
 	logSizeTests = 1000000
 )
 
+// The capped collection holding the audit log defaults to 50MB,
+// and is likewise tweaked to 1MB in tests.
+var (
+	auditLogSize      = 50000000
+	auditLogSizeTests = 1000000
+)
+
 func maybeUnauthorized(err error, msg string) error {
 	if err == nil {
 		return nil
@@ -211,26 +220,34 @@ func newState(session *mgo.Session, info *Info) (*State, error) {
 		}
 	}
 	st := &State{
-		info:           info,
-		db:             db,
-		environments:   db.C("environments"),
-		charms:         db.C("charms"),
-		machines:       db.C("machines"),
-		containerRefs:  db.C("containerRefs"),
-		instanceData:   db.C("instanceData"),
-		relations:      db.C("relations"),
-		relationScopes: db.C("relationscopes"),
-		services:       db.C("services"),
-		minUnits:       db.C("minunits"),
-		settings:       db.C("settings"),
-		settingsrefs:   db.C("settingsrefs"),
-		constraints:    db.C("constraints"),
-		units:          db.C("units"),
-		users:          db.C("users"),
-		presence:       pdb.C("presence"),
-		cleanups:       db.C("cleanups"),
-		annotations:    db.C("annotations"),
-		statuses:       db.C("statuses"),
+		info:             info,
+		db:               db,
+		environments:     db.C("environments"),
+		charms:           db.C("charms"),
+		machines:         db.C("machines"),
+		containerRefs:    db.C("containerRefs"),
+		instanceData:     db.C("instanceData"),
+		relations:        db.C("relations"),
+		relationScopes:   db.C("relationscopes"),
+		services:         db.C("services"),
+		minUnits:         db.C("minunits"),
+		containerOps:     db.C("containerops"),
+		actions:          db.C("actions"),
+		storageInstances: db.C("storageinstances"),
+		settings:         db.C("settings"),
+		settingsrefs:     db.C("settingsrefs"),
+		constraints:      db.C("constraints"),
+		units:            db.C("units"),
+		users:            db.C("users"),
+		presence:         pdb.C("presence"),
+		cleanups:         db.C("cleanups"),
+		annotations:      db.C("annotations"),
+		statuses:         db.C("statuses"),
+		workloadStatuses: db.C("workloadstatuses"),
+		leaderships:      db.C("leaderships"),
+		leaderSettings:   db.C("leadersettings"),
+		resources:        db.C("resources"),
+		auditLog:         db.C("auditlog"),
 	}
 	log := db.C("txns.log")
 	logInfo := mgo.CollectionInfo{Capped: true, MaxBytes: logSize}
@@ -240,6 +257,11 @@ func newState(session *mgo.Session, info *Info) (*State, error) {
 	if err != nil && err.Error() != "collection already exists" {
 		return nil, maybeUnauthorized(err, "cannot create log collection")
 	}
+	auditLogInfo := mgo.CollectionInfo{Capped: true, MaxBytes: auditLogSize}
+	err = st.auditLog.Create(&auditLogInfo)
+	if err != nil && err.Error() != "collection already exists" {
+		return nil, maybeUnauthorized(err, "cannot create audit log collection")
+	}
 	st.runner = txn.NewRunner(db.C("txns"))
 	st.runner.ChangeLog(db.C("txns.log"))
 	st.watcher = watcher.New(db.C("txns.log"))
@@ -13,6 +13,7 @@ import (
 
 	"launchpad.net/juju-core/charm"
 	errors "launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/names"
 	"launchpad.net/juju-core/utils"
 )
@@ -43,6 +44,52 @@ func (ru *RelationUnit) PrivateAddress() (string, bool) {
 	return ru.unit.PrivateAddress()
 }
 
+// IngressAddress returns the address the other units of the relation
+// should use to reach the unit, or the empty string if no suitable
+// address is known. Units in container-scoped relations share a
+// machine, and so may use addresses local to it. The addresses of
+// docker containers are only reachable from their host, which publishes
+// the ports of exposed services; the host address is used for them in
+// other relations once the ports opened by the unit are published.
+func (ru *RelationUnit) IngressAddress() (string, error) {
+	id, err := ru.unit.AssignedMachineId()
+	if err != nil {
+		return "", err
+	}
+	m, err := ru.st.Machine(id)
+	if err != nil {
+		return "", err
+	}
+	if ru.endpoint.Scope == charm.ScopeContainer {
+		return instance.SelectInternalAddress(m.NetworkAddresses(), true), nil
+	}
+	if parentId, ok := m.ParentId(); ok && m.ContainerType() == instance.DOCKER && ru.portsPublished(m) {
+		if m, err = ru.st.Machine(parentId); err != nil {
+			return "", err
+		}
+	}
+	return instance.SelectInternalAddress(m.NetworkAddresses(), false), nil
+}
+
+// portsPublished returns whether the unit has opened ports, and all of
+// them are published on the host of the container machine.
+func (ru *RelationUnit) portsPublished(m *Machine) bool {
+	opened := ru.unit.OpenedPorts()
+	if len(opened) == 0 {
+		return false
+	}
+	published := make(map[instance.Port]bool)
+	for _, port := range m.PublishedPorts() {
+		published[port] = true
+	}
+	for _, port := range opened {
+		if !published[port] {
+			return false
+		}
+	}
+	return true
+}
+
 // ErrCannotEnterScope indicates that a relation unit failed to enter its scope
 // due to either the unit or the relation not being Alive.
 var ErrCannotEnterScope = stderrors.New("cannot enter scope: unit or relation is not alive")
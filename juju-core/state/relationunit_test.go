@@ -11,6 +11,7 @@ import (
 
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/testing"
 	coretesting "launchpad.net/juju-core/testing"
@@ -552,6 +553,90 @@ func (s *RelationUnitSuite) assertNoScopeChange(c *C, ws ...*state.RelationScope
 	}
 }
 
+func (s *RelationUnitSuite) TestIngressAddress(c *C) {
+	prr := NewProReqRelation(c, &s.ConnSuite, charm.ScopeGlobal)
+	_, err := prr.pru0.IngressAddress()
+	c.Assert(err, ErrorMatches, `unit "mysql/0" is not assigned to a machine`)
+
+	err = prr.pu0.AssignToNewMachine()
+	c.Assert(err, IsNil)
+	address, err := prr.pru0.IngressAddress()
+	c.Assert(err, IsNil)
+	c.Assert(address, Equals, "")
+
+	id, err := prr.pu0.AssignedMachineId()
+	c.Assert(err, IsNil)
+	machine, err := s.State.Machine(id)
+	c.Assert(err, IsNil)
+	err = machine.SetAddresses([]instance.Address{instance.NewScopedAddress("8.8.8.8")})
+	c.Assert(err, IsNil)
+	address, err = prr.pru0.IngressAddress()
+	c.Assert(err, IsNil)
+	c.Assert(address, Equals, "8.8.8.8")
+
+	// Cloud-local addresses reported by the agent are preferred.
+	err = machine.SetMachineAddresses([]instance.Address{
+		instance.NewScopedAddress("127.0.0.1"),
+		instance.NewScopedAddress("10.0.0.2"),
+	})
+	c.Assert(err, IsNil)
+	address, err = prr.pru0.IngressAddress()
+	c.Assert(err, IsNil)
+	c.Assert(address, Equals, "10.0.0.2")
+
+	// Units in docker containers are reached through their host once
+	// their ports are published, which only happens when their service
+	// is exposed.
+	container, err := s.State.AddMachineWithConstraints(&state.AddMachineParams{
+		ParentId:      machine.Id(),
+		ContainerType: instance.DOCKER,
+		Series:        "series",
+		Jobs:          []state.MachineJob{state.JobHostUnits},
+	})
+	c.Assert(err, IsNil)
+	err = container.SetMachineAddresses([]instance.Address{instance.NewScopedAddress("172.17.0.2")})
+	c.Assert(err, IsNil)
+	err = prr.pu1.AssignToMachine(container)
+	c.Assert(err, IsNil)
+	address, err = prr.pru1.IngressAddress()
+	c.Assert(err, IsNil)
+	c.Assert(address, Equals, "172.17.0.2")
+
+	err = prr.pu1.OpenPort("tcp", 3306)
+	c.Assert(err, IsNil)
+	address, err = prr.pru1.IngressAddress()
+	c.Assert(err, IsNil)
+	c.Assert(address, Equals, "172.17.0.2")
+
+	err = container.SetPublishedPorts([]instance.Port{{"tcp", 3306}})
+	c.Assert(err, IsNil)
+	address, err = prr.pru1.IngressAddress()
+	c.Assert(err, IsNil)
+	c.Assert(address, Equals, "10.0.0.2")
+}
+
+func (s *RelationUnitSuite) TestContainerIngressAddress(c *C) {
+	prr := NewProReqRelation(c, &s.ConnSuite, charm.ScopeContainer)
+	err := prr.pu0.AssignToNewMachine()
+	c.Assert(err, IsNil)
+	id, err := prr.pu0.AssignedMachineId()
+	c.Assert(err, IsNil)
+	machine, err := s.State.Machine(id)
+	c.Assert(err, IsNil)
+	err = machine.SetMachineAddresses([]instance.Address{
+		instance.NewScopedAddress("127.0.0.1"),
+		instance.NewScopedAddress("10.0.0.2"),
+	})
+	c.Assert(err, IsNil)
+
+	// Units in a container-scoped relation share a machine.
+	for _, ru := range []*state.RelationUnit{prr.pru0, prr.rru0} {
+		address, err := ru.IngressAddress()
+		c.Assert(err, IsNil)
+		c.Assert(address, Equals, "127.0.0.1")
+	}
+}
+
 type PeerRelation struct {
 	rel                *state.Relation
 	svc                *state.Service
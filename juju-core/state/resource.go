@@ -0,0 +1,220 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/txn"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/utils"
+)
+
+// resourceDoc records the content uploaded for a resource of a service.
+type resourceDoc struct {
+	// Revision is incremented each time new content is uploaded for
+	// the resource; the first upload has revision 0.
+	Revision int
+	URL      *url.URL
+	Sha256   string
+	Size     int64
+}
+
+// serviceResourcesDoc holds the resources uploaded for a service,
+// keyed by resource name.
+type serviceResourcesDoc struct {
+	Service   string `bson:"_id"`
+	Resources map[string]resourceDoc
+}
+
+// Resource represents the content uploaded for a resource declared by
+// the charm of a service.
+type Resource struct {
+	service string
+	name    string
+	doc     resourceDoc
+}
+
+// ServiceName returns the name of the service the resource belongs to.
+func (r *Resource) ServiceName() string {
+	return r.service
+}
+
+// Name returns the name of the resource, as declared by the charm.
+func (r *Resource) Name() string {
+	return r.name
+}
+
+// Revision returns the revision of the content of the resource.
+func (r *Resource) Revision() int {
+	return r.doc.Revision
+}
+
+// URL returns the url to the content of the resource in the provider
+// storage.
+func (r *Resource) URL() *url.URL {
+	return r.doc.URL
+}
+
+// Sha256 returns the SHA256 digest of the content of the resource.
+func (r *Resource) Sha256() string {
+	return r.doc.Sha256
+}
+
+// Size returns the size of the content of the resource, in bytes.
+func (r *Resource) Size() int64 {
+	return r.doc.Size
+}
+
+// createResourcesOps returns the operations creating the resources
+// document of the named service.
+func createResourcesOps(st *State, serviceName string) []txn.Op {
+	return []txn.Op{{
+		C:      st.resources.Name,
+		Id:     serviceName,
+		Assert: txn.DocMissing,
+		Insert: &serviceResourcesDoc{
+			Service:   serviceName,
+			Resources: map[string]resourceDoc{},
+		},
+	}}
+}
+
+// removeResourcesOps returns the operations removing the resources
+// document of the named service.
+func removeResourcesOps(st *State, serviceName string) []txn.Op {
+	return []txn.Op{{
+		C:      st.resources.Name,
+		Id:     serviceName,
+		Remove: true,
+	}}
+}
+
+// resourcesDoc returns the resources document of the service, or nil
+// if the service has none. Services added before resources were
+// recorded have no resources document until a resource is first set.
+func (s *Service) resourcesDoc() (*serviceResourcesDoc, error) {
+	doc := &serviceResourcesDoc{}
+	err := s.st.resources.FindId(s.doc.Name).One(doc)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get resources of service %q: %v", s.doc.Name, err)
+	}
+	return doc, nil
+}
+
+// Resource returns the content last uploaded for the named resource
+// of the service.
+func (s *Service) Resource(name string) (*Resource, error) {
+	doc, err := s.resourcesDoc()
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, errors.NotFoundf("resource %q of service %q", name, s.doc.Name)
+	}
+	res, ok := doc.Resources[name]
+	if !ok {
+		return nil, errors.NotFoundf("resource %q of service %q", name, s.doc.Name)
+	}
+	return &Resource{service: s.doc.Name, name: name, doc: res}, nil
+}
+
+// Resources returns the content last uploaded for each resource of the
+// service, ordered by name.
+func (s *Service) Resources() ([]*Resource, error) {
+	doc, err := s.resourcesDoc()
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	var names []string
+	for name := range doc.Resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	resources := make([]*Resource, len(names))
+	for i, name := range names {
+		resources[i] = &Resource{service: s.doc.Name, name: name, doc: doc.Resources[name]}
+	}
+	return resources, nil
+}
+
+// SetResource records new content for the named resource of the
+// service, which must be declared by the charm of the service. The
+// content must already be available at contentURL. The revision of
+// the resource is incremented.
+func (s *Service) SetResource(name string, contentURL *url.URL, sha256 string, size int64) (res *Resource, err error) {
+	defer utils.ErrorContextf(&err, "cannot set resource %q of service %q", name, s.doc.Name)
+	if contentURL == nil || sha256 == "" {
+		return nil, fmt.Errorf("url and sha256 must be specified")
+	}
+	ch, _, err := s.Charm()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := ch.Meta().Resources[name]; !ok {
+		return nil, fmt.Errorf("charm %q declares no such resource", ch)
+	}
+	// The name is part of the key of the resource in the document, so
+	// it is checked again for charms added before names were checked.
+	if strings.ContainsAny(name, ".$") {
+		return nil, fmt.Errorf("invalid resource name")
+	}
+	key := "resources." + name
+	for i := 0; i < 3; i++ {
+		doc, err := s.resourcesDoc()
+		if err != nil {
+			return nil, err
+		}
+		newDoc := resourceDoc{URL: contentURL, Sha256: sha256, Size: size}
+		ops := []txn.Op{{
+			C:      s.st.services.Name,
+			Id:     s.doc.Name,
+			Assert: isAliveDoc,
+		}}
+		if doc == nil {
+			ops = append(ops, txn.Op{
+				C:      s.st.resources.Name,
+				Id:     s.doc.Name,
+				Assert: txn.DocMissing,
+				Insert: &serviceResourcesDoc{
+					Service:   s.doc.Name,
+					Resources: map[string]resourceDoc{name: newDoc},
+				},
+			})
+		} else {
+			assert := D{{key, D{{"$exists", false}}}}
+			if old, ok := doc.Resources[name]; ok {
+				newDoc.Revision = old.Revision + 1
+				assert = D{{key + ".revision", old.Revision}}
+			}
+			ops = append(ops, txn.Op{
+				C:      s.st.resources.Name,
+				Id:     s.doc.Name,
+				Assert: assert,
+				Update: D{{"$set", D{{key, newDoc}}}},
+			})
+		}
+		if err := s.st.runTransaction(ops); err != txn.ErrAborted {
+			if err != nil {
+				return nil, err
+			}
+			return &Resource{service: s.doc.Name, name: name, doc: newDoc}, nil
+		}
+		if err := s.Refresh(); err != nil {
+			return nil, err
+		}
+		if s.doc.Life != Alive {
+			return nil, fmt.Errorf("service is not alive")
+		}
+	}
+	return nil, ErrExcessiveContention
+}
@@ -0,0 +1,111 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"net/url"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing/checkers"
+)
+
+type ResourceSuite struct {
+	ConnSuite
+	service *state.Service
+}
+
+var _ = Suite(&ResourceSuite{})
+
+func (s *ResourceSuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.service, err = s.State.AddService("resources", s.AddTestingCharm(c, "resources"))
+	c.Assert(err, IsNil)
+}
+
+func (s *ResourceSuite) TestSetResource(c *C) {
+	resources, err := s.service.Resources()
+	c.Assert(err, IsNil)
+	c.Assert(resources, HasLen, 0)
+	_, err = s.service.Resource("jdk")
+	c.Assert(err, ErrorMatches, `resource "jdk" of service "resources" not found`)
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+
+	jdkURL, err := url.Parse("http://example.com/jdk-0")
+	c.Assert(err, IsNil)
+	res, err := s.service.SetResource("jdk", jdkURL, "jdk-sha256", 1234)
+	c.Assert(err, IsNil)
+	c.Assert(res.ServiceName(), Equals, "resources")
+	c.Assert(res.Name(), Equals, "jdk")
+	c.Assert(res.Revision(), Equals, 0)
+	c.Assert(res.URL(), DeepEquals, jdkURL)
+	c.Assert(res.Sha256(), Equals, "jdk-sha256")
+	c.Assert(res.Size(), Equals, int64(1234))
+
+	// Each upload increments the revision of the resource.
+	jdkURL, err = url.Parse("http://example.com/jdk-1")
+	c.Assert(err, IsNil)
+	res, err = s.service.SetResource("jdk", jdkURL, "jdk-sha256-1", 4321)
+	c.Assert(err, IsNil)
+	c.Assert(res.Revision(), Equals, 1)
+	vendorURL, err := url.Parse("http://example.com/vendor-0")
+	c.Assert(err, IsNil)
+	_, err = s.service.SetResource("vendor", vendorURL, "vendor-sha256", 42)
+	c.Assert(err, IsNil)
+
+	res, err = s.service.Resource("jdk")
+	c.Assert(err, IsNil)
+	c.Assert(res.Revision(), Equals, 1)
+	c.Assert(res.URL(), DeepEquals, jdkURL)
+	c.Assert(res.Sha256(), Equals, "jdk-sha256-1")
+	c.Assert(res.Size(), Equals, int64(4321))
+
+	resources, err = s.service.Resources()
+	c.Assert(err, IsNil)
+	c.Assert(resources, HasLen, 2)
+	c.Assert(resources[0].Name(), Equals, "jdk")
+	c.Assert(resources[1].Name(), Equals, "vendor")
+	c.Assert(resources[1].Revision(), Equals, 0)
+}
+
+func (s *ResourceSuite) TestSetResourceErrors(c *C) {
+	resURL, err := url.Parse("http://example.com/res")
+	c.Assert(err, IsNil)
+	_, err = s.service.SetResource("jdk", nil, "sha256", 0)
+	c.Assert(err, ErrorMatches, `cannot set resource "jdk" of service "resources": url and sha256 must be specified`)
+	_, err = s.service.SetResource("jre", resURL, "sha256", 0)
+	c.Assert(err, ErrorMatches, `cannot set resource "jre" of service "resources": charm "local:series/series-resources-1" declares no such resource`)
+
+	err = s.service.Destroy()
+	c.Assert(err, IsNil)
+	_, err = s.service.SetResource("jdk", resURL, "sha256", 0)
+	c.Assert(err, ErrorMatches, `cannot set resource "jdk" of service "resources": .*`)
+}
+
+func (s *ResourceSuite) TestServiceAddedBeforeResources(c *C) {
+	// Services added before resources were recorded have no resources
+	// document; it is created when a resource is first set.
+	err := s.MgoSuite.Session.DB("juju").C("resources").RemoveId("resources")
+	c.Assert(err, IsNil)
+	resources, err := s.service.Resources()
+	c.Assert(err, IsNil)
+	c.Assert(resources, HasLen, 0)
+	_, err = s.service.Resource("jdk")
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+
+	jdkURL, err := url.Parse("http://example.com/jdk-0")
+	c.Assert(err, IsNil)
+	res, err := s.service.SetResource("jdk", jdkURL, "jdk-sha256", 1234)
+	c.Assert(err, IsNil)
+	c.Assert(res.Revision(), Equals, 0)
+	res, err = s.service.Resource("jdk")
+	c.Assert(err, IsNil)
+	c.Assert(res.URL(), DeepEquals, jdkURL)
+	res, err = s.service.SetResource("jdk", jdkURL, "jdk-sha256", 1234)
+	c.Assert(err, IsNil)
+	c.Assert(res.Revision(), Equals, 1)
+}
@@ -42,7 +42,8 @@ type serviceDoc struct {
 	RelationCount int
 	Exposed       bool
 	MinUnits      int
-	TxnRevno      int64 `bson:"txn-revno"`
+	HookPolicy    *params.HookPolicy `bson:",omitempty"`
+	TxnRevno      int64              `bson:"txn-revno"`
 }
 
 func newService(st *State, doc *serviceDoc) *Service {
@@ -224,6 +225,8 @@ func (s *Service) removeOps(asserts D) []txn.Op {
 		Remove: true,
 	}}
 	ops = append(ops, removeConstraintsOp(s.st, s.globalKey()))
+	ops = append(ops, removeLeadershipOps(s.st, s.doc.Name)...)
+	ops = append(ops, removeResourcesOps(s.st, s.doc.Name)...)
 	return append(ops, annotationRemoveOp(s.st, s.globalKey()))
 }
 
@@ -573,6 +576,7 @@ func (s *Service) addUnitOps(principalName string, asserts D) (string, []txn.Op,
 			Insert: udoc,
 		},
 		createStatusOp(s.st, globalKey, sdoc),
+		createWorkloadStatusOp(s.st, name),
 		{
 			C:      s.st.services.Name,
 			Id:     s.doc.Name,
@@ -600,6 +604,15 @@ func (s *Service) addUnitOps(principalName string, asserts D) (string, []txn.Op,
 		cons := scons.WithFallbacks(econs)
 		ops = append(ops, createConstraintsOp(s.st, globalKey, cons))
 	}
+	ch, _, err := s.Charm()
+	if err != nil {
+		return "", nil, err
+	}
+	storageOps, err := createStorageInstancesOps(s.st, ch, name)
+	if err != nil {
+		return "", nil, err
+	}
+	ops = append(ops, storageOps...)
 	return name, ops, nil
 }
 
@@ -656,8 +669,19 @@ func (s *Service) removeUnitOps(u *Unit, asserts D) ([]txn.Op, error) {
 	},
 		removeConstraintsOp(s.st, u.globalKey()),
 		removeStatusOp(s.st, u.globalKey()),
+		removeWorkloadStatusOp(s.st, u.doc.Name),
 		annotationRemoveOp(s.st, u.globalKey()),
 	)
+	actionOps, err := removeActionsOps(s.st, u.doc.Name)
+	if err != nil {
+		return nil, err
+	}
+	ops = append(ops, actionOps...)
+	storageOps, err := removeStorageInstancesOps(s.st, u.doc.Name)
+	if err != nil {
+		return nil, err
+	}
+	ops = append(ops, storageOps...)
 	if u.doc.CharmURL != nil {
 		decOps, err := settingsDecRefOps(s.st, s.doc.Name, u.doc.CharmURL)
 		if errors.IsNotFoundError(err) {
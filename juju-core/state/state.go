@@ -52,6 +52,9 @@ type State struct {
 	relationScopes   *mgo.Collection
 	services         *mgo.Collection
 	minUnits         *mgo.Collection
+	containerOps     *mgo.Collection
+	actions          *mgo.Collection
+	storageInstances *mgo.Collection
 	settings         *mgo.Collection
 	settingsrefs     *mgo.Collection
 	constraints      *mgo.Collection
@@ -61,6 +64,11 @@ type State struct {
 	cleanups         *mgo.Collection
 	annotations      *mgo.Collection
 	statuses         *mgo.Collection
+	workloadStatuses *mgo.Collection
+	leaderships      *mgo.Collection
+	leaderSettings   *mgo.Collection
+	resources        *mgo.Collection
+	auditLog         *mgo.Collection
 	runner           *txn.Runner
 	transactionHooks chan ([]transactionHook)
 	watcher          *watcher.Watcher
@@ -318,11 +326,23 @@ func (st *State) addMachineContainerOps(params *AddMachineParams, cons constrain
 			containerParams.hostId = mdoc.Id
 			containerParams.newHost = true
 		} else {
-			// If a parent machine is specified, make sure it exists.
-			_, err := st.Machine(containerParams.hostId)
+			// If a parent machine is specified, make sure it exists
+			// and can host the container.
+			host, err := st.Machine(containerParams.hostId)
 			if err != nil {
 				return nil, nil, nil, err
 			}
+			if !host.SupportsContainerType(params.ContainerType) {
+				return nil, nil, nil, fmt.Errorf("machine %s cannot host %s containers", host, params.ContainerType)
+			}
+			ops = append(ops, txn.Op{
+				C:  st.machines.Name,
+				Id: host.doc.Id,
+				Assert: D{{"$or", []D{
+					{{"supportedcontainersknown", D{{"$ne", true}}}},
+					{{"supportedcontainers", params.ContainerType}},
+				}}},
+			})
 		}
 	}
 	return ops, instData, containerParams, nil
@@ -526,6 +546,7 @@ func (st *State) AddCharm(ch charm.Charm, curl *charm.URL, bundleURL *url.URL, b
 		URL:          curl,
 		Meta:         ch.Meta(),
 		Config:       ch.Config(),
+		Actions:      ch.Actions(),
 		BundleURL:    bundleURL,
 		BundleSha256: bundleSha256,
 	}
@@ -630,6 +651,8 @@ func (st *State) AddService(name string, ch *Charm) (service *Service, err error
 		return nil, err
 	}
 	ops = append(ops, peerOps...)
+	ops = append(ops, createLeadershipOps(st, name)...)
+	ops = append(ops, createResourcesOps(st, name)...)
 
 	// Run the transaction; happily, there's never any reason to retry,
 	// because all the possible failed assertions imply that the service
@@ -128,6 +128,16 @@ func (s *StateSuite) TestJobString(c *gc.C) {
 	}
 }
 
+func (s *StateSuite) TestMachineJobFromParams(c *gc.C) {
+	for _, t := range jobStringTests[:3] {
+		job, err := state.MachineJobFromParams(params.MachineJob(t.s))
+		c.Check(err, gc.IsNil)
+		c.Check(job, gc.Equals, t.job)
+	}
+	_, err := state.MachineJobFromParams("JobWhatever")
+	c.Assert(err, gc.ErrorMatches, `invalid machine job "JobWhatever"`)
+}
+
 func (s *StateSuite) TestAddMachineErrors(c *gc.C) {
 	_, err := s.State.AddMachine("")
 	c.Assert(err, gc.ErrorMatches, "cannot add a new machine: no series specified")
@@ -227,6 +237,53 @@ func (s *StateSuite) TestAddContainerToNewMachine(c *gc.C) {
 	s.assertMachineContainers(c, m, nil)
 }
 
+func (s *StateSuite) TestAddDockerContainerToExistingMachine(c *gc.C) {
+	oneJob := []state.MachineJob{state.JobHostUnits}
+	m0, err := s.State.AddMachine("series", oneJob...)
+	c.Assert(err, gc.IsNil)
+
+	params := state.AddMachineParams{
+		ParentId:      "0",
+		ContainerType: instance.DOCKER,
+		Series:        "series",
+		Jobs:          oneJob,
+	}
+	m, err := s.State.AddMachineWithConstraints(&params)
+	c.Assert(err, gc.IsNil)
+	c.Assert(m.Id(), gc.Equals, "0/docker/0")
+	c.Assert(m.ContainerType(), gc.Equals, instance.DOCKER)
+	s.assertMachineContainers(c, m0, []string{"0/docker/0"})
+
+	// Docker and lxc containers are numbered independently.
+	params.ContainerType = instance.LXC
+	m, err = s.State.AddMachineWithConstraints(&params)
+	c.Assert(err, gc.IsNil)
+	c.Assert(m.Id(), gc.Equals, "0/lxc/0")
+	s.assertMachineContainers(c, m0, []string{"0/docker/0", "0/lxc/0"})
+}
+
+func (s *StateSuite) TestAddContainerToUnsupportedHost(c *gc.C) {
+	host, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	err = host.SetSupportedContainers([]instance.ContainerType{instance.LXC})
+	c.Assert(err, gc.IsNil)
+
+	params := state.AddMachineParams{
+		ParentId:      host.Id(),
+		ContainerType: instance.DOCKER,
+		Series:        "series",
+		Jobs:          []state.MachineJob{state.JobHostUnits},
+	}
+	_, err = s.State.AddMachineWithConstraints(&params)
+	c.Assert(err, gc.ErrorMatches, "cannot add a new container: machine 0 cannot host docker containers")
+	s.assertMachineContainers(c, host, nil)
+
+	params.ContainerType = instance.LXC
+	m, err := s.State.AddMachineWithConstraints(&params)
+	c.Assert(err, gc.IsNil)
+	c.Assert(m.Id(), gc.Equals, "0/lxc/0")
+}
+
 func (s *StateSuite) TestAddContainerToExistingMachine(c *gc.C) {
 	oneJob := []state.MachineJob{state.JobHostUnits}
 	m0, err := s.State.AddMachine("series", oneJob...)
@@ -1459,7 +1516,7 @@ func (s *StateSuite) TestFindEntity(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 	_, err = svc.AddUnit()
 	c.Assert(err, gc.IsNil)
-	_, err = s.State.AddUser("arble", "pass")
+	_, err = s.State.AddUser("arble", "pass", state.WriteAccess)
 	c.Assert(err, gc.IsNil)
 
 	for i, test := range findEntityTests {
@@ -1521,7 +1578,7 @@ func (s *StateSuite) TestParseTag(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 
 	// Parse a user entity name.
-	user, err := s.State.AddUser("arble", "pass")
+	user, err := s.State.AddUser("arble", "pass", state.WriteAccess)
 	c.Assert(err, gc.IsNil)
 	coll, id, err = state.ParseTag(s.State, user.Tag())
 	c.Assert(coll, gc.Equals, "users")
@@ -1749,4 +1806,5 @@ func (s *StateSuite) TestContainerTypeFromId(c *gc.C) {
 	c.Assert(state.ContainerTypeFromId("0"), gc.Equals, instance.ContainerType(""))
 	c.Assert(state.ContainerTypeFromId("0/lxc/1"), gc.Equals, instance.LXC)
 	c.Assert(state.ContainerTypeFromId("0/lxc/1/kvm/0"), gc.Equals, instance.KVM)
+	c.Assert(state.ContainerTypeFromId("0/docker/2"), gc.Equals, instance.DOCKER)
 }
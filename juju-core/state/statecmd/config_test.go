@@ -107,6 +107,45 @@ var getTests = []struct {
 			},
 		},
 	},
+}, {
+	about: "secret values are redacted",
+	charm: "typed-config",
+	config: map[string]string{
+		"password": "sekrit",
+		"workers":  "8",
+	},
+	expect: params.ServiceGetResults{
+		Config: map[string]interface{}{
+			"flavour": map[string]interface{}{
+				"description": "The flavour of the service.",
+				"type":        "enum",
+				"value":       "vanilla",
+				"default":     true,
+			},
+			"mirrors": map[string]interface{}{
+				"description": "The mirrors to fetch packages from.",
+				"type":        "list",
+				"value":       nil,
+				"default":     true,
+			},
+			"labels": map[string]interface{}{
+				"description": "Labels attached to the service.",
+				"type":        "map",
+				"value":       nil,
+				"default":     true,
+			},
+			"workers": map[string]interface{}{
+				"description": "The number of worker processes.",
+				"type":        "int",
+				"value":       int64(8),
+			},
+			"password": map[string]interface{}{
+				"description": "The password of the admin account.",
+				"type":        "secret",
+				"value":       "<redacted>",
+			},
+		},
+	},
 }, {
 	about: "subordinate service",
 	charm: "logging",
@@ -0,0 +1,21 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Code shared by the CLI and API for the ContainerOperation function.
+
+package statecmd
+
+import (
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// ContainerOperation asks the agent of the machine hosting the container
+// to freeze, thaw, snapshot or restore it.
+func ContainerOperation(st *state.State, args params.ContainerOperation) error {
+	machine, err := st.Machine(args.MachineId)
+	if err != nil {
+		return err
+	}
+	return machine.RequestContainerOp(state.ContainerOp(args.Operation), args.Snapshot)
+}
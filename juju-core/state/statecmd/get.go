@@ -46,6 +46,10 @@ func ServiceGet(st *state.State, p params.ServiceGet) (params.ServiceGetResults,
 	}, nil
 }
 
+// redacted replaces the values of secret options in the results of
+// ServiceGet.
+const redacted = "<redacted>"
+
 func describe(settings charm.Settings, config *charm.Config) map[string]interface{} {
 	results := make(map[string]interface{})
 	for name, option := range config.Options {
@@ -59,6 +63,9 @@ func describe(settings charm.Settings, config *charm.Config) map[string]interfac
 			info["value"] = option.Default
 			info["default"] = true
 		}
+		if option.Type == "secret" && info["value"] != nil {
+			info["value"] = redacted
+		}
 		results[name] = info
 	}
 	return results
@@ -0,0 +1,332 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/txn"
+
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/utils"
+)
+
+// storageInstanceDoc records a store required by the charm of a unit,
+// and the volume provisioned for it.
+type storageInstanceDoc struct {
+	Id   string `bson:"_id"`
+	Name string
+	Kind charm.StorageType
+	Unit string
+	// Machine is the machine the unit is assigned to.
+	Machine string
+	// Life is Dead once the unit is removed, until the volume of the
+	// store is destroyed.
+	Life Life
+	// Location is the path at which a filesystem store is mounted.
+	Location string
+	// Size is the minimum size of the store, in MiB.
+	Size        uint64
+	Provisioned bool
+	VolumeId    string
+	Device      string
+	Attached    bool
+}
+
+// StorageInstance represents a store of a unit, as declared by the
+// charm of its service.
+type StorageInstance struct {
+	st  *State
+	doc storageInstanceDoc
+}
+
+func newStorageInstance(st *State, doc *storageInstanceDoc) *StorageInstance {
+	return &StorageInstance{st: st, doc: *doc}
+}
+
+// Id returns the id of the storage instance, which is unique in the
+// environment; for example, "data/0".
+func (s *StorageInstance) Id() string {
+	return s.doc.Id
+}
+
+// Name returns the name of the store, as declared by the charm.
+func (s *StorageInstance) Name() string {
+	return s.doc.Name
+}
+
+// Kind returns how the store is presented to the charm.
+func (s *StorageInstance) Kind() charm.StorageType {
+	return s.doc.Kind
+}
+
+// UnitName returns the name of the unit the store belongs to.
+func (s *StorageInstance) UnitName() string {
+	return s.doc.Unit
+}
+
+// MachineId returns the id of the machine the unit of the store is
+// assigned to, if any.
+func (s *StorageInstance) MachineId() string {
+	return s.doc.Machine
+}
+
+// Life returns whether the storage instance is Alive, or Dead once its
+// unit is removed.
+func (s *StorageInstance) Life() Life {
+	return s.doc.Life
+}
+
+// Location returns the path at which a filesystem store is mounted.
+func (s *StorageInstance) Location() string {
+	return s.doc.Location
+}
+
+// Size returns the minimum size of the store, in MiB.
+func (s *StorageInstance) Size() uint64 {
+	return s.doc.Size
+}
+
+// Provisioned returns whether a volume has been provisioned for the
+// store.
+func (s *StorageInstance) Provisioned() bool {
+	return s.doc.Provisioned
+}
+
+// VolumeId returns the provider id of the volume provisioned for the
+// store.
+func (s *StorageInstance) VolumeId() string {
+	return s.doc.VolumeId
+}
+
+// Device returns the path of the block device of the volume provisioned
+// for the store.
+func (s *StorageInstance) Device() string {
+	return s.doc.Device
+}
+
+// Attached returns whether the charm of the unit has been told that the
+// store is available.
+func (s *StorageInstance) Attached() bool {
+	return s.doc.Attached
+}
+
+// Refresh refreshes the contents of the storage instance from the
+// underlying state.
+func (s *StorageInstance) Refresh() error {
+	err := s.st.storageInstances.FindId(s.doc.Id).One(&s.doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("storage instance %q", s.doc.Id)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot refresh storage instance %q: %v", s.doc.Id, err)
+	}
+	return nil
+}
+
+// SetProvisioned records that a volume has been provisioned for the
+// store, and the device through which it is reached.
+func (s *StorageInstance) SetProvisioned(volumeId, device string) (err error) {
+	defer utils.ErrorContextf(&err, "cannot set storage instance %q as provisioned", s.doc.Id)
+	if volumeId == "" {
+		return fmt.Errorf("volume id is empty")
+	}
+	ops := []txn.Op{{
+		C:      s.st.storageInstances.Name,
+		Id:     s.doc.Id,
+		Assert: D{{"provisioned", false}},
+		Update: D{{"$set", D{
+			{"provisioned", true},
+			{"volumeid", volumeId},
+			{"device", device},
+		}}},
+	}}
+	if err := s.st.runTransaction(ops); err != nil {
+		return onAbort(err, fmt.Errorf("already provisioned"))
+	}
+	s.doc.Provisioned = true
+	s.doc.VolumeId = volumeId
+	s.doc.Device = device
+	return nil
+}
+
+// SetDevice records the device through which the volume of the store
+// is reached, once the volume is attached again.
+func (s *StorageInstance) SetDevice(device string) (err error) {
+	defer utils.ErrorContextf(&err, "cannot set device of storage instance %q", s.doc.Id)
+	ops := []txn.Op{{
+		C:      s.st.storageInstances.Name,
+		Id:     s.doc.Id,
+		Assert: D{{"provisioned", true}},
+		Update: D{{"$set", D{{"device", device}}}},
+	}}
+	if err := s.st.runTransaction(ops); err != nil {
+		return onAbort(err, fmt.Errorf("not provisioned"))
+	}
+	s.doc.Device = device
+	return nil
+}
+
+// Remove removes the dead storage instance, once its volume has been
+// destroyed.
+func (s *StorageInstance) Remove() (err error) {
+	defer utils.ErrorContextf(&err, "cannot remove storage instance %q", s.doc.Id)
+	if s.doc.Life != Dead {
+		return fmt.Errorf("storage instance is not dead")
+	}
+	ops := []txn.Op{{
+		C:      s.st.storageInstances.Name,
+		Id:     s.doc.Id,
+		Assert: isDeadDoc,
+		Remove: true,
+	}}
+	if err := s.st.runTransaction(ops); err != nil && err != txn.ErrAborted {
+		return err
+	}
+	return nil
+}
+
+// SetAttached records whether the charm of the unit has been told that
+// the store is available.
+func (s *StorageInstance) SetAttached(attached bool) (err error) {
+	defer utils.ErrorContextf(&err, "cannot set storage instance %q attachment", s.doc.Id)
+	ops := []txn.Op{{
+		C:      s.st.storageInstances.Name,
+		Id:     s.doc.Id,
+		Assert: D{{"provisioned", true}},
+		Update: D{{"$set", D{{"attached", attached}}}},
+	}}
+	if err := s.st.runTransaction(ops); err != nil {
+		return onAbort(err, fmt.Errorf("not provisioned"))
+	}
+	s.doc.Attached = attached
+	return nil
+}
+
+// StorageInstance returns the storage instance with the given id.
+func (st *State) StorageInstance(id string) (*StorageInstance, error) {
+	doc := &storageInstanceDoc{}
+	err := st.storageInstances.FindId(id).One(doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("storage instance %q", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get storage instance %q: %v", id, err)
+	}
+	return newStorageInstance(st, doc), nil
+}
+
+// StorageInstances returns the storage instances of the unit, ordered
+// by id.
+func (u *Unit) StorageInstances() ([]*StorageInstance, error) {
+	stores, err := findStorageInstances(u.st, D{{"unit", u.doc.Name}})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get storage instances of unit %q: %v", u, err)
+	}
+	return stores, nil
+}
+
+// StorageInstances returns all the storage instances of the
+// environment, ordered by id.
+func (st *State) StorageInstances() ([]*StorageInstance, error) {
+	stores, err := findStorageInstances(st, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get storage instances: %v", err)
+	}
+	return stores, nil
+}
+
+// findStorageInstances returns the storage instances selected by sel,
+// ordered by id.
+func findStorageInstances(st *State, sel D) ([]*StorageInstance, error) {
+	var docs []storageInstanceDoc
+	if err := st.storageInstances.Find(sel).Sort("_id").All(&docs); err != nil {
+		return nil, err
+	}
+	stores := make([]*StorageInstance, len(docs))
+	for i := range docs {
+		stores[i] = newStorageInstance(st, &docs[i])
+	}
+	return stores, nil
+}
+
+// createStorageInstancesOps returns the operations creating a storage
+// instance for each store declared by the charm, for the named unit.
+func createStorageInstancesOps(st *State, ch *Charm, unitName string) ([]txn.Op, error) {
+	var ops []txn.Op
+	for name, store := range ch.Meta().Storage {
+		seq, err := st.sequence("storage-" + name)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, txn.Op{
+			C:      st.storageInstances.Name,
+			Id:     fmt.Sprintf("%s/%d", name, seq),
+			Assert: txn.DocMissing,
+			Insert: &storageInstanceDoc{
+				Id:       fmt.Sprintf("%s/%d", name, seq),
+				Name:     name,
+				Kind:     store.Type,
+				Unit:     unitName,
+				Location: store.Location,
+				Size:     store.MinimumSize,
+			},
+		})
+	}
+	return ops, nil
+}
+
+// assignStorageInstancesOps returns the operations recording the
+// machine the named unit is assigned to on its storage instances, so
+// that the storage provisioner serving the machine provisions them.
+func assignStorageInstancesOps(st *State, unitName, machineId string) ([]txn.Op, error) {
+	var docs []storageInstanceDoc
+	err := st.storageInstances.Find(D{{"unit", unitName}}).Select(D{{"_id", 1}}).All(&docs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get storage instances of unit %q: %v", unitName, err)
+	}
+	ops := make([]txn.Op, len(docs))
+	for i, doc := range docs {
+		ops[i] = txn.Op{
+			C:      st.storageInstances.Name,
+			Id:     doc.Id,
+			Assert: txn.DocExists,
+			Update: D{{"$set", D{{"machine", machineId}}}},
+		}
+	}
+	return ops, nil
+}
+
+// removeStorageInstancesOps returns the operations removing the storage
+// instances of the named unit. The storage instances provisioned are
+// only marked as Dead, so that the storage provisioner destroys their
+// volumes before removing them.
+func removeStorageInstancesOps(st *State, unitName string) ([]txn.Op, error) {
+	var docs []storageInstanceDoc
+	err := st.storageInstances.Find(D{{"unit", unitName}}).Select(D{{"_id", 1}, {"provisioned", 1}}).All(&docs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get storage instances of unit %q: %v", unitName, err)
+	}
+	ops := make([]txn.Op, len(docs))
+	for i, doc := range docs {
+		if doc.Provisioned {
+			ops[i] = txn.Op{
+				C:      st.storageInstances.Name,
+				Id:     doc.Id,
+				Assert: D{{"provisioned", true}},
+				Update: D{{"$set", D{{"life", Dead}}}},
+			}
+		} else {
+			ops[i] = txn.Op{
+				C:      st.storageInstances.Name,
+				Id:     doc.Id,
+				Assert: D{{"provisioned", false}},
+				Remove: true,
+			}
+		}
+	}
+	return ops, nil
+}
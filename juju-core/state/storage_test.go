@@ -0,0 +1,242 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/state"
+	statetesting "launchpad.net/juju-core/state/testing"
+	"launchpad.net/juju-core/testing/checkers"
+)
+
+type StorageSuite struct {
+	ConnSuite
+	service *state.Service
+	unit    *state.Unit
+}
+
+var _ = Suite(&StorageSuite{})
+
+func (s *StorageSuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.service, err = s.State.AddService("storage", s.AddTestingCharm(c, "storage"))
+	c.Assert(err, IsNil)
+	s.unit, err = s.service.AddUnit()
+	c.Assert(err, IsNil)
+}
+
+func (s *StorageSuite) TestAddUnitCreatesStorageInstances(c *C) {
+	stores, err := s.unit.StorageInstances()
+	c.Assert(err, IsNil)
+	c.Assert(stores, HasLen, 2)
+
+	data := stores[0]
+	c.Assert(data.Id(), Equals, "data/0")
+	c.Assert(data.Name(), Equals, "data")
+	c.Assert(data.Kind(), Equals, charm.StorageFilesystem)
+	c.Assert(data.UnitName(), Equals, "storage/0")
+	c.Assert(data.Location(), Equals, "/srv/data")
+	c.Assert(data.Size(), Equals, uint64(1024))
+	c.Assert(data.Provisioned(), Equals, false)
+	c.Assert(data.Attached(), Equals, false)
+
+	disks := stores[1]
+	c.Assert(disks.Id(), Equals, "disks/0")
+	c.Assert(disks.Kind(), Equals, charm.StorageBlock)
+	c.Assert(disks.Location(), Equals, "")
+	c.Assert(disks.Size(), Equals, uint64(512))
+
+	unit, err := s.service.AddUnit()
+	c.Assert(err, IsNil)
+	stores, err = unit.StorageInstances()
+	c.Assert(err, IsNil)
+	c.Assert(stores, HasLen, 2)
+	c.Assert(stores[0].Id(), Equals, "data/1")
+	c.Assert(stores[1].Id(), Equals, "disks/1")
+
+	// Units of charms declaring no storage have none.
+	dummy, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	unit, err = dummy.AddUnit()
+	c.Assert(err, IsNil)
+	stores, err = unit.StorageInstances()
+	c.Assert(err, IsNil)
+	c.Assert(stores, HasLen, 0)
+}
+
+func (s *StorageSuite) TestProvisionAndAttach(c *C) {
+	store, err := s.State.StorageInstance("data/0")
+	c.Assert(err, IsNil)
+
+	err = store.SetAttached(true)
+	c.Assert(err, ErrorMatches, `cannot set storage instance "data/0" attachment: not provisioned`)
+
+	err = store.SetProvisioned("vol-0", "/dev/sdb")
+	c.Assert(err, IsNil)
+	c.Assert(store.Provisioned(), Equals, true)
+	c.Assert(store.VolumeId(), Equals, "vol-0")
+	c.Assert(store.Device(), Equals, "/dev/sdb")
+	err = store.SetProvisioned("vol-1", "/dev/sdc")
+	c.Assert(err, ErrorMatches, `cannot set storage instance "data/0" as provisioned: already provisioned`)
+
+	err = store.SetAttached(true)
+	c.Assert(err, IsNil)
+
+	store, err = s.State.StorageInstance("data/0")
+	c.Assert(err, IsNil)
+	c.Assert(store.VolumeId(), Equals, "vol-0")
+	c.Assert(store.Device(), Equals, "/dev/sdb")
+	c.Assert(store.Attached(), Equals, true)
+
+	err = store.SetAttached(false)
+	c.Assert(err, IsNil)
+	err = store.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(store.Attached(), Equals, false)
+}
+
+func (s *StorageSuite) TestAssignUnitSetsMachine(c *C) {
+	store, err := s.State.StorageInstance("data/0")
+	c.Assert(err, IsNil)
+	c.Assert(store.MachineId(), Equals, "")
+
+	machine, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	err = s.unit.AssignToMachine(machine)
+	c.Assert(err, IsNil)
+	err = store.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(store.MachineId(), Equals, machine.Id())
+
+	unit, err := s.service.AddUnit()
+	c.Assert(err, IsNil)
+	err = unit.AssignToNewMachine()
+	c.Assert(err, IsNil)
+	machineId, err := unit.AssignedMachineId()
+	c.Assert(err, IsNil)
+	store, err = s.State.StorageInstance("disks/1")
+	c.Assert(err, IsNil)
+	c.Assert(store.MachineId(), Equals, machineId)
+}
+
+func (s *StorageSuite) TestSetDevice(c *C) {
+	store, err := s.State.StorageInstance("data/0")
+	c.Assert(err, IsNil)
+	err = store.SetDevice("/dev/loop1")
+	c.Assert(err, ErrorMatches, `cannot set device of storage instance "data/0": not provisioned`)
+
+	err = store.SetProvisioned("vol-0", "/dev/loop0")
+	c.Assert(err, IsNil)
+	err = store.SetDevice("/dev/loop1")
+	c.Assert(err, IsNil)
+	store, err = s.State.StorageInstance("data/0")
+	c.Assert(err, IsNil)
+	c.Assert(store.Device(), Equals, "/dev/loop1")
+}
+
+func (s *StorageSuite) TestRemoveUnitRemovesStorageInstances(c *C) {
+	// Provisioned stores are left Dead, for their volumes to be
+	// destroyed; the others are removed with the unit.
+	data, err := s.State.StorageInstance("data/0")
+	c.Assert(err, IsNil)
+	err = data.SetProvisioned("vol-0", "/dev/sdb")
+	c.Assert(err, IsNil)
+	err = data.Remove()
+	c.Assert(err, ErrorMatches, `cannot remove storage instance "data/0": storage instance is not dead`)
+
+	err = s.unit.EnsureDead()
+	c.Assert(err, IsNil)
+	err = s.unit.Remove()
+	c.Assert(err, IsNil)
+	_, err = s.State.StorageInstance("disks/0")
+	c.Assert(err, ErrorMatches, `storage instance "disks/0" not found`)
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+
+	err = data.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(data.Life(), Equals, state.Dead)
+	err = data.Remove()
+	c.Assert(err, IsNil)
+	_, err = s.State.StorageInstance("data/0")
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+	err = data.Remove()
+	c.Assert(err, IsNil)
+}
+
+func (s *StorageSuite) TestWatchStorageInstances(c *C) {
+	w := s.unit.WatchStorageInstances()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	// Provisioning a store is reported.
+	store, err := s.State.StorageInstance("disks/0")
+	c.Assert(err, IsNil)
+	err = store.SetProvisioned("vol-0", "/dev/sdb")
+	c.Assert(err, IsNil)
+	wc.AssertOneChange()
+
+	// Changes to the stores of other units are ignored.
+	_, err = s.service.AddUnit()
+	c.Assert(err, IsNil)
+	store, err = s.State.StorageInstance("disks/1")
+	c.Assert(err, IsNil)
+	err = store.SetProvisioned("vol-1", "/dev/sdc")
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
+func (s *StorageSuite) TestWatchStorageProvisioning(c *C) {
+	machine, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	err = s.unit.AssignToMachine(machine)
+	c.Assert(err, IsNil)
+
+	w := s.State.WatchStorageProvisioning()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange("data/0", "disks/0")
+	wc.AssertNoChange()
+
+	// Stores of unassigned units are ignored, until they are assigned.
+	unit, err := s.service.AddUnit()
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+	err = unit.AssignToMachine(machine)
+	c.Assert(err, IsNil)
+	wc.AssertChange("data/1", "disks/1")
+	wc.AssertNoChange()
+
+	// Provisioning a store is not reported.
+	data, err := s.State.StorageInstance("data/0")
+	c.Assert(err, IsNil)
+	err = data.SetProvisioned("vol-0", "/dev/sdb")
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	// The stores waiting for a machine are reported again once it is
+	// provisioned.
+	err = machine.SetProvisioned("i-0", "fake_nonce", nil)
+	c.Assert(err, IsNil)
+	wc.AssertChange("disks/0", "data/1", "disks/1")
+	wc.AssertNoChange()
+
+	// Provisioned stores are reported once their unit is removed.
+	err = s.unit.EnsureDead()
+	c.Assert(err, IsNil)
+	err = s.unit.Remove()
+	c.Assert(err, IsNil)
+	wc.AssertChange("data/0")
+	wc.AssertNoChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
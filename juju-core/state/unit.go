@@ -475,6 +475,20 @@ func (u *Unit) PrivateAddress() (string, bool) {
 	return u.doc.PrivateAddress, u.doc.PrivateAddress != ""
 }
 
+// NetworkAddresses returns the addresses of the machine the unit is
+// assigned to.
+func (u *Unit) NetworkAddresses() ([]instance.Address, error) {
+	id, err := u.AssignedMachineId()
+	if err != nil {
+		return nil, err
+	}
+	m, err := u.st.Machine(id)
+	if err != nil {
+		return nil, err
+	}
+	return m.NetworkAddresses(), nil
+}
+
 // Refresh refreshes the contents of the Unit from the underlying
 // state. It an error that satisfies IsNotFound if the unit has been removed.
 func (u *Unit) Refresh() error {
@@ -785,6 +799,11 @@ func (u *Unit) assignToMachine(m *Machine, unused bool) (err error) {
 		Assert: massert,
 		Update: D{{"$addToSet", D{{"principals", u.doc.Name}}}, {"$set", D{{"clean", false}}}},
 	}}
+	storageOps, err := assignStorageInstancesOps(u.st, u.doc.Name, m.doc.Id)
+	if err != nil {
+		return err
+	}
+	ops = append(ops, storageOps...)
 	err = u.st.runTransaction(ops)
 	if err == nil {
 		u.doc.MachineId = m.doc.Id
@@ -864,6 +883,11 @@ func (u *Unit) assignToNewMachine(params *AddMachineParams, cons constraints.Val
 		Assert: asserts,
 		Update: D{{"$set", D{{"machineid", mdoc.Id}}}},
 	})
+	storageOps, err := assignStorageInstancesOps(u.st, u.doc.Name, mdoc.Id)
+	if err != nil {
+		return err
+	}
+	ops = append(ops, storageOps...)
 	err = u.st.runTransaction(ops)
 	if err == nil {
 		u.doc.MachineId = mdoc.Id
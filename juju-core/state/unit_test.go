@@ -208,6 +208,22 @@ func (s *UnitSuite) TestGetSetPrivateAddress(c *C) {
 	c.Assert(err, ErrorMatches, `cannot set private address of unit "wordpress/0": unit not found`)
 }
 
+func (s *UnitSuite) TestNetworkAddresses(c *C) {
+	_, err := s.unit.NetworkAddresses()
+	c.Assert(err, ErrorMatches, `unit "wordpress/0" is not assigned to a machine`)
+
+	machine, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	err = s.unit.AssignToMachine(machine)
+	c.Assert(err, IsNil)
+	addresses := []instance.Address{instance.NewScopedAddress("10.0.0.2")}
+	err = machine.SetMachineAddresses(addresses)
+	c.Assert(err, IsNil)
+	got, err := s.unit.NetworkAddresses()
+	c.Assert(err, IsNil)
+	c.Assert(got, DeepEquals, addresses)
+}
+
 func (s *UnitSuite) TestRefresh(c *C) {
 	unit1, err := s.State.Unit(s.unit.Name())
 	c.Assert(err, IsNil)
@@ -6,6 +6,8 @@ package state
 import (
 	"fmt"
 	"regexp"
+	"sort"
+	"time"
 
 	"labix.org/v2/mgo"
 	"labix.org/v2/mgo/txn"
@@ -16,16 +18,65 @@ import (
 
 var validUser = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9]*$")
 
-// AddUser adds a user to the state.
-func (st *State) AddUser(name, password string) (*User, error) {
+// UserAccess describes what a user may do in the environment.
+type UserAccess string
+
+const (
+	// ReadAccess allows a user to look at the environment but not to
+	// change it.
+	ReadAccess UserAccess = "read"
+	// WriteAccess allows a user to deploy and manage services, units,
+	// relations and machines in the environment.
+	WriteAccess UserAccess = "write"
+	// AdminAccess allows a user to do anything, including changing the
+	// environment configuration, destroying the environment and
+	// managing its users.
+	AdminAccess UserAccess = "admin"
+)
+
+var accessRank = map[UserAccess]int{
+	ReadAccess:  1,
+	WriteAccess: 2,
+	AdminAccess: 3,
+}
+
+// Valid returns whether the access is a known access level.
+func (a UserAccess) Valid() bool {
+	return accessRank[a] > 0
+}
+
+// Includes returns whether the access grants at least as much as
+// other does.
+func (a UserAccess) Includes(other UserAccess) bool {
+	return a.Valid() && accessRank[a] >= accessRank[other]
+}
+
+// AdminUser is the name of the user created when the environment is
+// bootstrapped. It cannot be removed.
+const AdminUser = "admin"
+
+// nowToTheSecond returns the current time rounded to the second, so
+// that it is unaffected by the loss of precision when stored.
+func nowToTheSecond() time.Time {
+	return time.Now().Round(time.Second).UTC()
+}
+
+// AddUser adds a user with the given access to the environment to the
+// state.
+func (st *State) AddUser(name, password string, access UserAccess) (*User, error) {
 	if !validUser.MatchString(name) {
 		return nil, fmt.Errorf("invalid user name %q", name)
 	}
+	if !access.Valid() {
+		return nil, fmt.Errorf("invalid access %q", access)
+	}
 	u := &User{
 		st: st,
 		doc: userDoc{
 			Name:         name,
 			PasswordHash: utils.PasswordHash(password),
+			Access:       access,
+			DateCreated:  nowToTheSecond(),
 		},
 	}
 	ops := []txn.Op{{
@@ -63,6 +114,26 @@ func (st *State) User(name string) (*User, error) {
 	return u, nil
 }
 
+// AllUsers returns all the users of the environment, ordered by name.
+func (st *State) AllUsers() ([]*User, error) {
+	var docs []userDoc
+	if err := st.users.Find(nil).All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get all users: %v", err)
+	}
+	users := make([]*User, len(docs))
+	for i, doc := range docs {
+		users[i] = &User{st: st, doc: doc}
+	}
+	sort.Sort(usersByName(users))
+	return users, nil
+}
+
+type usersByName []*User
+
+func (u usersByName) Len() int           { return len(u) }
+func (u usersByName) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
+func (u usersByName) Less(i, j int) bool { return u[i].Name() < u[j].Name() }
+
 // User represents a juju client user.
 type User struct {
 	st  *State
@@ -72,6 +143,9 @@ type User struct {
 type userDoc struct {
 	Name         string `bson:"_id_"`
 	PasswordHash string
+	Access       UserAccess
+	DateCreated  time.Time
+	LastLogin    time.Time
 }
 
 // Name returns the user name,
@@ -85,6 +159,78 @@ func (u *User) Tag() string {
 	return "user-" + u.doc.Name
 }
 
+// Access returns the access the user has to the environment. Users
+// created before access levels were introduced have admin access.
+func (u *User) Access() UserAccess {
+	if u.doc.Access == "" {
+		return AdminAccess
+	}
+	return u.doc.Access
+}
+
+// SetAccess changes the access the user has to the environment.
+func (u *User) SetAccess(access UserAccess) error {
+	if !access.Valid() {
+		return fmt.Errorf("invalid access %q", access)
+	}
+	ops := []txn.Op{{
+		C:      u.st.users.Name,
+		Id:     u.Name(),
+		Assert: txn.DocExists,
+		Update: D{{"$set", D{{"access", access}}}},
+	}}
+	if err := u.st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot set access of user %q: %v", u.Name(), onAbort(err, errors.NotFoundf("user")))
+	}
+	u.doc.Access = access
+	return nil
+}
+
+// DateCreated returns when the user was added. It is the zero time
+// for users created before creation times were recorded.
+func (u *User) DateCreated() time.Time {
+	return u.doc.DateCreated
+}
+
+// LastLogin returns when the user last logged in to the API server, or
+// the zero time if they never have.
+func (u *User) LastLogin() time.Time {
+	return u.doc.LastLogin
+}
+
+// UpdateLastLogin records that the user has just logged in.
+func (u *User) UpdateLastLogin() error {
+	now := nowToTheSecond()
+	ops := []txn.Op{{
+		C:      u.st.users.Name,
+		Id:     u.Name(),
+		Assert: txn.DocExists,
+		Update: D{{"$set", D{{"lastlogin", now}}}},
+	}}
+	if err := u.st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot update last login of user %q: %v", u.Name(), onAbort(err, errors.NotFoundf("user")))
+	}
+	u.doc.LastLogin = now
+	return nil
+}
+
+// Remove removes the user from the state. The admin user cannot be
+// removed. Removing a user that no longer exists is not an error.
+func (u *User) Remove() error {
+	if u.Name() == AdminUser {
+		return fmt.Errorf("cannot remove user %q", u.Name())
+	}
+	ops := []txn.Op{{
+		C:      u.st.users.Name,
+		Id:     u.Name(),
+		Remove: true,
+	}}
+	if err := u.st.runTransaction(ops); err != nil {
+		return fmt.Errorf("cannot remove user %q: %v", u.Name(), err)
+	}
+	return nil
+}
+
 // SetPassword sets the password associated with the user.
 func (u *User) SetPassword(password string) error {
 	return u.SetPasswordHash(utils.PasswordHash(password))
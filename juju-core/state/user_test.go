@@ -4,6 +4,8 @@
 package state_test
 
 import (
+	"time"
+
 	. "launchpad.net/gocheck"
 
 	"launchpad.net/juju-core/state"
@@ -22,19 +24,20 @@ func (s *UserSuite) TestAddUserInvalidNames(c *C) {
 		"",
 		"0foo",
 	} {
-		u, err := s.State.AddUser(name, "password")
+		u, err := s.State.AddUser(name, "password", state.WriteAccess)
 		c.Assert(err, ErrorMatches, `invalid user name "`+name+`"`)
 		c.Assert(u, IsNil)
 	}
 }
 
 func (s *UserSuite) TestAddUser(c *C) {
-	u, err := s.State.AddUser("a", "b")
+	u, err := s.State.AddUser("a", "b", state.WriteAccess)
 	c.Check(u, NotNil)
 	c.Assert(err, IsNil)
 
 	c.Assert(u.Name(), Equals, "a")
 	c.Assert(u.PasswordValid("b"), Equals, true)
+	c.Assert(u.Access(), Equals, state.WriteAccess)
 
 	u1, err := s.State.User("a")
 	c.Check(u1, NotNil)
@@ -42,10 +45,107 @@ func (s *UserSuite) TestAddUser(c *C) {
 
 	c.Assert(u1.Name(), Equals, "a")
 	c.Assert(u1.PasswordValid("b"), Equals, true)
+	c.Assert(u1.Access(), Equals, state.WriteAccess)
+	c.Assert(u1.DateCreated().Equal(u.DateCreated()), Equals, true)
+	c.Assert(time.Since(u1.DateCreated()) < time.Minute, Equals, true)
+	c.Assert(u1.LastLogin().IsZero(), Equals, true)
+
+	_, err = s.State.AddUser("a", "c", state.ReadAccess)
+	c.Assert(err, ErrorMatches, "user already exists")
+}
+
+func (s *UserSuite) TestAddUserInvalidAccess(c *C) {
+	u, err := s.State.AddUser("someuser", "", "superuser")
+	c.Assert(err, ErrorMatches, `invalid access "superuser"`)
+	c.Assert(u, IsNil)
+}
+
+func (s *UserSuite) TestUserAccessIncludes(c *C) {
+	for i, t := range []struct {
+		access, other state.UserAccess
+		includes      bool
+	}{
+		{state.ReadAccess, state.ReadAccess, true},
+		{state.ReadAccess, state.WriteAccess, false},
+		{state.WriteAccess, state.ReadAccess, true},
+		{state.WriteAccess, state.AdminAccess, false},
+		{state.AdminAccess, state.WriteAccess, true},
+		{state.AdminAccess, state.AdminAccess, true},
+		{"", state.ReadAccess, false},
+	} {
+		c.Logf("test %d: %q includes %q", i, t.access, t.other)
+		c.Check(t.access.Includes(t.other), Equals, t.includes)
+	}
+}
+
+func (s *UserSuite) TestSetAccess(c *C) {
+	u, err := s.State.AddUser("someuser", "", state.ReadAccess)
+	c.Assert(err, IsNil)
+
+	err = u.SetAccess(state.AdminAccess)
+	c.Assert(err, IsNil)
+	c.Assert(u.Access(), Equals, state.AdminAccess)
+
+	u1, err := s.State.User("someuser")
+	c.Assert(err, IsNil)
+	c.Assert(u1.Access(), Equals, state.AdminAccess)
+
+	err = u.SetAccess("none")
+	c.Assert(err, ErrorMatches, `invalid access "none"`)
+}
+
+func (s *UserSuite) TestUpdateLastLogin(c *C) {
+	u, err := s.State.AddUser("someuser", "", state.WriteAccess)
+	c.Assert(err, IsNil)
+
+	err = u.UpdateLastLogin()
+	c.Assert(err, IsNil)
+	c.Assert(u.LastLogin().IsZero(), Equals, false)
+
+	u1, err := s.State.User("someuser")
+	c.Assert(err, IsNil)
+	c.Assert(u1.LastLogin().Equal(u.LastLogin()), Equals, true)
+}
+
+func (s *UserSuite) TestRemove(c *C) {
+	u, err := s.State.AddUser("someuser", "", state.WriteAccess)
+	c.Assert(err, IsNil)
+
+	err = u.Remove()
+	c.Assert(err, IsNil)
+	_, err = s.State.User("someuser")
+	c.Assert(err, ErrorMatches, `user "someuser" not found`)
+
+	err = u.Remove()
+	c.Assert(err, IsNil)
+	err = u.UpdateLastLogin()
+	c.Assert(err, ErrorMatches, `cannot update last login of user "someuser": user not found`)
+}
+
+func (s *UserSuite) TestRemoveAdmin(c *C) {
+	u, err := s.State.AddUser(state.AdminUser, "", state.AdminAccess)
+	c.Assert(err, IsNil)
+
+	err = u.Remove()
+	c.Assert(err, ErrorMatches, `cannot remove user "admin"`)
+}
+
+func (s *UserSuite) TestAllUsers(c *C) {
+	for _, name := range []string{"zed", "bob", "alice"} {
+		_, err := s.State.AddUser(name, "", state.ReadAccess)
+		c.Assert(err, IsNil)
+	}
+	users, err := s.State.AllUsers()
+	c.Assert(err, IsNil)
+	var names []string
+	for _, u := range users {
+		names = append(names, u.Name())
+	}
+	c.Assert(names, DeepEquals, []string{"alice", "bob", "zed"})
 }
 
 func (s *UserSuite) TestSetPassword(c *C) {
-	u, err := s.State.AddUser("someuser", "")
+	u, err := s.State.AddUser("someuser", "", state.WriteAccess)
 	c.Assert(err, IsNil)
 
 	testSetPassword(c, func() (state.Authenticator, error) {
@@ -54,7 +154,7 @@ func (s *UserSuite) TestSetPassword(c *C) {
 }
 
 func (s *UserSuite) TestSetPasswordHash(c *C) {
-	u, err := s.State.AddUser("someuser", "")
+	u, err := s.State.AddUser("someuser", "", state.WriteAccess)
 	c.Assert(err, IsNil)
 
 	err = u.SetPasswordHash(utils.PasswordHash("foo"))
@@ -65,7 +165,7 @@ func (s *UserSuite) TestSetPasswordHash(c *C) {
 }
 
 func (s *UserSuite) TestName(c *C) {
-	u, err := s.State.AddUser("someuser", "")
+	u, err := s.State.AddUser("someuser", "", state.WriteAccess)
 	c.Assert(err, IsNil)
 
 	c.Assert(u.Name(), Equals, "someuser")
@@ -17,6 +17,8 @@ import (
 	"launchpad.net/juju-core/errors"
 	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/names"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/presence"
 	"launchpad.net/juju-core/state/watcher"
 	"launchpad.net/juju-core/utils/set"
 )
@@ -384,6 +386,504 @@ func (w *minUnitsWatcher) Changes() <-chan []string {
 	return w.out
 }
 
+// containerOpsWatcher notifies about the operations requested on the
+// containers of a machine. The first event returned by the watcher is the
+// set of containers with a pending operation; subsequent events are
+// generated when an operation is requested.
+type containerOpsWatcher struct {
+	commonWatcher
+	hostId string
+	out    chan []string
+}
+
+// WatchContainerOps returns a StringsWatcher that notifies of the ids
+// of the containers of the machine with a pending operation.
+func (m *Machine) WatchContainerOps() StringsWatcher {
+	w := &containerOpsWatcher{
+		commonWatcher: commonWatcher{st: m.st},
+		hostId:        m.doc.Id,
+		out:           make(chan []string),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+func (w *containerOpsWatcher) initial() (*set.Strings, error) {
+	ids := new(set.Strings)
+	doc := &containerOpDoc{}
+	iter := w.st.containerOps.Find(D{{"hostid", w.hostId}, {"error", ""}}).Iter()
+	for iter.Next(doc) {
+		ids.Add(doc.MachineId)
+	}
+	return ids, iter.Err()
+}
+
+func (w *containerOpsWatcher) merge(ids *set.Strings, change watcher.Change) error {
+	id := change.Id.(string)
+	if ParentId(id) != w.hostId {
+		return nil
+	}
+	if change.Revno == -1 {
+		ids.Remove(id)
+		return nil
+	}
+	doc := containerOpDoc{}
+	if err := w.st.containerOps.FindId(id).One(&doc); err == mgo.ErrNotFound {
+		ids.Remove(id)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if doc.Error == "" {
+		ids.Add(id)
+	} else {
+		ids.Remove(id)
+	}
+	return nil
+}
+
+func (w *containerOpsWatcher) loop() (err error) {
+	ch := make(chan watcher.Change)
+	w.st.watcher.WatchCollection(w.st.containerOps.Name, ch)
+	defer w.st.watcher.UnwatchCollection(w.st.containerOps.Name, ch)
+	ids, err := w.initial()
+	if err != nil {
+		return err
+	}
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case change, ok := <-ch:
+			if !ok {
+				return watcher.MustErr(w.st.watcher)
+			}
+			if err = w.merge(ids, change); err != nil {
+				return err
+			}
+			if !ids.IsEmpty() {
+				out = w.out
+			}
+		case out <- ids.Values():
+			out = nil
+			ids = new(set.Strings)
+		}
+	}
+	return nil
+}
+
+func (w *containerOpsWatcher) Changes() <-chan []string {
+	return w.out
+}
+
+// actionsWatcher notifies about the actions queued on a unit. The first
+// event returned by the watcher is the set of pending actions of the
+// unit; subsequent events are generated when actions are queued.
+type actionsWatcher struct {
+	commonWatcher
+	unitName string
+	out      chan []string
+}
+
+// WatchActions returns a StringsWatcher that notifies of the ids of the
+// pending actions of the unit.
+func (u *Unit) WatchActions() StringsWatcher {
+	w := &actionsWatcher{
+		commonWatcher: commonWatcher{st: u.st},
+		unitName:      u.doc.Name,
+		out:           make(chan []string),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+func (w *actionsWatcher) initial() (*set.Strings, error) {
+	ids := new(set.Strings)
+	doc := &actionDoc{}
+	iter := w.st.actions.Find(D{{"unit", w.unitName}, {"status", params.ActionPending}}).Iter()
+	for iter.Next(doc) {
+		ids.Add(doc.Id)
+	}
+	return ids, iter.Err()
+}
+
+func (w *actionsWatcher) merge(ids *set.Strings, change watcher.Change) error {
+	id := change.Id.(string)
+	if !strings.HasPrefix(id, w.unitName+":") {
+		return nil
+	}
+	if change.Revno == -1 {
+		ids.Remove(id)
+		return nil
+	}
+	doc := actionDoc{}
+	if err := w.st.actions.FindId(id).One(&doc); err == mgo.ErrNotFound {
+		ids.Remove(id)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if doc.Status == params.ActionPending {
+		ids.Add(id)
+	} else {
+		ids.Remove(id)
+	}
+	return nil
+}
+
+func (w *actionsWatcher) loop() (err error) {
+	ch := make(chan watcher.Change)
+	w.st.watcher.WatchCollection(w.st.actions.Name, ch)
+	defer w.st.watcher.UnwatchCollection(w.st.actions.Name, ch)
+	ids, err := w.initial()
+	if err != nil {
+		return err
+	}
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case change, ok := <-ch:
+			if !ok {
+				return watcher.MustErr(w.st.watcher)
+			}
+			if err = w.merge(ids, change); err != nil {
+				return err
+			}
+			if !ids.IsEmpty() {
+				out = w.out
+			}
+		case out <- ids.Values():
+			out = nil
+			ids = new(set.Strings)
+		}
+	}
+	return nil
+}
+
+func (w *actionsWatcher) Changes() <-chan []string {
+	return w.out
+}
+
+// storageInstancesWatcher notifies about changes to the storage
+// instances of a unit.
+type storageInstancesWatcher struct {
+	commonWatcher
+	unitName string
+	out      chan struct{}
+}
+
+// WatchStorageInstances returns a NotifyWatcher that notifies of
+// changes to the storage instances of the unit, such as the
+// provisioning of their volumes.
+func (u *Unit) WatchStorageInstances() NotifyWatcher {
+	w := &storageInstancesWatcher{
+		commonWatcher: commonWatcher{st: u.st},
+		unitName:      u.doc.Name,
+		out:           make(chan struct{}),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+func (w *storageInstancesWatcher) loop() (err error) {
+	ch := make(chan watcher.Change)
+	w.st.watcher.WatchCollection(w.st.storageInstances.Name, ch)
+	defer w.st.watcher.UnwatchCollection(w.st.storageInstances.Name, ch)
+	ids := new(set.Strings)
+	doc := &storageInstanceDoc{}
+	iter := w.st.storageInstances.Find(D{{"unit", w.unitName}}).Select(D{{"_id", 1}}).Iter()
+	for iter.Next(doc) {
+		ids.Add(doc.Id)
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case change, ok := <-ch:
+			if !ok {
+				return watcher.MustErr(w.st.watcher)
+			}
+			id := change.Id.(string)
+			if ids.Contains(id) {
+				if change.Revno == -1 {
+					ids.Remove(id)
+				}
+				out = w.out
+				continue
+			}
+			if change.Revno == -1 {
+				continue
+			}
+			err := w.st.storageInstances.FindId(id).One(doc)
+			if err == mgo.ErrNotFound {
+				continue
+			} else if err != nil {
+				return err
+			}
+			if doc.Unit == w.unitName {
+				ids.Add(id)
+				out = w.out
+			}
+		case out <- struct{}{}:
+			out = nil
+		}
+	}
+	return nil
+}
+
+// Changes returns the event channel for the storageInstancesWatcher.
+func (w *storageInstancesWatcher) Changes() <-chan struct{} {
+	return w.out
+}
+
+// storageProvisioningWatcher notifies about the storage instances
+// needing the attention of a storage provisioner: those of units
+// assigned to a machine that are not provisioned yet, and those left
+// Dead by the removal of their unit. The stores waiting for their
+// machine are notified again once the machine is provisioned.
+type storageProvisioningWatcher struct {
+	commonWatcher
+	out chan []string
+}
+
+// WatchStorageProvisioning returns a StringsWatcher that notifies of the
+// ids of the storage instances of the environment that need to be
+// provisioned, or whose volumes need to be destroyed.
+func (st *State) WatchStorageProvisioning() StringsWatcher {
+	w := &storageProvisioningWatcher{
+		commonWatcher: commonWatcher{st: st},
+		out:           make(chan []string),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// needsProvisioner returns whether the storage instance needs the
+// attention of a storage provisioner.
+func needsProvisioner(doc *storageInstanceDoc) bool {
+	return doc.Life == Dead || !doc.Provisioned && doc.Machine != ""
+}
+
+func (w *storageProvisioningWatcher) initial() (*set.Strings, error) {
+	ids := new(set.Strings)
+	doc := &storageInstanceDoc{}
+	iter := w.st.storageInstances.Find(nil).Iter()
+	for iter.Next(doc) {
+		if needsProvisioner(doc) {
+			ids.Add(doc.Id)
+		}
+	}
+	return ids, iter.Err()
+}
+
+func (w *storageProvisioningWatcher) merge(ids *set.Strings, change watcher.Change) error {
+	id := change.Id.(string)
+	if change.Revno == -1 {
+		ids.Remove(id)
+		return nil
+	}
+	doc := &storageInstanceDoc{}
+	if err := w.st.storageInstances.FindId(id).One(doc); err == mgo.ErrNotFound {
+		ids.Remove(id)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if needsProvisioner(doc) {
+		ids.Add(id)
+	} else {
+		ids.Remove(id)
+	}
+	return nil
+}
+
+// mergeMachine adds the ids of the storage instances waiting for the
+// machine with the given id to be provisioned.
+func (w *storageProvisioningWatcher) mergeMachine(ids *set.Strings, change watcher.Change) error {
+	if change.Revno == -1 {
+		return nil
+	}
+	doc := &storageInstanceDoc{}
+	sel := D{{"machine", change.Id.(string)}, {"provisioned", false}}
+	iter := w.st.storageInstances.Find(sel).Iter()
+	for iter.Next(doc) {
+		if needsProvisioner(doc) {
+			ids.Add(doc.Id)
+		}
+	}
+	return iter.Err()
+}
+
+func (w *storageProvisioningWatcher) loop() (err error) {
+	ch := make(chan watcher.Change)
+	w.st.watcher.WatchCollection(w.st.storageInstances.Name, ch)
+	defer w.st.watcher.UnwatchCollection(w.st.storageInstances.Name, ch)
+	machineCh := make(chan watcher.Change)
+	w.st.watcher.WatchCollection(w.st.instanceData.Name, machineCh)
+	defer w.st.watcher.UnwatchCollection(w.st.instanceData.Name, machineCh)
+	ids, err := w.initial()
+	if err != nil {
+		return err
+	}
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case change, ok := <-ch:
+			if !ok {
+				return watcher.MustErr(w.st.watcher)
+			}
+			if err = w.merge(ids, change); err != nil {
+				return err
+			}
+			if !ids.IsEmpty() {
+				out = w.out
+			}
+		case change, ok := <-machineCh:
+			if !ok {
+				return watcher.MustErr(w.st.watcher)
+			}
+			if err = w.mergeMachine(ids, change); err != nil {
+				return err
+			}
+			if !ids.IsEmpty() {
+				out = w.out
+			}
+		case out <- ids.Values():
+			out = nil
+			ids = new(set.Strings)
+		}
+	}
+	return nil
+}
+
+// Changes returns the event channel for the storageProvisioningWatcher.
+func (w *storageProvisioningWatcher) Changes() <-chan []string {
+	return w.out
+}
+
+// leadershipWatcher notifies about changes to the leadership of a
+// service. An event is generated when the leader changes, and when the
+// agent of the current leader is found to have died, so that the other
+// units may claim leadership.
+type leadershipWatcher struct {
+	commonWatcher
+	serviceName string
+	out         chan struct{}
+}
+
+// WatchLeadership returns a NotifyWatcher that notifies of changes to
+// the leadership of the service.
+func (s *Service) WatchLeadership() NotifyWatcher {
+	w := &leadershipWatcher{
+		commonWatcher: commonWatcher{st: s.st},
+		serviceName:   s.doc.Name,
+		out:           make(chan struct{}),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// WatchLeaderSettings returns a NotifyWatcher that notifies of changes
+// to the settings written by the leader of the service.
+func (s *Service) WatchLeaderSettings() NotifyWatcher {
+	return newEntityWatcher(s.st, s.st.leaderSettings, s.doc.Name)
+}
+
+func (w *leadershipWatcher) loop() (err error) {
+	doc := &struct {
+		Leader   string
+		TxnRevno int64 `bson:"txn-revno"`
+	}{}
+	if err := w.st.leaderships.FindId(w.serviceName).One(doc); err == mgo.ErrNotFound {
+		doc.TxnRevno = -1
+	} else if err != nil {
+		return err
+	}
+	in := make(chan watcher.Change)
+	w.st.watcher.Watch(w.st.leaderships.Name, w.serviceName, doc.TxnRevno, in)
+	defer w.st.watcher.Unwatch(w.st.leaderships.Name, w.serviceName, in)
+	presenceCh := make(chan presence.Change)
+	leader := doc.Leader
+	if leader != "" {
+		w.st.pwatcher.Watch(unitGlobalKey(leader), presenceCh)
+	}
+	defer func() {
+		if leader != "" {
+			w.st.pwatcher.Unwatch(unitGlobalKey(leader), presenceCh)
+		}
+	}()
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return watcher.MustErr(w.st.watcher)
+		case <-w.st.pwatcher.Dead():
+			return w.st.pwatcher.Err()
+		case ch := <-in:
+			if _, ok := collect(ch, in, w.tomb.Dying()); !ok {
+				return tomb.ErrDying
+			}
+			newLeader, err := w.st.serviceLeader(w.serviceName)
+			if err != nil {
+				return err
+			}
+			if newLeader != leader {
+				if leader != "" {
+					w.st.pwatcher.Unwatch(unitGlobalKey(leader), presenceCh)
+				}
+				leader = newLeader
+				if leader != "" {
+					w.st.pwatcher.Watch(unitGlobalKey(leader), presenceCh)
+				}
+			}
+			out = w.out
+		case change := <-presenceCh:
+			if !change.Alive {
+				out = w.out
+			}
+		case out <- struct{}{}:
+			out = nil
+		}
+	}
+	return nil
+}
+
+// Changes returns the event channel for the leadershipWatcher.
+func (w *leadershipWatcher) Changes() <-chan struct{} {
+	return w.out
+}
+
 // RelationScopeWatcher observes changes to the set of units
 // in a particular relation scope.
 type RelationScopeWatcher struct {
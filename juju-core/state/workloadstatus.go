@@ -0,0 +1,135 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/txn"
+
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// workloadStatusDoc represents the status of the workload of a unit, as
+// reported by its charm. It is kept apart from the status of the unit's
+// agent, and keyed by the unit name.
+type workloadStatusDoc struct {
+	Name   string `bson:"_id"`
+	Status params.WorkloadStatus
+	Info   string
+}
+
+// createWorkloadStatusOp returns the operation needed to create the
+// workload status document of the named unit.
+func createWorkloadStatusOp(st *State, unitName string) txn.Op {
+	return txn.Op{
+		C:      st.workloadStatuses.Name,
+		Id:     unitName,
+		Assert: txn.DocMissing,
+		Insert: &workloadStatusDoc{
+			Name:   unitName,
+			Status: params.WorkloadUnknown,
+		},
+	}
+}
+
+// removeWorkloadStatusOp returns the operation needed to remove the
+// workload status document of the named unit.
+func removeWorkloadStatusOp(st *State, unitName string) txn.Op {
+	return txn.Op{
+		C:      st.workloadStatuses.Name,
+		Id:     unitName,
+		Remove: true,
+	}
+}
+
+// getWorkloadStatus returns the workload status document of the named
+// unit. Units added before workload statuses were recorded have no
+// document, and their workload status is unknown.
+func getWorkloadStatus(st *State, unitName string) (workloadStatusDoc, error) {
+	var doc workloadStatusDoc
+	err := st.workloadStatuses.FindId(unitName).One(&doc)
+	if err == mgo.ErrNotFound {
+		return workloadStatusDoc{Name: unitName, Status: params.WorkloadUnknown}, nil
+	}
+	if err != nil {
+		return workloadStatusDoc{}, fmt.Errorf("cannot get workload status of unit %q: %v", unitName, err)
+	}
+	return doc, nil
+}
+
+// WorkloadStatus returns the status of the unit's workload, as last
+// reported by its charm, and the accompanying message.
+func (u *Unit) WorkloadStatus() (status params.WorkloadStatus, info string, err error) {
+	doc, err := getWorkloadStatus(u.st, u.doc.Name)
+	if err != nil {
+		return "", "", err
+	}
+	return doc.Status, doc.Info, nil
+}
+
+// SetWorkloadStatus records the status of the unit's workload, and a
+// message for the operator explaining it.
+func (u *Unit) SetWorkloadStatus(status params.WorkloadStatus, info string) error {
+	if !status.Valid() || status == params.WorkloadUnknown {
+		return fmt.Errorf("cannot set invalid workload status %q", status)
+	}
+	for i := 0; i < 2; i++ {
+		op := txn.Op{
+			C:      u.st.workloadStatuses.Name,
+			Id:     u.doc.Name,
+			Assert: txn.DocExists,
+			Update: D{{"$set", D{{"status", status}, {"info", info}}}},
+		}
+		if count, err := u.st.workloadStatuses.FindId(u.doc.Name).Count(); err != nil {
+			return fmt.Errorf("cannot set workload status of unit %q: %v", u, err)
+		} else if count == 0 {
+			// The unit was added before workload statuses were
+			// recorded.
+			op.Assert = txn.DocMissing
+			op.Update = nil
+			op.Insert = &workloadStatusDoc{
+				Name:   u.doc.Name,
+				Status: status,
+				Info:   info,
+			}
+		}
+		ops := []txn.Op{{
+			C:      u.st.units.Name,
+			Id:     u.doc.Name,
+			Assert: notDeadDoc,
+		}, op}
+		err := u.st.runTransaction(ops)
+		if err == nil {
+			return nil
+		} else if err != txn.ErrAborted {
+			return fmt.Errorf("cannot set workload status of unit %q: %v", u, err)
+		}
+		if notDead, err := isNotDead(u.st.units, u.doc.Name); err != nil {
+			return err
+		} else if !notDead {
+			return fmt.Errorf("cannot set workload status of unit %q: %v", u, errDead)
+		}
+	}
+	return fmt.Errorf("cannot set workload status of unit %q: %v", u, ErrExcessiveContention)
+}
+
+// WorkloadStatus summarizes the workload statuses of the units of the
+// service. It returns the status most in need of attention, with the
+// message of the first unit, in name order, reporting it.
+func (s *Service) WorkloadStatus() (status params.WorkloadStatus, info string, err error) {
+	var docs []workloadStatusDoc
+	sel := D{{"_id", D{{"$regex", "^" + s.doc.Name + "/"}}}}
+	if err := s.st.workloadStatuses.Find(sel).Sort("_id").All(&docs); err != nil {
+		return "", "", fmt.Errorf("cannot get workload statuses of service %q: %v", s, err)
+	}
+	status = params.WorkloadUnknown
+	for _, doc := range docs {
+		if doc.Status.MoreSevere(status) {
+			status, info = doc.Status, doc.Info
+		}
+	}
+	return status, info, nil
+}
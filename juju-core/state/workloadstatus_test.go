@@ -0,0 +1,151 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+type WorkloadStatusSuite struct {
+	ConnSuite
+	service *state.Service
+	unit    *state.Unit
+}
+
+var _ = Suite(&WorkloadStatusSuite{})
+
+func (s *WorkloadStatusSuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.service, err = s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	s.unit, err = s.service.AddUnit()
+	c.Assert(err, IsNil)
+}
+
+func (s *WorkloadStatusSuite) TestGetSetWorkloadStatus(c *C) {
+	status, info, err := s.unit.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.WorkloadUnknown)
+	c.Assert(info, Equals, "")
+
+	err = s.unit.SetWorkloadStatus(params.WorkloadUnknown, "")
+	c.Assert(err, ErrorMatches, `cannot set invalid workload status "unknown"`)
+	err = s.unit.SetWorkloadStatus(params.WorkloadStatus("vliegkat"), "orville")
+	c.Assert(err, ErrorMatches, `cannot set invalid workload status "vliegkat"`)
+
+	err = s.unit.SetWorkloadStatus(params.WorkloadBlocked, "missing config")
+	c.Assert(err, IsNil)
+	status, info, err = s.unit.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.WorkloadBlocked)
+	c.Assert(info, Equals, "missing config")
+
+	// The agent status is left alone.
+	agentStatus, _, err := s.unit.Status()
+	c.Assert(err, IsNil)
+	c.Assert(agentStatus, Equals, params.StatusPending)
+	err = s.unit.SetStatus(params.StatusStarted, "")
+	c.Assert(err, IsNil)
+	status, info, err = s.unit.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.WorkloadBlocked)
+	c.Assert(info, Equals, "missing config")
+}
+
+func (s *WorkloadStatusSuite) TestSetWorkloadStatusWhileNotAlive(c *C) {
+	err := s.unit.Destroy()
+	c.Assert(err, IsNil)
+	err = s.unit.SetWorkloadStatus(params.WorkloadActive, "")
+	c.Assert(err, ErrorMatches, `cannot set workload status of unit "wordpress/0": not found or dead`)
+	status, _, err := s.unit.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.WorkloadUnknown)
+}
+
+func (s *WorkloadStatusSuite) TestUnitAddedBeforeWorkloadStatus(c *C) {
+	// Units added before workload statuses were recorded have no
+	// workload status document.
+	err := s.MgoSuite.Session.DB("juju").C("workloadstatuses").RemoveId(s.unit.Name())
+	c.Assert(err, IsNil)
+	status, info, err := s.unit.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.WorkloadUnknown)
+	c.Assert(info, Equals, "")
+
+	err = s.unit.SetWorkloadStatus(params.WorkloadBlocked, "missing config")
+	c.Assert(err, IsNil)
+	status, info, err = s.unit.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.WorkloadBlocked)
+	c.Assert(info, Equals, "missing config")
+	status, info, err = s.service.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.WorkloadBlocked)
+	c.Assert(info, Equals, "missing config")
+
+	err = s.unit.SetWorkloadStatus(params.WorkloadActive, "ready")
+	c.Assert(err, IsNil)
+	status, info, err = s.unit.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.WorkloadActive)
+	c.Assert(info, Equals, "ready")
+}
+
+func (s *WorkloadStatusSuite) TestServiceWorkloadStatus(c *C) {
+	unit1, err := s.service.AddUnit()
+	c.Assert(err, IsNil)
+	unit2, err := s.service.AddUnit()
+	c.Assert(err, IsNil)
+	other, err := s.State.AddService("mysql", s.AddTestingCharm(c, "mysql"))
+	c.Assert(err, IsNil)
+	otherUnit, err := other.AddUnit()
+	c.Assert(err, IsNil)
+	err = otherUnit.SetWorkloadStatus(params.WorkloadBlocked, "not wordpress")
+	c.Assert(err, IsNil)
+
+	status, info, err := s.service.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.WorkloadUnknown)
+	c.Assert(info, Equals, "")
+
+	for i, t := range []struct {
+		unit       *state.Unit
+		status     params.WorkloadStatus
+		info       string
+		expect     params.WorkloadStatus
+		expectInfo string
+	}{
+		{s.unit, params.WorkloadActive, "ready", params.WorkloadActive, "ready"},
+		{unit1, params.WorkloadMaintenance, "upgrading", params.WorkloadMaintenance, "upgrading"},
+		{unit2, params.WorkloadWaiting, "waiting for database", params.WorkloadWaiting, "waiting for database"},
+		{unit1, params.WorkloadBlocked, "missing config", params.WorkloadBlocked, "missing config"},
+		{s.unit, params.WorkloadBlocked, "disk full", params.WorkloadBlocked, "disk full"},
+		{s.unit, params.WorkloadActive, "ready", params.WorkloadBlocked, "missing config"},
+		{unit1, params.WorkloadActive, "ready", params.WorkloadWaiting, "waiting for database"},
+	} {
+		c.Logf("test %d: %s %s", i, t.unit, t.status)
+		err := t.unit.SetWorkloadStatus(t.status, t.info)
+		c.Assert(err, IsNil)
+		status, info, err := s.service.WorkloadStatus()
+		c.Assert(err, IsNil)
+		c.Check(status, Equals, t.expect)
+		c.Check(info, Equals, t.expectInfo)
+	}
+}
+
+func (s *WorkloadStatusSuite) TestRemoveUnit(c *C) {
+	err := s.unit.SetWorkloadStatus(params.WorkloadBlocked, "missing config")
+	c.Assert(err, IsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, IsNil)
+	err = s.unit.Remove()
+	c.Assert(err, IsNil)
+	status, _, err := s.service.WorkloadStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, params.WorkloadUnknown)
+}
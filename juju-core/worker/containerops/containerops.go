@@ -0,0 +1,159 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package containerops
+
+import (
+	"fmt"
+
+	"launchpad.net/gocontainer"
+	// Register the container backends.
+	_ "launchpad.net/godocker"
+	_ "launchpad.net/golxc"
+	"launchpad.net/loggo"
+
+	"launchpad.net/juju-core/container/lxc"
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api"
+	"launchpad.net/juju-core/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.containerops")
+
+// backend returns the factory of the containers of the given type.
+var backend = func(ctype instance.ContainerType) (gocontainer.ContainerFactory, error) {
+	return gocontainer.Backend(string(ctype))
+}
+
+// ContainerOps carries out the operations requested on the containers
+// of a machine: freezing, thawing, snapshotting and restoring them.
+type ContainerOps struct {
+	st        *state.State
+	machineId string
+}
+
+// NewContainerOps returns a Worker that carries out the operations
+// requested on the containers of the given machine.
+func NewContainerOps(st *state.State, machineId string) worker.Worker {
+	ops := &ContainerOps{st: st, machineId: machineId}
+	return worker.NewStringsWorker(ops)
+}
+
+func (ops *ContainerOps) SetUp() (api.StringsWatcher, error) {
+	machine, err := ops.st.Machine(ops.machineId)
+	if err != nil {
+		return nil, err
+	}
+	return machine.WatchContainerOps(), nil
+}
+
+func (ops *ContainerOps) Handle(machineIds []string) error {
+	for _, id := range machineIds {
+		container, err := ops.st.Machine(id)
+		if errors.IsNotFoundError(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		op, err := container.ContainerOp()
+		if errors.IsNotFoundError(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if op.Error != "" {
+			// The operation has failed already.
+			continue
+		}
+		logger.Infof("running %s operation on container %s", op.Op, id)
+		// Failing operations are reported to the user, and must not
+		// stop the worker.
+		opErr := runOp(container, op)
+		if opErr != nil {
+			logger.Errorf("%s operation on container %s failed: %v", op.Op, id, opErr)
+		}
+		if err := container.CompleteContainerOp(opErr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ops *ContainerOps) TearDown() error {
+	// Nothing to do here.
+	return nil
+}
+
+// runOp carries out the operation on the container hosting the machine.
+func runOp(machine *state.Machine, op *state.ContainerOperation) error {
+	instId, err := machine.InstanceId()
+	if err != nil {
+		return err
+	}
+	ctype := machine.ContainerType()
+	factory, err := backend(ctype)
+	if err != nil {
+		return err
+	}
+	container := factory.New(string(instId))
+	switch op.Op {
+	case state.ContainerFreeze, state.ContainerThaw:
+		if !gocontainer.HasCapability(factory, gocontainer.Freezable) {
+			return fmt.Errorf("%s containers cannot be frozen", ctype)
+		}
+		if op.Op == state.ContainerFreeze {
+			return container.Freeze()
+		}
+		return container.Unfreeze()
+	case state.ContainerSnapshot, state.ContainerRestore:
+		snapshotter, ok := container.(gocontainer.Snapshotter)
+		if !ok || !gocontainer.HasCapability(factory, gocontainer.Snapshot) {
+			return fmt.Errorf("%s containers cannot be snapshotted", ctype)
+		}
+		if op.Op == state.ContainerSnapshot {
+			if gocontainer.HasCapability(factory, gocontainer.LiveSnapshot) {
+				return snapshotter.Snapshot(op.Snapshot)
+			}
+			return whileStopped(ctype, container, func() error {
+				return snapshotter.Snapshot(op.Snapshot)
+			})
+		}
+		return whileStopped(ctype, container, func() error {
+			return snapshotter.Restore(op.Snapshot)
+		})
+	}
+	return fmt.Errorf("unknown container operation %q", op.Op)
+}
+
+// whileStopped calls f with the container stopped, and starts the
+// container again afterwards if it was running, even when f fails.
+func whileStopped(ctype instance.ContainerType, container gocontainer.Container, f func() error) error {
+	running := container.IsRunning()
+	if running {
+		if err := container.Stop(); err != nil {
+			return err
+		}
+	}
+	err := f()
+	if running {
+		if startErr := start(ctype, container); startErr != nil {
+			if err != nil {
+				logger.Errorf("cannot start container %s: %v", container.Name(), startErr)
+				return err
+			}
+			return startErr
+		}
+	}
+	return err
+}
+
+// start starts the stopped container the way the provisioner started
+// it, so that lxc containers keep their console and log files.
+func start(ctype instance.ContainerType, container gocontainer.Container) error {
+	if ctype == instance.LXC {
+		return lxc.StartExistingContainer(container)
+	}
+	return container.Start("", "")
+}
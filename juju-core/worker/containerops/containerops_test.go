@@ -0,0 +1,308 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package containerops_test
+
+import (
+	"fmt"
+	"sync"
+	stdtesting "testing"
+	"time"
+
+	gc "launchpad.net/gocheck"
+	"launchpad.net/gocontainer"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	coretesting "launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/testing/checkers"
+	"launchpad.net/juju-core/worker"
+	"launchpad.net/juju-core/worker/containerops"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type containerOpsSuite struct {
+	testing.JujuConnSuite
+	factory *fakeFactory
+	restore func()
+	host    *state.Machine
+}
+
+var _ = gc.Suite(&containerOpsSuite{})
+
+var _ worker.StringsWatchHandler = (*containerops.ContainerOps)(nil)
+
+func (s *containerOpsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.factory = &fakeFactory{
+		capabilities: []gocontainer.Capability{gocontainer.Freezable, gocontainer.Snapshot},
+		running:      true,
+	}
+	s.restore = containerops.SetBackend(s.factory)
+	var err error
+	s.host, err = s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *containerOpsSuite) TearDownTest(c *gc.C) {
+	s.restore()
+	s.JujuConnSuite.TearDownTest(c)
+}
+
+func (s *containerOpsSuite) addContainer(c *gc.C, instId instance.Id) *state.Machine {
+	params := state.AddMachineParams{
+		ParentId:      s.host.Id(),
+		ContainerType: instance.LXC,
+		Series:        "series",
+		Jobs:          []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineWithConstraints(&params)
+	c.Assert(err, gc.IsNil)
+	if instId != "" {
+		err = container.SetProvisioned(instId, "fake-nonce", nil)
+		c.Assert(err, gc.IsNil)
+	}
+	return container
+}
+
+// waitCompleted waits for the operation on the container to complete,
+// and returns the error recorded, if any.
+func (s *containerOpsSuite) waitCompleted(c *gc.C, container *state.Machine) string {
+	timeout := time.After(coretesting.LongWait)
+	for {
+		s.State.StartSync()
+		select {
+		case <-time.After(coretesting.ShortWait):
+			op, err := container.ContainerOp()
+			if errors.IsNotFoundError(err) {
+				return ""
+			}
+			c.Assert(err, gc.IsNil)
+			if op.Error != "" {
+				return op.Error
+			}
+		case <-timeout:
+			c.Fatalf("container operation not completed")
+		}
+	}
+}
+
+var consoleFile = "/var/lib/juju/containers/juju-machine-0-lxc-0/console.log"
+
+func (s *containerOpsSuite) TestOperations(c *gc.C) {
+	w := containerops.NewContainerOps(s.State, s.host.Id())
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+	container := s.addContainer(c, "juju-machine-0-lxc-0")
+
+	for i, t := range []struct {
+		op       state.ContainerOp
+		snapshot string
+		calls    []string
+	}{{
+		op:    state.ContainerFreeze,
+		calls: []string{"Freeze juju-machine-0-lxc-0"},
+	}, {
+		op:    state.ContainerThaw,
+		calls: []string{"Unfreeze juju-machine-0-lxc-0"},
+	}, {
+		// Running lxc containers are stopped while they are
+		// snapshotted, and started again with their console file.
+		op:       state.ContainerSnapshot,
+		snapshot: "before-upgrade",
+		calls: []string{
+			"Stop juju-machine-0-lxc-0",
+			"Snapshot juju-machine-0-lxc-0 before-upgrade",
+			"Start juju-machine-0-lxc-0 " + consoleFile,
+		},
+	}, {
+		// Running containers are stopped while they are restored.
+		op:       state.ContainerRestore,
+		snapshot: "before-upgrade",
+		calls: []string{
+			"Stop juju-machine-0-lxc-0",
+			"Restore juju-machine-0-lxc-0 before-upgrade",
+			"Start juju-machine-0-lxc-0 " + consoleFile,
+		},
+	}} {
+		c.Logf("test %d: %s", i, t.op)
+		s.factory.reset()
+		err := container.RequestContainerOp(t.op, t.snapshot)
+		c.Assert(err, gc.IsNil)
+		c.Assert(s.waitCompleted(c, container), gc.Equals, "")
+		c.Assert(s.factory.calls(), gc.DeepEquals, t.calls)
+	}
+}
+
+func (s *containerOpsSuite) TestFailedOperation(c *gc.C) {
+	s.factory.err = fmt.Errorf("no snapshot %q found", "unknown")
+	w := containerops.NewContainerOps(s.State, s.host.Id())
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+	container := s.addContainer(c, "juju-machine-0-lxc-0")
+
+	err := container.RequestContainerOp(state.ContainerSnapshot, "unknown")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.waitCompleted(c, container), gc.Equals, `no snapshot "unknown" found`)
+
+	// The worker carries on with the next operation.
+	s.factory.err = nil
+	err = container.RequestContainerOp(state.ContainerFreeze, "")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.waitCompleted(c, container), gc.Equals, "")
+}
+
+func (s *containerOpsSuite) TestLiveSnapshot(c *gc.C) {
+	s.factory.capabilities = append(s.factory.capabilities, gocontainer.LiveSnapshot)
+	w := containerops.NewContainerOps(s.State, s.host.Id())
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+	container := s.addContainer(c, "juju-machine-0-lxc-0")
+
+	err := container.RequestContainerOp(state.ContainerSnapshot, "before-upgrade")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.waitCompleted(c, container), gc.Equals, "")
+	c.Assert(s.factory.calls(), gc.DeepEquals, []string{"Snapshot juju-machine-0-lxc-0 before-upgrade"})
+}
+
+func (s *containerOpsSuite) TestFailedRestoreStartsContainer(c *gc.C) {
+	s.factory.failRestore = true
+	w := containerops.NewContainerOps(s.State, s.host.Id())
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+	container := s.addContainer(c, "juju-machine-0-lxc-0")
+
+	err := container.RequestContainerOp(state.ContainerRestore, "before-upgrade")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.waitCompleted(c, container), gc.Equals, "cannot restore")
+	c.Assert(s.factory.calls(), gc.DeepEquals, []string{
+		"Stop juju-machine-0-lxc-0",
+		"Restore juju-machine-0-lxc-0 before-upgrade",
+		"Start juju-machine-0-lxc-0 " + consoleFile,
+	})
+}
+
+func (s *containerOpsSuite) TestUnsupportedOperation(c *gc.C) {
+	s.factory.capabilities = []gocontainer.Capability{gocontainer.Freezable}
+	w := containerops.NewContainerOps(s.State, s.host.Id())
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+	container := s.addContainer(c, "juju-machine-0-lxc-0")
+
+	err := container.RequestContainerOp(state.ContainerSnapshot, "before-upgrade")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.waitCompleted(c, container), gc.Equals, "lxc containers cannot be snapshotted")
+	c.Assert(s.factory.calls(), gc.HasLen, 0)
+}
+
+func (s *containerOpsSuite) TestNotProvisioned(c *gc.C) {
+	w := containerops.NewContainerOps(s.State, s.host.Id())
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+	container := s.addContainer(c, "")
+
+	err := container.RequestContainerOp(state.ContainerFreeze, "")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.waitCompleted(c, container), gc.Equals, `machine 0/lxc/0 is not provisioned`)
+}
+
+func (s *containerOpsSuite) TestPendingOperationsAtStart(c *gc.C) {
+	container := s.addContainer(c, "juju-machine-0-lxc-0")
+	err := container.RequestContainerOp(state.ContainerFreeze, "")
+	c.Assert(err, gc.IsNil)
+
+	w := containerops.NewContainerOps(s.State, s.host.Id())
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+	c.Assert(s.waitCompleted(c, container), gc.Equals, "")
+	_, err = container.ContainerOp()
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+	c.Assert(s.factory.calls(), gc.DeepEquals, []string{"Freeze juju-machine-0-lxc-0"})
+}
+
+// fakeFactory records the calls made on its containers.
+type fakeFactory struct {
+	mu           sync.Mutex
+	capabilities []gocontainer.Capability
+	running      bool
+	err          error
+	failRestore  bool
+	log          []string
+}
+
+func (f *fakeFactory) reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = nil
+}
+
+func (f *fakeFactory) calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.log
+}
+
+func (f *fakeFactory) record(call string, args ...interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, fmt.Sprintf(call, args...))
+	return f.err
+}
+
+func (f *fakeFactory) New(name string) gocontainer.Container {
+	return &fakeContainer{name: name, factory: f}
+}
+
+func (f *fakeFactory) List() ([]gocontainer.Container, error) {
+	return nil, nil
+}
+
+func (f *fakeFactory) Capabilities() []gocontainer.Capability {
+	return f.capabilities
+}
+
+// fakeContainer implements the container operations driven by the
+// worker; the other methods are never called.
+type fakeContainer struct {
+	gocontainer.Container
+	name    string
+	factory *fakeFactory
+}
+
+func (c *fakeContainer) Freeze() error {
+	return c.factory.record("Freeze %s", c.name)
+}
+
+func (c *fakeContainer) Unfreeze() error {
+	return c.factory.record("Unfreeze %s", c.name)
+}
+
+func (c *fakeContainer) Snapshot(name string) error {
+	return c.factory.record("Snapshot %s %s", c.name, name)
+}
+
+func (c *fakeContainer) Restore(name string) error {
+	if err := c.factory.record("Restore %s %s", c.name, name); err != nil {
+		return err
+	}
+	if c.factory.failRestore {
+		return fmt.Errorf("cannot restore")
+	}
+	return nil
+}
+
+func (c *fakeContainer) IsRunning() bool {
+	return c.factory.running
+}
+
+func (c *fakeContainer) Stop() error {
+	return c.factory.record("Stop %s", c.name)
+}
+
+func (c *fakeContainer) Start(configFile, consoleFile string) error {
+	return c.factory.record("Start %s %s", c.name, consoleFile)
+}
+
+func (c *fakeContainer) Name() string {
+	return c.name
+}
+
+func (c *fakeContainer) SetLogFile(filename string, level gocontainer.LogLevel) {}
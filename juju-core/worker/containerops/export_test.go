@@ -0,0 +1,22 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package containerops
+
+import (
+	"launchpad.net/gocontainer"
+
+	"launchpad.net/juju-core/instance"
+)
+
+// SetBackend replaces the backend of the containers of all types with
+// the given factory.
+func SetBackend(factory gocontainer.ContainerFactory) (restore func()) {
+	old := backend
+	backend = func(instance.ContainerType) (gocontainer.ContainerFactory, error) {
+		return factory, nil
+	}
+	return func() {
+		backend = old
+	}
+}
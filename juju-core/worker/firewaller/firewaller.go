@@ -5,6 +5,7 @@ package firewaller
 
 import (
 	"fmt"
+	"launchpad.net/juju-core/container/docker"
 	"launchpad.net/juju-core/environs"
 	"launchpad.net/juju-core/environs/config"
 	"launchpad.net/juju-core/errors"
@@ -32,14 +33,72 @@ type Firewaller struct {
 	exposedChange   chan *exposedChange
 	globalMode      bool
 	globalPortRef   map[instance.Port]int
+	instances       func(ids []instance.Id) ([]instance.Instance, error)
+	// publishedBy holds, for the firewaller of a docker host, the
+	// container machine each port of the host is published for.
+	publishedBy map[instance.Port]string
 }
 
 // NewFirewaller returns a new Firewaller.
 func NewFirewaller(st *state.State) *Firewaller {
-	fw := &Firewaller{
+	fw := newFirewaller(st, st.WatchEnvironConfig(), st.WatchEnvironMachines())
+	fw.start()
+	return fw
+}
+
+// NewContainerFirewaller returns a new Firewaller publishing the ports
+// of the docker containers of the host machine on the host. Two
+// containers cannot publish the same port: the first container opening
+// a port gets it, and the others only once it is closed. The ports
+// published before the firewaller started stay with their containers.
+func NewContainerFirewaller(st *state.State, hostId string) (*Firewaller, error) {
+	host, err := st.Machine(hostId)
+	if err != nil {
+		return nil, err
+	}
+	publishedBy, err := publishedPortOwners(st, host)
+	if err != nil {
+		return nil, err
+	}
+	fw := newFirewaller(st, nil, host.WatchContainers(instance.DOCKER))
+	fw.instances = docker.Instances
+	fw.publishedBy = publishedBy
+	fw.start()
+	return fw, nil
+}
+
+// publishedPortOwners returns the container machine each port of the
+// host is published for, as recorded in the state.
+func publishedPortOwners(st *state.State, host *state.Machine) (map[instance.Port]string, error) {
+	publishedBy := make(map[instance.Port]string)
+	ids, err := host.Containers()
+	if errors.IsNotFoundError(err) {
+		return publishedBy, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		m, err := st.Machine(id)
+		if errors.IsNotFoundError(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if m.ContainerType() != instance.DOCKER || m.Life() == state.Dead {
+			continue
+		}
+		for _, port := range m.PublishedPorts() {
+			publishedBy[port] = id
+		}
+	}
+	return publishedBy, nil
+}
+
+func newFirewaller(st *state.State, environWatcher *state.EnvironConfigWatcher, machinesWatcher state.StringsWatcher) *Firewaller {
+	return &Firewaller{
 		st:              st,
-		environWatcher:  st.WatchEnvironConfig(),
-		machinesWatcher: st.WatchEnvironMachines(),
+		environWatcher:  environWatcher,
+		machinesWatcher: machinesWatcher,
 		machineds:       make(map[string]*machineData),
 		unitsChange:     make(chan *unitsChange),
 		unitds:          make(map[string]*unitData),
@@ -47,11 +106,13 @@ func NewFirewaller(st *state.State) *Firewaller {
 		serviceds:       make(map[string]*serviceData),
 		exposedChange:   make(chan *exposedChange),
 	}
+}
+
+func (fw *Firewaller) start() {
 	go func() {
 		defer fw.tomb.Done()
 		fw.tomb.Kill(fw.loop())
 	}()
-	return fw
 }
 
 func (fw *Firewaller) loop() error {
@@ -60,19 +121,25 @@ func (fw *Firewaller) loop() error {
 	var err error
 	var reconciled bool
 
-	fw.environ, err = worker.WaitForEnviron(fw.environWatcher, fw.tomb.Dying())
-	if err != nil {
-		return err
-	}
-	if fw.environ.Config().FirewallMode() == config.FwGlobal {
-		fw.globalMode = true
-		fw.globalPortRef = make(map[instance.Port]int)
+	// The firewaller of a docker host has no environment to watch.
+	var environChanges <-chan *config.Config
+	if fw.environWatcher != nil {
+		fw.environ, err = worker.WaitForEnviron(fw.environWatcher, fw.tomb.Dying())
+		if err != nil {
+			return err
+		}
+		if fw.environ.Config().FirewallMode() == config.FwGlobal {
+			fw.globalMode = true
+			fw.globalPortRef = make(map[instance.Port]int)
+		}
+		fw.instances = fw.environ.Instances
+		environChanges = fw.environWatcher.Changes()
 	}
 	for {
 		select {
 		case <-fw.tomb.Dying():
 			return tomb.ErrDying
-		case change, ok := <-fw.environWatcher.Changes():
+		case change, ok := <-environChanges:
 			if !ok {
 				return watcher.MustErr(fw.environWatcher)
 			}
@@ -142,6 +209,11 @@ func (fw *Firewaller) startMachine(id string) error {
 	} else if err != nil {
 		return fmt.Errorf("worker/firewaller: cannot watch machine units: %v", err)
 	}
+	if fw.publishedBy != nil {
+		// Start from the ports published before, so that those no
+		// longer wanted are released.
+		machined.ports = m.PublishedPorts()
+	}
 	unitw := m.WatchUnits()
 	select {
 	case <-fw.tomb.Dying():
@@ -272,7 +344,7 @@ func (fw *Firewaller) reconcileInstances() error {
 		if err != nil {
 			return err
 		}
-		instances, err := fw.environ.Instances([]instance.Id{instanceId})
+		instances, err := fw.instances([]instance.Id{instanceId})
 		if err == environs.ErrNoInstances {
 			return nil
 		} else if err != nil {
@@ -285,6 +357,12 @@ func (fw *Firewaller) reconcileInstances() error {
 		// Check which ports to open or to close.
 		toOpen := Diff(machined.ports, initialPorts)
 		toClose := Diff(initialPorts, machined.ports)
+		if fw.publishedBy != nil {
+			if err := fw.flushPublishedPorts(machined, toOpen, toClose); err != nil {
+				return err
+			}
+			continue
+		}
 		if len(toOpen) > 0 {
 			log.Infof("worker/firewaller: opening instance ports %v for machine %s",
 				toOpen, machined.id)
@@ -381,9 +459,75 @@ func (fw *Firewaller) flushMachine(machined *machineData) error {
 	if fw.globalMode {
 		return fw.flushGlobalPorts(toOpen, toClose)
 	}
+	if fw.publishedBy != nil {
+		return fw.flushPublishedPorts(machined, toOpen, toClose)
+	}
 	return fw.flushInstancePorts(machined, toOpen, toClose)
 }
 
+// flushPublishedPorts publishes and unpublishes ports of a docker
+// container on its host. Ports published for another container are
+// left closed, until that container closes them, and the ports that
+// are published are recorded in the state.
+func (fw *Firewaller) flushPublishedPorts(machined *machineData, rawOpen, toClose []instance.Port) error {
+	var toOpen []instance.Port
+	for _, port := range rawOpen {
+		if owner, ok := fw.publishedBy[port]; ok && owner != machined.id {
+			log.Warningf("worker/firewaller: cannot publish port %v of machine %s: port published for machine %s",
+				port, machined.id, owner)
+			continue
+		}
+		toOpen = append(toOpen, port)
+	}
+	var released []instance.Port
+	for _, port := range toClose {
+		if fw.publishedBy[port] == machined.id {
+			delete(fw.publishedBy, port)
+			released = append(released, port)
+		}
+	}
+	if err := fw.flushInstancePorts(machined, nil, toClose); err != nil {
+		return err
+	}
+	published, err := fw.publishPorts(machined, toOpen)
+	if err != nil {
+		return err
+	}
+	for _, port := range published {
+		fw.publishedBy[port] = machined.id
+	}
+	var ports []instance.Port
+	for _, port := range machined.ports {
+		if fw.publishedBy[port] == machined.id {
+			ports = append(ports, port)
+		}
+	}
+	machined.ports = ports
+	if len(toOpen) > 0 || len(toClose) > 0 {
+		m, err := machined.machine()
+		if err != nil && !errors.IsNotFoundError(err) {
+			return err
+		}
+		if err == nil && m.Life() != state.Dead {
+			if err := m.SetPublishedPorts(machined.ports); err != nil {
+				return err
+			}
+		}
+	}
+	if len(released) == 0 {
+		return nil
+	}
+	// Publish the released ports for the containers waiting for them.
+	for _, other := range fw.machineds {
+		if other != machined {
+			if err := fw.flushMachine(other); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // flushGlobalPorts opens and closes global ports in the environment.
 // It keeps a reference count for ports so that only 0-to-1 and 1-to-0 events
 // modify the environment.
@@ -423,6 +567,43 @@ func (fw *Firewaller) flushGlobalPorts(rawOpen, rawClose []instance.Port) error
 	return nil
 }
 
+// publishPorts publishes the ports of a docker container on its host
+// one at a time, and returns the ports published. A port that cannot be
+// published, such as one still published for a container that is gone,
+// is logged and left closed.
+func (fw *Firewaller) publishPorts(machined *machineData, ports []instance.Port) ([]instance.Port, error) {
+	if len(ports) == 0 {
+		return nil, nil
+	}
+	m, err := machined.machine()
+	if errors.IsNotFoundError(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	instanceId, err := m.InstanceId()
+	if err != nil {
+		return nil, err
+	}
+	instances, err := fw.instances([]instance.Id{instanceId})
+	if err != nil {
+		return nil, err
+	}
+	var published []instance.Port
+	for _, port := range ports {
+		if err := instances[0].OpenPorts(machined.id, []instance.Port{port}); err != nil {
+			log.Warningf("worker/firewaller: cannot publish port %v of machine %s: %v", port, machined.id, err)
+			continue
+		}
+		published = append(published, port)
+	}
+	if len(published) > 0 {
+		state.SortPorts(published)
+		log.Infof("worker/firewaller: published ports %v of machine %s", published, machined.id)
+	}
+	return published, nil
+}
+
 // flushGlobalPorts opens and closes ports global on the machine.
 func (fw *Firewaller) flushInstancePorts(machined *machineData, toOpen, toClose []instance.Port) error {
 	// If there's nothing to do, do nothing.
@@ -443,7 +624,7 @@ func (fw *Firewaller) flushInstancePorts(machined *machineData, toOpen, toClose
 	if err != nil {
 		return err
 	}
-	instances, err := fw.environ.Instances([]instance.Id{instanceId})
+	instances, err := fw.instances([]instance.Id{instanceId})
 	if err != nil {
 		return err
 	}
@@ -530,7 +711,9 @@ func (fw *Firewaller) forgetUnit(unitd *unitData) {
 
 // stopWatchers stops all the firewaller's watchers.
 func (fw *Firewaller) stopWatchers() {
-	watcher.Stop(fw.environWatcher, &fw.tomb)
+	if fw.environWatcher != nil {
+		watcher.Stop(fw.environWatcher, &fw.tomb)
+	}
 	watcher.Stop(fw.machinesWatcher, &fw.tomb)
 	for _, unitd := range fw.unitds {
 		watcher.Stop(unitd, &fw.tomb)
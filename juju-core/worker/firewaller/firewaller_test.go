@@ -9,12 +9,15 @@ import (
 	stdtesting "testing"
 	"time"
 
+	"launchpad.net/juju-core/agent/tools"
+	"launchpad.net/juju-core/container/docker"
 	"launchpad.net/juju-core/environs/config"
 	"launchpad.net/juju-core/environs/dummy"
 	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/juju/testing"
 	"launchpad.net/juju-core/state"
 	coretesting "launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/version"
 	"launchpad.net/juju-core/worker"
 	"launchpad.net/juju-core/worker/firewaller"
 )
@@ -34,7 +37,13 @@ var _ worker.Worker = (*firewaller.Firewaller)(nil)
 // assertPorts retrieves the open ports of the instance and compares them
 // to the expected.
 func (s *FirewallerSuite) assertPorts(c *C, inst instance.Instance, machineId string, expected []instance.Port) {
-	s.State.StartSync()
+	waitForPorts(c, s.State, inst, machineId, expected)
+}
+
+// waitForPorts waits for the open ports of the instance to be the
+// expected.
+func waitForPorts(c *C, st *state.State, inst instance.Instance, machineId string, expected []instance.Port) {
+	st.StartSync()
 	start := time.Now()
 	for {
 		got, err := inst.Ports(machineId)
@@ -768,3 +777,254 @@ func (s *FirewallerSuite) TestGlobalModeRestartPortCount(c *C) {
 	c.Assert(err, IsNil)
 	s.assertEnvironPorts(c, nil)
 }
+
+type ContainerFirewallerSuite struct {
+	testing.JujuConnSuite
+	docker.TestSuite
+	charm   *state.Charm
+	manager docker.ContainerManager
+	host    *state.Machine
+}
+
+var _ = Suite(&ContainerFirewallerSuite{})
+
+var fakeTools = &tools.Tools{
+	Version: version.MustParseBinary("2.3.4-foo-bar"),
+	URL:     "http://tools.testing.invalid/2.3.4-foo-bar.tgz",
+}
+
+func (s *ContainerFirewallerSuite) SetUpSuite(c *C) {
+	s.JujuConnSuite.SetUpSuite(c)
+	s.TestSuite.SetUpSuite(c)
+}
+
+func (s *ContainerFirewallerSuite) TearDownSuite(c *C) {
+	s.TestSuite.TearDownSuite(c)
+	s.JujuConnSuite.TearDownSuite(c)
+}
+
+func (s *ContainerFirewallerSuite) SetUpTest(c *C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.TestSuite.SetUpTest(c)
+	s.charm = s.AddTestingCharm(c, "dummy")
+	s.AddTools(c, fakeTools)
+	s.manager = docker.NewContainerManager(docker.ManagerConfig{Name: "juju"})
+	var err error
+	s.host, err = s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+}
+
+func (s *ContainerFirewallerSuite) TearDownTest(c *C) {
+	s.TestSuite.TearDownTest(c)
+	s.JujuConnSuite.TearDownTest(c)
+}
+
+// addUnit adds a unit of the service to a new docker container of the
+// host, and starts the container.
+func (s *ContainerFirewallerSuite) addUnit(c *C, svc *state.Service) (*state.Unit, *state.Machine, instance.Instance) {
+	params := state.AddMachineParams{
+		ParentId:      s.host.Id(),
+		ContainerType: instance.DOCKER,
+		Series:        "series",
+		Jobs:          []state.MachineJob{state.JobHostUnits},
+	}
+	m, err := s.State.AddMachineWithConstraints(&params)
+	c.Assert(err, IsNil)
+	inst, err := s.manager.StartContainer(m.Id(), "series", "fake_nonce", fakeTools,
+		coretesting.EnvironConfig(c), testing.FakeStateInfo(m.Id()), testing.FakeAPIInfo(m.Id()))
+	c.Assert(err, IsNil)
+	err = m.SetProvisioned(inst.Id(), "fake_nonce", nil)
+	c.Assert(err, IsNil)
+	u, err := svc.AddUnit()
+	c.Assert(err, IsNil)
+	err = u.AssignToMachine(m)
+	c.Assert(err, IsNil)
+	return u, m, inst
+}
+
+// assertPublishedPorts waits for the published ports of the machine
+// recorded in the state to be the expected.
+func (s *ContainerFirewallerSuite) assertPublishedPorts(c *C, m *state.Machine, expected []instance.Port) {
+	s.State.StartSync()
+	start := time.Now()
+	for {
+		c.Assert(m.Refresh(), IsNil)
+		got := m.PublishedPorts()
+		state.SortPorts(expected)
+		if len(got) == 0 && len(expected) == 0 || reflect.DeepEqual(got, expected) {
+			return
+		}
+		if time.Since(start) > coretesting.LongWait {
+			c.Fatalf("timed out: expected %q; got %q", expected, got)
+		}
+		time.Sleep(coretesting.ShortWait)
+	}
+}
+
+func (s *ContainerFirewallerSuite) TestPublishPorts(c *C) {
+	fw, err := firewaller.NewContainerFirewaller(s.State, s.host.Id())
+	c.Assert(err, IsNil)
+	defer func() { c.Assert(fw.Stop(), IsNil) }()
+
+	svc, err := s.State.AddService("wordpress", s.charm)
+	c.Assert(err, IsNil)
+	err = svc.SetExposed()
+	c.Assert(err, IsNil)
+	u, m, inst := s.addUnit(c, svc)
+
+	err = u.OpenPort("tcp", 80)
+	c.Assert(err, IsNil)
+	waitForPorts(c, s.State, inst, m.Id(), []instance.Port{{"tcp", 80}})
+	s.assertPublishedPorts(c, m, []instance.Port{{"tcp", 80}})
+
+	err = u.ClosePort("tcp", 80)
+	c.Assert(err, IsNil)
+	waitForPorts(c, s.State, inst, m.Id(), nil)
+	s.assertPublishedPorts(c, m, nil)
+}
+
+func (s *ContainerFirewallerSuite) TestPublishPortsConflict(c *C) {
+	fw, err := firewaller.NewContainerFirewaller(s.State, s.host.Id())
+	c.Assert(err, IsNil)
+	defer func() { c.Assert(fw.Stop(), IsNil) }()
+
+	svc, err := s.State.AddService("wordpress", s.charm)
+	c.Assert(err, IsNil)
+	err = svc.SetExposed()
+	c.Assert(err, IsNil)
+	u0, m0, inst0 := s.addUnit(c, svc)
+	u1, m1, inst1 := s.addUnit(c, svc)
+
+	err = u0.OpenPort("tcp", 80)
+	c.Assert(err, IsNil)
+	waitForPorts(c, s.State, inst0, m0.Id(), []instance.Port{{"tcp", 80}})
+
+	// The port is taken, so only the other one is published.
+	err = u1.OpenPort("tcp", 80)
+	c.Assert(err, IsNil)
+	err = u1.OpenPort("tcp", 443)
+	c.Assert(err, IsNil)
+	waitForPorts(c, s.State, inst1, m1.Id(), []instance.Port{{"tcp", 443}})
+	s.assertPublishedPorts(c, m1, []instance.Port{{"tcp", 443}})
+
+	// Once released, the port is published for the waiting container.
+	err = u0.ClosePort("tcp", 80)
+	c.Assert(err, IsNil)
+	waitForPorts(c, s.State, inst0, m0.Id(), nil)
+	waitForPorts(c, s.State, inst1, m1.Id(), []instance.Port{{"tcp", 80}, {"tcp", 443}})
+	s.assertPublishedPorts(c, m0, nil)
+	s.assertPublishedPorts(c, m1, []instance.Port{{"tcp", 80}, {"tcp", 443}})
+}
+
+func (s *ContainerFirewallerSuite) TestPublishedPortsKeptOnRestart(c *C) {
+	fw, err := firewaller.NewContainerFirewaller(s.State, s.host.Id())
+	c.Assert(err, IsNil)
+
+	svc, err := s.State.AddService("wordpress", s.charm)
+	c.Assert(err, IsNil)
+	err = svc.SetExposed()
+	c.Assert(err, IsNil)
+	u0, m0, inst0 := s.addUnit(c, svc)
+	u1, m1, inst1 := s.addUnit(c, svc)
+	err = u0.OpenPort("tcp", 80)
+	c.Assert(err, IsNil)
+	s.assertPublishedPorts(c, m0, []instance.Port{{"tcp", 80}})
+	c.Assert(fw.Stop(), IsNil)
+
+	// The port stays with the container it was published for, whatever
+	// the order the containers are seen in after a restart.
+	err = u1.OpenPort("tcp", 80)
+	c.Assert(err, IsNil)
+	err = u1.OpenPort("tcp", 443)
+	c.Assert(err, IsNil)
+	fw, err = firewaller.NewContainerFirewaller(s.State, s.host.Id())
+	c.Assert(err, IsNil)
+	defer func() { c.Assert(fw.Stop(), IsNil) }()
+	waitForPorts(c, s.State, inst1, m1.Id(), []instance.Port{{"tcp", 443}})
+	waitForPorts(c, s.State, inst0, m0.Id(), []instance.Port{{"tcp", 80}})
+	s.assertPublishedPorts(c, m1, []instance.Port{{"tcp", 443}})
+
+	err = u0.ClosePort("tcp", 80)
+	c.Assert(err, IsNil)
+	waitForPorts(c, s.State, inst1, m1.Id(), []instance.Port{{"tcp", 80}, {"tcp", 443}})
+	s.assertPublishedPorts(c, m0, nil)
+}
+
+func (s *ContainerFirewallerSuite) TestPublishedPortsReleasedOnRestart(c *C) {
+	fw, err := firewaller.NewContainerFirewaller(s.State, s.host.Id())
+	c.Assert(err, IsNil)
+
+	svc, err := s.State.AddService("wordpress", s.charm)
+	c.Assert(err, IsNil)
+	err = svc.SetExposed()
+	c.Assert(err, IsNil)
+	u0, m0, inst0 := s.addUnit(c, svc)
+	err = u0.OpenPort("tcp", 80)
+	c.Assert(err, IsNil)
+	s.assertPublishedPorts(c, m0, []instance.Port{{"tcp", 80}})
+	c.Assert(fw.Stop(), IsNil)
+
+	// A port closed while the firewaller was stopped is released.
+	err = u0.ClosePort("tcp", 80)
+	c.Assert(err, IsNil)
+	u1, m1, inst1 := s.addUnit(c, svc)
+	err = u1.OpenPort("tcp", 80)
+	c.Assert(err, IsNil)
+	fw, err = firewaller.NewContainerFirewaller(s.State, s.host.Id())
+	c.Assert(err, IsNil)
+	defer func() { c.Assert(fw.Stop(), IsNil) }()
+	waitForPorts(c, s.State, inst0, m0.Id(), nil)
+	waitForPorts(c, s.State, inst1, m1.Id(), []instance.Port{{"tcp", 80}})
+	s.assertPublishedPorts(c, m0, nil)
+	s.assertPublishedPorts(c, m1, []instance.Port{{"tcp", 80}})
+}
+
+func (s *ContainerFirewallerSuite) TestStaleRuleLogged(c *C) {
+	// A rule left behind for a container that is gone keeps its port.
+	s.Iptables.Run("-t", "nat", "-A", "PREROUTING",
+		"-p", "tcp", "-m", "addrtype", "--dst-type", "LOCAL", "--dport", "80",
+		"-m", "comment", "--comment", "juju:juju-machine-0-docker-9",
+		"-j", "DNAT", "--to-destination", "172.17.0.9:80")
+	fw, err := firewaller.NewContainerFirewaller(s.State, s.host.Id())
+	c.Assert(err, IsNil)
+	defer func() { c.Assert(fw.Stop(), IsNil) }()
+
+	svc, err := s.State.AddService("wordpress", s.charm)
+	c.Assert(err, IsNil)
+	err = svc.SetExposed()
+	c.Assert(err, IsNil)
+	u, m, inst := s.addUnit(c, svc)
+	err = u.OpenPort("tcp", 80)
+	c.Assert(err, IsNil)
+	err = u.OpenPort("tcp", 443)
+	c.Assert(err, IsNil)
+	waitForPorts(c, s.State, inst, m.Id(), []instance.Port{{"tcp", 443}})
+	s.assertPublishedPorts(c, m, []instance.Port{{"tcp", 443}})
+}
+
+func (s *ContainerFirewallerSuite) TestIgnoresOtherMachines(c *C) {
+	fw, err := firewaller.NewContainerFirewaller(s.State, s.host.Id())
+	c.Assert(err, IsNil)
+	defer func() { c.Assert(fw.Stop(), IsNil) }()
+
+	svc, err := s.State.AddService("wordpress", s.charm)
+	c.Assert(err, IsNil)
+	err = svc.SetExposed()
+	c.Assert(err, IsNil)
+	m, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	inst, hc := testing.StartInstance(c, s.Conn.Environ, m.Id())
+	err = m.SetProvisioned(inst.Id(), "fake_nonce", hc)
+	c.Assert(err, IsNil)
+	u, err := svc.AddUnit()
+	c.Assert(err, IsNil)
+	err = u.AssignToMachine(m)
+	c.Assert(err, IsNil)
+	err = u.OpenPort("tcp", 80)
+	c.Assert(err, IsNil)
+
+	// Only the environment firewaller opens the ports of machines
+	// that are not docker containers of the host.
+	waitForPorts(c, s.State, inst, m.Id(), nil)
+	c.Assert(s.Iptables.Rules("PREROUTING"), HasLen, 0)
+}
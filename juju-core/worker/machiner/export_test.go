@@ -0,0 +1,18 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machiner
+
+import (
+	"net"
+)
+
+// SetInterfaceAddrs replaces the function used to find the addresses
+// of the network interfaces of the machine.
+func SetInterfaceAddrs(f func() ([]net.Addr, error)) (restore func()) {
+	old := interfaceAddrs
+	interfaceAddrs = f
+	return func() {
+		interfaceAddrs = old
+	}
+}
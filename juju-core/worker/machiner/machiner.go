@@ -3,9 +3,12 @@
 package machiner
 
 import (
+	"net"
+
 	"launchpad.net/loggo"
 
 	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/state/api"
 	"launchpad.net/juju-core/state/api/machiner"
 	"launchpad.net/juju-core/state/api/params"
@@ -14,6 +17,10 @@ import (
 
 var logger = loggo.GetLogger("juju.worker.machiner")
 
+// interfaceAddrs returns the addresses of the network interfaces of
+// the machine; it is a variable so that tests can replace it.
+var interfaceAddrs = net.InterfaceAddrs
+
 // Machiner is responsible for a machine agent's lifecycle.
 type Machiner struct {
 	st      *machiner.State
@@ -50,9 +57,37 @@ func (mr *Machiner) SetUp() (api.NotifyWatcher, error) {
 	}
 	logger.Infof("%q started", mr.tag)
 
+	// Report the addresses of the machine, so that units deployed
+	// to it can be reached by the units they are related to.
+	if err := mr.setMachineAddresses(); err != nil {
+		logger.Errorf("%s failed to set machine addresses: %v", mr, err)
+		return nil, err
+	}
+
 	return m.Watch()
 }
 
+func (mr *Machiner) setMachineAddresses() error {
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		return err
+	}
+	var addresses []instance.Address
+	for _, addr := range addrs {
+		var ip net.IP
+		switch addr := addr.(type) {
+		case *net.IPNet:
+			ip = addr.IP
+		case *net.IPAddr:
+			ip = addr.IP
+		default:
+			continue
+		}
+		addresses = append(addresses, instance.NewScopedAddress(ip.String()))
+	}
+	return mr.machine.SetMachineAddresses(addresses)
+}
+
 func (mr *Machiner) Handle() error {
 	if err := mr.machine.Refresh(); isNotFoundOrUnauthorized(err) {
 		return worker.ErrTerminateAgent
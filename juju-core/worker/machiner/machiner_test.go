@@ -5,6 +5,7 @@ package machiner_test
 
 import (
 	. "launchpad.net/gocheck"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/juju/testing"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api"
@@ -13,6 +14,7 @@ import (
 	coretesting "launchpad.net/juju-core/testing"
 	"launchpad.net/juju-core/worker"
 	"launchpad.net/juju-core/worker/machiner"
+	"net"
 	stdtesting "testing"
 	"time"
 )
@@ -127,3 +129,36 @@ func (s *MachinerSuite) TestSetDead(c *C) {
 	c.Assert(s.machine.Refresh(), IsNil)
 	c.Assert(s.machine.Life(), Equals, state.Dead)
 }
+
+func (s *MachinerSuite) TestMachineAddresses(c *C) {
+	restore := machiner.SetInterfaceAddrs(func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.ParseIP("127.0.0.1")},
+			&net.IPNet{IP: net.ParseIP("10.0.0.2")},
+			&net.IPAddr{IP: net.ParseIP("8.8.8.8")},
+		}, nil
+	})
+	defer restore()
+	mr := machiner.NewMachiner(s.machinerState, s.apiMachine.Tag())
+	defer worker.Stop(mr)
+	s.waitMachineStatus(c, s.machine, params.StatusStarted)
+
+	timeout := time.After(worstCase)
+	for {
+		select {
+		case <-timeout:
+			c.Fatalf("timeout while waiting for machine addresses to be set")
+		case <-time.After(10 * time.Millisecond):
+			c.Assert(s.machine.Refresh(), IsNil)
+			if len(s.machine.MachineAddresses()) == 0 {
+				continue
+			}
+			c.Assert(s.machine.MachineAddresses(), DeepEquals, []instance.Address{
+				instance.NewScopedAddress("127.0.0.1"),
+				instance.NewScopedAddress("10.0.0.2"),
+				instance.NewScopedAddress("8.8.8.8"),
+			})
+			return
+		}
+	}
+}
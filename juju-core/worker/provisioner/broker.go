@@ -4,6 +4,8 @@
 package provisioner
 
 import (
+	"fmt"
+
 	"launchpad.net/juju-core/constraints"
 	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/state"
@@ -26,3 +28,29 @@ type Broker interface {
 	// AllInstances returns all instances currently known to the broker.
 	AllInstances() ([]instance.Instance, error)
 }
+
+// unsupportedBroker is used on machines that cannot host containers of
+// the type provisioned. It fails to start any instance, so that the
+// machines waiting for one are marked in error rather than left pending.
+type unsupportedBroker struct {
+	machineId     string
+	containerType instance.ContainerType
+}
+
+func newUnsupportedBroker(machineId string, containerType instance.ContainerType) Broker {
+	return &unsupportedBroker{machineId, containerType}
+}
+
+func (broker *unsupportedBroker) StartInstance(machineId, machineNonce string, series string, cons constraints.Value, info *state.Info, apiInfo *api.Info) (instance.Instance, *instance.HardwareCharacteristics, error) {
+	return nil, nil, fmt.Errorf("machine %s cannot host %s containers", broker.machineId, broker.containerType)
+}
+
+// StopInstances does nothing, as no instance was ever started.
+func (broker *unsupportedBroker) StopInstances([]instance.Instance) error {
+	return nil
+}
+
+// AllInstances returns no instance.
+func (broker *unsupportedBroker) AllInstances() ([]instance.Instance, error) {
+	return nil, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner
+
+import (
+	"launchpad.net/loggo"
+
+	"launchpad.net/juju-core/agent/tools"
+	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/container/docker"
+	"launchpad.net/juju-core/environs/config"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api"
+)
+
+var dockerLogger = loggo.GetLogger("juju.provisioner.docker")
+
+var _ Broker = (*dockerBroker)(nil)
+
+func NewDockerBroker(config *config.Config, tools *tools.Tools) Broker {
+	return &dockerBroker{
+		manager: docker.NewContainerManager(docker.ManagerConfig{Name: "juju"}),
+		config:  config,
+		tools:   tools,
+	}
+}
+
+type dockerBroker struct {
+	manager docker.ContainerManager
+	config  *config.Config
+	tools   *tools.Tools
+}
+
+func (broker *dockerBroker) StartInstance(machineId, machineNonce string, series string, cons constraints.Value, info *state.Info, apiInfo *api.Info) (instance.Instance, *instance.HardwareCharacteristics, error) {
+	dockerLogger.Infof("starting docker container for machineId: %s", machineId)
+
+	inst, err := broker.manager.StartContainer(machineId, series, machineNonce, broker.tools, broker.config, info, apiInfo)
+	if err != nil {
+		dockerLogger.Errorf("failed to start container: %v", err)
+		return nil, nil, err
+	}
+	dockerLogger.Infof("started docker container for machineId: %s, %s", machineId, inst.Id())
+	return inst, nil, nil
+}
+
+// StopInstances shuts down the given instances.
+func (broker *dockerBroker) StopInstances(instances []instance.Instance) error {
+	for _, instance := range instances {
+		dockerLogger.Infof("stopping docker container for instance: %s", instance.Id())
+		if err := broker.manager.StopContainer(instance); err != nil {
+			dockerLogger.Errorf("container did not stop: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// AllInstances only returns running containers.
+func (broker *dockerBroker) AllInstances() (result []instance.Instance, err error) {
+	return broker.manager.ListContainers()
+}
@@ -0,0 +1,305 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/agent/tools"
+	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/container/docker"
+	dockermock "launchpad.net/juju-core/container/docker/mock"
+	"launchpad.net/juju-core/environs/config"
+	"launchpad.net/juju-core/instance"
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	coretesting "launchpad.net/juju-core/testing"
+	jc "launchpad.net/juju-core/testing/checkers"
+	"launchpad.net/juju-core/version"
+	"launchpad.net/juju-core/worker/provisioner"
+)
+
+type dockerSuite struct {
+	coretesting.LoggingSuite
+	docker.TestSuite
+	events chan dockermock.Event
+}
+
+type dockerBrokerSuite struct {
+	dockerSuite
+	broker provisioner.Broker
+}
+
+var _ = gc.Suite(&dockerBrokerSuite{})
+
+func (s *dockerSuite) SetUpSuite(c *gc.C) {
+	s.LoggingSuite.SetUpSuite(c)
+	s.TestSuite.SetUpSuite(c)
+}
+
+func (s *dockerSuite) TearDownSuite(c *gc.C) {
+	s.TestSuite.TearDownSuite(c)
+	s.LoggingSuite.TearDownSuite(c)
+}
+
+func (s *dockerSuite) SetUpTest(c *gc.C) {
+	s.LoggingSuite.SetUpTest(c)
+	s.TestSuite.SetUpTest(c)
+	s.events = make(chan dockermock.Event)
+	go func() {
+		for event := range s.events {
+			c.Output(3, fmt.Sprintf("docker event: <%s, %s>", event.Action, event.InstanceId))
+		}
+	}()
+	s.TestSuite.Factory.AddListener(s.events)
+}
+
+func (s *dockerSuite) TearDownTest(c *gc.C) {
+	close(s.events)
+	s.TestSuite.TearDownTest(c)
+	s.LoggingSuite.TearDownTest(c)
+}
+
+func (s *dockerBrokerSuite) SetUpTest(c *gc.C) {
+	s.dockerSuite.SetUpTest(c)
+	tools := &tools.Tools{
+		Version: version.MustParseBinary("2.3.4-foo-bar"),
+		URL:     "http://tools.testing.invalid/2.3.4-foo-bar.tgz",
+	}
+	s.AddTools(c, tools)
+	s.broker = provisioner.NewDockerBroker(coretesting.EnvironConfig(c), tools)
+}
+
+func (s *dockerBrokerSuite) startInstance(c *gc.C, machineId string) instance.Instance {
+	stateInfo := jujutesting.FakeStateInfo(machineId)
+	apiInfo := jujutesting.FakeAPIInfo(machineId)
+
+	series := "series"
+	nonce := "fake-nonce"
+	cons := constraints.Value{}
+	inst, _, err := s.broker.StartInstance(machineId, nonce, series, cons, stateInfo, apiInfo)
+	c.Assert(err, gc.IsNil)
+	return inst
+}
+
+func (s *dockerBrokerSuite) TestStartInstance(c *gc.C) {
+	inst := s.startInstance(c, "1/docker/0")
+	c.Assert(inst.Id(), gc.Equals, instance.Id("juju-machine-1-docker-0"))
+	c.Assert(s.dockerContainerDir(inst), jc.IsDirectory)
+	s.assertInstances(c, inst)
+	creation := dockermock.Creation(s.Factory, string(inst.Id()))
+	c.Assert(creation, gc.NotNil)
+	c.Assert(creation.Image, gc.Equals, "ubuntu:series")
+}
+
+func (s *dockerBrokerSuite) TestStopInstance(c *gc.C) {
+	inst0 := s.startInstance(c, "1/docker/0")
+	inst1 := s.startInstance(c, "1/docker/1")
+	inst2 := s.startInstance(c, "1/docker/2")
+
+	err := s.broker.StopInstances([]instance.Instance{inst0})
+	c.Assert(err, gc.IsNil)
+	s.assertInstances(c, inst1, inst2)
+	c.Assert(s.dockerContainerDir(inst0), jc.DoesNotExist)
+	c.Assert(s.dockerRemovedContainerDir(inst0), jc.IsDirectory)
+
+	err = s.broker.StopInstances([]instance.Instance{inst1, inst2})
+	c.Assert(err, gc.IsNil)
+	s.assertInstances(c)
+}
+
+func (s *dockerBrokerSuite) TestAllInstances(c *gc.C) {
+	inst0 := s.startInstance(c, "1/docker/0")
+	inst1 := s.startInstance(c, "1/docker/1")
+	s.assertInstances(c, inst0, inst1)
+
+	err := s.broker.StopInstances([]instance.Instance{inst1})
+	c.Assert(err, gc.IsNil)
+	inst2 := s.startInstance(c, "1/docker/2")
+	s.assertInstances(c, inst0, inst2)
+}
+
+func (s *dockerBrokerSuite) TestAllInstancesIgnoresForeignContainers(c *gc.C) {
+	inst0 := s.startInstance(c, "1/docker/0")
+	foreign := s.Factory.New("some-other-container")
+	c.Assert(foreign.Create("", "ubuntu"), gc.IsNil)
+	c.Assert(foreign.Start("", ""), gc.IsNil)
+	s.assertInstances(c, inst0)
+}
+
+func (s *dockerBrokerSuite) assertInstances(c *gc.C, inst ...instance.Instance) {
+	results, err := s.broker.AllInstances()
+	c.Assert(err, gc.IsNil)
+	coretesting.MatchInstances(c, results, inst...)
+}
+
+func (s *dockerBrokerSuite) dockerContainerDir(inst instance.Instance) string {
+	return filepath.Join(s.ContainerDir, string(inst.Id()))
+}
+
+func (s *dockerBrokerSuite) dockerRemovedContainerDir(inst instance.Instance) string {
+	return filepath.Join(s.RemovedDir, string(inst.Id()))
+}
+
+type dockerProvisionerSuite struct {
+	CommonProvisionerSuite
+	dockerSuite
+	machineId string
+	events    chan dockermock.Event
+}
+
+var _ = gc.Suite(&dockerProvisionerSuite{})
+
+func (s *dockerProvisionerSuite) SetUpSuite(c *gc.C) {
+	s.CommonProvisionerSuite.SetUpSuite(c)
+	s.dockerSuite.SetUpSuite(c)
+}
+
+func (s *dockerProvisionerSuite) TearDownSuite(c *gc.C) {
+	s.dockerSuite.TearDownSuite(c)
+	s.CommonProvisionerSuite.TearDownSuite(c)
+}
+
+func (s *dockerProvisionerSuite) SetUpTest(c *gc.C) {
+	s.CommonProvisionerSuite.SetUpTest(c)
+	s.dockerSuite.SetUpTest(c)
+	// Write the tools file.
+	toolsDir := tools.SharedToolsDir(s.DataDir(), version.Current)
+	c.Assert(os.MkdirAll(toolsDir, 0755), gc.IsNil)
+	urlPath := filepath.Join(toolsDir, "downloaded-url.txt")
+	err := ioutil.WriteFile(urlPath, []byte("http://testing.invalid/tools"), 0644)
+	c.Assert(err, gc.IsNil)
+	// The base images are built from the same tools.
+	s.AddTools(c, &tools.Tools{
+		Version: version.Current,
+		URL:     "http://testing.invalid/tools",
+	})
+
+	// The docker provisioner needs the machine it is being created on
+	// to be in state, in order to get the watcher.
+	m, err := s.State.AddMachine(config.DefaultSeries, state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	s.machineId = m.Id()
+
+	s.events = make(chan dockermock.Event, 25)
+	s.Factory.AddListener(s.events)
+}
+
+func (s *dockerProvisionerSuite) expectStarted(c *gc.C, machine *state.Machine) string {
+	event := <-s.events
+	c.Assert(event.Action, gc.Equals, dockermock.Started)
+	err := machine.Refresh()
+	c.Assert(err, gc.IsNil)
+	s.waitInstanceId(c, machine, instance.Id(event.InstanceId))
+	return event.InstanceId
+}
+
+func (s *dockerProvisionerSuite) expectStopped(c *gc.C, instId string) {
+	event := <-s.events
+	c.Assert(event.Action, gc.Equals, dockermock.Stopped)
+	c.Assert(event.InstanceId, gc.Equals, instId)
+}
+
+func (s *dockerProvisionerSuite) expectNoEvents(c *gc.C) {
+	select {
+	case event := <-s.events:
+		c.Fatalf("unexpected event %#v", event)
+	case <-time.After(coretesting.ShortWait):
+		return
+	}
+}
+
+func (s *dockerProvisionerSuite) TearDownTest(c *gc.C) {
+	close(s.events)
+	s.dockerSuite.TearDownTest(c)
+	s.CommonProvisionerSuite.TearDownTest(c)
+}
+
+func (s *dockerProvisionerSuite) newDockerProvisioner() *provisioner.Provisioner {
+	return provisioner.NewProvisioner(provisioner.DOCKER, s.State, s.machineId, s.DataDir())
+}
+
+func (s *dockerProvisionerSuite) addContainer(c *gc.C, ctype instance.ContainerType) *state.Machine {
+	params := state.AddMachineParams{
+		ParentId:      s.machineId,
+		ContainerType: ctype,
+		Series:        config.DefaultSeries,
+		Jobs:          []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineWithConstraints(&params)
+	c.Assert(err, gc.IsNil)
+	return container
+}
+
+func (s *dockerProvisionerSuite) TestProvisionerStartStop(c *gc.C) {
+	p := s.newDockerProvisioner()
+	c.Assert(p.Stop(), gc.IsNil)
+}
+
+func (s *dockerProvisionerSuite) TestDoesNotStartEnvironMachines(c *gc.C) {
+	p := s.newDockerProvisioner()
+	defer stop(c, p)
+
+	_, err := s.State.AddMachine(config.DefaultSeries, state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+
+	s.expectNoEvents(c)
+}
+
+func (s *dockerProvisionerSuite) TestDoesNotStartLxcContainers(c *gc.C) {
+	p := s.newDockerProvisioner()
+	defer stop(c, p)
+
+	s.addContainer(c, instance.LXC)
+	s.expectNoEvents(c)
+}
+
+func (s *dockerProvisionerSuite) TestUnsupportedHost(c *gc.C) {
+	// The container is added before the host agent finds that docker
+	// is not available.
+	container := s.addContainer(c, instance.DOCKER)
+	host, err := s.State.Machine(s.machineId)
+	c.Assert(err, gc.IsNil)
+	err = host.SetSupportedContainers([]instance.ContainerType{instance.LXC})
+	c.Assert(err, gc.IsNil)
+
+	p := s.newDockerProvisioner()
+	defer stop(c, p)
+	s.expectNoEvents(c)
+
+	t0 := time.Now()
+	for time.Since(t0) < coretesting.LongWait {
+		status, info, err := container.Status()
+		c.Assert(err, gc.IsNil)
+		if status == params.StatusPending {
+			time.Sleep(coretesting.ShortWait)
+			continue
+		}
+		c.Assert(status, gc.Equals, params.StatusError)
+		c.Assert(info, gc.Equals, fmt.Sprintf("machine %s cannot host docker containers", s.machineId))
+		return
+	}
+	c.Fatalf("container not marked in error")
+}
+
+func (s *dockerProvisionerSuite) TestContainerStartedAndStopped(c *gc.C) {
+	p := s.newDockerProvisioner()
+	defer stop(c, p)
+
+	container := s.addContainer(c, instance.DOCKER)
+
+	instId := s.expectStarted(c, container)
+
+	// ...and removed, along with the machine, when the machine is Dead.
+	c.Assert(container.EnsureDead(), gc.IsNil)
+	s.expectStopped(c, instId)
+	s.waitRemoved(c, container)
+}
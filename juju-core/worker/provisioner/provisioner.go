@@ -29,6 +29,8 @@ var (
 	ENVIRON ProvisionerType = "environ"
 	// LXC provisioners create lxc containers on their parent machine
 	LXC ProvisionerType = "lxc"
+	// DOCKER provisioners create docker containers on their parent machine
+	DOCKER ProvisionerType = "docker"
 )
 
 // Provisioner represents a running provisioning worker.
@@ -150,6 +152,12 @@ func (p *Provisioner) getWatcher() (Watcher, error) {
 			return nil, err
 		}
 		return machine.WatchContainers(instance.LXC), nil
+	case DOCKER:
+		machine, err := p.getMachine()
+		if err != nil {
+			return nil, err
+		}
+		return machine.WatchContainers(instance.DOCKER), nil
 	}
 	return nil, fmt.Errorf("unknown provisioner type")
 }
@@ -166,6 +174,22 @@ func (p *Provisioner) getBroker() (Broker, error) {
 			return nil, err
 		}
 		return NewLxcBroker(config, tools), nil
+	case DOCKER:
+		machine, err := p.getMachine()
+		if err != nil {
+			return nil, err
+		}
+		if !machine.SupportsContainerType(instance.DOCKER) {
+			logger.Warningf("machine %s cannot host docker containers", p.machineId)
+			return newUnsupportedBroker(p.machineId, instance.DOCKER), nil
+		}
+		config := p.environ.Config()
+		tools, err := p.getAgentTools()
+		if err != nil {
+			logger.Errorf("cannot get tools from machine for docker broker")
+			return nil, err
+		}
+		return NewDockerBroker(config, tools), nil
 	}
 	return nil, fmt.Errorf("unknown provisioner type")
 }
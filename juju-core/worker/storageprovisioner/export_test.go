@@ -0,0 +1,14 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner
+
+// SetRunCommand replaces the function running the commands that create,
+// mount and unmount the filesystems of filesystem stores.
+func SetRunCommand(f func(name string, args ...string) error) (restore func()) {
+	old := runCommand
+	runCommand = f
+	return func() {
+		runCommand = old
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"launchpad.net/loggo"
+
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/container/lxc"
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api"
+	"launchpad.net/juju-core/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.storageprovisioner")
+
+// StorageProvisioner provisions volumes for the stores of the units
+// deployed to a machine and to its lxc containers, when the environment
+// supports it, and destroys them once their units are removed. The
+// volumes are attached to the machine the provisioner runs on, and the
+// filesystems of the stores of containers are mounted into their root
+// filesystems, since containers cannot set up devices themselves.
+type StorageProvisioner struct {
+	st        *state.State
+	machineId string
+	// all is set when the provisioner serves all the machines of the
+	// environment, which are then containers of its own machine.
+	all     bool
+	machine *state.Machine
+	source  environs.VolumeSource
+}
+
+// NewStorageProvisioner returns a Worker that provisions volumes for the
+// stores of the units deployed to the given machine and to its
+// containers.
+func NewStorageProvisioner(st *state.State, machineId string) worker.Worker {
+	p := &StorageProvisioner{st: st, machineId: machineId}
+	return worker.NewStringsWorker(p)
+}
+
+// NewLocalStorageProvisioner returns a Worker that provisions volumes
+// for the stores of the units of all the machines of a local
+// environment, from the host of the environment.
+func NewLocalStorageProvisioner(st *state.State, machineId string) worker.Worker {
+	p := &StorageProvisioner{st: st, machineId: machineId, all: true}
+	return worker.NewStringsWorker(p)
+}
+
+func (p *StorageProvisioner) SetUp() (api.StringsWatcher, error) {
+	machine, err := p.st.Machine(p.machineId)
+	if err != nil {
+		return nil, err
+	}
+	p.machine = machine
+	cfg, err := p.st.EnvironConfig()
+	if err != nil {
+		return nil, err
+	}
+	env, err := environs.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if source, ok := env.(environs.VolumeSource); ok {
+		p.source = source
+		if err := p.reattach(); err != nil {
+			return nil, err
+		}
+	}
+	return p.st.WatchStorageProvisioning(), nil
+}
+
+func (p *StorageProvisioner) Handle(ids []string) error {
+	for _, id := range ids {
+		store, err := p.st.StorageInstance(id)
+		if errors.IsNotFoundError(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if !p.serves(store.MachineId()) {
+			continue
+		}
+		if p.source == nil {
+			logger.Warningf("environment does not support storage; not provisioning storage instance %q", id)
+			continue
+		}
+		if store.Life() == state.Dead {
+			err = p.destroy(store)
+		} else if !store.Provisioned() {
+			err = p.provision(store)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *StorageProvisioner) TearDown() error {
+	// Nothing to do here.
+	return nil
+}
+
+// serves returns whether the provisioner provisions the stores of the
+// units deployed to the given machine.
+func (p *StorageProvisioner) serves(machineId string) bool {
+	if machineId == "" {
+		return false
+	}
+	return p.all || machineId == p.machineId || state.ParentId(machineId) == p.machineId
+}
+
+// errNotProvisioned is returned by mountPoint when the container of a
+// store has no instance yet. The store is notified again once the
+// container is provisioned.
+var errNotProvisioned = fmt.Errorf("container not provisioned")
+
+// mountPoint returns the path, on the machine of the provisioner, at
+// which the filesystem of the store is mounted.
+func (p *StorageProvisioner) mountPoint(store *state.StorageInstance) (string, error) {
+	if store.MachineId() == p.machineId {
+		return store.Location(), nil
+	}
+	m, err := p.st.Machine(store.MachineId())
+	if err != nil {
+		return "", err
+	}
+	if !p.all && m.ContainerType() != instance.LXC {
+		return "", fmt.Errorf("cannot mount storage into %s containers", m.ContainerType())
+	}
+	instId, err := m.InstanceId()
+	if state.IsNotProvisionedError(err) {
+		return "", errNotProvisioned
+	} else if err != nil {
+		return "", err
+	}
+	return filepath.Join(lxc.RootFS(string(instId)), store.Location()), nil
+}
+
+// provision creates and attaches a volume for the store, prepares it
+// for use by the charm and records it in state.
+func (p *StorageProvisioner) provision(store *state.StorageInstance) error {
+	var target string
+	switch {
+	case store.Kind() == charm.StorageFilesystem:
+		var err error
+		target, err = p.mountPoint(store)
+		if err == errNotProvisioned {
+			logger.Debugf("not provisioning storage instance %q until machine %s is provisioned", store.Id(), store.MachineId())
+			return nil
+		} else if err != nil {
+			logger.Warningf("not provisioning storage instance %q: %v", store.Id(), err)
+			return nil
+		}
+	case store.MachineId() != p.machineId:
+		logger.Warningf("not provisioning storage instance %q: block devices cannot be passed to containers", store.Id())
+		return nil
+	}
+	instId, err := p.machine.InstanceId()
+	if err != nil {
+		return err
+	}
+	volume, err := p.source.CreateVolume(environs.VolumeParams{
+		Name:     store.Id(),
+		Size:     store.Size(),
+		Instance: instId,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot provision storage instance %q: %v", store.Id(), err)
+	}
+	logger.Infof("created volume %q for storage instance %q", volume.Id, store.Id())
+	if target != "" {
+		err = makeFilesystem(volume.Device)
+		if err == nil {
+			err = mount(volume.Device, target)
+		}
+	}
+	if err == nil {
+		if err = store.SetProvisioned(volume.Id, volume.Device); err != nil && target != "" {
+			unmount(target)
+		}
+	}
+	if err != nil {
+		if err := p.source.DestroyVolume(volume.Id); err != nil {
+			logger.Errorf("cannot destroy volume %q: %v", volume.Id, err)
+		}
+		return fmt.Errorf("cannot provision storage instance %q: %v", store.Id(), err)
+	}
+	return nil
+}
+
+// destroy unmounts and destroys the volume of the dead store, and
+// removes the store. A volume that cannot be destroyed is logged, and
+// destroyed again when the provisioner next starts.
+func (p *StorageProvisioner) destroy(store *state.StorageInstance) error {
+	if store.Kind() == charm.StorageFilesystem && store.Device() != "" {
+		unmount(store.Device())
+	}
+	if err := p.source.DestroyVolume(store.VolumeId()); err != nil {
+		logger.Errorf("cannot destroy volume %q of storage instance %q: %v", store.VolumeId(), store.Id(), err)
+		return nil
+	}
+	logger.Infof("destroyed volume %q of storage instance %q", store.VolumeId(), store.Id())
+	return store.Remove()
+}
+
+// reattach attaches the volumes of the provisioned stores again and
+// mounts their filesystems, which do not survive a reboot of the
+// machine.
+func (p *StorageProvisioner) reattach() error {
+	stores, err := p.st.StorageInstances()
+	if err != nil {
+		return err
+	}
+	instId, err := p.machine.InstanceId()
+	if err != nil {
+		return err
+	}
+	for _, store := range stores {
+		if store.Life() != state.Alive || !store.Provisioned() || !p.serves(store.MachineId()) {
+			continue
+		}
+		device, err := p.source.AttachVolume(store.VolumeId(), instId)
+		if err != nil {
+			logger.Errorf("cannot attach volume of storage instance %q: %v", store.Id(), err)
+			continue
+		}
+		if device != store.Device() {
+			if err := store.SetDevice(device); err != nil {
+				return err
+			}
+		}
+		if store.Kind() != charm.StorageFilesystem {
+			continue
+		}
+		target, err := p.mountPoint(store)
+		if err != nil {
+			logger.Errorf("cannot mount storage instance %q: %v", store.Id(), err)
+			continue
+		}
+		if runCommand("mountpoint", "-q", target) == nil {
+			continue
+		}
+		if err := mount(device, target); err != nil {
+			logger.Errorf("cannot mount storage instance %q: %v", store.Id(), err)
+		}
+	}
+	return nil
+}
+
+// runCommand runs the named command, returning its output in the error
+// when it fails. It is a variable so that tests can avoid touching the
+// host's devices and mounts.
+var runCommand = func(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		if output := strings.TrimSpace(string(out)); output != "" {
+			err = fmt.Errorf("%v (%s)", err, output)
+		}
+		return fmt.Errorf("%s failed: %v", name, err)
+	}
+	return nil
+}
+
+func makeFilesystem(device string) error {
+	return runCommand("mkfs.ext4", "-q", device)
+}
+
+func mount(device, target string) error {
+	if err := runCommand("mkdir", "-p", target); err != nil {
+		return err
+	}
+	return runCommand("mount", device, target)
+}
+
+// unmount unmounts the given device or mount point, logging failures.
+func unmount(path string) {
+	if err := runCommand("umount", path); err != nil {
+		logger.Warningf("cannot unmount %s: %v", path, err)
+	}
+}
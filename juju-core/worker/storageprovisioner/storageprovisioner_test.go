@@ -0,0 +1,236 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storageprovisioner_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	stdtesting "testing"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/container/lxc"
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	coretesting "launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker"
+	"launchpad.net/juju-core/worker/storageprovisioner"
+)
+
+func TestPackage(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type storageProvisionerSuite struct {
+	testing.JujuConnSuite
+	machine  *state.Machine
+	instId   instance.Id
+	mu       sync.Mutex
+	commands []string
+	mounted  map[string]bool
+	restore  func()
+}
+
+var _ = gc.Suite(&storageProvisionerSuite{})
+
+var _ worker.StringsWatchHandler = (*storageprovisioner.StorageProvisioner)(nil)
+
+func (s *storageProvisionerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.commands = nil
+	s.mounted = make(map[string]bool)
+	s.restore = storageprovisioner.SetRunCommand(s.runCommand)
+	var err error
+	s.machine, err = s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, gc.IsNil)
+	inst, _ := testing.StartInstance(c, s.Conn.Environ, s.machine.Id())
+	s.instId = inst.Id()
+	err = s.machine.SetProvisioned(inst.Id(), "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *storageProvisionerSuite) TearDownTest(c *gc.C) {
+	s.restore()
+	s.JujuConnSuite.TearDownTest(c)
+}
+
+// runCommand records the commands run by the worker, and keeps track of
+// the mount points.
+func (s *storageProvisionerSuite) runCommand(name string, args ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = append(s.commands, name+" "+strings.Join(args, " "))
+	switch name {
+	case "mount":
+		s.mounted[args[1]] = true
+	case "mountpoint":
+		if !s.mounted[args[1]] {
+			return fmt.Errorf("mountpoint failed: exit status 1")
+		}
+	}
+	return nil
+}
+
+func (s *storageProvisionerSuite) resetCommands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	commands := s.commands
+	s.commands = nil
+	return commands
+}
+
+// addUnit adds a unit of a charm declaring a filesystem store and a
+// block store, assigned to the given machine.
+func (s *storageProvisionerSuite) addUnit(c *gc.C, machine *state.Machine) *state.Unit {
+	svc, err := s.State.Service("storage")
+	if errors.IsNotFoundError(err) {
+		svc, err = s.State.AddService("storage", s.AddTestingCharm(c, "storage"))
+	}
+	c.Assert(err, gc.IsNil)
+	unit, err := svc.AddUnit()
+	c.Assert(err, gc.IsNil)
+	err = unit.AssignToMachine(machine)
+	c.Assert(err, gc.IsNil)
+	return unit
+}
+
+// waitProvisioned waits for the storage instance to be provisioned.
+func (s *storageProvisionerSuite) waitProvisioned(c *gc.C, id string) *state.StorageInstance {
+	timeout := time.After(coretesting.LongWait)
+	for {
+		s.State.StartSync()
+		select {
+		case <-time.After(coretesting.ShortWait):
+			store, err := s.State.StorageInstance(id)
+			c.Assert(err, gc.IsNil)
+			if store.Provisioned() {
+				return store
+			}
+		case <-timeout:
+			c.Fatalf("storage instance %q not provisioned", id)
+		}
+	}
+}
+
+// waitRemoved waits for the storage instance to be removed.
+func (s *storageProvisionerSuite) waitRemoved(c *gc.C, id string) {
+	timeout := time.After(coretesting.LongWait)
+	for {
+		s.State.StartSync()
+		select {
+		case <-time.After(coretesting.ShortWait):
+			_, err := s.State.StorageInstance(id)
+			if errors.IsNotFoundError(err) {
+				return
+			}
+			c.Assert(err, gc.IsNil)
+		case <-timeout:
+			c.Fatalf("storage instance %q not removed", id)
+		}
+	}
+}
+
+func (s *storageProvisionerSuite) assertVolumeDestroyed(c *gc.C, volumeId string) {
+	source := s.Conn.Environ.(environs.VolumeSource)
+	_, err := source.AttachVolume(volumeId, s.instId)
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf("volume %q not found", volumeId))
+}
+
+func (s *storageProvisionerSuite) TestProvision(c *gc.C) {
+	w := storageprovisioner.NewStorageProvisioner(s.State, s.machine.Id())
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+	s.addUnit(c, s.machine)
+
+	data := s.waitProvisioned(c, "data/0")
+	disks := s.waitProvisioned(c, "disks/0")
+	c.Assert(data.VolumeId(), gc.Not(gc.Equals), disks.VolumeId())
+	c.Assert(data.Device(), gc.Matches, "/dev/dummy-[0-9]+")
+	c.Assert(disks.Device(), gc.Matches, "/dev/dummy-[0-9]+")
+
+	// Only the filesystem store is formatted and mounted.
+	c.Assert(s.resetCommands(), gc.DeepEquals, []string{
+		"mkfs.ext4 -q " + data.Device(),
+		"mkdir -p /srv/data",
+		"mount " + data.Device() + " /srv/data",
+	})
+}
+
+func (s *storageProvisionerSuite) TestDestroyOnUnitRemoval(c *gc.C) {
+	w := storageprovisioner.NewStorageProvisioner(s.State, s.machine.Id())
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+	unit := s.addUnit(c, s.machine)
+	data := s.waitProvisioned(c, "data/0")
+	disks := s.waitProvisioned(c, "disks/0")
+	s.resetCommands()
+
+	err := unit.EnsureDead()
+	c.Assert(err, gc.IsNil)
+	err = unit.Remove()
+	c.Assert(err, gc.IsNil)
+	s.waitRemoved(c, "data/0")
+	s.waitRemoved(c, "disks/0")
+	s.assertVolumeDestroyed(c, data.VolumeId())
+	s.assertVolumeDestroyed(c, disks.VolumeId())
+	c.Assert(s.resetCommands(), gc.DeepEquals, []string{"umount " + data.Device()})
+}
+
+func (s *storageProvisionerSuite) TestContainer(c *gc.C) {
+	w := storageprovisioner.NewStorageProvisioner(s.State, s.machine.Id())
+	defer func() { c.Assert(worker.Stop(w), gc.IsNil) }()
+	params := state.AddMachineParams{
+		ParentId:      s.machine.Id(),
+		ContainerType: instance.LXC,
+		Series:        "series",
+		Jobs:          []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineWithConstraints(&params)
+	c.Assert(err, gc.IsNil)
+	s.addUnit(c, container)
+
+	// The store is provisioned once the container is, and its
+	// filesystem is mounted into the root filesystem of the container.
+	err = container.SetProvisioned("juju-machine-0-lxc-0", "fake_nonce", nil)
+	c.Assert(err, gc.IsNil)
+	data := s.waitProvisioned(c, "data/0")
+	location := lxc.RootFS("juju-machine-0-lxc-0") + "/srv/data"
+	c.Assert(s.resetCommands(), gc.DeepEquals, []string{
+		"mkfs.ext4 -q " + data.Device(),
+		"mkdir -p " + location,
+		"mount " + data.Device() + " " + location,
+	})
+
+	// Block devices cannot be passed to containers.
+	disks, err := s.State.StorageInstance("disks/0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(disks.Provisioned(), gc.Equals, false)
+}
+
+func (s *storageProvisionerSuite) TestReattachOnStart(c *gc.C) {
+	w := storageprovisioner.NewStorageProvisioner(s.State, s.machine.Id())
+	s.addUnit(c, s.machine)
+	data := s.waitProvisioned(c, "data/0")
+	s.waitProvisioned(c, "disks/0")
+	c.Assert(worker.Stop(w), gc.IsNil)
+
+	// Mounted filesystems are left alone.
+	s.resetCommands()
+	w = storageprovisioner.NewStorageProvisioner(s.State, s.machine.Id())
+	c.Assert(worker.Stop(w), gc.IsNil)
+	c.Assert(s.resetCommands(), gc.DeepEquals, []string{"mountpoint -q /srv/data"})
+
+	// The filesystems are mounted again after a reboot.
+	s.mounted = make(map[string]bool)
+	w = storageprovisioner.NewStorageProvisioner(s.State, s.machine.Id())
+	c.Assert(worker.Stop(w), gc.IsNil)
+	c.Assert(s.resetCommands(), gc.DeepEquals, []string{
+		"mountpoint -q /srv/data",
+		"mkdir -p /srv/data",
+		"mount " + data.Device() + " /srv/data",
+	})
+}
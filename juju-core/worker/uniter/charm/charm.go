@@ -11,14 +11,21 @@ import (
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/downloader"
 	"launchpad.net/juju-core/log"
-	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/utils"
+	"net/url"
 	"os"
 	"path"
 )
 
+// BundleInfo holds bundle information for a charm.
+type BundleInfo interface {
+	URL() *charm.URL
+	BundleURL() *url.URL
+	BundleSha256() string
+}
+
 // BundlesDir is responsible for storing and retrieving charm bundles
-// identified by state charms.
+// identified by BundleInfo values.
 type BundlesDir struct {
 	path string
 }
@@ -31,7 +38,7 @@ func NewBundlesDir(path string) *BundlesDir {
 // Read returns a charm bundle from the directory. If no bundle exists yet,
 // one will be downloaded and validated and copied into the directory before
 // being returned. Downloads will be aborted if a value is received on abort.
-func (d *BundlesDir) Read(sch *state.Charm, abort <-chan struct{}) (*charm.Bundle, error) {
+func (d *BundlesDir) Read(sch BundleInfo, abort <-chan struct{}) (*charm.Bundle, error) {
 	path := d.bundlePath(sch)
 	if _, err := os.Stat(path); err != nil {
 		if !os.IsNotExist(err) {
@@ -46,15 +53,21 @@ func (d *BundlesDir) Read(sch *state.Charm, abort <-chan struct{}) (*charm.Bundl
 // download fetches the supplied charm and checks that it has the correct sha256
 // hash, then copies it into the directory. If a value is received on abort, the
 // download will be stopped.
-func (d *BundlesDir) download(sch *state.Charm, abort <-chan struct{}) (err error) {
+func (d *BundlesDir) download(sch BundleInfo, abort <-chan struct{}) (err error) {
 	defer utils.ErrorContextf(&err, "failed to download charm %q from %q", sch.URL(), sch.BundleURL())
-	dir := d.downloadsPath()
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
 	burl := sch.BundleURL().String()
 	log.Infof("worker/uniter/charm: downloading %s from %s", sch.URL(), burl)
-	dl := downloader.New(burl, dir)
+	return downloadVerified(burl, sch.BundleSha256(), d.downloadsPath(), d.bundlePath(sch), abort)
+}
+
+// downloadVerified downloads the content at source into downloadsDir,
+// checks that it has the expected sha256 hash, and renames it to target.
+// If a value is received on abort, the download will be stopped.
+func downloadVerified(source, expectSha256, downloadsDir, target string, abort <-chan struct{}) error {
+	if err := os.MkdirAll(downloadsDir, 0755); err != nil {
+		return err
+	}
+	dl := downloader.New(source, downloadsDir)
 	defer dl.Stop()
 	for {
 		select {
@@ -68,27 +81,27 @@ func (d *BundlesDir) download(sch *state.Charm, abort <-chan struct{}) (err erro
 			log.Infof("worker/uniter/charm: download complete")
 			defer st.File.Close()
 			hash := sha256.New()
-			if _, err = io.Copy(hash, st.File); err != nil {
+			if _, err := io.Copy(hash, st.File); err != nil {
 				return err
 			}
 			actualSha256 := hex.EncodeToString(hash.Sum(nil))
-			if actualSha256 != sch.BundleSha256() {
+			if actualSha256 != expectSha256 {
 				return fmt.Errorf(
-					"expected sha256 %q, got %q", sch.BundleSha256(), actualSha256,
+					"expected sha256 %q, got %q", expectSha256, actualSha256,
 				)
 			}
 			log.Infof("worker/uniter/charm: download verified")
-			if err := os.MkdirAll(d.path, 0755); err != nil {
+			if err := os.MkdirAll(path.Dir(target), 0755); err != nil {
 				return err
 			}
-			return os.Rename(st.File.Name(), d.bundlePath(sch))
+			return os.Rename(st.File.Name(), target)
 		}
 	}
 }
 
 // bundlePath returns the path to the location where the verified charm
 // bundle identified by sch will be, or has been, saved.
-func (d *BundlesDir) bundlePath(sch *state.Charm) string {
+func (d *BundlesDir) bundlePath(sch BundleInfo) string {
 	return path.Join(d.path, charm.Quote(sch.URL().String()))
 }
 
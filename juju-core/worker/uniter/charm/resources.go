@@ -0,0 +1,72 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+
+	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/utils"
+)
+
+// ResourceInfo holds information about the content uploaded for a
+// resource of a service.
+type ResourceInfo interface {
+	Name() string
+	Revision() int
+	URL() *url.URL
+	Sha256() string
+}
+
+// ResourcesDir is responsible for storing and retrieving the content
+// of resources identified by ResourceInfo values.
+type ResourcesDir struct {
+	path string
+}
+
+// NewResourcesDir returns a new ResourcesDir which uses path for storage.
+func NewResourcesDir(path string) *ResourcesDir {
+	return &ResourcesDir{path}
+}
+
+// Read returns the path to the content of a resource in the directory,
+// saved under the given filename. If the content of that revision of the
+// resource is not there yet, it is downloaded and validated and copied
+// into the directory first. Downloads will be aborted if a value is
+// received on abort.
+func (d *ResourcesDir) Read(res ResourceInfo, filename string, abort <-chan struct{}) (string, error) {
+	path := d.resourcePath(res, filename)
+	if _, err := os.Stat(path); err != nil {
+		if !os.IsNotExist(err) {
+			return "", err
+		} else if err = d.download(res, path, abort); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
+
+// download fetches the content of the supplied resource and checks that
+// it has the correct sha256 hash, then copies it to path.
+func (d *ResourcesDir) download(res ResourceInfo, path string, abort <-chan struct{}) (err error) {
+	defer utils.ErrorContextf(&err, "failed to download resource %q from %q", res.Name(), res.URL())
+	rurl := res.URL().String()
+	log.Infof("worker/uniter/charm: downloading resource %s revision %d from %s", res.Name(), res.Revision(), rurl)
+	return downloadVerified(rurl, res.Sha256(), d.downloadsPath(), path, abort)
+}
+
+// resourcePath returns the path to the location where the verified
+// content of the resource will be, or has been, saved.
+func (d *ResourcesDir) resourcePath(res ResourceInfo, filename string) string {
+	return path.Join(d.path, "content", res.Name(), fmt.Sprint(res.Revision()), filename)
+}
+
+// downloadsPath returns the path to the directory into which resources
+// are downloaded.
+func (d *ResourcesDir) downloadsPath() string {
+	return path.Join(d.path, "downloads")
+}
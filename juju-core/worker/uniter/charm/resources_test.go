@@ -0,0 +1,73 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+
+	. "launchpad.net/gocheck"
+
+	coretesting "launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/charm"
+)
+
+type ResourcesDirSuite struct {
+	coretesting.HTTPSuite
+}
+
+var _ = Suite(&ResourcesDirSuite{})
+
+type resourceInfo struct {
+	name     string
+	revision int
+	url      *url.URL
+	sha256   string
+}
+
+func (r resourceInfo) Name() string   { return r.name }
+func (r resourceInfo) Revision() int  { return r.revision }
+func (r resourceInfo) URL() *url.URL  { return r.url }
+func (r resourceInfo) Sha256() string { return r.sha256 }
+
+func (s *ResourcesDirSuite) TestRead(c *C) {
+	resdir := filepath.Join(c.MkDir(), "resources")
+	d := charm.NewResourcesDir(resdir)
+	path := filepath.Join(c.MkDir(), "jdk")
+	err := ioutil.WriteFile(path, []byte("jdk content"), 0644)
+	c.Assert(err, IsNil)
+	data, hash := readHash(c, path)
+	rurl, err := url.Parse(s.URL("/some/resource"))
+	c.Assert(err, IsNil)
+	res := resourceInfo{"jdk", 3, rurl, hash}
+
+	// Try to get the resource when the content doesn't match.
+	coretesting.Server.Response(200, nil, []byte("roflcopter"))
+	_, err = d.Read(res, "jdk.tar.gz", nil)
+	prefix := fmt.Sprintf(`failed to download resource "jdk" from %q: `, rurl)
+	c.Assert(err, ErrorMatches, prefix+fmt.Sprintf(`expected sha256 %q, got ".*"`, hash))
+
+	// Get the resource when its content matches.
+	coretesting.Server.Response(200, nil, data)
+	local, err := d.Read(res, "jdk.tar.gz", nil)
+	c.Assert(err, IsNil)
+	c.Assert(local, Equals, filepath.Join(resdir, "content", "jdk", "3", "jdk.tar.gz"))
+	content, err := ioutil.ReadFile(local)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "jdk content")
+
+	// Get the same resource again, without preparing a response from
+	// the server.
+	local, err = d.Read(res, "jdk.tar.gz", nil)
+	c.Assert(err, IsNil)
+	c.Assert(local, Equals, filepath.Join(resdir, "content", "jdk", "3", "jdk.tar.gz"))
+
+	// A new revision of the resource is downloaded again.
+	res.revision = 4
+	coretesting.Server.Response(404, nil, nil)
+	_, err = d.Read(res, "jdk.tar.gz", nil)
+	c.Assert(err, ErrorMatches, prefix+`.* 404 Not Found`)
+}
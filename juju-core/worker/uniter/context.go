@@ -5,10 +5,14 @@ package uniter
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"launchpad.net/juju-core/charm"
-	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/api/uniter"
+	ucharm "launchpad.net/juju-core/worker/uniter/charm"
 	unitdebug "launchpad.net/juju-core/worker/uniter/debug"
 	"launchpad.net/juju-core/worker/uniter/jujuc"
 	"os"
@@ -17,12 +21,21 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
 // HookContext is the implementation of jujuc.Context.
 type HookContext struct {
-	unit *state.Unit
+	unit *uniter.Unit
+
+	// publicAddress and privateAddress hold the addresses of the unit,
+	// and whether they are set, when the context was created.
+	publicAddress    string
+	hasPublicAddress bool
+
+	privateAddress    string
+	hasPrivateAddress bool
 
 	// configSettings holds the service configuration.
 	configSettings charm.Settings
@@ -49,12 +62,33 @@ type HookContext struct {
 
 	// apiAddrs contains the API server addresses.
 	apiAddrs []string
+
+	// action is the action being run, if the context is running one.
+	action *uniter.Action
+
+	// actionResults holds the results recorded by the action being run.
+	actionResults map[string]interface{}
+
+	// storageId identifies the storage instance for which a storage hook
+	// is executing. It is empty if the context is not running a storage
+	// hook.
+	storageId string
+
+	// charmDir is the directory the charm of the unit is deployed to.
+	charmDir string
+
+	// hookTimeout is the time a hook may run for before it is killed.
+	// If it is zero, hooks may run for as long as they need.
+	hookTimeout time.Duration
+
+	// resources holds the local copies of the resources of the charm.
+	resources *ucharm.ResourcesDir
 }
 
-func NewHookContext(unit *state.Unit, id, uuid string, relationId int,
+func NewHookContext(unit *uniter.Unit, id, uuid string, relationId int,
 	remoteUnitName string, relations map[int]*ContextRelation,
-	apiAddrs []string) *HookContext {
-	return &HookContext{
+	apiAddrs []string) (*HookContext, error) {
+	ctx := &HookContext{
 		unit:           unit,
 		id:             id,
 		uuid:           uuid,
@@ -63,6 +97,18 @@ func NewHookContext(unit *state.Unit, id, uuid string, relationId int,
 		relations:      relations,
 		apiAddrs:       apiAddrs,
 	}
+	// Get and cache the addresses of the unit, so the hook tools can
+	// report them without a round trip to the API server.
+	var err error
+	ctx.publicAddress, ctx.hasPublicAddress, err = unit.PublicAddress()
+	if err != nil {
+		return nil, err
+	}
+	ctx.privateAddress, ctx.hasPrivateAddress, err = unit.PrivateAddress()
+	if err != nil {
+		return nil, err
+	}
+	return ctx, nil
 }
 
 func (ctx *HookContext) UnitName() string {
@@ -70,11 +116,11 @@ func (ctx *HookContext) UnitName() string {
 }
 
 func (ctx *HookContext) PublicAddress() (string, bool) {
-	return ctx.unit.PublicAddress()
+	return ctx.publicAddress, ctx.hasPublicAddress
 }
 
 func (ctx *HookContext) PrivateAddress() (string, bool) {
-	return ctx.unit.PrivateAddress()
+	return ctx.privateAddress, ctx.hasPrivateAddress
 }
 
 func (ctx *HookContext) OpenPort(protocol string, port int) error {
@@ -121,6 +167,95 @@ func (ctx *HookContext) RelationIds() []int {
 	return ids
 }
 
+func (ctx *HookContext) WorkloadStatus() (params.WorkloadStatus, string, error) {
+	return ctx.unit.WorkloadStatus()
+}
+
+func (ctx *HookContext) SetWorkloadStatus(status params.WorkloadStatus, info string) error {
+	return ctx.unit.SetWorkloadStatus(status, info)
+}
+
+func (ctx *HookContext) IsLeader() (bool, error) {
+	return ctx.unit.IsLeader()
+}
+
+func (ctx *HookContext) LeaderSettings() (map[string]string, error) {
+	service, err := ctx.unit.Service()
+	if err != nil {
+		return nil, err
+	}
+	return service.LeaderSettings()
+}
+
+func (ctx *HookContext) WriteLeaderSettings(settings map[string]string) error {
+	return ctx.unit.SetLeaderSettings(settings)
+}
+
+func (ctx *HookContext) ActionParams() (map[string]interface{}, error) {
+	if ctx.action == nil {
+		return nil, fmt.Errorf("not running an action")
+	}
+	params := map[string]interface{}{}
+	for name, value := range ctx.action.Params() {
+		params[name] = value
+	}
+	return params, nil
+}
+
+func (ctx *HookContext) UpdateActionResults(results map[string]interface{}) error {
+	if ctx.action == nil {
+		return fmt.Errorf("not running an action")
+	}
+	if ctx.actionResults == nil {
+		ctx.actionResults = map[string]interface{}{}
+	}
+	for name, value := range results {
+		ctx.actionResults[name] = value
+	}
+	return nil
+}
+
+func (ctx *HookContext) HookStorageId() (string, bool) {
+	return ctx.storageId, ctx.storageId != ""
+}
+
+func (ctx *HookContext) Storage(id string) (jujuc.ContextStorage, error) {
+	stores, err := ctx.unit.StorageInstances()
+	if err != nil {
+		return nil, err
+	}
+	for _, store := range stores {
+		if store.Id() == id {
+			return store, nil
+		}
+	}
+	return nil, fmt.Errorf("storage instance %q not found", id)
+}
+
+func (ctx *HookContext) Resource(name string) (string, error) {
+	ch, err := charm.ReadDir(ctx.charmDir)
+	if err != nil {
+		return "", err
+	}
+	declared, ok := ch.Meta().Resources[name]
+	if !ok {
+		return "", fmt.Errorf("charm declares no resource %q", name)
+	}
+	service, err := ctx.unit.Service()
+	if err != nil {
+		return "", err
+	}
+	res, err := service.Resource(name)
+	if err != nil {
+		return "", err
+	}
+	return ctx.resources.Read(res, declared.Filename, nil)
+}
+
+func (ctx *HookContext) NetworkAddresses() ([]instance.Address, error) {
+	return ctx.unit.NetworkAddresses()
+}
+
 // hookVars returns an os.Environ-style list of strings necessary to run a hook
 // such that it can know what environment it's operating in, and can call back
 // into ctx.
@@ -142,6 +277,13 @@ func (ctx *HookContext) hookVars(charmDir, toolsDir, socketPath string) []string
 		name, _ := ctx.RemoteUnitName()
 		vars = append(vars, "JUJU_REMOTE_UNIT="+name)
 	}
+	if ctx.action != nil {
+		vars = append(vars, "JUJU_ACTION_NAME="+ctx.action.Name())
+		vars = append(vars, "JUJU_ACTION_ID="+ctx.action.Id())
+	}
+	if ctx.storageId != "" {
+		vars = append(vars, "JUJU_STORAGE_ID="+ctx.storageId)
+	}
 	return vars
 }
 
@@ -155,15 +297,65 @@ func (ctx *HookContext) RunHook(hookName, charmDir, toolsDir, socketPath string)
 		logger.Infof("executing %s via debug-hooks", hookName)
 		err = session.RunHook(hookName, charmDir, env)
 	} else {
-		err = runCharmHook(hookName, charmDir, env)
+		err = runCharmHook(hookName, charmDir, env, ctx.hookTimeout)
+	}
+	return ctx.finalizeContext(hookName, err)
+}
+
+// RunAction executes the named action of the charm in an environment
+// which allows it to call back into ctx to execute jujuc tools, as a
+// hook would. Unlike a missing hook, a missing action is an error.
+func (ctx *HookContext) RunAction(actionName, charmDir, toolsDir, socketPath string) error {
+	env := ctx.hookVars(charmDir, toolsDir, socketPath)
+	err := runCharmProcess(filepath.Join(charmDir, "actions", actionName), charmDir, env, 0)
+	if ee, ok := err.(*exec.Error); ok && os.IsNotExist(ee.Err) {
+		err = fmt.Errorf("action %q not implemented", actionName)
 	}
+	return ctx.finalizeContext(actionName, err)
+}
+
+// RunCommands executes the commands in an environment which allows them
+// to call back into ctx to execute jujuc tools, as a hook would. A
+// non-zero exit code is reported in the result and does not cause an
+// error, but the relation settings changed by the commands are only
+// written when they succeed.
+func (ctx *HookContext) RunCommands(commands, charmDir, toolsDir, socketPath string) (*RunResult, error) {
+	env := ctx.hookVars(charmDir, toolsDir, socketPath)
+	ps := exec.Command("/bin/bash", "-s")
+	ps.Env = env
+	ps.Dir = charmDir
+	ps.Stdin = strings.NewReader(commands)
+	var stdout, stderr bytes.Buffer
+	ps.Stdout = &stdout
+	ps.Stderr = &stderr
+	err := ps.Run()
+	result := &RunResult{}
+	if ee, ok := err.(*exec.ExitError); ok {
+		result.Code = ee.Sys().(syscall.WaitStatus).ExitStatus()
+	} else if err != nil {
+		return nil, err
+	}
+	// A failure of the commands is not an error of the context, but
+	// writing the settings they changed is.
+	if err := ctx.finalizeContext("run commands", err); err != nil && result.Code == 0 {
+		return nil, err
+	}
+	result.Stdout = stdout.Bytes()
+	result.Stderr = stderr.Bytes()
+	return result, nil
+}
+
+// finalizeContext writes the relation settings changed by process, if
+// it succeeded, and clears the relation caches of ctx. It returns the
+// error of the process, if any, or else any error writing the settings.
+func (ctx *HookContext) finalizeContext(process string, err error) error {
 	write := err == nil
 	for id, rctx := range ctx.relations {
 		if write {
 			if e := rctx.WriteSettings(); e != nil {
 				e = fmt.Errorf(
 					"could not write settings from %q to relation %d: %v",
-					hookName, id, e,
+					process, id, e,
 				)
 				logger.Errorf("%v", e)
 				if err == nil {
@@ -176,10 +368,28 @@ func (ctx *HookContext) RunHook(hookName, charmDir, toolsDir, socketPath string)
 	return err
 }
 
-func runCharmHook(hookName, charmDir string, env []string) error {
-	ps := exec.Command(filepath.Join(charmDir, "hooks", hookName))
+func runCharmHook(hookName, charmDir string, env []string, timeout time.Duration) error {
+	err := runCharmProcess(filepath.Join(charmDir, "hooks", hookName), charmDir, env, timeout)
+	if ee, ok := err.(*exec.Error); ok && err != nil {
+		if os.IsNotExist(ee.Err) {
+			// Missing hook is perfectly valid, but worth mentioning.
+			logger.Infof("skipped %q hook (not implemented)", hookName)
+			return nil
+		}
+	}
+	return err
+}
+
+// runCharmProcess runs the executable at path, which is part of the charm,
+// logging its output. If timeout is not zero and the process has not
+// exited by then, it is killed along with any process it started.
+func runCharmProcess(path, charmDir string, env []string, timeout time.Duration) error {
+	ps := exec.Command(path)
 	ps.Env = env
 	ps.Dir = charmDir
+	// Run the process in its own process group, so that it can be
+	// killed together with its children.
+	ps.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 	outReader, outWriter, err := os.Pipe()
 	if err != nil {
 		return fmt.Errorf("cannot make logging pipe: %v", err)
@@ -194,19 +404,35 @@ func runCharmHook(hookName, charmDir string, env []string) error {
 	err = ps.Start()
 	outWriter.Close()
 	if err == nil {
-		err = ps.Wait()
+		err = waitCharmProcess(ps, timeout)
 	}
 	hookLogger.stop()
-	if ee, ok := err.(*exec.Error); ok && err != nil {
-		if os.IsNotExist(ee.Err) {
-			// Missing hook is perfectly valid, but worth mentioning.
-			logger.Infof("skipped %q hook (not implemented)", hookName)
-			return nil
-		}
-	}
 	return err
 }
 
+// waitCharmProcess waits for the started process ps to exit. If timeout
+// is not zero and ps is still running by then, the process group of ps
+// is killed and an error is returned.
+func waitCharmProcess(ps *exec.Cmd, timeout time.Duration) error {
+	if timeout == 0 {
+		return ps.Wait()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- ps.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+	}
+	if err := syscall.Kill(-ps.Process.Pid, syscall.SIGKILL); err != nil {
+		logger.Errorf("cannot kill process group of %q: %v", ps.Path, err)
+	}
+	<-done
+	return fmt.Errorf("timed out after %v", timeout)
+}
+
 type hookLogger struct {
 	r       io.ReadCloser
 	done    chan struct{}
@@ -258,14 +484,14 @@ type SettingsMap map[string]map[string]interface{}
 
 // ContextRelation is the implementation of jujuc.ContextRelation.
 type ContextRelation struct {
-	ru *state.RelationUnit
+	ru *uniter.RelationUnit
 
 	// members contains settings for known relation members. Nil values
 	// indicate members whose settings have not yet been cached.
 	members SettingsMap
 
 	// settings allows read and write access to the relation unit settings.
-	settings *state.Settings
+	settings *uniter.Settings
 
 	// cache is a short-term cache that enables consistent access to settings
 	// for units that are not currently participating in the relation. Its
@@ -275,7 +501,7 @@ type ContextRelation struct {
 
 // NewContextRelation creates a new context for the given relation unit.
 // The unit-name keys of members supplies the initial membership.
-func NewContextRelation(ru *state.RelationUnit, members map[string]int64) *ContextRelation {
+func NewContextRelation(ru *uniter.RelationUnit, members map[string]int64) *ContextRelation {
 	ctx := &ContextRelation{ru: ru, members: SettingsMap{}}
 	for unit := range members {
 		ctx.members[unit] = nil
@@ -287,7 +513,7 @@ func NewContextRelation(ru *state.RelationUnit, members map[string]int64) *Conte
 // WriteSettings persists all changes made to the unit's relation settings.
 func (ctx *ContextRelation) WriteSettings() (err error) {
 	if ctx.settings != nil {
-		_, err = ctx.settings.Write()
+		err = ctx.settings.Write()
 	}
 	return
 }
@@ -367,3 +593,7 @@ func (ctx *ContextRelation) ReadSettings(unit string) (settings map[string]inter
 	}
 	return settings, nil
 }
+
+func (ctx *ContextRelation) IngressAddress() (string, error) {
+	return ctx.ru.IngressAddress()
+}
@@ -8,8 +8,11 @@ import (
 	"io/ioutil"
 	. "launchpad.net/gocheck"
 	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/juju/testing"
 	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api"
+	apiuniter "launchpad.net/juju-core/state/api/uniter"
 	"launchpad.net/juju-core/utils"
 	"launchpad.net/juju-core/worker/uniter"
 	"launchpad.net/juju-core/worker/uniter/jujuc"
@@ -323,6 +326,9 @@ type ContextRelationSuite struct {
 	svc *state.Service
 	rel *state.Relation
 	ru  *state.RelationUnit
+
+	st         *api.State
+	apiRelUnit *apiuniter.RelationUnit
 }
 
 var _ = Suite(&ContextRelationSuite{})
@@ -343,10 +349,43 @@ func (s *ContextRelationSuite) SetUpTest(c *C) {
 	c.Assert(err, IsNil)
 	err = s.ru.EnterScope(nil)
 	c.Assert(err, IsNil)
+
+	var apiUniter *apiuniter.State
+	s.st, apiUniter = loginAsUnit(c, &s.JujuConnSuite, unit)
+	s.apiRelUnit = apiRelationUnit(c, apiUniter, s.rel, unit)
+}
+
+func (s *ContextRelationSuite) TearDownTest(c *C) {
+	if s.st != nil {
+		err := s.st.Close()
+		c.Assert(err, IsNil)
+		s.st = nil
+	}
+	s.JujuConnSuite.TearDownTest(c)
+}
+
+func (s *ContextRelationSuite) TestIngressAddress(c *C) {
+	ctx := uniter.NewContextRelation(s.apiRelUnit, nil)
+	_, err := ctx.IngressAddress()
+	c.Assert(err, ErrorMatches, `unit "u/0" is not assigned to a machine`)
+
+	unit, err := s.State.Unit("u/0")
+	c.Assert(err, IsNil)
+	err = unit.AssignToNewMachine()
+	c.Assert(err, IsNil)
+	id, err := unit.AssignedMachineId()
+	c.Assert(err, IsNil)
+	machine, err := s.State.Machine(id)
+	c.Assert(err, IsNil)
+	err = machine.SetMachineAddresses([]instance.Address{instance.NewScopedAddress("10.0.0.2")})
+	c.Assert(err, IsNil)
+	address, err := ctx.IngressAddress()
+	c.Assert(err, IsNil)
+	c.Assert(address, Equals, "10.0.0.2")
 }
 
 func (s *ContextRelationSuite) TestChangeMembers(c *C) {
-	ctx := uniter.NewContextRelation(s.ru, nil)
+	ctx := uniter.NewContextRelation(s.apiRelUnit, nil)
 	c.Assert(ctx.UnitNames(), HasLen, 0)
 
 	// Check the units and settings after a simple update.
@@ -399,7 +438,7 @@ func (s *ContextRelationSuite) TestMemberCaching(c *C) {
 	settings.Set("ping", "pong")
 	_, err = settings.Write()
 	c.Assert(err, IsNil)
-	ctx := uniter.NewContextRelation(s.ru, map[string]int64{"u/1": 0})
+	ctx := uniter.NewContextRelation(s.apiRelUnit, map[string]int64{"u/1": 0})
 
 	// Check that uncached settings are read from state.
 	m, err := ctx.ReadSettings("u/1")
@@ -441,7 +480,7 @@ func (s *ContextRelationSuite) TestNonMemberCaching(c *C) {
 	settings.Set("ping", "pong")
 	_, err = settings.Write()
 	c.Assert(err, IsNil)
-	ctx := uniter.NewContextRelation(s.ru, nil)
+	ctx := uniter.NewContextRelation(s.apiRelUnit, nil)
 
 	// Check that settings are read from state.
 	m, err := ctx.ReadSettings("u/1")
@@ -466,7 +505,7 @@ func (s *ContextRelationSuite) TestNonMemberCaching(c *C) {
 }
 
 func (s *ContextRelationSuite) TestSettings(c *C) {
-	ctx := uniter.NewContextRelation(s.ru, nil)
+	ctx := uniter.NewContextRelation(s.apiRelUnit, nil)
 
 	// Change Settings, then clear cache without writing.
 	node, err := ctx.Settings()
@@ -595,6 +634,10 @@ type HookContextSuite struct {
 	relch    *state.Charm
 	relunits map[int]*state.RelationUnit
 	relctxs  map[int]*uniter.ContextRelation
+
+	st        *api.State
+	apiUniter *apiuniter.State
+	apiUnit   *apiuniter.Unit
 }
 
 func (s *HookContextSuite) SetUpTest(c *C) {
@@ -609,6 +652,9 @@ func (s *HookContextSuite) SetUpTest(c *C) {
 	// before the initial install hook).
 	err = s.unit.SetCharmURL(sch.URL())
 	c.Assert(err, IsNil)
+	s.st, s.apiUniter = loginAsUnit(c, &s.JujuConnSuite, s.unit)
+	s.apiUnit, err = s.apiUniter.Unit(s.unit.Tag())
+	c.Assert(err, IsNil)
 	s.relch = s.AddTestingCharm(c, "mysql")
 	s.relunits = map[int]*state.RelationUnit{}
 	s.relctxs = map[int]*uniter.ContextRelation{}
@@ -616,6 +662,15 @@ func (s *HookContextSuite) SetUpTest(c *C) {
 	s.AddContextRelation(c, "db1")
 }
 
+func (s *HookContextSuite) TearDownTest(c *C) {
+	if s.st != nil {
+		err := s.st.Close()
+		c.Assert(err, IsNil)
+		s.st = nil
+	}
+	s.JujuConnSuite.TearDownTest(c)
+}
+
 func (s *HookContextSuite) AddUnit(c *C, svc *state.Service) *state.Unit {
 	unit, err := svc.AddUnit()
 	c.Assert(err, IsNil)
@@ -637,7 +692,8 @@ func (s *HookContextSuite) AddContextRelation(c *C, name string) {
 	s.relunits[rel.Id()] = ru
 	err = ru.EnterScope(map[string]interface{}{"relation-name": name})
 	c.Assert(err, IsNil)
-	s.relctxs[rel.Id()] = uniter.NewContextRelation(ru, nil)
+	apiRelUnit := apiRelationUnit(c, s.apiUniter, rel, s.unit)
+	s.relctxs[rel.Id()] = uniter.NewContextRelation(apiRelUnit, nil)
 }
 
 func (s *HookContextSuite) GetHookContext(c *C, uuid string, relid int,
@@ -646,6 +702,8 @@ func (s *HookContextSuite) GetHookContext(c *C, uuid string, relid int,
 		_, found := s.relctxs[relid]
 		c.Assert(found, Equals, true)
 	}
-	return uniter.NewHookContext(s.unit, "TestCtx", uuid, relid, remote,
+	ctx, err := uniter.NewHookContext(s.apiUnit, "TestCtx", uuid, relid, remote,
 		s.relctxs, apiAddrs)
+	c.Assert(err, IsNil)
+	return ctx
 }
@@ -6,12 +6,15 @@ package uniter
 import (
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	"launchpad.net/loggo"
 
 	"launchpad.net/juju-core/charm"
-	"launchpad.net/juju-core/errors"
-	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/api/uniter"
+	apiwatcher "launchpad.net/juju-core/state/api/watcher"
 	"launchpad.net/juju-core/state/watcher"
 	"launchpad.net/juju-core/worker"
 	"launchpad.net/tomb"
@@ -23,7 +26,7 @@ var filterLogger = loggo.GetLogger("juju.worker.uniter.filter")
 // state watchers, and presents it as events on channels designed specifically
 // for the convenience of the uniter.
 type filter struct {
-	st   *state.State
+	st   *uniter.State
 	tomb tomb.Tomb
 
 	// outUnitDying is closed when the unit's life becomes Dying.
@@ -33,14 +36,24 @@ type filter struct {
 	// The out* chans, when set to the corresponding out*On chan (rather than
 	// nil) indicate that an event of the appropriate type is ready to send
 	// to the client.
-	outConfig      chan struct{}
-	outConfigOn    chan struct{}
-	outUpgrade     chan *charm.URL
-	outUpgradeOn   chan *charm.URL
-	outResolved    chan state.ResolvedMode
-	outResolvedOn  chan state.ResolvedMode
-	outRelations   chan []int
-	outRelationsOn chan []int
+	outConfig           chan struct{}
+	outConfigOn         chan struct{}
+	outUpgrade          chan *charm.URL
+	outUpgradeOn        chan *charm.URL
+	outResolved         chan params.ResolvedMode
+	outResolvedOn       chan params.ResolvedMode
+	outRelations        chan []int
+	outRelationsOn      chan []int
+	outAction           chan string
+	outActionOn         chan string
+	outLeadership       chan struct{}
+	outLeadershipOn     chan struct{}
+	outLeaderSettings   chan struct{}
+	outLeaderSettingsOn chan struct{}
+	outStorage          chan struct{}
+	outStorageOn        chan struct{}
+	outHookPolicy       chan struct{}
+	outHookPolicyOn     chan struct{}
 
 	// The want* chans are used to indicate that the filter should send
 	// events if it has them available.
@@ -77,41 +90,47 @@ type filter struct {
 
 	// The following fields hold state that is collected while running,
 	// and used to detect interesting changes to express as events.
-	unit             *state.Unit
-	life             state.Life
-	resolved         state.ResolvedMode
-	service          *state.Service
+	unit             *uniter.Unit
+	life             params.Life
+	resolved         params.ResolvedMode
+	service          *uniter.Service
 	upgradeFrom      serviceCharm
 	upgradeAvailable serviceCharm
 	upgrade          *charm.URL
 	relations        []int
+	actions          []string
 }
 
 // newFilter returns a filter that handles state changes pertaining to the
 // supplied unit.
-func newFilter(st *state.State, unitName string) (*filter, error) {
+func newFilter(st *uniter.State, unitTag string) (*filter, error) {
 	f := &filter{
-		st:                st,
-		outUnitDying:      make(chan struct{}),
-		outConfig:         make(chan struct{}),
-		outConfigOn:       make(chan struct{}),
-		outUpgrade:        make(chan *charm.URL),
-		outUpgradeOn:      make(chan *charm.URL),
-		outResolved:       make(chan state.ResolvedMode),
-		outResolvedOn:     make(chan state.ResolvedMode),
-		outRelations:      make(chan []int),
-		outRelationsOn:    make(chan []int),
-		wantForcedUpgrade: make(chan bool),
-		wantResolved:      make(chan struct{}),
-		discardConfig:     make(chan struct{}),
-		setCharm:          make(chan *charm.URL),
-		didSetCharm:       make(chan struct{}),
-		clearResolved:     make(chan struct{}),
-		didClearResolved:  make(chan struct{}),
+		st:                  st,
+		outUnitDying:        make(chan struct{}),
+		outConfig:           make(chan struct{}),
+		outConfigOn:         make(chan struct{}),
+		outUpgrade:          make(chan *charm.URL),
+		outUpgradeOn:        make(chan *charm.URL),
+		outResolved:         make(chan params.ResolvedMode),
+		outResolvedOn:       make(chan params.ResolvedMode),
+		outRelations:        make(chan []int),
+		outRelationsOn:      make(chan []int),
+		outActionOn:         make(chan string),
+		outLeadershipOn:     make(chan struct{}),
+		outLeaderSettingsOn: make(chan struct{}),
+		outStorageOn:        make(chan struct{}),
+		outHookPolicyOn:     make(chan struct{}),
+		wantForcedUpgrade:   make(chan bool),
+		wantResolved:        make(chan struct{}),
+		discardConfig:       make(chan struct{}),
+		setCharm:            make(chan *charm.URL),
+		didSetCharm:         make(chan struct{}),
+		clearResolved:       make(chan struct{}),
+		didClearResolved:    make(chan struct{}),
 	}
 	go func() {
 		defer f.tomb.Done()
-		err := f.loop(unitName)
+		err := f.loop(unitTag)
 		filterLogger.Errorf("%v", err)
 		f.tomb.Kill(err)
 	}()
@@ -147,7 +166,7 @@ func (f *filter) UpgradeEvents() <-chan *charm.URL {
 // unit's Resolved value changes, or when an event is explicitly requested.
 // A ResolvedNone state will never generate events, but ResolvedRetryHooks and
 // ResolvedNoHooks will always be delivered as described.
-func (f *filter) ResolvedEvents() <-chan state.ResolvedMode {
+func (f *filter) ResolvedEvents() <-chan params.ResolvedMode {
 	return f.outResolvedOn
 }
 
@@ -163,6 +182,38 @@ func (f *filter) RelationsEvents() <-chan []int {
 	return f.outRelationsOn
 }
 
+// ActionEvents returns a channel that will receive the id of every action
+// queued on the unit, one at a time, in the order they were queued.
+func (f *filter) ActionEvents() <-chan string {
+	return f.outActionOn
+}
+
+// LeadershipEvents returns a channel that will receive a signal whenever
+// the leader of the service changes, or the agent of the leader is found
+// to have died.
+func (f *filter) LeadershipEvents() <-chan struct{} {
+	return f.outLeadershipOn
+}
+
+// LeaderSettingsEvents returns a channel that will receive a signal
+// whenever the leader of the service writes new settings.
+func (f *filter) LeaderSettingsEvents() <-chan struct{} {
+	return f.outLeaderSettingsOn
+}
+
+// StorageEvents returns a channel that will receive a signal whenever
+// the storage instances of the unit change, such as when a volume is
+// provisioned for one of them.
+func (f *filter) StorageEvents() <-chan struct{} {
+	return f.outStorageOn
+}
+
+// HookPolicyEvents returns a channel that will receive a signal whenever
+// the service changes, so that its hook policy may be read again.
+func (f *filter) HookPolicyEvents() <-chan struct{} {
+	return f.outHookPolicyOn
+}
+
 // WantUpgradeEvent controls whether the filter will generate upgrade
 // events for unforced service charm changes.
 func (f *filter) WantUpgradeEvent(mustForce bool) {
@@ -235,8 +286,8 @@ func (f *filter) DiscardConfigEvent() {
 	}
 }
 
-func (f *filter) loop(unitName string) (err error) {
-	f.unit, err = f.st.Unit(unitName)
+func (f *filter) loop(unitTag string) (err error) {
+	f.unit, err = f.st.Unit(unitTag)
 	if err != nil {
 		return err
 	}
@@ -250,29 +301,61 @@ func (f *filter) loop(unitName string) (err error) {
 	if err = f.serviceChanged(); err != nil {
 		return err
 	}
-	unitw := f.unit.Watch()
+	unitw, err := f.unit.Watch()
+	if err != nil {
+		return err
+	}
 	defer watcher.Stop(unitw, &f.tomb)
-	servicew := f.service.Watch()
+	servicew, err := f.service.Watch()
+	if err != nil {
+		return err
+	}
 	defer watcher.Stop(servicew, &f.tomb)
 	// configw and relationsw can get restarted, so we need to use
 	// their eventual values in the defer calls.
-	var configw state.NotifyWatcher
+	var configw *apiwatcher.NotifyWatcher
 	var configChanges <-chan struct{}
-	if curl, ok := f.unit.CharmURL(); ok {
+	curl, err := f.unit.CharmURL()
+	if err == nil {
 		configw, err = f.unit.WatchConfigSettings()
 		if err != nil {
 			return err
 		}
 		configChanges = configw.Changes()
 		f.upgradeFrom.url = curl
+	} else if err != uniter.ErrNoCharmURLSet {
+		return err
 	}
 	defer func() {
 		if configw != nil {
 			watcher.Stop(configw, &f.tomb)
 		}
 	}()
-	relationsw := f.service.WatchRelations()
+	relationsw, err := f.service.WatchRelations()
+	if err != nil {
+		return err
+	}
 	defer func() { watcher.Stop(relationsw, &f.tomb) }()
+	actionsw, err := f.unit.WatchActions()
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop(actionsw, &f.tomb)
+	leadershipw, err := f.service.WatchLeadership()
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop(leadershipw, &f.tomb)
+	leaderSettingsw, err := f.service.WatchLeaderSettings()
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop(leaderSettingsw, &f.tomb)
+	storagew, err := f.unit.WatchStorageInstances()
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop(storagew, &f.tomb)
 
 	// Config events cannot be meaningfully discarded until one is available;
 	// once we receive the initial change, we unblock discard requests by
@@ -301,6 +384,7 @@ func (f *filter) loop(unitName string) (err error) {
 			if err = f.serviceChanged(); err != nil {
 				return err
 			}
+			f.outHookPolicy = f.outHookPolicyOn
 		case _, ok = <-configChanges:
 			filterLogger.Debugf("got config change")
 			if !ok {
@@ -316,7 +400,7 @@ func (f *filter) loop(unitName string) (err error) {
 			}
 			var ids []int
 			for _, key := range keys {
-				if rel, err := f.st.KeyRelation(key); errors.IsNotFoundError(err) {
+				if rel, err := f.st.Relation(key); params.ErrCode(err) == params.CodeNotFound {
 					// If it's actually gone, this unit cannot have entered
 					// scope, and therefore never needs to know about it.
 				} else if err != nil {
@@ -326,6 +410,30 @@ func (f *filter) loop(unitName string) (err error) {
 				}
 			}
 			f.relationsChanged(ids)
+		case ids, ok := <-actionsw.Changes():
+			filterLogger.Debugf("got actions change")
+			if !ok {
+				return watcher.MustErr(actionsw)
+			}
+			f.actionsChanged(ids)
+		case _, ok = <-leadershipw.Changes():
+			filterLogger.Debugf("got leadership change")
+			if !ok {
+				return watcher.MustErr(leadershipw)
+			}
+			f.outLeadership = f.outLeadershipOn
+		case _, ok = <-leaderSettingsw.Changes():
+			filterLogger.Debugf("got leader settings change")
+			if !ok {
+				return watcher.MustErr(leaderSettingsw)
+			}
+			f.outLeaderSettings = f.outLeaderSettingsOn
+		case _, ok = <-storagew.Changes():
+			filterLogger.Debugf("got storage change")
+			if !ok {
+				return watcher.MustErr(storagew)
+			}
+			f.outStorage = f.outStorageOn
 
 		// Send events on active out chans.
 		case f.outUpgrade <- f.upgrade:
@@ -341,6 +449,24 @@ func (f *filter) loop(unitName string) (err error) {
 			filterLogger.Debugf("sent relations event")
 			f.outRelations = nil
 			f.relations = nil
+		case f.outAction <- f.nextAction():
+			filterLogger.Debugf("sent action event")
+			f.actions = f.actions[1:]
+			if len(f.actions) == 0 {
+				f.outAction = nil
+			}
+		case f.outLeadership <- nothing:
+			filterLogger.Debugf("sent leadership event")
+			f.outLeadership = nil
+		case f.outLeaderSettings <- nothing:
+			filterLogger.Debugf("sent leader settings event")
+			f.outLeaderSettings = nil
+		case f.outStorage <- nothing:
+			filterLogger.Debugf("sent storage event")
+			f.outStorage = nil
+		case f.outHookPolicy <- nothing:
+			filterLogger.Debugf("sent hook policy event")
+			f.outHookPolicy = nil
 
 		// Handle explicit requests.
 		case curl := <-f.setCharm:
@@ -372,7 +498,10 @@ func (f *filter) loop(unitName string) (err error) {
 			if err := relationsw.Stop(); err != nil {
 				return err
 			}
-			relationsw = f.service.WatchRelations()
+			relationsw, err = f.service.WatchRelations()
+			if err != nil {
+				return err
+			}
 
 			f.upgradeFrom.url = curl
 			if err = f.upgradeChanged(); err != nil {
@@ -386,7 +515,7 @@ func (f *filter) loop(unitName string) (err error) {
 			}
 		case <-f.wantResolved:
 			filterLogger.Debugf("want resolved event")
-			if f.resolved != state.ResolvedNone {
+			if f.resolved != params.ResolvedNone {
 				f.outResolved = f.outResolvedOn
 			}
 		case <-f.clearResolved:
@@ -413,25 +542,29 @@ func (f *filter) loop(unitName string) (err error) {
 // unitChanged responds to changes in the unit.
 func (f *filter) unitChanged() error {
 	if err := f.unit.Refresh(); err != nil {
-		if errors.IsNotFoundError(err) {
+		if params.ErrCode(err) == params.CodeNotFound {
 			return worker.ErrTerminateAgent
 		}
 		return err
 	}
 	if f.life != f.unit.Life() {
 		switch f.life = f.unit.Life(); f.life {
-		case state.Dying:
+		case params.Dying:
 			filterLogger.Infof("unit is dying")
 			close(f.outUnitDying)
 			f.outUpgrade = nil
-		case state.Dead:
+		case params.Dead:
 			filterLogger.Infof("unit is dead")
 			return worker.ErrTerminateAgent
 		}
 	}
-	if resolved := f.unit.Resolved(); resolved != f.resolved {
+	resolved, err := f.unit.Resolved()
+	if err != nil {
+		return err
+	}
+	if resolved != f.resolved {
 		f.resolved = resolved
-		if f.resolved != state.ResolvedNone {
+		if f.resolved != params.ResolvedNone {
 			f.outResolved = f.outResolvedOn
 		}
 	}
@@ -441,19 +574,22 @@ func (f *filter) unitChanged() error {
 // serviceChanged responds to changes in the service.
 func (f *filter) serviceChanged() error {
 	if err := f.service.Refresh(); err != nil {
-		if errors.IsNotFoundError(err) {
+		if params.ErrCode(err) == params.CodeNotFound {
 			return fmt.Errorf("service unexpectedly removed")
 		}
 		return err
 	}
-	url, force := f.service.CharmURL()
+	url, force, err := f.service.CharmURL()
+	if err != nil {
+		return err
+	}
 	f.upgradeAvailable = serviceCharm{url, force}
 	switch f.service.Life() {
-	case state.Dying:
+	case params.Dying:
 		if err := f.unit.Destroy(); err != nil {
 			return err
 		}
-	case state.Dead:
+	case params.Dead:
 		return fmt.Errorf("service unexpectedly dead")
 	}
 	return f.upgradeChanged()
@@ -463,7 +599,7 @@ func (f *filter) serviceChanged() error {
 // upgrade requests that defines which charm changes should be
 // delivered as upgrades.
 func (f *filter) upgradeChanged() (err error) {
-	if f.life != state.Alive {
+	if f.life != params.Alive {
 		filterLogger.Debugf("charm check skipped, unit is dying")
 		f.outUpgrade = nil
 		return nil
@@ -505,6 +641,50 @@ outer:
 	}
 }
 
+// actionsChanged responds to actions being queued on the unit.
+func (f *filter) actionsChanged(ids []string) {
+	var queued []string
+outer:
+	for _, id := range ids {
+		for _, existing := range f.actions {
+			if id == existing {
+				continue outer
+			}
+		}
+		queued = append(queued, id)
+	}
+	sort.Sort(actionIds(queued))
+	f.actions = append(f.actions, queued...)
+	if len(f.actions) != 0 {
+		f.outAction = f.outActionOn
+	}
+}
+
+// nextAction returns the id of the next action to send, if any.
+func (f *filter) nextAction() string {
+	if len(f.actions) == 0 {
+		return ""
+	}
+	return f.actions[0]
+}
+
+// actionIds sorts the ids of the actions of a unit in the order they were
+// queued.
+type actionIds []string
+
+func (ids actionIds) Len() int      { return len(ids) }
+func (ids actionIds) Swap(i, j int) { ids[i], ids[j] = ids[j], ids[i] }
+func (ids actionIds) Less(i, j int) bool {
+	return actionSequence(ids[i]) < actionSequence(ids[j])
+}
+
+// actionSequence returns the sequence number that ends the id of an
+// action.
+func actionSequence(id string) int {
+	seq, _ := strconv.Atoi(id[strings.LastIndex(id, ":")+1:])
+	return seq
+}
+
 // serviceCharm holds information about a charm.
 type serviceCharm struct {
 	url   *charm.URL
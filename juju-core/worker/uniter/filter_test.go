@@ -9,8 +9,11 @@ import (
 	"launchpad.net/juju-core/charm"
 	jujutesting "launchpad.net/juju-core/juju/testing"
 	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api"
 	"launchpad.net/juju-core/state/api/params"
+	apiuniter "launchpad.net/juju-core/state/api/uniter"
 	coretesting "launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/utils"
 	"launchpad.net/juju-core/worker"
 	"launchpad.net/tomb"
 	"time"
@@ -22,6 +25,9 @@ type FilterSuite struct {
 	unit       *state.Unit
 	mysqlcharm *state.Charm
 	wpcharm    *state.Charm
+
+	st     *api.State
+	uniter *apiuniter.State
 }
 
 var _ = Suite(&FilterSuite{})
@@ -42,14 +48,40 @@ func (s *FilterSuite) SetUpTest(c *C) {
 	c.Assert(err, IsNil)
 	err = machine.SetProvisioned("i-exist", "fake_nonce", nil)
 	c.Assert(err, IsNil)
+	s.APILogin(c, s.unit)
+}
+
+func (s *FilterSuite) TearDownTest(c *C) {
+	if s.st != nil {
+		err := s.st.Close()
+		c.Assert(err, IsNil)
+		s.st = nil
+	}
+	s.JujuConnSuite.TearDownTest(c)
+}
+
+// APILogin logs in to the API as the agent of the given unit, replacing
+// any previous connection.
+func (s *FilterSuite) APILogin(c *C, unit *state.Unit) {
+	if s.st != nil {
+		err := s.st.Close()
+		c.Assert(err, IsNil)
+	}
+	password, err := utils.RandomPassword()
+	c.Assert(err, IsNil)
+	err = unit.SetPassword(password)
+	c.Assert(err, IsNil)
+	s.st = s.OpenAPIAs(c, unit.Tag(), password)
+	s.uniter = s.st.Uniter()
+	c.Assert(s.uniter, NotNil)
 }
 
 func (s *FilterSuite) TestUnitDeath(c *C) {
-	f, err := newFilter(s.State, s.unit.Name())
+	f, err := newFilter(s.uniter, s.unit.Tag())
 	c.Assert(err, IsNil)
 	defer f.Stop()
 	asserter := coretesting.NotifyAsserterC{
-		Precond: func() { s.State.StartSync() },
+		Precond: func() { s.BackingState.StartSync() },
 		C:       c,
 		Chan:    f.UnitDying(),
 	}
@@ -79,7 +111,7 @@ func (s *FilterSuite) TestUnitDeath(c *C) {
 }
 
 func (s *FilterSuite) TestUnitRemoval(c *C) {
-	f, err := newFilter(s.State, s.unit.Name())
+	f, err := newFilter(s.uniter, s.unit.Tag())
 	c.Assert(err, IsNil)
 	defer f.Stop()
 
@@ -92,7 +124,7 @@ func (s *FilterSuite) TestUnitRemoval(c *C) {
 // Ensure we get a signal on f.Dead()
 func (s *FilterSuite) assertFilterDies(c *C, f *filter) {
 	asserter := coretesting.NotifyAsserterC{
-		Precond: func() { s.State.StartSync() },
+		Precond: func() { s.BackingState.StartSync() },
 		C:       c,
 		Chan:    f.Dead(),
 	}
@@ -105,12 +137,12 @@ func (s *FilterSuite) assertAgentTerminates(c *C, f *filter) {
 }
 
 func (s *FilterSuite) TestServiceDeath(c *C) {
-	f, err := newFilter(s.State, s.unit.Name())
+	f, err := newFilter(s.uniter, s.unit.Tag())
 	c.Assert(err, IsNil)
 	defer f.Stop()
 	dyingAsserter := coretesting.NotifyAsserterC{
 		C:       c,
-		Precond: func() { s.State.StartSync() },
+		Precond: func() { s.BackingState.StartSync() },
 		Chan:    f.UnitDying(),
 	}
 	dyingAsserter.AssertNoReceive()
@@ -126,7 +158,7 @@ loop:
 		case <-f.UnitDying():
 			break loop
 		case <-time.After(coretesting.ShortWait):
-			s.State.StartSync()
+			s.BackingState.StartSync()
 		case <-timeout:
 			c.Fatalf("dead not detected")
 		}
@@ -139,13 +171,13 @@ loop:
 }
 
 func (s *FilterSuite) TestResolvedEvents(c *C) {
-	f, err := newFilter(s.State, s.unit.Name())
+	f, err := newFilter(s.uniter, s.unit.Tag())
 	c.Assert(err, IsNil)
 	defer f.Stop()
 
 	resolvedAsserter := coretesting.ContentAsserterC{
 		C:       c,
-		Precond: func() { s.State.StartSync() },
+		Precond: func() { s.BackingState.StartSync() },
 		Chan:    f.ResolvedEvents(),
 	}
 	resolvedAsserter.AssertNoReceive()
@@ -162,15 +194,15 @@ func (s *FilterSuite) TestResolvedEvents(c *C) {
 	// Change the unit's resolved to an interesting value; new event received.
 	err = s.unit.SetResolved(state.ResolvedRetryHooks)
 	c.Assert(err, IsNil)
-	assertChange := func(expect state.ResolvedMode) {
-		rm := resolvedAsserter.AssertOneReceive().(state.ResolvedMode)
+	assertChange := func(expect params.ResolvedMode) {
+		rm := resolvedAsserter.AssertOneReceive().(params.ResolvedMode)
 		c.Assert(rm, Equals, expect)
 	}
-	assertChange(state.ResolvedRetryHooks)
+	assertChange(params.ResolvedRetryHooks)
 
 	// Ask for the event again, and check it's resent.
 	f.WantResolvedEvent()
-	assertChange(state.ResolvedRetryHooks)
+	assertChange(params.ResolvedRetryHooks)
 
 	// Clear the resolved status *via the filter*; check not resent...
 	err = f.ClearResolved()
@@ -188,7 +220,7 @@ func (s *FilterSuite) TestResolvedEvents(c *C) {
 	c.Assert(err, IsNil)
 	err = s.unit.SetResolved(state.ResolvedNoHooks)
 	c.Assert(err, IsNil)
-	assertChange(state.ResolvedNoHooks)
+	assertChange(params.ResolvedNoHooks)
 }
 
 func (s *FilterSuite) TestCharmUpgradeEvents(c *C) {
@@ -198,13 +230,14 @@ func (s *FilterSuite) TestCharmUpgradeEvents(c *C) {
 	unit, err := svc.AddUnit()
 	c.Assert(err, IsNil)
 
-	f, err := newFilter(s.State, unit.Name())
+	s.APILogin(c, unit)
+	f, err := newFilter(s.uniter, unit.Tag())
 	c.Assert(err, IsNil)
 	defer f.Stop()
 
 	// No initial event is sent.
 	assertNoChange := func() {
-		s.State.StartSync()
+		s.BackingState.StartSync()
 		select {
 		case sch := <-f.UpgradeEvents():
 			c.Fatalf("unexpected %#v", sch)
@@ -232,7 +265,7 @@ func (s *FilterSuite) TestCharmUpgradeEvents(c *C) {
 	err = svc.SetCharm(newCharm, false)
 	c.Assert(err, IsNil)
 	assertChange := func(url *charm.URL) {
-		s.State.Sync()
+		s.BackingState.Sync()
 		select {
 		case upgradeCharm := <-f.UpgradeEvents():
 			c.Assert(upgradeCharm, DeepEquals, url)
@@ -264,13 +297,13 @@ func (s *FilterSuite) TestCharmUpgradeEvents(c *C) {
 }
 
 func (s *FilterSuite) TestConfigEvents(c *C) {
-	f, err := newFilter(s.State, s.unit.Name())
+	f, err := newFilter(s.uniter, s.unit.Tag())
 	c.Assert(err, IsNil)
 	defer f.Stop()
 
 	// Test no changes before the charm URL is set.
 	assertNoChange := func() {
-		s.State.StartSync()
+		s.BackingState.StartSync()
 		select {
 		case <-f.ConfigEvents():
 			c.Fatalf("unexpected config event")
@@ -283,7 +316,7 @@ func (s *FilterSuite) TestConfigEvents(c *C) {
 	err = f.SetCharm(s.wpcharm.URL())
 	c.Assert(err, IsNil)
 	assertChange := func() {
-		s.State.Sync()
+		s.BackingState.Sync()
 		select {
 		case _, ok := <-f.ConfigEvents():
 			c.Assert(ok, Equals, true)
@@ -312,17 +345,17 @@ func (s *FilterSuite) TestConfigEvents(c *C) {
 	// that's a bit inconvenient for this change.
 	changeConfig(nil)
 	changeConfig("the curious incident of the dog in the cloud")
-	s.State.Sync()
+	s.BackingState.Sync()
 	time.Sleep(250 * time.Millisecond)
 	f.DiscardConfigEvent()
 	assertNoChange()
 
 	// Check that a filter's initial event works with DiscardConfigEvent
 	// as expected.
-	f, err = newFilter(s.State, s.unit.Name())
+	f, err = newFilter(s.uniter, s.unit.Tag())
 	c.Assert(err, IsNil)
 	defer f.Stop()
-	s.State.Sync()
+	s.BackingState.Sync()
 	f.DiscardConfigEvent()
 	assertNoChange()
 
@@ -333,12 +366,12 @@ func (s *FilterSuite) TestConfigEvents(c *C) {
 }
 
 func (s *FilterSuite) TestCharmErrorEvents(c *C) {
-	f, err := newFilter(s.State, s.unit.Name())
+	f, err := newFilter(s.uniter, s.unit.Tag())
 	c.Assert(err, IsNil)
 	defer f.Stop()
 
 	assertNoChange := func() {
-		s.State.StartSync()
+		s.BackingState.StartSync()
 		select {
 		case <-f.ConfigEvents():
 			c.Fatalf("unexpected config event")
@@ -353,7 +386,7 @@ func (s *FilterSuite) TestCharmErrorEvents(c *C) {
 	s.assertFilterDies(c, f)
 
 	// Filter died after the error, so restart it.
-	f, err = newFilter(s.State, s.unit.Name())
+	f, err = newFilter(s.uniter, s.unit.Tag())
 	c.Assert(err, IsNil)
 	defer f.Stop()
 
@@ -365,12 +398,12 @@ func (s *FilterSuite) TestCharmErrorEvents(c *C) {
 }
 
 func (s *FilterSuite) TestRelationsEvents(c *C) {
-	f, err := newFilter(s.State, s.unit.Name())
+	f, err := newFilter(s.uniter, s.unit.Tag())
 	c.Assert(err, IsNil)
 	defer f.Stop()
 
 	assertNoChange := func() {
-		s.State.Sync()
+		s.BackingState.Sync()
 		select {
 		case ids := <-f.RelationsEvents():
 			c.Fatalf("unexpected relations event %#v", ids)
@@ -383,7 +416,7 @@ func (s *FilterSuite) TestRelationsEvents(c *C) {
 	rel0 := s.addRelation(c)
 	rel1 := s.addRelation(c)
 	assertChange := func(expect []int) {
-		s.State.Sync()
+		s.BackingState.Sync()
 		select {
 		case got := <-f.RelationsEvents():
 			c.Assert(got, DeepEquals, expect)
@@ -413,7 +446,7 @@ func (s *FilterSuite) TestRelationsEvents(c *C) {
 	assertNoChange()
 
 	// Start a new filter, check initial event.
-	f, err = newFilter(s.State, s.unit.Name())
+	f, err = newFilter(s.uniter, s.unit.Tag())
 	c.Assert(err, IsNil)
 	defer f.Stop()
 	assertChange([]int{0, 2})
@@ -424,6 +457,48 @@ func (s *FilterSuite) TestRelationsEvents(c *C) {
 	assertChange([]int{0, 2})
 }
 
+func (s *FilterSuite) TestActionEvents(c *C) {
+	dummy, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	unit, err := dummy.AddUnit()
+	c.Assert(err, IsNil)
+	_, err = unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	s.APILogin(c, unit)
+	f, err := newFilter(s.uniter, unit.Tag())
+	c.Assert(err, IsNil)
+	defer f.Stop()
+
+	assertNoChange := func() {
+		s.BackingState.Sync()
+		select {
+		case id := <-f.ActionEvents():
+			c.Fatalf("unexpected action event %q", id)
+		case <-time.After(coretesting.ShortWait):
+		}
+	}
+	assertChange := func(expect string) {
+		s.BackingState.Sync()
+		select {
+		case got := <-f.ActionEvents():
+			c.Assert(got, Equals, expect)
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out")
+		}
+	}
+	// Pending actions are sent one at a time, in the order they were queued.
+	assertChange("dummy/0:0")
+	assertNoChange()
+	for i := 0; i < 11; i++ {
+		_, err = unit.AddAction("snapshot", nil)
+		c.Assert(err, IsNil)
+	}
+	for i := 1; i < 12; i++ {
+		assertChange(fmt.Sprintf("dummy/0:%d", i))
+	}
+	assertNoChange()
+}
+
 func (s *FilterSuite) addRelation(c *C) *state.Relation {
 	if s.mysqlcharm == nil {
 		s.mysqlcharm = s.AddTestingCharm(c, "mysql")
@@ -31,6 +31,14 @@ type Info struct {
 	// member of the relation; if a unit is not present, no inferences about
 	// its state can be drawn.
 	Members map[string]map[string]interface{} `yaml:"members,omitempty"`
+
+	// ActionId identifies the action requested. It is only set when Kind
+	// indicates an action hook.
+	ActionId string `yaml:"action-id,omitempty"`
+
+	// StorageId identifies the storage instance associated with the hook.
+	// It is only set when Kind indicates a storage hook.
+	StorageId string `yaml:"storage-id,omitempty"`
 }
 
 // Validate returns an error if the info is not valid.
@@ -41,7 +49,18 @@ func (hi Info) Validate() error {
 			return fmt.Errorf("%q hook requires a remote unit", hi.Kind)
 		}
 		fallthrough
-	case hooks.Install, hooks.Start, hooks.ConfigChanged, hooks.UpgradeCharm, hooks.Stop, hooks.RelationBroken:
+	case hooks.Install, hooks.Start, hooks.ConfigChanged, hooks.UpgradeCharm, hooks.Stop, hooks.RelationBroken,
+		hooks.LeaderElected, hooks.LeaderSettingsChanged:
+		return nil
+	case hooks.Action:
+		if hi.ActionId == "" {
+			return fmt.Errorf("%q hook requires an action id", hi.Kind)
+		}
+		return nil
+	case hooks.StorageAttached, hooks.StorageDetaching:
+		if hi.StorageId == "" {
+			return fmt.Errorf("%q hook requires a storage id", hi.Kind)
+		}
 		return nil
 	}
 	return fmt.Errorf("unknown hook kind %q", hi.Kind)
@@ -31,6 +31,15 @@ var validateTests = []struct {
 	}, {
 		hook.Info{Kind: hooks.RelationDeparted},
 		`"relation-departed" hook requires a remote unit`,
+	}, {
+		hook.Info{Kind: hooks.Action},
+		`"action" hook requires an action id`,
+	}, {
+		hook.Info{Kind: hooks.StorageAttached},
+		`"storage-attached" hook requires a storage id`,
+	}, {
+		hook.Info{Kind: hooks.StorageDetaching},
+		`"storage-detaching" hook requires a storage id`,
 	}, {
 		hook.Info{Kind: hooks.Kind("grok")},
 		`unknown hook kind "grok"`,
@@ -40,10 +49,15 @@ var validateTests = []struct {
 	{hook.Info{Kind: hooks.ConfigChanged}, ""},
 	{hook.Info{Kind: hooks.UpgradeCharm}, ""},
 	{hook.Info{Kind: hooks.Stop}, ""},
+	{hook.Info{Kind: hooks.LeaderElected}, ""},
+	{hook.Info{Kind: hooks.LeaderSettingsChanged}, ""},
 	{hook.Info{Kind: hooks.RelationJoined, RemoteUnit: "x"}, ""},
 	{hook.Info{Kind: hooks.RelationChanged, RemoteUnit: "x"}, ""},
 	{hook.Info{Kind: hooks.RelationDeparted, RemoteUnit: "x"}, ""},
 	{hook.Info{Kind: hooks.RelationBroken}, ""},
+	{hook.Info{Kind: hooks.Action, ActionId: "u/0:0"}, ""},
+	{hook.Info{Kind: hooks.StorageAttached, StorageId: "data/0"}, ""},
+	{hook.Info{Kind: hooks.StorageDetaching, StorageId: "data/0"}, ""},
 }
 
 func (s *InfoSuite) TestValidate(c *C) {
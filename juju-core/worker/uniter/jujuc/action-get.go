@@ -0,0 +1,62 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+)
+
+// ActionGetCommand implements the action-get command.
+type ActionGetCommand struct {
+	cmd.CommandBase
+	ctx Context
+	Key string // The key to show. If empty, show all.
+	out cmd.Output
+}
+
+func NewActionGetCommand(ctx Context) cmd.Command {
+	return &ActionGetCommand{ctx: ctx}
+}
+
+func (c *ActionGetCommand) Info() *cmd.Info {
+	doc := `
+When no <key> is supplied, all the parameters of the running action are
+printed, including the default values of those not given when the action
+was requested.
+`
+	return &cmd.Info{
+		Name:    "action-get",
+		Args:    "[<key>]",
+		Purpose: "print action parameters",
+		Doc:     doc,
+	}
+}
+
+func (c *ActionGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+}
+
+func (c *ActionGetCommand) Init(args []string) error {
+	if args == nil {
+		return nil
+	}
+	c.Key = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *ActionGetCommand) Run(ctx *cmd.Context) error {
+	params, err := c.ctx.ActionParams()
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if c.Key == "" {
+		value = params
+	} else {
+		value, _ = params[c.Key]
+	}
+	return c.out.Write(ctx, value)
+}
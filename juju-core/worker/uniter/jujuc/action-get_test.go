@@ -0,0 +1,66 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type ActionGetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&ActionGetSuite{})
+
+func (s *ActionGetSuite) actionContext(c *C) *Context {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.actionParams = map[string]interface{}{
+		"outfile":     "foo.bz2",
+		"compression": 9,
+	}
+	return hctx
+}
+
+var actionGetTests = []struct {
+	args []string
+	out  string
+}{
+	{nil, "compression: 9\noutfile: foo.bz2\n"},
+	{[]string{"outfile"}, "foo.bz2\n"},
+	{[]string{"--format", "json", "compression"}, "9\n"},
+	{[]string{"--format", "json"}, `{"compression":9,"outfile":"foo.bz2"}` + "\n"},
+	{[]string{"missing"}, ""},
+}
+
+func (s *ActionGetSuite) TestOutput(c *C) {
+	for i, t := range actionGetTests {
+		c.Logf("test %d: %#v", i, t.args)
+		com, err := jujuc.NewCommand(s.actionContext(c), "action-get")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+		c.Assert(bufferString(ctx.Stdout), Equals, t.out)
+	}
+}
+
+func (s *ActionGetSuite) TestNotRunningAction(c *C) {
+	com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "action-get")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, nil)
+	c.Assert(code, Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), Equals, "error: not running an action\n")
+}
+
+func (s *ActionGetSuite) TestUnknownArg(c *C) {
+	com, err := jujuc.NewCommand(s.actionContext(c), "action-get")
+	c.Assert(err, IsNil)
+	testing.TestInit(c, com, []string{"outfile", "blah"}, `unrecognized args: \["blah"\]`)
+}
@@ -0,0 +1,58 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+	"strings"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+)
+
+// ActionSetCommand implements the action-set command.
+type ActionSetCommand struct {
+	cmd.CommandBase
+	ctx     Context
+	Results map[string]interface{}
+}
+
+func NewActionSetCommand(ctx Context) cmd.Command {
+	return &ActionSetCommand{ctx: ctx, Results: map[string]interface{}{}}
+}
+
+func (c *ActionSetCommand) Info() *cmd.Info {
+	doc := `
+The results are reported to the user once the running action completes.
+Setting a key again replaces its value.
+`
+	return &cmd.Info{
+		Name:    "action-set",
+		Args:    "key=value [key=value ...]",
+		Purpose: "set action results",
+		Doc:     doc,
+	}
+}
+
+func (c *ActionSetCommand) SetFlags(f *gnuflag.FlagSet) {
+}
+
+func (c *ActionSetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no results specified")
+	}
+	for _, kv := range args {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return fmt.Errorf(`expected "key=value", got %q`, kv)
+		}
+		c.Results[parts[0]] = parts[1]
+	}
+	return nil
+}
+
+func (c *ActionSetCommand) Run(ctx *cmd.Context) error {
+	return c.ctx.UpdateActionResults(c.Results)
+}
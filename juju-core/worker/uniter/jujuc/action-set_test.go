@@ -0,0 +1,63 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type ActionSetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&ActionSetSuite{})
+
+func (s *ActionSetSuite) TestActionSet(c *C) {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.actionParams = map[string]interface{}{}
+	for _, args := range [][]string{
+		{"outfile=/tmp/foo.bz2", "size=42"},
+		{"size=43", "checksum=a=b"},
+	} {
+		com, err := jujuc.NewCommand(hctx, "action-set")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+	}
+	c.Assert(hctx.actionResults, DeepEquals, map[string]interface{}{
+		"outfile":  "/tmp/foo.bz2",
+		"size":     "43",
+		"checksum": "a=b",
+	})
+}
+
+func (s *ActionSetSuite) TestInitErrors(c *C) {
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{nil, "no results specified"},
+		{[]string{"outfile"}, `expected "key=value", got "outfile"`},
+		{[]string{"=foo"}, `expected "key=value", got "=foo"`},
+	} {
+		com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "action-set")
+		c.Assert(err, IsNil)
+		testing.TestInit(c, com, t.args, t.err)
+	}
+}
+
+func (s *ActionSetSuite) TestNotRunningAction(c *C) {
+	com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "action-set")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"foo=bar"})
+	c.Assert(code, Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), Equals, "error: not running an action\n")
+}
@@ -6,6 +6,8 @@ package jujuc
 import (
 	"fmt"
 	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/state/api/params"
 	"strconv"
 	"strings"
 )
@@ -50,6 +52,75 @@ type Context interface {
 	// RelationIds returns the ids of all relations the executing unit is
 	// currently participating in.
 	RelationIds() []int
+
+	// WorkloadStatus returns the status of the executing unit's workload,
+	// as last set by the charm, and the message accompanying it.
+	WorkloadStatus() (params.WorkloadStatus, string, error)
+
+	// SetWorkloadStatus records the status of the executing unit's
+	// workload and a message explaining it to the operator.
+	SetWorkloadStatus(status params.WorkloadStatus, info string) error
+
+	// IsLeader returns whether the executing unit leads its service.
+	IsLeader() (bool, error)
+
+	// LeaderSettings returns the settings written by the leader of the
+	// executing unit's service.
+	LeaderSettings() (map[string]string, error)
+
+	// WriteLeaderSettings updates the leader settings of the executing
+	// unit's service, removing those set to the empty string. It returns
+	// an error if the executing unit is not the leader.
+	WriteLeaderSettings(settings map[string]string) error
+
+	// ActionParams returns the parameters of the executing action, or an
+	// error if no action is executing.
+	ActionParams() (map[string]interface{}, error)
+
+	// UpdateActionResults adds the supplied results to those recorded by
+	// the executing action, or returns an error if no action is executing.
+	UpdateActionResults(results map[string]interface{}) error
+
+	// HookStorageId returns the id of the storage instance the executing
+	// storage hook is associated with if it was found, and whether it was
+	// found.
+	HookStorageId() (string, bool)
+
+	// Storage returns the storage instance of the executing unit with the
+	// supplied id.
+	Storage(id string) (ContextStorage, error)
+
+	// Resource downloads and verifies the content last uploaded for the
+	// named resource of the service, if it is not already available
+	// locally, and returns the path to the local copy.
+	Resource(name string) (string, error)
+
+	// NetworkAddresses returns the addresses of the machine the executing
+	// unit is deployed to.
+	NetworkAddresses() ([]instance.Address, error)
+}
+
+// ContextStorage expresses the capabilities of a hook with respect to a
+// storage instance of its unit.
+type ContextStorage interface {
+
+	// Id returns the id of the storage instance, such as "data/0".
+	Id() string
+
+	// Name returns the name of the store, as declared by the charm.
+	Name() string
+
+	// Kind returns how the store is presented to the charm.
+	Kind() charm.StorageType
+
+	// Location returns the path at which a filesystem store is mounted.
+	Location() string
+
+	// Device returns the path of the block device of the store.
+	Device() string
+
+	// Size returns the minimum size of the store, in MiB.
+	Size() uint64
 }
 
 // ContextRelation expresses the capabilities of a hook with respect to a relation.
@@ -76,6 +147,11 @@ type ContextRelation interface {
 
 	// ReadSettings returns the settings of any remote unit in the relation.
 	ReadSettings(unit string) (map[string]interface{}, error)
+
+	// IngressAddress returns the address the remote units in the relation
+	// should use to reach the local unit, or the empty string if no
+	// suitable address is known.
+	IngressAddress() (string, error)
 }
 
 // Settings is implemented by types that manipulate unit settings.
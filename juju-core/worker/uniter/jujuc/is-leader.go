@@ -0,0 +1,49 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+)
+
+// IsLeaderCommand implements the is-leader command.
+type IsLeaderCommand struct {
+	cmd.CommandBase
+	ctx Context
+	out cmd.Output
+}
+
+func NewIsLeaderCommand(ctx Context) cmd.Command {
+	return &IsLeaderCommand{ctx: ctx}
+}
+
+func (c *IsLeaderCommand) Info() *cmd.Info {
+	doc := `
+Print True if the unit leads its service, and False otherwise. Only the
+leader can write settings with leader-set.
+`
+	return &cmd.Info{
+		Name:    "is-leader",
+		Purpose: "print whether the unit is the service leader",
+		Doc:     doc,
+	}
+}
+
+func (c *IsLeaderCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+}
+
+func (c *IsLeaderCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *IsLeaderCommand) Run(ctx *cmd.Context) error {
+	isLeader, err := c.ctx.IsLeader()
+	if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, isLeader)
+}
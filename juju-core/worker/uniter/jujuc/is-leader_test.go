@@ -0,0 +1,47 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type IsLeaderSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&IsLeaderSuite{})
+
+func (s *IsLeaderSuite) TestOutput(c *C) {
+	for i, t := range []struct {
+		leader bool
+		args   []string
+		out    string
+	}{
+		{true, nil, "True\n"},
+		{false, nil, "False\n"},
+		{true, []string{"--format", "json"}, "true\n"},
+	} {
+		c.Logf("test %d: %v %#v", i, t.leader, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		hctx.leader = t.leader
+		com, err := jujuc.NewCommand(hctx, "is-leader")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+		c.Assert(bufferString(ctx.Stdout), Equals, t.out)
+	}
+}
+
+func (s *IsLeaderSuite) TestUnknownArg(c *C) {
+	com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "is-leader")
+	c.Assert(err, IsNil)
+	testing.TestInit(c, com, []string{"blah"}, `unrecognized args: \["blah"\]`)
+}
@@ -0,0 +1,52 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type LeaderGetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&LeaderGetSuite{})
+
+var leaderGetTests = []struct {
+	args []string
+	out  string
+}{
+	{nil, "master: 10.0.0.1\nreplicas: \"2\"\n"},
+	{[]string{"master"}, "10.0.0.1\n"},
+	{[]string{"--format", "json"}, `{"master":"10.0.0.1","replicas":"2"}` + "\n"},
+	{[]string{"missing"}, ""},
+}
+
+func (s *LeaderGetSuite) TestOutput(c *C) {
+	for i, t := range leaderGetTests {
+		c.Logf("test %d: %#v", i, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		hctx.leaderSettings = map[string]string{
+			"master":   "10.0.0.1",
+			"replicas": "2",
+		}
+		com, err := jujuc.NewCommand(hctx, "leader-get")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+		c.Assert(bufferString(ctx.Stdout), Equals, t.out)
+	}
+}
+
+func (s *LeaderGetSuite) TestUnknownArg(c *C) {
+	com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "leader-get")
+	c.Assert(err, IsNil)
+	testing.TestInit(c, com, []string{"master", "blah"}, `unrecognized args: \["blah"\]`)
+}
@@ -0,0 +1,60 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+	"strings"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+)
+
+// LeaderSetCommand implements the leader-set command.
+type LeaderSetCommand struct {
+	cmd.CommandBase
+	ctx      Context
+	Settings map[string]string
+}
+
+func NewLeaderSetCommand(ctx Context) cmd.Command {
+	return &LeaderSetCommand{ctx: ctx, Settings: map[string]string{}}
+}
+
+func (c *LeaderSetCommand) Info() *cmd.Info {
+	doc := `
+Write settings for the other units of the service to read with leader-get.
+Only the leader of the service may write them; the other units run the
+leader-settings-changed hook in response. Setting a key to the empty
+string removes it.
+`
+	return &cmd.Info{
+		Name:    "leader-set",
+		Args:    "key=value [key=value ...]",
+		Purpose: "write service leader settings",
+		Doc:     doc,
+	}
+}
+
+func (c *LeaderSetCommand) SetFlags(f *gnuflag.FlagSet) {
+}
+
+func (c *LeaderSetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no settings specified")
+	}
+	for _, kv := range args {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return fmt.Errorf(`expected "key=value", got %q`, kv)
+		}
+		c.Settings[parts[0]] = parts[1]
+	}
+	return nil
+}
+
+func (c *LeaderSetCommand) Run(ctx *cmd.Context) error {
+	return c.ctx.WriteLeaderSettings(c.Settings)
+}
@@ -0,0 +1,62 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type LeaderSetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&LeaderSetSuite{})
+
+func (s *LeaderSetSuite) TestLeaderSet(c *C) {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.leader = true
+	for _, args := range [][]string{
+		{"master=10.0.0.1", "replicas=2"},
+		{"replicas=", "dsn=user=admin"},
+	} {
+		com, err := jujuc.NewCommand(hctx, "leader-set")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+	}
+	c.Assert(hctx.leaderSettings, DeepEquals, map[string]string{
+		"master": "10.0.0.1",
+		"dsn":    "user=admin",
+	})
+}
+
+func (s *LeaderSetSuite) TestInitErrors(c *C) {
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{nil, "no settings specified"},
+		{[]string{"master"}, `expected "key=value", got "master"`},
+		{[]string{"=foo"}, `expected "key=value", got "=foo"`},
+	} {
+		com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "leader-set")
+		c.Assert(err, IsNil)
+		testing.TestInit(c, com, t.args, t.err)
+	}
+}
+
+func (s *LeaderSetSuite) TestNotLeader(c *C) {
+	com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "leader-set")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"master=10.0.0.1"})
+	c.Assert(code, Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), Equals, "error: not the leader\n")
+}
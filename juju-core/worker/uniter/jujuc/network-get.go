@@ -0,0 +1,106 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+)
+
+// NetworkGetCommand implements the network-get command.
+type NetworkGetCommand struct {
+	cmd.CommandBase
+	ctx        Context
+	RelationId int
+	Key        string // The key to show. If empty, show all.
+	out        cmd.Output
+}
+
+func NewNetworkGetCommand(ctx Context) cmd.Command {
+	return &NetworkGetCommand{ctx: ctx}
+}
+
+func (c *NetworkGetCommand) Info() *cmd.Info {
+	doc := `
+When no <key> is supplied, all the network information known for the
+unit is printed. The keys are addresses, the addresses of the machine
+the unit is deployed to, and ingress-address, the address the units of
+a relation should use to reach the unit. The relation is the one of the
+executing relation hook, unless one is given with -r.
+`
+	return &cmd.Info{
+		Name:    "network-get",
+		Args:    "[<key>]",
+		Purpose: "print network information about the unit",
+		Doc:     doc,
+	}
+}
+
+func (c *NetworkGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+	f.Var(newRelationIdValue(c.ctx, &c.RelationId), "r", "specify a relation by id")
+}
+
+func (c *NetworkGetCommand) Init(args []string) error {
+	if len(args) > 0 {
+		c.Key = args[0]
+		args = args[1:]
+	}
+	switch c.Key {
+	case "", "addresses":
+	case "ingress-address":
+		if c.RelationId == -1 {
+			return fmt.Errorf("no relation id specified")
+		}
+	default:
+		return fmt.Errorf("unknown key %q", c.Key)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *NetworkGetCommand) Run(ctx *cmd.Context) error {
+	var ingress string
+	if c.RelationId != -1 {
+		r, found := c.ctx.Relation(c.RelationId)
+		if !found {
+			return fmt.Errorf("unknown relation id")
+		}
+		var err error
+		if ingress, err = r.IngressAddress(); err != nil {
+			return err
+		}
+	}
+	if c.Key == "ingress-address" {
+		return c.out.Write(ctx, ingress)
+	}
+	addrs, err := c.ctx.NetworkAddresses()
+	if err != nil {
+		return err
+	}
+	addresses := []map[string]string{}
+	for _, addr := range addrs {
+		address := map[string]string{
+			"value": addr.Value,
+			"type":  string(addr.Type),
+		}
+		if addr.NetworkScope != "" {
+			address["scope"] = string(addr.NetworkScope)
+		}
+		if addr.NetworkName != "" {
+			address["network-name"] = addr.NetworkName
+		}
+		addresses = append(addresses, address)
+	}
+	if c.Key == "addresses" {
+		return c.out.Write(ctx, addresses)
+	}
+	result := map[string]interface{}{"addresses": addresses}
+	if ingress != "" {
+		result["ingress-address"] = ingress
+	}
+	return c.out.Write(ctx, result)
+}
@@ -0,0 +1,73 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type NetworkGetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&NetworkGetSuite{})
+
+var addressesYaml = `
+- scope: local-machine
+  type: ipv4
+  value: 127.0.0.1
+- scope: local-cloud
+  type: ipv4
+  value: 192.168.0.99
+`[1:]
+
+var networkGetTests = []struct {
+	relid int
+	args  []string
+	out   string
+}{
+	{-1, nil, "addresses:\n" + addressesYaml},
+	{-1, []string{"addresses"}, addressesYaml},
+	{-1, []string{"-r", "1", "ingress-address"}, "192.168.0.99\n"},
+	{1, []string{"ingress-address"}, "192.168.0.99\n"},
+	{1, nil, "addresses:\n" + addressesYaml + "ingress-address: 192.168.0.99\n"},
+	{1, []string{"--format", "json", "ingress-address"}, `"192.168.0.99"` + "\n"},
+}
+
+func (s *NetworkGetSuite) TestOutput(c *C) {
+	for i, t := range networkGetTests {
+		c.Logf("test %d: %#v", i, t.args)
+		hctx := s.GetHookContext(c, t.relid, "")
+		com, err := jujuc.NewCommand(hctx, "network-get")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+		c.Assert(bufferString(ctx.Stdout), Equals, t.out)
+	}
+}
+
+func (s *NetworkGetSuite) TestErrors(c *C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, "network-get")
+	c.Assert(err, IsNil)
+	testing.TestInit(c, com, []string{"ingress-address"}, "no relation id specified")
+
+	com, err = jujuc.NewCommand(hctx, "network-get")
+	c.Assert(err, IsNil)
+	testing.TestInit(c, com, []string{"colour"}, `unknown key "colour"`)
+
+	com, err = jujuc.NewCommand(hctx, "network-get")
+	c.Assert(err, IsNil)
+	testing.TestInit(c, com, []string{"addresses", "blah"}, `unrecognized args: \["blah"\]`)
+
+	com, err = jujuc.NewCommand(hctx, "network-get")
+	c.Assert(err, IsNil)
+	testing.TestInit(c, com, []string{"-r", "42", "ingress-address"}, `invalid value "42" for flag -r: unknown relation id`)
+}
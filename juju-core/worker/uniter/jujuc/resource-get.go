@@ -0,0 +1,58 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+)
+
+// ResourceGetCommand implements the resource-get command.
+type ResourceGetCommand struct {
+	cmd.CommandBase
+	ctx  Context
+	Name string
+	out  cmd.Output
+}
+
+func NewResourceGetCommand(ctx Context) cmd.Command {
+	return &ResourceGetCommand{ctx: ctx}
+}
+
+func (c *ResourceGetCommand) Info() *cmd.Info {
+	doc := `
+The content last uploaded for the resource with juju deploy --resource or
+juju attach is downloaded and verified, unless it is already available
+on the unit, and the path to the local copy is printed.
+`
+	return &cmd.Info{
+		Name:    "resource-get",
+		Args:    "<name>",
+		Purpose: "get the path to a resource of the charm",
+		Doc:     doc,
+	}
+}
+
+func (c *ResourceGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+}
+
+func (c *ResourceGetCommand) Init(args []string) error {
+	if args == nil {
+		return fmt.Errorf("no resource specified")
+	}
+	c.Name = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *ResourceGetCommand) Run(ctx *cmd.Context) error {
+	path, err := c.ctx.Resource(c.Name)
+	if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, path)
+}
@@ -0,0 +1,55 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type ResourceGetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&ResourceGetSuite{})
+
+func (s *ResourceGetSuite) TestOutput(c *C) {
+	for i, t := range []struct {
+		args []string
+		out  string
+	}{
+		{[]string{"jdk"}, "/var/lib/juju/agents/unit-u-0/resources/jdk/0/jdk.tar.gz\n"},
+		{[]string{"--format", "json", "jdk"}, `"/var/lib/juju/agents/unit-u-0/resources/jdk/0/jdk.tar.gz"` + "\n"},
+	} {
+		c.Logf("test %d: %#v", i, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		com, err := jujuc.NewCommand(hctx, "resource-get")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+		c.Assert(bufferString(ctx.Stdout), Equals, t.out)
+	}
+}
+
+func (s *ResourceGetSuite) TestErrors(c *C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, "resource-get")
+	c.Assert(err, IsNil)
+	testing.TestInit(c, com, nil, "no resource specified")
+	com, err = jujuc.NewCommand(hctx, "resource-get")
+	c.Assert(err, IsNil)
+	testing.TestInit(c, com, []string{"jdk", "blah"}, `unrecognized args: \["blah"\]`)
+
+	com, err = jujuc.NewCommand(hctx, "resource-get")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"vendor"})
+	c.Assert(code, Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), Equals, "error: resource \"vendor\" not found\n")
+}
@@ -21,14 +21,24 @@ import (
 
 // newCommands maps Command names to initializers.
 var newCommands = map[string]func(Context) cmd.Command{
+	"action-get":    NewActionGetCommand,
+	"action-set":    NewActionSetCommand,
 	"close-port":    NewClosePortCommand,
 	"config-get":    NewConfigGetCommand,
+	"is-leader":     NewIsLeaderCommand,
 	"juju-log":      NewJujuLogCommand,
+	"leader-get":    NewLeaderGetCommand,
+	"leader-set":    NewLeaderSetCommand,
+	"network-get":   NewNetworkGetCommand,
 	"open-port":     NewOpenPortCommand,
 	"relation-get":  NewRelationGetCommand,
 	"relation-ids":  NewRelationIdsCommand,
 	"relation-list": NewRelationListCommand,
 	"relation-set":  NewRelationSetCommand,
+	"resource-get":  NewResourceGetCommand,
+	"status-get":    NewStatusGetCommand,
+	"status-set":    NewStatusSetCommand,
+	"storage-get":   NewStorageGetCommand,
 	"unit-get":      NewUnitGetCommand,
 }
 
@@ -190,14 +190,24 @@ var newCommandTests = []struct {
 	name string
 	err  string
 }{
+	{"action-get", ""},
+	{"action-set", ""},
 	{"close-port", ""},
 	{"config-get", ""},
+	{"is-leader", ""},
 	{"juju-log", ""},
+	{"leader-get", ""},
+	{"leader-set", ""},
+	{"network-get", ""},
 	{"open-port", ""},
 	{"relation-get", ""},
 	{"relation-ids", ""},
 	{"relation-list", ""},
 	{"relation-set", ""},
+	{"resource-get", ""},
+	{"status-get", ""},
+	{"status-set", ""},
+	{"storage-get", ""},
 	{"unit-get", ""},
 	{"random", "unknown command: random"},
 }
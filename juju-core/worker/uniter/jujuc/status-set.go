@@ -0,0 +1,62 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// StatusSetCommand implements the status-set command.
+type StatusSetCommand struct {
+	cmd.CommandBase
+	ctx     Context
+	Status  params.WorkloadStatus
+	Message string
+}
+
+func NewStatusSetCommand(ctx Context) cmd.Command {
+	return &StatusSetCommand{ctx: ctx}
+}
+
+func (c *StatusSetCommand) Info() *cmd.Info {
+	doc := `
+Report the status of the unit's workload to the operator, who sees it in
+juju status alongside the status of the unit agent. The status is one of
+maintenance, waiting, blocked or active, and the message explains it, as
+in "waiting for database" or "missing config option".
+`
+	return &cmd.Info{
+		Name:    "status-set",
+		Args:    "<maintenance|waiting|blocked|active> [<message>]",
+		Purpose: "set workload status",
+		Doc:     doc,
+	}
+}
+
+func (c *StatusSetCommand) SetFlags(f *gnuflag.FlagSet) {
+}
+
+func (c *StatusSetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no status specified")
+	}
+	c.Status = params.WorkloadStatus(args[0])
+	if !c.Status.Valid() || c.Status == params.WorkloadUnknown {
+		return fmt.Errorf("invalid status %q", args[0])
+	}
+	if len(args) == 1 {
+		return nil
+	}
+	c.Message = args[1]
+	return cmd.CheckEmpty(args[2:])
+}
+
+func (c *StatusSetCommand) Run(ctx *cmd.Context) error {
+	return c.ctx.SetWorkloadStatus(c.Status, c.Message)
+}
@@ -0,0 +1,63 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type StatusSetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&StatusSetSuite{})
+
+var statusSetInitTests = []struct {
+	args []string
+	err  string
+}{
+	{nil, "no status specified"},
+	{[]string{"sleepy"}, `invalid status "sleepy"`},
+	{[]string{"unknown"}, `invalid status "unknown"`},
+	{[]string{"blocked", "missing config", "option"}, `unrecognized args: \["option"\]`},
+}
+
+func (s *StatusSetSuite) TestInitErrors(c *C) {
+	for i, t := range statusSetInitTests {
+		c.Logf("test %d: %#v", i, t.args)
+		com, err := jujuc.NewCommand(s.GetHookContext(c, -1, ""), "status-set")
+		c.Assert(err, IsNil)
+		testing.TestInit(c, com, t.args, t.err)
+	}
+}
+
+func (s *StatusSetSuite) TestStatusSet(c *C) {
+	for i, t := range []struct {
+		args   []string
+		status params.WorkloadStatus
+		info   string
+	}{
+		{[]string{"maintenance", "installing packages"}, params.WorkloadMaintenance, "installing packages"},
+		{[]string{"waiting", "waiting for database"}, params.WorkloadWaiting, "waiting for database"},
+		{[]string{"active"}, params.WorkloadActive, ""},
+	} {
+		c.Logf("test %d: %#v", i, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		com, err := jujuc.NewCommand(hctx, "status-set")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+		status, info, err := hctx.WorkloadStatus()
+		c.Assert(err, IsNil)
+		c.Assert(status, Equals, t.status)
+		c.Assert(info, Equals, t.info)
+	}
+}
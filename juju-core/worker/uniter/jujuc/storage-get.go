@@ -0,0 +1,79 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/cmd"
+)
+
+// StorageGetCommand implements the storage-get command.
+type StorageGetCommand struct {
+	cmd.CommandBase
+	ctx       Context
+	StorageId string
+	Key       string // The key to show. If empty, show all.
+	out       cmd.Output
+}
+
+func NewStorageGetCommand(ctx Context) cmd.Command {
+	return &StorageGetCommand{ctx: ctx}
+}
+
+func (c *StorageGetCommand) Info() *cmd.Info {
+	doc := `
+When no <key> is supplied, all the details of the storage instance are
+printed. The keys are kind, location, device and size, in MiB. The
+storage instance is the one of the executing storage hook, unless one
+is given with -s.
+`
+	return &cmd.Info{
+		Name:    "storage-get",
+		Args:    "[<key>]",
+		Purpose: "print information about a storage instance",
+		Doc:     doc,
+	}
+}
+
+func (c *StorageGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+	c.StorageId, _ = c.ctx.HookStorageId()
+	f.StringVar(&c.StorageId, "s", c.StorageId, "specify a storage instance by id")
+}
+
+func (c *StorageGetCommand) Init(args []string) error {
+	if c.StorageId == "" {
+		return fmt.Errorf("no storage instance specified")
+	}
+	if args == nil {
+		return nil
+	}
+	c.Key = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *StorageGetCommand) Run(ctx *cmd.Context) error {
+	store, err := c.ctx.Storage(c.StorageId)
+	if err != nil {
+		return err
+	}
+	details := map[string]interface{}{
+		"kind":     string(store.Kind()),
+		"location": store.Location(),
+		"device":   store.Device(),
+		"size":     store.Size(),
+	}
+	var value interface{}
+	if c.Key == "" {
+		value = details
+	} else if v, ok := details[c.Key]; ok {
+		value = v
+	} else {
+		return fmt.Errorf("unknown key %q", c.Key)
+	}
+	return c.out.Write(ctx, value)
+}
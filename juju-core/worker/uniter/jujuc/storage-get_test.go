@@ -0,0 +1,71 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type StorageGetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&StorageGetSuite{})
+
+var storageGetTests = []struct {
+	args []string
+	out  string
+}{
+	{nil, "device: /dev/sdb\nkind: filesystem\nlocation: /srv/data\nsize: 1024\n"},
+	{[]string{"location"}, "/srv/data\n"},
+	{[]string{"--format", "json", "size"}, "1024\n"},
+	{[]string{"-s", "disks/0", "device"}, "/dev/sdc\n"},
+	{[]string{"-s", "disks/0", "location"}, ""},
+}
+
+func (s *StorageGetSuite) TestOutput(c *C) {
+	for i, t := range storageGetTests {
+		c.Logf("test %d: %#v", i, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		hctx.storageId = "data/0"
+		com, err := jujuc.NewCommand(hctx, "storage-get")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+		c.Assert(bufferString(ctx.Stdout), Equals, t.out)
+	}
+}
+
+func (s *StorageGetSuite) TestErrors(c *C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, "storage-get")
+	c.Assert(err, IsNil)
+	testing.TestInit(c, com, nil, "no storage instance specified")
+
+	hctx.storageId = "data/0"
+	com, err = jujuc.NewCommand(hctx, "storage-get")
+	c.Assert(err, IsNil)
+	testing.TestInit(c, com, []string{"kind", "blah"}, `unrecognized args: \["blah"\]`)
+
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"colour"}, `error: unknown key "colour"` + "\n"},
+		{[]string{"-s", "data/42"}, `error: storage instance "data/42" not found` + "\n"},
+	} {
+		com, err = jujuc.NewCommand(hctx, "storage-get")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 1)
+		c.Assert(bufferString(ctx.Stderr), Equals, t.err)
+	}
+}
@@ -9,10 +9,13 @@ import (
 	"io"
 	. "launchpad.net/gocheck"
 	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/instance"
 	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/utils/set"
 	"launchpad.net/juju-core/worker/uniter/jujuc"
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -73,10 +76,17 @@ func setSettings(c *C, ru *state.RelationUnit, settings map[string]interface{})
 }
 
 type Context struct {
-	ports  set.Strings
-	relid  int
-	remote string
-	rels   map[int]*ContextRelation
+	ports          set.Strings
+	relid          int
+	remote         string
+	rels           map[int]*ContextRelation
+	actionParams   map[string]interface{}
+	actionResults  map[string]interface{}
+	workloadStatus params.WorkloadStatus
+	workloadInfo   string
+	leader         bool
+	leaderSettings map[string]string
+	storageId      string
 }
 
 func (c *Context) UnitName() string {
@@ -132,6 +142,142 @@ func (c *Context) RelationIds() []int {
 	return ids
 }
 
+func (c *Context) WorkloadStatus() (params.WorkloadStatus, string, error) {
+	if c.workloadStatus == "" {
+		return params.WorkloadUnknown, "", nil
+	}
+	return c.workloadStatus, c.workloadInfo, nil
+}
+
+func (c *Context) SetWorkloadStatus(status params.WorkloadStatus, info string) error {
+	c.workloadStatus = status
+	c.workloadInfo = info
+	return nil
+}
+
+func (c *Context) IsLeader() (bool, error) {
+	return c.leader, nil
+}
+
+func (c *Context) LeaderSettings() (map[string]string, error) {
+	settings := map[string]string{}
+	for k, v := range c.leaderSettings {
+		settings[k] = v
+	}
+	return settings, nil
+}
+
+func (c *Context) WriteLeaderSettings(settings map[string]string) error {
+	if !c.leader {
+		return fmt.Errorf("not the leader")
+	}
+	if c.leaderSettings == nil {
+		c.leaderSettings = map[string]string{}
+	}
+	for k, v := range settings {
+		if v == "" {
+			delete(c.leaderSettings, k)
+		} else {
+			c.leaderSettings[k] = v
+		}
+	}
+	return nil
+}
+
+func (c *Context) ActionParams() (map[string]interface{}, error) {
+	if c.actionParams == nil {
+		return nil, fmt.Errorf("not running an action")
+	}
+	return c.actionParams, nil
+}
+
+func (c *Context) UpdateActionResults(results map[string]interface{}) error {
+	if c.actionParams == nil {
+		return fmt.Errorf("not running an action")
+	}
+	if c.actionResults == nil {
+		c.actionResults = map[string]interface{}{}
+	}
+	for k, v := range results {
+		c.actionResults[k] = v
+	}
+	return nil
+}
+
+func (c *Context) HookStorageId() (string, bool) {
+	return c.storageId, c.storageId != ""
+}
+
+func (c *Context) Storage(id string) (jujuc.ContextStorage, error) {
+	store, found := storage[id]
+	if !found {
+		return nil, fmt.Errorf("storage instance %q not found", id)
+	}
+	return store, nil
+}
+
+func (c *Context) Resource(name string) (string, error) {
+	if name != "jdk" {
+		return "", fmt.Errorf("resource %q not found", name)
+	}
+	return "/var/lib/juju/agents/unit-u-0/resources/jdk/0/jdk.tar.gz", nil
+}
+
+func (c *Context) NetworkAddresses() ([]instance.Address, error) {
+	return []instance.Address{
+		instance.NewScopedAddress("127.0.0.1"),
+		instance.NewScopedAddress("192.168.0.99"),
+	}, nil
+}
+
+var storage = map[string]*ContextStorage{
+	"data/0": {
+		id:       "data/0",
+		kind:     charm.StorageFilesystem,
+		location: "/srv/data",
+		device:   "/dev/sdb",
+		size:     1024,
+	},
+	"disks/0": {
+		id:     "disks/0",
+		kind:   charm.StorageBlock,
+		device: "/dev/sdc",
+		size:   512,
+	},
+}
+
+type ContextStorage struct {
+	id       string
+	kind     charm.StorageType
+	location string
+	device   string
+	size     uint64
+}
+
+func (s *ContextStorage) Id() string {
+	return s.id
+}
+
+func (s *ContextStorage) Name() string {
+	return s.id[:strings.Index(s.id, "/")]
+}
+
+func (s *ContextStorage) Kind() charm.StorageType {
+	return s.kind
+}
+
+func (s *ContextStorage) Location() string {
+	return s.location
+}
+
+func (s *ContextStorage) Device() string {
+	return s.device
+}
+
+func (s *ContextStorage) Size() uint64 {
+	return s.size
+}
+
 type ContextRelation struct {
 	id    int
 	name  string
@@ -171,6 +317,10 @@ func (r *ContextRelation) ReadSettings(name string) (map[string]interface{}, err
 	return s.Map(), nil
 }
 
+func (r *ContextRelation) IngressAddress() (string, error) {
+	return "192.168.0.99", nil
+}
+
 type Settings map[string]interface{}
 
 func (s Settings) Get(k string) (interface{}, bool) {
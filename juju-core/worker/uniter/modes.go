@@ -6,11 +6,11 @@ package uniter
 import (
 	stderrors "errors"
 	"fmt"
+	"time"
+
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/charm/hooks"
 	"launchpad.net/juju-core/environs"
-	"launchpad.net/juju-core/errors"
-	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/watcher"
 	"launchpad.net/juju-core/worker"
@@ -27,11 +27,11 @@ type Mode func(u *Uniter) (Mode, error)
 func ModeInit(u *Uniter) (next Mode, err error) {
 	defer modeContext("ModeInit", &err)()
 	logger.Infof("updating unit addresses")
-	cfg, err := u.st.EnvironConfig()
+	providerType, err := u.st.ProviderType()
 	if err != nil {
 		return nil, err
 	}
-	provider, err := environs.Provider(cfg.Type())
+	provider, err := environs.Provider(providerType)
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +62,10 @@ func ModeContinue(u *Uniter) (next Mode, err error) {
 		if u.s, err = u.sf.Read(); err == ErrNoStateFile {
 			// When no state exists, start from scratch.
 			logger.Infof("charm is not deployed")
-			curl, _ := u.service.CharmURL()
+			curl, _, err := u.service.CharmURL()
+			if err != nil {
+				return nil, err
+			}
 			return ModeInstalling(curl), nil
 		} else if err != nil {
 			return nil, err
@@ -189,7 +192,10 @@ func ModeTerminating(u *Uniter) (next Mode, err error) {
 	if err = u.unit.SetStatus(params.StatusStopped, ""); err != nil {
 		return nil, err
 	}
-	w := u.unit.Watch()
+	w, err := u.unit.Watch()
+	if err != nil {
+		return nil, err
+	}
 	defer watcher.Stop(w, &u.tomb)
 	for {
 		select {
@@ -202,7 +208,9 @@ func ModeTerminating(u *Uniter) (next Mode, err error) {
 			if err := u.unit.Refresh(); err != nil {
 				return nil, err
 			}
-			if len(u.unit.SubordinateNames()) > 0 {
+			if hasSubs, err := u.unit.HasSubordinates(); err != nil {
+				return nil, err
+			} else if hasSubs {
 				continue
 			}
 			// The unit is known to be Dying; so if it didn't have subordinates
@@ -219,6 +227,7 @@ func ModeTerminating(u *Uniter) (next Mode, err error) {
 // * service configuration changes
 // * charm upgrade requests
 // * relation changes
+// * storage provisioning
 // * unit death
 func ModeAbide(u *Uniter) (next Mode, err error) {
 	defer modeContext("ModeAbide", &err)()
@@ -230,7 +239,9 @@ func ModeAbide(u *Uniter) (next Mode, err error) {
 	}
 	u.f.WantUpgradeEvent(false)
 	for _, r := range u.relationers {
-		r.StartHooks()
+		if err := r.StartHooks(); err != nil {
+			return nil, err
+		}
 	}
 	defer func() {
 		for _, r := range u.relationers {
@@ -266,11 +277,53 @@ func modeAbideAliveLoop(u *Uniter) (Mode, error) {
 				return nil, err
 			}
 			for _, r := range added {
-				r.StartHooks()
+				if err := r.StartHooks(); err != nil {
+					return nil, err
+				}
 			}
 			continue
 		case curl := <-u.f.UpgradeEvents():
 			return ModeUpgrading(curl), nil
+		case id := <-u.f.ActionEvents():
+			if err := u.runAction(hook.Info{Kind: hooks.Action, ActionId: id}); err != nil {
+				return nil, err
+			}
+			continue
+		case <-u.f.LeadershipEvents():
+			elected, err := u.claimLeadership()
+			if err != nil {
+				return nil, err
+			}
+			if !elected {
+				continue
+			}
+			hi = hook.Info{Kind: hooks.LeaderElected}
+		case <-u.f.LeaderSettingsEvents():
+			// Leadership is settled first, so that a unit never reads
+			// settings as a follower just before being elected; the
+			// leader wrote the settings itself and need not be told.
+			elected, err := u.claimLeadership()
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case elected:
+				hi = hook.Info{Kind: hooks.LeaderElected}
+			case u.leader:
+				continue
+			default:
+				hi = hook.Info{Kind: hooks.LeaderSettingsChanged}
+			}
+		case <-u.f.StorageEvents():
+			// Committing a storage-attached hook changes the store, so
+			// the remaining stores are handled in subsequent events.
+			var found bool
+			var err error
+			if hi, found, err = u.nextStorageHook(hooks.StorageAttached); err != nil {
+				return nil, err
+			} else if !found {
+				continue
+			}
 		}
 		if err := u.runHook(hi); err == errHookFailed {
 			return ModeHookError, nil
@@ -286,25 +339,32 @@ func modeAbideDyingLoop(u *Uniter) (next Mode, err error) {
 	if err := u.unit.Refresh(); err != nil {
 		return nil, err
 	}
-	for _, name := range u.unit.SubordinateNames() {
-		if sub, err := u.st.Unit(name); errors.IsNotFoundError(err) {
-			continue
-		} else if err != nil {
-			return nil, err
-		} else if err = sub.Destroy(); err != nil {
-			return nil, err
-		}
+	if err = u.unit.DestroyAllSubordinates(); err != nil {
+		return nil, err
 	}
 	for id, r := range u.relationers {
 		if err := r.SetDying(); err != nil {
 			return nil, err
 		} else if r.IsImplicit() {
-			delete(u.relationers, id)
+			u.removeRelationer(id)
 		}
 	}
 	for {
 		if len(u.relationers) == 0 {
-			return ModeStopping, nil
+			// Once the relations are gone, the charm is told about the
+			// departure of its stores, one at a time.
+			hi, found, err := u.nextStorageHook(hooks.StorageDetaching)
+			if err != nil {
+				return nil, err
+			} else if !found {
+				return ModeStopping, nil
+			}
+			if err = u.runHook(hi); err == errHookFailed {
+				return ModeHookError, nil
+			} else if err != nil {
+				return nil, err
+			}
+			continue
 		}
 		hi := hook.Info{}
 		select {
@@ -322,16 +382,67 @@ func modeAbideDyingLoop(u *Uniter) (next Mode, err error) {
 	}
 }
 
+// Failed hooks are retried automatically, if the hook policy of the
+// service asks for it, after a delay that doubles with every attempt
+// up to a maximum. These are the delays used when the policy does not
+// specify them.
+var (
+	defaultHookRetryDelay    = 10 * time.Second
+	defaultMaxHookRetryDelay = 5 * time.Minute
+)
+
+// hookRetryDelay returns the time to wait before retrying a failed hook
+// for the given attempt, counting from zero.
+func hookRetryDelay(policy params.HookPolicy, attempt int) time.Duration {
+	delay, max := policy.RetryDelay, policy.MaxRetryDelay
+	if delay == 0 {
+		delay = defaultHookRetryDelay
+	}
+	if max == 0 {
+		max = defaultMaxHookRetryDelay
+	}
+	if max < delay {
+		max = delay
+	}
+	for i := 0; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
 // ModeHookError is responsible for watching and responding to:
 // * user resolution of hook errors
 // * charm upgrade requests
+// * automatic retries of the failed hook
+// * changes to the hook policy of the service
 func ModeHookError(u *Uniter) (next Mode, err error) {
 	defer modeContext("ModeHookError", &err)()
 	if u.s.Op != RunHook || u.s.OpStep != Pending {
 		return nil, fmt.Errorf("insane uniter state: %#v", u.s)
 	}
-	msg := fmt.Sprintf("hook failed: %q", u.s.Hook.Kind)
-	if err = u.unit.SetStatus(params.StatusError, msg); err != nil {
+	var policy params.HookPolicy
+	var retry <-chan time.Time
+	// setPolicy schedules the next retry of the failed hook according
+	// to the policy, and reports it in the status of the unit.
+	setPolicy := func(p params.HookPolicy) error {
+		policy = p
+		msg := fmt.Sprintf("hook failed: %q", u.s.Hook.Kind)
+		retry = nil
+		if policy.Retry {
+			delay := hookRetryDelay(policy, u.hookRetries)
+			msg += fmt.Sprintf("; retrying in %v (attempt %d)", delay, u.hookRetries+1)
+			retry = time.After(delay)
+		}
+		return u.unit.SetStatus(params.StatusError, msg)
+	}
+	p, err := u.service.HookPolicy()
+	if err != nil {
+		return nil, err
+	}
+	if err = setPolicy(p); err != nil {
 		return nil, err
 	}
 	u.f.WantResolvedEvent()
@@ -340,11 +451,21 @@ func ModeHookError(u *Uniter) (next Mode, err error) {
 		select {
 		case <-u.tomb.Dying():
 			return nil, tomb.ErrDying
+		case <-u.f.HookPolicyEvents():
+			p, err := u.service.HookPolicy()
+			if err != nil {
+				return nil, err
+			}
+			if p != policy {
+				if err = setPolicy(p); err != nil {
+					return nil, err
+				}
+			}
 		case rm := <-u.f.ResolvedEvents():
 			switch rm {
-			case state.ResolvedRetryHooks:
+			case params.ResolvedRetryHooks:
 				err = u.runHook(*u.s.Hook)
-			case state.ResolvedNoHooks:
+			case params.ResolvedNoHooks:
 				err = u.commitHook(*u.s.Hook)
 			default:
 				return nil, fmt.Errorf("unknown resolved mode %q", rm)
@@ -357,8 +478,20 @@ func ModeHookError(u *Uniter) (next Mode, err error) {
 			} else if err != nil {
 				return nil, err
 			}
+			u.hookRetries = 0
+			return ModeContinue, nil
+		case <-retry:
+			u.hookRetries++
+			logger.Infof("retrying failed %q hook (attempt %d)", u.s.Hook.Kind, u.hookRetries)
+			if err = u.runHook(*u.s.Hook); err == errHookFailed {
+				return ModeHookError, nil
+			} else if err != nil {
+				return nil, err
+			}
+			u.hookRetries = 0
 			return ModeContinue, nil
 		case curl := <-u.f.UpgradeEvents():
+			u.hookRetries = 0
 			return ModeUpgrading(curl), nil
 		}
 	}
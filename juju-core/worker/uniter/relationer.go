@@ -6,7 +6,7 @@ package uniter
 import (
 	"fmt"
 	"launchpad.net/juju-core/charm/hooks"
-	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/uniter"
 	"launchpad.net/juju-core/worker/uniter/hook"
 	"launchpad.net/juju-core/worker/uniter/relation"
 )
@@ -14,7 +14,7 @@ import (
 // Relationer manages a unit's presence in a relation.
 type Relationer struct {
 	ctx   *ContextRelation
-	ru    *state.RelationUnit
+	ru    *uniter.RelationUnit
 	dir   *relation.StateDir
 	queue relation.HookQueue
 	hooks chan<- hook.Info
@@ -23,7 +23,7 @@ type Relationer struct {
 
 // NewRelationer creates a new Relationer. The unit will not join the
 // relation until explicitly requested.
-func NewRelationer(ru *state.RelationUnit, dir *relation.StateDir, hooks chan<- hook.Info) *Relationer {
+func NewRelationer(ru *uniter.RelationUnit, dir *relation.StateDir, hooks chan<- hook.Info) *Relationer {
 	return &Relationer{
 		ctx:   NewContextRelation(ru, dir.State().Members),
 		ru:    ru,
@@ -50,11 +50,7 @@ func (r *Relationer) Join() error {
 	if r.dying {
 		panic("dying relationer must not join!")
 	}
-	address, ok := r.ru.PrivateAddress()
-	if !ok {
-		return fmt.Errorf("cannot enter scope: private-address not set")
-	}
-	return r.ru.EnterScope(map[string]interface{}{"private-address": address})
+	return r.ru.EnterScope()
 }
 
 // SetDying informs the relationer that the unit is departing the relation,
@@ -69,7 +65,8 @@ func (r *Relationer) SetDying() error {
 		if err := r.StopHooks(); err != nil {
 			return err
 		}
-		defer r.StartHooks()
+		r.dying = true
+		return r.StartHooks()
 	}
 	r.dying = true
 	return nil
@@ -87,9 +84,9 @@ func (r *Relationer) die() error {
 // StartHooks starts watching the relation, and sending hook.Info events on the
 // hooks channel. It will panic if called when already responding to relation
 // changes.
-func (r *Relationer) StartHooks() {
+func (r *Relationer) StartHooks() error {
 	if r.IsImplicit() {
-		return
+		return nil
 	}
 	if r.queue != nil {
 		panic("hooks already started!")
@@ -97,8 +94,13 @@ func (r *Relationer) StartHooks() {
 	if r.dying {
 		r.queue = relation.NewDyingHookQueue(r.dir.State(), r.hooks)
 	} else {
-		r.queue = relation.NewAliveHookQueue(r.dir.State(), r.hooks, r.ru.Watch())
+		w, err := r.ru.Watch()
+		if err != nil {
+			return err
+		}
+		r.queue = relation.NewAliveHookQueue(r.dir.State(), r.hooks, w)
 	}
+	return nil
 }
 
 // StopHooks ensures that the relationer is not watching the relation, or sending
@@ -16,6 +16,8 @@ import (
 	"launchpad.net/juju-core/errors"
 	jujutesting "launchpad.net/juju-core/juju/testing"
 	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api"
+	apiuniter "launchpad.net/juju-core/state/api/uniter"
 	coretesting "launchpad.net/juju-core/testing"
 	"launchpad.net/juju-core/testing/checkers"
 	"launchpad.net/juju-core/worker/uniter"
@@ -28,9 +30,12 @@ type RelationerSuite struct {
 	hooks   chan hook.Info
 	svc     *state.Service
 	rel     *state.Relation
-	ru      *state.RelationUnit
 	dir     *relation.StateDir
 	dirPath string
+
+	st         *api.State
+	uniter     *apiuniter.State
+	apiRelUnit *apiuniter.RelationUnit
 }
 
 var _ = Suite(&RelationerSuite{})
@@ -44,11 +49,47 @@ func (s *RelationerSuite) SetUpTest(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(rels, HasLen, 1)
 	s.rel = rels[0]
-	s.ru = s.AddRelationUnit(c, "u/0")
+	s.AddRelationUnit(c, "u/0")
 	s.dirPath = c.MkDir()
 	s.dir, err = relation.ReadStateDir(s.dirPath, s.rel.Id())
 	c.Assert(err, IsNil)
 	s.hooks = make(chan hook.Info)
+
+	// Log in as the agent of u/0, and get its API relation unit.
+	unit, err := s.State.Unit("u/0")
+	c.Assert(err, IsNil)
+	s.st, s.uniter = loginAsUnit(c, &s.JujuConnSuite, unit)
+	s.apiRelUnit = apiRelationUnit(c, s.uniter, s.rel, unit)
+}
+
+func (s *RelationerSuite) TearDownTest(c *C) {
+	if s.st != nil {
+		err := s.st.Close()
+		c.Assert(err, IsNil)
+		s.st = nil
+	}
+	s.JujuConnSuite.TearDownTest(c)
+}
+
+// loginAsUnit sets a password for the given unit and connects to the
+// API as its agent.
+func loginAsUnit(c *C, s *jujutesting.JujuConnSuite, unit *state.Unit) (*api.State, *apiuniter.State) {
+	err := unit.SetPassword("password")
+	c.Assert(err, IsNil)
+	st := s.OpenAPIAs(c, unit.Tag(), "password")
+	uniter := st.Uniter()
+	c.Assert(uniter, NotNil)
+	return st, uniter
+}
+
+// apiRelationUnit returns the API view of the given unit in the given
+// relation.
+func apiRelationUnit(c *C, st *apiuniter.State, rel *state.Relation, unit *state.Unit) *apiuniter.RelationUnit {
+	apiRel, err := st.Relation(rel.String())
+	c.Assert(err, IsNil)
+	apiUnit, err := st.Unit(unit.Tag())
+	c.Assert(err, IsNil)
+	return apiRel.Unit(apiUnit)
 }
 
 func (s *RelationerSuite) AddRelationUnit(c *C, name string) *state.RelationUnit {
@@ -64,12 +105,12 @@ func (s *RelationerSuite) AddRelationUnit(c *C, name string) *state.RelationUnit
 
 func (s *RelationerSuite) TestEnterLeaveScope(c *C) {
 	ru1 := s.AddRelationUnit(c, "u/1")
-	r := uniter.NewRelationer(s.ru, s.dir, s.hooks)
+	r := uniter.NewRelationer(s.apiRelUnit, s.dir, s.hooks)
 
 	// u/1 does not consider u/0 to be alive.
 	w := ru1.Watch()
 	defer stop(c, w)
-	s.State.StartSync()
+	s.BackingState.StartSync()
 	ch, ok := <-w.Changes()
 	c.Assert(ok, Equals, true)
 	c.Assert(ch.Changed, HasLen, 0)
@@ -78,7 +119,7 @@ func (s *RelationerSuite) TestEnterLeaveScope(c *C) {
 	// u/0 enters scope; u/1 observes it.
 	err := r.Join()
 	c.Assert(err, IsNil)
-	s.State.StartSync()
+	s.BackingState.StartSync()
 	select {
 	case ch, ok := <-w.Changes():
 		c.Assert(ok, Equals, true)
@@ -94,7 +135,7 @@ func (s *RelationerSuite) TestEnterLeaveScope(c *C) {
 	err = r.Join()
 	c.Assert(err, IsNil)
 	// TODO(jam): This would be a great to replace with statetesting.NotifyWatcherC
-	s.State.StartSync()
+	s.BackingState.StartSync()
 	select {
 	case ch, ok := <-w.Changes():
 		c.Fatalf("got unexpected change: %#v, %#v", ch, ok)
@@ -113,7 +154,7 @@ func (s *RelationerSuite) TestEnterLeaveScope(c *C) {
 
 	err = r.CommitHook(hi)
 	c.Assert(err, IsNil)
-	s.State.StartSync()
+	s.BackingState.StartSync()
 	select {
 	case ch, ok := <-w.Changes():
 		c.Assert(ok, Equals, true)
@@ -127,7 +168,7 @@ func (s *RelationerSuite) TestEnterLeaveScope(c *C) {
 func (s *RelationerSuite) TestStartStopHooks(c *C) {
 	ru1 := s.AddRelationUnit(c, "u/1")
 	ru2 := s.AddRelationUnit(c, "u/2")
-	r := uniter.NewRelationer(s.ru, s.dir, s.hooks)
+	r := uniter.NewRelationer(s.apiRelUnit, s.dir, s.hooks)
 	c.Assert(r.IsImplicit(), Equals, false)
 	err := r.Join()
 	c.Assert(err, IsNil)
@@ -136,7 +177,8 @@ func (s *RelationerSuite) TestStartStopHooks(c *C) {
 	s.assertNoHook(c)
 
 	// Start hooks, and check that still no changes are sent.
-	r.StartHooks()
+	err = r.StartHooks()
+	c.Assert(err, IsNil)
 	defer stopHooks(c, r)
 	s.assertNoHook(c)
 
@@ -180,7 +222,8 @@ func (s *RelationerSuite) TestStartStopHooks(c *C) {
 	s.assertNoHook(c)
 
 	// Start them again, and check we get the expected events sent.
-	r.StartHooks()
+	err = r.StartHooks()
+	c.Assert(err, IsNil)
 	defer stopHooks(c, r)
 	s.assertHook(c, hook.Info{
 		Kind:       hooks.RelationDeparted,
@@ -212,7 +255,7 @@ func (s *RelationerSuite) TestStartStopHooks(c *C) {
 }
 
 func (s *RelationerSuite) TestPrepareCommitHooks(c *C) {
-	r := uniter.NewRelationer(s.ru, s.dir, s.hooks)
+	r := uniter.NewRelationer(s.apiRelUnit, s.dir, s.hooks)
 	err := r.Join()
 	c.Assert(err, IsNil)
 	ctx := r.Context()
@@ -312,10 +355,11 @@ func (s *RelationerSuite) TestSetDying(c *C) {
 	settings := map[string]interface{}{"unit": "settings"}
 	err := ru1.EnterScope(settings)
 	c.Assert(err, IsNil)
-	r := uniter.NewRelationer(s.ru, s.dir, s.hooks)
+	r := uniter.NewRelationer(s.apiRelUnit, s.dir, s.hooks)
 	err = r.Join()
 	c.Assert(err, IsNil)
-	r.StartHooks()
+	err = r.StartHooks()
+	c.Assert(err, IsNil)
 	defer stopHooks(c, r)
 	s.assertHook(c, hook.Info{
 		Kind:       hooks.RelationJoined,
@@ -348,7 +392,7 @@ func (s *RelationerSuite) TestSetDying(c *C) {
 }
 
 func (s *RelationerSuite) assertNoHook(c *C) {
-	s.State.StartSync()
+	s.BackingState.StartSync()
 	select {
 	case hi, ok := <-s.hooks:
 		c.Fatalf("got unexpected hook info %#v (%t)", hi, ok)
@@ -357,7 +401,7 @@ func (s *RelationerSuite) assertNoHook(c *C) {
 }
 
 func (s *RelationerSuite) assertHook(c *C, expect hook.Info) {
-	s.State.StartSync()
+	s.BackingState.StartSync()
 	// We must ensure the local state dir exists first.
 	c.Assert(s.dir.Ensure(), IsNil)
 	select {
@@ -403,8 +447,9 @@ func (s *RelationerImplicitSuite) TestImplicitRelationer(c *C) {
 	c.Assert(err, IsNil)
 	rel, err := s.State.AddRelation(eps...)
 	c.Assert(err, IsNil)
-	ru, err := rel.Unit(u)
-	c.Assert(err, IsNil)
+	st, apiUniter := loginAsUnit(c, &s.JujuConnSuite, u)
+	defer st.Close()
+	ru := apiRelationUnit(c, apiUniter, rel, u)
 	relsDir := c.MkDir()
 	dir, err := relation.ReadStateDir(relsDir, rel.Id())
 	c.Assert(err, IsNil)
@@ -423,13 +468,14 @@ func (s *RelationerImplicitSuite) TestImplicitRelationer(c *C) {
 	c.Assert(err, IsNil)
 
 	// Join the other side; check no hooks are sent.
-	r.StartHooks()
+	err = r.StartHooks()
+	c.Assert(err, IsNil)
 	defer func() { c.Assert(r.StopHooks(), IsNil) }()
 	subru, err := rel.Unit(sub)
 	c.Assert(err, IsNil)
 	err = subru.EnterScope(map[string]interface{}{"some": "data"})
 	c.Assert(err, IsNil)
-	s.State.StartSync()
+	s.BackingState.StartSync()
 	select {
 	case <-time.After(coretesting.ShortWait):
 	case <-hooks:
@@ -0,0 +1,118 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"net"
+	"net/rpc"
+	"os"
+	"sync"
+)
+
+// RunListenerFile is the name of the socket, in the agent directory of
+// the unit, on which the uniter serves the commands of juju run.
+const RunListenerFile = "run.socket"
+
+// JujuRunEndpoint is the net/rpc method called to run commands.
+const JujuRunEndpoint = "JujuRunServer.RunCommands"
+
+// RunResult holds the exit code and the output of commands run in a hook
+// context.
+type RunResult struct {
+	Code   int
+	Stdout []byte
+	Stderr []byte
+}
+
+// CommandRunner runs commands in a hook context of a unit.
+type CommandRunner interface {
+	RunCommands(commands string) (*RunResult, error)
+}
+
+// JujuRunServer implements the juju run server in the form required by
+// net/rpc.
+type JujuRunServer struct {
+	runner CommandRunner
+}
+
+// RunCommands runs the commands and fills in result.
+func (r *JujuRunServer) RunCommands(commands string, result *RunResult) error {
+	res, err := r.runner.RunCommands(commands)
+	if err != nil {
+		return err
+	}
+	*result = *res
+	return nil
+}
+
+// RunListener serves the commands of juju run via a unix domain socket.
+type RunListener struct {
+	socketPath string
+	listener   net.Listener
+	server     *rpc.Server
+	closed     chan bool
+	closing    chan bool
+	wg         sync.WaitGroup
+}
+
+// NewRunListener returns a listener bound to socketPath, which will run
+// the commands it receives with runner once Run is called.
+func NewRunListener(runner CommandRunner, socketPath string) (*RunListener, error) {
+	server := rpc.NewServer()
+	if err := server.Register(&JujuRunServer{runner}); err != nil {
+		return nil, err
+	}
+	// The socket of a previous agent that did not shut down cleanly
+	// would prevent listening.
+	if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	s := &RunListener{
+		socketPath: socketPath,
+		listener:   listener,
+		server:     server,
+		closed:     make(chan bool),
+		closing:    make(chan bool),
+	}
+	return s, nil
+}
+
+// Run accepts new connections until it encounters an error, or until Close is
+// called, and then blocks until all existing connections have been closed.
+func (s *RunListener) Run() (err error) {
+	var conn net.Conn
+	for {
+		conn, err = s.listener.Accept()
+		if err != nil {
+			break
+		}
+		s.wg.Add(1)
+		go func(conn net.Conn) {
+			s.server.ServeConn(conn)
+			s.wg.Done()
+		}(conn)
+	}
+	select {
+	case <-s.closing:
+		// The error is the result of Close closing the listener.
+		err = nil
+	default:
+	}
+	s.wg.Wait()
+	close(s.closed)
+	return
+}
+
+// Close immediately stops accepting connections, and blocks until all existing
+// connections have been closed.
+func (s *RunListener) Close() {
+	close(s.closing)
+	s.listener.Close()
+	os.Remove(s.socketPath)
+	<-s.closed
+}
@@ -0,0 +1,80 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/rpc"
+	"path/filepath"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter"
+)
+
+type ListenerSuite struct {
+	testing.LoggingSuite
+	socketPath string
+}
+
+var _ = Suite(&ListenerSuite{})
+
+func (s *ListenerSuite) SetUpTest(c *C) {
+	s.LoggingSuite.SetUpTest(c)
+	s.socketPath = filepath.Join(c.MkDir(), uniter.RunListenerFile)
+}
+
+func (s *ListenerSuite) runCommands(c *C, commands string) (*uniter.RunResult, error) {
+	client, err := rpc.Dial("unix", s.socketPath)
+	c.Assert(err, IsNil)
+	defer client.Close()
+	var result uniter.RunResult
+	err = client.Call(uniter.JujuRunEndpoint, commands, &result)
+	return &result, err
+}
+
+func (s *ListenerSuite) TestRunCommands(c *C) {
+	listener, err := uniter.NewRunListener(&mockRunner{}, s.socketPath)
+	c.Assert(err, IsNil)
+	go listener.Run()
+	defer listener.Close()
+
+	result, err := s.runCommands(c, "some-command")
+	c.Assert(err, IsNil)
+	c.Assert(result, DeepEquals, &uniter.RunResult{
+		Code:   42,
+		Stdout: []byte("some-command stdout"),
+		Stderr: []byte("some-command stderr"),
+	})
+
+	_, err = s.runCommands(c, "fail")
+	c.Assert(err, ErrorMatches, "cannot run commands")
+}
+
+func (s *ListenerSuite) TestStaleSocket(c *C) {
+	err := ioutil.WriteFile(s.socketPath, nil, 0644)
+	c.Assert(err, IsNil)
+	listener, err := uniter.NewRunListener(&mockRunner{}, s.socketPath)
+	c.Assert(err, IsNil)
+	go listener.Run()
+	defer listener.Close()
+	result, err := s.runCommands(c, "some-command")
+	c.Assert(err, IsNil)
+	c.Assert(result.Code, Equals, 42)
+}
+
+type mockRunner struct{}
+
+func (*mockRunner) RunCommands(commands string) (*uniter.RunResult, error) {
+	if commands == "fail" {
+		return nil, fmt.Errorf("cannot run commands")
+	}
+	return &uniter.RunResult{
+		Code:   42,
+		Stdout: []byte(commands + " stdout"),
+		Stderr: []byte(commands + " stderr"),
+	}, nil
+}
@@ -11,9 +11,10 @@ import (
 )
 
 // EnsureJujucSymlinks creates a symbolic link to jujuc within dir for each
-// hook command. If the commands already exist, this operation does nothing.
+// hook command, and for the juju-run command. If the commands already exist,
+// this operation does nothing.
 func EnsureJujucSymlinks(dir string) (err error) {
-	for _, name := range jujuc.CommandNames() {
+	for _, name := range append(jujuc.CommandNames(), "juju-run") {
 		// The link operation fails when the target already exists,
 		// so this is a no-op when the command names already
 		// exist.
@@ -50,7 +50,7 @@ func (s *ToolsSuite) TestEnsureJujucSymlinks(c *C) {
 	err = uniter.EnsureJujucSymlinks(s.toolsDir)
 	c.Assert(err, IsNil)
 	mtimes := map[string]time.Time{}
-	for _, name := range jujuc.CommandNames() {
+	for _, name := range append(jujuc.CommandNames(), "juju-run") {
 		tool := filepath.Join(s.toolsDir, name)
 		mtimes[tool] = assertLink(tool)
 	}
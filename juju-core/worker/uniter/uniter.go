@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"launchpad.net/loggo"
@@ -19,8 +20,8 @@ import (
 	corecharm "launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/charm/hooks"
 	"launchpad.net/juju-core/cmd"
-	"launchpad.net/juju-core/errors"
-	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/api/uniter"
 	"launchpad.net/juju-core/state/watcher"
 	"launchpad.net/juju-core/utils"
 	"launchpad.net/juju-core/utils/fslock"
@@ -38,10 +39,10 @@ var logger = loggo.GetLogger("juju.worker.uniter")
 // the uniter's responses to them.
 type Uniter struct {
 	tomb          tomb.Tomb
-	st            *state.State
+	st            *uniter.State
 	f             *filter
-	unit          *state.Unit
-	service       *state.Service
+	unit          *uniter.Unit
+	service       *uniter.Service
 	relationers   map[int]*Relationer
 	relationHooks chan hook.Info
 	uuid          string
@@ -52,38 +53,52 @@ type Uniter struct {
 	relationsDir string
 	charm        *charm.GitDir
 	bundles      *charm.BundlesDir
+	resources    *charm.ResourcesDir
 	deployer     *charm.Deployer
 	s            *State
 	sf           *StateFile
 	rand         *rand.Rand
 	hookLock     *fslock.Lock
 
+	// runMu serializes hook execution with the commands run by
+	// RunCommands, which are served outside the uniter's goroutine,
+	// and guards the relationers and rand fields they both use.
+	runMu sync.Mutex
+
 	ranConfigChanged bool
+
+	// leader holds whether the unit was found to lead its service when
+	// it last claimed leadership.
+	leader bool
+
+	// hookRetries holds the number of times the failed hook has been
+	// retried automatically.
+	hookRetries int
 }
 
 // NewUniter creates a new Uniter which will install, run, and upgrade a
-// charm on behalf of the named unit, by executing hooks and operations
-// provoked by changes in st.
-func NewUniter(st *state.State, name string, dataDir string) *Uniter {
+// charm on behalf of the unit with the given unitTag, by executing hooks
+// and operations provoked by changes in st.
+func NewUniter(st *uniter.State, unitTag string, dataDir string) *Uniter {
 	u := &Uniter{
 		st:      st,
 		dataDir: dataDir,
 	}
 	go func() {
 		defer u.tomb.Done()
-		u.tomb.Kill(u.loop(name))
+		u.tomb.Kill(u.loop(unitTag))
 	}()
 	return u
 }
 
-func (u *Uniter) loop(name string) (err error) {
-	if err = u.init(name); err != nil {
+func (u *Uniter) loop(unitTag string) (err error) {
+	if err = u.init(unitTag); err != nil {
 		return err
 	}
 	logger.Infof("unit %q started", u.unit)
 
 	// Start filtering state change events for consumption by modes.
-	u.f, err = newFilter(u.st, name)
+	u.f, err = newFilter(u.st, unitTag)
 	if err != nil {
 		return err
 	}
@@ -92,12 +107,13 @@ func (u *Uniter) loop(name string) (err error) {
 		u.tomb.Kill(u.f.Wait())
 	}()
 
-	// Announce our presence to the world.
-	pinger, err := u.unit.SetAgentAlive()
+	// Serve the commands run with juju run.
+	runListener, err := NewRunListener(u, filepath.Join(u.baseDir, RunListenerFile))
 	if err != nil {
 		return err
 	}
-	defer watcher.Stop(pinger, &u.tomb)
+	go runListener.Run()
+	defer runListener.Close()
 
 	// Run modes until we encounter an error.
 	mode := ModeInit
@@ -133,9 +149,9 @@ func (u *Uniter) setupLocks() (err error) {
 	return nil
 }
 
-func (u *Uniter) init(name string) (err error) {
-	defer utils.ErrorContextf(&err, "failed to initialize uniter for unit %q", name)
-	u.unit, err = u.st.Unit(name)
+func (u *Uniter) init(unitTag string) (err error) {
+	defer utils.ErrorContextf(&err, "failed to initialize uniter for %q", unitTag)
+	u.unit, err = u.st.Unit(unitTag)
 	if err != nil {
 		return err
 	}
@@ -152,20 +168,19 @@ func (u *Uniter) init(name string) (err error) {
 	if err := os.MkdirAll(u.relationsDir, 0755); err != nil {
 		return err
 	}
-	u.service, err = u.st.Service(u.unit.ServiceName())
+	u.service, err = u.unit.Service()
 	if err != nil {
 		return err
 	}
-	var env *state.Environment
-	env, err = u.st.Environment()
+	u.uuid, err = u.st.EnvironUUID()
 	if err != nil {
 		return err
 	}
-	u.uuid = env.UUID()
 	u.relationers = map[int]*Relationer{}
 	u.relationHooks = make(chan hook.Info)
 	u.charm = charm.NewGitDir(filepath.Join(u.baseDir, "charm"))
 	u.bundles = charm.NewBundlesDir(filepath.Join(u.baseDir, "state", "bundles"))
+	u.resources = charm.NewResourcesDir(filepath.Join(u.baseDir, "resources"))
 	u.deployer = charm.NewDeployer(filepath.Join(u.baseDir, "state", "deployer"))
 	u.sf = NewStateFile(filepath.Join(u.baseDir, "state", "uniter"))
 	u.rand = rand.New(rand.NewSource(time.Now().Unix()))
@@ -279,6 +294,101 @@ func (u *Uniter) deploy(curl *corecharm.URL, reason Op) error {
 	return u.writeState(RunHook, status, hi, nil)
 }
 
+// acquireHookLock acquires the machine-wide lock serializing hook
+// execution, giving up if the uniter is dying.
+func (u *Uniter) acquireHookLock(message string) error {
+	// We want to make sure we don't block forever when locking, but take the
+	// tomb into account.
+	checkTomb := func() error {
+		select {
+		case <-u.tomb.Dying():
+			return tomb.ErrDying
+		default:
+			// no-op to fall through to return.
+		}
+		return nil
+	}
+	return u.hookLock.LockWithFunc(message, checkTomb)
+}
+
+// getHookContext returns a hook context for the unit, with the supplied
+// relation and remote unit if running a relation hook.
+func (u *Uniter) getHookContext(hctxId string, relationId int, remoteUnitName string) (*HookContext, error) {
+	ctxRelations := map[int]*ContextRelation{}
+	for id, r := range u.relationers {
+		ctxRelations[id] = r.Context()
+	}
+	apiAddrs, err := u.st.APIAddresses()
+	if err != nil {
+		return nil, err
+	}
+	hctx, err := NewHookContext(u.unit, hctxId, u.uuid, relationId, remoteUnitName,
+		ctxRelations, apiAddrs)
+	if err != nil {
+		return nil, err
+	}
+	hctx.charmDir = u.charm.Path()
+	hctx.resources = u.resources
+	return hctx, nil
+}
+
+// startJujucServer starts the server executing the hook tools run
+// within hctx, and returns it with the path of its socket.
+func (u *Uniter) startJujucServer(hctx *HookContext) (*jujuc.Server, string, error) {
+	hctxId := hctx.id
+	getCmd := func(ctxId, cmdName string) (cmd.Command, error) {
+		// TODO: switch to long-running server with single context;
+		// use nonce in place of context id.
+		if ctxId != hctxId {
+			return nil, fmt.Errorf("expected context id %q, got %q", hctxId, ctxId)
+		}
+		return jujuc.NewCommand(hctx, cmdName)
+	}
+	socketPath := filepath.Join(u.baseDir, "agent.socket")
+	srv, err := jujuc.NewServer(getCmd, socketPath)
+	if err != nil {
+		return nil, "", err
+	}
+	go srv.Run()
+	return srv, socketPath, nil
+}
+
+// RunCommands executes the supplied commands in a hook context of the
+// unit, as the juju run command does. The commands are never run at the
+// same time as a hook.
+func (u *Uniter) RunCommands(commands string) (result *RunResult, err error) {
+	logger.Tracef("run commands: %s", commands)
+	u.runMu.Lock()
+	defer u.runMu.Unlock()
+	if _, err := os.Stat(u.charm.Path()); err != nil {
+		return nil, fmt.Errorf("charm of unit %q not yet deployed", u.unit.Name())
+	}
+	hctxId := fmt.Sprintf("%s:run-commands:%d", u.unit.Name(), u.rand.Int63())
+	lockMessage := fmt.Sprintf("%s: running commands", u.unit.Name())
+	if err = u.acquireHookLock(lockMessage); err != nil {
+		return nil, err
+	}
+	defer u.hookLock.Unlock()
+
+	hctx, err := u.getHookContext(hctxId, -1, "")
+	if err != nil {
+		return nil, err
+	}
+	srv, socketPath, err := u.startJujucServer(hctx)
+	if err != nil {
+		return nil, err
+	}
+	defer srv.Close()
+
+	logger.Infof("running commands")
+	result, err = hctx.RunCommands(commands, u.charm.Path(), u.toolsDir, socketPath)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("ran commands, exit code %d", result.Code)
+	return result, nil
+}
+
 // errHookFailed indicates that a hook failed to execute, but that the Uniter's
 // operation is not affected by the error.
 var errHookFailed = stderrors.New("hook execution failed")
@@ -290,6 +400,8 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 	if err = hi.Validate(); err != nil {
 		return err
 	}
+	u.runMu.Lock()
+	defer u.runMu.Unlock()
 
 	hookName := string(hi.Kind)
 	relationId := -1
@@ -298,51 +410,36 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 		if hookName, err = u.relationers[relationId].PrepareHook(hi); err != nil {
 			return err
 		}
-	}
-	hctxId := fmt.Sprintf("%s:%s:%d", u.unit.Name(), hookName, u.rand.Int63())
-	// We want to make sure we don't block forever when locking, but take the
-	// tomb into account.
-	checkTomb := func() error {
-		select {
-		case <-u.tomb.Dying():
-			return tomb.ErrDying
-		default:
-			// no-op to fall through to return.
+	} else if hi.Kind.IsStorage() {
+		store, err := u.storageInstance(hi.StorageId)
+		if err != nil {
+			return err
 		}
-		return nil
+		hookName = fmt.Sprintf("%s-%s", store.Name(), hi.Kind)
 	}
+	hctxId := fmt.Sprintf("%s:%s:%d", u.unit.Name(), hookName, u.rand.Int63())
 	lockMessage := fmt.Sprintf("%s: running hook %q", u.unit.Name(), hookName)
-	if err = u.hookLock.LockWithFunc(lockMessage, checkTomb); err != nil {
+	if err = u.acquireHookLock(lockMessage); err != nil {
 		return err
 	}
 	defer u.hookLock.Unlock()
 
-	ctxRelations := map[int]*ContextRelation{}
-	for id, r := range u.relationers {
-		ctxRelations[id] = r.Context()
+	hctx, err := u.getHookContext(hctxId, relationId, hi.RemoteUnit)
+	if err != nil {
+		return err
 	}
-	apiAddrs, err := u.st.APIAddresses()
+	hctx.storageId = hi.StorageId
+	policy, err := u.service.HookPolicy()
 	if err != nil {
 		return err
 	}
-	hctx := NewHookContext(u.unit, hctxId, u.uuid, relationId, hi.RemoteUnit,
-		ctxRelations, apiAddrs)
+	hctx.hookTimeout = policy.Timeout
 
 	// Prepare server.
-	getCmd := func(ctxId, cmdName string) (cmd.Command, error) {
-		// TODO: switch to long-running server with single context;
-		// use nonce in place of context id.
-		if ctxId != hctxId {
-			return nil, fmt.Errorf("expected context id %q, got %q", hctxId, ctxId)
-		}
-		return jujuc.NewCommand(hctx, cmdName)
-	}
-	socketPath := filepath.Join(u.baseDir, "agent.socket")
-	srv, err := jujuc.NewServer(getCmd, socketPath)
+	srv, socketPath, err := u.startJujucServer(hctx)
 	if err != nil {
 		return err
 	}
-	go srv.Run()
 	defer srv.Close()
 
 	// Run the hook.
@@ -361,6 +458,106 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 	return u.commitHook(hi)
 }
 
+// storageInstance returns the storage instance of the unit with the
+// given id.
+func (u *Uniter) storageInstance(id string) (*uniter.StorageInstance, error) {
+	stores, err := u.unit.StorageInstances()
+	if err != nil {
+		return nil, err
+	}
+	for _, store := range stores {
+		if store.Id() == id {
+			return store, nil
+		}
+	}
+	return nil, fmt.Errorf("storage instance %q not found", id)
+}
+
+// nextStorageHook returns the storage hook of the given kind that must
+// be run next, if any: a storage-attached hook for a store whose volume
+// has been provisioned, or a storage-detaching hook for a store the
+// charm was told about.
+func (u *Uniter) nextStorageHook(kind hooks.Kind) (hi hook.Info, found bool, err error) {
+	stores, err := u.unit.StorageInstances()
+	if err != nil {
+		return hook.Info{}, false, err
+	}
+	for _, store := range stores {
+		if kind == hooks.StorageAttached && store.Provisioned() && !store.Attached() ||
+			kind == hooks.StorageDetaching && store.Attached() {
+			return hook.Info{Kind: kind, StorageId: store.Id()}, true, nil
+		}
+	}
+	return hook.Info{}, false, nil
+}
+
+// claimLeadership makes the unit the leader of its service if the
+// service has no live leader, and returns whether the unit was newly
+// elected, and so should run the leader-elected hook.
+func (u *Uniter) claimLeadership() (elected bool, err error) {
+	// A unit on its way out does not take over; the UnitDying event
+	// will follow shortly.
+	if err := u.unit.Refresh(); err != nil {
+		return false, err
+	}
+	if u.unit.Life() != params.Alive {
+		return false, nil
+	}
+	isLeader, err := u.unit.ClaimLeadership()
+	if err != nil {
+		return false, err
+	}
+	elected = isLeader && !u.leader
+	u.leader = isLeader
+	return elected, nil
+}
+
+// runAction runs the action identified by the supplied hook.Info in an
+// appropriate hook context, and records its outcome. The failure of the
+// action itself is recorded against the action, and does not affect the
+// operation of the Uniter.
+func (u *Uniter) runAction(hi hook.Info) (err error) {
+	if err = hi.Validate(); err != nil {
+		return err
+	}
+	action, err := u.st.Action(hi.ActionId)
+	if err != nil {
+		return err
+	}
+	if action.Status() != params.ActionPending {
+		return nil
+	}
+	u.runMu.Lock()
+	defer u.runMu.Unlock()
+
+	actionName := action.Name()
+	hctxId := fmt.Sprintf("%s:%s:%d", u.unit.Name(), actionName, u.rand.Int63())
+	lockMessage := fmt.Sprintf("%s: running action %q", u.unit.Name(), actionName)
+	if err = u.acquireHookLock(lockMessage); err != nil {
+		return err
+	}
+	defer u.hookLock.Unlock()
+
+	hctx, err := u.getHookContext(hctxId, -1, "")
+	if err != nil {
+		return err
+	}
+	hctx.action = action
+	srv, socketPath, err := u.startJujucServer(hctx)
+	if err != nil {
+		return err
+	}
+	defer srv.Close()
+
+	logger.Infof("running %q action", actionName)
+	if err := hctx.RunAction(actionName, u.charm.Path(), u.toolsDir, socketPath); err != nil {
+		logger.Errorf("action failed: %s", err)
+		return action.Fail(err.Error())
+	}
+	logger.Infof("ran %q action", actionName)
+	return action.Complete(hctx.actionResults)
+}
+
 // commitHook ensures that state is consistent with the supplied hook, and
 // that the fact of the hook's completion is persisted.
 func (u *Uniter) commitHook(hi hook.Info) error {
@@ -372,6 +569,14 @@ func (u *Uniter) commitHook(hi hook.Info) error {
 		if hi.Kind == hooks.RelationBroken {
 			delete(u.relationers, hi.RelationId)
 		}
+	} else if hi.Kind.IsStorage() {
+		store, err := u.storageInstance(hi.StorageId)
+		if err != nil {
+			return err
+		}
+		if err := store.SetAttached(hi.Kind == hooks.StorageAttached); err != nil {
+			return err
+		}
 	}
 	if err := u.charm.Snapshotf("Completed %q hook.", hi.Kind); err != nil {
 		return err
@@ -396,13 +601,13 @@ func (u *Uniter) restoreRelations() error {
 	}
 	for id, dir := range dirs {
 		remove := false
-		rel, err := u.st.Relation(id)
-		if errors.IsNotFoundError(err) {
+		rel, err := u.st.RelationById(id)
+		if params.ErrCode(err) == params.CodeNotFound {
 			remove = true
 		} else if err != nil {
 			return err
 		}
-		if err = u.addRelation(rel, dir); err == state.ErrCannotEnterScope {
+		if err = u.addRelation(rel, dir); params.ErrCode(err) == params.CodeCannotEnterScope {
 			remove = true
 		} else if err != nil {
 			return err
@@ -430,25 +635,25 @@ func (u *Uniter) updateRelations(ids []int) (added []*Relationer, err error) {
 			if err := rel.Refresh(); err != nil {
 				return nil, fmt.Errorf("cannot update relation %q: %v", rel, err)
 			}
-			if rel.Life() == state.Dying {
+			if rel.Life() == params.Dying {
 				if err := r.SetDying(); err != nil {
 					return nil, err
 				} else if r.IsImplicit() {
-					delete(u.relationers, id)
+					u.removeRelationer(id)
 				}
 			}
 			continue
 		}
 		// Relations that are not alive are simply skipped, because they
 		// were not previously known anyway.
-		rel, err := u.st.Relation(id)
+		rel, err := u.st.RelationById(id)
 		if err != nil {
-			if errors.IsNotFoundError(err) {
+			if params.ErrCode(err) == params.CodeNotFound {
 				continue
 			}
 			return nil, err
 		}
-		if rel.Life() != state.Alive {
+		if rel.Life() != params.Alive {
 			continue
 		}
 		// Make sure we ignore relations not implemented by the unit's charm
@@ -456,10 +661,8 @@ func (u *Uniter) updateRelations(ids []int) (added []*Relationer, err error) {
 		if err != nil {
 			return nil, err
 		}
-		if ep, err := rel.Endpoint(u.unit.ServiceName()); err != nil {
-			return nil, err
-		} else if !ep.ImplementedBy(ch) {
-			logger.Warningf("skipping relation with unknown endpoint %q", ep)
+		if ep := rel.Endpoint(); !ep.ImplementedBy(ch) {
+			logger.Warningf("skipping relation with unknown endpoint %q", ep.Name)
 			continue
 		}
 		dir, err := relation.ReadStateDir(u.relationsDir, id)
@@ -472,14 +675,16 @@ func (u *Uniter) updateRelations(ids []int) (added []*Relationer, err error) {
 			continue
 		}
 		e := dir.Remove()
-		if err != state.ErrCannotEnterScope {
+		if params.ErrCode(err) != params.CodeCannotEnterScope {
 			return nil, err
 		}
 		if e != nil {
 			return nil, e
 		}
 	}
-	if u.unit.IsPrincipal() {
+	if isPrincipal, err := u.unit.IsPrincipal(); err != nil {
+		return nil, err
+	} else if isPrincipal {
 		return added, nil
 	}
 	// If no Alive relations remain between a subordinate unit's service
@@ -502,14 +707,14 @@ func (u *Uniter) updateRelations(ids []int) (added []*Relationer, err error) {
 
 // addRelation causes the unit agent to join the supplied relation, and to
 // store persistent state in the supplied dir.
-func (u *Uniter) addRelation(rel *state.Relation, dir *relation.StateDir) error {
+func (u *Uniter) addRelation(rel *uniter.Relation, dir *relation.StateDir) error {
 	logger.Infof("joining relation %q", rel)
-	ru, err := rel.Unit(u.unit)
+	ru := rel.Unit(u.unit)
+	r := NewRelationer(ru, dir, u.relationHooks)
+	w, err := u.unit.Watch()
 	if err != nil {
 		return err
 	}
-	r := NewRelationer(ru, dir, u.relationHooks)
-	w := u.unit.Watch()
 	defer watcher.Stop(w, &u.tomb)
 	for {
 		select {
@@ -519,15 +724,25 @@ func (u *Uniter) addRelation(rel *state.Relation, dir *relation.StateDir) error
 			if !ok {
 				return watcher.MustErr(w)
 			}
-			if err := r.Join(); err == state.ErrCannotEnterScopeYet {
+			if err := r.Join(); params.ErrCode(err) == params.CodeCannotEnterScopeYet {
 				logger.Infof("cannot enter scope for relation %q; waiting for subordinate to be removed", rel)
 				continue
 			} else if err != nil {
 				return err
 			}
 			logger.Infof("joined relation %q", rel)
+			u.runMu.Lock()
 			u.relationers[rel.Id()] = r
+			u.runMu.Unlock()
 			return nil
 		}
 	}
 }
+
+// removeRelationer forgets the relationer of the relation with the
+// given id.
+func (u *Uniter) removeRelationer(id int) {
+	u.runMu.Lock()
+	defer u.runMu.Unlock()
+	delete(u.relationers, id)
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/rpc"
 	"net/url"
 	"os"
 	"os/exec"
@@ -29,7 +30,9 @@ import (
 	"launchpad.net/juju-core/errors"
 	"launchpad.net/juju-core/juju/testing"
 	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api"
 	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/presence"
 	coretesting "launchpad.net/juju-core/testing"
 	"launchpad.net/juju-core/testing/checkers"
 	"launchpad.net/juju-core/utils/fslock"
@@ -126,6 +129,7 @@ type context struct {
 	dataDir       string
 	s             *UniterSuite
 	st            *state.State
+	api           *api.State
 	charms        coretesting.ResponseMap
 	hooks         []string
 	sch           *state.Charm
@@ -136,6 +140,7 @@ type context struct {
 	relation      *state.Relation
 	relationUnits map[string]*state.RelationUnit
 	subordinate   *state.Unit
+	leaderPinger  *presence.Pinger
 }
 
 func (ctx *context) run(c *C, steps []stepper) {
@@ -144,6 +149,14 @@ func (ctx *context) run(c *C, steps []stepper) {
 			err := ctx.uniter.Stop()
 			c.Assert(err, IsNil)
 		}
+		if ctx.api != nil {
+			err := ctx.api.Close()
+			c.Assert(err, IsNil)
+		}
+		if ctx.leaderPinger != nil {
+			err := ctx.leaderPinger.Stop()
+			c.Assert(err, IsNil)
+		}
 	}()
 	for i, s := range steps {
 		c.Logf("step %d", i)
@@ -225,11 +238,21 @@ var startupTests = []uniterTest{
 		createCharm{},
 		createServiceAndUnit{},
 		startUniter{},
-		waitUniterDead{`failed to initialize uniter for unit "u/0": .*not a directory`},
+		waitUniterDead{`failed to initialize uniter for "unit-u-0": .*not a directory`},
 	), ut(
 		"unknown unit",
+		createCharm{},
+		createServiceAndUnit{},
+		// Log in before the unit goes away, as an agent would have.
+		apiLogin{},
+		custom{func(c *C, ctx *context) {
+			err := ctx.unit.EnsureDead()
+			c.Assert(err, IsNil)
+			err = ctx.unit.Remove()
+			c.Assert(err, IsNil)
+		}},
 		startUniter{},
-		waitUniterDead{`failed to initialize uniter for unit "u/0": unit "u/0" not found`},
+		waitUniterDead{`failed to initialize uniter for "unit-u-0": unit "u/0" not found`},
 	),
 }
 
@@ -906,6 +929,237 @@ func (s *UniterSuite) TestUniterSubordinates(c *C) {
 	s.runUniterTests(c, subordinatesTests)
 }
 
+var runCommandsTests = []uniterTest{
+	ut(
+		"run commands: environment",
+		quickStart{},
+		runCommands{
+			commands: "echo $JUJU_UNIT_NAME; unit-get private-address; echo oops >&2; exit 3",
+			code:     3,
+			stdout:   "u/0\nprivate.dummy.address.example.com\n",
+			stderr:   "oops\n",
+		},
+		verifyRunning{},
+	), ut(
+		"run commands: relation settings",
+		quickStartRelation{},
+		runCommands{
+			commands: "relation-ids db; relation-list -r db:0; relation-set -r db:0 ran=commands",
+			stdout:   "db:0\nmysql/0\n",
+		},
+		verifyRelationSetting{"ran", "commands"},
+		runCommands{
+			commands: "relation-set -r db:0 ran=failed; exit 1",
+			code:     1,
+		},
+		verifyRelationSetting{"ran", "commands"},
+	), ut(
+		"run commands: wait for hook lock",
+		quickStart{},
+		verifyRunCommandsWaitForHookLock,
+	),
+}
+
+func (s *UniterSuite) TestUniterRunCommands(c *C) {
+	s.runUniterTests(c, runCommandsTests)
+}
+
+var actionsTests = []uniterTest{
+	ut(
+		"run action: success",
+		startupActions{},
+		addAction{"snapshot", map[string]interface{}{"outfile": "bar.bz2"}},
+		waitAction{
+			id:      "u/0:0",
+			status:  params.ActionCompleted,
+			results: map[string]interface{}{"outfile": "bar.bz2", "name": "snapshot", "id": "u/0:0"},
+		},
+		verifyRunning{},
+	), ut(
+		"run action: failure does not affect the unit",
+		startupActions{},
+		addAction{"fail", nil},
+		waitAction{id: "u/0:0", status: params.ActionFailed, message: "exit status 1"},
+		addAction{"missing", nil},
+		waitAction{id: "u/0:1", status: params.ActionFailed, message: `action "missing" not implemented`},
+		verifyRunning{},
+	), ut(
+		"run action: actions queued while the uniter is stopped",
+		startupActions{},
+		stopUniter{},
+		addAction{"snapshot", map[string]interface{}{"outfile": "first"}},
+		addAction{"snapshot", map[string]interface{}{"outfile": "second"}},
+		startUniter{},
+		waitAction{
+			id:      "u/0:0",
+			status:  params.ActionCompleted,
+			results: map[string]interface{}{"outfile": "first", "name": "snapshot", "id": "u/0:0"},
+		},
+		waitAction{
+			id:      "u/0:1",
+			status:  params.ActionCompleted,
+			results: map[string]interface{}{"outfile": "second", "name": "snapshot", "id": "u/0:1"},
+		},
+		verifyRunning{},
+	),
+}
+
+func (s *UniterSuite) TestUniterActions(c *C) {
+	s.runUniterTests(c, actionsTests)
+}
+
+var leadershipTests = []uniterTest{
+	ut(
+		"leader elected once started",
+		startupLeadership{},
+		waitHooks{"leader-elected"},
+		writeLeaderSettings{"u/0", map[string]string{"master": "u/0"}},
+		waitHooks{},
+		verifyRunning{},
+	), ut(
+		"follower sees leader settings and takes over from a dead leader",
+		createCharm{customize: leadershipHooks},
+		serveCharm{},
+		createServiceAndUnit{},
+		addLeader{},
+		startUniter{},
+		waitAddresses{},
+		waitUnit{status: params.StatusStarted},
+		waitHooks{"install", "config-changed", "start", "leader-settings-changed"},
+		writeLeaderSettings{"u/1", map[string]string{"master": "u/1"}},
+		waitHooks{"leader-settings-changed"},
+		killLeader{},
+		waitHooks{"leader-elected"},
+		verifyRunning{},
+	),
+}
+
+func (s *UniterSuite) TestUniterLeadership(c *C) {
+	s.runUniterTests(c, leadershipTests)
+}
+
+var storageTests = []uniterTest{
+	ut(
+		"storage attached once provisioned, and detached when dying",
+		startupStorage{},
+		waitHooks{},
+		provisionStorage{"data/0"},
+		waitHooks{"data-storage-attached /srv/data"},
+		verifyStorageAttached{"data/0", true},
+		unitDying,
+		waitHooks{"data-storage-detaching data/0", "stop"},
+		waitUniterDead{},
+		verifyStorageAttached{"data/0", false},
+	), ut(
+		"storage provisioned while the uniter is stopped",
+		startupStorage{},
+		stopUniter{},
+		provisionStorage{"data/0"},
+		startUniter{},
+		waitHooks{"data-storage-attached /srv/data"},
+		verifyStorageAttached{"data/0", true},
+		verifyRunning{},
+	),
+}
+
+func (s *UniterSuite) TestUniterStorage(c *C) {
+	s.runUniterTests(c, storageTests)
+}
+
+var resourceTests = []uniterTest{
+	ut(
+		"resources downloaded once per revision",
+		startupResources{},
+		runCommands{
+			commands: "resource-get jdk",
+			code:     1,
+			stderr:   "error: resource \"jdk\" of service \"u\" not found\n",
+		},
+		attachResource{"jdk", "jdk content"},
+		runCommands{
+			commands: "basename $(resource-get jdk); cat $(resource-get jdk)",
+			stdout:   "jdk.tar.gz\njdk content",
+		},
+		attachResource{"jdk", "new jdk content"},
+		runCommands{
+			commands: "cat $(resource-get jdk)",
+			stdout:   "new jdk content",
+		},
+		runCommands{
+			commands: "resource-get jre",
+			code:     1,
+			stderr:   "error: charm declares no resource \"jre\"\n",
+		},
+	),
+}
+
+func (s *UniterSuite) TestUniterResources(c *C) {
+	s.runUniterTests(c, resourceTests)
+}
+
+var hookPolicyTests = []uniterTest{
+	ut(
+		"failed hook retried with backoff until it succeeds",
+		createCharm{badHooks: []string{"start"}},
+		serveCharm{},
+		createServiceAndUnit{},
+		setHookPolicy{
+			Retry:         true,
+			RetryDelay:    500 * time.Millisecond,
+			MaxRetryDelay: time.Second,
+		},
+		startUniter{},
+		waitHooks{"install", "config-changed", "fail-start"},
+		waitUnit{
+			status: params.StatusError,
+			info:   `hook failed: "start"; retrying in 1s (attempt 2)`,
+		},
+		fixHook{"start"},
+		waitUnit{status: params.StatusStarted},
+	), ut(
+		"failed hook retried once the policy asks for it",
+		createCharm{badHooks: []string{"start"}},
+		serveCharm{},
+		createServiceAndUnit{},
+		startUniter{},
+		waitHooks{"install", "config-changed", "fail-start"},
+		waitUnit{
+			status: params.StatusError,
+			info:   `hook failed: "start"`,
+		},
+		fixHook{"start"},
+		setHookPolicy{
+			Retry:      true,
+			RetryDelay: 500 * time.Millisecond,
+		},
+		waitHooks{"start"},
+		waitUnit{status: params.StatusStarted},
+	), ut(
+		"hook killed when it runs for too long",
+		createCharm{
+			customize: func(c *C, ctx *context, path string) {
+				hook := badHook[:strings.LastIndex(badHook, "exit")] + "sleep 60\n"
+				content := fmt.Sprintf(hook, "start")
+				err := ioutil.WriteFile(filepath.Join(path, "hooks", "start"), []byte(content), 0755)
+				c.Assert(err, IsNil)
+			},
+		},
+		serveCharm{},
+		createServiceAndUnit{},
+		setHookPolicy{Timeout: 500 * time.Millisecond},
+		startUniter{},
+		waitUnit{
+			status: params.StatusError,
+			info:   `hook failed: "start"`,
+		},
+		waitHooks{"install", "config-changed", "fail-start"},
+	),
+}
+
+func (s *UniterSuite) TestUniterHookPolicy(c *C) {
+	s.runUniterTests(c, hookPolicyTests)
+}
+
 func (s *UniterSuite) runUniterTests(c *C, uniterTests []uniterTest) {
 	for i, t := range uniterTests {
 		c.Logf("\ntest %d: %s\n", i, t.summary)
@@ -913,9 +1167,12 @@ func (s *UniterSuite) runUniterTests(c *C, uniterTests []uniterTest) {
 			defer s.Reset(c)
 			env, err := s.State.Environment()
 			c.Assert(err, IsNil)
+			// The uniter talks to the API server, whose watchers are
+			// run against BackingState, so that is the state the
+			// tests manipulate and sync.
 			ctx := &context{
 				s:       s,
-				st:      s.State,
+				st:      s.BackingState,
 				uuid:    env.UUID(),
 				path:    s.unitDir,
 				dataDir: s.dataDir,
@@ -929,7 +1186,8 @@ func (s *UniterSuite) runUniterTests(c *C, uniterTests []uniterTest) {
 func (s *UniterSuite) TestSubordinateDying(c *C) {
 	// Create a test context for later use.
 	ctx := &context{
-		st:      s.State,
+		s:       s,
+		st:      s.BackingState,
 		path:    filepath.Join(s.dataDir, "agents", "unit-u-0"),
 		dataDir: s.dataDir,
 		charms:  coretesting.ResponseMap{},
@@ -959,7 +1217,9 @@ func (s *UniterSuite) TestSubordinateDying(c *C) {
 	c.Assert(err, IsNil)
 	err = wpru.EnterScope(nil)
 	c.Assert(err, IsNil)
-	ctx.unit, err = s.State.Unit("u/0")
+	ctx.unit, err = s.BackingState.Unit("u/0")
+	c.Assert(err, IsNil)
+	err = ctx.unit.SetPassword("password")
 	c.Assert(err, IsNil)
 
 	// Run the actual test.
@@ -1072,6 +1332,8 @@ func (createServiceAndUnit) step(c *C, ctx *context) {
 	c.Assert(err, IsNil)
 	err = machine.SetProvisioned("i-exist", "fake_nonce", nil)
 	c.Assert(err, IsNil)
+	err = unit.SetPassword("password")
+	c.Assert(err, IsNil)
 	ctx.svc = svc
 	ctx.unit = unit
 }
@@ -1112,13 +1374,23 @@ func (waitAddresses) step(c *C, ctx *context) {
 	}
 }
 
+type apiLogin struct{}
+
+func (s apiLogin) step(c *C, ctx *context) {
+	if ctx.api != nil {
+		return
+	}
+	ctx.api = ctx.s.OpenAPIAs(c, "unit-u-0", "password")
+}
+
 type startUniter struct{}
 
 func (s startUniter) step(c *C, ctx *context) {
 	if ctx.uniter != nil {
 		panic("don't start two uniters!")
 	}
-	ctx.uniter = uniter.NewUniter(ctx.st, "u/0", ctx.dataDir)
+	step(c, ctx, apiLogin{})
+	ctx.uniter = uniter.NewUniter(ctx.api.Uniter(), "unit-u-0", ctx.dataDir)
 }
 
 type waitUniterDead struct {
@@ -1341,6 +1613,13 @@ func (s waitHooks) step(c *C, ctx *context) {
 	}
 }
 
+type setHookPolicy params.HookPolicy
+
+func (s setHookPolicy) step(c *C, ctx *context) {
+	err := ctx.svc.SetHookPolicy(params.HookPolicy(s))
+	c.Assert(err, IsNil)
+}
+
 type fixHook struct {
 	name string
 }
@@ -1748,3 +2027,294 @@ var verifyHookSyncLockLocked = custom{func(c *C, ctx *context) {
 	lock := createHookLock(c, ctx.dataDir)
 	c.Assert(lock, checkers.Satisfies, (*fslock.Lock).IsLocked)
 }}
+
+func (ctx *context) runCommands(commands string) (*uniter.RunResult, error) {
+	client, err := rpc.Dial("unix", filepath.Join(ctx.path, uniter.RunListenerFile))
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	var result uniter.RunResult
+	err = client.Call(uniter.JujuRunEndpoint, commands, &result)
+	return &result, err
+}
+
+type runCommands struct {
+	commands string
+	code     int
+	stdout   string
+	stderr   string
+}
+
+func (s runCommands) step(c *C, ctx *context) {
+	result, err := ctx.runCommands(s.commands)
+	c.Assert(err, IsNil)
+	c.Assert(result.Code, Equals, s.code)
+	c.Assert(string(result.Stdout), Equals, s.stdout)
+	c.Assert(string(result.Stderr), Equals, s.stderr)
+}
+
+// startupActions starts a unit whose charm defines the snapshot, fail and
+// missing actions; only the last has no implementation.
+type startupActions struct{}
+
+func (s startupActions) step(c *C, ctx *context) {
+	step(c, ctx, createCharm{
+		customize: func(c *C, ctx *context, path string) {
+			actions := `
+actions:
+  snapshot:
+    params:
+      outfile: {type: string}
+  fail: {}
+  missing: {}
+`
+			err := ioutil.WriteFile(filepath.Join(path, "actions.yaml"), []byte(actions), 0644)
+			c.Assert(err, IsNil)
+			err = os.Mkdir(filepath.Join(path, "actions"), 0755)
+			c.Assert(err, IsNil)
+			snapshot := "#!/bin/bash\naction-set outfile=$(action-get outfile) name=$JUJU_ACTION_NAME id=$JUJU_ACTION_ID\n"
+			err = ioutil.WriteFile(filepath.Join(path, "actions", "snapshot"), []byte(snapshot), 0755)
+			c.Assert(err, IsNil)
+			err = ioutil.WriteFile(filepath.Join(path, "actions", "fail"), []byte("#!/bin/bash\nexit 1\n"), 0755)
+			c.Assert(err, IsNil)
+		},
+	})
+	step(c, ctx, serveCharm{})
+	step(c, ctx, createUniter{})
+	step(c, ctx, waitUnit{status: params.StatusStarted})
+	step(c, ctx, waitHooks{"install", "config-changed", "start"})
+}
+
+type addAction struct {
+	name   string
+	params map[string]interface{}
+}
+
+func (s addAction) step(c *C, ctx *context) {
+	_, err := ctx.unit.AddAction(s.name, s.params)
+	c.Assert(err, IsNil)
+}
+
+type waitAction struct {
+	id      string
+	status  params.ActionStatus
+	results map[string]interface{}
+	message string
+}
+
+func (s waitAction) step(c *C, ctx *context) {
+	timeout := time.After(worstCase)
+	for {
+		ctx.st.StartSync()
+		select {
+		case <-time.After(coretesting.ShortWait):
+			action, err := ctx.st.Action(s.id)
+			c.Assert(err, IsNil)
+			if action.Status() == params.ActionPending {
+				c.Logf("action %q still pending", s.id)
+				continue
+			}
+			c.Assert(action.Status(), Equals, s.status)
+			c.Assert(action.Results(), DeepEquals, s.results)
+			c.Assert(action.Message(), Equals, s.message)
+			return
+		case <-timeout:
+			c.Fatalf("action %q never ran", s.id)
+		}
+	}
+}
+
+type verifyRelationSetting struct {
+	key   string
+	value string
+}
+
+func (s verifyRelationSetting) step(c *C, ctx *context) {
+	settings, err := ctx.relationUnits["mysql/0"].ReadSettings("u/0")
+	c.Assert(err, IsNil)
+	c.Assert(settings[s.key], Equals, s.value)
+}
+
+var verifyRunCommandsWaitForHookLock = custom{func(c *C, ctx *context) {
+	lock := createHookLock(c, ctx.dataDir)
+	err := lock.Lock("u/1:fake")
+	c.Assert(err, IsNil)
+	done := make(chan *uniter.RunResult)
+	go func() {
+		result, err := ctx.runCommands("echo ran")
+		c.Check(err, IsNil)
+		done <- result
+	}()
+	select {
+	case <-done:
+		c.Fatalf("commands ran while hook lock held")
+	case <-time.After(coretesting.ShortWait):
+	}
+	err = lock.BreakLock()
+	c.Assert(err, IsNil)
+	select {
+	case result := <-done:
+		c.Assert(string(result.Stdout), Equals, "ran\n")
+	case <-time.After(worstCase):
+		c.Fatalf("commands not run after hook lock released")
+	}
+}}
+
+// leadershipHooks adds the leadership hooks to a charm.
+func leadershipHooks(c *C, ctx *context, path string) {
+	for _, name := range []string{"leader-elected", "leader-settings-changed"} {
+		ctx.writeHook(c, filepath.Join(path, "hooks", name), true)
+	}
+}
+
+// startupLeadership starts a unit whose charm implements the leadership
+// hooks.
+type startupLeadership struct{}
+
+func (s startupLeadership) step(c *C, ctx *context) {
+	step(c, ctx, createCharm{customize: leadershipHooks})
+	step(c, ctx, serveCharm{})
+	step(c, ctx, createUniter{})
+	step(c, ctx, waitUnit{status: params.StatusStarted})
+	step(c, ctx, waitHooks{"install", "config-changed", "start"})
+}
+
+// addLeader adds a second unit to the service, whose agent is alive and
+// leads the service.
+type addLeader struct{}
+
+func (s addLeader) step(c *C, ctx *context) {
+	unit, err := ctx.svc.AddUnit()
+	c.Assert(err, IsNil)
+	ctx.leaderPinger, err = unit.SetAgentAlive()
+	c.Assert(err, IsNil)
+	ctx.st.Sync()
+	isLeader, err := unit.ClaimLeadership()
+	c.Assert(err, IsNil)
+	c.Assert(isLeader, Equals, true)
+}
+
+// killLeader kills the agent of the unit added by addLeader.
+type killLeader struct{}
+
+func (s killLeader) step(c *C, ctx *context) {
+	err := ctx.leaderPinger.Kill()
+	c.Assert(err, IsNil)
+	ctx.leaderPinger = nil
+}
+
+// startupStorage starts a unit whose charm requires a filesystem store,
+// and implements its storage hooks.
+type startupStorage struct{}
+
+func (s startupStorage) step(c *C, ctx *context) {
+	step(c, ctx, createCharm{
+		customize: func(c *C, ctx *context, path string) {
+			storage := `
+storage:
+  data:
+    type: filesystem
+    location: /srv/data
+`
+			f, err := os.OpenFile(filepath.Join(path, "metadata.yaml"), os.O_WRONLY|os.O_APPEND, 0644)
+			c.Assert(err, IsNil)
+			_, err = f.WriteString(storage)
+			c.Assert(err, IsNil)
+			c.Assert(f.Close(), IsNil)
+			hooks := map[string]string{
+				"data-storage-attached":  "#!/bin/bash\njuju-log $JUJU_ENV_UUID data-storage-attached $(storage-get location)\n",
+				"data-storage-detaching": "#!/bin/bash\njuju-log $JUJU_ENV_UUID data-storage-detaching $JUJU_STORAGE_ID\n",
+			}
+			for name, hook := range hooks {
+				err = ioutil.WriteFile(filepath.Join(path, "hooks", name), []byte(hook), 0755)
+				c.Assert(err, IsNil)
+			}
+		},
+	})
+	step(c, ctx, serveCharm{})
+	step(c, ctx, createUniter{})
+	step(c, ctx, waitUnit{status: params.StatusStarted})
+	step(c, ctx, waitHooks{"install", "config-changed", "start"})
+}
+
+// provisionStorage records a volume as provisioned for the storage
+// instance of the unit, as the storage provisioner would.
+type provisionStorage struct {
+	id string
+}
+
+func (s provisionStorage) step(c *C, ctx *context) {
+	store, err := ctx.st.StorageInstance(s.id)
+	c.Assert(err, IsNil)
+	err = store.SetProvisioned("vol-0", "/dev/fake")
+	c.Assert(err, IsNil)
+}
+
+type verifyStorageAttached struct {
+	id       string
+	attached bool
+}
+
+func (s verifyStorageAttached) step(c *C, ctx *context) {
+	store, err := ctx.st.StorageInstance(s.id)
+	c.Assert(err, IsNil)
+	c.Assert(store.Attached(), Equals, s.attached)
+}
+
+// startupResources starts a unit whose charm declares the jdk resource.
+type startupResources struct{}
+
+func (s startupResources) step(c *C, ctx *context) {
+	step(c, ctx, createCharm{
+		customize: func(c *C, ctx *context, path string) {
+			resources := `
+resources:
+  jdk:
+    filename: jdk.tar.gz
+`
+			f, err := os.OpenFile(filepath.Join(path, "metadata.yaml"), os.O_WRONLY|os.O_APPEND, 0644)
+			c.Assert(err, IsNil)
+			_, err = f.WriteString(resources)
+			c.Assert(err, IsNil)
+			c.Assert(f.Close(), IsNil)
+		},
+	})
+	step(c, ctx, serveCharm{})
+	step(c, ctx, createUniter{})
+	step(c, ctx, waitUnit{status: params.StatusStarted})
+	step(c, ctx, waitHooks{"install", "config-changed", "start"})
+}
+
+// attachResource records new content for a resource of the service, and
+// serves it once, as juju attach would upload it.
+type attachResource struct {
+	name    string
+	content string
+}
+
+func (s attachResource) step(c *C, ctx *context) {
+	hasher := sha256.New()
+	hasher.Write([]byte(s.content))
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	key := fmt.Sprintf("/resources/%s/%s", s.name, hash)
+	rurl, err := url.Parse(coretesting.Server.URL + key)
+	c.Assert(err, IsNil)
+	_, err = ctx.svc.SetResource(s.name, rurl, hash, int64(len(s.content)))
+	c.Assert(err, IsNil)
+	coretesting.Server.ResponseMap(1, map[string]coretesting.Response{
+		key: {200, nil, []byte(s.content)},
+	})
+}
+
+type writeLeaderSettings struct {
+	unit     string
+	settings map[string]string
+}
+
+func (s writeLeaderSettings) step(c *C, ctx *context) {
+	unit, err := ctx.st.Unit(s.unit)
+	c.Assert(err, IsNil)
+	err = unit.SetLeaderSettings(s.settings)
+	c.Assert(err, IsNil)
+}